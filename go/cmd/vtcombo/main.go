@@ -113,6 +113,10 @@ func main() {
 
 	servenv.Init()
 	tabletserver.Init()
+	if err := tabletserver.VerifyConfig(); err != nil {
+		log.Error(err)
+		exit.Return(1)
+	}
 
 	// database configs
 	mycnf, err := mysqlctl.NewMycnfFromFlags(0)
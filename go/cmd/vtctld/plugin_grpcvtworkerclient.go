@@ -0,0 +1,11 @@
+// Copyright 2016, Google Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+// Imports and registers the gRPC vtworker client.
+
+import (
+	_ "github.com/youtube/vitess/go/vt/worker/grpcvtworkerclient"
+)
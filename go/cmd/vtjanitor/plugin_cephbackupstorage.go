@@ -0,0 +1,5 @@
+package main
+
+import (
+	_ "github.com/youtube/vitess/go/vt/mysqlctl/cephbackupstorage"
+)
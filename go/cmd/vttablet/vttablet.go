@@ -54,6 +54,10 @@ func main() {
 
 	servenv.Init()
 
+	if err := tabletserver.VerifyConfig(); err != nil {
+		log.Error(err)
+		exit.Return(1)
+	}
 	if *tabletPath == "" {
 		log.Errorf("tabletPath required")
 		exit.Return(1)
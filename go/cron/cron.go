@@ -0,0 +1,194 @@
+// Copyright 2016, Google Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package cron parses cron-like schedule specifications, and computes
+// the next time they fire.
+//
+// A spec has five space separated fields: minute (0-59), hour (0-23),
+// day of month (1-31), month (1-12) and day of week (0-6, 0 is Sunday).
+// Each field is '*', a value, a range 'a-b', a step '*/n' or 'a-b/n',
+// or a comma separated list of those. The '@hourly', '@daily',
+// '@weekly' and '@monthly' shortcuts are also accepted.
+// As with cron, if both day of month and day of week are restricted,
+// a day matches if either of them matches.
+package cron
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule is a parsed cron spec. Each field is a bit set of the
+// values that match.
+type Schedule struct {
+	spec   string
+	minute uint64
+	hour   uint64
+	dom    uint64
+	month  uint64
+	dow    uint64
+
+	// domStar and dowStar are true if the corresponding field was '*'.
+	domStar bool
+	dowStar bool
+}
+
+type fieldRange struct {
+	name     string
+	min, max uint
+}
+
+var (
+	minuteRange = fieldRange{"minute", 0, 59}
+	hourRange   = fieldRange{"hour", 0, 23}
+	domRange    = fieldRange{"day of month", 1, 31}
+	monthRange  = fieldRange{"month", 1, 12}
+	dowRange    = fieldRange{"day of week", 0, 6}
+)
+
+var shortcuts = map[string]string{
+	"@hourly":  "0 * * * *",
+	"@daily":   "0 0 * * *",
+	"@weekly":  "0 0 * * 0",
+	"@monthly": "0 0 1 * *",
+}
+
+// Parse parses a cron spec.
+func Parse(spec string) (*Schedule, error) {
+	expanded := strings.TrimSpace(spec)
+	if s, ok := shortcuts[expanded]; ok {
+		expanded = s
+	}
+	fields := strings.Fields(expanded)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("invalid cron spec %q: expected 5 fields, got %v", spec, len(fields))
+	}
+
+	s := &Schedule{
+		spec:    spec,
+		domStar: fields[2] == "*",
+		dowStar: fields[4] == "*",
+	}
+	var err error
+	for _, f := range []struct {
+		value string
+		r     fieldRange
+		bits  *uint64
+	}{
+		{fields[0], minuteRange, &s.minute},
+		{fields[1], hourRange, &s.hour},
+		{fields[2], domRange, &s.dom},
+		{fields[3], monthRange, &s.month},
+		{fields[4], dowRange, &s.dow},
+	} {
+		if *f.bits, err = parseField(f.value, f.r); err != nil {
+			return nil, fmt.Errorf("invalid cron spec %q: %v", spec, err)
+		}
+	}
+	return s, nil
+}
+
+// parseField parses one field of a spec, and returns its bit set.
+func parseField(field string, r fieldRange) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		step := uint(1)
+		if i := strings.Index(part, "/"); i != -1 {
+			n, err := strconv.ParseUint(part[i+1:], 10, 8)
+			if err != nil || n == 0 {
+				return 0, fmt.Errorf("invalid step in %v field %q", r.name, part)
+			}
+			step = uint(n)
+			part = part[:i]
+		}
+
+		var low, high uint
+		switch {
+		case part == "*":
+			low, high = r.min, r.max
+		case strings.Contains(part, "-"):
+			bounds := strings.SplitN(part, "-", 2)
+			l, err := parseValue(bounds[0], r)
+			if err != nil {
+				return 0, err
+			}
+			h, err := parseValue(bounds[1], r)
+			if err != nil {
+				return 0, err
+			}
+			if l > h {
+				return 0, fmt.Errorf("invalid range in %v field %q", r.name, part)
+			}
+			low, high = l, h
+		default:
+			v, err := parseValue(part, r)
+			if err != nil {
+				return 0, err
+			}
+			low, high = v, v
+			if step != 1 {
+				// 'a/n' means 'a-max/n'.
+				high = r.max
+			}
+		}
+
+		for v := low; v <= high; v += step {
+			bits |= 1 << v
+		}
+	}
+	return bits, nil
+}
+
+func parseValue(value string, r fieldRange) (uint, error) {
+	v, err := strconv.ParseUint(value, 10, 8)
+	if err != nil || uint(v) < r.min || uint(v) > r.max {
+		return 0, fmt.Errorf("invalid %v value %q, must be between %v and %v", r.name, value, r.min, r.max)
+	}
+	return uint(v), nil
+}
+
+// String returns the spec the schedule was parsed from.
+func (s *Schedule) String() string {
+	return s.spec
+}
+
+// dayMatches returns true if the day of t matches the schedule.
+func (s *Schedule) dayMatches(t time.Time) bool {
+	domMatch := s.dom&(1<<uint(t.Day())) != 0
+	dowMatch := s.dow&(1<<uint(t.Weekday())) != 0
+	if s.domStar || s.dowStar {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
+
+// Next returns the first time strictly after t that matches the
+// schedule, with a one minute granularity. It returns the zero time
+// if no such time exists in the next five years (e.g. "0 0 30 2 *").
+func (s *Schedule) Next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+	for t.Before(limit) {
+		if s.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !s.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if s.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+			continue
+		}
+		if s.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
@@ -0,0 +1,90 @@
+// Copyright 2016, Google Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cron
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseErrors(t *testing.T) {
+	for _, spec := range []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 7",
+		"5-1 * * * *",
+		"*/0 * * * *",
+		"a * * * *",
+		"@yearly",
+	} {
+		if _, err := Parse(spec); err == nil {
+			t.Errorf("Parse(%q) should have failed", spec)
+		}
+	}
+}
+
+func TestNext(t *testing.T) {
+	// A Wednesday.
+	from := time.Date(2016, 8, 24, 10, 15, 35, 0, time.UTC)
+	testcases := []struct {
+		spec string
+		want time.Time
+	}{{
+		spec: "* * * * *",
+		want: time.Date(2016, 8, 24, 10, 16, 0, 0, time.UTC),
+	}, {
+		spec: "@hourly",
+		want: time.Date(2016, 8, 24, 11, 0, 0, 0, time.UTC),
+	}, {
+		spec: "@daily",
+		want: time.Date(2016, 8, 25, 0, 0, 0, 0, time.UTC),
+	}, {
+		spec: "@weekly",
+		want: time.Date(2016, 8, 28, 0, 0, 0, 0, time.UTC),
+	}, {
+		spec: "@monthly",
+		want: time.Date(2016, 9, 1, 0, 0, 0, 0, time.UTC),
+	}, {
+		spec: "30 2 * * *",
+		want: time.Date(2016, 8, 25, 2, 30, 0, 0, time.UTC),
+	}, {
+		spec: "*/20 * * * *",
+		want: time.Date(2016, 8, 24, 10, 20, 0, 0, time.UTC),
+	}, {
+		spec: "0 9-17/4 * * *",
+		want: time.Date(2016, 8, 24, 13, 0, 0, 0, time.UTC),
+	}, {
+		spec: "0 3 * * 1,5",
+		want: time.Date(2016, 8, 26, 3, 0, 0, 0, time.UTC),
+	}, {
+		spec: "0 0 1 1 *",
+		want: time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC),
+	}, {
+		// Day of month and day of week are OR-ed: the 1st, or Mondays.
+		spec: "0 0 1 * 1",
+		want: time.Date(2016, 8, 29, 0, 0, 0, 0, time.UTC),
+	}, {
+		spec: "0 0 29 2 *",
+		want: time.Date(2020, 2, 29, 0, 0, 0, 0, time.UTC),
+	}, {
+		spec: "0 0 30 2 *",
+		want: time.Time{},
+	}}
+	for _, tc := range testcases {
+		s, err := Parse(tc.spec)
+		if err != nil {
+			t.Errorf("Parse(%q) failed: %v", tc.spec, err)
+			continue
+		}
+		if got := s.Next(from); !got.Equal(tc.want) {
+			t.Errorf("Parse(%q).Next(%v) = %v, want %v", tc.spec, from, got, tc.want)
+		}
+	}
+}
@@ -24,6 +24,9 @@ func (t *MigrateServedTypesTask) Run(parameters map[string]string) ([]*automatio
 	if reverse := parameters["reverse"]; reverse != "" {
 		args = append(args, "--reverse="+reverse)
 	}
+	if reverseReplication := parameters["reverse_replication"]; reverseReplication != "" {
+		args = append(args, "--reverse_replication="+reverseReplication)
+	}
 	args = append(args,
 		topoproto.KeyspaceShardString(parameters["keyspace"], parameters["source_shard"]),
 		parameters["type"])
@@ -38,5 +41,5 @@ func (t *MigrateServedTypesTask) RequiredParameters() []string {
 
 // OptionalParameters is part of the Task interface.
 func (t *MigrateServedTypesTask) OptionalParameters() []string {
-	return []string{"cells", "reverse"}
+	return []string{"cells", "reverse", "reverse_replication"}
 }
@@ -36,4 +36,13 @@ func TestMigrateServedTypesTask(t *testing.T) {
 	parameters["cells"] = "cell1"
 	parameters["reverse"] = "true"
 	testTask(t, "MigrateServedTypes", task, parameters, fake)
+
+	fake.RegisterResult([]string{"MigrateServedTypes", "--reverse_replication=true", "test_keyspace/0", "master"},
+		"",  // No output.
+		nil) // No error.
+	delete(parameters, "cells")
+	delete(parameters, "reverse")
+	parameters["type"] = "master"
+	parameters["reverse_replication"] = "true"
+	testTask(t, "MigrateServedTypes", task, parameters, fake)
 }
@@ -0,0 +1,56 @@
+package janitor
+
+import (
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/youtube/vitess/go/vt/mysqlctl"
+	"github.com/youtube/vitess/go/vt/mysqlctl/backupstorage"
+	"github.com/youtube/vitess/go/vt/wrangler"
+)
+
+var (
+	backupRetentionKeepLast       = flag.Int("backup_retention_keep_last", 0, "backup_retention janitor: number of most recent restorable backups to keep")
+	backupRetentionKeepDailyDays  = flag.Int("backup_retention_keep_daily_days", 0, "backup_retention janitor: keep the most recent restorable backup of each day, for that many days")
+	backupRetentionKeepWeeklyDays = flag.Int("backup_retention_keep_weekly_days", 0, "backup_retention janitor: keep the most recent restorable backup of each week, for that many days")
+)
+
+// BackupRetention is a janitor module that removes the backups of
+// its shard that are not retained by the configured policy. In dry
+// run mode, it only logs what it would remove.
+type BackupRetention struct {
+	wr       *wrangler.Wrangler
+	keyspace string
+	shard    string
+	policy   mysqlctl.BackupRetentionPolicy
+}
+
+// Configure is part of the Janitor interface.
+func (br *BackupRetention) Configure(wr *wrangler.Wrangler, keyspace, shard string) error {
+	br.wr = wr
+	br.keyspace = keyspace
+	br.shard = shard
+	br.policy = mysqlctl.BackupRetentionPolicy{
+		KeepLast:       *backupRetentionKeepLast,
+		KeepDailyDays:  *backupRetentionKeepDailyDays,
+		KeepWeeklyDays: *backupRetentionKeepWeeklyDays,
+	}
+	return br.policy.Validate()
+}
+
+// Run is part of the Janitor interface.
+func (br *BackupRetention) Run(active bool) error {
+	bs, err := backupstorage.GetBackupStorage()
+	if err != nil {
+		return err
+	}
+	defer bs.Close()
+	dir := fmt.Sprintf("%v/%v", br.keyspace, br.shard)
+	_, err = mysqlctl.PruneBackups(bs, dir, br.policy, time.Now().UTC(), !active, br.wr.Logger())
+	return err
+}
+
+func init() {
+	Register("backup_retention", &BackupRetention{})
+}
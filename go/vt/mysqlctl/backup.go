@@ -450,6 +450,62 @@ func checkNoDB(ctx context.Context, mysqld MysqlDaemon) (bool, error) {
 	return true, nil
 }
 
+// ReadBackupManifest reads and decodes the MANIFEST of a backup.
+// It returns an error if the backup is incomplete.
+func ReadBackupManifest(bh backupstorage.BackupHandle) (*BackupManifest, error) {
+	rc, err := bh.ReadFile(backupManifest)
+	if err != nil {
+		return nil, fmt.Errorf("can't read MANIFEST: %v", err)
+	}
+	defer rc.Close()
+
+	bm := &BackupManifest{}
+	if err := json.NewDecoder(rc).Decode(bm); err != nil {
+		return nil, fmt.Errorf("cannot JSON decode MANIFEST: %v", err)
+	}
+	return bm, nil
+}
+
+// VerifyBackupFiles reads all the files of a backup from the
+// BackupStorage, and checks their hashes match the ones recorded in
+// the MANIFEST. Nothing is written to disk.
+func VerifyBackupFiles(bh backupstorage.BackupHandle, bm *BackupManifest, verifyConcurrency int) error {
+	sema := sync2.NewSemaphore(verifyConcurrency, 0)
+	rec := concurrency.AllErrorRecorder{}
+	wg := sync.WaitGroup{}
+	for i, fe := range bm.FileEntries {
+		wg.Add(1)
+		go func(i int, fe FileEntry) {
+			defer wg.Done()
+
+			sema.Acquire()
+			defer sema.Release()
+			if rec.HasErrors() {
+				return
+			}
+
+			name := fmt.Sprintf("%v", i)
+			source, err := bh.ReadFile(name)
+			if err != nil {
+				rec.RecordError(err)
+				return
+			}
+			defer source.Close()
+
+			hasher := newHasher()
+			if _, err := io.Copy(hasher, source); err != nil {
+				rec.RecordError(fmt.Errorf("cannot read %v: %v", fe.Name, err))
+				return
+			}
+			if hash := hasher.HashString(); hash != fe.Hash {
+				rec.RecordError(fmt.Errorf("hash mismatch for %v, got %v expected %v", fe.Name, hash, fe.Hash))
+			}
+		}(i, fe)
+	}
+	wg.Wait()
+	return rec.Error()
+}
+
 // restoreFiles will copy all the files from the BackupStorage to the
 // right place
 func restoreFiles(cnf *Mycnf, bh backupstorage.BackupHandle, fes []FileEntry, restoreConcurrency int) error {
@@ -599,20 +655,13 @@ func Restore(
 		return replication.Position{}, fmt.Errorf("ListBackups failed: %v", err)
 	}
 	var bh backupstorage.BackupHandle
-	var bm BackupManifest
+	var bm *BackupManifest
 	var toRestore int
 	for toRestore = len(bhs) - 1; toRestore >= 0; toRestore-- {
 		bh = bhs[toRestore]
-		rc, err := bh.ReadFile(backupManifest)
+		bm, err = ReadBackupManifest(bh)
 		if err != nil {
-			log.Warningf("Possibly incomplete backup %v in directory %v on BackupStorage: can't read MANIFEST: %v)", bh.Name(), dir, err)
-			continue
-		}
-
-		err = json.NewDecoder(rc).Decode(&bm)
-		rc.Close()
-		if err != nil {
-			log.Warningf("Possibly incomplete backup %v in directory %v on BackupStorage (cannot JSON decode MANIFEST: %v)", bh.Name(), dir, err)
+			log.Warningf("Possibly incomplete backup %v in directory %v on BackupStorage: %v", bh.Name(), dir, err)
 			continue
 		}
 
@@ -642,26 +691,53 @@ func Restore(
 		}
 	}
 
-	logger.Infof("Restore: shutdown mysqld")
-	err = mysqld.Shutdown(ctx, true)
+	if err := restoreBackup(ctx, mysqld, bh, bm, restoreConcurrency, localMetadata, logger); err != nil {
+		return replication.Position{}, err
+	}
+	return bm.Position, nil
+}
+
+// RestoreBackup restores the given backup into mysqld, regardless of
+// what mysqld currently contains, and returns the replication position
+// the backup was taken at. Unlike Restore, it does not pick the backup,
+// does not check for existing data and does not start replication.
+// It is meant to restore a backup into a scratch mysqld, e.g. to
+// verify the backup can be restored.
+func RestoreBackup(ctx context.Context, mysqld MysqlDaemon, bh backupstorage.BackupHandle, restoreConcurrency int, logger logutil.Logger) (replication.Position, error) {
+	bm, err := ReadBackupManifest(bh)
 	if err != nil {
 		return replication.Position{}, err
 	}
+	logger.Infof("RestoreBackup: restoring backup %v %v with %v files", bh.Directory(), bh.Name(), len(bm.FileEntries))
+	if err := restoreBackup(ctx, mysqld, bh, bm, restoreConcurrency, nil, logger); err != nil {
+		return replication.Position{}, err
+	}
+	return bm.Position, nil
+}
+
+// restoreBackup replaces the data of mysqld with the contents of the
+// backup, and restarts mysqld.
+func restoreBackup(ctx context.Context, mysqld MysqlDaemon, bh backupstorage.BackupHandle, bm *BackupManifest, restoreConcurrency int, localMetadata map[string]string, logger logutil.Logger) error {
+	logger.Infof("Restore: shutdown mysqld")
+	err := mysqld.Shutdown(ctx, true)
+	if err != nil {
+		return err
+	}
 
 	logger.Infof("Restore: deleting existing files")
 	if err := removeExistingFiles(mysqld.Cnf()); err != nil {
-		return replication.Position{}, err
+		return err
 	}
 
 	logger.Infof("Restore: reinit config file")
 	err = mysqld.ReinitConfig(ctx)
 	if err != nil {
-		return replication.Position{}, err
+		return err
 	}
 
 	logger.Infof("Restore: copying all files")
 	if err := restoreFiles(mysqld.Cnf(), bh, bm.FileEntries, restoreConcurrency); err != nil {
-		return replication.Position{}, err
+		return err
 	}
 
 	// mysqld needs to be running in order for mysql_upgrade to work.
@@ -674,12 +750,12 @@ func Restore(
 	logger.Infof("Restore: starting mysqld for mysql_upgrade")
 	err = mysqld.Start(ctx, "--skip-grant-tables", "--skip-networking")
 	if err != nil {
-		return replication.Position{}, err
+		return err
 	}
 
 	logger.Infof("Restore: running mysql_upgrade")
 	if err := mysqld.RunMysqlUpgrade(); err != nil {
-		return replication.Position{}, fmt.Errorf("mysql_upgrade failed: %v", err)
+		return fmt.Errorf("mysql_upgrade failed: %v", err)
 	}
 
 	// Populate local_metadata before starting without --skip-networking,
@@ -687,7 +763,7 @@ func Restore(
 	logger.Infof("Restore: populating local_metadata")
 	err = populateMetadataTables(mysqld, localMetadata)
 	if err != nil {
-		return replication.Position{}, err
+		return err
 	}
 
 	// The MySQL manual recommends restarting mysqld after running mysql_upgrade,
@@ -695,12 +771,12 @@ func Restore(
 	logger.Infof("Restore: restarting mysqld after mysql_upgrade")
 	err = mysqld.Shutdown(ctx, true)
 	if err != nil {
-		return replication.Position{}, err
+		return err
 	}
 	err = mysqld.Start(ctx)
 	if err != nil {
-		return replication.Position{}, err
+		return err
 	}
 
-	return bm.Position, nil
+	return nil
 }
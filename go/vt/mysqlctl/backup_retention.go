@@ -0,0 +1,226 @@
+// Copyright 2016, Google Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mysqlctl
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/youtube/vitess/go/vt/logutil"
+	"github.com/youtube/vitess/go/vt/mysqlctl/backupstorage"
+)
+
+// This file contains the backup retention engine: it decides which
+// backups of a shard can be removed from the BackupStorage according
+// to a BackupRetentionPolicy, and removes them.
+
+const (
+	// BackupTimestampFormat is the format of the timestamp that
+	// prefixes backup names (see tabletmanager's Backup RPC).
+	BackupTimestampFormat = "2006-01-02.150405"
+)
+
+// ErrEmptyRetentionPolicy is returned when a BackupRetentionPolicy
+// would only keep the most recent backup. That is almost certainly
+// a configuration mistake, so we refuse to apply it.
+var ErrEmptyRetentionPolicy = errors.New("backup retention policy keeps nothing: at least one of keep_last, keep_daily_days or keep_weekly_days must be set")
+
+// BackupRetentionPolicy describes which backups of a shard to keep.
+// A backup is kept if any of the rules selects it. In addition, the
+// most recent restorable backup is always kept.
+type BackupRetentionPolicy struct {
+	// KeepLast is the number of most recent restorable backups to keep.
+	KeepLast int
+
+	// KeepDailyDays keeps the most recent restorable backup of
+	// each day, for that many days.
+	KeepDailyDays int
+
+	// KeepWeeklyDays keeps the most recent restorable backup of
+	// each week, for that many days.
+	KeepWeeklyDays int
+}
+
+// Validate returns an error if the policy cannot be applied.
+func (p *BackupRetentionPolicy) Validate() error {
+	if p.KeepLast < 0 || p.KeepDailyDays < 0 || p.KeepWeeklyDays < 0 {
+		return fmt.Errorf("backup retention policy values cannot be negative: %+v", *p)
+	}
+	if p.KeepLast == 0 && p.KeepDailyDays == 0 && p.KeepWeeklyDays == 0 {
+		return ErrEmptyRetentionPolicy
+	}
+	return nil
+}
+
+// String returns a human readable version of the policy.
+func (p *BackupRetentionPolicy) String() string {
+	return fmt.Sprintf("keep_last=%v keep_daily_days=%v keep_weekly_days=%v", p.KeepLast, p.KeepDailyDays, p.KeepWeeklyDays)
+}
+
+// BackupInfo is what the retention engine knows about a backup.
+type BackupInfo struct {
+	// Name is the name of the backup in the BackupStorage.
+	Name string
+
+	// Time is the time the backup was started, parsed from its name.
+	// It is the zero value if the name could not be parsed.
+	Time time.Time
+
+	// Restorable is true if the backup has a valid MANIFEST.
+	Restorable bool
+}
+
+// ParseBackupTime returns the time encoded at the start of a backup
+// name, e.g. "2016-08-24.101535.cell-0000000100".
+func ParseBackupTime(name string) (time.Time, error) {
+	if len(name) < len(BackupTimestampFormat) {
+		return time.Time{}, fmt.Errorf("backup name %v is too short to contain a timestamp", name)
+	}
+	return time.Parse(BackupTimestampFormat, name[:len(BackupTimestampFormat)])
+}
+
+// readBackupInfo fills in a BackupInfo for the given handle, reading
+// its MANIFEST to check that it can be restored.
+func readBackupInfo(bh backupstorage.BackupHandle) BackupInfo {
+	bi := BackupInfo{
+		Name: bh.Name(),
+	}
+	if t, err := ParseBackupTime(bh.Name()); err == nil {
+		bi.Time = t
+	}
+
+	if _, err := ReadBackupManifest(bh); err == nil {
+		bi.Restorable = true
+	}
+	return bi
+}
+
+// SelectBackupsToRemove applies the policy to the list of backups,
+// and returns the names of the backups that can be removed, oldest
+// first. A backup whose name cannot be parsed is never removed, and
+// neither is the most recent restorable backup. Non-restorable backups
+// more recent than that one are kept too, as they may still be in
+// progress, while older non-restorable backups are removed. Other
+// restorable backups are kept if any rule of the policy selects them.
+func SelectBackupsToRemove(backups []BackupInfo, policy BackupRetentionPolicy, now time.Time) []string {
+	// Work on a copy sorted by time, most recent first.
+	sorted := make([]BackupInfo, 0, len(backups))
+	for _, bi := range backups {
+		if bi.Time.IsZero() {
+			continue
+		}
+		sorted = append(sorted, bi)
+	}
+	sort.Sort(sort.Reverse(byBackupTime(sorted)))
+
+	keep := make(map[string]bool)
+	restorableSeen := 0
+	days := make(map[string]bool)
+	weeks := make(map[string]bool)
+	dailyCutoff := now.AddDate(0, 0, -policy.KeepDailyDays)
+	weeklyCutoff := now.AddDate(0, 0, -policy.KeepWeeklyDays)
+	var toRemove []string
+	for _, bi := range sorted {
+		if !bi.Restorable {
+			if restorableSeen == 0 {
+				keep[bi.Name] = true
+			} else {
+				toRemove = append(toRemove, bi.Name)
+			}
+			continue
+		}
+
+		restorableSeen++
+		if restorableSeen == 1 || restorableSeen <= policy.KeepLast {
+			keep[bi.Name] = true
+		}
+		if policy.KeepDailyDays > 0 && bi.Time.After(dailyCutoff) {
+			day := bi.Time.Format("2006-01-02")
+			if !days[day] {
+				days[day] = true
+				keep[bi.Name] = true
+			}
+		}
+		if policy.KeepWeeklyDays > 0 && bi.Time.After(weeklyCutoff) {
+			year, week := bi.Time.ISOWeek()
+			w := fmt.Sprintf("%v-%v", year, week)
+			if !weeks[w] {
+				weeks[w] = true
+				keep[bi.Name] = true
+			}
+		}
+		if !keep[bi.Name] {
+			toRemove = append(toRemove, bi.Name)
+		}
+	}
+
+	// Return the oldest backups first.
+	for i, j := 0, len(toRemove)-1; i < j; i, j = i+1, j-1 {
+		toRemove[i], toRemove[j] = toRemove[j], toRemove[i]
+	}
+	return toRemove
+}
+
+type byBackupTime []BackupInfo
+
+func (b byBackupTime) Len() int           { return len(b) }
+func (b byBackupTime) Swap(i, j int)      { b[i], b[j] = b[j], b[i] }
+func (b byBackupTime) Less(i, j int) bool { return b[i].Time.Before(b[j].Time) }
+
+// PruneBackups applies the retention policy to the backups in dir,
+// and removes the backups that are not retained. If dryRun is set,
+// it only logs what would be removed. It returns the names of the
+// removed backups (or the ones that would be removed, in dry-run mode).
+func PruneBackups(bs backupstorage.BackupStorage, dir string, policy BackupRetentionPolicy, now time.Time, dryRun bool, logger logutil.Logger) ([]string, error) {
+	if err := policy.Validate(); err != nil {
+		return nil, err
+	}
+	bhs, err := bs.ListBackups(dir)
+	if err != nil {
+		return nil, fmt.Errorf("ListBackups failed: %v", err)
+	}
+
+	backups := make([]BackupInfo, 0, len(bhs))
+	for _, bh := range bhs {
+		bi := readBackupInfo(bh)
+		if bi.Time.IsZero() {
+			logger.Warningf("PruneBackups: cannot parse timestamp of backup %v in directory %v, it will be kept", bi.Name, dir)
+		} else if !bi.Restorable {
+			logger.Warningf("PruneBackups: backup %v in directory %v has no valid MANIFEST", bi.Name, dir)
+		}
+		backups = append(backups, bi)
+	}
+
+	toRemove := SelectBackupsToRemove(backups, policy, now)
+	if len(toRemove) == 0 {
+		logger.Infof("PruneBackups: nothing to remove in directory %v (%v backups, policy: %v)", dir, len(backups), &policy)
+		return nil, nil
+	}
+
+	if dryRun {
+		for _, name := range toRemove {
+			logger.Infof("PruneBackups: would remove backup %v in directory %v (dry run)", name, dir)
+		}
+		return toRemove, nil
+	}
+
+	var removed []string
+	var errs []string
+	for _, name := range toRemove {
+		logger.Infof("PruneBackups: removing backup %v in directory %v", name, dir)
+		if err := bs.RemoveBackup(dir, name); err != nil {
+			errs = append(errs, fmt.Sprintf("cannot remove backup %v: %v", name, err))
+			continue
+		}
+		removed = append(removed, name)
+	}
+	if len(errs) > 0 {
+		return removed, fmt.Errorf("PruneBackups failed in directory %v: %v", dir, strings.Join(errs, ", "))
+	}
+	return removed, nil
+}
@@ -0,0 +1,218 @@
+// Copyright 2016, Google Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mysqlctl
+
+import (
+	"io/ioutil"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/youtube/vitess/go/vt/logutil"
+	"github.com/youtube/vitess/go/vt/mysqlctl/filebackupstorage"
+)
+
+func newTestBackupInfo(t *testing.T, name string, restorable bool) BackupInfo {
+	bt, err := ParseBackupTime(name)
+	if err != nil {
+		t.Fatalf("ParseBackupTime(%v) failed: %v", name, err)
+	}
+	return BackupInfo{
+		Name:       name,
+		Time:       bt,
+		Restorable: restorable,
+	}
+}
+
+func TestParseBackupTime(t *testing.T) {
+	got, err := ParseBackupTime("2016-08-24.101535.cell-0000000100")
+	if err != nil {
+		t.Fatalf("ParseBackupTime failed: %v", err)
+	}
+	if want := time.Date(2016, 8, 24, 10, 15, 35, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("ParseBackupTime: got %v, want %v", got, want)
+	}
+
+	for _, name := range []string{"", "short", "not-a-backup-name-at-all"} {
+		if _, err := ParseBackupTime(name); err == nil {
+			t.Errorf("ParseBackupTime(%v) should have failed", name)
+		}
+	}
+}
+
+func TestBackupRetentionPolicyValidate(t *testing.T) {
+	if err := (&BackupRetentionPolicy{}).Validate(); err != ErrEmptyRetentionPolicy {
+		t.Errorf("empty policy: got %v, want %v", err, ErrEmptyRetentionPolicy)
+	}
+	if err := (&BackupRetentionPolicy{KeepLast: -1, KeepDailyDays: 3}).Validate(); err == nil {
+		t.Errorf("negative policy should fail")
+	}
+	if err := (&BackupRetentionPolicy{KeepWeeklyDays: 30}).Validate(); err != nil {
+		t.Errorf("valid policy failed: %v", err)
+	}
+}
+
+func TestSelectBackupsToRemove(t *testing.T) {
+	now := time.Date(2016, 8, 24, 12, 0, 0, 0, time.UTC)
+	testcases := []struct {
+		desc    string
+		backups []BackupInfo
+		policy  BackupRetentionPolicy
+		want    []string
+	}{{
+		desc: "keep last",
+		backups: []BackupInfo{
+			newTestBackupInfo(t, "2016-08-20.100000.cell-0000000100", true),
+			newTestBackupInfo(t, "2016-08-21.100000.cell-0000000100", true),
+			newTestBackupInfo(t, "2016-08-22.100000.cell-0000000100", true),
+			newTestBackupInfo(t, "2016-08-23.100000.cell-0000000100", true),
+		},
+		policy: BackupRetentionPolicy{KeepLast: 2},
+		want: []string{
+			"2016-08-20.100000.cell-0000000100",
+			"2016-08-21.100000.cell-0000000100",
+		},
+	}, {
+		desc: "keep daily",
+		backups: []BackupInfo{
+			newTestBackupInfo(t, "2016-08-10.100000.cell-0000000100", true),
+			newTestBackupInfo(t, "2016-08-22.100000.cell-0000000100", true),
+			newTestBackupInfo(t, "2016-08-22.200000.cell-0000000100", true),
+			newTestBackupInfo(t, "2016-08-23.100000.cell-0000000100", true),
+			newTestBackupInfo(t, "2016-08-24.080000.cell-0000000100", true),
+			newTestBackupInfo(t, "2016-08-24.100000.cell-0000000100", true),
+		},
+		policy: BackupRetentionPolicy{KeepDailyDays: 7},
+		want: []string{
+			"2016-08-10.100000.cell-0000000100",
+			"2016-08-22.100000.cell-0000000100",
+			"2016-08-24.080000.cell-0000000100",
+		},
+	}, {
+		desc: "keep weekly",
+		backups: []BackupInfo{
+			newTestBackupInfo(t, "2016-06-01.100000.cell-0000000100", true),
+			// Week 31.
+			newTestBackupInfo(t, "2016-08-02.100000.cell-0000000100", true),
+			newTestBackupInfo(t, "2016-08-03.100000.cell-0000000100", true),
+			// Week 32.
+			newTestBackupInfo(t, "2016-08-10.100000.cell-0000000100", true),
+			// Week 34.
+			newTestBackupInfo(t, "2016-08-22.100000.cell-0000000100", true),
+			newTestBackupInfo(t, "2016-08-23.100000.cell-0000000100", true),
+		},
+		policy: BackupRetentionPolicy{KeepWeeklyDays: 28},
+		want: []string{
+			"2016-06-01.100000.cell-0000000100",
+			"2016-08-02.100000.cell-0000000100",
+			"2016-08-22.100000.cell-0000000100",
+		},
+	}, {
+		desc: "never remove the only restorable backup",
+		backups: []BackupInfo{
+			newTestBackupInfo(t, "2016-01-01.100000.cell-0000000100", true),
+			newTestBackupInfo(t, "2016-08-23.100000.cell-0000000100", false),
+		},
+		policy: BackupRetentionPolicy{KeepDailyDays: 1},
+		want:   nil,
+	}, {
+		desc: "incomplete backups",
+		backups: []BackupInfo{
+			newTestBackupInfo(t, "2016-08-21.100000.cell-0000000100", false),
+			newTestBackupInfo(t, "2016-08-22.100000.cell-0000000100", true),
+			newTestBackupInfo(t, "2016-08-23.100000.cell-0000000100", true),
+			newTestBackupInfo(t, "2016-08-24.100000.cell-0000000100", false),
+		},
+		policy: BackupRetentionPolicy{KeepLast: 2},
+		want: []string{
+			"2016-08-21.100000.cell-0000000100",
+		},
+	}, {
+		desc: "unparseable names are kept",
+		backups: []BackupInfo{
+			{Name: "manual-backup", Restorable: true},
+			newTestBackupInfo(t, "2016-08-22.100000.cell-0000000100", true),
+			newTestBackupInfo(t, "2016-08-23.100000.cell-0000000100", true),
+		},
+		policy: BackupRetentionPolicy{KeepLast: 1},
+		want: []string{
+			"2016-08-22.100000.cell-0000000100",
+		},
+	}}
+	for _, tc := range testcases {
+		got := SelectBackupsToRemove(tc.backups, tc.policy, now)
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%v: got %v, want %v", tc.desc, got, tc.want)
+		}
+	}
+}
+
+func TestPruneBackups(t *testing.T) {
+	root, err := ioutil.TempDir("", "backupretentiontest")
+	if err != nil {
+		t.Fatalf("os.TempDir failed: %v", err)
+	}
+	defer os.RemoveAll(root)
+	*filebackupstorage.FileBackupStorageRoot = root
+	fbs := &filebackupstorage.FileBackupStorage{}
+
+	dir := "ks/0"
+	for _, name := range []string{
+		"2016-08-21.100000.cell-0000000100",
+		"2016-08-22.100000.cell-0000000100",
+		"2016-08-23.100000.cell-0000000100",
+	} {
+		bh, err := fbs.StartBackup(dir, name)
+		if err != nil {
+			t.Fatalf("StartBackup failed: %v", err)
+		}
+		wc, err := bh.AddFile(backupManifest)
+		if err != nil {
+			t.Fatalf("AddFile failed: %v", err)
+		}
+		if _, err := wc.Write([]byte("{}")); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+		wc.Close()
+		if err := bh.EndBackup(); err != nil {
+			t.Fatalf("EndBackup failed: %v", err)
+		}
+	}
+
+	now := time.Date(2016, 8, 24, 12, 0, 0, 0, time.UTC)
+	policy := BackupRetentionPolicy{KeepLast: 1}
+	want := []string{
+		"2016-08-21.100000.cell-0000000100",
+		"2016-08-22.100000.cell-0000000100",
+	}
+
+	// Dry run doesn't remove anything.
+	logger := logutil.NewMemoryLogger()
+	removed, err := PruneBackups(fbs, dir, policy, now, true /* dryRun */, logger)
+	if err != nil {
+		t.Fatalf("PruneBackups(dryRun) failed: %v", err)
+	}
+	if !reflect.DeepEqual(removed, want) {
+		t.Errorf("PruneBackups(dryRun): got %v, want %v", removed, want)
+	}
+	bhs, err := fbs.ListBackups(dir)
+	if err != nil || len(bhs) != 3 {
+		t.Fatalf("ListBackups after dry run: got %v %v, want 3 backups", len(bhs), err)
+	}
+
+	// Real run.
+	removed, err = PruneBackups(fbs, dir, policy, now, false /* dryRun */, logger)
+	if err != nil {
+		t.Fatalf("PruneBackups failed: %v", err)
+	}
+	if !reflect.DeepEqual(removed, want) {
+		t.Errorf("PruneBackups: got %v, want %v", removed, want)
+	}
+	bhs, err = fbs.ListBackups(dir)
+	if err != nil || len(bhs) != 1 || bhs[0].Name() != "2016-08-23.100000.cell-0000000100" {
+		t.Fatalf("ListBackups after PruneBackups: got %v %v", bhs, err)
+	}
+}
@@ -5,12 +5,18 @@
 package mysqlctl
 
 import (
+	"encoding/json"
+	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
 	"path"
 	"reflect"
 	"sort"
+	"strings"
 	"testing"
+
+	"github.com/youtube/vitess/go/vt/mysqlctl/filebackupstorage"
 )
 
 func TestFindFilesToBackup(t *testing.T) {
@@ -91,3 +97,68 @@ type forTest []FileEntry
 func (f forTest) Len() int           { return len(f) }
 func (f forTest) Swap(i, j int)      { f[i], f[j] = f[j], f[i] }
 func (f forTest) Less(i, j int) bool { return f[i].Base+f[i].Name < f[j].Base+f[j].Name }
+
+func TestVerifyBackupFiles(t *testing.T) {
+	root, err := ioutil.TempDir("", "verifybackuptest")
+	if err != nil {
+		t.Fatalf("os.TempDir failed: %v", err)
+	}
+	defer os.RemoveAll(root)
+	*filebackupstorage.FileBackupStorageRoot = root
+	fbs := &filebackupstorage.FileBackupStorage{}
+
+	bh, err := fbs.StartBackup("ks/0", "2016-08-24.101535.cell-0000000100")
+	if err != nil {
+		t.Fatalf("StartBackup failed: %v", err)
+	}
+	bm := &BackupManifest{}
+	for i, content := range []string{"first file", "second file"} {
+		wc, err := bh.AddFile(fmt.Sprintf("%v", i))
+		if err != nil {
+			t.Fatalf("AddFile failed: %v", err)
+		}
+		hasher := newHasher()
+		if _, err := io.Copy(wc, io.TeeReader(strings.NewReader(content), hasher)); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+		wc.Close()
+		bm.FileEntries = append(bm.FileEntries, FileEntry{
+			Base: backupData,
+			Name: fmt.Sprintf("file%v", i),
+			Hash: hasher.HashString(),
+		})
+	}
+	wc, err := bh.AddFile(backupManifest)
+	if err != nil {
+		t.Fatalf("AddFile failed: %v", err)
+	}
+	if err := json.NewEncoder(wc).Encode(bm); err != nil {
+		t.Fatalf("Encode failed: %v", err)
+	}
+	wc.Close()
+	if err := bh.EndBackup(); err != nil {
+		t.Fatalf("EndBackup failed: %v", err)
+	}
+	bhs, err := fbs.ListBackups("ks/0")
+	if err != nil || len(bhs) != 1 {
+		t.Fatalf("ListBackups failed: %v %v", bhs, err)
+	}
+	bh = bhs[0]
+
+	got, err := ReadBackupManifest(bh)
+	if err != nil {
+		t.Fatalf("ReadBackupManifest failed: %v", err)
+	}
+	if !reflect.DeepEqual(got, bm) {
+		t.Errorf("ReadBackupManifest: got %v, want %v", got, bm)
+	}
+	if err := VerifyBackupFiles(bh, got, 2); err != nil {
+		t.Errorf("VerifyBackupFiles failed: %v", err)
+	}
+
+	// A corrupted file is detected.
+	got.FileEntries[1].Hash = "bad"
+	if err := VerifyBackupFiles(bh, got, 2); err == nil || !strings.Contains(err.Error(), "hash mismatch for file1") {
+		t.Errorf("VerifyBackupFiles with a bad hash: got %v", err)
+	}
+}
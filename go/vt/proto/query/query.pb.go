@@ -1218,6 +1218,16 @@ type RealtimeStats struct {
 	// qps is the average QPS (queries per second) rate in the last XX seconds
 	// where XX is usually 60 (See query_service_stats.go).
 	Qps float64 `protobuf:"fixed64,6,opt,name=qps" json:"qps,omitempty"`
+	// table_schema_changed lists the tables whose schema changed since the
+	// last health message, as seen by the tablet when reloading its schema
+	// or applying a DDL. It is only set in the message sent right after
+	// the change, so clients can reload their copy of the schema.
+	TableSchemaChanged []string `protobuf:"bytes,7,rep,name=table_schema_changed,json=tableSchemaChanged" json:"table_schema_changed,omitempty"`
+	// schema_version is incremented every time table_schema_changed is
+	// sent, and is set in all the health messages. A client that sees it
+	// change by more than one missed a change, and must reload the whole
+	// schema. It is reset when the tablet restarts.
+	SchemaVersion int64 `protobuf:"varint,8,opt,name=schema_version,json=schemaVersion" json:"schema_version,omitempty"`
 }
 
 func (m *RealtimeStats) Reset()                    { *m = RealtimeStats{} }
@@ -1368,134 +1378,135 @@ func init() {
 func init() { proto.RegisterFile("query.proto", fileDescriptor0) }
 
 var fileDescriptor0 = []byte{
-	// 2056 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0xec, 0x59, 0x5b, 0x6f, 0x1b, 0xc7,
-	0x15, 0xf6, 0x2e, 0xef, 0x87, 0xa2, 0x3c, 0x1a, 0xca, 0x2d, 0xab, 0xb8, 0x8d, 0xbb, 0x89, 0x13,
-	0xd5, 0x36, 0x58, 0x97, 0x56, 0x5d, 0x23, 0x4d, 0x5b, 0x93, 0x12, 0xe5, 0x10, 0xa1, 0x68, 0x7a,
-	0xb8, 0x14, 0xea, 0x22, 0xc0, 0x62, 0x45, 0x8e, 0xa4, 0x85, 0x96, 0xbb, 0xab, 0xdd, 0x59, 0xc9,
-	0x7c, 0x53, 0xd3, 0xfb, 0x3d, 0x45, 0x2f, 0xe9, 0x05, 0x48, 0x0b, 0xf4, 0x27, 0xf4, 0xb9, 0x40,
-	0xd1, 0x1f, 0xd0, 0x7f, 0x51, 0x14, 0x7d, 0x6a, 0x7f, 0x41, 0x51, 0xcc, 0xec, 0xec, 0x72, 0x29,
-	0xd3, 0xb1, 0x93, 0x37, 0xa7, 0x79, 0xd2, 0xcc, 0x39, 0xdf, 0x9c, 0xfb, 0x39, 0xc3, 0x1d, 0x41,
-	0xf9, 0x38, 0xa4, 0xfe, 0xb4, 0xee, 0xf9, 0x2e, 0x73, 0x71, 0x4e, 0x6c, 0xd6, 0x96, 0x99, 0xeb,
-	0xb9, 0x63, 0x93, 0x99, 0x11, 0x79, 0xad, 0x7c, 0xc2, 0x7c, 0x6f, 0x14, 0x6d, 0xb4, 0x63, 0xc8,
-	0xeb, 0xa6, 0x7f, 0x40, 0x19, 0x5e, 0x83, 0xe2, 0x11, 0x9d, 0x06, 0x9e, 0x39, 0xa2, 0x35, 0xe5,
-	0x8a, 0xb2, 0x5e, 0x22, 0xc9, 0x1e, 0xaf, 0x42, 0x2e, 0x38, 0x34, 0xfd, 0x71, 0x4d, 0x15, 0x8c,
-	0x68, 0x83, 0xbf, 0x08, 0x65, 0x66, 0xee, 0xd9, 0x94, 0x19, 0x6c, 0xea, 0xd1, 0x5a, 0xe6, 0x8a,
-	0xb2, 0xbe, 0xdc, 0x58, 0xad, 0x27, 0xea, 0x74, 0xc1, 0xd4, 0xa7, 0x1e, 0x25, 0xc0, 0x92, 0xb5,
-	0x76, 0x03, 0x96, 0x77, 0xf5, 0x7b, 0x26, 0xa3, 0x9b, 0xa6, 0x6d, 0x53, 0xbf, 0xb3, 0xc5, 0x55,
-	0x87, 0x01, 0xf5, 0x1d, 0x73, 0x92, 0xa8, 0x8e, 0xf7, 0xda, 0x5b, 0x00, 0xed, 0x13, 0xea, 0x30,
-	0xdd, 0x3d, 0xa2, 0x0e, 0xbe, 0x0c, 0x25, 0x66, 0x4d, 0x68, 0xc0, 0xcc, 0x89, 0x27, 0xa0, 0x19,
-	0x32, 0x23, 0x3c, 0xc1, 0xcc, 0x35, 0x28, 0x7a, 0x6e, 0x60, 0x31, 0xcb, 0x75, 0x84, 0x8d, 0x25,
-	0x92, 0xec, 0xb5, 0xaf, 0x42, 0x6e, 0xd7, 0xb4, 0x43, 0x8a, 0x5f, 0x84, 0xac, 0x70, 0x42, 0x11,
-	0x4e, 0x94, 0xeb, 0x51, 0x1c, 0x85, 0xed, 0x82, 0xc1, 0x65, 0x9f, 0x70, 0xa4, 0x90, 0xbd, 0x44,
-	0xa2, 0x8d, 0x76, 0x04, 0x4b, 0x2d, 0xcb, 0x19, 0xef, 0x9a, 0xbe, 0xc5, 0x1d, 0xfc, 0x90, 0x62,
-	0xf0, 0xcb, 0x90, 0x17, 0x8b, 0xa0, 0x96, 0xb9, 0x92, 0x59, 0x2f, 0x37, 0x96, 0xe4, 0x41, 0x61,
-	0x1b, 0x91, 0x3c, 0xed, 0x6f, 0x0a, 0x40, 0xcb, 0x0d, 0x9d, 0xf1, 0x03, 0xce, 0xc4, 0x08, 0x32,
-	0xc1, 0xb1, 0x2d, 0x03, 0xc6, 0x97, 0xf8, 0x4d, 0x58, 0xde, 0xb3, 0x9c, 0xb1, 0x71, 0x22, 0xcd,
-	0x09, 0x6a, 0xaa, 0x10, 0xf7, 0xb2, 0x14, 0x37, 0x3b, 0x5c, 0x4f, 0x5b, 0x1d, 0xb4, 0x1d, 0xe6,
-	0x4f, 0x49, 0x65, 0x2f, 0x4d, 0x5b, 0x1b, 0x02, 0x7e, 0x1c, 0xc4, 0x95, 0x1e, 0xd1, 0x69, 0xac,
-	0xf4, 0x88, 0x4e, 0xf1, 0xe7, 0xd2, 0x1e, 0x95, 0x1b, 0xd5, 0x58, 0x57, 0xea, 0xac, 0x74, 0xf3,
-	0x35, 0xf5, 0x8e, 0xa2, 0xfd, 0x59, 0x81, 0xe5, 0xf6, 0x23, 0x3a, 0x0a, 0x19, 0xbd, 0xef, 0xf1,
-	0x1c, 0x04, 0xb8, 0x0e, 0x55, 0xfa, 0x68, 0x64, 0x87, 0x63, 0x6a, 0xec, 0x5b, 0xd4, 0x1e, 0x1b,
-	0x3c, 0xf1, 0x81, 0xd0, 0x51, 0x24, 0x2b, 0x92, 0xb5, 0xcd, 0x39, 0x3d, 0xce, 0xe0, 0x78, 0xcb,
-	0x89, 0xf0, 0x94, 0x97, 0x86, 0xc1, 0x78, 0x6d, 0x08, 0xfd, 0x45, 0xb2, 0x22, 0x59, 0xa9, 0xa2,
-	0x69, 0x42, 0x75, 0xe4, 0x4e, 0x3c, 0xd3, 0x9f, 0xc7, 0x67, 0x84, 0xbd, 0x2b, 0xd2, 0xde, 0x19,
-	0x9e, 0xac, 0x48, 0xf4, 0x8c, 0xa4, 0xbd, 0x0e, 0x39, 0x61, 0x00, 0xc6, 0x90, 0x4d, 0x95, 0xa9,
-	0x58, 0x27, 0x49, 0x57, 0x9f, 0x90, 0x74, 0xed, 0x4b, 0x90, 0x21, 0xee, 0x29, 0xae, 0x41, 0xc1,
-	0xa6, 0xce, 0x01, 0x3b, 0xe4, 0xbe, 0x65, 0xd6, 0x31, 0x89, 0xb7, 0xf8, 0x13, 0x49, 0xfe, 0xa3,
-	0xb2, 0x88, 0x33, 0xfe, 0x16, 0x2c, 0x11, 0x1a, 0x84, 0x36, 0x6b, 0x3f, 0x62, 0xbe, 0x19, 0xe0,
-	0x06, 0x94, 0xd3, 0x1e, 0x28, 0x4f, 0xf2, 0x00, 0xe8, 0xcc, 0xfb, 0x1a, 0x14, 0xf6, 0x7d, 0x1a,
-	0x1c, 0x52, 0x5f, 0x46, 0x28, 0xde, 0xf2, 0x7a, 0x2a, 0x8b, 0x6a, 0x88, 0x74, 0xf0, 0x2a, 0x14,
-	0xf1, 0x8f, 0xcc, 0x9b, 0x55, 0xa1, 0xf0, 0x9c, 0x48, 0x1e, 0x7e, 0x09, 0x2a, 0xbe, 0x7b, 0x1a,
-	0x18, 0xe6, 0xfe, 0x3e, 0x1d, 0x31, 0x1a, 0x35, 0x5b, 0x96, 0x2c, 0x71, 0x62, 0x53, 0xd2, 0xf0,
-	0x0b, 0x50, 0xb2, 0x9c, 0x80, 0xfa, 0xcc, 0xb0, 0xc6, 0x22, 0xd0, 0x59, 0x52, 0x8c, 0x08, 0x9d,
-	0x31, 0xfe, 0x0c, 0x64, 0x39, 0xb8, 0x96, 0x15, 0x5a, 0x40, 0x6a, 0x21, 0xee, 0x29, 0x11, 0x74,
-	0x7c, 0x1d, 0xf2, 0x54, 0xf8, 0x5b, 0xcb, 0xcd, 0x95, 0x54, 0x3a, 0x14, 0x44, 0x42, 0xb4, 0x3f,
-	0x66, 0xa0, 0x3c, 0x60, 0x3e, 0x35, 0x27, 0xc2, 0x7f, 0xfc, 0x3a, 0x40, 0xc0, 0x4c, 0x46, 0x27,
-	0xd4, 0x61, 0xb1, 0x23, 0x97, 0xa5, 0x80, 0x14, 0xae, 0x3e, 0x88, 0x41, 0x24, 0x85, 0x3f, 0x1f,
-	0x60, 0xf5, 0x19, 0x02, 0xbc, 0xf6, 0x9e, 0x0a, 0xa5, 0x44, 0x1a, 0x6e, 0x42, 0x71, 0x64, 0x32,
-	0x7a, 0xe0, 0xfa, 0x53, 0x39, 0x05, 0xae, 0xbe, 0x9f, 0xf6, 0xfa, 0xa6, 0x04, 0x93, 0xe4, 0x18,
-	0xfe, 0x34, 0x44, 0xe3, 0x52, 0xf4, 0x81, 0x9c, 0x65, 0x25, 0x41, 0xe1, 0xf5, 0x8f, 0x5f, 0x03,
-	0xec, 0xf9, 0xd6, 0xc4, 0xf4, 0xa7, 0xc6, 0x11, 0x9d, 0x1a, 0x32, 0x65, 0x99, 0x05, 0x29, 0x43,
-	0x12, 0xf7, 0x26, 0x9d, 0x6e, 0x47, 0xc9, 0xbb, 0x33, 0x7f, 0x56, 0x16, 0xdd, 0xe3, 0x89, 0x48,
-	0x9d, 0x14, 0x33, 0x28, 0x88, 0xa7, 0x4d, 0x4e, 0xd4, 0x27, 0x5f, 0x6a, 0xaf, 0x42, 0x31, 0x36,
-	0x1e, 0x97, 0x20, 0xd7, 0xf6, 0x7d, 0xd7, 0x47, 0x17, 0x70, 0x01, 0x32, 0x5b, 0x3b, 0x5d, 0xa4,
-	0x88, 0xc5, 0x56, 0x17, 0xa9, 0xda, 0x5f, 0xd5, 0xa4, 0xe5, 0x09, 0x3d, 0x0e, 0x69, 0xc0, 0xf0,
-	0xd7, 0xa0, 0x4a, 0x45, 0xad, 0x58, 0x27, 0xd4, 0x18, 0x89, 0x7b, 0x80, 0x57, 0x4a, 0x54, 0xd0,
-	0x17, 0xeb, 0xd1, 0x0d, 0x15, 0xdf, 0x0f, 0x64, 0x25, 0xc1, 0x4a, 0xd2, 0x18, 0xb7, 0xa1, 0x6a,
-	0x4d, 0x26, 0x74, 0x6c, 0x99, 0x2c, 0x2d, 0x20, 0x4a, 0xd8, 0xa5, 0x78, 0x7c, 0xce, 0x5d, 0x33,
-	0x64, 0x25, 0x39, 0x91, 0x88, 0xb9, 0x0a, 0x79, 0x26, 0xae, 0x3f, 0x39, 0x0d, 0x2a, 0x71, 0xf3,
-	0x0a, 0x22, 0x91, 0x4c, 0xfc, 0x2a, 0x44, 0x77, 0x69, 0x2d, 0x3b, 0x57, 0x10, 0xb3, 0x79, 0x4a,
-	0x22, 0x3e, 0xbe, 0x0a, 0xcb, 0xcc, 0x37, 0x9d, 0xc0, 0x1c, 0xf1, 0xd1, 0xc6, 0x2d, 0xca, 0x89,
-	0x4b, 0xaa, 0x92, 0xa2, 0x76, 0xc6, 0xf8, 0xf3, 0x50, 0x70, 0xa3, 0xe1, 0x57, 0xcb, 0xcf, 0x59,
-	0x3c, 0x3f, 0x19, 0x49, 0x8c, 0xd2, 0xbe, 0x02, 0x17, 0x93, 0x08, 0x06, 0x9e, 0xeb, 0x04, 0x14,
-	0x5f, 0x83, 0xbc, 0x2f, 0x1a, 0x42, 0x46, 0x0d, 0x4b, 0x11, 0xa9, 0x8e, 0x26, 0x12, 0xa1, 0xfd,
-	0x47, 0x85, 0xaa, 0x3c, 0xdf, 0x32, 0xd9, 0xe8, 0xf0, 0x39, 0x4d, 0xc3, 0x75, 0x28, 0x70, 0xba,
-	0x95, 0x94, 0xec, 0x82, 0x44, 0xc4, 0x08, 0x9e, 0x0a, 0x33, 0x30, 0x52, 0x71, 0x17, 0xa9, 0x28,
-	0x92, 0x8a, 0x19, 0xe8, 0x33, 0xe2, 0x82, 0x8c, 0xe5, 0x9f, 0x92, 0xb1, 0xc2, 0x33, 0x65, 0x6c,
-	0x0b, 0x56, 0xe7, 0x23, 0x2e, 0xd3, 0x76, 0x03, 0x0a, 0x51, 0x52, 0xe2, 0xe1, 0xb4, 0x28, 0x6f,
-	0x31, 0x44, 0xfb, 0x83, 0x0a, 0xab, 0x72, 0x6e, 0xfc, 0x7f, 0x34, 0x50, 0x2a, 0xce, 0xb9, 0x67,
-	0x8a, 0xf3, 0x26, 0x5c, 0x3a, 0x17, 0xa0, 0x0f, 0xd1, 0x1f, 0x7f, 0x51, 0x60, 0xa9, 0x45, 0x0f,
-	0x2c, 0xe7, 0xf9, 0x0c, 0xaf, 0x76, 0x1b, 0x2a, 0xd2, 0x7c, 0xe9, 0xfc, 0xe3, 0x55, 0xad, 0x2c,
-	0xa8, 0x6a, 0xed, 0x1f, 0x0a, 0x54, 0x36, 0xdd, 0xc9, 0xc4, 0x62, 0xcf, 0x69, 0x5d, 0x3d, 0xee,
-	0x67, 0x76, 0x91, 0x9f, 0x08, 0x96, 0x63, 0x37, 0xa3, 0x00, 0x69, 0xff, 0x54, 0xe0, 0x22, 0x71,
-	0x6d, 0x7b, 0xcf, 0x1c, 0x1d, 0x7d, 0xb4, 0x7d, 0xc7, 0x80, 0x66, 0x8e, 0x4a, 0xef, 0xdf, 0x53,
-	0xa1, 0x2a, 0x0a, 0xe6, 0xe3, 0xa9, 0xb2, 0x78, 0xaa, 0xbc, 0xa3, 0xc0, 0xea, 0x7c, 0x80, 0x92,
-	0xc6, 0xca, 0x51, 0xfe, 0x43, 0xe7, 0x5c, 0x4c, 0x48, 0x7f, 0x53, 0xfc, 0xfe, 0x21, 0x11, 0x37,
-	0x35, 0x7c, 0xd4, 0xa7, 0x0d, 0x9f, 0x05, 0x79, 0xcc, 0x2c, 0xca, 0xe3, 0xdf, 0x55, 0xa8, 0xa5,
-	0x4d, 0xfa, 0xf8, 0x22, 0x9f, 0xbf, 0xc8, 0x3f, 0xf0, 0x6f, 0xaa, 0x77, 0x15, 0xf8, 0xd4, 0x82,
-	0x80, 0x7e, 0xb0, 0x44, 0xa7, 0xae, 0x73, 0xf5, 0xa9, 0xd7, 0xf9, 0xb3, 0xa6, 0xfa, 0xed, 0x2c,
-	0xac, 0x0c, 0x3c, 0xdb, 0x62, 0x52, 0xc8, 0x47, 0xbb, 0x39, 0x3f, 0x0b, 0x4b, 0x01, 0x77, 0xd6,
-	0x18, 0xb9, 0x76, 0x38, 0xe1, 0xd9, 0xcd, 0xac, 0x97, 0x48, 0x59, 0xd0, 0x36, 0x05, 0x09, 0xbf,
-	0x08, 0xe5, 0x18, 0x12, 0x3a, 0x4c, 0xfe, 0x42, 0x03, 0x89, 0x08, 0x1d, 0x86, 0x37, 0xe0, 0x93,
-	0x4e, 0x38, 0x31, 0xc4, 0x87, 0xa9, 0x47, 0x7d, 0x43, 0x48, 0x36, 0x3c, 0xd3, 0x67, 0xb5, 0xa2,
-	0x00, 0x57, 0x9d, 0x70, 0x42, 0xdc, 0xd3, 0xa0, 0x4f, 0x7d, 0xa1, 0xbc, 0x6f, 0xfa, 0x0c, 0xdf,
-	0x85, 0x92, 0x69, 0x1f, 0xb8, 0xbe, 0xc5, 0x0e, 0x27, 0xb5, 0x92, 0xf8, 0x58, 0xd3, 0xe2, 0x8f,
-	0xb5, 0xf3, 0xe1, 0xaf, 0x37, 0x63, 0x24, 0x99, 0x1d, 0xc2, 0xd7, 0x01, 0x87, 0x01, 0x35, 0x22,
-	0xe3, 0x22, 0xa5, 0x27, 0x8d, 0x1a, 0x88, 0xfa, 0xbc, 0x18, 0x06, 0x74, 0x26, 0x66, 0xb7, 0xa1,
-	0xdd, 0x80, 0x52, 0x22, 0x04, 0x23, 0x58, 0x6a, 0x3f, 0x18, 0x36, 0xbb, 0xc6, 0xa0, 0xdf, 0xed,
-	0xe8, 0x03, 0x74, 0x01, 0x57, 0xa0, 0xb4, 0x3d, 0xec, 0x76, 0x8d, 0xc1, 0x66, 0xb3, 0x87, 0x14,
-	0x8d, 0x00, 0x88, 0x83, 0x42, 0xc4, 0x2c, 0x9a, 0xca, 0x53, 0xa2, 0xf9, 0x02, 0x94, 0x7c, 0xf7,
-	0x54, 0x06, 0x4a, 0x15, 0xbe, 0x17, 0x7d, 0xf7, 0x54, 0x84, 0x49, 0x6b, 0x02, 0x4e, 0x3b, 0x26,
-	0x4b, 0x3d, 0xd5, 0x8d, 0xca, 0x5c, 0x37, 0xce, 0xf4, 0x27, 0xdd, 0xa8, 0x5d, 0x82, 0x6a, 0xf4,
-	0x7b, 0xeb, 0x0d, 0x6a, 0xda, 0x2c, 0x1e, 0x40, 0xda, 0x9f, 0x54, 0xa8, 0x10, 0x4e, 0xb1, 0x26,
-	0x94, 0x7f, 0xdc, 0x06, 0x3c, 0xad, 0x87, 0x02, 0x62, 0xcc, 0xfa, 0xa8, 0x44, 0xca, 0x11, 0x4d,
-	0xf4, 0x10, 0x6e, 0xc0, 0xa5, 0x80, 0x8e, 0x5c, 0x67, 0x1c, 0x18, 0x7b, 0xf4, 0xd0, 0x72, 0xc6,
-	0xc6, 0xc4, 0x0c, 0x98, 0x7c, 0xa8, 0xa8, 0x90, 0xaa, 0x64, 0xb6, 0x04, 0x6f, 0x47, 0xb0, 0xf0,
-	0x4d, 0x58, 0xdd, 0xb3, 0x1c, 0xdb, 0x3d, 0x30, 0x3c, 0xdb, 0x9c, 0x52, 0x3f, 0x90, 0xae, 0xf2,
-	0x5a, 0xcc, 0x11, 0x1c, 0xf1, 0xfa, 0x11, 0x2b, 0xaa, 0x8d, 0x6f, 0xc0, 0xb5, 0x85, 0x5a, 0x8c,
-	0x7d, 0xcb, 0x66, 0xd4, 0xa7, 0x63, 0xc3, 0xa7, 0x9e, 0x6d, 0x8d, 0x4c, 0x31, 0x5b, 0xa2, 0x3b,
-	0xf4, 0x95, 0x05, 0xaa, 0xb7, 0x25, 0x9c, 0xcc, 0xd0, 0x3c, 0xda, 0x23, 0x2f, 0x34, 0xc2, 0xc0,
-	0x3c, 0xa0, 0x62, 0x2c, 0x29, 0xa4, 0x38, 0xf2, 0xc2, 0x21, 0xdf, 0xf3, 0x4f, 0xe6, 0x63, 0x2f,
-	0x9a, 0x46, 0x0a, 0xe1, 0x4b, 0xed, 0x5f, 0x4a, 0xfc, 0x73, 0x3e, 0x8e, 0x5e, 0x32, 0x6d, 0xe2,
-	0x9e, 0x52, 0xde, 0xaf, 0xa7, 0x6a, 0x50, 0x08, 0xa8, 0x7f, 0x62, 0x39, 0x07, 0xf1, 0x5b, 0x8e,
-	0xdc, 0xe2, 0x01, 0xbc, 0x22, 0xdf, 0x62, 0xe9, 0x23, 0x46, 0x7d, 0xc7, 0xb4, 0xed, 0x29, 0xf7,
-	0xcb, 0xf4, 0xa9, 0xc3, 0xe8, 0xd8, 0x98, 0xbd, 0x9a, 0x46, 0x13, 0xe7, 0xa5, 0x08, 0xdd, 0x4e,
-	0xc0, 0x24, 0xc1, 0xea, 0xc9, 0x7b, 0xea, 0x97, 0x61, 0xd9, 0x97, 0x39, 0x35, 0x02, 0x9e, 0x54,
-	0xd9, 0xcb, 0xab, 0xc9, 0x83, 0x4c, 0x2a, 0xe1, 0xa4, 0xe2, 0xa7, 0xb7, 0xda, 0x7f, 0x15, 0xa8,
-	0x0e, 0xbd, 0xb1, 0xc9, 0x68, 0xe4, 0xf1, 0x73, 0x3a, 0xc6, 0xd2, 0xaf, 0xc7, 0xd9, 0xf9, 0xd7,
-	0xe3, 0xf9, 0xd7, 0xe8, 0xdc, 0xb9, 0xd7, 0x68, 0xed, 0x2e, 0xac, 0xce, 0xfb, 0x2f, 0x73, 0xbd,
-	0x0e, 0x39, 0xf1, 0x7a, 0x74, 0xee, 0xbb, 0x24, 0xf5, 0x3c, 0x44, 0x22, 0xc0, 0xb5, 0x23, 0xc8,
-	0x6e, 0xdb, 0xe6, 0x01, 0x2e, 0x42, 0xb6, 0x77, 0xbf, 0xd7, 0x46, 0x17, 0xf0, 0x45, 0x80, 0xce,
-	0xa0, 0xd3, 0xd3, 0xdb, 0xf7, 0x48, 0xb3, 0x8b, 0xce, 0xd4, 0x88, 0x30, 0xec, 0x0d, 0x3a, 0xf7,
-	0x7a, 0xed, 0x2d, 0x74, 0x96, 0xc5, 0x4b, 0x50, 0xe8, 0x0c, 0xb6, 0xbb, 0xf7, 0x9b, 0x3a, 0x3a,
-	0x2b, 0xe2, 0x0a, 0x14, 0x3b, 0x83, 0x07, 0xc3, 0xfb, 0x3a, 0x67, 0x22, 0x5c, 0x86, 0x7c, 0x67,
-	0xa0, 0xb7, 0xbf, 0xae, 0xa3, 0xb3, 0x2b, 0x11, 0xaf, 0xd5, 0xe9, 0x35, 0xc9, 0x43, 0x74, 0x76,
-	0xf7, 0xda, 0xbf, 0x55, 0xc8, 0xea, 0x53, 0x8f, 0xf2, 0x39, 0xd4, 0xe3, 0x73, 0x48, 0x7f, 0xd8,
-	0xe7, 0x2a, 0x4b, 0x90, 0xed, 0xf4, 0xf4, 0x3b, 0xe8, 0x9b, 0x2a, 0x06, 0xc8, 0x0d, 0xc5, 0xfa,
-	0xed, 0x3c, 0x5f, 0x77, 0x7a, 0xfa, 0x17, 0x6e, 0xa3, 0x6f, 0xa9, 0x5c, 0xec, 0x30, 0xda, 0x7c,
-	0x3b, 0x66, 0x34, 0x36, 0xd0, 0x77, 0x12, 0x46, 0x63, 0x03, 0x7d, 0x37, 0x66, 0xdc, 0x6a, 0xa0,
-	0xef, 0x25, 0x8c, 0x5b, 0x0d, 0xf4, 0xfd, 0x98, 0x71, 0x7b, 0x03, 0xfd, 0x20, 0x61, 0xdc, 0xde,
-	0x40, 0x3f, 0xcc, 0x73, 0x5f, 0x84, 0x27, 0xb7, 0x1a, 0xe8, 0x47, 0xc5, 0x64, 0x77, 0x7b, 0x03,
-	0xfd, 0xb8, 0x88, 0x97, 0xa1, 0xa4, 0x77, 0x76, 0xda, 0x03, 0xbd, 0xb9, 0xd3, 0x47, 0x3f, 0x41,
-	0xdc, 0xcc, 0xad, 0xa6, 0xde, 0x46, 0x3f, 0x15, 0x4b, 0xce, 0x42, 0x3f, 0x43, 0xdc, 0x47, 0x4e,
-	0x15, 0xdb, 0x77, 0x04, 0xe7, 0x61, 0xbb, 0x49, 0xd0, 0xcf, 0xf3, 0xb8, 0x0c, 0x85, 0xad, 0xf6,
-	0x66, 0x67, 0xa7, 0xd9, 0x45, 0x58, 0x9c, 0xe0, 0x51, 0xf9, 0xc5, 0x4d, 0xbe, 0x6c, 0x75, 0xef,
-	0xb7, 0xd0, 0x2f, 0xfb, 0x5c, 0xe1, 0x6e, 0x93, 0x6c, 0xbe, 0xd1, 0x24, 0xe8, 0x57, 0x37, 0xb9,
-	0xc2, 0xdd, 0x26, 0x91, 0xf1, 0xfa, 0x75, 0x9f, 0x03, 0x05, 0xeb, 0xdd, 0x9b, 0xdc, 0x68, 0x49,
-	0xff, 0x4d, 0x1f, 0x17, 0x21, 0xd3, 0xea, 0xe8, 0xe8, 0xb7, 0x42, 0x5b, 0xbb, 0x37, 0xdc, 0x41,
-	0xbf, 0x43, 0x9c, 0x38, 0x68, 0xeb, 0xe8, 0xf7, 0x9c, 0x98, 0xd3, 0x87, 0xfd, 0x6e, 0x1b, 0x5d,
-	0x6e, 0xad, 0x41, 0x6d, 0xe4, 0x4e, 0xea, 0x53, 0x37, 0x64, 0xe1, 0x1e, 0xad, 0x9f, 0x58, 0x8c,
-	0x06, 0x41, 0xf4, 0x7f, 0x99, 0xbd, 0xbc, 0xf8, 0x73, 0xeb, 0x7f, 0x01, 0x00, 0x00, 0xff, 0xff,
-	0x49, 0x1d, 0xea, 0xf8, 0xd1, 0x19, 0x00, 0x00,
+	// 2075 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xed, 0x19, 0x6b, 0x73, 0x1b, 0x57,
+	0x95, 0xd5, 0xcb, 0xd2, 0x91, 0x65, 0x6f, 0xae, 0x1d, 0x10, 0x26, 0x40, 0xd9, 0xbe, 0x42, 0x9a,
+	0x11, 0xc1, 0x0d, 0x21, 0x53, 0x0a, 0x44, 0x96, 0xd7, 0xa9, 0xa6, 0xb6, 0xac, 0x5c, 0xad, 0x3c,
+	0x84, 0xe9, 0xcc, 0xce, 0x5a, 0xba, 0xb1, 0x77, 0xbc, 0xda, 0x55, 0x76, 0x57, 0x6e, 0xf4, 0x2d,
+	0x94, 0xf7, 0xbb, 0x0c, 0x8f, 0x02, 0x9d, 0x29, 0xfc, 0x07, 0x3e, 0x33, 0xd3, 0xe9, 0x0f, 0xe0,
+	0x5f, 0x30, 0x0c, 0x9f, 0xe0, 0x07, 0x30, 0x0c, 0xe7, 0x3e, 0x76, 0xb5, 0x72, 0x94, 0x26, 0xed,
+	0xb7, 0x94, 0x7e, 0xd2, 0x3d, 0x8f, 0x7b, 0xcf, 0xfb, 0xdc, 0x3d, 0x57, 0x50, 0xbd, 0x3b, 0x61,
+	0xe1, 0xb4, 0x31, 0x0e, 0x83, 0x38, 0x20, 0x45, 0x01, 0x6c, 0xac, 0xc4, 0xc1, 0x38, 0x18, 0x3a,
+	0xb1, 0x23, 0xd1, 0x1b, 0xd5, 0xd3, 0x38, 0x1c, 0x0f, 0x24, 0x60, 0xdc, 0x85, 0x92, 0xe5, 0x84,
+	0x47, 0x2c, 0x26, 0x1b, 0x50, 0x3e, 0x61, 0xd3, 0x68, 0xec, 0x0c, 0x58, 0x5d, 0x7b, 0x4a, 0xbb,
+	0x58, 0xa1, 0x29, 0x4c, 0xd6, 0xa1, 0x18, 0x1d, 0x3b, 0xe1, 0xb0, 0x9e, 0x13, 0x04, 0x09, 0x90,
+	0xaf, 0x40, 0x35, 0x76, 0x0e, 0x3d, 0x16, 0xdb, 0xf1, 0x74, 0xcc, 0xea, 0x79, 0xa4, 0xad, 0x6c,
+	0xae, 0x37, 0x52, 0x71, 0x96, 0x20, 0x5a, 0x48, 0xa3, 0x10, 0xa7, 0x6b, 0xe3, 0x32, 0xac, 0x1c,
+	0x58, 0x37, 0x9d, 0x98, 0xb5, 0x1c, 0xcf, 0x63, 0x61, 0x7b, 0x9b, 0x8b, 0x9e, 0x44, 0x2c, 0xf4,
+	0x9d, 0x51, 0x2a, 0x3a, 0x81, 0x8d, 0xd7, 0x00, 0xcc, 0x53, 0xe6, 0xc7, 0x56, 0x70, 0xc2, 0x7c,
+	0x72, 0x01, 0x2a, 0xb1, 0x3b, 0x62, 0x51, 0xec, 0x8c, 0xc6, 0x82, 0x35, 0x4f, 0x67, 0x88, 0x87,
+	0xa8, 0x89, 0xa7, 0x8f, 0x83, 0xc8, 0x8d, 0xdd, 0xc0, 0x17, 0x3a, 0xe2, 0xe9, 0x09, 0x6c, 0x7c,
+	0x03, 0x8a, 0x07, 0x8e, 0x37, 0x61, 0xe4, 0xf3, 0x50, 0x10, 0x46, 0x68, 0xc2, 0x88, 0x6a, 0x43,
+	0xfa, 0x51, 0xe8, 0x2e, 0x08, 0xfc, 0xec, 0x53, 0xce, 0x29, 0xce, 0x5e, 0xa6, 0x12, 0x30, 0x4e,
+	0x60, 0x79, 0xcb, 0xf5, 0x87, 0x07, 0x4e, 0xe8, 0x72, 0x03, 0x3f, 0xe4, 0x31, 0xe4, 0x19, 0x28,
+	0x89, 0x45, 0x84, 0x0a, 0xe6, 0x2f, 0x56, 0x37, 0x97, 0xd5, 0x46, 0xa1, 0x1b, 0x55, 0x34, 0xe3,
+	0x3d, 0x0d, 0x60, 0x2b, 0x98, 0xf8, 0xc3, 0x5b, 0x9c, 0x48, 0x74, 0xc8, 0x47, 0x77, 0x3d, 0xe5,
+	0x30, 0xbe, 0x24, 0xaf, 0xc2, 0xca, 0x21, 0x6a, 0x63, 0x9f, 0x2a, 0x75, 0x22, 0x94, 0xc2, 0x8f,
+	0x7b, 0x46, 0x1d, 0x37, 0xdb, 0xdc, 0xc8, 0x6a, 0x1d, 0x99, 0x7e, 0x1c, 0x4e, 0x69, 0xed, 0x30,
+	0x8b, 0xdb, 0xe8, 0x03, 0x79, 0x90, 0x89, 0x0b, 0xc5, 0xac, 0x48, 0x84, 0xe2, 0x92, 0x7c, 0x31,
+	0x6b, 0x51, 0x75, 0x73, 0x2d, 0x91, 0x95, 0xd9, 0xab, 0xcc, 0x7c, 0x29, 0x77, 0x5d, 0x33, 0xfe,
+	0xa2, 0xc1, 0x8a, 0x79, 0x8f, 0x0d, 0x26, 0x31, 0xdb, 0x1f, 0xf3, 0x18, 0x44, 0xa4, 0x01, 0x6b,
+	0xec, 0xde, 0xc0, 0x9b, 0x0c, 0x99, 0x7d, 0xc7, 0x65, 0xde, 0xd0, 0xe6, 0x81, 0x8f, 0x84, 0x8c,
+	0x32, 0x3d, 0xa7, 0x48, 0x3b, 0x9c, 0xd2, 0xe1, 0x04, 0xce, 0xef, 0xfa, 0x92, 0x9f, 0xf1, 0xd4,
+	0xb0, 0x63, 0x9e, 0x1b, 0x42, 0x3e, 0xf2, 0x2b, 0x52, 0x26, 0x69, 0x9a, 0xb0, 0x36, 0x08, 0x46,
+	0x63, 0x27, 0x9c, 0xe7, 0xcf, 0x0b, 0x7d, 0xcf, 0x29, 0x7d, 0x67, 0xfc, 0xf4, 0x9c, 0xe2, 0x9e,
+	0xa1, 0x8c, 0x97, 0xa1, 0x28, 0x14, 0x20, 0x04, 0x0a, 0x99, 0x34, 0x15, 0xeb, 0x34, 0xe8, 0xb9,
+	0x87, 0x04, 0xdd, 0xf8, 0x2a, 0xe4, 0x69, 0xf0, 0x3a, 0xa9, 0xc3, 0x92, 0xc7, 0xfc, 0xa3, 0xf8,
+	0x98, 0xdb, 0x96, 0xbf, 0x48, 0x68, 0x02, 0x92, 0x4f, 0xa6, 0xf1, 0x97, 0x69, 0x91, 0x44, 0xfc,
+	0x35, 0x58, 0xa6, 0x2c, 0x9a, 0x78, 0xb1, 0x79, 0x2f, 0x0e, 0x9d, 0x88, 0x6c, 0x42, 0x35, 0x6b,
+	0x81, 0xf6, 0x30, 0x0b, 0x80, 0xcd, 0xac, 0x47, 0xa9, 0x77, 0x42, 0x16, 0x1d, 0xb3, 0x50, 0x79,
+	0x28, 0x01, 0x79, 0x3e, 0x55, 0x45, 0x36, 0x48, 0x19, 0x3c, 0x0b, 0x85, 0xff, 0xa5, 0x7a, 0xb3,
+	0x2c, 0x14, 0x96, 0x53, 0x45, 0x23, 0x4f, 0x43, 0x2d, 0x0c, 0x5e, 0x8f, 0x6c, 0xe7, 0xce, 0x1d,
+	0x36, 0x88, 0x99, 0x2c, 0xb6, 0x02, 0x5d, 0xe6, 0xc8, 0xa6, 0xc2, 0x91, 0xcf, 0x40, 0xc5, 0xf5,
+	0xb1, 0x84, 0x63, 0xdb, 0x1d, 0x0a, 0x47, 0x17, 0x68, 0x59, 0x22, 0xda, 0x43, 0xf2, 0x39, 0x28,
+	0x70, 0xe6, 0x7a, 0x41, 0x48, 0x01, 0x25, 0x05, 0x3d, 0x44, 0x05, 0x9e, 0xbc, 0x00, 0x25, 0x26,
+	0xec, 0xad, 0x17, 0xe7, 0x52, 0x2a, 0xeb, 0x0a, 0xaa, 0x58, 0x8c, 0x3f, 0xe7, 0xa1, 0xda, 0x8b,
+	0x43, 0xe6, 0x8c, 0x84, 0xfd, 0xe4, 0x65, 0x00, 0x6c, 0x06, 0x31, 0x1b, 0x21, 0x90, 0x18, 0x72,
+	0x41, 0x1d, 0x90, 0xe1, 0xc3, 0xb5, 0x62, 0xa2, 0x19, 0xfe, 0xb3, 0x0e, 0xce, 0x3d, 0x86, 0x83,
+	0x37, 0xde, 0xc9, 0x41, 0x25, 0x3d, 0x0d, 0x93, 0xad, 0x3c, 0xc0, 0xf5, 0x51, 0x10, 0x4e, 0x55,
+	0x17, 0x78, 0xf6, 0xfd, 0xa4, 0x37, 0x5a, 0x8a, 0x99, 0xa6, 0xdb, 0xc8, 0x67, 0x41, 0xb6, 0x4b,
+	0x51, 0x07, 0xaa, 0x97, 0x55, 0x04, 0x86, 0xe7, 0x3f, 0x79, 0x09, 0xc8, 0x38, 0x74, 0x47, 0x4e,
+	0x38, 0xb5, 0xb1, 0xfe, 0x6c, 0x15, 0xb2, 0xfc, 0x82, 0x90, 0xe9, 0x8a, 0xef, 0x55, 0x36, 0xdd,
+	0x91, 0xc1, 0xbb, 0x3e, 0xbf, 0x57, 0x25, 0xdd, 0x83, 0x81, 0xc8, 0xec, 0x14, 0x3d, 0x28, 0x4a,
+	0xba, 0x4d, 0x51, 0xe4, 0x27, 0x5f, 0x1a, 0xcf, 0x43, 0x39, 0x51, 0x9e, 0x54, 0xa0, 0x68, 0x86,
+	0x61, 0x10, 0xea, 0x9f, 0x20, 0x4b, 0x90, 0xdf, 0xde, 0xdb, 0xd5, 0x35, 0xb1, 0xd8, 0xde, 0xd5,
+	0x73, 0xc6, 0xbb, 0xb9, 0xb4, 0xe4, 0x29, 0x43, 0x19, 0x51, 0x4c, 0xbe, 0x89, 0x25, 0x2f, 0x72,
+	0xc5, 0x3d, 0x65, 0xf6, 0x40, 0xdc, 0x03, 0x3c, 0x53, 0x64, 0x42, 0xaf, 0x36, 0xe4, 0x0d, 0x95,
+	0xdc, 0x0f, 0xd8, 0x03, 0x12, 0x5e, 0x85, 0x1a, 0x12, 0x13, 0x7b, 0xc0, 0x68, 0xc4, 0x86, 0x2e,
+	0x6a, 0x90, 0x39, 0x40, 0x06, 0xec, 0x7c, 0xd2, 0x3e, 0xe7, 0xae, 0x19, 0x6c, 0x0d, 0xc9, 0x8e,
+	0xf4, 0x98, 0x67, 0xa1, 0x14, 0x8b, 0xeb, 0x4f, 0x75, 0x83, 0x5a, 0x52, 0xbc, 0x02, 0x49, 0x15,
+	0x91, 0x3c, 0x0f, 0xf2, 0x2e, 0x45, 0x4f, 0x65, 0x13, 0x62, 0xd6, 0x4f, 0xa9, 0xa4, 0xe3, 0x79,
+	0x2b, 0x98, 0x95, 0x7e, 0xe4, 0x0c, 0x78, 0x6b, 0xe3, 0x1a, 0x15, 0xc5, 0x25, 0x55, 0xcb, 0x60,
+	0x51, 0xec, 0x97, 0x60, 0x29, 0x90, 0xcd, 0xaf, 0x5e, 0x9a, 0xd3, 0x78, 0xbe, 0x33, 0xd2, 0x84,
+	0xcb, 0xf8, 0x3a, 0xac, 0xa6, 0x1e, 0x8c, 0xc6, 0x88, 0x61, 0xe4, 0x12, 0x94, 0x42, 0x51, 0x10,
+	0xca, 0x6b, 0x44, 0x1d, 0x91, 0xa9, 0x68, 0xaa, 0x38, 0x8c, 0x7f, 0xe7, 0x60, 0x4d, 0xed, 0xdf,
+	0x72, 0xe2, 0xc1, 0xf1, 0x13, 0x1a, 0x86, 0x17, 0x60, 0x89, 0xe3, 0xdd, 0x34, 0x65, 0x17, 0x04,
+	0x22, 0xe1, 0xe0, 0xa1, 0x70, 0x22, 0x3b, 0xe3, 0x77, 0x11, 0x8a, 0x32, 0xad, 0x39, 0x91, 0x35,
+	0x43, 0x2e, 0x88, 0x58, 0xe9, 0x11, 0x11, 0x5b, 0x7a, 0xac, 0x88, 0x6d, 0xc3, 0xfa, 0xbc, 0xc7,
+	0x55, 0xd8, 0x2e, 0xc3, 0x92, 0x0c, 0x4a, 0xd2, 0x9c, 0x16, 0xc5, 0x2d, 0x61, 0x31, 0xfe, 0x94,
+	0x83, 0x75, 0xd5, 0x37, 0xfe, 0x3f, 0x0a, 0x28, 0xe3, 0xe7, 0xe2, 0x63, 0xf9, 0xb9, 0x05, 0xe7,
+	0xcf, 0x38, 0xe8, 0x43, 0xd4, 0xc7, 0x5f, 0x35, 0xfc, 0x8e, 0x63, 0x47, 0xae, 0xff, 0x64, 0xba,
+	0xd7, 0xb8, 0x06, 0x35, 0xa5, 0xbe, 0x32, 0xfe, 0xc1, 0xac, 0xd6, 0x16, 0x64, 0xb5, 0xf1, 0x77,
+	0x0d, 0x6a, 0xad, 0x60, 0x34, 0x72, 0xe3, 0x27, 0x34, 0xaf, 0x1e, 0xb4, 0xb3, 0xb0, 0xc8, 0x4e,
+	0x1d, 0x56, 0x12, 0x33, 0xa5, 0x83, 0x8c, 0x7f, 0x68, 0xb0, 0x4a, 0x03, 0xcf, 0x3b, 0x74, 0x06,
+	0x27, 0x1f, 0x6d, 0xdb, 0x09, 0xe8, 0x33, 0x43, 0x95, 0xf5, 0xf8, 0xc9, 0xb2, 0x26, 0x12, 0xe6,
+	0xe3, 0xae, 0xb2, 0xb8, 0xab, 0xbc, 0xa9, 0xc1, 0xfa, 0xbc, 0x83, 0xd2, 0xc2, 0x2a, 0x32, 0xfe,
+	0xa1, 0x73, 0xc6, 0x27, 0xb4, 0xdb, 0x12, 0xdf, 0x3f, 0x54, 0x52, 0x33, 0xcd, 0x27, 0xf7, 0xa8,
+	0xe6, 0xb3, 0x20, 0x8e, 0xf9, 0x45, 0x71, 0xfc, 0x5b, 0x0e, 0xea, 0x59, 0x95, 0x3e, 0xbe, 0xc8,
+	0xe7, 0x2f, 0xf2, 0x0f, 0xfc, 0x4d, 0xf5, 0x96, 0x06, 0x9f, 0x5e, 0xe0, 0xd0, 0x0f, 0x16, 0xe8,
+	0xcc, 0x75, 0x9e, 0x7b, 0xe4, 0x75, 0xfe, 0xb8, 0xa1, 0x7e, 0xa3, 0x00, 0xe7, 0x7a, 0x63, 0xcf,
+	0x8d, 0xd5, 0x21, 0x1f, 0xed, 0xe2, 0xfc, 0x02, 0x2c, 0x47, 0xdc, 0x58, 0x7b, 0x10, 0x78, 0x93,
+	0x11, 0x8f, 0x6e, 0x1e, 0x07, 0x9e, 0xaa, 0xc0, 0xb5, 0x04, 0x0a, 0x27, 0xec, 0x6a, 0xc2, 0x32,
+	0xf1, 0x63, 0xf5, 0x85, 0x06, 0x8a, 0x03, 0x31, 0xe4, 0x2a, 0x7c, 0xca, 0x9f, 0x8c, 0x6c, 0x31,
+	0x98, 0x8e, 0xd1, 0x2c, 0x71, 0xb2, 0x8d, 0x43, 0x7c, 0x5c, 0x2f, 0x0b, 0xe6, 0x35, 0x24, 0xe3,
+	0x60, 0x13, 0x75, 0x59, 0x28, 0x84, 0x77, 0x91, 0x44, 0x6e, 0x40, 0xc5, 0xf1, 0x70, 0x7e, 0x71,
+	0xe3, 0xe3, 0x51, 0xbd, 0x22, 0x86, 0x35, 0x23, 0x19, 0xd6, 0xce, 0xba, 0xbf, 0xd1, 0x4c, 0x38,
+	0xe9, 0x6c, 0x13, 0x26, 0x32, 0x99, 0x44, 0xcc, 0x96, 0xca, 0x49, 0xa1, 0xa7, 0x9b, 0x75, 0x10,
+	0xf9, 0xb9, 0x8a, 0x94, 0xd9, 0x31, 0x07, 0x9b, 0xc6, 0x65, 0xa8, 0xa4, 0x87, 0xe0, 0x3c, 0xb5,
+	0x6c, 0xde, 0xea, 0x37, 0x77, 0xed, 0x5e, 0x77, 0xb7, 0x6d, 0xf5, 0x70, 0x70, 0xaa, 0x41, 0x65,
+	0xa7, 0xbf, 0x8b, 0x88, 0x56, 0xb3, 0xa3, 0x6b, 0x06, 0x05, 0x10, 0x1b, 0xc5, 0x11, 0x33, 0x6f,
+	0x6a, 0x8f, 0xf0, 0x26, 0x4e, 0xde, 0xe8, 0x05, 0xe5, 0xa8, 0x9c, 0xb0, 0xbd, 0x8c, 0x08, 0xe1,
+	0x26, 0xa3, 0x09, 0x24, 0x6b, 0x98, 0x4a, 0xf5, 0x4c, 0x35, 0x6a, 0x73, 0xd5, 0x38, 0x93, 0x9f,
+	0x56, 0xa3, 0x71, 0x1e, 0xd6, 0xe4, 0xf7, 0xd6, 0x2b, 0xcc, 0xf1, 0xe2, 0xa4, 0x01, 0x19, 0xff,
+	0xc9, 0x41, 0x8d, 0x72, 0x8c, 0x3b, 0x62, 0x7c, 0xb8, 0x8d, 0x78, 0x58, 0x8f, 0x05, 0x8b, 0x3d,
+	0xab, 0x23, 0x0c, 0xab, 0xc4, 0x89, 0x1a, 0xc2, 0x69, 0xfb, 0x7c, 0xc4, 0x06, 0x81, 0x3f, 0x8c,
+	0xec, 0x43, 0x76, 0xcc, 0x5f, 0xae, 0x46, 0x4e, 0x14, 0xab, 0x87, 0x8a, 0x1a, 0x5d, 0x53, 0xc4,
+	0x2d, 0x41, 0xdb, 0x13, 0x24, 0x72, 0x05, 0xd6, 0x0f, 0x5d, 0xdf, 0x0b, 0x8e, 0xec, 0xb1, 0xe7,
+	0x4c, 0x59, 0x18, 0x29, 0x53, 0x79, 0x2e, 0x16, 0x29, 0x91, 0xb4, 0xae, 0x24, 0xc9, 0xdc, 0xf8,
+	0x36, 0x5c, 0x5a, 0x28, 0x05, 0x27, 0x67, 0x0f, 0x7f, 0xd8, 0xd0, 0x0e, 0x19, 0xda, 0x88, 0xe3,
+	0x37, 0xef, 0x2d, 0xf2, 0x0e, 0x7d, 0x6e, 0x81, 0xe8, 0x1d, 0xc5, 0x4e, 0x67, 0xdc, 0xdc, 0xdb,
+	0x83, 0xf1, 0xc4, 0x9e, 0x44, 0xce, 0x11, 0x13, 0x6d, 0x49, 0xc3, 0x39, 0x7e, 0x3c, 0xe9, 0x73,
+	0x98, 0x8f, 0xcc, 0x77, 0xc7, 0xb2, 0x1b, 0x69, 0x94, 0x2f, 0xb9, 0xf2, 0x72, 0xb2, 0x8f, 0x06,
+	0xc7, 0x6c, 0xe4, 0xd8, 0x83, 0x63, 0xc7, 0x3f, 0x62, 0x43, 0x1c, 0x29, 0x78, 0xca, 0x13, 0x41,
+	0xeb, 0x09, 0x52, 0x4b, 0x52, 0x78, 0xc7, 0x50, 0xbc, 0xa7, 0x68, 0x10, 0x57, 0x50, 0xe6, 0x73,
+	0x4d, 0x62, 0x0f, 0x24, 0xd2, 0xf8, 0xa7, 0x96, 0xcc, 0x09, 0x49, 0x58, 0xd2, 0x36, 0x96, 0x14,
+	0xab, 0xf6, 0x7e, 0xc5, 0x5a, 0x87, 0xa5, 0x88, 0x85, 0xa7, 0xae, 0x7f, 0x94, 0x3c, 0x12, 0x29,
+	0x90, 0xf4, 0xe0, 0x39, 0xf5, 0xc8, 0xcb, 0xee, 0xc5, 0xfc, 0x4d, 0xd6, 0xf3, 0xa6, 0xdc, 0x61,
+	0x4e, 0xc8, 0xfc, 0x18, 0x7d, 0x37, 0x7b, 0x8e, 0x95, 0xad, 0xec, 0x69, 0xc9, 0x6d, 0xa6, 0xcc,
+	0x34, 0xe5, 0xb5, 0xd2, 0x87, 0xda, 0xaf, 0xc1, 0x4a, 0xa8, 0x92, 0xc5, 0xe6, 0xaf, 0x2f, 0x91,
+	0x6a, 0x12, 0xeb, 0xe9, 0x4b, 0x4f, 0x26, 0x93, 0x68, 0x2d, 0xcc, 0x82, 0xc6, 0x7f, 0x35, 0x58,
+	0xeb, 0x8f, 0x87, 0xd8, 0x92, 0xa4, 0xc5, 0x4f, 0x68, 0x7f, 0xcc, 0x3e, 0x4b, 0x17, 0xe6, 0x9f,
+	0xa5, 0xe7, 0x9f, 0xb9, 0x8b, 0x67, 0x9e, 0xb9, 0x8d, 0x1b, 0xb0, 0x3e, 0x6f, 0xbf, 0x8a, 0xf5,
+	0x45, 0xbc, 0xb2, 0xf8, 0xeb, 0xd2, 0x99, 0x81, 0x27, 0xf3, 0xee, 0x44, 0x25, 0xc3, 0xa5, 0x13,
+	0x28, 0xec, 0x78, 0xce, 0x11, 0x29, 0x43, 0xa1, 0xb3, 0xdf, 0x31, 0xb1, 0xf9, 0xac, 0x02, 0xb4,
+	0x7b, 0xed, 0x8e, 0x65, 0xde, 0xa4, 0xcd, 0x5d, 0xfd, 0x7e, 0x4e, 0x22, 0xfa, 0x9d, 0x5e, 0xfb,
+	0x66, 0xc7, 0xdc, 0xd6, 0xef, 0x17, 0xc8, 0x32, 0x2c, 0xb5, 0x7b, 0x3b, 0xbb, 0xfb, 0x4d, 0x4b,
+	0xbf, 0x5f, 0xc6, 0x66, 0x55, 0x6e, 0xf7, 0x6e, 0xf5, 0xf7, 0x2d, 0x4e, 0xd4, 0x49, 0x15, 0x4a,
+	0xed, 0x9e, 0x65, 0x7e, 0x0b, 0x69, 0x4f, 0x49, 0xda, 0x56, 0xbb, 0xd3, 0xa4, 0xb7, 0xf5, 0xfb,
+	0x37, 0x2e, 0xfd, 0x2b, 0x07, 0x05, 0xfe, 0x18, 0xca, 0x1b, 0x5c, 0x87, 0x37, 0x38, 0xeb, 0x76,
+	0x97, 0x8b, 0xac, 0x40, 0x01, 0x05, 0x5e, 0xd7, 0xbf, 0x93, 0x23, 0x00, 0xc5, 0xbe, 0x58, 0xbf,
+	0x51, 0xe2, 0x6b, 0x5c, 0x7e, 0xf9, 0x9a, 0xfe, 0xdd, 0x1c, 0x3f, 0xb6, 0x2f, 0x81, 0xef, 0x25,
+	0x84, 0xcd, 0xab, 0xfa, 0xf7, 0x53, 0x02, 0x02, 0x3f, 0x48, 0x08, 0x2f, 0x6e, 0xea, 0x3f, 0x4c,
+	0x09, 0x08, 0xfc, 0x28, 0x21, 0x5c, 0xbb, 0xaa, 0xff, 0x38, 0x25, 0x20, 0xf0, 0x93, 0x12, 0xb7,
+	0x45, 0x58, 0x82, 0x6c, 0x3f, 0x2d, 0xa7, 0x10, 0xd2, 0x7e, 0x56, 0x26, 0x2b, 0x50, 0xb1, 0xda,
+	0x7b, 0x66, 0xcf, 0x6a, 0xee, 0x75, 0xf5, 0x9f, 0xeb, 0x5c, 0xcd, 0xed, 0xa6, 0x65, 0xea, 0xbf,
+	0x10, 0x4b, 0x4e, 0xd2, 0x7f, 0xa9, 0x73, 0x1b, 0x39, 0x56, 0x80, 0x6f, 0x0a, 0xca, 0x6d, 0xb3,
+	0x49, 0xf5, 0x5f, 0x95, 0x50, 0xd0, 0xd2, 0xb6, 0xd9, 0x6a, 0xef, 0xa1, 0x1b, 0x89, 0xd8, 0xc1,
+	0xbd, 0xf2, 0xeb, 0x2b, 0x7c, 0xb9, 0xb5, 0xbb, 0xbf, 0xa5, 0xff, 0xa6, 0xcb, 0x05, 0x1e, 0x34,
+	0x69, 0xeb, 0x15, 0xdc, 0xf0, 0xdb, 0x2b, 0x5c, 0x20, 0x42, 0xca, 0x5f, 0xbf, 0xeb, 0x72, 0x46,
+	0x41, 0x7a, 0xeb, 0x0a, 0x57, 0x5a, 0xe1, 0x7f, 0xdf, 0xc5, 0x60, 0xe5, 0xb7, 0xda, 0x96, 0xfe,
+	0x07, 0x21, 0xcd, 0xec, 0xf4, 0xf7, 0xf4, 0x3f, 0xea, 0x1c, 0xd9, 0x33, 0x2d, 0xfd, 0x6d, 0x8e,
+	0x2c, 0x5a, 0xfd, 0xee, 0xae, 0xa9, 0x5f, 0xd8, 0xda, 0x80, 0xfa, 0x20, 0x18, 0x35, 0xa6, 0xc1,
+	0x24, 0x9e, 0x1c, 0xb2, 0xc6, 0xa9, 0x1b, 0xb3, 0x28, 0x92, 0x7f, 0xf8, 0x1c, 0x96, 0xc4, 0xcf,
+	0x8b, 0xff, 0x03, 0x72, 0x76, 0x0e, 0x3e, 0x2a, 0x1a, 0x00, 0x00,
 }
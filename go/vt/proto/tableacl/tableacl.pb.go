@@ -6,11 +6,15 @@
 Package tableacl is a generated protocol buffer package.
 
 It is generated from these files:
+
 	tableacl.proto
 
 It has these top-level messages:
+
 	TableGroupSpec
 	Config
+	ColumnReaders
+	RowFilter
 */
 package tableacl
 
@@ -37,6 +41,10 @@ type TableGroupSpec struct {
 	Readers              []string `protobuf:"bytes,3,rep,name=readers" json:"readers,omitempty"`
 	Writers              []string `protobuf:"bytes,4,rep,name=writers" json:"writers,omitempty"`
 	Admins               []string `protobuf:"bytes,5,rep,name=admins" json:"admins,omitempty"`
+	// column_readers can only read some of the columns of the tables.
+	ColumnReaders []*ColumnReaders `protobuf:"bytes,6,rep,name=column_readers,json=columnReaders" json:"column_readers,omitempty"`
+	// row_filters restrict the rows some readers can read.
+	RowFilters []*RowFilter `protobuf:"bytes,7,rep,name=row_filters,json=rowFilters" json:"row_filters,omitempty"`
 }
 
 func (m *TableGroupSpec) Reset()                    { *m = TableGroupSpec{} }
@@ -44,6 +52,20 @@ func (m *TableGroupSpec) String() string            { return proto.CompactTextSt
 func (*TableGroupSpec) ProtoMessage()               {}
 func (*TableGroupSpec) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{0} }
 
+func (m *TableGroupSpec) GetColumnReaders() []*ColumnReaders {
+	if m != nil {
+		return m.ColumnReaders
+	}
+	return nil
+}
+
+func (m *TableGroupSpec) GetRowFilters() []*RowFilter {
+	if m != nil {
+		return m.RowFilters
+	}
+	return nil
+}
+
 type Config struct {
 	TableGroups []*TableGroupSpec `protobuf:"bytes,1,rep,name=table_groups,json=tableGroups" json:"table_groups,omitempty"`
 }
@@ -60,26 +82,60 @@ func (m *Config) GetTableGroups() []*TableGroupSpec {
 	return nil
 }
 
+// ColumnReaders grants read access to some columns of a table group.
+// Queries reading any other column (including 'select *') are denied.
+type ColumnReaders struct {
+	Columns []string `protobuf:"bytes,1,rep,name=columns" json:"columns,omitempty"`
+	Readers []string `protobuf:"bytes,2,rep,name=readers" json:"readers,omitempty"`
+}
+
+func (m *ColumnReaders) Reset()                    { *m = ColumnReaders{} }
+func (m *ColumnReaders) String() string            { return proto.CompactTextString(m) }
+func (*ColumnReaders) ProtoMessage()               {}
+func (*ColumnReaders) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{2} }
+
+// RowFilter restricts the rows its readers can read to the ones
+// matching a SQL predicate, e.g. "region = 'US'". The predicate is
+// added to the WHERE clause of their selects. If a reader is in
+// several filters, all of their predicates apply.
+type RowFilter struct {
+	Predicate string   `protobuf:"bytes,1,opt,name=predicate" json:"predicate,omitempty"`
+	Readers   []string `protobuf:"bytes,2,rep,name=readers" json:"readers,omitempty"`
+}
+
+func (m *RowFilter) Reset()                    { *m = RowFilter{} }
+func (m *RowFilter) String() string            { return proto.CompactTextString(m) }
+func (*RowFilter) ProtoMessage()               {}
+func (*RowFilter) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{3} }
+
 func init() {
 	proto.RegisterType((*TableGroupSpec)(nil), "tableacl.TableGroupSpec")
 	proto.RegisterType((*Config)(nil), "tableacl.Config")
+	proto.RegisterType((*ColumnReaders)(nil), "tableacl.ColumnReaders")
+	proto.RegisterType((*RowFilter)(nil), "tableacl.RowFilter")
 }
 
 func init() { proto.RegisterFile("tableacl.proto", fileDescriptor0) }
 
 var fileDescriptor0 = []byte{
-	// 207 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0xe2, 0xe2, 0x2b, 0x49, 0x4c, 0xca,
-	0x49, 0x4d, 0x4c, 0xce, 0xd1, 0x2b, 0x28, 0xca, 0x2f, 0xc9, 0x17, 0xe2, 0x80, 0xf1, 0x95, 0x96,
-	0x33, 0x72, 0xf1, 0x85, 0x80, 0x38, 0xee, 0x45, 0xf9, 0xa5, 0x05, 0xc1, 0x05, 0xa9, 0xc9, 0x42,
-	0x42, 0x5c, 0x2c, 0x79, 0x89, 0xb9, 0xa9, 0x12, 0x8c, 0x0a, 0x8c, 0x1a, 0x9c, 0x41, 0x60, 0xb6,
-	0x90, 0x29, 0x97, 0x38, 0x58, 0x4b, 0x3c, 0x88, 0x57, 0x1c, 0x9f, 0x5f, 0x14, 0x5f, 0x50, 0x94,
-	0x9a, 0x96, 0x59, 0x91, 0x5a, 0x2c, 0xc1, 0xa4, 0xc0, 0xac, 0xc1, 0x19, 0x24, 0x02, 0x96, 0xf6,
-	0x03, 0xc9, 0xfa, 0x17, 0x05, 0x40, 0xe5, 0x84, 0x24, 0xb8, 0xd8, 0x8b, 0x52, 0x13, 0x53, 0x52,
-	0x8b, 0x8a, 0x25, 0x98, 0xc1, 0xca, 0x60, 0x5c, 0x90, 0x4c, 0x79, 0x51, 0x66, 0x09, 0x48, 0x86,
-	0x05, 0x22, 0x03, 0xe5, 0x0a, 0x89, 0x71, 0xb1, 0x25, 0xa6, 0xe4, 0x66, 0xe6, 0x15, 0x4b, 0xb0,
-	0x82, 0x25, 0xa0, 0x3c, 0x25, 0x57, 0x2e, 0x36, 0xe7, 0xfc, 0xbc, 0xb4, 0xcc, 0x74, 0x21, 0x6b,
-	0x2e, 0x1e, 0x88, 0x63, 0xd2, 0x41, 0x6e, 0x2e, 0x96, 0x60, 0x54, 0x60, 0xd6, 0xe0, 0x36, 0x92,
-	0xd0, 0x83, 0x7b, 0x12, 0xd5, 0x43, 0x41, 0xdc, 0x25, 0x70, 0x7e, 0x71, 0x12, 0x1b, 0x38, 0x04,
-	0x8c, 0x01, 0x01, 0x00, 0x00, 0xff, 0xff, 0x64, 0x57, 0xee, 0xfe, 0x13, 0x01, 0x00, 0x00,
+	// 297 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0x74, 0x92, 0xbd, 0x6e, 0xb3, 0x30,
+	0x14, 0x86, 0x15, 0xc8, 0x47, 0x3e, 0x0e, 0x0d, 0x83, 0x5b, 0x35, 0x1e, 0x3a, 0x20, 0x26, 0xa6,
+	0x0c, 0xfd, 0x99, 0x2a, 0x75, 0x41, 0x6d, 0xb7, 0xb6, 0x72, 0xbb, 0x23, 0x07, 0x4c, 0x64, 0x09,
+	0x30, 0xb2, 0x89, 0xe8, 0xcd, 0xf4, 0x5e, 0x2b, 0x1b, 0x0c, 0x61, 0xe8, 0xe6, 0x27, 0x4f, 0xce,
+	0xcb, 0xf1, 0x0b, 0x10, 0x76, 0xf4, 0x50, 0x31, 0x9a, 0x57, 0xfb, 0x56, 0x8a, 0x4e, 0xa0, 0xff,
+	0x96, 0xe3, 0x1f, 0x07, 0xc2, 0x2f, 0x0d, 0xaf, 0x52, 0x9c, 0xda, 0xcf, 0x96, 0xe5, 0x08, 0xc1,
+	0xba, 0xa1, 0x35, 0xc3, 0xab, 0x68, 0x95, 0xf8, 0xc4, 0x9c, 0xd1, 0x03, 0xec, 0xcc, 0x48, 0xa6,
+	0x49, 0x65, 0x42, 0x66, 0xad, 0x64, 0x25, 0xff, 0x66, 0x0a, 0x3b, 0x91, 0x9b, 0xf8, 0xe4, 0xca,
+	0xe8, 0x37, 0x6d, 0xdf, 0xe5, 0xc7, 0xe8, 0x10, 0x86, 0x8d, 0x64, 0xb4, 0x60, 0x52, 0x61, 0xd7,
+	0xfc, 0xcd, 0xa2, 0x36, 0xbd, 0xe4, 0x9d, 0x36, 0xeb, 0xc1, 0x8c, 0x88, 0xae, 0xc1, 0xa3, 0x45,
+	0xcd, 0x1b, 0x85, 0xff, 0x19, 0x31, 0x12, 0x7a, 0x82, 0x30, 0x17, 0xd5, 0xa9, 0x6e, 0x32, 0x1b,
+	0xe9, 0x45, 0x6e, 0x12, 0xdc, 0xee, 0xf6, 0xd3, 0xe5, 0x52, 0xe3, 0xc9, 0xa0, 0xc9, 0x36, 0x3f,
+	0x47, 0x74, 0x0f, 0x81, 0x14, 0x7d, 0x56, 0xf2, 0xca, 0x3c, 0x75, 0x63, 0x86, 0x2f, 0xe7, 0x61,
+	0x22, 0xfa, 0x17, 0xe3, 0x08, 0x48, 0x7b, 0x54, 0xf1, 0x33, 0x78, 0xa9, 0x68, 0x4a, 0x7e, 0x44,
+	0x8f, 0x70, 0x31, 0x54, 0x70, 0xd4, 0x4d, 0x29, 0xbc, 0x32, 0x01, 0x78, 0x0e, 0x58, 0xd6, 0x48,
+	0x82, 0x6e, 0x62, 0x15, 0xa7, 0xb0, 0x5d, 0x2c, 0xa7, 0xef, 0x3f, 0xac, 0x37, 0x04, 0xf9, 0xc4,
+	0xe2, 0x79, 0x67, 0xce, 0xa2, 0xb3, 0x38, 0x05, 0x7f, 0x5a, 0x12, 0xdd, 0x80, 0xdf, 0x4a, 0x56,
+	0xf0, 0x9c, 0x76, 0xf6, 0x55, 0xcd, 0x3f, 0xfc, 0x1d, 0x72, 0xf0, 0xcc, 0x17, 0x70, 0xf7, 0x3b,
+	0x00, 0x80, 0x74, 0x18, 0x4f, 0x13, 0x02, 0x00, 0x00,
 }
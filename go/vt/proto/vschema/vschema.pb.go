@@ -15,6 +15,8 @@ It has these top-level messages:
 	ColumnVindex
 	AutoIncrement
 	SrvVSchema
+	RoutingRules
+	RoutingRule
 */
 package vschema
 
@@ -148,6 +150,9 @@ func (*AutoIncrement) Descriptor() ([]byte, []int) { return fileDescriptor0, []i
 type SrvVSchema struct {
 	// keyspaces is a map of keyspace name -> Keyspace object.
 	Keyspaces map[string]*Keyspace `protobuf:"bytes,1,rep,name=keyspaces" json:"keyspaces,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// routing_rules redirect table references to other tables,
+	// e.g. while tables are moved between keyspaces.
+	RoutingRules *RoutingRules `protobuf:"bytes,2,opt,name=routing_rules,json=routingRules" json:"routing_rules,omitempty"`
 }
 
 func (m *SrvVSchema) Reset()                    { *m = SrvVSchema{} }
@@ -162,6 +167,46 @@ func (m *SrvVSchema) GetKeyspaces() map[string]*Keyspace {
 	return nil
 }
 
+func (m *SrvVSchema) GetRoutingRules() *RoutingRules {
+	if m != nil {
+		return m.RoutingRules
+	}
+	return nil
+}
+
+// RoutingRules is the list of routing rules. They are stored once in the
+// global topology, and copied into the SrvVSchema of each cell.
+type RoutingRules struct {
+	Rules []*RoutingRule `protobuf:"bytes,1,rep,name=rules" json:"rules,omitempty"`
+}
+
+func (m *RoutingRules) Reset()                    { *m = RoutingRules{} }
+func (m *RoutingRules) String() string            { return proto.CompactTextString(m) }
+func (*RoutingRules) ProtoMessage()               {}
+func (*RoutingRules) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{6} }
+
+func (m *RoutingRules) GetRules() []*RoutingRule {
+	if m != nil {
+		return m.Rules
+	}
+	return nil
+}
+
+// RoutingRule redirects the references to a table to another table.
+type RoutingRule struct {
+	// from_table is the table as referenced by the application, either
+	// qualified with a keyspace ("ks.table") or not ("table").
+	FromTable string `protobuf:"bytes,1,opt,name=from_table,json=fromTable" json:"from_table,omitempty"`
+	// to_table is the keyspace qualified table ("ks.table") the
+	// references are redirected to.
+	ToTable string `protobuf:"bytes,2,opt,name=to_table,json=toTable" json:"to_table,omitempty"`
+}
+
+func (m *RoutingRule) Reset()                    { *m = RoutingRule{} }
+func (m *RoutingRule) String() string            { return proto.CompactTextString(m) }
+func (*RoutingRule) ProtoMessage()               {}
+func (*RoutingRule) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{7} }
+
 func init() {
 	proto.RegisterType((*Keyspace)(nil), "vschema.Keyspace")
 	proto.RegisterType((*Vindex)(nil), "vschema.Vindex")
@@ -169,38 +214,44 @@ func init() {
 	proto.RegisterType((*ColumnVindex)(nil), "vschema.ColumnVindex")
 	proto.RegisterType((*AutoIncrement)(nil), "vschema.AutoIncrement")
 	proto.RegisterType((*SrvVSchema)(nil), "vschema.SrvVSchema")
+	proto.RegisterType((*RoutingRules)(nil), "vschema.RoutingRules")
+	proto.RegisterType((*RoutingRule)(nil), "vschema.RoutingRule")
 }
 
 func init() { proto.RegisterFile("vschema.proto", fileDescriptor0) }
 
 var fileDescriptor0 = []byte{
-	// 436 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0x74, 0x93, 0xd1, 0x6a, 0xd4, 0x40,
-	0x14, 0x86, 0x99, 0xc4, 0x4d, 0xb3, 0x27, 0x26, 0xd5, 0xa1, 0x96, 0x10, 0x11, 0x97, 0xa0, 0xb8,
-	0x57, 0xb9, 0xd8, 0x22, 0x68, 0x45, 0x51, 0x8a, 0x17, 0x45, 0x41, 0x49, 0xa5, 0xb7, 0x65, 0x9a,
-	0x3d, 0xd0, 0xd2, 0xcd, 0x24, 0x66, 0x92, 0x68, 0x5e, 0xc5, 0x1b, 0xc1, 0x37, 0xf0, 0x0d, 0xa5,
-	0x93, 0xc9, 0x74, 0xb2, 0x1b, 0xef, 0xe6, 0xe7, 0x9c, 0xff, 0x9f, 0x6f, 0xe6, 0xcc, 0x80, 0xdf,
-	0x8a, 0xec, 0x0a, 0x73, 0x96, 0x94, 0x55, 0x51, 0x17, 0x74, 0x4f, 0xc9, 0xf8, 0xaf, 0x05, 0xee,
-	0x27, 0xec, 0x44, 0xc9, 0x32, 0xa4, 0x21, 0xec, 0x89, 0x2b, 0x56, 0xad, 0x71, 0x1d, 0x92, 0x05,
-	0x59, 0xba, 0xe9, 0x20, 0xe9, 0x1b, 0x70, 0xdb, 0x6b, 0xbe, 0xc6, 0x9f, 0x28, 0x42, 0x6b, 0x61,
-	0x2f, 0xbd, 0xd5, 0xd3, 0x64, 0x48, 0x1c, 0xec, 0xc9, 0xb9, 0xea, 0xf8, 0xc8, 0xeb, 0xaa, 0x4b,
-	0xb5, 0x81, 0xbe, 0x04, 0xa7, 0x66, 0x97, 0x1b, 0x14, 0xa1, 0x2d, 0xad, 0x4f, 0x76, 0xad, 0xdf,
-	0x64, 0xbd, 0x37, 0xaa, 0xe6, 0xe8, 0x33, 0xf8, 0xa3, 0x44, 0xfa, 0x00, 0xec, 0x1b, 0xec, 0x24,
-	0xda, 0x3c, 0xbd, 0x5d, 0xd2, 0xe7, 0x30, 0x6b, 0xd9, 0xa6, 0xc1, 0xd0, 0x5a, 0x90, 0xa5, 0xb7,
-	0xda, 0xd7, 0xc1, 0xbd, 0x31, 0xed, 0xab, 0xc7, 0xd6, 0x2b, 0x12, 0x9d, 0x82, 0x67, 0x6c, 0x32,
-	0x91, 0xf5, 0x6c, 0x9c, 0x15, 0xe8, 0x2c, 0x69, 0x33, 0xa2, 0xe2, 0x3f, 0x04, 0x9c, 0x7e, 0x03,
-	0x4a, 0xe1, 0x5e, 0xdd, 0x95, 0xa8, 0x72, 0xe4, 0x9a, 0x1e, 0x81, 0x53, 0xb2, 0x8a, 0xe5, 0xc3,
-	0x4d, 0x3d, 0xde, 0xa2, 0x4a, 0xbe, 0xca, 0xaa, 0x3a, 0x6c, 0xdf, 0x4a, 0x0f, 0x60, 0x56, 0xfc,
-	0xe0, 0x58, 0x85, 0xb6, 0x4c, 0xea, 0x45, 0xf4, 0x1a, 0x3c, 0xa3, 0x79, 0x02, 0xfa, 0xc0, 0x84,
-	0x9e, 0x9b, 0x90, 0xbf, 0x08, 0xcc, 0x24, 0xf9, 0x24, 0xe3, 0x3b, 0xd8, 0xcf, 0x8a, 0x4d, 0x93,
-	0xf3, 0x8b, 0xad, 0xb1, 0x3e, 0xd2, 0xb0, 0x27, 0xb2, 0xae, 0x2e, 0x32, 0xc8, 0x0c, 0x85, 0x82,
-	0xbe, 0x85, 0x80, 0x35, 0x75, 0x71, 0x71, 0xcd, 0xb3, 0x0a, 0x73, 0xe4, 0xb5, 0xe4, 0xf6, 0x56,
-	0x87, 0xda, 0xfe, 0xa1, 0xa9, 0x8b, 0xd3, 0xa1, 0x9a, 0xfa, 0xcc, 0x94, 0xf1, 0x31, 0xdc, 0x37,
-	0xe3, 0xe9, 0x21, 0x38, 0xfd, 0x06, 0x0a, 0x52, 0xa9, 0x5b, 0x74, 0xce, 0xf2, 0xe1, 0x74, 0x72,
-	0x1d, 0x9f, 0x80, 0x3f, 0xca, 0xfe, 0xaf, 0x39, 0x02, 0x57, 0xe0, 0xf7, 0x06, 0x79, 0x36, 0x04,
-	0x68, 0x1d, 0xff, 0x26, 0x00, 0x67, 0x55, 0x7b, 0x7e, 0x26, 0x61, 0xe9, 0x7b, 0x98, 0xdf, 0xa8,
-	0xa7, 0x28, 0x42, 0x22, 0x2f, 0x22, 0xd6, 0x27, 0xb9, 0xeb, 0xd3, 0xef, 0x55, 0x0d, 0xef, 0xce,
-	0x14, 0x7d, 0x81, 0x60, 0x5c, 0x9c, 0x18, 0xd6, 0x8b, 0xf1, 0x0b, 0x7b, 0xb8, 0xf3, 0x0d, 0x8c,
-	0xf9, 0x5d, 0x3a, 0xf2, 0xa3, 0x1e, 0xfd, 0x0b, 0x00, 0x00, 0xff, 0xff, 0xf1, 0x47, 0x7e, 0x27,
-	0xb9, 0x03, 0x00, 0x00,
+	// 508 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x75, 0x54, 0xd1, 0x4e, 0xdb, 0x30,
+	0x14, 0x55, 0xda, 0x35, 0x4d, 0x6f, 0x48, 0x19, 0x56, 0x41, 0x25, 0x08, 0x0d, 0x45, 0x9b, 0x40,
+	0x7b, 0xe8, 0x03, 0x68, 0xd2, 0xd6, 0x89, 0x69, 0x08, 0x21, 0x84, 0x86, 0x04, 0x32, 0x13, 0xaf,
+	0x95, 0x49, 0x0d, 0x54, 0x34, 0x4e, 0x71, 0x92, 0x6e, 0xfd, 0x15, 0x1e, 0xf7, 0x07, 0x7c, 0x0f,
+	0x3f, 0x83, 0x63, 0x3b, 0xc1, 0x81, 0xec, 0xcd, 0x37, 0xe7, 0x9e, 0x73, 0x8f, 0xef, 0xbd, 0x0e,
+	0x78, 0xf3, 0x24, 0xbc, 0xa5, 0x11, 0x19, 0xcc, 0x78, 0x9c, 0xc6, 0xa8, 0xad, 0xc3, 0xe0, 0xb1,
+	0x01, 0xce, 0x2f, 0xba, 0x48, 0x66, 0x24, 0xa4, 0xa8, 0x0f, 0xed, 0xe4, 0x96, 0xf0, 0x31, 0x1d,
+	0xf7, 0xad, 0x2d, 0x6b, 0xc7, 0xc1, 0x45, 0x88, 0xbe, 0x83, 0x33, 0x9f, 0xb0, 0x31, 0xfd, 0x4b,
+	0x93, 0x7e, 0x63, 0xab, 0xb9, 0xe3, 0xee, 0x7e, 0x18, 0x14, 0x8a, 0x05, 0x7d, 0x70, 0xa9, 0x33,
+	0x8e, 0x58, 0xca, 0x17, 0xb8, 0x24, 0xa0, 0x2f, 0x60, 0xa7, 0xe4, 0x6a, 0x2a, 0xa8, 0x4d, 0x49,
+	0xdd, 0x7c, 0x4b, 0xfd, 0x2d, 0x71, 0x45, 0xd4, 0xc9, 0xfe, 0x29, 0x78, 0x15, 0x45, 0xf4, 0x1e,
+	0x9a, 0x77, 0x74, 0x21, 0xad, 0x75, 0x70, 0x7e, 0x44, 0x9f, 0xa0, 0x35, 0x27, 0xd3, 0x8c, 0x0a,
+	0x4f, 0x96, 0x10, 0x5e, 0x2e, 0x85, 0x15, 0x11, 0x2b, 0x74, 0xd8, 0xf8, 0x6a, 0xf9, 0x27, 0xe0,
+	0x1a, 0x45, 0x6a, 0xb4, 0x3e, 0x56, 0xb5, 0xba, 0xa5, 0x96, 0xa4, 0x19, 0x52, 0xc1, 0x3f, 0x0b,
+	0x6c, 0x55, 0x00, 0x21, 0x78, 0x97, 0x2e, 0x66, 0x54, 0xeb, 0xc8, 0x33, 0xda, 0x03, 0x7b, 0x46,
+	0x38, 0x89, 0x8a, 0x4e, 0x6d, 0xbc, 0x72, 0x35, 0x38, 0x97, 0xa8, 0xbe, 0xac, 0x4a, 0x45, 0x3d,
+	0x68, 0xc5, 0x7f, 0x18, 0xe5, 0xa2, 0x45, 0xb9, 0x92, 0x0a, 0xfc, 0x6f, 0xe0, 0x1a, 0xc9, 0x35,
+	0xa6, 0x7b, 0xa6, 0xe9, 0x8e, 0x69, 0xf2, 0xc1, 0x82, 0x96, 0x74, 0x5e, 0xeb, 0xf1, 0x07, 0x2c,
+	0x87, 0xf1, 0x34, 0x8b, 0xd8, 0xe8, 0xd5, 0x58, 0x57, 0x4b, 0xb3, 0x87, 0x12, 0xd7, 0x8d, 0xec,
+	0x86, 0x46, 0x24, 0x46, 0xba, 0x0f, 0x5d, 0x92, 0xa5, 0xf1, 0x68, 0xc2, 0x42, 0x4e, 0x23, 0xca,
+	0x52, 0xe9, 0xdb, 0xdd, 0x5d, 0x2b, 0xe9, 0x07, 0x02, 0x3e, 0x29, 0x50, 0xec, 0x11, 0x33, 0x0c,
+	0x86, 0xb0, 0x64, 0xca, 0xa3, 0x35, 0xb0, 0x55, 0x01, 0x6d, 0x52, 0x47, 0xb9, 0x75, 0x46, 0xa2,
+	0xe2, 0x76, 0xf2, 0x1c, 0x1c, 0x82, 0x57, 0xd1, 0xfe, 0x2f, 0xd9, 0x07, 0x27, 0xa1, 0xf7, 0x19,
+	0x65, 0x61, 0x21, 0x50, 0xc6, 0xc1, 0x93, 0x05, 0x70, 0xc1, 0xe7, 0x97, 0x17, 0xd2, 0x2c, 0xfa,
+	0x09, 0x9d, 0x3b, 0xbd, 0x8a, 0x89, 0x50, 0xc9, 0x1b, 0x11, 0x94, 0x37, 0x79, 0xc9, 0x2b, 0xf7,
+	0x55, 0x0f, 0xef, 0x85, 0x84, 0x86, 0xe0, 0xf1, 0x38, 0x4b, 0x27, 0xec, 0x66, 0xc4, 0xb3, 0xa9,
+	0x6c, 0xa7, 0x55, 0x69, 0x27, 0x56, 0x28, 0xce, 0x41, 0xbc, 0xc4, 0x8d, 0xc8, 0x3f, 0x83, 0x6e,
+	0x55, 0xb8, 0x66, 0xd0, 0xdb, 0xd5, 0xed, 0x5c, 0x79, 0xf3, 0x84, 0xcc, 0xd9, 0x8b, 0xf6, 0x9a,
+	0xe5, 0xd0, 0x67, 0x68, 0x29, 0x53, 0xea, 0x6a, 0xbd, 0x3a, 0x53, 0x58, 0xa5, 0x04, 0xc7, 0xe0,
+	0x1a, 0x5f, 0xd1, 0x26, 0xc0, 0x35, 0x8f, 0xa3, 0x91, 0x7c, 0x93, 0xda, 0x50, 0x27, 0xff, 0xa2,
+	0x76, 0x6b, 0x1d, 0x1c, 0xb1, 0x05, 0x0a, 0x54, 0x3d, 0x6e, 0xa7, 0xb1, 0x84, 0xae, 0x6c, 0xf9,
+	0xa7, 0xd9, 0x7b, 0x06, 0x20, 0x43, 0x8d, 0x1f, 0x7a, 0x04, 0x00, 0x00,
 }
@@ -0,0 +1,187 @@
+// Copyright 2016, Google Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package schemamanager
+
+import (
+	"fmt"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/youtube/vitess/go/vt/schemamanager/onlineddl"
+	"github.com/youtube/vitess/go/vt/topo"
+	"github.com/youtube/vitess/go/vt/wrangler"
+)
+
+// OnlineExecutor queues schema changes as online schema migrations in
+// the topology, instead of applying them. vtctld releases the queued
+// migrations of a keyspace one at a time, and the master of each shard
+// runs them, see the onlineddl package.
+type OnlineExecutor struct {
+	wr       *wrangler.Wrangler
+	keyspace string
+	shards   []string
+	isClosed bool
+	// now returns the current time. Replaced in tests.
+	now func() time.Time
+}
+
+// NewOnlineExecutor creates a new OnlineExecutor instance.
+func NewOnlineExecutor(wr *wrangler.Wrangler) *OnlineExecutor {
+	return &OnlineExecutor{
+		wr:       wr,
+		isClosed: true,
+		now:      time.Now,
+	}
+}
+
+// Open reads the shards of the keyspace.
+func (exec *OnlineExecutor) Open(ctx context.Context, keyspace string) error {
+	if !exec.isClosed {
+		return nil
+	}
+	shards, err := exec.wr.TopoServer().GetShardNames(ctx, keyspace)
+	if err != nil {
+		return fmt.Errorf("unable to get shard names for keyspace: %s, error: %v", keyspace, err)
+	}
+	if len(shards) == 0 {
+		return fmt.Errorf("keyspace: %s does not contain any shard", keyspace)
+	}
+	exec.keyspace = keyspace
+	exec.shards = shards
+	exec.isClosed = false
+	return nil
+}
+
+// Validate checks all statements are ALTER TABLE statements that
+// can run online.
+func (exec *OnlineExecutor) Validate(ctx context.Context, sqls []string) error {
+	if exec.isClosed {
+		return fmt.Errorf("executor is closed")
+	}
+	for _, sql := range sqls {
+		if _, _, _, err := onlineddl.ParseAlterTable(sql); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Execute queues one schema migration per statement. The migrations
+// run in order, after the ones already queued for the keyspace.
+func (exec *OnlineExecutor) Execute(ctx context.Context, sqls []string) *ExecuteResult {
+	execResult := ExecuteResult{}
+	execResult.Sqls = sqls
+	if exec.isClosed {
+		execResult.ExecutorErr = "executor is closed"
+		return &execResult
+	}
+	startTime := exec.now()
+	defer func() { execResult.TotalTimeSpent = time.Since(startTime) }()
+
+	for index, sql := range sqls {
+		execResult.CurSQLIndex = index
+		_, table, _, err := onlineddl.ParseAlterTable(sql)
+		if err != nil {
+			execResult.ExecutorErr = err.Error()
+			return &execResult
+		}
+		now := exec.now()
+		sm := &topo.SchemaMigration{
+			SQL:        sql,
+			Table:      table,
+			State:      topo.SchemaMigrationQueued,
+			CreateTime: now.Unix(),
+			Shards:     make(map[string]*topo.ShardSchemaMigration),
+		}
+		for _, shard := range exec.shards {
+			sm.Shards[shard] = &topo.ShardSchemaMigration{
+				State: topo.SchemaMigrationQueued,
+			}
+		}
+		id := NewSchemaMigrationID(now)
+		if _, err := exec.wr.TopoServer().CreateSchemaMigration(ctx, exec.keyspace, id, sm); err != nil {
+			execResult.ExecutorErr = fmt.Sprintf("cannot queue schema migration for %v: %v", sql, err)
+			return &execResult
+		}
+		exec.wr.Logger().Printf("Queued schema migration %v: %v\n", id, sql)
+	}
+	execResult.SuccessShards = make([]ShardResult, len(exec.shards))
+	for i, shard := range exec.shards {
+		execResult.SuccessShards[i] = ShardResult{Shard: shard}
+	}
+	return &execResult
+}
+
+// Close clears the executor states.
+func (exec *OnlineExecutor) Close() {
+	if !exec.isClosed {
+		exec.shards = nil
+		exec.isClosed = true
+	}
+}
+
+var _ Executor = (*OnlineExecutor)(nil)
+
+// NewSchemaMigrationID returns the id of a migration created at the
+// given time. Ids sort in creation order, and only contain digits so
+// they can be used in table names.
+func NewSchemaMigrationID(now time.Time) string {
+	now = now.UTC()
+	return fmt.Sprintf("%v%09d", now.Format("20060102150405"), now.Nanosecond())
+}
+
+// CancelSchemaMigration cancels a schema migration. A queued migration
+// is cancelled right away. The shards of a running migration stop and
+// drop their shadow table the next time they report their progress,
+// and vtctld marks the migration cancelled once they all stopped.
+// The shards that were already cut over keep the new schema.
+func CancelSchemaMigration(ctx context.Context, ts topo.Server, keyspace, id string) error {
+	_, err := ts.UpdateSchemaMigrationFields(ctx, keyspace, id, func(sm *topo.SchemaMigration) error {
+		switch sm.State {
+		case topo.SchemaMigrationQueued:
+			sm.State = topo.SchemaMigrationCancelled
+			sm.EndTime = time.Now().Unix()
+			for _, ssm := range sm.Shards {
+				ssm.State = topo.SchemaMigrationCancelled
+			}
+		case topo.SchemaMigrationRunning:
+			if sm.CancelRequested {
+				return topo.ErrNoUpdateNeeded
+			}
+		default:
+			return fmt.Errorf("schema migration %v is %v, it cannot be cancelled", id, sm.State)
+		}
+		sm.CancelRequested = true
+		return nil
+	})
+	return err
+}
+
+// RetrySchemaMigration runs a failed or cancelled migration again on
+// the shards where it didn't complete. It is queued again if another
+// migration of the keyspace is running.
+func RetrySchemaMigration(ctx context.Context, ts topo.Server, keyspace, id string) error {
+	_, err := ts.UpdateSchemaMigrationFields(ctx, keyspace, id, func(sm *topo.SchemaMigration) error {
+		switch sm.State {
+		case topo.SchemaMigrationFailed, topo.SchemaMigrationCancelled:
+		default:
+			return fmt.Errorf("schema migration %v is %v, only failed or cancelled migrations can be retried", id, sm.State)
+		}
+		sm.State = topo.SchemaMigrationQueued
+		sm.CancelRequested = false
+		sm.StartTime = 0
+		sm.EndTime = 0
+		for _, ssm := range sm.Shards {
+			if ssm.State != topo.SchemaMigrationComplete {
+				*ssm = topo.ShardSchemaMigration{
+					State: topo.SchemaMigrationQueued,
+				}
+			}
+		}
+		return nil
+	})
+	return err
+}
@@ -0,0 +1,101 @@
+// Copyright 2016, Google Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package schemamanager
+
+import (
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/youtube/vitess/go/vt/logutil"
+	"github.com/youtube/vitess/go/vt/topo"
+	"github.com/youtube/vitess/go/vt/wrangler"
+	"github.com/youtube/vitess/go/vt/zktopo/zktestserver"
+
+	topodatapb "github.com/youtube/vitess/go/vt/proto/topodata"
+)
+
+func TestOnlineExecutor(t *testing.T) {
+	ctx := context.Background()
+	ts := zktestserver.New(t, []string{"cell1"})
+	ts.CreateKeyspace(ctx, "ks", &topodatapb.Keyspace{})
+	for _, shard := range []string{"-80", "80-"} {
+		ts.Impl.CreateShard(ctx, "ks", shard, &topodatapb.Shard{})
+	}
+	wr := wrangler.New(logutil.NewConsoleLogger(), ts, newFakeTabletManagerClient())
+	executor := NewOnlineExecutor(wr)
+	now := time.Unix(1476835200, 5)
+	executor.now = func() time.Time {
+		now = now.Add(time.Second)
+		return now
+	}
+
+	if err := executor.Open(ctx, "ks"); err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer executor.Close()
+	if err := executor.Validate(ctx, []string{"create table t (id int)"}); err == nil {
+		t.Errorf("Validate should reject statements other than ALTER TABLE")
+	}
+	sqls := []string{"alter table t1 add column c int", "alter table t2 add index (c)"}
+	if err := executor.Validate(ctx, sqls); err != nil {
+		t.Fatalf("Validate failed: %v", err)
+	}
+	result := executor.Execute(ctx, sqls)
+	if result.ExecutorErr != "" || len(result.SuccessShards) != 2 {
+		t.Fatalf("Execute: %+v, want success on 2 shards", result)
+	}
+
+	ids, err := ts.GetSchemaMigrationIDs(ctx, "ks")
+	if err != nil {
+		t.Fatalf("GetSchemaMigrationIDs failed: %v", err)
+	}
+	want := []string{"20161019000002000000005", "20161019000003000000005"}
+	if len(ids) != 2 || ids[0] != want[0] || ids[1] != want[1] {
+		t.Fatalf("GetSchemaMigrationIDs: %v, want %v", ids, want)
+	}
+	smi, err := ts.GetSchemaMigration(ctx, "ks", ids[1])
+	if err != nil {
+		t.Fatalf("GetSchemaMigration failed: %v", err)
+	}
+	if smi.Table != "t2" || smi.State != topo.SchemaMigrationQueued || len(smi.Shards) != 2 || smi.Shards["80-"].State != topo.SchemaMigrationQueued {
+		t.Errorf("unexpected queued migration: %+v", smi.SchemaMigration)
+	}
+
+	// A queued migration is cancelled right away, and can be retried.
+	if err := CancelSchemaMigration(ctx, ts, "ks", ids[1]); err != nil {
+		t.Fatalf("CancelSchemaMigration failed: %v", err)
+	}
+	if smi, _ := ts.GetSchemaMigration(ctx, "ks", ids[1]); smi.State != topo.SchemaMigrationCancelled || smi.Shards["-80"].State != topo.SchemaMigrationCancelled {
+		t.Errorf("cancelled migration: %+v", smi.SchemaMigration)
+	}
+	if err := CancelSchemaMigration(ctx, ts, "ks", ids[1]); err == nil {
+		t.Errorf("CancelSchemaMigration on a cancelled migration should fail")
+	}
+	if err := RetrySchemaMigration(ctx, ts, "ks", ids[1]); err != nil {
+		t.Fatalf("RetrySchemaMigration failed: %v", err)
+	}
+	if smi, _ := ts.GetSchemaMigration(ctx, "ks", ids[1]); smi.State != topo.SchemaMigrationQueued || smi.Shards["-80"].State != topo.SchemaMigrationQueued || smi.CancelRequested {
+		t.Errorf("retried migration: %+v", smi.SchemaMigration)
+	}
+	if err := RetrySchemaMigration(ctx, ts, "ks", ids[1]); err == nil {
+		t.Errorf("RetrySchemaMigration on a queued migration should fail")
+	}
+
+	// A running migration is only flagged, the tablets stop it.
+	if _, err := ts.UpdateSchemaMigrationFields(ctx, "ks", ids[0], func(sm *topo.SchemaMigration) error {
+		sm.State = topo.SchemaMigrationRunning
+		return nil
+	}); err != nil {
+		t.Fatalf("UpdateSchemaMigrationFields failed: %v", err)
+	}
+	if err := CancelSchemaMigration(ctx, ts, "ks", ids[0]); err != nil {
+		t.Fatalf("CancelSchemaMigration failed: %v", err)
+	}
+	if smi, _ := ts.GetSchemaMigration(ctx, "ks", ids[0]); smi.State != topo.SchemaMigrationRunning || !smi.CancelRequested {
+		t.Errorf("cancelled running migration: %+v", smi.SchemaMigration)
+	}
+}
@@ -0,0 +1,694 @@
+// Copyright 2016, Google Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package onlineddl runs online schema migrations on the master of a
+// shard. The ALTER TABLE statement is applied to an empty shadow copy of
+// the table, the rows are copied to it in chunks, and the changes made
+// to the table in the meantime are replayed from the binlogs. Once the
+// shadow table has caught up, the table is locked for a brief moment,
+// the last changes are replayed, and the shadow table is renamed in
+// place of the original one.
+package onlineddl
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/golang/glog"
+	"golang.org/x/net/context"
+
+	"github.com/youtube/vitess/go/sqltypes"
+	"github.com/youtube/vitess/go/vt/binlog"
+	"github.com/youtube/vitess/go/vt/dbconnpool"
+	"github.com/youtube/vitess/go/vt/discovery"
+	"github.com/youtube/vitess/go/vt/mysqlctl"
+	"github.com/youtube/vitess/go/vt/mysqlctl/replication"
+	"github.com/youtube/vitess/go/vt/sqlparser"
+	"github.com/youtube/vitess/go/vt/throttler"
+
+	querypb "github.com/youtube/vitess/go/vt/proto/query"
+	tabletmanagerdatapb "github.com/youtube/vitess/go/vt/proto/tabletmanagerdata"
+	topodatapb "github.com/youtube/vitess/go/vt/proto/topodata"
+)
+
+// Config has the tunables of a Migrator.
+type Config struct {
+	// ChunkSize is the number of rows copied at once.
+	ChunkSize int
+	// MaxRate is the maximum number of chunks copied, or binlog
+	// transactions replayed, per second.
+	// Use throttler.MaxRateModuleDisabled to disable it.
+	MaxRate int64
+	// MaxReplicationLag is the replication lag (in seconds) of the
+	// replicas above which the migration slows down.
+	// Use throttler.ReplicationLagModuleDisabled to disable it.
+	MaxReplicationLag int64
+	// CutOverTimeout is the maximum time the table stays locked
+	// during the cut-over.
+	CutOverTimeout time.Duration
+	// CutOverAttempts is the number of times the cut-over is tried
+	// before giving up.
+	CutOverAttempts int
+}
+
+// DefaultConfig is the default configuration of a Migrator.
+var DefaultConfig = Config{
+	ChunkSize:         1000,
+	MaxRate:           throttler.MaxRateModuleDisabled,
+	MaxReplicationLag: throttler.ReplicationLagModuleDisabled,
+	CutOverTimeout:    3 * time.Second,
+	CutOverAttempts:   10,
+}
+
+// ProgressFunc is called regularly by Run, while copying the rows and
+// while replaying the binlogs, with the number of rows copied so far,
+// and the estimated number of rows of the table. If it returns an
+// error, the migration is aborted with that error.
+type ProgressFunc func(rowsCopied, rowsTotal int64) error
+
+// streamFunc streams the binlog events from startPos until ctx is done.
+type streamFunc func(ctx context.Context, startPos replication.Position, send func(*querypb.StreamEvent) error) error
+
+const (
+	// catchUpProgressInterval is how often the progress function is
+	// called while waiting for the binlog stream.
+	catchUpProgressInterval = time.Second
+	// renameWaitingCheckInterval is how often the cut-over checks
+	// whether its RENAME TABLE is waiting for the table locks.
+	renameWaitingCheckInterval = 10 * time.Millisecond
+)
+
+// errCutOverTimeout is returned by cutOver if the shadow table didn't
+// catch up in time.
+var errCutOverTimeout = errors.New("shadow table didn't catch up within the cut-over timeout")
+
+// pkChange is a binlog transaction that changed some rows of the table.
+type pkChange struct {
+	pks [][]sqltypes.Value
+	pos replication.Position
+}
+
+// Migrator runs one online schema migration.
+type Migrator struct {
+	mysqld       mysqlctl.MysqlDaemon
+	dbName       string
+	config       Config
+	table        string
+	alterOptions string
+	shadowTable  string
+	oldTable     string
+	throttler    *throttler.Throttler
+	stream       streamFunc
+
+	// The fields below are set by Run.
+	conn      *dbconnpool.DBConnection
+	columns   []string
+	pkColumns []string
+	changes   chan *pkChange
+	applied   replication.Position
+
+	// streamErr is set when the binlog stream stops.
+	streamErrMu sync.Mutex
+	streamErr   error
+}
+
+// ParseAlterTable parses an ALTER TABLE statement, and returns the
+// table it alters, its database qualifier if any, and its options
+// (everything after the table name).
+func ParseAlterTable(sql string) (qualifier, table, options string, err error) {
+	stmt, err := sqlparser.Parse(sql)
+	if err != nil {
+		return "", "", "", err
+	}
+	ddl, ok := stmt.(*sqlparser.DDL)
+	if !ok || ddl.Action != sqlparser.AlterStr {
+		return "", "", "", fmt.Errorf("online schema migrations only support ALTER TABLE statements: %v", sql)
+	}
+
+	// The parser doesn't keep the alter options, so find where the
+	// table name ends: ALTER [IGNORE] TABLE [<db>.]<name> <options>.
+	tokenizer := sqlparser.NewStringTokenizer(sql)
+	if typ, _ := tokenizer.Scan(); typ != sqlparser.ALTER {
+		return "", "", "", fmt.Errorf("cannot parse ALTER TABLE statement: %v", sql)
+	}
+	switch typ, _ := tokenizer.Scan(); typ {
+	case sqlparser.TABLE:
+	case sqlparser.IGNORE:
+		return "", "", "", fmt.Errorf("ALTER IGNORE TABLE is not supported by online schema migrations: %v", sql)
+	default:
+		return "", "", "", fmt.Errorf("online schema migrations only support ALTER TABLE statements: %v", sql)
+	}
+	typ, name := tokenizer.Scan()
+	if typ != sqlparser.ID {
+		return "", "", "", fmt.Errorf("cannot parse ALTER TABLE statement: %v", sql)
+	}
+	table = string(name)
+	// Position is one character past the end of the last token,
+	// because the tokenizer reads ahead.
+	end := tokenizer.Position - 1
+	if typ, _ := tokenizer.Scan(); typ == '.' {
+		typ, name := tokenizer.Scan()
+		if typ != sqlparser.ID {
+			return "", "", "", fmt.Errorf("cannot parse ALTER TABLE statement: %v", sql)
+		}
+		qualifier, table = table, string(name)
+		end = tokenizer.Position - 1
+	}
+	options = strings.TrimSpace(sql[end:])
+	options = strings.TrimSpace(strings.TrimSuffix(options, ";"))
+	if options == "" {
+		return "", "", "", fmt.Errorf("ALTER TABLE statement has no alter options: %v", sql)
+	}
+	return qualifier, table, options, nil
+}
+
+// ShadowTableName returns the name of the shadow table of a migration.
+func ShadowTableName(id string) string {
+	return fmt.Sprintf("_vt_osc_%v", id)
+}
+
+// OldTableName returns the name the original table is renamed to when
+// a migration is cut over. It is not dropped automatically: its data
+// may be needed to undo the migration, and dropping a big table is
+// an expensive operation that should be scheduled.
+func OldTableName(id string) string {
+	return fmt.Sprintf("_vt_osc_%v_old", id)
+}
+
+// NewMigrator creates a Migrator for the ALTER TABLE statement of the
+// migration with the given id. Close must be called when it's done.
+func NewMigrator(mysqld mysqlctl.MysqlDaemon, dbName, id, sql string, config Config) (*Migrator, error) {
+	qualifier, table, options, err := ParseAlterTable(sql)
+	if err != nil {
+		return nil, err
+	}
+	if qualifier != "" && qualifier != dbName {
+		return nil, fmt.Errorf("ALTER TABLE of %v.%v cannot run on database %v", qualifier, table, dbName)
+	}
+	if config.ChunkSize <= 0 {
+		return nil, fmt.Errorf("invalid chunk size: %v", config.ChunkSize)
+	}
+	t, err := throttler.NewThrottler("OnlineDDL/"+id, "chunks", 1, config.MaxRate, config.MaxReplicationLag)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{
+		mysqld:       mysqld,
+		dbName:       dbName,
+		config:       config,
+		table:        table,
+		alterOptions: options,
+		shadowTable:  ShadowTableName(id),
+		oldTable:     OldTableName(id),
+		throttler:    t,
+		stream: func(ctx context.Context, startPos replication.Position, send func(*querypb.StreamEvent) error) error {
+			return binlog.NewEventStreamer(dbName, mysqld, startPos, 0, send).Stream(ctx)
+		},
+	}, nil
+}
+
+// Table returns the table the migration alters.
+func (m *Migrator) Table() string {
+	return m.table
+}
+
+// StatsUpdate records the replication lag of the replicas of the shard
+// in the throttler. It is part of the
+// discovery.HealthCheckStatsListener interface.
+func (m *Migrator) StatsUpdate(ts *discovery.TabletStats) {
+	if ts.Target == nil || (ts.Target.TabletType != topodatapb.TabletType_REPLICA && ts.Target.TabletType != topodatapb.TabletType_RDONLY) {
+		return
+	}
+	m.throttler.RecordReplicationLag(time.Now(), ts)
+}
+
+// Close releases the throttler of the migration.
+func (m *Migrator) Close() {
+	m.throttler.Close()
+}
+
+// Run runs the migration. If it fails, or ctx is canceled, the shadow
+// table is dropped and the original table is left untouched.
+func (m *Migrator) Run(ctx context.Context, progress ProgressFunc) (err error) {
+	m.conn, err = m.mysqld.GetDbaConnection()
+	if err != nil {
+		return err
+	}
+	defer m.conn.Close()
+
+	td, err := m.tableDefinition(m.table)
+	if err != nil {
+		return err
+	}
+	if len(td.PrimaryKeyColumns) == 0 {
+		return fmt.Errorf("table %v has no primary key, it cannot be migrated online", m.table)
+	}
+	m.pkColumns = td.PrimaryKeyColumns
+
+	// All the changes made after this position are replayed on the
+	// shadow table, so it must be read before the copy starts.
+	startPos, err := m.mysqld.MasterPosition()
+	if err != nil {
+		return err
+	}
+	m.applied = startPos
+
+	if err := m.createShadowTable(); err != nil {
+		return err
+	}
+	cutOver := false
+	defer func() {
+		if cutOver {
+			return
+		}
+		if _, dropErr := m.conn.ExecuteFetch("DROP TABLE IF EXISTS "+m.qualified(m.shadowTable), 0, false); dropErr != nil {
+			log.Warningf("cannot drop shadow table %v: %v", m.shadowTable, dropErr)
+		}
+	}()
+
+	shadowTD, err := m.tableDefinition(m.shadowTable)
+	if err != nil {
+		return err
+	}
+	if strings.Join(shadowTD.PrimaryKeyColumns, ",") != strings.Join(td.PrimaryKeyColumns, ",") {
+		return fmt.Errorf("online schema migrations cannot change the primary key of %v", m.table)
+	}
+	m.columns = commonColumns(td.Columns, shadowTD.Columns)
+
+	rowsCopied, err := m.copyRows(ctx, func(rowsCopied int64) error {
+		return progress(rowsCopied, int64(td.RowCount))
+	})
+	if err != nil {
+		return err
+	}
+
+	// Replay the binlogs from the start position.
+	streamCtx, cancel := context.WithCancel(ctx)
+	streamDone := make(chan struct{})
+	defer func() {
+		cancel()
+		<-streamDone
+	}()
+	m.changes = make(chan *pkChange, 100)
+	go func() {
+		defer close(streamDone)
+		err := m.stream(streamCtx, startPos, m.sendEvent(streamCtx))
+		m.streamErrMu.Lock()
+		m.streamErr = err
+		m.streamErrMu.Unlock()
+		close(m.changes)
+	}()
+
+	for attempt := 1; attempt <= m.config.CutOverAttempts; attempt++ {
+		target, err := m.mysqld.MasterPosition()
+		if err != nil {
+			return err
+		}
+		if err := m.catchUp(ctx, target, func() error {
+			return progress(rowsCopied, int64(td.RowCount))
+		}); err != nil {
+			return err
+		}
+		if err := progress(rowsCopied, int64(td.RowCount)); err != nil {
+			return err
+		}
+		err = m.cutOver(ctx)
+		switch err {
+		case nil:
+			cutOver = true
+			return nil
+		case errCutOverTimeout:
+			log.Infof("online schema migration of %v: cut-over attempt %v/%v timed out, retrying", m.table, attempt, m.config.CutOverAttempts)
+		default:
+			return err
+		}
+	}
+	return fmt.Errorf("cut-over of %v failed %v times, the table is probably too busy: %v", m.table, m.config.CutOverAttempts, errCutOverTimeout)
+}
+
+// tableDefinition returns the definition of a table of the database.
+func (m *Migrator) tableDefinition(table string) (*tabletmanagerdatapb.TableDefinition, error) {
+	sd, err := m.mysqld.GetSchema(m.dbName, []string{"^" + regexp.QuoteMeta(table) + "$"}, nil, false)
+	if err != nil {
+		return nil, err
+	}
+	if len(sd.TableDefinitions) != 1 {
+		return nil, fmt.Errorf("table %v not found", table)
+	}
+	return sd.TableDefinitions[0], nil
+}
+
+// createShadowTable creates the shadow table, and applies the
+// ALTER to it. A shadow table left over by a previous attempt is
+// dropped first.
+func (m *Migrator) createShadowTable() error {
+	for _, sql := range []string{
+		"DROP TABLE IF EXISTS " + m.qualified(m.shadowTable),
+		fmt.Sprintf("CREATE TABLE %v LIKE %v", m.qualified(m.shadowTable), m.qualified(m.table)),
+		fmt.Sprintf("ALTER TABLE %v %v", m.qualified(m.shadowTable), m.alterOptions),
+	} {
+		if _, err := m.conn.ExecuteFetch(sql, 0, false); err != nil {
+			return fmt.Errorf("cannot create shadow table %v: %v", m.shadowTable, err)
+		}
+	}
+	return nil
+}
+
+// copyRows copies all rows of the table to the shadow table, in
+// primary key order, one chunk at a time. It returns the number of
+// rows copied.
+func (m *Migrator) copyRows(ctx context.Context, progress func(rowsCopied int64) error) (int64, error) {
+	pkList := m.columnList(m.pkColumns)
+	columnList := m.columnList(m.columns)
+	var rowsCopied int64
+	var lastPK []sqltypes.Value
+	for {
+		if err := m.throttle(ctx); err != nil {
+			return rowsCopied, err
+		}
+
+		// Find the upper bound of the chunk.
+		var where string
+		if lastPK != nil {
+			where = fmt.Sprintf(" WHERE (%v) > %v", pkList, tuple(lastPK))
+		}
+		qr, err := m.conn.ExecuteFetch(fmt.Sprintf("SELECT %v FROM %v%v ORDER BY %v LIMIT %v", pkList, m.qualified(m.table), where, pkList, m.config.ChunkSize), m.config.ChunkSize, false)
+		if err != nil {
+			return rowsCopied, err
+		}
+		if len(qr.Rows) == 0 {
+			return rowsCopied, nil
+		}
+		upperPK := qr.Rows[len(qr.Rows)-1]
+
+		// And copy it. Rows inserted in the range since the previous
+		// query are copied too, which is fine: the binlog replay
+		// makes all rows consistent in the end.
+		if lastPK != nil {
+			where = fmt.Sprintf("(%v) > %v AND ", pkList, tuple(lastPK))
+		}
+		sql := fmt.Sprintf("INSERT IGNORE INTO %v (%v) SELECT %v FROM %v WHERE %v(%v) <= %v", m.qualified(m.shadowTable), columnList, columnList, m.qualified(m.table), where, pkList, tuple(upperPK))
+		if _, err := m.conn.ExecuteFetch(sql, 0, false); err != nil {
+			return rowsCopied, err
+		}
+		rowsCopied += int64(len(qr.Rows))
+		lastPK = upperPK
+		if err := progress(rowsCopied); err != nil {
+			return rowsCopied, err
+		}
+	}
+}
+
+// sendEvent returns the callback of the binlog stream. It sends the
+// primary keys changed by each transaction on the changes channel.
+func (m *Migrator) sendEvent(ctx context.Context) func(*querypb.StreamEvent) error {
+	return func(event *querypb.StreamEvent) error {
+		pos, err := replication.DecodePosition(event.EventToken.Position)
+		if err != nil {
+			return err
+		}
+		change := &pkChange{pos: pos}
+		for _, stmt := range event.Statements {
+			switch stmt.Category {
+			case querypb.StreamEvent_Statement_DML:
+				if stmt.TableName != m.table {
+					continue
+				}
+				for _, row := range stmt.PrimaryKeyValues {
+					change.pks = append(change.pks, sqltypes.MakeRowTrusted(stmt.PrimaryKeyFields, row))
+				}
+			case querypb.StreamEvent_Statement_DDL:
+				if tableName(string(stmt.Sql)) == m.table {
+					return fmt.Errorf("table %v was altered during the migration: %s", m.table, stmt.Sql)
+				}
+			case querypb.StreamEvent_Statement_Error:
+				// The changes made without going through vttablet
+				// don't have the primary keys in a comment, so they
+				// can't be replayed.
+				if tableName(string(stmt.Sql)) == m.table {
+					return fmt.Errorf("table %v was changed outside of vttablet during the migration: %s", m.table, stmt.Sql)
+				}
+			}
+		}
+		select {
+		case m.changes <- change:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// tableName returns the table a DML or DDL statement changes, or ""
+// if it can't be parsed.
+func tableName(sql string) string {
+	stmt, err := sqlparser.Parse(sql)
+	if err != nil {
+		return ""
+	}
+	switch stmt := stmt.(type) {
+	case *sqlparser.Insert:
+		return string(stmt.Table.Name)
+	case *sqlparser.Update:
+		return string(stmt.Table.Name)
+	case *sqlparser.Delete:
+		return string(stmt.Table.Name)
+	case *sqlparser.DDL:
+		return string(stmt.Table)
+	}
+	return ""
+}
+
+// catchUp replays the changes until the target position is applied.
+// Outside of the cut-over, progress is set: the replay is throttled,
+// and progress is called after each replayed transaction, and every
+// catchUpProgressInterval while waiting for the binlog stream.
+func (m *Migrator) catchUp(ctx context.Context, target replication.Position, progress func() error) error {
+	var tick <-chan time.Time
+	if progress != nil {
+		ticker := time.NewTicker(catchUpProgressInterval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+	for !m.applied.AtLeast(target) {
+		if progress != nil {
+			if err := m.throttle(ctx); err != nil {
+				return err
+			}
+		}
+		select {
+		case change, ok := <-m.changes:
+			if !ok {
+				m.streamErrMu.Lock()
+				defer m.streamErrMu.Unlock()
+				return fmt.Errorf("binlog stream stopped: %v", m.streamErr)
+			}
+			if err := m.apply(change.pks); err != nil {
+				return err
+			}
+			m.applied = change.pos
+			if progress != nil {
+				if err := progress(); err != nil {
+					return err
+				}
+			}
+		case <-tick:
+			if err := progress(); err != nil {
+				return err
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+// apply copies the current version of the rows to the shadow table.
+// The rows that don't exist any more are deleted.
+func (m *Migrator) apply(pks [][]sqltypes.Value) error {
+	if len(pks) == 0 {
+		return nil
+	}
+	pkList := m.columnList(m.pkColumns)
+	columnList := m.columnList(m.columns)
+	var in bytes.Buffer
+	for i, pk := range pks {
+		if i > 0 {
+			in.WriteString(", ")
+		}
+		in.WriteString(tuple(pk))
+	}
+	for _, sql := range []string{
+		fmt.Sprintf("DELETE FROM %v WHERE (%v) IN (%v)", m.qualified(m.shadowTable), pkList, in.String()),
+		fmt.Sprintf("INSERT INTO %v (%v) SELECT %v FROM %v WHERE (%v) IN (%v)", m.qualified(m.shadowTable), columnList, columnList, m.qualified(m.table), pkList, in.String()),
+	} {
+		if _, err := m.conn.ExecuteFetch(sql, 0, false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// cutOver locks the tables, replays the last changes, and swaps the
+// shadow table in. It returns errCutOverTimeout if the changes couldn't
+// be replayed within CutOverTimeout, in which case the tables are
+// unlocked and nothing else changed.
+//
+// RENAME TABLE can't run under LOCK TABLES, so the swap is done by a
+// second connection: its RENAME TABLE waits for the locks, and once
+// it's seen waiting the tables are unlocked. The pending RENAME TABLE
+// then runs before the writes blocked by the locks, and swaps both
+// tables atomically: no write can reach the table in between.
+func (m *Migrator) cutOver(ctx context.Context) error {
+	renameConn, err := m.mysqld.GetDbaConnection()
+	if err != nil {
+		return err
+	}
+	defer renameConn.Close()
+	qr, err := renameConn.ExecuteFetch("SELECT CONNECTION_ID()", 1, false)
+	if err != nil {
+		return err
+	}
+	if len(qr.Rows) != 1 || len(qr.Rows[0]) != 1 {
+		return fmt.Errorf("unexpected result for SELECT CONNECTION_ID(): %v", qr.Rows)
+	}
+	renameConnID := qr.Rows[0][0].String()
+
+	if _, err := m.conn.ExecuteFetch(fmt.Sprintf("LOCK TABLES %v WRITE, %v WRITE", m.qualified(m.table), m.qualified(m.shadowTable)), 0, false); err != nil {
+		return err
+	}
+	unlock := func() {
+		if _, err := m.conn.ExecuteFetch("UNLOCK TABLES", 0, false); err != nil {
+			log.Warningf("cannot unlock tables: %v", err)
+		}
+	}
+
+	// No write can happen on the table any more, so once this
+	// position is applied, the shadow table is up to date.
+	target, err := m.mysqld.MasterPosition()
+	if err != nil {
+		unlock()
+		return err
+	}
+	catchUpCtx, cancel := context.WithTimeout(ctx, m.config.CutOverTimeout)
+	defer cancel()
+	if err := m.catchUp(catchUpCtx, target, nil /* progress */); err != nil {
+		unlock()
+		if catchUpCtx.Err() == context.DeadlineExceeded && ctx.Err() == nil {
+			return errCutOverTimeout
+		}
+		return err
+	}
+
+	renameDone := make(chan error, 1)
+	go func() {
+		_, err := renameConn.ExecuteFetch(fmt.Sprintf("RENAME TABLE %v TO %v, %v TO %v", m.qualified(m.table), m.qualified(m.oldTable), m.qualified(m.shadowTable), m.qualified(m.table)), 0, false)
+		renameDone <- err
+	}()
+	waitingQuery := fmt.Sprintf("SELECT COUNT(*) FROM information_schema.processlist WHERE id = %v AND state = 'Waiting for table metadata lock'", renameConnID)
+	for waiting := false; !waiting; {
+		select {
+		case err := <-renameDone:
+			// It can only fail while we hold the locks.
+			unlock()
+			if err != nil {
+				return fmt.Errorf("cut-over of %v failed: %v", m.table, err)
+			}
+			return nil
+		case <-catchUpCtx.Done():
+			// The RENAME TABLE must not run once the tables are unlocked.
+			if _, err := m.conn.ExecuteFetch("KILL QUERY "+renameConnID, 0, false); err != nil {
+				log.Warningf("cannot kill the RENAME TABLE of the cut-over of %v: %v", m.table, err)
+			}
+			<-renameDone
+			unlock()
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return errCutOverTimeout
+		case <-time.After(renameWaitingCheckInterval):
+			qr, err := m.conn.ExecuteFetch(waitingQuery, 1, false)
+			if err != nil {
+				log.Warningf("cannot check the RENAME TABLE of the cut-over of %v is waiting: %v", m.table, err)
+				continue
+			}
+			waiting = len(qr.Rows) == 1 && len(qr.Rows[0]) == 1 && qr.Rows[0][0].String() != "0"
+		}
+	}
+	unlock()
+	if err := <-renameDone; err != nil {
+		return fmt.Errorf("cut-over of %v failed: %v", m.table, err)
+	}
+	return nil
+}
+
+// throttle waits until the throttler lets the next chunk through.
+func (m *Migrator) throttle(ctx context.Context) error {
+	for {
+		backoff := m.throttler.Throttle(0)
+		if backoff == throttler.NotThrottled {
+			return nil
+		}
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// qualified returns the escaped, database qualified name of a table.
+func (m *Migrator) qualified(table string) string {
+	return escape(m.dbName) + "." + escape(table)
+}
+
+// columnList returns the comma separated list of the escaped columns.
+func (m *Migrator) columnList(columns []string) string {
+	escaped := make([]string, len(columns))
+	for i, c := range columns {
+		escaped[i] = escape(c)
+	}
+	return strings.Join(escaped, ", ")
+}
+
+// commonColumns returns the columns of the shadow table that are also
+// in the original table, in the shadow table order. Added columns get
+// their default value, and dropped columns are not copied.
+func commonColumns(columns, shadowColumns []string) []string {
+	exists := make(map[string]bool, len(columns))
+	for _, c := range columns {
+		exists[strings.ToLower(c)] = true
+	}
+	var result []string
+	for _, c := range shadowColumns {
+		if exists[strings.ToLower(c)] {
+			result = append(result, c)
+		}
+	}
+	return result
+}
+
+// tuple returns the SQL tuple of the values, e.g. (1, 'a').
+func tuple(values []sqltypes.Value) string {
+	var buf bytes.Buffer
+	buf.WriteByte('(')
+	for i, v := range values {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		v.EncodeSQL(&buf)
+	}
+	buf.WriteByte(')')
+	return buf.String()
+}
+
+// escape adds surrounding backticks (`) to an MySQL identifier.
+func escape(identifier string) string {
+	return "`" + strings.Replace(identifier, "`", "``", -1) + "`"
+}
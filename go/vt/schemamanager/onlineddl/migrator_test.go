@@ -0,0 +1,241 @@
+// Copyright 2016, Google Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package onlineddl
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+
+	"golang.org/x/net/context"
+
+	"github.com/youtube/vitess/go/sqltypes"
+	"github.com/youtube/vitess/go/vt/mysqlctl"
+	"github.com/youtube/vitess/go/vt/mysqlctl/replication"
+	"github.com/youtube/vitess/go/vt/vttest/fakesqldb"
+
+	querypb "github.com/youtube/vitess/go/vt/proto/query"
+	tabletmanagerdatapb "github.com/youtube/vitess/go/vt/proto/tabletmanagerdata"
+)
+
+func TestParseAlterTable(t *testing.T) {
+	testcases := []struct {
+		sql       string
+		qualifier string
+		table     string
+		options   string
+		err       bool
+	}{{
+		sql:     "alter table t add column c int",
+		table:   "t",
+		options: "add column c int",
+	}, {
+		sql:       "alter table db.t add column c int",
+		qualifier: "db",
+		table:     "t",
+		options:   "add column c int",
+	}, {
+		sql:       "ALTER TABLE `db`.`t` ADD INDEX (c)",
+		qualifier: "db",
+		table:     "t",
+		options:   "ADD INDEX (c)",
+	}, {
+		sql:     "ALTER TABLE `t` ADD INDEX (c), DROP COLUMN d;",
+		table:   "t",
+		options: "ADD INDEX (c), DROP COLUMN d",
+	}, {
+		sql: "alter ignore table t add unique key (c)",
+		err: true,
+	}, {
+		sql: "alter table t rename to u",
+		err: true,
+	}, {
+		sql: "alter view v as select 1 from dual",
+		err: true,
+	}, {
+		sql: "create table t (id int)",
+		err: true,
+	}, {
+		sql: "alter table t",
+		err: true,
+	}}
+	for _, tc := range testcases {
+		qualifier, table, options, err := ParseAlterTable(tc.sql)
+		if tc.err {
+			if err == nil {
+				t.Errorf("ParseAlterTable(%q): %q, %q, %q, want an error", tc.sql, qualifier, table, options)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseAlterTable(%q) failed: %v", tc.sql, err)
+			continue
+		}
+		if qualifier != tc.qualifier || table != tc.table || options != tc.options {
+			t.Errorf("ParseAlterTable(%q): %q, %q, %q, want %q, %q, %q", tc.sql, qualifier, table, options, tc.qualifier, tc.table, tc.options)
+		}
+	}
+}
+
+func TestNewMigratorOtherDatabase(t *testing.T) {
+	mysqld := mysqlctl.NewFakeMysqlDaemon(fakesqldb.Register())
+	if _, err := NewMigrator(mysqld, "vt_ks", "1", "alter table vt_other.t add column c int", DefaultConfig); err == nil {
+		t.Errorf("NewMigrator should fail for a table of another database")
+	}
+}
+
+func intRows(values ...int) *sqltypes.Result {
+	qr := &sqltypes.Result{}
+	for _, v := range values {
+		qr.Rows = append(qr.Rows, []sqltypes.Value{sqltypes.MakeTrusted(sqltypes.Int64, []byte(strconv.Itoa(v)))})
+	}
+	return qr
+}
+
+func TestMigratorRun(t *testing.T) {
+	db := fakesqldb.Register()
+	mysqld := mysqlctl.NewFakeMysqlDaemon(db)
+	mysqld.Schema = &tabletmanagerdatapb.SchemaDefinition{
+		TableDefinitions: []*tabletmanagerdatapb.TableDefinition{{
+			Name:              "t",
+			Columns:           []string{"id", "a", "b"},
+			PrimaryKeyColumns: []string{"id"},
+			Type:              "BASE TABLE",
+			RowCount:          3,
+		}, {
+			Name:              "_vt_osc_1",
+			Columns:           []string{"id", "b", "c"},
+			PrimaryKeyColumns: []string{"id"},
+			Type:              "BASE TABLE",
+		}},
+	}
+	pos1, _ := replication.DecodePosition("MariaDB/0-1-1")
+	pos2, _ := replication.DecodePosition("MariaDB/0-1-2")
+	mysqld.CurrentMasterPosition = pos1
+
+	empty := &sqltypes.Result{}
+	for _, sql := range []string{
+		"DROP TABLE IF EXISTS `vt_ks`.`_vt_osc_1`",
+		"CREATE TABLE `vt_ks`.`_vt_osc_1` LIKE `vt_ks`.`t`",
+		"ALTER TABLE `vt_ks`.`_vt_osc_1` drop column a, add column c int",
+		"INSERT IGNORE INTO `vt_ks`.`_vt_osc_1` (`id`, `b`) SELECT `id`, `b` FROM `vt_ks`.`t` WHERE (`id`) <= (2)",
+		"INSERT IGNORE INTO `vt_ks`.`_vt_osc_1` (`id`, `b`) SELECT `id`, `b` FROM `vt_ks`.`t` WHERE (`id`) > (2) AND (`id`) <= (3)",
+		"SELECT `id` FROM `vt_ks`.`t` WHERE (`id`) > (3) ORDER BY `id` LIMIT 2",
+		"DELETE FROM `vt_ks`.`_vt_osc_1` WHERE (`id`) IN ((2))",
+		"INSERT INTO `vt_ks`.`_vt_osc_1` (`id`, `b`) SELECT `id`, `b` FROM `vt_ks`.`t` WHERE (`id`) IN ((2))",
+		"LOCK TABLES `vt_ks`.`t` WRITE, `vt_ks`.`_vt_osc_1` WRITE",
+		"RENAME TABLE `vt_ks`.`t` TO `vt_ks`.`_vt_osc_1_old`, `vt_ks`.`_vt_osc_1` TO `vt_ks`.`t`",
+		"UNLOCK TABLES",
+	} {
+		db.AddQuery(sql, empty)
+	}
+	db.AddQuery("SELECT CONNECTION_ID()", intRows(42))
+	db.AddQuery("SELECT COUNT(*) FROM information_schema.processlist WHERE id = 42 AND state = 'Waiting for table metadata lock'", intRows(1))
+	db.AddQuery("SELECT `id` FROM `vt_ks`.`t` ORDER BY `id` LIMIT 2", intRows(1, 2))
+	db.AddQuery("SELECT `id` FROM `vt_ks`.`t` WHERE (`id`) > (2) ORDER BY `id` LIMIT 2", intRows(3))
+
+	config := DefaultConfig
+	config.ChunkSize = 2
+	m, err := NewMigrator(mysqld, "vt_ks", "1", "alter table t drop column a, add column c int", config)
+	if err != nil {
+		t.Fatalf("NewMigrator failed: %v", err)
+	}
+	defer m.Close()
+	m.stream = func(ctx context.Context, startPos replication.Position, send func(*querypb.StreamEvent) error) error {
+		if !startPos.Equal(pos1) {
+			return errors.New("unexpected start position")
+		}
+		// Row 2 changed after the copy started.
+		if err := send(&querypb.StreamEvent{
+			Statements: []*querypb.StreamEvent_Statement{{
+				Category:         querypb.StreamEvent_Statement_DML,
+				TableName:        "t",
+				PrimaryKeyFields: []*querypb.Field{{Name: "id", Type: sqltypes.Int64}},
+				PrimaryKeyValues: []*querypb.Row{{Lengths: []int64{1}, Values: []byte("2")}},
+			}},
+			EventToken: &querypb.EventToken{Position: replication.EncodePosition(pos2)},
+		}); err != nil {
+			return err
+		}
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	var progress []int64
+	err = m.Run(context.Background(), func(rowsCopied, rowsTotal int64) error {
+		if rowsTotal != 3 {
+			t.Errorf("rowsTotal: %v, want 3", rowsTotal)
+		}
+		progress = append(progress, rowsCopied)
+		// Writes happen on the table during the copy.
+		mysqld.CurrentMasterPosition = pos2
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	// The progress is also reported while replaying the binlogs.
+	if len(progress) != 4 || progress[0] != 2 || progress[1] != 3 || progress[2] != 3 || progress[3] != 3 {
+		t.Errorf("progress: %v, want [2 3 3 3]", progress)
+	}
+	for _, sql := range []string{
+		"INSERT INTO `vt_ks`.`_vt_osc_1` (`id`, `b`) SELECT `id`, `b` FROM `vt_ks`.`t` WHERE (`id`) IN ((2))",
+		"RENAME TABLE `vt_ks`.`t` TO `vt_ks`.`_vt_osc_1_old`, `vt_ks`.`_vt_osc_1` TO `vt_ks`.`t`",
+		"UNLOCK TABLES",
+	} {
+		if got := db.GetQueryCalledNum(sql); got != 1 {
+			t.Errorf("%v was called %v times, want 1", sql, got)
+		}
+	}
+	if got := db.GetQueryCalledNum("DROP TABLE IF EXISTS `vt_ks`.`_vt_osc_1`"); got != 1 {
+		t.Errorf("shadow table should only be dropped before the migration, was dropped %v times", got)
+	}
+}
+
+func TestMigratorRunCancelled(t *testing.T) {
+	db := fakesqldb.Register()
+	mysqld := mysqlctl.NewFakeMysqlDaemon(db)
+	mysqld.Schema = &tabletmanagerdatapb.SchemaDefinition{
+		TableDefinitions: []*tabletmanagerdatapb.TableDefinition{{
+			Name:              "t",
+			Columns:           []string{"id", "a"},
+			PrimaryKeyColumns: []string{"id"},
+			Type:              "BASE TABLE",
+		}, {
+			Name:              "_vt_osc_1",
+			Columns:           []string{"id", "a"},
+			PrimaryKeyColumns: []string{"id"},
+			Type:              "BASE TABLE",
+		}},
+	}
+	empty := &sqltypes.Result{}
+	for _, sql := range []string{
+		"DROP TABLE IF EXISTS `vt_ks`.`_vt_osc_1`",
+		"CREATE TABLE `vt_ks`.`_vt_osc_1` LIKE `vt_ks`.`t`",
+		"ALTER TABLE `vt_ks`.`_vt_osc_1` add index (a)",
+		"INSERT IGNORE INTO `vt_ks`.`_vt_osc_1` (`id`, `a`) SELECT `id`, `a` FROM `vt_ks`.`t` WHERE (`id`) <= (1)",
+	} {
+		db.AddQuery(sql, empty)
+	}
+	db.AddQuery("SELECT `id` FROM `vt_ks`.`t` ORDER BY `id` LIMIT 1000", intRows(1))
+
+	m, err := NewMigrator(mysqld, "vt_ks", "1", "alter table t add index (a)", DefaultConfig)
+	if err != nil {
+		t.Fatalf("NewMigrator failed: %v", err)
+	}
+	defer m.Close()
+
+	cancelled := errors.New("cancelled")
+	err = m.Run(context.Background(), func(rowsCopied, rowsTotal int64) error {
+		return cancelled
+	})
+	if err != cancelled {
+		t.Errorf("Run: %v, want %v", err, cancelled)
+	}
+	// The shadow table is dropped before and after the migration.
+	if got := db.GetQueryCalledNum("DROP TABLE IF EXISTS `vt_ks`.`_vt_osc_1`"); got != 2 {
+		t.Errorf("shadow table was dropped %v times, want 2", got)
+	}
+}
@@ -109,3 +109,27 @@ func StringIn(str string, values ...string) bool {
 	}
 	return false
 }
+
+// ParseBoolExpr parses a standalone boolean expression, like the
+// condition of a WHERE clause. Bind variables are not allowed.
+func ParseBoolExpr(expr string) (BoolExpr, error) {
+	stmt, err := Parse("select 1 from dual where " + expr)
+	if err != nil {
+		return nil, err
+	}
+	sel, ok := stmt.(*Select)
+	if !ok || sel.Where == nil || sel.GroupBy != nil || sel.Having != nil || sel.OrderBy != nil || sel.Limit != nil || sel.Lock != "" {
+		return nil, fmt.Errorf("not a boolean expression: %s", expr)
+	}
+	err = Walk(func(node SQLNode) (bool, error) {
+		switch node.(type) {
+		case ValArg, ListArg:
+			return false, fmt.Errorf("bind variables are not allowed: %s", expr)
+		}
+		return true, nil
+	}, sel.Where.Expr)
+	if err != nil {
+		return nil, err
+	}
+	return sel.Where.Expr, nil
+}
@@ -0,0 +1,55 @@
+// Copyright 2016, Google Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqlparser
+
+import "testing"
+
+func TestParseBoolExpr(t *testing.T) {
+	testcases := []struct {
+		in  string
+		out string
+		err bool
+	}{{
+		in:  "region = 'US'",
+		out: "region = 'US'",
+	}, {
+		in:  "a = 1 or b in (select id from t)",
+		out: "a = 1 or b in (select id from t)",
+	}, {
+		in:  "",
+		err: true,
+	}, {
+		in:  "a = 1 union select secret from t",
+		err: true,
+	}, {
+		in:  "a = 1 order by a",
+		err: true,
+	}, {
+		in:  "a = 1 limit 1",
+		err: true,
+	}, {
+		in:  "a = :a",
+		err: true,
+	}, {
+		in:  "a in ::list",
+		err: true,
+	}}
+	for _, tc := range testcases {
+		expr, err := ParseBoolExpr(tc.in)
+		if tc.err {
+			if err == nil {
+				t.Errorf("ParseBoolExpr(%q): %v, want an error", tc.in, String(expr))
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseBoolExpr(%q) failed: %v", tc.in, err)
+			continue
+		}
+		if got := String(expr); got != tc.out {
+			t.Errorf("ParseBoolExpr(%q): %q, want %q", tc.in, got, tc.out)
+		}
+	}
+}
@@ -29,6 +29,20 @@ import (
 func Parse(sql string) (Statement, error) {
 	tokenizer := NewStringTokenizer(sql)
 	if yyParse(tokenizer) != 0 {
+		// The grammar doesn't cover all the CREATE TABLE,
+		// ALTER TABLE and SHOW syntax. If the beginning of
+		// the statement could be parsed, return what we know
+		// about it. The DDLs are marked Partial: the rest
+		// of the statement may also be a syntax error. What
+		// was parsed of it is dropped, as it's incomplete.
+		if tokenizer.partialStatement != nil {
+			if ddl, ok := tokenizer.partialStatement.(*DDL); ok {
+				ddl.TableSpec = nil
+				ddl.AlterSpecs = nil
+				ddl.Partial = true
+			}
+			return tokenizer.partialStatement, nil
+		}
 		return nil, errors.New(tokenizer.LastError)
 	}
 	return tokenizer.ParseTree, nil
@@ -91,14 +105,19 @@ type Statement interface {
 	SQLNode
 }
 
-func (*Union) iStatement()  {}
-func (*Select) iStatement() {}
-func (*Insert) iStatement() {}
-func (*Update) iStatement() {}
-func (*Delete) iStatement() {}
-func (*Set) iStatement()    {}
-func (*DDL) iStatement()    {}
-func (*Other) iStatement()  {}
+func (*Union) iStatement()    {}
+func (*Select) iStatement()   {}
+func (*Insert) iStatement()   {}
+func (*Update) iStatement()   {}
+func (*Delete) iStatement()   {}
+func (*Set) iStatement()      {}
+func (*DDL) iStatement()      {}
+func (*Show) iStatement()     {}
+func (*Use) iStatement()      {}
+func (*Begin) iStatement()    {}
+func (*Commit) iStatement()   {}
+func (*Rollback) iStatement() {}
+func (*Other) iStatement()    {}
 
 // SelectStatement any SELECT statement.
 type SelectStatement interface {
@@ -373,11 +392,27 @@ func (node *Set) WalkSubtree(visit Visit) error {
 // DDL represents a CREATE, ALTER, DROP or RENAME statement.
 // Table is set for AlterStr, DropStr, RenameStr.
 // NewName is set for AlterStr, CreateStr, RenameStr.
+// Qualifier is the database of the table of a CREATE TABLE or
+// ALTER TABLE, if it was given.
+// TableSpec is set for a CREATE TABLE with a table definition,
+// and AlterSpecs for an ALTER TABLE whose operations could
+// be parsed. They're nil otherwise.
+// IfNotExists is set for a CREATE TABLE IF NOT EXISTS.
+// Partial is set for a CREATE TABLE or ALTER TABLE of which only
+// the beginning, up to the table name, could be parsed: the table
+// definition or the operations are missing, and Format doesn't
+// return the whole statement. It's also set for a CREATE INDEX or
+// DROP INDEX, which is formatted as an ALTER TABLE of its table.
 type DDL struct {
-	Action   string
-	Table    TableIdent
-	NewName  TableIdent
-	IfExists bool
+	Action      string
+	Qualifier   TableIdent
+	Table       TableIdent
+	NewName     TableIdent
+	IfExists    bool
+	IfNotExists bool
+	TableSpec   *TableSpec
+	AlterSpecs  AlterSpecs
+	Partial     bool
 }
 
 // DDL strings.
@@ -392,7 +427,16 @@ const (
 func (node *DDL) Format(buf *TrackedBuffer) {
 	switch node.Action {
 	case CreateStr:
-		buf.Myprintf("%s table %v", node.Action, node.NewName)
+		notExists := ""
+		if node.IfNotExists {
+			notExists = " if not exists"
+		}
+		newName := &TableName{Qualifier: node.Qualifier, Name: node.NewName}
+		if node.TableSpec == nil {
+			buf.Myprintf("%s table%s %v", node.Action, notExists, newName)
+			return
+		}
+		buf.Myprintf("%s table%s %v %v", node.Action, notExists, newName, node.TableSpec)
 	case DropStr:
 		exists := ""
 		if node.IfExists {
@@ -400,9 +444,9 @@ func (node *DDL) Format(buf *TrackedBuffer) {
 		}
 		buf.Myprintf("%s table%s %v", node.Action, exists, node.Table)
 	case RenameStr:
-		buf.Myprintf("%s table %v %v", node.Action, node.Table, node.NewName)
+		buf.Myprintf("%s table %v %v", node.Action, &TableName{Qualifier: node.Qualifier, Name: node.Table}, node.NewName)
 	default:
-		buf.Myprintf("%s table %v", node.Action, node.Table)
+		buf.Myprintf("%s table %v%v", node.Action, &TableName{Qualifier: node.Qualifier, Name: node.Table}, node.AlterSpecs)
 	}
 }
 
@@ -413,12 +457,510 @@ func (node *DDL) WalkSubtree(visit Visit) error {
 	}
 	return Walk(
 		visit,
+		node.Qualifier,
 		node.Table,
 		node.NewName,
+		node.TableSpec,
+		node.AlterSpecs,
+	)
+}
+
+// TableSpec describes the columns, indexes and options
+// of a CREATE TABLE statement.
+type TableSpec struct {
+	Columns []*ColumnDefinition
+	Indexes []*IndexDefinition
+	Options TableOptions
+}
+
+// Format formats the node.
+func (node *TableSpec) Format(buf *TrackedBuffer) {
+	prefix := "("
+	for _, col := range node.Columns {
+		buf.Myprintf("%s%v", prefix, col)
+		prefix = ", "
+	}
+	for _, idx := range node.Indexes {
+		buf.Myprintf("%s%v", prefix, idx)
+		prefix = ", "
+	}
+	buf.WriteString(")")
+	if node.Options != nil {
+		buf.Myprintf(" %v", node.Options)
+	}
+}
+
+// WalkSubtree walks the nodes of the subtree
+func (node *TableSpec) WalkSubtree(visit Visit) error {
+	if node == nil {
+		return nil
+	}
+	for _, n := range node.Columns {
+		if err := Walk(visit, n); err != nil {
+			return err
+		}
+	}
+	for _, n := range node.Indexes {
+		if err := Walk(visit, n); err != nil {
+			return err
+		}
+	}
+	return Walk(visit, node.Options)
+}
+
+// ColumnDefinition describes a column of a table.
+type ColumnDefinition struct {
+	Name ColIdent
+	Type ColumnType
+}
+
+// Format formats the node.
+func (node *ColumnDefinition) Format(buf *TrackedBuffer) {
+	buf.Myprintf("%v %v", node.Name, node.Type)
+}
+
+// WalkSubtree walks the nodes of the subtree
+func (node *ColumnDefinition) WalkSubtree(visit Visit) error {
+	if node == nil {
+		return nil
+	}
+	return Walk(
+		visit,
+		node.Name,
+		node.Type,
+	)
+}
+
+// ColumnType represents the type of a column, and its attributes.
+// Length and Scale are nil if they were not specified. Type is
+// lower-cased.
+type ColumnType struct {
+	Type       string
+	Length     NumVal
+	Scale      NumVal
+	EnumValues []string
+	Unsigned   bool
+	Zerofill   bool
+	Charset    string
+	Collate    string
+
+	// Null is NullStr or NotNullStr if the column
+	// nullability was specified, "" otherwise.
+	Null          string
+	Default       ValExpr
+	OnUpdate      ValExpr
+	Autoincrement bool
+	KeyOpt        string
+	Comment       StrVal
+}
+
+// ColumnType.Null
+const (
+	NullStr    = "null"
+	NotNullStr = "not null"
+)
+
+// ColumnType.KeyOpt
+const (
+	ColKeyPrimaryStr   = "primary key"
+	ColKeyUniqueStr    = "unique"
+	ColKeyUniqueKeyStr = "unique key"
+)
+
+// Format formats the node.
+func (node ColumnType) Format(buf *TrackedBuffer) {
+	buf.Myprintf("%s", node.Type)
+	if node.Length != nil {
+		buf.Myprintf("(%v", node.Length)
+		if node.Scale != nil {
+			buf.Myprintf(", %v", node.Scale)
+		}
+		buf.WriteString(")")
+	}
+	if node.EnumValues != nil {
+		prefix := "("
+		for _, v := range node.EnumValues {
+			buf.Myprintf("%s%v", prefix, StrVal(v))
+			prefix = ", "
+		}
+		buf.WriteString(")")
+	}
+	if node.Unsigned {
+		buf.WriteString(" unsigned")
+	}
+	if node.Zerofill {
+		buf.WriteString(" zerofill")
+	}
+	if node.Charset != "" {
+		buf.Myprintf(" character set %s", node.Charset)
+	}
+	if node.Collate != "" {
+		buf.Myprintf(" collate %s", node.Collate)
+	}
+	if node.Null != "" {
+		buf.Myprintf(" %s", node.Null)
+	}
+	if node.Default != nil {
+		buf.Myprintf(" default %v", node.Default)
+	}
+	if node.OnUpdate != nil {
+		buf.Myprintf(" on update %v", node.OnUpdate)
+	}
+	if node.Autoincrement {
+		buf.WriteString(" auto_increment")
+	}
+	if node.KeyOpt != "" {
+		buf.Myprintf(" %s", node.KeyOpt)
+	}
+	if node.Comment != nil {
+		buf.Myprintf(" comment %v", node.Comment)
+	}
+}
+
+// WalkSubtree walks the nodes of the subtree
+func (node ColumnType) WalkSubtree(visit Visit) error {
+	return Walk(
+		visit,
+		node.Default,
+		node.OnUpdate,
+	)
+}
+
+// IndexDefinition describes an index of a table.
+type IndexDefinition struct {
+	Info    *IndexInfo
+	Columns []*IndexColumn
+	Using   ColIdent
+}
+
+// Format formats the node.
+func (node *IndexDefinition) Format(buf *TrackedBuffer) {
+	buf.Myprintf("%v", node.Info)
+	prefix := " ("
+	for _, col := range node.Columns {
+		buf.Myprintf("%s%v", prefix, col)
+		prefix = ", "
+	}
+	buf.WriteString(")")
+	if node.Using.Original() != "" {
+		buf.Myprintf(" using %v", node.Using)
+	}
+}
+
+// WalkSubtree walks the nodes of the subtree
+func (node *IndexDefinition) WalkSubtree(visit Visit) error {
+	if node == nil {
+		return nil
+	}
+	if err := Walk(visit, node.Info); err != nil {
+		return err
+	}
+	for _, n := range node.Columns {
+		if err := Walk(visit, n); err != nil {
+			return err
+		}
+	}
+	return Walk(visit, node.Using)
+}
+
+// IndexInfo describes the type and the name of an index.
+// Type is the lower-cased index type, as written in the
+// statement: "primary key", "unique key", "key", "index",
+// "fulltext key", etc.
+type IndexInfo struct {
+	Type    string
+	Name    ColIdent
+	Primary bool
+	Unique  bool
+}
+
+// Format formats the node.
+func (node *IndexInfo) Format(buf *TrackedBuffer) {
+	buf.Myprintf("%s", node.Type)
+	if !node.Primary && node.Name.Original() != "" {
+		buf.Myprintf(" %v", node.Name)
+	}
+}
+
+// WalkSubtree walks the nodes of the subtree
+func (node *IndexInfo) WalkSubtree(visit Visit) error {
+	if node == nil {
+		return nil
+	}
+	return Walk(visit, node.Name)
+}
+
+// IndexColumn describes a column of an index. Length is
+// set for prefix indexes.
+type IndexColumn struct {
+	Column ColIdent
+	Length NumVal
+}
+
+// Format formats the node.
+func (node *IndexColumn) Format(buf *TrackedBuffer) {
+	buf.Myprintf("%v", node.Column)
+	if node.Length != nil {
+		buf.Myprintf("(%v)", node.Length)
+	}
+}
+
+// WalkSubtree walks the nodes of the subtree
+func (node *IndexColumn) WalkSubtree(visit Visit) error {
+	if node == nil {
+		return nil
+	}
+	return Walk(visit, node.Column)
+}
+
+// TableOptions represents the options of a table,
+// like its engine or its default character set.
+type TableOptions []*TableOption
+
+// Format formats the node.
+func (node TableOptions) Format(buf *TrackedBuffer) {
+	var prefix string
+	for _, n := range node {
+		buf.Myprintf("%s%v", prefix, n)
+		prefix = " "
+	}
+}
+
+// WalkSubtree walks the nodes of the subtree
+func (node TableOptions) WalkSubtree(visit Visit) error {
+	for _, n := range node {
+		if err := Walk(visit, n); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// TableOption represents a table option. Name is lower-cased.
+// Value is set if the value is a string or a number, and
+// String if it's an identifier, like an engine name.
+type TableOption struct {
+	Name   string
+	Value  ValExpr
+	String string
+}
+
+// Format formats the node.
+func (node *TableOption) Format(buf *TrackedBuffer) {
+	if node.Value != nil {
+		buf.Myprintf("%s=%v", node.Name, node.Value)
+		return
+	}
+	buf.Myprintf("%s=%s", node.Name, node.String)
+}
+
+// WalkSubtree walks the nodes of the subtree
+func (node *TableOption) WalkSubtree(visit Visit) error {
+	if node == nil {
+		return nil
+	}
+	return Walk(visit, node.Value)
+}
+
+// AlterSpecs represents the operations of an ALTER TABLE statement.
+type AlterSpecs []*AlterSpec
+
+// Format formats the node.
+func (node AlterSpecs) Format(buf *TrackedBuffer) {
+	prefix := " "
+	for _, n := range node {
+		buf.Myprintf("%s%v", prefix, n)
+		prefix = ", "
+	}
+}
+
+// WalkSubtree walks the nodes of the subtree
+func (node AlterSpecs) WalkSubtree(visit Visit) error {
+	for _, n := range node {
+		if err := Walk(visit, n); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AlterSpec represents an operation of an ALTER TABLE statement.
+// Column is set for AddColumnStr, ModifyColumnStr and ChangeColumnStr.
+// Index is set for AddIndexStr.
+// Name is the column or index to drop for DropColumnStr and
+// DropIndexStr, and the column to change for ChangeColumnStr.
+// Options is set for TableOptionsStr.
+// First and After give the position of an added or changed column.
+type AlterSpec struct {
+	Action  string
+	Name    ColIdent
+	Column  *ColumnDefinition
+	Index   *IndexDefinition
+	Options TableOptions
+	First   bool
+	After   ColIdent
+}
+
+// AlterSpec.Action
+const (
+	AddColumnStr      = "add column"
+	AddIndexStr       = "add"
+	DropColumnStr     = "drop column"
+	DropIndexStr      = "drop index"
+	DropPrimaryKeyStr = "drop primary key"
+	ModifyColumnStr   = "modify column"
+	ChangeColumnStr   = "change column"
+	TableOptionsStr   = "options"
+)
+
+// Format formats the node.
+func (node *AlterSpec) Format(buf *TrackedBuffer) {
+	switch node.Action {
+	case AddColumnStr, ModifyColumnStr:
+		buf.Myprintf("%s %v", node.Action, node.Column)
+	case ChangeColumnStr:
+		buf.Myprintf("%s %v %v", node.Action, node.Name, node.Column)
+	case AddIndexStr:
+		buf.Myprintf("%s %v", node.Action, node.Index)
+	case DropColumnStr, DropIndexStr:
+		buf.Myprintf("%s %v", node.Action, node.Name)
+	case DropPrimaryKeyStr:
+		buf.WriteString(node.Action)
+	case TableOptionsStr:
+		buf.Myprintf("%v", node.Options)
+	}
+	if node.First {
+		buf.WriteString(" first")
+	} else if node.After.Original() != "" {
+		buf.Myprintf(" after %v", node.After)
+	}
+}
+
+// WalkSubtree walks the nodes of the subtree
+func (node *AlterSpec) WalkSubtree(visit Visit) error {
+	if node == nil {
+		return nil
+	}
+	return Walk(
+		visit,
+		node.Name,
+		node.Column,
+		node.Index,
+		node.Options,
+		node.After,
 	)
 }
 
-// Other represents a SHOW, DESCRIBE, or EXPLAIN statement.
+// Show represents a SHOW statement. Type is ShowUnsupportedStr
+// for the SHOW statements that are not parsed.
+type Show struct {
+	Type string
+}
+
+// Show.Type
+const (
+	ShowDatabasesStr     = "databases"
+	ShowTablesStr        = "tables"
+	ShowVitessShardsStr  = "vitess_shards"
+	ShowVSchemaTablesStr = "vschema tables"
+	ShowUnsupportedStr   = "unsupported"
+)
+
+// Format formats the node.
+func (node *Show) Format(buf *TrackedBuffer) {
+	buf.Myprintf("show %s", node.Type)
+}
+
+// WalkSubtree walks the nodes of the subtree
+func (node *Show) WalkSubtree(visit Visit) error {
+	return nil
+}
+
+// Use represents a USE statement. Its target is a keyspace,
+// optionally followed by :shard and @tablet_type. All the fields
+// are empty for a USE without target.
+type Use struct {
+	Keyspace   string
+	Shard      string
+	TabletType string
+}
+
+// NewUse creates a Use from its target string,
+// formatted as keyspace[:shard][@tablet_type].
+func NewUse(target string) *Use {
+	node := &Use{}
+	if i := strings.LastIndexByte(target, '@'); i != -1 {
+		node.TabletType = target[i+1:]
+		target = target[:i]
+	}
+	if i := strings.IndexByte(target, ':'); i != -1 {
+		node.Shard = target[i+1:]
+		target = target[:i]
+	}
+	node.Keyspace = target
+	return node
+}
+
+// Format formats the node.
+func (node *Use) Format(buf *TrackedBuffer) {
+	buf.WriteString("use")
+	if node.Keyspace == "" && node.Shard == "" && node.TabletType == "" {
+		return
+	}
+	buf.Myprintf(" %s", node.Keyspace)
+	if node.Shard != "" {
+		buf.Myprintf(":%s", node.Shard)
+	}
+	if node.TabletType != "" {
+		buf.Myprintf("@%s", node.TabletType)
+	}
+}
+
+// WalkSubtree walks the nodes of the subtree
+func (node *Use) WalkSubtree(visit Visit) error {
+	return nil
+}
+
+// Begin represents a BEGIN or START TRANSACTION statement.
+type Begin struct{}
+
+// Format formats the node.
+func (node *Begin) Format(buf *TrackedBuffer) {
+	buf.WriteString("begin")
+}
+
+// WalkSubtree walks the nodes of the subtree
+func (node *Begin) WalkSubtree(visit Visit) error {
+	return nil
+}
+
+// Commit represents a COMMIT statement.
+type Commit struct{}
+
+// Format formats the node.
+func (node *Commit) Format(buf *TrackedBuffer) {
+	buf.WriteString("commit")
+}
+
+// WalkSubtree walks the nodes of the subtree
+func (node *Commit) WalkSubtree(visit Visit) error {
+	return nil
+}
+
+// Rollback represents a ROLLBACK statement.
+type Rollback struct{}
+
+// Format formats the node.
+func (node *Rollback) Format(buf *TrackedBuffer) {
+	buf.WriteString("rollback")
+}
+
+// WalkSubtree walks the nodes of the subtree
+func (node *Rollback) WalkSubtree(visit Visit) error {
+	return nil
+}
+
+// Other represents a DESCRIBE or EXPLAIN statement.
 // It should be used only as an indicator. It does not contain
 // the full AST for the statement.
 type Other struct{}
@@ -4,7 +4,10 @@
 
 package sqlparser
 
-import "testing"
+import (
+	"reflect"
+	"testing"
+)
 
 func TestValid(t *testing.T) {
 	validSQL := []struct {
@@ -456,7 +459,7 @@ func TestValid(t *testing.T) {
 		output: "alter table a",
 	}, {
 		input:  "alter table a drop foo",
-		output: "alter table a",
+		output: "alter table a drop column foo",
 	}, {
 		input:  "alter table a disable foo",
 		output: "alter table a",
@@ -475,6 +478,33 @@ func TestValid(t *testing.T) {
 	}, {
 		input:  "alter table a import foo",
 		output: "alter table a",
+	}, {
+		input:  "alter table a foo bar",
+		output: "alter table a",
+	}, {
+		input: "alter table a add column b int",
+	}, {
+		input:  "alter ignore table a add b varchar(10) not null default 'x' after c",
+		output: "alter table a add column b varchar(10) not null default 'x' after c",
+	}, {
+		input: "alter table a add column b int first, add column c int unsigned after b",
+	}, {
+		input: "alter table a add index b (c, d(10)), add unique key e (f), add primary key (g)",
+	}, {
+		input:  "alter table a drop column b, drop index c, drop key d, drop primary key",
+		output: "alter table a drop column b, drop index c, drop index d, drop primary key",
+	}, {
+		input:  "alter table a modify b bigint(20) unsigned not null auto_increment",
+		output: "alter table a modify column b bigint(20) unsigned not null auto_increment",
+	}, {
+		input:  "alter table a change b c decimal(10,2) comment 'price'",
+		output: "alter table a change column b c decimal(10, 2) comment 'price'",
+	}, {
+		input:  "alter table a engine = InnoDB, auto_increment 100, comment 'b'",
+		output: "alter table a engine=InnoDB, auto_increment=100, comment='b'",
+	}, {
+		input:  "alter table a add column b int, algorithm=inplace, lock=none",
+		output: "alter table a",
 	}, {
 		input:  "alter table a rename b",
 		output: "rename table a b",
@@ -484,12 +514,41 @@ func TestValid(t *testing.T) {
 	}, {
 		input:  "alter table a rename to b",
 		output: "rename table a b",
+	}, {
+		input:  "alter table db.a add column b int",
+		output: "alter table db.a add column b int",
+	}, {
+		input:  "alter table db.a foo bar",
+		output: "alter table db.a",
+	}, {
+		input:  "alter table db.a rename b",
+		output: "rename table db.a b",
 	}, {
 		input: "create table a",
+	}, {
+		input: "create table db.a (b int)",
 	}, {
 		input: "create table `by`",
 	}, {
-		input:  "create table if not exists a",
+		input: "create table if not exists a",
+	}, {
+		input: "create table if not exists db.a (b int)",
+	}, {
+		input: "create table a (id bigint(20) unsigned not null auto_increment, name varchar(64) character set utf8 collate utf8_bin default null comment 'the name', primary key (id), unique key name (name(10)), key name_id (name, id) using btree) engine=InnoDB default charset=utf8",
+	}, {
+		input:  "CREATE TABLE `a` (`id` INT NOT NULL, `price` DECIMAL(10,2), `ts` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP, PRIMARY KEY (`id`)) ENGINE=InnoDB AUTO_INCREMENT=5 DEFAULT CHARSET=latin1 COMMENT='x'",
+		output: "create table a (id int not null, price decimal(10, 2), ts timestamp not null default current_timestamp() on update current_timestamp(), primary key (id)) engine=InnoDB auto_increment=5 default charset=latin1 comment='x'",
+	}, {
+		input: "create table a (b enum('x', 'y') not null default 'x', c set('z'), d int default -1 unique, e int primary key, f datetime(6) default current_timestamp(6))",
+	}, {
+		input: "create table a (b int, fulltext (c), spatial index d (e), unique f (g), index (h))",
+	}, {
+		input: "create table a (`first` int, `after` int, `comment` text, `auto_increment` int)",
+	}, {
+		input:  "create table a (b int, foreign key (b) references c (d))",
+		output: "create table a",
+	}, {
+		input:  "create table a like b",
 		output: "create table a",
 	}, {
 		input:  "create index a on b",
@@ -526,7 +585,48 @@ func TestValid(t *testing.T) {
 		output: "alter table a",
 	}, {
 		input:  "show foobar",
-		output: "other",
+		output: "show unsupported",
+	}, {
+		input:  "show create table a",
+		output: "show unsupported",
+	}, {
+		input:  "show tables like '%a'",
+		output: "show unsupported",
+	}, {
+		input: "show databases",
+	}, {
+		input:  "SHOW TABLES",
+		output: "show tables",
+	}, {
+		input: "show vitess_shards",
+	}, {
+		input: "show vschema tables",
+	}, {
+		input:  "show vschema foo",
+		output: "show unsupported",
+	}, {
+		input: "use",
+	}, {
+		input: "use ks",
+	}, {
+		input: "use ks@replica",
+	}, {
+		input: "use ks:-80",
+	}, {
+		input:  "use `ks:80-c0@rdonly`",
+		output: "use ks:80-c0@rdonly",
+	}, {
+		input: "begin",
+	}, {
+		input:  "start transaction",
+		output: "begin",
+	}, {
+		input: "commit",
+	}, {
+		input: "rollback",
+	}, {
+		input:  "select begin, commit, rollback, start, transaction from t",
+		output: "select `begin`, `commit`, `rollback`, `start`, `transaction` from t",
 	}, {
 		input:  "describe foobar",
 		output: "other",
@@ -557,6 +657,156 @@ func TestValid(t *testing.T) {
 	}
 }
 
+func TestParseDDL(t *testing.T) {
+	tree, err := Parse("create table a (id int unsigned not null, name varchar(10) default 'x', primary key (id), key name (name(5))) engine=InnoDB")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ddl := tree.(*DDL)
+	want := &TableSpec{
+		Columns: []*ColumnDefinition{{
+			Name: NewColIdent("id"),
+			Type: ColumnType{Type: "int", Unsigned: true, Null: NotNullStr},
+		}, {
+			Name: NewColIdent("name"),
+			Type: ColumnType{Type: "varchar", Length: NumVal("10"), Default: StrVal("x")},
+		}},
+		Indexes: []*IndexDefinition{{
+			Info:    &IndexInfo{Type: "primary key", Name: NewColIdent("PRIMARY"), Primary: true, Unique: true},
+			Columns: []*IndexColumn{{Column: NewColIdent("id")}},
+		}, {
+			Info:    &IndexInfo{Type: "key", Name: NewColIdent("name")},
+			Columns: []*IndexColumn{{Column: NewColIdent("name"), Length: NumVal("5")}},
+		}},
+		Options: TableOptions{{Name: "engine", String: "InnoDB"}},
+	}
+	if !reflect.DeepEqual(ddl.TableSpec, want) {
+		t.Errorf("TableSpec: %s, want %s", String(ddl.TableSpec), String(want))
+	}
+
+	tree, err = Parse("alter table a add column b int after c, drop index d")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ddl = tree.(*DDL)
+	wantSpecs := AlterSpecs{{
+		Action: AddColumnStr,
+		Column: &ColumnDefinition{Name: NewColIdent("b"), Type: ColumnType{Type: "int"}},
+		After:  NewColIdent("c"),
+	}, {
+		Action: DropIndexStr,
+		Name:   NewColIdent("d"),
+	}}
+	if ddl.Action != AlterStr || ddl.Table != "a" || !reflect.DeepEqual(ddl.AlterSpecs, wantSpecs) || ddl.Partial {
+		t.Errorf("Parse: %s, want %s", String(ddl), "alter table a"+String(wantSpecs))
+	}
+
+	// Unsupported operations, and syntax errors after the table
+	// name, give a partial statement with the table name.
+	partials := []struct {
+		sql                       string
+		action                    string
+		qualifier, table, newName TableIdent
+	}{{
+		sql:     "alter table a partition by hash(b)",
+		action:  AlterStr,
+		table:   "a",
+		newName: "a",
+	}, {
+		sql:       "alter table db.a add column b int, lock=none",
+		action:    AlterStr,
+		qualifier: "db",
+		table:     "a",
+		newName:   "a",
+	}, {
+		sql:     "alter table a add column",
+		action:  AlterStr,
+		table:   "a",
+		newName: "a",
+	}, {
+		sql:     "create table a like b",
+		action:  CreateStr,
+		newName: "a",
+	}, {
+		sql:     "create table a (b int, foreign key (b) references c (d))",
+		action:  CreateStr,
+		newName: "a",
+	}, {
+		sql:       "create table db.a (b int) partition by hash(b)",
+		action:    CreateStr,
+		qualifier: "db",
+		newName:   "a",
+	}, {
+		sql:     "create index i on t (a)",
+		action:  AlterStr,
+		table:   "t",
+		newName: "t",
+	}, {
+		sql:     "drop index i on t",
+		action:  AlterStr,
+		table:   "t",
+		newName: "t",
+	}}
+	for _, tcase := range partials {
+		tree, err = Parse(tcase.sql)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tcase.sql, err)
+			continue
+		}
+		ddl = tree.(*DDL)
+		if !ddl.Partial || ddl.Action != tcase.action || ddl.Qualifier != tcase.qualifier || ddl.Table != tcase.table || ddl.NewName != tcase.newName || ddl.TableSpec != nil || ddl.AlterSpecs != nil {
+			t.Errorf("Parse(%q): %#v, want a partial %v of %v.%v", tcase.sql, ddl, tcase.action, tcase.qualifier, tcase.newName)
+		}
+	}
+
+	// IF NOT EXISTS is kept, also for partial statements.
+	tree, err = Parse("create table if not exists a like b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ddl = tree.(*DDL)
+	if !ddl.IfNotExists || !ddl.Partial || ddl.NewName != "a" {
+		t.Errorf("Parse: %#v, want a partial create of a, if not exists", ddl)
+	}
+	if got, want := String(ddl), "create table if not exists a"; got != want {
+		t.Errorf("String: %s, want %s", got, want)
+	}
+
+	// Qualified names.
+	tree, err = Parse("alter table db.a add column c int")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ddl = tree.(*DDL)
+	if ddl.Qualifier != "db" || ddl.Table != "a" || ddl.NewName != "a" || len(ddl.AlterSpecs) != 1 || ddl.Partial {
+		t.Errorf("Parse: %#v, want an alter of db.a", ddl)
+	}
+}
+
+func TestNewUse(t *testing.T) {
+	testcases := []struct {
+		in  string
+		out *Use
+	}{{
+		in:  "ks",
+		out: &Use{Keyspace: "ks"},
+	}, {
+		in:  "ks:-80",
+		out: &Use{Keyspace: "ks", Shard: "-80"},
+	}, {
+		in:  "ks@master",
+		out: &Use{Keyspace: "ks", TabletType: "master"},
+	}, {
+		in:  "ks:80-@replica",
+		out: &Use{Keyspace: "ks", Shard: "80-", TabletType: "replica"},
+	}}
+	for _, tcase := range testcases {
+		if got := NewUse(tcase.in); !reflect.DeepEqual(got, tcase.out) {
+			t.Errorf("NewUse(%s): %#v, want %#v", tcase.in, got, tcase.out)
+		}
+	}
+}
+
 func TestCaseSensitivity(t *testing.T) {
 	validSQL := []struct {
 		input  string
@@ -743,6 +993,18 @@ func TestErrors(t *testing.T) {
 	}, {
 		input:  "select next id from a",
 		output: "expecting value after next at position 23",
+	}, {
+		input:  "use ks foo",
+		output: "syntax error at position 11 near 'foo'",
+	}, {
+		input:  "use `ks",
+		output: "syntax error at position 8 near 'ks'",
+	}, {
+		input:  "use ks;",
+		output: "syntax error at position 8",
+	}, {
+		input:  "use ks:-80;",
+		output: "syntax error at position 12",
 	}}
 	for _, tcase := range invalidSQL {
 		if tcase.output == "" {
@@ -4,10 +4,17 @@ package sqlparser
 import __yyfmt__ "fmt"
 
 //line sql.y:6
+
+import "strings"
+
 func setParseTree(yylex interface{}, stmt Statement) {
 	yylex.(*Tokenizer).ParseTree = stmt
 }
 
+func setPartialStatement(yylex interface{}, stmt Statement) {
+	yylex.(*Tokenizer).partialStatement = stmt
+}
+
 func setAllowComments(yylex interface{}, allow bool) {
 	yylex.(*Tokenizer).AllowComments = allow
 }
@@ -28,44 +35,61 @@ func forceEOF(yylex interface{}) {
 	yylex.(*Tokenizer).ForceEOF = true
 }
 
-//line sql.y:34
+func forceTarget(yylex interface{}) {
+	yylex.(*Tokenizer).forceTarget = true
+}
+
+//line sql.y:44
 type yySymType struct {
-	yys         int
-	empty       struct{}
-	statement   Statement
-	selStmt     SelectStatement
-	byt         byte
-	bytes       []byte
-	bytes2      [][]byte
-	str         string
-	selectExprs SelectExprs
-	selectExpr  SelectExpr
-	columns     Columns
-	colName     *ColName
-	tableExprs  TableExprs
-	tableExpr   TableExpr
-	tableName   *TableName
-	indexHints  *IndexHints
-	expr        Expr
-	boolExpr    BoolExpr
-	valExpr     ValExpr
-	colTuple    ColTuple
-	valExprs    ValExprs
-	values      Values
-	rowTuple    RowTuple
-	subquery    *Subquery
-	caseExpr    *CaseExpr
-	whens       []*When
-	when        *When
-	orderBy     OrderBy
-	order       *Order
-	limit       *Limit
-	insRows     InsertRows
-	updateExprs UpdateExprs
-	updateExpr  *UpdateExpr
-	colIdent    ColIdent
-	colIdents   []ColIdent
-	tableIdent  TableIdent
+	yys              int
+	empty            struct{}
+	statement        Statement
+	selStmt          SelectStatement
+	byt              byte
+	bytes            []byte
+	bytes2           [][]byte
+	str              string
+	selectExprs      SelectExprs
+	selectExpr       SelectExpr
+	columns          Columns
+	colName          *ColName
+	tableExprs       TableExprs
+	tableExpr        TableExpr
+	tableName        *TableName
+	indexHints       *IndexHints
+	expr             Expr
+	boolExpr         BoolExpr
+	valExpr          ValExpr
+	colTuple         ColTuple
+	valExprs         ValExprs
+	values           Values
+	rowTuple         RowTuple
+	subquery         *Subquery
+	caseExpr         *CaseExpr
+	whens            []*When
+	when             *When
+	orderBy          OrderBy
+	order            *Order
+	limit            *Limit
+	insRows          InsertRows
+	updateExprs      UpdateExprs
+	updateExpr       *UpdateExpr
+	colIdent         ColIdent
+	colIdents        []ColIdent
+	tableIdent       TableIdent
+	strs             []string
+	ddl              *DDL
+	tableSpec        *TableSpec
+	columnDefinition *ColumnDefinition
+	columnType       ColumnType
+	indexDefinition  *IndexDefinition
+	indexInfo        *IndexInfo
+	indexColumn      *IndexColumn
+	indexColumns     []*IndexColumn
+	tableOptions     TableOptions
+	tableOption      *TableOption
+	alterSpecs       AlterSpecs
+	alterSpec        *AlterSpec
 }
 
 const LEX_ERROR = 57346
@@ -155,7 +179,29 @@ const USING = 57429
 const SHOW = 57430
 const DESCRIBE = 57431
 const EXPLAIN = 57432
-const UNUSED = 57433
+const DATABASES = 57433
+const ADD = 57434
+const COLUMN = 57435
+const CHANGE = 57436
+const PRIMARY = 57437
+const FULLTEXT = 57438
+const SPATIAL = 57439
+const UNSIGNED = 57440
+const ZEROFILL = 57441
+const CHARACTER = 57442
+const COLLATE = 57443
+const CURRENT_TIMESTAMP = 57444
+const DATA_TYPE = 57445
+const AFTER = 57446
+const AUTO_INCREMENT = 57447
+const COMMENT_KEYWORD = 57448
+const FIRST = 57449
+const BEGIN = 57450
+const COMMIT = 57451
+const ROLLBACK = 57452
+const START = 57453
+const TRANSACTION = 57454
+const UNUSED = 57455
 
 var yyToknames = [...]string{
 	"$end",
@@ -264,6 +310,28 @@ var yyToknames = [...]string{
 	"SHOW",
 	"DESCRIBE",
 	"EXPLAIN",
+	"DATABASES",
+	"ADD",
+	"COLUMN",
+	"CHANGE",
+	"PRIMARY",
+	"FULLTEXT",
+	"SPATIAL",
+	"UNSIGNED",
+	"ZEROFILL",
+	"CHARACTER",
+	"COLLATE",
+	"CURRENT_TIMESTAMP",
+	"DATA_TYPE",
+	"AFTER",
+	"AUTO_INCREMENT",
+	"COMMENT_KEYWORD",
+	"FIRST",
+	"BEGIN",
+	"COMMIT",
+	"ROLLBACK",
+	"START",
+	"TRANSACTION",
 	"UNUSED",
 }
 var yyStatenames = [...]string{}
@@ -277,303 +345,449 @@ var yyExca = [...]int{
 	-1, 1,
 	1, -1,
 	-2, 0,
-	-1, 106,
-	45, 221,
-	89, 221,
-	-2, 220,
+	-1, 223,
+	45, 323,
+	89, 323,
+	-2, 321,
+	-1, 224,
+	45, 324,
+	89, 324,
+	-2, 322,
 }
 
-const yyNprod = 225
+const yyNprod = 338
 const yyPrivate = 57344
 
 var yyTokenNames []string
 var yyStates []string
 
-const yyLast = 813
+const yyLast = 1449
 
 var yyAct = [...]int{
 
-	127, 192, 283, 233, 62, 112, 211, 223, 195, 324,
-	291, 400, 334, 222, 274, 245, 221, 136, 239, 194,
-	3, 145, 74, 225, 99, 67, 35, 100, 37, 40,
-	70, 41, 38, 41, 220, 64, 94, 383, 69, 382,
-	47, 71, 381, 68, 151, 46, 42, 50, 363, 128,
-	352, 354, 14, 306, 122, 80, 58, 149, 104, 43,
-	44, 45, 84, 176, 413, 122, 48, 49, 174, 182,
-	183, 177, 178, 179, 180, 181, 176, 98, 153, 63,
-	105, 275, 166, 322, 64, 85, 106, 64, 134, 139,
-	164, 122, 87, 275, 106, 123, 124, 125, 214, 89,
-	126, 81, 122, 160, 166, 60, 133, 129, 353, 162,
-	91, 196, 93, 165, 164, 197, 198, 199, 200, 365,
-	230, 65, 148, 150, 147, 302, 113, 114, 166, 142,
-	161, 205, 115, 122, 116, 409, 236, 152, 60, 156,
-	248, 106, 252, 215, 165, 164, 217, 117, 179, 180,
-	181, 176, 210, 60, 206, 250, 251, 249, 65, 166,
-	105, 90, 229, 231, 28, 105, 138, 191, 193, 244,
-	75, 202, 253, 254, 255, 86, 257, 258, 259, 260,
-	261, 262, 263, 264, 265, 266, 228, 218, 234, 309,
-	310, 311, 256, 325, 236, 86, 213, 267, 268, 270,
-	157, 271, 269, 105, 165, 164, 236, 226, 14, 64,
-	281, 141, 122, 279, 137, 231, 137, 282, 247, 166,
-	240, 242, 243, 237, 238, 241, 272, 278, 182, 183,
-	177, 178, 179, 180, 181, 176, 158, 105, 307, 288,
-	269, 236, 158, 236, 305, 289, 236, 122, 215, 289,
-	60, 86, 314, 315, 316, 312, 330, 236, 370, 367,
-	308, 208, 73, 377, 325, 313, 293, 296, 297, 298,
-	294, 318, 295, 299, 216, 97, 319, 226, 122, 349,
-	380, 297, 298, 327, 329, 331, 332, 335, 321, 328,
-	109, 177, 178, 179, 180, 181, 176, 347, 340, 247,
-	342, 345, 348, 339, 79, 341, 346, 358, 76, 357,
-	350, 39, 359, 379, 344, 163, 360, 343, 406, 362,
-	109, 109, 55, 369, 364, 83, 14, 336, 386, 201,
-	407, 368, 366, 203, 323, 54, 82, 143, 96, 226,
-	226, 226, 226, 57, 304, 209, 51, 52, 284, 109,
-	277, 376, 163, 175, 174, 182, 183, 177, 178, 179,
-	180, 181, 176, 384, 285, 212, 375, 385, 338, 227,
-	109, 388, 335, 387, 137, 109, 109, 109, 215, 132,
-	246, 390, 61, 389, 140, 392, 131, 412, 398, 14,
-	28, 30, 1, 399, 303, 300, 401, 401, 401, 64,
-	159, 144, 36, 404, 408, 219, 410, 411, 402, 403,
-	414, 146, 66, 109, 415, 130, 416, 59, 293, 296,
-	297, 298, 294, 280, 295, 299, 29, 72, 378, 207,
-	405, 77, 371, 333, 391, 374, 393, 394, 337, 227,
-	320, 204, 31, 32, 33, 34, 59, 109, 273, 118,
-	111, 88, 326, 110, 276, 92, 167, 107, 95, 351,
-	292, 246, 290, 103, 224, 102, 78, 59, 53, 135,
-	27, 56, 13, 14, 15, 16, 17, 372, 373, 154,
-	12, 11, 155, 10, 9, 8, 7, 109, 235, 6,
-	121, 5, 4, 2, 0, 18, 0, 0, 0, 0,
-	0, 227, 227, 227, 227, 0, 0, 0, 0, 0,
-	0, 0, 0, 0, 122, 59, 236, 106, 123, 124,
-	125, 0, 0, 126, 119, 120, 0, 0, 108, 0,
-	129, 175, 174, 182, 183, 177, 178, 179, 180, 181,
-	176, 0, 59, 103, 0, 0, 0, 232, 103, 113,
-	114, 101, 0, 0, 0, 115, 361, 116, 19, 20,
-	22, 21, 23, 0, 0, 0, 0, 0, 0, 0,
-	117, 24, 25, 26, 175, 174, 182, 183, 177, 178,
-	179, 180, 181, 176, 0, 0, 103, 109, 0, 109,
-	109, 0, 0, 395, 396, 397, 0, 0, 121, 232,
-	0, 286, 0, 0, 287, 0, 0, 0, 0, 0,
-	301, 0, 59, 0, 0, 0, 0, 0, 0, 0,
-	103, 0, 122, 0, 317, 106, 123, 124, 125, 0,
-	0, 126, 119, 120, 0, 0, 108, 0, 129, 0,
-	0, 14, 175, 174, 182, 183, 177, 178, 179, 180,
-	181, 176, 0, 0, 0, 0, 121, 113, 114, 101,
-	0, 0, 0, 115, 0, 116, 0, 0, 0, 0,
-	0, 0, 0, 121, 59, 59, 59, 59, 117, 0,
-	122, 0, 0, 106, 123, 124, 125, 355, 356, 126,
-	119, 120, 0, 0, 108, 0, 129, 122, 0, 0,
-	106, 123, 124, 125, 0, 0, 126, 119, 120, 0,
-	0, 108, 0, 129, 65, 113, 114, 0, 0, 0,
-	0, 115, 0, 116, 0, 122, 0, 0, 106, 123,
-	124, 125, 113, 114, 126, 0, 117, 0, 115, 0,
-	116, 129, 175, 174, 182, 183, 177, 178, 179, 180,
-	181, 176, 0, 117, 0, 0, 0, 0, 0, 0,
-	113, 114, 0, 0, 0, 0, 115, 0, 116, 0,
-	0, 0, 0, 0, 0, 0, 169, 172, 0, 0,
-	0, 117, 184, 185, 186, 187, 188, 189, 190, 173,
-	170, 171, 168, 175, 174, 182, 183, 177, 178, 179,
-	180, 181, 176, 175, 174, 182, 183, 177, 178, 179,
-	180, 181, 176,
+	224, 446, 336, 509, 230, 583, 387, 221, 466, 352,
+	105, 499, 81, 437, 408, 385, 253, 367, 278, 168,
+	386, 216, 358, 402, 245, 335, 3, 217, 374, 286,
+	63, 112, 106, 80, 62, 57, 111, 64, 288, 359,
+	368, 287, 196, 198, 199, 135, 94, 144, 109, 179,
+	109, 82, 140, 109, 389, 131, 181, 459, 139, 244,
+	195, 284, 174, 78, 152, 53, 83, 52, 125, 211,
+	109, 54, 71, 94, 363, 364, 69, 94, 71, 366,
+	128, 566, 175, 533, 535, 73, 74, 75, 109, 131,
+	130, 50, 565, 138, 564, 122, 149, 76, 72, 544,
+	91, 121, 50, 481, 596, 94, 365, 107, 164, 113,
+	360, 317, 123, 197, 307, 146, 147, 438, 109, 497,
+	141, 142, 77, 145, 135, 94, 65, 66, 94, 148,
+	109, 67, 68, 109, 109, 355, 186, 109, 109, 121,
+	117, 534, 167, 368, 305, 151, 303, 160, 188, 438,
+	94, 361, 94, 176, 161, 109, 19, 170, 307, 165,
+	512, 209, 189, 94, 109, 94, 191, 109, 183, 184,
+	109, 513, 120, 200, 251, 375, 373, 182, 109, 187,
+	109, 403, 405, 406, 109, 109, 404, 50, 202, 113,
+	204, 205, 192, 193, 411, 50, 113, 201, 93, 255,
+	133, 262, 109, 260, 210, 206, 261, 207, 208, 461,
+	120, 450, 94, 514, 215, 94, 375, 250, 415, 185,
+	109, 154, 131, 107, 134, 121, 107, 372, 297, 113,
+	289, 413, 414, 412, 332, 334, 280, 279, 290, 182,
+	281, 282, 157, 182, 182, 484, 485, 486, 94, 592,
+	170, 292, 320, 321, 322, 317, 129, 180, 41, 180,
+	180, 113, 354, 291, 343, 293, 294, 156, 351, 153,
+	95, 96, 98, 100, 97, 99, 101, 102, 103, 301,
+	306, 305, 432, 170, 258, 109, 546, 299, 170, 109,
+	50, 138, 166, 93, 170, 307, 120, 377, 432, 94,
+	170, 116, 347, 109, 551, 376, 306, 305, 131, 49,
+	115, 118, 119, 400, 401, 548, 132, 136, 137, 126,
+	383, 307, 50, 390, 397, 560, 398, 392, 318, 319,
+	320, 321, 322, 317, 410, 464, 170, 159, 419, 505,
+	170, 500, 109, 166, 381, 456, 170, 500, 382, 254,
+	109, 109, 380, 384, 430, 431, 433, 454, 170, 298,
+	442, 445, 393, 395, 435, 95, 96, 98, 100, 97,
+	99, 101, 102, 103, 441, 378, 170, 214, 170, 109,
+	449, 94, 169, 170, 464, 254, 333, 452, 306, 305,
+	94, 349, 94, 453, 455, 299, 457, 460, 563, 177,
+	528, 434, 463, 307, 70, 529, 526, 480, 50, 107,
+	444, 527, 487, 562, 525, 395, 390, 524, 482, 357,
+	166, 483, 468, 471, 472, 473, 469, 488, 470, 474,
+	589, 530, 64, 472, 473, 88, 203, 410, 279, 163,
+	19, 569, 590, 227, 371, 502, 498, 506, 87, 90,
+	496, 143, 370, 60, 503, 494, 194, 178, 275, 276,
+	109, 547, 162, 504, 440, 94, 94, 94, 94, 285,
+	213, 511, 521, 42, 523, 517, 479, 249, 94, 94,
+	447, 516, 109, 531, 248, 520, 559, 522, 448, 390,
+	390, 390, 390, 353, 44, 45, 46, 47, 59, 56,
+	538, 84, 85, 540, 558, 304, 109, 541, 519, 254,
+	104, 227, 227, 58, 595, 61, 581, 369, 19, 515,
+	342, 65, 66, 41, 344, 43, 67, 68, 31, 1,
+	79, 478, 555, 556, 475, 350, 300, 55, 259, 227,
+	458, 539, 304, 277, 114, 222, 362, 173, 172, 171,
+	110, 48, 26, 570, 256, 572, 24, 283, 51, 477,
+	127, 124, 575, 247, 379, 549, 574, 573, 576, 577,
+	443, 348, 588, 552, 508, 582, 557, 518, 495, 109,
+	109, 109, 109, 391, 227, 585, 586, 93, 345, 227,
+	227, 227, 587, 109, 409, 436, 257, 109, 236, 109,
+	229, 501, 228, 591, 439, 593, 594, 308, 225, 532,
+	467, 465, 388, 219, 337, 158, 86, 40, 338, 339,
+	340, 341, 468, 471, 472, 473, 469, 227, 470, 474,
+	89, 17, 561, 346, 16, 15, 18, 14, 584, 584,
+	584, 107, 92, 13, 12, 356, 11, 10, 9, 64,
+	8, 7, 597, 451, 6, 5, 598, 4, 599, 95,
+	96, 98, 100, 97, 99, 101, 102, 103, 2, 150,
+	60, 121, 0, 155, 0, 0, 391, 0, 0, 0,
+	0, 0, 0, 0, 227, 0, 222, 0, 0, 0,
+	0, 222, 0, 108, 0, 407, 0, 409, 416, 417,
+	418, 92, 420, 421, 422, 423, 424, 425, 426, 427,
+	428, 429, 0, 0, 0, 59, 0, 0, 0, 0,
+	0, 92, 0, 227, 190, 0, 0, 0, 0, 222,
+	58, 0, 61, 0, 0, 0, 0, 0, 65, 66,
+	0, 0, 120, 67, 68, 0, 92, 116, 212, 391,
+	391, 391, 391, 0, 0, 220, 115, 118, 119, 92,
+	0, 252, 0, 0, 0, 95, 96, 98, 100, 97,
+	99, 101, 102, 103, 0, 0, 0, 0, 0, 0,
+	223, 0, 0, 0, 0, 302, 222, 0, 0, 0,
+	0, 0, 108, 0, 0, 0, 356, 0, 0, 0,
+	489, 490, 491, 0, 0, 0, 0, 0, 295, 0,
+	0, 296, 0, 108, 394, 0, 0, 0, 0, 493,
+	316, 315, 323, 324, 318, 319, 320, 321, 322, 317,
+	108, 0, 0, 0, 507, 510, 0, 0, 0, 93,
+	0, 0, 0, 227, 92, 227, 227, 0, 0, 578,
+	579, 580, 95, 96, 98, 100, 97, 99, 101, 102,
+	103, 0, 0, 0, 95, 96, 98, 100, 97, 99,
+	101, 102, 103, 0, 0, 0, 0, 0, 0, 543,
+	0, 0, 0, 0, 545, 95, 96, 98, 100, 97,
+	99, 101, 102, 103, 0, 92, 220, 0, 0, 0,
+	396, 220, 95, 96, 98, 100, 97, 99, 101, 102,
+	103, 95, 96, 98, 100, 97, 99, 101, 102, 103,
+	323, 324, 318, 319, 320, 321, 322, 317, 399, 567,
+	239, 0, 0, 568, 0, 0, 0, 571, 510, 220,
+	0, 0, 0, 0, 0, 0, 356, 0, 223, 0,
+	0, 0, 396, 0, 50, 0, 170, 223, 240, 241,
+	242, 0, 0, 243, 237, 238, 0, 0, 226, 0,
+	246, 0, 0, 0, 0, 0, 0, 462, 0, 239,
+	0, 0, 0, 0, 0, 0, 476, 0, 92, 231,
+	232, 218, 0, 0, 0, 233, 220, 234, 0, 0,
+	0, 0, 0, 50, 0, 0, 223, 240, 241, 242,
+	235, 0, 243, 237, 238, 0, 0, 226, 0, 246,
+	95, 96, 98, 100, 97, 99, 101, 102, 103, 95,
+	96, 98, 100, 97, 99, 101, 102, 103, 231, 232,
+	218, 0, 0, 0, 233, 0, 234, 0, 0, 0,
+	19, 0, 0, 0, 0, 0, 0, 0, 0, 235,
+	0, 92, 92, 92, 92, 239, 0, 0, 0, 0,
+	0, 0, 0, 0, 536, 537, 0, 0, 95, 96,
+	98, 100, 97, 99, 101, 102, 103, 0, 0, 50,
+	0, 0, 223, 240, 241, 242, 0, 0, 243, 237,
+	238, 0, 0, 226, 0, 246, 0, 0, 0, 0,
+	0, 0, 0, 0, 239, 315, 323, 324, 318, 319,
+	320, 321, 322, 317, 231, 232, 0, 0, 553, 554,
+	233, 0, 234, 0, 0, 0, 0, 0, 50, 0,
+	0, 223, 240, 241, 242, 235, 0, 243, 237, 238,
+	0, 0, 226, 19, 246, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 95, 96, 98, 100, 97, 99,
+	101, 102, 103, 231, 232, 0, 0, 0, 0, 233,
+	0, 234, 316, 315, 323, 324, 318, 319, 320, 321,
+	322, 317, 50, 0, 235, 223, 240, 241, 242, 0,
+	0, 243, 0, 0, 0, 0, 0, 0, 246, 0,
+	0, 0, 0, 95, 96, 98, 100, 97, 99, 101,
+	102, 103, 0, 0, 0, 0, 0, 231, 232, 0,
+	0, 0, 0, 233, 0, 234, 0, 0, 0, 0,
+	0, 50, 0, 0, 223, 240, 241, 242, 235, 0,
+	243, 0, 0, 0, 0, 0, 0, 246, 19, 20,
+	21, 22, 0, 0, 0, 0, 0, 95, 96, 98,
+	100, 97, 99, 101, 102, 103, 231, 232, 269, 0,
+	23, 0, 233, 0, 234, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 32, 270, 550, 235, 0, 0,
+	0, 0, 0, 0, 0, 267, 0, 0, 0, 0,
+	268, 0, 0, 0, 0, 0, 95, 96, 98, 100,
+	97, 99, 101, 102, 103, 0, 316, 315, 323, 324,
+	318, 319, 320, 321, 322, 317, 0, 0, 0, 0,
+	0, 0, 0, 25, 27, 29, 28, 30, 0, 0,
+	0, 0, 0, 273, 0, 0, 39, 37, 38, 0,
+	0, 0, 272, 0, 0, 263, 264, 265, 266, 0,
+	0, 0, 271, 274, 310, 313, 33, 35, 36, 34,
+	325, 326, 327, 328, 329, 330, 331, 314, 311, 312,
+	309, 316, 315, 323, 324, 318, 319, 320, 321, 322,
+	317, 542, 0, 0, 0, 0, 0, 0, 0, 0,
+	0, 492, 0, 0, 0, 0, 0, 0, 0, 316,
+	315, 323, 324, 318, 319, 320, 321, 322, 317, 316,
+	315, 323, 324, 318, 319, 320, 321, 322, 317, 316,
+	315, 323, 324, 318, 319, 320, 321, 322, 317,
 }
 var yyPact = [...]int{
 
-	467, -1000, -1000, 385, -1000, -1000, -1000, -1000, -1000, -1000,
-	-1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000, -70,
-	-69, -50, -37, -51, -1000, -1000, -1000, 383, 328, 303,
-	-1000, -67, 90, 372, 73, -76, -54, 73, -1000, -66,
-	73, -1000, 90, -79, 122, -79, 90, -1000, -1000, -1000,
-	-1000, -1000, -1000, 269, 73, -1000, 48, 312, 297, -27,
-	-1000, 90, 129, -1000, 27, -1000, 90, 40, 113, -1000,
-	90, -1000, -63, 90, 317, 231, 73, -1000, 577, -1000,
-	369, -1000, 90, 73, 90, 363, 73, 680, -1000, 316,
-	-82, -1000, 30, -1000, 90, -1000, -1000, 90, -1000, 190,
-	-1000, -1000, 110, 20, 87, 717, -1000, -1000, 652, 635,
-	-1000, -1000, -1000, 680, 680, 680, 680, 167, -1000, -1000,
-	-1000, 167, -1000, -1000, -1000, -1000, -1000, -1000, -1000, 680,
-	90, -1000, -1000, 233, 205, -1000, 351, 652, -1000, 727,
-	9, 46, -1000, -1000, 230, 73, -1000, -65, -1000, -1000,
-	-1000, -1000, -1000, -1000, -1000, -1000, -1000, 57, 577, -1000,
-	-1000, 73, 38, 469, 652, 652, 166, 680, 88, 82,
-	680, 680, 680, 166, 680, 680, 680, 680, 680, 680,
-	680, 680, 680, 680, -1000, -1000, -1000, -1000, -1000, -1000,
-	-1000, 10, 717, 147, 159, 194, 717, -1000, -1000, -1000,
-	666, 577, -1000, 383, 31, 727, -1000, 320, 73, 73,
-	351, 332, 349, 87, 93, 727, 90, -1000, -1000, 90,
-	-1000, 203, 232, -1000, -1000, 105, 324, 202, -1000, -1000,
-	-1000, -1000, -36, -1000, 196, 577, -1000, 10, 32, -1000,
-	-1000, 135, -1000, -1000, 727, -1000, 46, -1000, -1000, 88,
-	680, 680, 680, 727, 727, 566, -1000, 150, -9, -1000,
-	66, 66, -22, -22, -22, 211, 211, -1000, -1000, 680,
-	-1000, -1000, 196, 19, -1000, 652, 220, 167, 385, 149,
-	210, -1000, 332, -1000, 680, 680, -1000, -1000, 356, 57,
-	57, 57, 57, -1000, 283, 280, -1000, 267, 263, 245,
-	8, -1000, 90, 90, -1000, 199, 73, -1000, 196, -1000,
-	-1000, -1000, 194, -1000, 727, 727, 498, 680, 727, -1000,
-	-42, -1000, 680, 56, -1000, 307, 213, -1000, -1000, -1000,
-	73, -1000, 277, 212, -1000, 455, -1000, 353, 336, 232,
-	219, 384, -1000, -1000, -1000, -1000, 279, -1000, 246, -1000,
-	-1000, -1000, -55, -58, -60, -1000, -1000, -1000, -1000, -1000,
-	-1000, 680, 727, -1000, 727, 680, 302, 167, -1000, 680,
-	680, -1000, -1000, -1000, 351, 652, 680, 652, 652, -1000,
-	-1000, 167, 167, 167, 727, 727, 380, -1000, 727, -1000,
-	332, 87, 156, 87, 87, 73, 73, 73, 73, 301,
-	89, -1000, 89, 89, 129, -1000, 379, -11, -1000, 73,
-	-1000, -1000, -1000, 73, -1000, 73, -1000,
+	1252, -1000, -1000, 518, -1000, -1000, -1000, -1000, -1000, -1000,
+	-1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000,
+	-1000, -1000, -1000, -1000, 277, -31, 405, -22, 2, -11,
+	1, 15, -1000, -1000, -95, -1000, -1000, -1000, -1000, -1000,
+	512, 483, 416, -1000, -28, 791, 500, 782, -1000, 645,
+	-1000, -2, 782, -33, -1000, 273, -19, -1000, 199, 113,
+	-20, -54, 10, -1000, 4, 423, 58, 58, 58, 782,
+	0, -1000, 791, -37, 221, -37, 791, -1000, 219, 194,
+	-1000, -1000, -1000, -1000, -1000, -1000, 302, 782, -1000, 101,
+	438, 411, 19, -1000, -1000, -1000, -1000, -1000, -1000, -1000,
+	-1000, -1000, -1000, -1000, 791, 246, -1000, 77, -1000, -1000,
+	336, -1000, -1000, 34, 277, 431, 75, 782, 75, 75,
+	-1000, -1000, 171, -1000, 791, 89, 622, 791, -1000, 782,
+	-1000, -1000, 782, 782, 430, -6, 782, 782, -1000, 59,
+	58, 408, 58, 58, -6, -1000, -6, -6, -1000, 791,
+	-30, 791, 449, 333, 782, -1000, -1000, -1000, 958, -1000,
+	467, -1000, 791, 782, 791, 498, 782, 1196, 10, 645,
+	-1000, 1251, -1000, 277, 277, -1000, -1000, 782, -1000, 782,
+	-1000, -1000, -1000, 782, 782, -42, -1000, -1000, 448, -1000,
+	-1000, -82, -1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000,
+	-82, 782, -6, 58, -6, -6, -1000, -1000, -1000, -1000,
+	-1000, 791, -1000, -1000, 791, -1000, 349, -1000, -1000, 765,
+	57, 249, 1315, -1000, -1000, -1000, 1093, 1044, -1000, -1000,
+	-1000, 1196, 1196, 1196, 1196, 277, -1000, -1000, -1000, 277,
+	-1000, -1000, -1000, -1000, -1000, -1000, 1196, 791, -1000, -1000,
+	363, 374, -1000, 479, 1093, -1000, 1363, 46, 1147, -1000,
+	10, -1000, -1000, -1000, -1000, 391, -9, -1000, 97, 25,
+	509, -1000, 426, 418, 178, 126, 167, 329, -1000, 277,
+	-1000, -1000, -1000, 308, 782, -1000, -1000, -1000, 782, -1000,
+	-82, -1000, -6, -1000, -1000, -1000, -1000, -1000, 245, 958,
+	-1000, -1000, 782, 732, 909, 1093, 1093, 127, 1196, 142,
+	158, 1196, 1196, 1196, 127, 1196, 1196, 1196, 1196, 1196,
+	1196, 1196, 1196, 1196, 1196, -1000, -1000, -1000, -1000, -1000,
+	-1000, -1000, 42, 1315, 331, 253, 236, 1315, -1000, -1000,
+	-1000, 744, 958, -1000, 512, 87, 1363, -1000, 434, 782,
+	782, 479, 464, 473, 249, 900, 1363, -9, -1000, -1000,
+	-1000, -1000, -1000, -1000, -1000, 161, -1000, -1000, 277, -78,
+	-1000, -1000, -1000, 311, 299, -1000, 299, -46, 782, 159,
+	791, -1000, -1000, -1000, -1000, 338, 388, -1000, -1000, 539,
+	456, 150, -1000, -1000, -1000, -1000, 14, -1000, 241, 958,
+	42, 86, -1000, -1000, 191, -1000, -1000, 1363, -1000, 1147,
+	-1000, -1000, 142, 1196, 1196, 1196, 1363, 1363, 1353, -1000,
+	842, 1038, -1000, 170, 170, 26, 26, 26, 248, 248,
+	-1000, -1000, 1196, -1000, -1000, 241, 55, -1000, 1093, 303,
+	277, 518, 297, 293, -1000, 464, -1000, 1196, 1196, -1000,
+	-1000, 110, -1000, -1000, 121, -1000, 164, -1000, -1000, 782,
+	-1000, 247, -1000, 496, 245, 245, 245, 245, -1000, 383,
+	380, -1000, 372, 366, 397, 41, -1000, 791, 791, -1000,
+	289, 782, -1000, 241, -1000, -1000, -1000, 236, -1000, 1363,
+	1363, 1343, 1196, 1363, -1000, 9, -1000, 1196, 223, -1000,
+	436, 269, -1000, -1000, -1000, 782, -1000, 1250, 258, -1000,
+	1106, -1000, 247, 247, -1000, -1000, -1000, -1000, 491, 471,
+	388, 281, 588, -1000, -1000, -1000, -1000, 379, -1000, 364,
+	-1000, -1000, -1000, -3, -5, -16, -1000, -1000, -1000, -1000,
+	-1000, -1000, 1196, 1363, -1000, 1363, 1196, 415, 277, -1000,
+	1196, 1196, -1000, -1000, -1000, -1000, -1000, 479, 1093, 1196,
+	1093, 1093, -1000, -1000, 277, 277, 277, 1363, 1363, 508,
+	-1000, 1363, -1000, 464, 249, 252, 249, 249, 782, 782,
+	782, 782, 413, 203, -1000, 203, 203, 246, -1000, 506,
+	29, -1000, 782, -1000, -1000, -1000, 782, -1000, 782, -1000,
 }
 var yyPgo = [...]int{
 
-	0, 493, 19, 492, 491, 489, 486, 485, 484, 483,
-	481, 480, 472, 426, 471, 470, 468, 466, 24, 27,
-	465, 16, 13, 7, 464, 462, 10, 460, 23, 459,
-	11, 17, 58, 457, 456, 454, 453, 1, 18, 15,
-	8, 452, 5, 49, 450, 449, 448, 14, 441, 440,
-	438, 435, 6, 433, 12, 432, 2, 430, 429, 423,
-	9, 4, 79, 415, 311, 262, 412, 411, 405, 402,
-	401, 0, 400, 384, 395, 394, 40, 392, 391, 211,
-	3,
+	0, 668, 25, 657, 655, 654, 651, 650, 648, 647,
+	646, 644, 643, 637, 636, 635, 634, 631, 473, 630,
+	617, 616, 615, 21, 27, 613, 15, 20, 6, 612,
+	611, 8, 610, 54, 609, 5, 16, 7, 608, 607,
+	604, 602, 386, 23, 14, 2, 601, 4, 24, 600,
+	598, 595, 13, 588, 578, 577, 576, 9, 574, 3,
+	573, 1, 572, 571, 570, 11, 10, 32, 563, 404,
+	145, 561, 560, 558, 557, 256, 47, 556, 552, 551,
+	550, 36, 549, 548, 547, 22, 140, 49, 28, 546,
+	17, 31, 544, 543, 18, 56, 540, 538, 34, 30,
+	60, 537, 35, 29, 0, 59, 536, 596, 534, 531,
+	12, 530, 529, 284, 19, 528, 525,
 }
 var yyR1 = [...]int{
 
-	0, 77, 1, 1, 1, 1, 1, 1, 1, 1,
-	1, 1, 1, 2, 2, 2, 3, 3, 4, 5,
-	6, 7, 7, 7, 8, 8, 8, 9, 10, 10,
-	10, 11, 12, 12, 12, 78, 13, 14, 14, 15,
-	15, 15, 16, 16, 17, 17, 18, 18, 19, 19,
-	19, 20, 20, 72, 72, 72, 21, 21, 22, 22,
-	23, 23, 23, 24, 24, 24, 24, 75, 75, 74,
-	74, 74, 25, 25, 25, 25, 26, 26, 26, 26,
-	27, 27, 28, 28, 29, 29, 29, 29, 30, 30,
-	31, 31, 32, 32, 32, 32, 32, 32, 33, 33,
-	33, 33, 33, 33, 33, 33, 33, 33, 33, 33,
-	33, 38, 38, 38, 38, 38, 38, 34, 34, 34,
-	34, 34, 34, 34, 39, 39, 39, 43, 40, 40,
-	37, 37, 37, 37, 37, 37, 37, 37, 37, 37,
-	37, 37, 37, 37, 37, 37, 37, 37, 37, 37,
-	37, 37, 45, 48, 48, 46, 46, 47, 49, 49,
-	44, 44, 44, 36, 36, 36, 36, 50, 50, 51,
-	51, 52, 52, 53, 53, 54, 55, 55, 55, 56,
-	56, 56, 57, 57, 57, 58, 58, 59, 59, 60,
-	60, 35, 35, 41, 41, 42, 42, 61, 61, 62,
-	63, 63, 65, 65, 66, 66, 64, 64, 67, 67,
-	67, 67, 67, 67, 68, 68, 69, 69, 70, 70,
-	71, 73, 79, 80, 76,
+	0, 112, 1, 1, 1, 1, 1, 1, 1, 1,
+	1, 1, 1, 1, 1, 1, 1, 1, 2, 2,
+	2, 3, 3, 4, 5, 6, 7, 7, 7, 7,
+	8, 8, 8, 77, 78, 79, 80, 80, 80, 80,
+	81, 82, 82, 82, 82, 82, 82, 82, 82, 82,
+	82, 82, 82, 82, 82, 83, 83, 83, 83, 83,
+	84, 84, 88, 88, 85, 85, 89, 89, 89, 89,
+	89, 90, 90, 90, 91, 92, 92, 92, 92, 92,
+	86, 86, 87, 87, 95, 95, 93, 93, 94, 94,
+	96, 96, 97, 97, 98, 98, 99, 99, 99, 99,
+	99, 99, 99, 99, 100, 100, 100, 100, 101, 101,
+	102, 102, 102, 102, 102, 102, 102, 102, 103, 103,
+	103, 9, 10, 10, 10, 11, 12, 12, 12, 115,
+	13, 13, 15, 15, 16, 17, 14, 14, 116, 18,
+	19, 19, 20, 20, 20, 21, 21, 22, 22, 23,
+	23, 24, 24, 24, 25, 25, 106, 106, 106, 26,
+	26, 27, 27, 28, 28, 28, 29, 29, 29, 29,
+	109, 109, 108, 108, 108, 30, 30, 30, 30, 31,
+	31, 31, 31, 32, 32, 33, 33, 34, 34, 34,
+	34, 35, 35, 36, 36, 37, 37, 37, 37, 37,
+	37, 38, 38, 38, 38, 38, 38, 38, 38, 38,
+	38, 38, 38, 38, 43, 43, 43, 43, 43, 43,
+	39, 39, 39, 39, 39, 39, 39, 44, 44, 44,
+	48, 45, 45, 42, 42, 42, 42, 42, 42, 42,
+	42, 42, 42, 42, 42, 42, 42, 42, 42, 42,
+	42, 42, 42, 42, 42, 50, 53, 53, 51, 51,
+	52, 54, 54, 49, 49, 49, 41, 41, 41, 41,
+	55, 55, 56, 56, 57, 57, 58, 58, 59, 60,
+	60, 60, 61, 61, 61, 62, 62, 62, 63, 63,
+	64, 64, 65, 65, 40, 40, 46, 46, 47, 47,
+	66, 66, 67, 68, 68, 70, 70, 71, 71, 69,
+	69, 72, 72, 73, 73, 74, 74, 75, 75, 76,
+	76, 105, 105, 107, 107, 104, 104, 104, 104, 104,
+	104, 104, 104, 104, 113, 114, 110, 111,
 }
 var yyR2 = [...]int{
 
 	0, 1, 1, 1, 1, 1, 1, 1, 1, 1,
-	1, 1, 1, 13, 6, 3, 8, 8, 8, 7,
-	3, 5, 8, 4, 6, 7, 4, 5, 4, 5,
-	5, 3, 2, 2, 2, 0, 2, 0, 2, 1,
-	2, 2, 0, 1, 0, 1, 1, 3, 1, 2,
-	3, 1, 1, 0, 1, 2, 1, 3, 1, 1,
-	3, 3, 3, 3, 5, 5, 3, 0, 1, 0,
-	1, 2, 1, 2, 2, 1, 2, 3, 2, 3,
-	2, 2, 1, 3, 0, 5, 5, 5, 1, 3,
-	0, 2, 1, 3, 3, 2, 3, 3, 1, 1,
-	3, 3, 4, 3, 4, 3, 4, 5, 6, 3,
-	2, 1, 2, 1, 2, 1, 2, 1, 1, 1,
-	1, 1, 1, 1, 3, 1, 1, 3, 1, 3,
-	1, 1, 1, 3, 3, 3, 3, 3, 3, 3,
-	3, 3, 3, 2, 2, 2, 3, 3, 4, 5,
-	4, 1, 5, 0, 1, 1, 2, 4, 0, 2,
-	1, 3, 5, 1, 1, 1, 1, 0, 3, 0,
-	2, 0, 3, 1, 3, 2, 0, 1, 1, 0,
-	2, 4, 0, 2, 4, 0, 3, 1, 3, 0,
-	5, 2, 1, 1, 3, 3, 1, 1, 3, 3,
-	1, 1, 0, 2, 0, 3, 0, 1, 1, 1,
-	1, 1, 1, 1, 0, 1, 0, 1, 0, 2,
-	1, 1, 1, 1, 0,
+	1, 1, 1, 1, 1, 1, 1, 1, 13, 6,
+	3, 8, 8, 8, 7, 3, 1, 2, 8, 4,
+	2, 4, 4, 4, 4, 4, 1, 1, 3, 3,
+	2, 1, 2, 2, 4, 3, 2, 3, 3, 4,
+	2, 3, 2, 3, 3, 1, 4, 6, 4, 4,
+	1, 1, 1, 3, 1, 1, 1, 1, 2, 1,
+	1, 1, 3, 4, 5, 2, 3, 2, 3, 3,
+	1, 1, 0, 1, 0, 1, 1, 3, 1, 4,
+	0, 2, 0, 1, 1, 2, 3, 4, 5, 4,
+	4, 3, 3, 3, 1, 1, 1, 1, 1, 3,
+	4, 2, 3, 3, 3, 4, 5, 1, 0, 1,
+	2, 5, 4, 5, 5, 3, 2, 2, 3, 1,
+	2, 3, 1, 2, 1, 1, 2, 2, 0, 2,
+	0, 2, 1, 2, 2, 0, 1, 0, 1, 1,
+	3, 1, 2, 3, 1, 1, 0, 1, 2, 1,
+	3, 1, 1, 3, 3, 3, 3, 5, 5, 3,
+	0, 1, 0, 1, 2, 1, 2, 2, 1, 2,
+	3, 2, 3, 2, 2, 1, 3, 0, 5, 5,
+	5, 1, 3, 0, 2, 1, 3, 3, 2, 3,
+	3, 1, 1, 3, 3, 4, 3, 4, 3, 4,
+	5, 6, 3, 2, 1, 2, 1, 2, 1, 2,
+	1, 1, 1, 1, 1, 1, 1, 3, 1, 1,
+	3, 1, 3, 1, 1, 1, 3, 3, 3, 3,
+	3, 3, 3, 3, 3, 3, 2, 2, 2, 3,
+	3, 4, 5, 4, 1, 5, 0, 1, 1, 2,
+	4, 0, 2, 1, 3, 5, 1, 1, 1, 1,
+	0, 3, 0, 2, 0, 3, 1, 3, 2, 0,
+	1, 1, 0, 2, 4, 0, 2, 4, 0, 3,
+	1, 3, 0, 5, 2, 1, 1, 3, 3, 1,
+	1, 3, 3, 1, 1, 0, 2, 0, 3, 0,
+	1, 0, 1, 0, 1, 0, 2, 0, 1, 0,
+	1, 1, 1, 1, 1, 1, 1, 1, 1, 1,
+	1, 1, 1, 1, 1, 1, 0, 0,
 }
 var yyChk = [...]int{
 
-	-1000, -77, -1, -2, -3, -4, -5, -6, -7, -8,
-	-9, -10, -11, -12, 6, 7, 8, 9, 28, 91,
-	92, 94, 93, 95, 104, 105, 106, -15, 5, -13,
-	-78, -13, -13, -13, -13, 96, -69, 98, 102, -64,
-	98, 100, 96, 96, 97, 98, 96, -76, -76, -76,
-	-2, 18, 19, -16, 32, 19, -14, -64, -28, -73,
-	48, 10, -61, -62, -71, 48, -66, 101, 97, -71,
-	96, -71, -73, -65, 101, 48, -65, -73, -17, 35,
-	-71, 53, 24, 28, 89, -28, 46, 65, -73, 59,
-	48, -76, -73, -76, 99, -73, 21, 44, -71, -18,
-	-19, 82, -20, -73, -32, -37, 48, -33, 59, -79,
-	-36, -44, -42, 80, 81, 86, 88, 101, -45, 55,
-	56, 21, 45, 49, 50, 51, 54, -71, -43, 61,
-	-63, 17, 10, -28, -61, -73, -31, 11, -62, -37,
-	-73, -79, -76, 21, -70, 103, -67, 94, 92, 27,
-	93, 14, 107, 48, -73, -73, -76, 10, 46, -72,
-	-71, 20, 89, -79, 58, 57, 72, -34, 75, 59,
-	73, 74, 60, 72, 77, 76, 85, 80, 81, 82,
-	83, 84, 78, 79, 65, 66, 67, 68, 69, 70,
-	71, -32, -37, -32, -2, -40, -37, -37, -37, -37,
-	-37, -79, -43, -79, -48, -37, -28, -58, 28, -79,
-	-31, -52, 14, -32, 89, -37, 44, -71, -76, -68,
-	99, -21, -22, -23, -24, -28, -43, -79, -19, -71,
-	82, -71, -73, -80, -18, 19, 47, -32, -32, -38,
-	54, 59, 55, 56, -37, -39, -79, -43, 52, 75,
-	73, 74, 60, -37, -37, -37, -38, -37, -37, -37,
-	-37, -37, -37, -37, -37, -37, -37, -80, -80, 46,
-	-80, -71, -18, -46, -47, 62, -35, 30, -2, -61,
-	-59, -71, -52, -56, 16, 15, -73, -73, -31, 46,
-	-25, -26, -27, 34, 38, 40, 35, 36, 37, 41,
-	-74, -73, 20, -75, 20, -21, 89, -80, -18, 54,
-	55, 56, -40, -39, -37, -37, -37, 58, -37, -80,
-	-49, -47, 64, -32, -60, 44, -41, -42, -60, -80,
-	46, -56, -37, -53, -54, -37, -76, -50, 12, -22,
-	-23, -22, -23, 34, 34, 34, 39, 34, 39, 34,
-	-26, -29, 42, 100, 43, -73, -73, -80, -71, -80,
-	-80, 58, -37, 90, -37, 63, 25, 46, -71, 46,
-	46, -55, 22, 23, -51, 13, 15, 44, 44, 34,
-	34, 97, 97, 97, -37, -37, 26, -42, -37, -54,
-	-52, -32, -40, -32, -32, -79, -79, -79, 8, -56,
-	-30, -71, -30, -30, -61, -57, 17, 29, -80, 46,
-	-80, -80, 8, 75, -71, -71, -71,
+	-1000, -112, -1, -2, -3, -4, -5, -6, -7, -8,
+	-9, -10, -11, -12, -13, -15, -16, -17, -14, 6,
+	7, 8, 9, 28, -77, 91, -78, 92, 94, 93,
+	95, -115, 42, 124, 127, 125, 126, 105, 106, 104,
+	-20, 5, -18, -116, -18, -18, -18, -18, -79, -113,
+	45, -73, 98, 96, 102, -101, 94, -102, 108, 93,
+	48, 110, -98, -99, 27, 116, 117, 121, 122, 98,
+	-69, 100, 96, 96, 97, 98, 96, 107, 48, -111,
+	128, -110, -110, -2, 18, 19, -21, 32, 19, -19,
+	-69, -33, -107, 48, -104, 120, 121, 124, 122, 125,
+	123, 126, 127, 128, 10, -66, -67, -105, 48, -104,
+	-80, -81, -91, -105, -92, 111, 102, -86, 112, 113,
+	97, 26, 97, -105, -71, 101, 46, -72, 99, -75,
+	-91, 109, -75, -86, 111, 65, -75, -75, -99, 48,
+	48, 116, 117, 28, -76, 65, -76, -76, -105, 96,
+	-107, -70, 101, 48, -70, -107, 48, 48, -22, 35,
+	-105, 53, 24, 28, 89, -33, 46, 65, -114, 46,
+	47, -82, -83, -84, 28, 48, 119, -113, 26, -87,
+	-86, -95, -105, -87, -87, 48, -110, -33, 59, -102,
+	-107, -81, -105, -105, 26, -100, 48, 119, 49, 50,
+	-81, -105, -76, 28, -76, -76, -100, -100, -100, -110,
+	-33, 99, -107, 21, 44, -105, -23, -24, 82, -25,
+	-107, -37, -42, 48, -104, -38, 59, -113, -41, -49,
+	-47, 80, 81, 86, 88, 101, -50, 55, 56, 21,
+	49, 50, 51, 54, -105, -48, 61, -68, 17, 10,
+	-33, -66, -107, -36, 11, -67, -42, -107, -113, -97,
+	-98, -81, -91, 114, 115, 116, 117, 54, 59, 27,
+	44, 121, 111, 102, 122, -113, -113, -93, -94, -105,
+	-95, -95, -95, -74, 103, 21, -103, 123, 120, -103,
+	-81, -100, -76, -100, -100, -107, -107, -110, 10, 46,
+	-106, -105, 20, 89, -113, 58, 57, 72, -39, 75,
+	59, 73, 74, 60, 72, 77, 76, 85, 80, 81,
+	82, 83, 84, 78, 79, 65, 66, 67, 68, 69,
+	70, 71, -37, -42, -37, -2, -45, -42, -42, -42,
+	-42, -42, -113, -48, -113, -53, -42, -33, -63, 28,
+	-113, -36, -57, 14, -37, 89, -42, 28, -85, 48,
+	119, 54, -89, 49, 50, 81, 54, -90, 118, 8,
+	26, 26, 49, 50, -88, 49, -88, -114, 46, -113,
+	44, -105, -105, -103, -100, -26, -27, -28, -29, -33,
+	-48, -113, -24, -105, 82, -105, -107, -114, -23, 19,
+	-37, -37, -43, 54, 59, 55, 56, -42, -44, -113,
+	-48, 52, 75, 73, 74, 60, -42, -42, -42, -43,
+	-42, -42, -42, -42, -42, -42, -42, -42, -42, -42,
+	-114, -114, 46, -114, -105, -23, -51, -52, 62, -40,
+	30, -2, -66, -64, -105, -57, -61, 16, 15, -85,
+	50, -113, -90, -114, 46, -114, 46, -114, -96, 103,
+	-94, 50, -107, -36, 46, -30, -31, -32, 34, 38,
+	40, 35, 36, 37, 41, -108, -107, 20, -109, 20,
+	-26, 89, -114, -23, 54, 55, 56, -45, -44, -42,
+	-42, -42, 58, -42, -114, -54, -52, 64, -37, -65,
+	44, -46, -47, -65, -114, 46, -61, -42, -58, -59,
+	-42, -114, 50, 50, 49, -105, -114, -110, -55, 12,
+	-27, -28, -27, -28, 34, 34, 34, 39, 34, 39,
+	34, -31, -34, 42, 100, 43, -107, -107, -114, -105,
+	-114, -114, 58, -42, 90, -42, 63, 25, 46, -105,
+	46, 46, -60, 22, 23, -114, -114, -56, 13, 15,
+	44, 44, 34, 34, 97, 97, 97, -42, -42, 26,
+	-47, -42, -59, -57, -37, -45, -37, -37, -113, -113,
+	-113, 8, -61, -35, -105, -35, -35, -66, -62, 17,
+	29, -114, 46, -114, -114, 8, 75, -105, -105, -105,
 }
 var yyDef = [...]int{
 
 	0, -2, 1, 2, 3, 4, 5, 6, 7, 8,
-	9, 10, 11, 12, 35, 35, 35, 35, 35, 216,
-	206, 0, 0, 0, 224, 224, 224, 0, 39, 42,
-	37, 206, 0, 0, 0, 204, 0, 0, 217, 0,
-	0, 207, 0, 202, 0, 202, 0, 32, 33, 34,
-	15, 40, 41, 44, 0, 43, 36, 0, 0, 82,
-	221, 0, 20, 197, 0, 220, 0, 0, 0, 224,
-	0, 224, 0, 0, 0, 0, 0, 31, 0, 45,
-	0, 38, 0, 0, 0, 90, 0, 0, 224, 0,
-	218, 23, 0, 26, 0, 28, 203, 0, 224, 0,
-	46, 48, 53, 0, 51, 52, -2, 92, 0, 0,
-	130, 131, 132, 0, 0, 0, 0, 0, 151, 98,
-	99, 0, 222, 163, 164, 165, 166, 160, 196, 153,
-	0, 200, 201, 185, 90, 83, 171, 0, 198, 199,
-	0, 0, 21, 205, 0, 0, 224, 214, 208, 209,
-	210, 211, 212, 213, 27, 29, 30, 0, 0, 49,
-	54, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+	9, 10, 11, 12, 13, 14, 15, 16, 17, 138,
+	138, 138, 138, 138, 26, 313, 0, 309, 0, 0,
+	0, 0, 337, 132, 0, 134, 135, 336, 336, 129,
+	0, 142, 145, 140, 309, 0, 0, 0, 27, 0,
+	334, 0, 0, 307, 314, 30, 311, 108, 317, 317,
+	317, 317, 117, 94, 0, 0, 319, 319, 319, 0,
+	0, 310, 0, 305, 0, 305, 0, 126, 127, 130,
+	133, 136, 137, 20, 143, 144, 147, 0, 146, 139,
+	0, 0, 185, 323, 324, 325, 326, 327, 328, 329,
+	330, 331, 332, 333, 0, 25, 300, 0, 321, 322,
+	0, 36, 37, 0, 0, 0, 82, 84, 82, 82,
+	80, 81, 0, 336, 0, 0, 0, 0, 312, 0,
+	111, 318, 0, 0, 0, 0, 0, 0, 95, 0,
+	319, 0, 319, 319, 0, 320, 0, 0, 336, 0,
+	0, 0, 0, 0, 0, 125, 128, 131, 0, 148,
+	0, 141, 0, 0, 0, 193, 0, 0, 92, 0,
+	335, 40, 41, 55, 0, 60, 61, 0, 75, 84,
+	83, 77, 85, 84, 84, 315, 29, 33, 0, 109,
+	31, 118, 112, 113, 114, 96, 104, 105, 106, 107,
+	118, 0, 0, 319, 0, 0, 101, 102, 103, 32,
+	34, 0, 122, 306, 0, 336, 0, 149, 151, 156,
+	0, 154, 155, -2, -2, 195, 0, 0, 233, 234,
+	235, 0, 0, 0, 0, 0, 254, 201, 202, 0,
+	266, 267, 268, 269, 263, 299, 256, 0, 303, 304,
+	288, 193, 186, 274, 0, 301, 302, 0, 0, 35,
+	93, 38, 39, 42, 43, 0, 0, 46, 0, 0,
+	0, 50, 0, 52, 0, 0, 0, 0, 86, 88,
+	76, 78, 79, 0, 0, 308, 110, 119, 0, 115,
+	118, 97, 0, 99, 100, 121, 123, 124, 0, 0,
+	152, 157, 0, 0, 0, 0, 0, 0, 0, 0,
 	0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
-	0, 0, 0, 0, 117, 118, 119, 120, 121, 122,
-	123, 95, 0, 0, 0, 0, 128, 143, 144, 145,
-	0, 0, 110, 0, 0, 154, 14, 0, 0, 0,
-	171, 179, 0, 91, 0, 128, 0, 219, 24, 0,
-	215, 90, 56, 58, 59, 69, 67, 0, 47, 55,
-	50, 161, 0, 147, 0, 0, 223, 93, 94, 97,
-	111, 0, 113, 115, 100, 101, 0, 125, 126, 0,
-	0, 0, 0, 103, 105, 0, 109, 133, 134, 135,
-	136, 137, 138, 139, 140, 141, 142, 96, 127, 0,
-	195, 146, 0, 158, 155, 0, 189, 0, 192, 189,
-	0, 187, 179, 19, 0, 0, 224, 25, 167, 0,
-	0, 0, 0, 72, 0, 0, 75, 0, 0, 0,
-	84, 70, 0, 0, 68, 0, 0, 148, 0, 112,
-	114, 116, 0, 102, 104, 106, 0, 0, 129, 150,
-	0, 156, 0, 0, 16, 0, 191, 193, 17, 186,
-	0, 18, 180, 172, 173, 176, 22, 169, 0, 57,
-	63, 0, 66, 73, 74, 76, 0, 78, 0, 80,
-	81, 60, 0, 0, 0, 71, 61, 62, 162, 149,
-	124, 0, 107, 152, 159, 0, 0, 0, 188, 0,
-	0, 175, 177, 178, 171, 0, 0, 0, 0, 77,
-	79, 0, 0, 0, 108, 157, 0, 194, 181, 174,
-	179, 170, 168, 64, 65, 0, 0, 0, 0, 182,
-	0, 88, 0, 0, 190, 13, 0, 0, 85, 0,
-	86, 87, 183, 0, 89, 0, 184,
+	0, 0, 0, 0, 0, 220, 221, 222, 223, 224,
+	225, 226, 198, 0, 0, 0, 0, 231, 246, 247,
+	248, 0, 0, 213, 0, 0, 257, 19, 0, 0,
+	0, 274, 282, 0, 194, 0, 231, 0, 45, 64,
+	65, 47, 48, 66, 67, 0, 69, 70, 71, 0,
+	51, 53, 54, 0, 0, 62, 0, 90, 0, 0,
+	0, 316, 120, 116, 98, 193, 159, 161, 162, 172,
+	170, 0, 150, 158, 153, 264, 0, 250, 0, 0,
+	196, 197, 200, 214, 0, 216, 218, 203, 204, 0,
+	228, 229, 0, 0, 0, 0, 206, 208, 0, 212,
+	236, 237, 238, 239, 240, 241, 242, 243, 244, 245,
+	199, 230, 0, 298, 249, 0, 261, 258, 0, 292,
+	0, 295, 292, 0, 290, 282, 24, 0, 0, 44,
+	68, 0, 49, 56, 0, 58, 0, 59, 74, 0,
+	87, 0, 336, 270, 0, 0, 0, 0, 175, 0,
+	0, 178, 0, 0, 0, 187, 173, 0, 0, 171,
+	0, 0, 251, 0, 215, 217, 219, 0, 205, 207,
+	209, 0, 0, 232, 253, 0, 259, 0, 0, 21,
+	0, 294, 296, 22, 289, 0, 23, 283, 275, 276,
+	279, 72, 0, 0, 63, 91, 89, 28, 272, 0,
+	160, 166, 0, 169, 176, 177, 179, 0, 181, 0,
+	183, 184, 163, 0, 0, 0, 174, 164, 165, 265,
+	252, 227, 0, 210, 255, 262, 0, 0, 0, 291,
+	0, 0, 278, 280, 281, 73, 57, 274, 0, 0,
+	0, 0, 180, 182, 0, 0, 0, 211, 260, 0,
+	297, 284, 277, 282, 273, 271, 167, 168, 0, 0,
+	0, 0, 285, 0, 191, 0, 0, 293, 18, 0,
+	0, 188, 0, 189, 190, 286, 0, 192, 0, 287,
 }
 var yyTok1 = [...]int{
 
@@ -602,6 +816,9 @@ var yyTok2 = [...]int{
 	68, 69, 70, 71, 72, 73, 74, 75, 78, 79,
 	87, 88, 90, 91, 92, 93, 94, 95, 96, 97,
 	98, 99, 100, 101, 102, 103, 104, 105, 106, 107,
+	108, 109, 110, 111, 112, 113, 114, 115, 116, 117,
+	118, 119, 120, 121, 122, 123, 124, 125, 126, 127,
+	128, 129,
 }
 var yyTok3 = [...]int{
 	0,
@@ -946,25 +1163,25 @@ yydefault:
 
 	case 1:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line sql.y:171
+		//line sql.y:219
 		{
 			setParseTree(yylex, yyDollar[1].statement)
 		}
 	case 2:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line sql.y:177
+		//line sql.y:225
 		{
 			yyVAL.statement = yyDollar[1].selStmt
 		}
-	case 13:
+	case 18:
 		yyDollar = yyS[yypt-13 : yypt+1]
-		//line sql.y:193
+		//line sql.y:246
 		{
 			yyVAL.selStmt = &Select{Comments: Comments(yyDollar[2].bytes2), Distinct: yyDollar[3].str, Hints: yyDollar[4].str, SelectExprs: yyDollar[5].selectExprs, From: yyDollar[7].tableExprs, Where: NewWhere(WhereStr, yyDollar[8].boolExpr), GroupBy: GroupBy(yyDollar[9].valExprs), Having: NewWhere(HavingStr, yyDollar[10].boolExpr), OrderBy: yyDollar[11].orderBy, Limit: yyDollar[12].limit, Lock: yyDollar[13].str}
 		}
-	case 14:
+	case 19:
 		yyDollar = yyS[yypt-6 : yypt+1]
-		//line sql.y:197
+		//line sql.y:250
 		{
 			if yyDollar[4].colIdent.Lowered() != "value" {
 				yylex.Error("expecting value after next")
@@ -972,21 +1189,21 @@ yydefault:
 			}
 			yyVAL.selStmt = &Select{Comments: Comments(yyDollar[2].bytes2), SelectExprs: SelectExprs{Nextval{}}, From: TableExprs{&AliasedTableExpr{Expr: yyDollar[6].tableName}}}
 		}
-	case 15:
+	case 20:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line sql.y:205
+		//line sql.y:258
 		{
 			yyVAL.selStmt = &Union{Type: yyDollar[2].str, Left: yyDollar[1].selStmt, Right: yyDollar[3].selStmt}
 		}
-	case 16:
+	case 21:
 		yyDollar = yyS[yypt-8 : yypt+1]
-		//line sql.y:211
+		//line sql.y:264
 		{
 			yyVAL.statement = &Insert{Comments: Comments(yyDollar[2].bytes2), Ignore: yyDollar[3].str, Table: yyDollar[5].tableName, Columns: yyDollar[6].columns, Rows: yyDollar[7].insRows, OnDup: OnDup(yyDollar[8].updateExprs)}
 		}
-	case 17:
+	case 22:
 		yyDollar = yyS[yypt-8 : yypt+1]
-		//line sql.y:215
+		//line sql.y:268
 		{
 			cols := make(Columns, 0, len(yyDollar[7].updateExprs))
 			vals := make(ValTuple, 0, len(yyDollar[7].updateExprs))
@@ -996,757 +1213,1401 @@ yydefault:
 			}
 			yyVAL.statement = &Insert{Comments: Comments(yyDollar[2].bytes2), Ignore: yyDollar[3].str, Table: yyDollar[5].tableName, Columns: cols, Rows: Values{vals}, OnDup: OnDup(yyDollar[8].updateExprs)}
 		}
-	case 18:
+	case 23:
 		yyDollar = yyS[yypt-8 : yypt+1]
-		//line sql.y:227
+		//line sql.y:280
 		{
 			yyVAL.statement = &Update{Comments: Comments(yyDollar[2].bytes2), Table: yyDollar[3].tableName, Exprs: yyDollar[5].updateExprs, Where: NewWhere(WhereStr, yyDollar[6].boolExpr), OrderBy: yyDollar[7].orderBy, Limit: yyDollar[8].limit}
 		}
-	case 19:
+	case 24:
 		yyDollar = yyS[yypt-7 : yypt+1]
-		//line sql.y:233
+		//line sql.y:286
 		{
 			yyVAL.statement = &Delete{Comments: Comments(yyDollar[2].bytes2), Table: yyDollar[4].tableName, Where: NewWhere(WhereStr, yyDollar[5].boolExpr), OrderBy: yyDollar[6].orderBy, Limit: yyDollar[7].limit}
 		}
-	case 20:
+	case 25:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line sql.y:239
+		//line sql.y:292
 		{
 			yyVAL.statement = &Set{Comments: Comments(yyDollar[2].bytes2), Exprs: yyDollar[3].updateExprs}
 		}
-	case 21:
-		yyDollar = yyS[yypt-5 : yypt+1]
-		//line sql.y:245
-		{
-			yyVAL.statement = &DDL{Action: CreateStr, NewName: yyDollar[4].tableIdent}
-		}
-	case 22:
-		yyDollar = yyS[yypt-8 : yypt+1]
-		//line sql.y:249
-		{
-			// Change this to an alter statement
-			yyVAL.statement = &DDL{Action: AlterStr, Table: yyDollar[7].tableIdent, NewName: yyDollar[7].tableIdent}
-		}
-	case 23:
-		yyDollar = yyS[yypt-4 : yypt+1]
-		//line sql.y:254
-		{
-			yyVAL.statement = &DDL{Action: CreateStr, NewName: TableIdent(yyDollar[3].colIdent.Lowered())}
-		}
-	case 24:
-		yyDollar = yyS[yypt-6 : yypt+1]
-		//line sql.y:260
-		{
-			yyVAL.statement = &DDL{Action: AlterStr, Table: yyDollar[4].tableIdent, NewName: yyDollar[4].tableIdent}
-		}
-	case 25:
-		yyDollar = yyS[yypt-7 : yypt+1]
-		//line sql.y:264
-		{
-			// Change this to a rename statement
-			yyVAL.statement = &DDL{Action: RenameStr, Table: yyDollar[4].tableIdent, NewName: yyDollar[7].tableIdent}
-		}
 	case 26:
-		yyDollar = yyS[yypt-4 : yypt+1]
-		//line sql.y:269
+		yyDollar = yyS[yypt-1 : yypt+1]
+		//line sql.y:298
 		{
-			yyVAL.statement = &DDL{Action: AlterStr, Table: TableIdent(yyDollar[3].colIdent.Lowered()), NewName: TableIdent(yyDollar[3].colIdent.Lowered())}
+			yyVAL.statement = yyDollar[1].ddl
 		}
 	case 27:
-		yyDollar = yyS[yypt-5 : yypt+1]
-		//line sql.y:275
+		yyDollar = yyS[yypt-2 : yypt+1]
+		//line sql.y:302
 		{
-			yyVAL.statement = &DDL{Action: RenameStr, Table: yyDollar[3].tableIdent, NewName: yyDollar[5].tableIdent}
+			yyDollar[1].ddl.TableSpec = yyDollar[2].tableSpec
+			yyVAL.statement = yyDollar[1].ddl
 		}
 	case 28:
-		yyDollar = yyS[yypt-4 : yypt+1]
-		//line sql.y:281
+		yyDollar = yyS[yypt-8 : yypt+1]
+		//line sql.y:307
 		{
-			var exists bool
-			if yyDollar[3].byt != 0 {
-				exists = true
-			}
-			yyVAL.statement = &DDL{Action: DropStr, Table: yyDollar[4].tableIdent, IfExists: exists}
+			// Change this to an alter statement. It's partial, since
+			// the index is dropped.
+			yyVAL.statement = &DDL{Action: AlterStr, Table: yyDollar[7].tableIdent, NewName: yyDollar[7].tableIdent, Partial: true}
 		}
 	case 29:
-		yyDollar = yyS[yypt-5 : yypt+1]
-		//line sql.y:289
+		yyDollar = yyS[yypt-4 : yypt+1]
+		//line sql.y:313
 		{
-			// Change this to an alter statement
-			yyVAL.statement = &DDL{Action: AlterStr, Table: yyDollar[5].tableIdent, NewName: yyDollar[5].tableIdent}
+			yyVAL.statement = &DDL{Action: CreateStr, NewName: TableIdent(yyDollar[3].colIdent.Lowered())}
 		}
 	case 30:
-		yyDollar = yyS[yypt-5 : yypt+1]
-		//line sql.y:294
+		yyDollar = yyS[yypt-2 : yypt+1]
+		//line sql.y:319
 		{
-			var exists bool
-			if yyDollar[3].byt != 0 {
-				exists = true
-			}
-			yyVAL.statement = &DDL{Action: DropStr, Table: TableIdent(yyDollar[4].colIdent.Lowered()), IfExists: exists}
+			yyDollar[1].ddl.AlterSpecs = yyDollar[2].alterSpecs
+			yyVAL.statement = yyDollar[1].ddl
 		}
 	case 31:
-		yyDollar = yyS[yypt-3 : yypt+1]
-		//line sql.y:304
+		yyDollar = yyS[yypt-4 : yypt+1]
+		//line sql.y:324
 		{
-			yyVAL.statement = &DDL{Action: AlterStr, Table: yyDollar[3].tableIdent, NewName: yyDollar[3].tableIdent}
+			// Change this to a rename statement
+			yyVAL.statement = &DDL{Action: RenameStr, Qualifier: yyDollar[1].ddl.Qualifier, Table: yyDollar[1].ddl.Table, NewName: yyDollar[4].tableIdent}
 		}
 	case 32:
-		yyDollar = yyS[yypt-2 : yypt+1]
-		//line sql.y:310
+		yyDollar = yyS[yypt-4 : yypt+1]
+		//line sql.y:329
 		{
-			yyVAL.statement = &Other{}
+			yyVAL.statement = &DDL{Action: AlterStr, Table: TableIdent(yyDollar[3].colIdent.Lowered()), NewName: TableIdent(yyDollar[3].colIdent.Lowered())}
 		}
 	case 33:
-		yyDollar = yyS[yypt-2 : yypt+1]
-		//line sql.y:314
+		yyDollar = yyS[yypt-4 : yypt+1]
+		//line sql.y:337
 		{
-			yyVAL.statement = &Other{}
+			var notExists bool
+			if yyDollar[3].byt != 0 {
+				notExists = true
+			}
+			yyVAL.ddl = &DDL{Action: CreateStr, Qualifier: yyDollar[4].tableName.Qualifier, NewName: yyDollar[4].tableName.Name, IfNotExists: notExists}
+			setPartialStatement(yylex, yyVAL.ddl)
 		}
 	case 34:
-		yyDollar = yyS[yypt-2 : yypt+1]
-		//line sql.y:318
+		yyDollar = yyS[yypt-4 : yypt+1]
+		//line sql.y:348
 		{
-			yyVAL.statement = &Other{}
+			yyVAL.ddl = &DDL{Action: AlterStr, Qualifier: yyDollar[4].tableName.Qualifier, Table: yyDollar[4].tableName.Name, NewName: yyDollar[4].tableName.Name}
+			setPartialStatement(yylex, yyVAL.ddl)
 		}
 	case 35:
-		yyDollar = yyS[yypt-0 : yypt+1]
-		//line sql.y:323
+		yyDollar = yyS[yypt-4 : yypt+1]
+		//line sql.y:355
 		{
-			setAllowComments(yylex, true)
+			yyVAL.tableSpec = yyDollar[2].tableSpec
+			yyVAL.tableSpec.Options = yyDollar[4].tableOptions
 		}
 	case 36:
-		yyDollar = yyS[yypt-2 : yypt+1]
-		//line sql.y:327
+		yyDollar = yyS[yypt-1 : yypt+1]
+		//line sql.y:362
 		{
-			yyVAL.bytes2 = yyDollar[2].bytes2
-			setAllowComments(yylex, false)
+			yyVAL.tableSpec = &TableSpec{Columns: []*ColumnDefinition{yyDollar[1].columnDefinition}}
 		}
 	case 37:
-		yyDollar = yyS[yypt-0 : yypt+1]
-		//line sql.y:333
+		yyDollar = yyS[yypt-1 : yypt+1]
+		//line sql.y:366
 		{
-			yyVAL.bytes2 = nil
+			yyVAL.tableSpec = &TableSpec{Indexes: []*IndexDefinition{yyDollar[1].indexDefinition}}
 		}
 	case 38:
-		yyDollar = yyS[yypt-2 : yypt+1]
-		//line sql.y:337
+		yyDollar = yyS[yypt-3 : yypt+1]
+		//line sql.y:370
 		{
-			yyVAL.bytes2 = append(yyDollar[1].bytes2, yyDollar[2].bytes)
+			yyVAL.tableSpec = yyDollar[1].tableSpec
+			yyVAL.tableSpec.Columns = append(yyVAL.tableSpec.Columns, yyDollar[3].columnDefinition)
 		}
 	case 39:
-		yyDollar = yyS[yypt-1 : yypt+1]
-		//line sql.y:343
+		yyDollar = yyS[yypt-3 : yypt+1]
+		//line sql.y:375
 		{
-			yyVAL.str = UnionStr
+			yyVAL.tableSpec = yyDollar[1].tableSpec
+			yyVAL.tableSpec.Indexes = append(yyVAL.tableSpec.Indexes, yyDollar[3].indexDefinition)
 		}
 	case 40:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line sql.y:347
+		//line sql.y:382
 		{
-			yyVAL.str = UnionAllStr
+			yyVAL.columnDefinition = &ColumnDefinition{Name: yyDollar[1].colIdent, Type: yyDollar[2].columnType}
 		}
 	case 41:
-		yyDollar = yyS[yypt-2 : yypt+1]
-		//line sql.y:351
+		yyDollar = yyS[yypt-1 : yypt+1]
+		//line sql.y:389
 		{
-			yyVAL.str = UnionDistinctStr
+			yyVAL.columnType = yyDollar[1].columnType
 		}
 	case 42:
-		yyDollar = yyS[yypt-0 : yypt+1]
-		//line sql.y:356
+		yyDollar = yyS[yypt-2 : yypt+1]
+		//line sql.y:393
 		{
-			yyVAL.str = ""
+			yyVAL.columnType = yyDollar[1].columnType
+			yyVAL.columnType.Unsigned = true
 		}
 	case 43:
-		yyDollar = yyS[yypt-1 : yypt+1]
-		//line sql.y:360
+		yyDollar = yyS[yypt-2 : yypt+1]
+		//line sql.y:398
 		{
-			yyVAL.str = DistinctStr
+			yyVAL.columnType = yyDollar[1].columnType
+			yyVAL.columnType.Zerofill = true
 		}
 	case 44:
-		yyDollar = yyS[yypt-0 : yypt+1]
-		//line sql.y:365
+		yyDollar = yyS[yypt-4 : yypt+1]
+		//line sql.y:403
 		{
-			yyVAL.str = ""
+			yyVAL.columnType = yyDollar[1].columnType
+			yyVAL.columnType.Charset = yyDollar[4].str
 		}
 	case 45:
-		yyDollar = yyS[yypt-1 : yypt+1]
-		//line sql.y:369
+		yyDollar = yyS[yypt-3 : yypt+1]
+		//line sql.y:408
 		{
-			yyVAL.str = StraightJoinHint
+			yyVAL.columnType = yyDollar[1].columnType
+			yyVAL.columnType.Collate = yyDollar[3].str
 		}
 	case 46:
-		yyDollar = yyS[yypt-1 : yypt+1]
-		//line sql.y:375
+		yyDollar = yyS[yypt-2 : yypt+1]
+		//line sql.y:413
 		{
-			yyVAL.selectExprs = SelectExprs{yyDollar[1].selectExpr}
+			yyVAL.columnType = yyDollar[1].columnType
+			yyVAL.columnType.Null = NullStr
 		}
 	case 47:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line sql.y:379
+		//line sql.y:418
 		{
-			yyVAL.selectExprs = append(yyVAL.selectExprs, yyDollar[3].selectExpr)
+			yyVAL.columnType = yyDollar[1].columnType
+			yyVAL.columnType.Null = NotNullStr
 		}
 	case 48:
-		yyDollar = yyS[yypt-1 : yypt+1]
-		//line sql.y:385
+		yyDollar = yyS[yypt-3 : yypt+1]
+		//line sql.y:423
 		{
-			yyVAL.selectExpr = &StarExpr{}
+			yyVAL.columnType = yyDollar[1].columnType
+			yyVAL.columnType.Default = yyDollar[3].valExpr
 		}
 	case 49:
-		yyDollar = yyS[yypt-2 : yypt+1]
-		//line sql.y:389
+		yyDollar = yyS[yypt-4 : yypt+1]
+		//line sql.y:428
 		{
-			yyVAL.selectExpr = &NonStarExpr{Expr: yyDollar[1].expr, As: yyDollar[2].colIdent}
+			yyVAL.columnType = yyDollar[1].columnType
+			yyVAL.columnType.OnUpdate = yyDollar[4].valExpr
 		}
 	case 50:
-		yyDollar = yyS[yypt-3 : yypt+1]
-		//line sql.y:393
+		yyDollar = yyS[yypt-2 : yypt+1]
+		//line sql.y:433
 		{
-			yyVAL.selectExpr = &StarExpr{TableName: yyDollar[1].tableIdent}
+			yyVAL.columnType = yyDollar[1].columnType
+			yyVAL.columnType.Autoincrement = true
 		}
 	case 51:
-		yyDollar = yyS[yypt-1 : yypt+1]
-		//line sql.y:399
+		yyDollar = yyS[yypt-3 : yypt+1]
+		//line sql.y:438
 		{
-			yyVAL.expr = yyDollar[1].boolExpr
+			yyVAL.columnType = yyDollar[1].columnType
+			yyVAL.columnType.KeyOpt = ColKeyPrimaryStr
 		}
 	case 52:
-		yyDollar = yyS[yypt-1 : yypt+1]
-		//line sql.y:403
+		yyDollar = yyS[yypt-2 : yypt+1]
+		//line sql.y:443
 		{
-			yyVAL.expr = yyDollar[1].valExpr
+			yyVAL.columnType = yyDollar[1].columnType
+			yyVAL.columnType.KeyOpt = ColKeyUniqueStr
 		}
 	case 53:
-		yyDollar = yyS[yypt-0 : yypt+1]
-		//line sql.y:408
+		yyDollar = yyS[yypt-3 : yypt+1]
+		//line sql.y:448
 		{
-			yyVAL.colIdent = ColIdent{}
+			yyVAL.columnType = yyDollar[1].columnType
+			yyVAL.columnType.KeyOpt = ColKeyUniqueKeyStr
 		}
 	case 54:
-		yyDollar = yyS[yypt-1 : yypt+1]
-		//line sql.y:412
+		yyDollar = yyS[yypt-3 : yypt+1]
+		//line sql.y:453
 		{
-			yyVAL.colIdent = yyDollar[1].colIdent
+			yyVAL.columnType = yyDollar[1].columnType
+			yyVAL.columnType.Comment = StrVal(yyDollar[3].bytes)
 		}
 	case 55:
-		yyDollar = yyS[yypt-2 : yypt+1]
-		//line sql.y:416
+		yyDollar = yyS[yypt-1 : yypt+1]
+		//line sql.y:460
 		{
-			yyVAL.colIdent = yyDollar[2].colIdent
+			yyVAL.columnType = ColumnType{Type: yyDollar[1].str}
 		}
 	case 56:
-		yyDollar = yyS[yypt-1 : yypt+1]
-		//line sql.y:422
+		yyDollar = yyS[yypt-4 : yypt+1]
+		//line sql.y:464
 		{
-			yyVAL.tableExprs = TableExprs{yyDollar[1].tableExpr}
+			yyVAL.columnType = ColumnType{Type: yyDollar[1].str, Length: NumVal(yyDollar[3].bytes)}
 		}
 	case 57:
-		yyDollar = yyS[yypt-3 : yypt+1]
-		//line sql.y:426
+		yyDollar = yyS[yypt-6 : yypt+1]
+		//line sql.y:468
 		{
-			yyVAL.tableExprs = append(yyVAL.tableExprs, yyDollar[3].tableExpr)
+			yyVAL.columnType = ColumnType{Type: yyDollar[1].str, Length: NumVal(yyDollar[3].bytes), Scale: NumVal(yyDollar[5].bytes)}
+		}
+	case 58:
+		yyDollar = yyS[yypt-4 : yypt+1]
+		//line sql.y:472
+		{
+			yyVAL.columnType = ColumnType{Type: yyDollar[1].str, EnumValues: yyDollar[3].strs}
+		}
+	case 59:
+		yyDollar = yyS[yypt-4 : yypt+1]
+		//line sql.y:476
+		{
+			yyVAL.columnType = ColumnType{Type: "set", EnumValues: yyDollar[3].strs}
 		}
 	case 60:
-		yyDollar = yyS[yypt-3 : yypt+1]
-		//line sql.y:436
+		yyDollar = yyS[yypt-1 : yypt+1]
+		//line sql.y:483
 		{
-			yyVAL.tableExpr = &AliasedTableExpr{Expr: yyDollar[1].tableName, As: yyDollar[2].tableIdent, Hints: yyDollar[3].indexHints}
+			yyVAL.str = strings.ToLower(string(yyDollar[1].bytes))
 		}
 	case 61:
-		yyDollar = yyS[yypt-3 : yypt+1]
-		//line sql.y:440
+		yyDollar = yyS[yypt-1 : yypt+1]
+		//line sql.y:487
 		{
-			yyVAL.tableExpr = &AliasedTableExpr{Expr: yyDollar[1].subquery, As: yyDollar[3].tableIdent}
+			yyVAL.str = string(yyDollar[1].bytes)
 		}
 	case 62:
-		yyDollar = yyS[yypt-3 : yypt+1]
-		//line sql.y:444
+		yyDollar = yyS[yypt-1 : yypt+1]
+		//line sql.y:493
 		{
-			yyVAL.tableExpr = &ParenTableExpr{Exprs: yyDollar[2].tableExprs}
+			yyVAL.strs = []string{string(yyDollar[1].bytes)}
 		}
 	case 63:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line sql.y:457
+		//line sql.y:497
 		{
-			yyVAL.tableExpr = &JoinTableExpr{LeftExpr: yyDollar[1].tableExpr, Join: yyDollar[2].str, RightExpr: yyDollar[3].tableExpr}
+			yyVAL.strs = append(yyDollar[1].strs, string(yyDollar[3].bytes))
 		}
 	case 64:
-		yyDollar = yyS[yypt-5 : yypt+1]
-		//line sql.y:461
+		yyDollar = yyS[yypt-1 : yypt+1]
+		//line sql.y:504
 		{
-			yyVAL.tableExpr = &JoinTableExpr{LeftExpr: yyDollar[1].tableExpr, Join: yyDollar[2].str, RightExpr: yyDollar[3].tableExpr, On: yyDollar[5].boolExpr}
+			yyVAL.str = string(yyDollar[1].bytes)
 		}
 	case 65:
-		yyDollar = yyS[yypt-5 : yypt+1]
-		//line sql.y:465
+		yyDollar = yyS[yypt-1 : yypt+1]
+		//line sql.y:508
 		{
-			yyVAL.tableExpr = &JoinTableExpr{LeftExpr: yyDollar[1].tableExpr, Join: yyDollar[2].str, RightExpr: yyDollar[3].tableExpr, On: yyDollar[5].boolExpr}
+			yyVAL.str = string(yyDollar[1].bytes)
 		}
 	case 66:
-		yyDollar = yyS[yypt-3 : yypt+1]
-		//line sql.y:469
+		yyDollar = yyS[yypt-1 : yypt+1]
+		//line sql.y:514
 		{
-			yyVAL.tableExpr = &JoinTableExpr{LeftExpr: yyDollar[1].tableExpr, Join: yyDollar[2].str, RightExpr: yyDollar[3].tableExpr}
+			yyVAL.valExpr = StrVal(yyDollar[1].bytes)
 		}
 	case 67:
-		yyDollar = yyS[yypt-0 : yypt+1]
-		//line sql.y:474
+		yyDollar = yyS[yypt-1 : yypt+1]
+		//line sql.y:518
 		{
-			yyVAL.empty = struct{}{}
+			yyVAL.valExpr = NumVal(yyDollar[1].bytes)
 		}
 	case 68:
-		yyDollar = yyS[yypt-1 : yypt+1]
-		//line sql.y:476
+		yyDollar = yyS[yypt-2 : yypt+1]
+		//line sql.y:522
 		{
-			yyVAL.empty = struct{}{}
+			yyVAL.valExpr = append(NumVal("-"), yyDollar[2].bytes...)
 		}
 	case 69:
-		yyDollar = yyS[yypt-0 : yypt+1]
-		//line sql.y:479
+		yyDollar = yyS[yypt-1 : yypt+1]
+		//line sql.y:526
 		{
-			yyVAL.tableIdent = ""
+			yyVAL.valExpr = &NullVal{}
 		}
 	case 70:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line sql.y:483
+		//line sql.y:530
 		{
-			yyVAL.tableIdent = yyDollar[1].tableIdent
+			yyVAL.valExpr = yyDollar[1].valExpr
 		}
 	case 71:
-		yyDollar = yyS[yypt-2 : yypt+1]
-		//line sql.y:487
+		yyDollar = yyS[yypt-1 : yypt+1]
+		//line sql.y:536
 		{
-			yyVAL.tableIdent = yyDollar[2].tableIdent
+			yyVAL.valExpr = &FuncExpr{Name: "current_timestamp"}
 		}
 	case 72:
-		yyDollar = yyS[yypt-1 : yypt+1]
-		//line sql.y:493
+		yyDollar = yyS[yypt-3 : yypt+1]
+		//line sql.y:540
 		{
-			yyVAL.str = JoinStr
+			yyVAL.valExpr = &FuncExpr{Name: "current_timestamp"}
 		}
 	case 73:
-		yyDollar = yyS[yypt-2 : yypt+1]
-		//line sql.y:497
+		yyDollar = yyS[yypt-4 : yypt+1]
+		//line sql.y:544
 		{
-			yyVAL.str = JoinStr
+			yyVAL.valExpr = &FuncExpr{Name: "current_timestamp", Exprs: SelectExprs{&NonStarExpr{Expr: NumVal(yyDollar[3].bytes)}}}
 		}
 	case 74:
-		yyDollar = yyS[yypt-2 : yypt+1]
-		//line sql.y:501
+		yyDollar = yyS[yypt-5 : yypt+1]
+		//line sql.y:550
 		{
-			yyVAL.str = JoinStr
+			yyVAL.indexDefinition = &IndexDefinition{Info: yyDollar[1].indexInfo, Columns: yyDollar[3].indexColumns, Using: yyDollar[5].colIdent}
 		}
 	case 75:
-		yyDollar = yyS[yypt-1 : yypt+1]
-		//line sql.y:505
+		yyDollar = yyS[yypt-2 : yypt+1]
+		//line sql.y:556
 		{
-			yyVAL.str = StraightJoinStr
+			yyVAL.indexInfo = &IndexInfo{Type: "primary key", Name: NewColIdent("PRIMARY"), Primary: true, Unique: true}
 		}
 	case 76:
-		yyDollar = yyS[yypt-2 : yypt+1]
-		//line sql.y:511
+		yyDollar = yyS[yypt-3 : yypt+1]
+		//line sql.y:560
 		{
-			yyVAL.str = LeftJoinStr
+			yyVAL.indexInfo = &IndexInfo{Type: "unique" + yyDollar[2].str, Name: yyDollar[3].colIdent, Unique: true}
 		}
 	case 77:
-		yyDollar = yyS[yypt-3 : yypt+1]
-		//line sql.y:515
+		yyDollar = yyS[yypt-2 : yypt+1]
+		//line sql.y:564
 		{
-			yyVAL.str = LeftJoinStr
+			yyVAL.indexInfo = &IndexInfo{Type: yyDollar[1].str, Name: yyDollar[2].colIdent}
 		}
 	case 78:
-		yyDollar = yyS[yypt-2 : yypt+1]
-		//line sql.y:519
+		yyDollar = yyS[yypt-3 : yypt+1]
+		//line sql.y:568
 		{
-			yyVAL.str = RightJoinStr
+			yyVAL.indexInfo = &IndexInfo{Type: "fulltext" + yyDollar[2].str, Name: yyDollar[3].colIdent}
 		}
 	case 79:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line sql.y:523
+		//line sql.y:572
 		{
-			yyVAL.str = RightJoinStr
+			yyVAL.indexInfo = &IndexInfo{Type: "spatial" + yyDollar[2].str, Name: yyDollar[3].colIdent}
 		}
 	case 80:
-		yyDollar = yyS[yypt-2 : yypt+1]
-		//line sql.y:529
+		yyDollar = yyS[yypt-1 : yypt+1]
+		//line sql.y:578
 		{
-			yyVAL.str = NaturalJoinStr
+			yyVAL.str = "index"
 		}
 	case 81:
-		yyDollar = yyS[yypt-2 : yypt+1]
-		//line sql.y:533
+		yyDollar = yyS[yypt-1 : yypt+1]
+		//line sql.y:582
 		{
-			if yyDollar[2].str == LeftJoinStr {
-				yyVAL.str = NaturalLeftJoinStr
-			} else {
-				yyVAL.str = NaturalRightJoinStr
-			}
+			yyVAL.str = "key"
 		}
 	case 82:
-		yyDollar = yyS[yypt-1 : yypt+1]
-		//line sql.y:543
+		yyDollar = yyS[yypt-0 : yypt+1]
+		//line sql.y:587
 		{
-			yyVAL.tableName = &TableName{Name: yyDollar[1].tableIdent}
+			yyVAL.str = ""
 		}
 	case 83:
-		yyDollar = yyS[yypt-3 : yypt+1]
-		//line sql.y:547
+		yyDollar = yyS[yypt-1 : yypt+1]
+		//line sql.y:591
 		{
-			yyVAL.tableName = &TableName{Qualifier: yyDollar[1].tableIdent, Name: yyDollar[3].tableIdent}
+			yyVAL.str = " " + yyDollar[1].str
 		}
 	case 84:
 		yyDollar = yyS[yypt-0 : yypt+1]
-		//line sql.y:552
+		//line sql.y:596
 		{
-			yyVAL.indexHints = nil
+			yyVAL.colIdent = ColIdent{}
 		}
 	case 85:
-		yyDollar = yyS[yypt-5 : yypt+1]
-		//line sql.y:556
+		yyDollar = yyS[yypt-1 : yypt+1]
+		//line sql.y:600
+		{
+			yyVAL.colIdent = yyDollar[1].colIdent
+		}
+	case 86:
+		yyDollar = yyS[yypt-1 : yypt+1]
+		//line sql.y:606
+		{
+			yyVAL.indexColumns = []*IndexColumn{yyDollar[1].indexColumn}
+		}
+	case 87:
+		yyDollar = yyS[yypt-3 : yypt+1]
+		//line sql.y:610
+		{
+			yyVAL.indexColumns = append(yyDollar[1].indexColumns, yyDollar[3].indexColumn)
+		}
+	case 88:
+		yyDollar = yyS[yypt-1 : yypt+1]
+		//line sql.y:616
+		{
+			yyVAL.indexColumn = &IndexColumn{Column: yyDollar[1].colIdent}
+		}
+	case 89:
+		yyDollar = yyS[yypt-4 : yypt+1]
+		//line sql.y:620
+		{
+			yyVAL.indexColumn = &IndexColumn{Column: yyDollar[1].colIdent, Length: NumVal(yyDollar[3].bytes)}
+		}
+	case 90:
+		yyDollar = yyS[yypt-0 : yypt+1]
+		//line sql.y:625
+		{
+			yyVAL.colIdent = ColIdent{}
+		}
+	case 91:
+		yyDollar = yyS[yypt-2 : yypt+1]
+		//line sql.y:629
+		{
+			yyVAL.colIdent = yyDollar[2].colIdent
+		}
+	case 92:
+		yyDollar = yyS[yypt-0 : yypt+1]
+		//line sql.y:634
+		{
+			yyVAL.tableOptions = nil
+		}
+	case 93:
+		yyDollar = yyS[yypt-1 : yypt+1]
+		//line sql.y:638
+		{
+			yyVAL.tableOptions = yyDollar[1].tableOptions
+		}
+	case 94:
+		yyDollar = yyS[yypt-1 : yypt+1]
+		//line sql.y:644
+		{
+			yyVAL.tableOptions = TableOptions{yyDollar[1].tableOption}
+		}
+	case 95:
+		yyDollar = yyS[yypt-2 : yypt+1]
+		//line sql.y:648
+		{
+			yyVAL.tableOptions = append(yyDollar[1].tableOptions, yyDollar[2].tableOption)
+		}
+	case 96:
+		yyDollar = yyS[yypt-3 : yypt+1]
+		//line sql.y:656
+		{
+			yyVAL.tableOption = yyDollar[3].tableOption
+			yyVAL.tableOption.Name = strings.ToLower(string(yyDollar[1].bytes))
+		}
+	case 97:
+		yyDollar = yyS[yypt-4 : yypt+1]
+		//line sql.y:661
+		{
+			yyVAL.tableOption = yyDollar[4].tableOption
+			yyVAL.tableOption.Name = "default " + strings.ToLower(string(yyDollar[2].bytes))
+		}
+	case 98:
+		yyDollar = yyS[yypt-5 : yypt+1]
+		//line sql.y:666
+		{
+			yyVAL.tableOption = yyDollar[5].tableOption
+			yyVAL.tableOption.Name = "default character set"
+		}
+	case 99:
+		yyDollar = yyS[yypt-4 : yypt+1]
+		//line sql.y:671
+		{
+			yyVAL.tableOption = yyDollar[4].tableOption
+			yyVAL.tableOption.Name = "default collate"
+		}
+	case 100:
+		yyDollar = yyS[yypt-4 : yypt+1]
+		//line sql.y:676
+		{
+			yyVAL.tableOption = yyDollar[4].tableOption
+			yyVAL.tableOption.Name = "character set"
+		}
+	case 101:
+		yyDollar = yyS[yypt-3 : yypt+1]
+		//line sql.y:681
+		{
+			yyVAL.tableOption = yyDollar[3].tableOption
+			yyVAL.tableOption.Name = "collate"
+		}
+	case 102:
+		yyDollar = yyS[yypt-3 : yypt+1]
+		//line sql.y:686
+		{
+			yyVAL.tableOption = yyDollar[3].tableOption
+			yyVAL.tableOption.Name = "auto_increment"
+		}
+	case 103:
+		yyDollar = yyS[yypt-3 : yypt+1]
+		//line sql.y:691
+		{
+			yyVAL.tableOption = yyDollar[3].tableOption
+			yyVAL.tableOption.Name = "comment"
+		}
+	case 104:
+		yyDollar = yyS[yypt-1 : yypt+1]
+		//line sql.y:698
+		{
+			yyVAL.tableOption = &TableOption{String: string(yyDollar[1].bytes)}
+		}
+	case 105:
+		yyDollar = yyS[yypt-1 : yypt+1]
+		//line sql.y:702
+		{
+			yyVAL.tableOption = &TableOption{String: string(yyDollar[1].bytes)}
+		}
+	case 106:
+		yyDollar = yyS[yypt-1 : yypt+1]
+		//line sql.y:706
+		{
+			yyVAL.tableOption = &TableOption{Value: StrVal(yyDollar[1].bytes)}
+		}
+	case 107:
+		yyDollar = yyS[yypt-1 : yypt+1]
+		//line sql.y:710
+		{
+			yyVAL.tableOption = &TableOption{Value: NumVal(yyDollar[1].bytes)}
+		}
+	case 108:
+		yyDollar = yyS[yypt-1 : yypt+1]
+		//line sql.y:716
+		{
+			yyVAL.alterSpecs = AlterSpecs{yyDollar[1].alterSpec}
+		}
+	case 109:
+		yyDollar = yyS[yypt-3 : yypt+1]
+		//line sql.y:720
+		{
+			yyVAL.alterSpecs = append(yyDollar[1].alterSpecs, yyDollar[3].alterSpec)
+		}
+	case 110:
+		yyDollar = yyS[yypt-4 : yypt+1]
+		//line sql.y:726
+		{
+			yyVAL.alterSpec = yyDollar[4].alterSpec
+			yyVAL.alterSpec.Action = AddColumnStr
+			yyVAL.alterSpec.Column = yyDollar[3].columnDefinition
+		}
+	case 111:
+		yyDollar = yyS[yypt-2 : yypt+1]
+		//line sql.y:732
+		{
+			yyVAL.alterSpec = &AlterSpec{Action: AddIndexStr, Index: yyDollar[2].indexDefinition}
+		}
+	case 112:
+		yyDollar = yyS[yypt-3 : yypt+1]
+		//line sql.y:736
+		{
+			yyVAL.alterSpec = &AlterSpec{Action: DropColumnStr, Name: yyDollar[3].colIdent}
+		}
+	case 113:
+		yyDollar = yyS[yypt-3 : yypt+1]
+		//line sql.y:740
+		{
+			yyVAL.alterSpec = &AlterSpec{Action: DropIndexStr, Name: yyDollar[3].colIdent}
+		}
+	case 114:
+		yyDollar = yyS[yypt-3 : yypt+1]
+		//line sql.y:744
+		{
+			yyVAL.alterSpec = &AlterSpec{Action: DropPrimaryKeyStr}
+		}
+	case 115:
+		yyDollar = yyS[yypt-4 : yypt+1]
+		//line sql.y:748
+		{
+			if strings.ToLower(string(yyDollar[1].bytes)) != "modify" {
+				yylex.Error("expecting modify")
+				return 1
+			}
+			yyVAL.alterSpec = yyDollar[4].alterSpec
+			yyVAL.alterSpec.Action = ModifyColumnStr
+			yyVAL.alterSpec.Column = yyDollar[3].columnDefinition
+		}
+	case 116:
+		yyDollar = yyS[yypt-5 : yypt+1]
+		//line sql.y:758
+		{
+			yyVAL.alterSpec = yyDollar[5].alterSpec
+			yyVAL.alterSpec.Action = ChangeColumnStr
+			yyVAL.alterSpec.Name = yyDollar[3].colIdent
+			yyVAL.alterSpec.Column = yyDollar[4].columnDefinition
+		}
+	case 117:
+		yyDollar = yyS[yypt-1 : yypt+1]
+		//line sql.y:765
+		{
+			yyVAL.alterSpec = &AlterSpec{Action: TableOptionsStr, Options: yyDollar[1].tableOptions}
+		}
+	case 118:
+		yyDollar = yyS[yypt-0 : yypt+1]
+		//line sql.y:770
+		{
+			yyVAL.alterSpec = &AlterSpec{}
+		}
+	case 119:
+		yyDollar = yyS[yypt-1 : yypt+1]
+		//line sql.y:774
+		{
+			yyVAL.alterSpec = &AlterSpec{First: true}
+		}
+	case 120:
+		yyDollar = yyS[yypt-2 : yypt+1]
+		//line sql.y:778
+		{
+			yyVAL.alterSpec = &AlterSpec{After: yyDollar[2].colIdent}
+		}
+	case 121:
+		yyDollar = yyS[yypt-5 : yypt+1]
+		//line sql.y:784
+		{
+			yyVAL.statement = &DDL{Action: RenameStr, Table: yyDollar[3].tableIdent, NewName: yyDollar[5].tableIdent}
+		}
+	case 122:
+		yyDollar = yyS[yypt-4 : yypt+1]
+		//line sql.y:790
+		{
+			var exists bool
+			if yyDollar[3].byt != 0 {
+				exists = true
+			}
+			yyVAL.statement = &DDL{Action: DropStr, Table: yyDollar[4].tableIdent, IfExists: exists}
+		}
+	case 123:
+		yyDollar = yyS[yypt-5 : yypt+1]
+		//line sql.y:798
+		{
+			// Change this to an alter statement. It's partial, since
+			// the index is dropped.
+			yyVAL.statement = &DDL{Action: AlterStr, Table: yyDollar[5].tableIdent, NewName: yyDollar[5].tableIdent, Partial: true}
+		}
+	case 124:
+		yyDollar = yyS[yypt-5 : yypt+1]
+		//line sql.y:804
+		{
+			var exists bool
+			if yyDollar[3].byt != 0 {
+				exists = true
+			}
+			yyVAL.statement = &DDL{Action: DropStr, Table: TableIdent(yyDollar[4].colIdent.Lowered()), IfExists: exists}
+		}
+	case 125:
+		yyDollar = yyS[yypt-3 : yypt+1]
+		//line sql.y:814
+		{
+			yyVAL.statement = &DDL{Action: AlterStr, Table: yyDollar[3].tableIdent, NewName: yyDollar[3].tableIdent}
+		}
+	case 126:
+		yyDollar = yyS[yypt-2 : yypt+1]
+		//line sql.y:822
+		{
+			yyVAL.statement = &Show{Type: ShowDatabasesStr}
+		}
+	case 127:
+		yyDollar = yyS[yypt-2 : yypt+1]
+		//line sql.y:826
+		{
+			switch strings.ToLower(string(yyDollar[2].bytes)) {
+			case ShowTablesStr:
+				yyVAL.statement = &Show{Type: ShowTablesStr}
+			case ShowVitessShardsStr:
+				yyVAL.statement = &Show{Type: ShowVitessShardsStr}
+			default:
+				yyVAL.statement = &Show{Type: ShowUnsupportedStr}
+			}
+		}
+	case 128:
+		yyDollar = yyS[yypt-3 : yypt+1]
+		//line sql.y:837
+		{
+			if strings.ToLower(string(yyDollar[2].bytes)) == "vschema" && strings.ToLower(string(yyDollar[3].bytes)) == "tables" {
+				yyVAL.statement = &Show{Type: ShowVSchemaTablesStr}
+			} else {
+				yyVAL.statement = &Show{Type: ShowUnsupportedStr}
+			}
+		}
+	case 129:
+		yyDollar = yyS[yypt-1 : yypt+1]
+		//line sql.y:847
+		{
+			setPartialStatement(yylex, &Show{Type: ShowUnsupportedStr})
+		}
+	case 130:
+		yyDollar = yyS[yypt-2 : yypt+1]
+		//line sql.y:854
+		{
+			yyVAL.statement = &Use{}
+		}
+	case 131:
+		yyDollar = yyS[yypt-3 : yypt+1]
+		//line sql.y:858
+		{
+			yyVAL.statement = NewUse(string(yyDollar[3].bytes))
+		}
+	case 132:
+		yyDollar = yyS[yypt-1 : yypt+1]
+		//line sql.y:864
+		{
+			yyVAL.statement = &Begin{}
+		}
+	case 133:
+		yyDollar = yyS[yypt-2 : yypt+1]
+		//line sql.y:868
+		{
+			yyVAL.statement = &Begin{}
+		}
+	case 134:
+		yyDollar = yyS[yypt-1 : yypt+1]
+		//line sql.y:874
+		{
+			yyVAL.statement = &Commit{}
+		}
+	case 135:
+		yyDollar = yyS[yypt-1 : yypt+1]
+		//line sql.y:880
+		{
+			yyVAL.statement = &Rollback{}
+		}
+	case 136:
+		yyDollar = yyS[yypt-2 : yypt+1]
+		//line sql.y:886
+		{
+			yyVAL.statement = &Other{}
+		}
+	case 137:
+		yyDollar = yyS[yypt-2 : yypt+1]
+		//line sql.y:890
+		{
+			yyVAL.statement = &Other{}
+		}
+	case 138:
+		yyDollar = yyS[yypt-0 : yypt+1]
+		//line sql.y:895
+		{
+			setAllowComments(yylex, true)
+		}
+	case 139:
+		yyDollar = yyS[yypt-2 : yypt+1]
+		//line sql.y:899
+		{
+			yyVAL.bytes2 = yyDollar[2].bytes2
+			setAllowComments(yylex, false)
+		}
+	case 140:
+		yyDollar = yyS[yypt-0 : yypt+1]
+		//line sql.y:905
+		{
+			yyVAL.bytes2 = nil
+		}
+	case 141:
+		yyDollar = yyS[yypt-2 : yypt+1]
+		//line sql.y:909
+		{
+			yyVAL.bytes2 = append(yyDollar[1].bytes2, yyDollar[2].bytes)
+		}
+	case 142:
+		yyDollar = yyS[yypt-1 : yypt+1]
+		//line sql.y:915
+		{
+			yyVAL.str = UnionStr
+		}
+	case 143:
+		yyDollar = yyS[yypt-2 : yypt+1]
+		//line sql.y:919
+		{
+			yyVAL.str = UnionAllStr
+		}
+	case 144:
+		yyDollar = yyS[yypt-2 : yypt+1]
+		//line sql.y:923
+		{
+			yyVAL.str = UnionDistinctStr
+		}
+	case 145:
+		yyDollar = yyS[yypt-0 : yypt+1]
+		//line sql.y:928
+		{
+			yyVAL.str = ""
+		}
+	case 146:
+		yyDollar = yyS[yypt-1 : yypt+1]
+		//line sql.y:932
+		{
+			yyVAL.str = DistinctStr
+		}
+	case 147:
+		yyDollar = yyS[yypt-0 : yypt+1]
+		//line sql.y:937
+		{
+			yyVAL.str = ""
+		}
+	case 148:
+		yyDollar = yyS[yypt-1 : yypt+1]
+		//line sql.y:941
+		{
+			yyVAL.str = StraightJoinHint
+		}
+	case 149:
+		yyDollar = yyS[yypt-1 : yypt+1]
+		//line sql.y:947
+		{
+			yyVAL.selectExprs = SelectExprs{yyDollar[1].selectExpr}
+		}
+	case 150:
+		yyDollar = yyS[yypt-3 : yypt+1]
+		//line sql.y:951
+		{
+			yyVAL.selectExprs = append(yyVAL.selectExprs, yyDollar[3].selectExpr)
+		}
+	case 151:
+		yyDollar = yyS[yypt-1 : yypt+1]
+		//line sql.y:957
+		{
+			yyVAL.selectExpr = &StarExpr{}
+		}
+	case 152:
+		yyDollar = yyS[yypt-2 : yypt+1]
+		//line sql.y:961
+		{
+			yyVAL.selectExpr = &NonStarExpr{Expr: yyDollar[1].expr, As: yyDollar[2].colIdent}
+		}
+	case 153:
+		yyDollar = yyS[yypt-3 : yypt+1]
+		//line sql.y:965
+		{
+			yyVAL.selectExpr = &StarExpr{TableName: yyDollar[1].tableIdent}
+		}
+	case 154:
+		yyDollar = yyS[yypt-1 : yypt+1]
+		//line sql.y:971
+		{
+			yyVAL.expr = yyDollar[1].boolExpr
+		}
+	case 155:
+		yyDollar = yyS[yypt-1 : yypt+1]
+		//line sql.y:975
+		{
+			yyVAL.expr = yyDollar[1].valExpr
+		}
+	case 156:
+		yyDollar = yyS[yypt-0 : yypt+1]
+		//line sql.y:980
+		{
+			yyVAL.colIdent = ColIdent{}
+		}
+	case 157:
+		yyDollar = yyS[yypt-1 : yypt+1]
+		//line sql.y:984
+		{
+			yyVAL.colIdent = yyDollar[1].colIdent
+		}
+	case 158:
+		yyDollar = yyS[yypt-2 : yypt+1]
+		//line sql.y:988
+		{
+			yyVAL.colIdent = yyDollar[2].colIdent
+		}
+	case 159:
+		yyDollar = yyS[yypt-1 : yypt+1]
+		//line sql.y:994
+		{
+			yyVAL.tableExprs = TableExprs{yyDollar[1].tableExpr}
+		}
+	case 160:
+		yyDollar = yyS[yypt-3 : yypt+1]
+		//line sql.y:998
+		{
+			yyVAL.tableExprs = append(yyVAL.tableExprs, yyDollar[3].tableExpr)
+		}
+	case 163:
+		yyDollar = yyS[yypt-3 : yypt+1]
+		//line sql.y:1008
+		{
+			yyVAL.tableExpr = &AliasedTableExpr{Expr: yyDollar[1].tableName, As: yyDollar[2].tableIdent, Hints: yyDollar[3].indexHints}
+		}
+	case 164:
+		yyDollar = yyS[yypt-3 : yypt+1]
+		//line sql.y:1012
+		{
+			yyVAL.tableExpr = &AliasedTableExpr{Expr: yyDollar[1].subquery, As: yyDollar[3].tableIdent}
+		}
+	case 165:
+		yyDollar = yyS[yypt-3 : yypt+1]
+		//line sql.y:1016
+		{
+			yyVAL.tableExpr = &ParenTableExpr{Exprs: yyDollar[2].tableExprs}
+		}
+	case 166:
+		yyDollar = yyS[yypt-3 : yypt+1]
+		//line sql.y:1029
+		{
+			yyVAL.tableExpr = &JoinTableExpr{LeftExpr: yyDollar[1].tableExpr, Join: yyDollar[2].str, RightExpr: yyDollar[3].tableExpr}
+		}
+	case 167:
+		yyDollar = yyS[yypt-5 : yypt+1]
+		//line sql.y:1033
+		{
+			yyVAL.tableExpr = &JoinTableExpr{LeftExpr: yyDollar[1].tableExpr, Join: yyDollar[2].str, RightExpr: yyDollar[3].tableExpr, On: yyDollar[5].boolExpr}
+		}
+	case 168:
+		yyDollar = yyS[yypt-5 : yypt+1]
+		//line sql.y:1037
+		{
+			yyVAL.tableExpr = &JoinTableExpr{LeftExpr: yyDollar[1].tableExpr, Join: yyDollar[2].str, RightExpr: yyDollar[3].tableExpr, On: yyDollar[5].boolExpr}
+		}
+	case 169:
+		yyDollar = yyS[yypt-3 : yypt+1]
+		//line sql.y:1041
+		{
+			yyVAL.tableExpr = &JoinTableExpr{LeftExpr: yyDollar[1].tableExpr, Join: yyDollar[2].str, RightExpr: yyDollar[3].tableExpr}
+		}
+	case 170:
+		yyDollar = yyS[yypt-0 : yypt+1]
+		//line sql.y:1046
+		{
+			yyVAL.empty = struct{}{}
+		}
+	case 171:
+		yyDollar = yyS[yypt-1 : yypt+1]
+		//line sql.y:1048
+		{
+			yyVAL.empty = struct{}{}
+		}
+	case 172:
+		yyDollar = yyS[yypt-0 : yypt+1]
+		//line sql.y:1051
+		{
+			yyVAL.tableIdent = ""
+		}
+	case 173:
+		yyDollar = yyS[yypt-1 : yypt+1]
+		//line sql.y:1055
+		{
+			yyVAL.tableIdent = yyDollar[1].tableIdent
+		}
+	case 174:
+		yyDollar = yyS[yypt-2 : yypt+1]
+		//line sql.y:1059
+		{
+			yyVAL.tableIdent = yyDollar[2].tableIdent
+		}
+	case 175:
+		yyDollar = yyS[yypt-1 : yypt+1]
+		//line sql.y:1065
+		{
+			yyVAL.str = JoinStr
+		}
+	case 176:
+		yyDollar = yyS[yypt-2 : yypt+1]
+		//line sql.y:1069
+		{
+			yyVAL.str = JoinStr
+		}
+	case 177:
+		yyDollar = yyS[yypt-2 : yypt+1]
+		//line sql.y:1073
+		{
+			yyVAL.str = JoinStr
+		}
+	case 178:
+		yyDollar = yyS[yypt-1 : yypt+1]
+		//line sql.y:1077
+		{
+			yyVAL.str = StraightJoinStr
+		}
+	case 179:
+		yyDollar = yyS[yypt-2 : yypt+1]
+		//line sql.y:1083
+		{
+			yyVAL.str = LeftJoinStr
+		}
+	case 180:
+		yyDollar = yyS[yypt-3 : yypt+1]
+		//line sql.y:1087
+		{
+			yyVAL.str = LeftJoinStr
+		}
+	case 181:
+		yyDollar = yyS[yypt-2 : yypt+1]
+		//line sql.y:1091
+		{
+			yyVAL.str = RightJoinStr
+		}
+	case 182:
+		yyDollar = yyS[yypt-3 : yypt+1]
+		//line sql.y:1095
+		{
+			yyVAL.str = RightJoinStr
+		}
+	case 183:
+		yyDollar = yyS[yypt-2 : yypt+1]
+		//line sql.y:1101
+		{
+			yyVAL.str = NaturalJoinStr
+		}
+	case 184:
+		yyDollar = yyS[yypt-2 : yypt+1]
+		//line sql.y:1105
+		{
+			if yyDollar[2].str == LeftJoinStr {
+				yyVAL.str = NaturalLeftJoinStr
+			} else {
+				yyVAL.str = NaturalRightJoinStr
+			}
+		}
+	case 185:
+		yyDollar = yyS[yypt-1 : yypt+1]
+		//line sql.y:1115
+		{
+			yyVAL.tableName = &TableName{Name: yyDollar[1].tableIdent}
+		}
+	case 186:
+		yyDollar = yyS[yypt-3 : yypt+1]
+		//line sql.y:1119
+		{
+			yyVAL.tableName = &TableName{Qualifier: yyDollar[1].tableIdent, Name: yyDollar[3].tableIdent}
+		}
+	case 187:
+		yyDollar = yyS[yypt-0 : yypt+1]
+		//line sql.y:1124
+		{
+			yyVAL.indexHints = nil
+		}
+	case 188:
+		yyDollar = yyS[yypt-5 : yypt+1]
+		//line sql.y:1128
 		{
 			yyVAL.indexHints = &IndexHints{Type: UseStr, Indexes: yyDollar[4].colIdents}
 		}
-	case 86:
+	case 189:
 		yyDollar = yyS[yypt-5 : yypt+1]
-		//line sql.y:560
+		//line sql.y:1132
 		{
 			yyVAL.indexHints = &IndexHints{Type: IgnoreStr, Indexes: yyDollar[4].colIdents}
 		}
-	case 87:
+	case 190:
 		yyDollar = yyS[yypt-5 : yypt+1]
-		//line sql.y:564
+		//line sql.y:1136
 		{
 			yyVAL.indexHints = &IndexHints{Type: ForceStr, Indexes: yyDollar[4].colIdents}
 		}
-	case 88:
+	case 191:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line sql.y:570
+		//line sql.y:1142
 		{
 			yyVAL.colIdents = []ColIdent{yyDollar[1].colIdent}
 		}
-	case 89:
+	case 192:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line sql.y:574
+		//line sql.y:1146
 		{
 			yyVAL.colIdents = append(yyDollar[1].colIdents, yyDollar[3].colIdent)
 		}
-	case 90:
+	case 193:
 		yyDollar = yyS[yypt-0 : yypt+1]
-		//line sql.y:579
+		//line sql.y:1151
 		{
 			yyVAL.boolExpr = nil
 		}
-	case 91:
+	case 194:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line sql.y:583
+		//line sql.y:1155
 		{
 			yyVAL.boolExpr = yyDollar[2].boolExpr
 		}
-	case 93:
+	case 196:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line sql.y:590
+		//line sql.y:1162
 		{
 			yyVAL.boolExpr = &AndExpr{Left: yyDollar[1].boolExpr, Right: yyDollar[3].boolExpr}
 		}
-	case 94:
+	case 197:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line sql.y:594
+		//line sql.y:1166
 		{
 			yyVAL.boolExpr = &OrExpr{Left: yyDollar[1].boolExpr, Right: yyDollar[3].boolExpr}
 		}
-	case 95:
+	case 198:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line sql.y:598
+		//line sql.y:1170
 		{
 			yyVAL.boolExpr = &NotExpr{Expr: yyDollar[2].boolExpr}
 		}
-	case 96:
+	case 199:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line sql.y:602
+		//line sql.y:1174
 		{
 			yyVAL.boolExpr = &ParenBoolExpr{Expr: yyDollar[2].boolExpr}
 		}
-	case 97:
+	case 200:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line sql.y:606
+		//line sql.y:1178
 		{
 			yyVAL.boolExpr = &IsExpr{Operator: yyDollar[3].str, Expr: yyDollar[1].boolExpr}
 		}
-	case 98:
+	case 201:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line sql.y:612
+		//line sql.y:1184
 		{
 			yyVAL.boolExpr = BoolVal(true)
 		}
-	case 99:
+	case 202:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line sql.y:616
+		//line sql.y:1188
 		{
 			yyVAL.boolExpr = BoolVal(false)
 		}
-	case 100:
+	case 203:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line sql.y:620
+		//line sql.y:1192
 		{
 			yyVAL.boolExpr = &ComparisonExpr{Left: yyDollar[1].valExpr, Operator: yyDollar[2].str, Right: yyDollar[3].valExpr}
 		}
-	case 101:
+	case 204:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line sql.y:624
+		//line sql.y:1196
 		{
 			yyVAL.boolExpr = &ComparisonExpr{Left: yyDollar[1].valExpr, Operator: InStr, Right: yyDollar[3].colTuple}
 		}
-	case 102:
+	case 205:
 		yyDollar = yyS[yypt-4 : yypt+1]
-		//line sql.y:628
+		//line sql.y:1200
 		{
 			yyVAL.boolExpr = &ComparisonExpr{Left: yyDollar[1].valExpr, Operator: NotInStr, Right: yyDollar[4].colTuple}
 		}
-	case 103:
+	case 206:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line sql.y:632
+		//line sql.y:1204
 		{
 			yyVAL.boolExpr = &ComparisonExpr{Left: yyDollar[1].valExpr, Operator: LikeStr, Right: yyDollar[3].valExpr}
 		}
-	case 104:
+	case 207:
 		yyDollar = yyS[yypt-4 : yypt+1]
-		//line sql.y:636
+		//line sql.y:1208
 		{
 			yyVAL.boolExpr = &ComparisonExpr{Left: yyDollar[1].valExpr, Operator: NotLikeStr, Right: yyDollar[4].valExpr}
 		}
-	case 105:
+	case 208:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line sql.y:640
+		//line sql.y:1212
 		{
 			yyVAL.boolExpr = &ComparisonExpr{Left: yyDollar[1].valExpr, Operator: RegexpStr, Right: yyDollar[3].valExpr}
 		}
-	case 106:
+	case 209:
 		yyDollar = yyS[yypt-4 : yypt+1]
-		//line sql.y:644
+		//line sql.y:1216
 		{
 			yyVAL.boolExpr = &ComparisonExpr{Left: yyDollar[1].valExpr, Operator: NotRegexpStr, Right: yyDollar[4].valExpr}
 		}
-	case 107:
+	case 210:
 		yyDollar = yyS[yypt-5 : yypt+1]
-		//line sql.y:648
+		//line sql.y:1220
 		{
 			yyVAL.boolExpr = &RangeCond{Left: yyDollar[1].valExpr, Operator: BetweenStr, From: yyDollar[3].valExpr, To: yyDollar[5].valExpr}
 		}
-	case 108:
+	case 211:
 		yyDollar = yyS[yypt-6 : yypt+1]
-		//line sql.y:652
+		//line sql.y:1224
 		{
 			yyVAL.boolExpr = &RangeCond{Left: yyDollar[1].valExpr, Operator: NotBetweenStr, From: yyDollar[4].valExpr, To: yyDollar[6].valExpr}
 		}
-	case 109:
+	case 212:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line sql.y:656
+		//line sql.y:1228
 		{
 			yyVAL.boolExpr = &IsExpr{Operator: yyDollar[3].str, Expr: yyDollar[1].valExpr}
 		}
-	case 110:
+	case 213:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line sql.y:660
+		//line sql.y:1232
 		{
 			yyVAL.boolExpr = &ExistsExpr{Subquery: yyDollar[2].subquery}
 		}
-	case 111:
+	case 214:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line sql.y:666
+		//line sql.y:1238
 		{
 			yyVAL.str = IsNullStr
 		}
-	case 112:
+	case 215:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line sql.y:670
+		//line sql.y:1242
 		{
 			yyVAL.str = IsNotNullStr
 		}
-	case 113:
+	case 216:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line sql.y:674
+		//line sql.y:1246
 		{
 			yyVAL.str = IsTrueStr
 		}
-	case 114:
+	case 217:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line sql.y:678
+		//line sql.y:1250
 		{
 			yyVAL.str = IsNotTrueStr
 		}
-	case 115:
+	case 218:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line sql.y:682
+		//line sql.y:1254
 		{
 			yyVAL.str = IsFalseStr
 		}
-	case 116:
+	case 219:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line sql.y:686
+		//line sql.y:1258
 		{
 			yyVAL.str = IsNotFalseStr
 		}
-	case 117:
+	case 220:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line sql.y:692
+		//line sql.y:1264
 		{
 			yyVAL.str = EqualStr
 		}
-	case 118:
+	case 221:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line sql.y:696
+		//line sql.y:1268
 		{
 			yyVAL.str = LessThanStr
 		}
-	case 119:
+	case 222:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line sql.y:700
+		//line sql.y:1272
 		{
 			yyVAL.str = GreaterThanStr
 		}
-	case 120:
+	case 223:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line sql.y:704
+		//line sql.y:1276
 		{
 			yyVAL.str = LessEqualStr
 		}
-	case 121:
+	case 224:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line sql.y:708
+		//line sql.y:1280
 		{
 			yyVAL.str = GreaterEqualStr
 		}
-	case 122:
+	case 225:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line sql.y:712
+		//line sql.y:1284
 		{
 			yyVAL.str = NotEqualStr
 		}
-	case 123:
+	case 226:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line sql.y:716
+		//line sql.y:1288
 		{
 			yyVAL.str = NullSafeEqualStr
 		}
-	case 124:
+	case 227:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line sql.y:722
+		//line sql.y:1294
 		{
 			yyVAL.colTuple = ValTuple(yyDollar[2].valExprs)
 		}
-	case 125:
+	case 228:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line sql.y:726
+		//line sql.y:1298
 		{
 			yyVAL.colTuple = yyDollar[1].subquery
 		}
-	case 126:
+	case 229:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line sql.y:730
+		//line sql.y:1302
 		{
 			yyVAL.colTuple = ListArg(yyDollar[1].bytes)
 		}
-	case 127:
+	case 230:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line sql.y:736
+		//line sql.y:1308
 		{
 			yyVAL.subquery = &Subquery{yyDollar[2].selStmt}
 		}
-	case 128:
+	case 231:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line sql.y:742
+		//line sql.y:1314
 		{
 			yyVAL.valExprs = ValExprs{yyDollar[1].valExpr}
 		}
-	case 129:
+	case 232:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line sql.y:746
+		//line sql.y:1318
 		{
 			yyVAL.valExprs = append(yyDollar[1].valExprs, yyDollar[3].valExpr)
 		}
-	case 130:
+	case 233:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line sql.y:752
+		//line sql.y:1324
 		{
 			yyVAL.valExpr = yyDollar[1].valExpr
 		}
-	case 131:
+	case 234:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line sql.y:756
+		//line sql.y:1328
 		{
 			yyVAL.valExpr = yyDollar[1].colName
 		}
-	case 132:
+	case 235:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line sql.y:760
+		//line sql.y:1332
 		{
 			yyVAL.valExpr = yyDollar[1].rowTuple
 		}
-	case 133:
+	case 236:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line sql.y:764
+		//line sql.y:1336
 		{
 			yyVAL.valExpr = &BinaryExpr{Left: yyDollar[1].valExpr, Operator: BitAndStr, Right: yyDollar[3].valExpr}
 		}
-	case 134:
+	case 237:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line sql.y:768
+		//line sql.y:1340
 		{
 			yyVAL.valExpr = &BinaryExpr{Left: yyDollar[1].valExpr, Operator: BitOrStr, Right: yyDollar[3].valExpr}
 		}
-	case 135:
+	case 238:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line sql.y:772
+		//line sql.y:1344
 		{
 			yyVAL.valExpr = &BinaryExpr{Left: yyDollar[1].valExpr, Operator: BitXorStr, Right: yyDollar[3].valExpr}
 		}
-	case 136:
+	case 239:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line sql.y:776
+		//line sql.y:1348
 		{
 			yyVAL.valExpr = &BinaryExpr{Left: yyDollar[1].valExpr, Operator: PlusStr, Right: yyDollar[3].valExpr}
 		}
-	case 137:
+	case 240:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line sql.y:780
+		//line sql.y:1352
 		{
 			yyVAL.valExpr = &BinaryExpr{Left: yyDollar[1].valExpr, Operator: MinusStr, Right: yyDollar[3].valExpr}
 		}
-	case 138:
+	case 241:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line sql.y:784
+		//line sql.y:1356
 		{
 			yyVAL.valExpr = &BinaryExpr{Left: yyDollar[1].valExpr, Operator: MultStr, Right: yyDollar[3].valExpr}
 		}
-	case 139:
+	case 242:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line sql.y:788
+		//line sql.y:1360
 		{
 			yyVAL.valExpr = &BinaryExpr{Left: yyDollar[1].valExpr, Operator: DivStr, Right: yyDollar[3].valExpr}
 		}
-	case 140:
+	case 243:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line sql.y:792
+		//line sql.y:1364
 		{
 			yyVAL.valExpr = &BinaryExpr{Left: yyDollar[1].valExpr, Operator: ModStr, Right: yyDollar[3].valExpr}
 		}
-	case 141:
+	case 244:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line sql.y:796
+		//line sql.y:1368
 		{
 			yyVAL.valExpr = &BinaryExpr{Left: yyDollar[1].valExpr, Operator: ShiftLeftStr, Right: yyDollar[3].valExpr}
 		}
-	case 142:
+	case 245:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line sql.y:800
+		//line sql.y:1372
 		{
 			yyVAL.valExpr = &BinaryExpr{Left: yyDollar[1].valExpr, Operator: ShiftRightStr, Right: yyDollar[3].valExpr}
 		}
-	case 143:
+	case 246:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line sql.y:804
+		//line sql.y:1376
 		{
 			if num, ok := yyDollar[2].valExpr.(NumVal); ok {
 				yyVAL.valExpr = num
@@ -1754,9 +2615,9 @@ yydefault:
 				yyVAL.valExpr = &UnaryExpr{Operator: UPlusStr, Expr: yyDollar[2].valExpr}
 			}
 		}
-	case 144:
+	case 247:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line sql.y:812
+		//line sql.y:1384
 		{
 			if num, ok := yyDollar[2].valExpr.(NumVal); ok {
 				// Handle double negative
@@ -1769,15 +2630,15 @@ yydefault:
 				yyVAL.valExpr = &UnaryExpr{Operator: UMinusStr, Expr: yyDollar[2].valExpr}
 			}
 		}
-	case 145:
+	case 248:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line sql.y:825
+		//line sql.y:1397
 		{
 			yyVAL.valExpr = &UnaryExpr{Operator: TildaStr, Expr: yyDollar[2].valExpr}
 		}
-	case 146:
+	case 249:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line sql.y:829
+		//line sql.y:1401
 		{
 			// This rule prevents the usage of INTERVAL
 			// as a function. If support is needed for that,
@@ -1785,231 +2646,231 @@ yydefault:
 			// will be non-trivial because of grammar conflicts.
 			yyVAL.valExpr = &IntervalExpr{Expr: yyDollar[2].valExpr, Unit: yyDollar[3].colIdent}
 		}
-	case 147:
+	case 250:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line sql.y:837
+		//line sql.y:1409
 		{
 			yyVAL.valExpr = &FuncExpr{Name: string(yyDollar[1].tableIdent)}
 		}
-	case 148:
+	case 251:
 		yyDollar = yyS[yypt-4 : yypt+1]
-		//line sql.y:841
+		//line sql.y:1413
 		{
 			yyVAL.valExpr = &FuncExpr{Name: string(yyDollar[1].tableIdent), Exprs: yyDollar[3].selectExprs}
 		}
-	case 149:
+	case 252:
 		yyDollar = yyS[yypt-5 : yypt+1]
-		//line sql.y:845
+		//line sql.y:1417
 		{
 			yyVAL.valExpr = &FuncExpr{Name: string(yyDollar[1].tableIdent), Distinct: true, Exprs: yyDollar[4].selectExprs}
 		}
-	case 150:
+	case 253:
 		yyDollar = yyS[yypt-4 : yypt+1]
-		//line sql.y:849
+		//line sql.y:1421
 		{
 			yyVAL.valExpr = &FuncExpr{Name: "if", Exprs: yyDollar[3].selectExprs}
 		}
-	case 151:
+	case 254:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line sql.y:853
+		//line sql.y:1425
 		{
 			yyVAL.valExpr = yyDollar[1].caseExpr
 		}
-	case 152:
+	case 255:
 		yyDollar = yyS[yypt-5 : yypt+1]
-		//line sql.y:859
+		//line sql.y:1431
 		{
 			yyVAL.caseExpr = &CaseExpr{Expr: yyDollar[2].valExpr, Whens: yyDollar[3].whens, Else: yyDollar[4].valExpr}
 		}
-	case 153:
+	case 256:
 		yyDollar = yyS[yypt-0 : yypt+1]
-		//line sql.y:864
+		//line sql.y:1436
 		{
 			yyVAL.valExpr = nil
 		}
-	case 154:
+	case 257:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line sql.y:868
+		//line sql.y:1440
 		{
 			yyVAL.valExpr = yyDollar[1].valExpr
 		}
-	case 155:
+	case 258:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line sql.y:874
+		//line sql.y:1446
 		{
 			yyVAL.whens = []*When{yyDollar[1].when}
 		}
-	case 156:
+	case 259:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line sql.y:878
+		//line sql.y:1450
 		{
 			yyVAL.whens = append(yyDollar[1].whens, yyDollar[2].when)
 		}
-	case 157:
+	case 260:
 		yyDollar = yyS[yypt-4 : yypt+1]
-		//line sql.y:884
+		//line sql.y:1456
 		{
 			yyVAL.when = &When{Cond: yyDollar[2].boolExpr, Val: yyDollar[4].valExpr}
 		}
-	case 158:
+	case 261:
 		yyDollar = yyS[yypt-0 : yypt+1]
-		//line sql.y:889
+		//line sql.y:1461
 		{
 			yyVAL.valExpr = nil
 		}
-	case 159:
+	case 262:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line sql.y:893
+		//line sql.y:1465
 		{
 			yyVAL.valExpr = yyDollar[2].valExpr
 		}
-	case 160:
+	case 263:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line sql.y:899
+		//line sql.y:1471
 		{
 			yyVAL.colName = &ColName{Name: yyDollar[1].colIdent}
 		}
-	case 161:
+	case 264:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line sql.y:903
+		//line sql.y:1475
 		{
 			yyVAL.colName = &ColName{Qualifier: &TableName{Name: yyDollar[1].tableIdent}, Name: yyDollar[3].colIdent}
 		}
-	case 162:
+	case 265:
 		yyDollar = yyS[yypt-5 : yypt+1]
-		//line sql.y:907
+		//line sql.y:1479
 		{
 			yyVAL.colName = &ColName{Qualifier: &TableName{Qualifier: yyDollar[1].tableIdent, Name: yyDollar[3].tableIdent}, Name: yyDollar[5].colIdent}
 		}
-	case 163:
+	case 266:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line sql.y:913
+		//line sql.y:1485
 		{
 			yyVAL.valExpr = StrVal(yyDollar[1].bytes)
 		}
-	case 164:
+	case 267:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line sql.y:917
+		//line sql.y:1489
 		{
 			yyVAL.valExpr = NumVal(yyDollar[1].bytes)
 		}
-	case 165:
+	case 268:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line sql.y:921
+		//line sql.y:1493
 		{
 			yyVAL.valExpr = ValArg(yyDollar[1].bytes)
 		}
-	case 166:
+	case 269:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line sql.y:925
+		//line sql.y:1497
 		{
 			yyVAL.valExpr = &NullVal{}
 		}
-	case 167:
+	case 270:
 		yyDollar = yyS[yypt-0 : yypt+1]
-		//line sql.y:930
+		//line sql.y:1502
 		{
 			yyVAL.valExprs = nil
 		}
-	case 168:
+	case 271:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line sql.y:934
+		//line sql.y:1506
 		{
 			yyVAL.valExprs = yyDollar[3].valExprs
 		}
-	case 169:
+	case 272:
 		yyDollar = yyS[yypt-0 : yypt+1]
-		//line sql.y:939
+		//line sql.y:1511
 		{
 			yyVAL.boolExpr = nil
 		}
-	case 170:
+	case 273:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line sql.y:943
+		//line sql.y:1515
 		{
 			yyVAL.boolExpr = yyDollar[2].boolExpr
 		}
-	case 171:
+	case 274:
 		yyDollar = yyS[yypt-0 : yypt+1]
-		//line sql.y:948
+		//line sql.y:1520
 		{
 			yyVAL.orderBy = nil
 		}
-	case 172:
+	case 275:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line sql.y:952
+		//line sql.y:1524
 		{
 			yyVAL.orderBy = yyDollar[3].orderBy
 		}
-	case 173:
+	case 276:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line sql.y:958
+		//line sql.y:1530
 		{
 			yyVAL.orderBy = OrderBy{yyDollar[1].order}
 		}
-	case 174:
+	case 277:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line sql.y:962
+		//line sql.y:1534
 		{
 			yyVAL.orderBy = append(yyDollar[1].orderBy, yyDollar[3].order)
 		}
-	case 175:
+	case 278:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line sql.y:968
+		//line sql.y:1540
 		{
 			yyVAL.order = &Order{Expr: yyDollar[1].valExpr, Direction: yyDollar[2].str}
 		}
-	case 176:
+	case 279:
 		yyDollar = yyS[yypt-0 : yypt+1]
-		//line sql.y:973
+		//line sql.y:1545
 		{
 			yyVAL.str = AscScr
 		}
-	case 177:
+	case 280:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line sql.y:977
+		//line sql.y:1549
 		{
 			yyVAL.str = AscScr
 		}
-	case 178:
+	case 281:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line sql.y:981
+		//line sql.y:1553
 		{
 			yyVAL.str = DescScr
 		}
-	case 179:
+	case 282:
 		yyDollar = yyS[yypt-0 : yypt+1]
-		//line sql.y:986
+		//line sql.y:1558
 		{
 			yyVAL.limit = nil
 		}
-	case 180:
+	case 283:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line sql.y:990
+		//line sql.y:1562
 		{
 			yyVAL.limit = &Limit{Rowcount: yyDollar[2].valExpr}
 		}
-	case 181:
+	case 284:
 		yyDollar = yyS[yypt-4 : yypt+1]
-		//line sql.y:994
+		//line sql.y:1566
 		{
 			yyVAL.limit = &Limit{Offset: yyDollar[2].valExpr, Rowcount: yyDollar[4].valExpr}
 		}
-	case 182:
+	case 285:
 		yyDollar = yyS[yypt-0 : yypt+1]
-		//line sql.y:999
+		//line sql.y:1571
 		{
 			yyVAL.str = ""
 		}
-	case 183:
+	case 286:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line sql.y:1003
+		//line sql.y:1575
 		{
 			yyVAL.str = ForUpdateStr
 		}
-	case 184:
+	case 287:
 		yyDollar = yyS[yypt-4 : yypt+1]
-		//line sql.y:1007
+		//line sql.y:1579
 		{
 			if yyDollar[3].colIdent.Lowered() != "share" {
 				yylex.Error("expecting share")
@@ -2021,237 +2882,243 @@ yydefault:
 			}
 			yyVAL.str = ShareModeStr
 		}
-	case 185:
+	case 288:
 		yyDollar = yyS[yypt-0 : yypt+1]
-		//line sql.y:1020
+		//line sql.y:1592
 		{
 			yyVAL.columns = nil
 		}
-	case 186:
+	case 289:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line sql.y:1024
+		//line sql.y:1596
 		{
 			yyVAL.columns = yyDollar[2].columns
 		}
-	case 187:
+	case 290:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line sql.y:1030
+		//line sql.y:1602
 		{
 			yyVAL.columns = Columns{yyDollar[1].colIdent}
 		}
-	case 188:
+	case 291:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line sql.y:1034
+		//line sql.y:1606
 		{
 			yyVAL.columns = append(yyVAL.columns, yyDollar[3].colIdent)
 		}
-	case 189:
+	case 292:
 		yyDollar = yyS[yypt-0 : yypt+1]
-		//line sql.y:1039
+		//line sql.y:1611
 		{
 			yyVAL.updateExprs = nil
 		}
-	case 190:
+	case 293:
 		yyDollar = yyS[yypt-5 : yypt+1]
-		//line sql.y:1043
+		//line sql.y:1615
 		{
 			yyVAL.updateExprs = yyDollar[5].updateExprs
 		}
-	case 191:
+	case 294:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line sql.y:1049
+		//line sql.y:1621
 		{
 			yyVAL.insRows = yyDollar[2].values
 		}
-	case 192:
+	case 295:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line sql.y:1053
+		//line sql.y:1625
 		{
 			yyVAL.insRows = yyDollar[1].selStmt
 		}
-	case 193:
+	case 296:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line sql.y:1059
+		//line sql.y:1631
 		{
 			yyVAL.values = Values{yyDollar[1].rowTuple}
 		}
-	case 194:
+	case 297:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line sql.y:1063
+		//line sql.y:1635
 		{
 			yyVAL.values = append(yyDollar[1].values, yyDollar[3].rowTuple)
 		}
-	case 195:
+	case 298:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line sql.y:1069
+		//line sql.y:1641
 		{
 			yyVAL.rowTuple = ValTuple(yyDollar[2].valExprs)
 		}
-	case 196:
+	case 299:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line sql.y:1073
+		//line sql.y:1645
 		{
 			yyVAL.rowTuple = yyDollar[1].subquery
 		}
-	case 197:
+	case 300:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line sql.y:1079
+		//line sql.y:1651
 		{
 			yyVAL.updateExprs = UpdateExprs{yyDollar[1].updateExpr}
 		}
-	case 198:
+	case 301:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line sql.y:1083
+		//line sql.y:1655
 		{
 			yyVAL.updateExprs = append(yyDollar[1].updateExprs, yyDollar[3].updateExpr)
 		}
-	case 199:
+	case 302:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line sql.y:1089
+		//line sql.y:1661
 		{
 			yyVAL.updateExpr = &UpdateExpr{Name: yyDollar[1].colIdent, Expr: yyDollar[3].valExpr}
 		}
-	case 202:
+	case 305:
 		yyDollar = yyS[yypt-0 : yypt+1]
-		//line sql.y:1098
+		//line sql.y:1670
 		{
 			yyVAL.byt = 0
 		}
-	case 203:
+	case 306:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line sql.y:1100
+		//line sql.y:1672
 		{
 			yyVAL.byt = 1
 		}
-	case 204:
+	case 307:
 		yyDollar = yyS[yypt-0 : yypt+1]
-		//line sql.y:1103
+		//line sql.y:1675
 		{
-			yyVAL.empty = struct{}{}
+			yyVAL.byt = 0
 		}
-	case 205:
+	case 308:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line sql.y:1105
+		//line sql.y:1677
 		{
-			yyVAL.empty = struct{}{}
+			yyVAL.byt = 1
 		}
-	case 206:
+	case 309:
 		yyDollar = yyS[yypt-0 : yypt+1]
-		//line sql.y:1108
+		//line sql.y:1680
 		{
 			yyVAL.str = ""
 		}
-	case 207:
+	case 310:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line sql.y:1110
+		//line sql.y:1682
 		{
 			yyVAL.str = IgnoreStr
 		}
-	case 208:
-		yyDollar = yyS[yypt-1 : yypt+1]
-		//line sql.y:1114
+	case 311:
+		yyDollar = yyS[yypt-0 : yypt+1]
+		//line sql.y:1685
 		{
 			yyVAL.empty = struct{}{}
 		}
-	case 209:
+	case 312:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line sql.y:1116
+		//line sql.y:1687
 		{
 			yyVAL.empty = struct{}{}
 		}
-	case 210:
-		yyDollar = yyS[yypt-1 : yypt+1]
-		//line sql.y:1118
+	case 313:
+		yyDollar = yyS[yypt-0 : yypt+1]
+		//line sql.y:1690
 		{
 			yyVAL.empty = struct{}{}
 		}
-	case 211:
+	case 314:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line sql.y:1120
+		//line sql.y:1692
 		{
 			yyVAL.empty = struct{}{}
 		}
-	case 212:
-		yyDollar = yyS[yypt-1 : yypt+1]
-		//line sql.y:1122
+	case 315:
+		yyDollar = yyS[yypt-0 : yypt+1]
+		//line sql.y:1695
 		{
 			yyVAL.empty = struct{}{}
 		}
-	case 213:
-		yyDollar = yyS[yypt-1 : yypt+1]
-		//line sql.y:1124
+	case 316:
+		yyDollar = yyS[yypt-2 : yypt+1]
+		//line sql.y:1697
 		{
 			yyVAL.empty = struct{}{}
 		}
-	case 214:
+	case 317:
 		yyDollar = yyS[yypt-0 : yypt+1]
-		//line sql.y:1127
+		//line sql.y:1700
 		{
 			yyVAL.empty = struct{}{}
 		}
-	case 215:
+	case 318:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line sql.y:1129
+		//line sql.y:1702
 		{
 			yyVAL.empty = struct{}{}
 		}
-	case 216:
+	case 319:
 		yyDollar = yyS[yypt-0 : yypt+1]
-		//line sql.y:1132
+		//line sql.y:1705
 		{
 			yyVAL.empty = struct{}{}
 		}
-	case 217:
+	case 320:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line sql.y:1134
+		//line sql.y:1707
 		{
 			yyVAL.empty = struct{}{}
 		}
-	case 218:
-		yyDollar = yyS[yypt-0 : yypt+1]
-		//line sql.y:1137
+	case 321:
+		yyDollar = yyS[yypt-1 : yypt+1]
+		//line sql.y:1711
 		{
-			yyVAL.empty = struct{}{}
+			yyVAL.colIdent = NewColIdent(string(yyDollar[1].bytes))
 		}
-	case 219:
-		yyDollar = yyS[yypt-2 : yypt+1]
-		//line sql.y:1139
+	case 322:
+		yyDollar = yyS[yypt-1 : yypt+1]
+		//line sql.y:1715
 		{
-			yyVAL.empty = struct{}{}
+			yyVAL.colIdent = NewColIdent(string(yyDollar[1].bytes))
 		}
-	case 220:
+	case 323:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line sql.y:1143
+		//line sql.y:1721
 		{
-			yyVAL.colIdent = NewColIdent(string(yyDollar[1].bytes))
+			yyVAL.tableIdent = TableIdent(yyDollar[1].bytes)
 		}
-	case 221:
+	case 324:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line sql.y:1149
+		//line sql.y:1725
 		{
 			yyVAL.tableIdent = TableIdent(yyDollar[1].bytes)
 		}
-	case 222:
+	case 334:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line sql.y:1155
+		//line sql.y:1742
 		{
 			if incNesting(yylex) {
 				yylex.Error("max nesting level reached")
 				return 1
 			}
 		}
-	case 223:
+	case 335:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line sql.y:1164
+		//line sql.y:1751
 		{
 			decNesting(yylex)
 		}
-	case 224:
+	case 336:
 		yyDollar = yyS[yypt-0 : yypt+1]
-		//line sql.y:1169
+		//line sql.y:1756
 		{
 			forceEOF(yylex)
 		}
+	case 337:
+		yyDollar = yyS[yypt-0 : yypt+1]
+		//line sql.y:1761
+		{
+			forceTarget(yylex)
+		}
 	}
 	goto yystack /* stack new state and value */
 }
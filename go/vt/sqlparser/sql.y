@@ -5,10 +5,16 @@
 %{
 package sqlparser
 
+import "strings"
+
 func setParseTree(yylex interface{}, stmt Statement) {
   yylex.(*Tokenizer).ParseTree = stmt
 }
 
+func setPartialStatement(yylex interface{}, stmt Statement) {
+  yylex.(*Tokenizer).partialStatement = stmt
+}
+
 func setAllowComments(yylex interface{}, allow bool) {
   yylex.(*Tokenizer).AllowComments = allow
 }
@@ -29,6 +35,10 @@ func forceEOF(yylex interface{}) {
   yylex.(*Tokenizer).ForceEOF = true
 }
 
+func forceTarget(yylex interface{}) {
+  yylex.(*Tokenizer).forceTarget = true
+}
+
 %}
 
 %union {
@@ -67,6 +77,19 @@ func forceEOF(yylex interface{}) {
   colIdent    ColIdent
   colIdents   []ColIdent
   tableIdent  TableIdent
+  strs        []string
+  ddl         *DDL
+  tableSpec   *TableSpec
+  columnDefinition *ColumnDefinition
+  columnType  ColumnType
+  indexDefinition *IndexDefinition
+  indexInfo   *IndexInfo
+  indexColumn *IndexColumn
+  indexColumns []*IndexColumn
+  tableOptions TableOptions
+  tableOption *TableOption
+  alterSpecs  AlterSpecs
+  alterSpec   *AlterSpec
 }
 
 %token LEX_ERROR
@@ -104,7 +127,14 @@ func forceEOF(yylex interface{}) {
 // DDL Tokens
 %token <empty> CREATE ALTER DROP RENAME ANALYZE
 %token <empty> TABLE INDEX VIEW TO IGNORE IF UNIQUE USING
-%token <empty> SHOW DESCRIBE EXPLAIN
+%token <empty> SHOW DESCRIBE EXPLAIN DATABASES
+%token <empty> ADD COLUMN CHANGE PRIMARY FULLTEXT SPATIAL
+%token <empty> UNSIGNED ZEROFILL CHARACTER COLLATE CURRENT_TIMESTAMP
+%token <bytes> DATA_TYPE
+
+// Non-reserved keywords: they can also be used as identifiers.
+%token <bytes> AFTER AUTO_INCREMENT COMMENT_KEYWORD FIRST
+%token <bytes> BEGIN COMMIT ROLLBACK START TRANSACTION
 
 // MySQL reserved words that are unused by this grammar will map to this token.
 %token <empty> UNUSED
@@ -113,7 +143,8 @@ func forceEOF(yylex interface{}) {
 %type <selStmt> select_statement
 %type <statement> insert_statement update_statement delete_statement set_statement
 %type <statement> create_statement alter_statement rename_statement drop_statement
-%type <statement> analyze_statement other_statement
+%type <statement> analyze_statement show_statement use_statement other_statement
+%type <statement> begin_statement commit_statement rollback_statement
 %type <bytes2> comment_opt comment_list
 %type <str> union_op
 %type <str> distinct_opt straight_join_opt
@@ -155,12 +186,29 @@ func forceEOF(yylex interface{}) {
 %type <updateExpr> update_expression
 %type <empty> for_from
 %type <str> ignore_opt
-%type <byt> exists_opt
-%type <empty> not_exists_opt non_rename_operation to_opt constraint_opt using_opt
+%type <byt> exists_opt not_exists_opt
+%type <empty> to_opt constraint_opt using_opt column_opt equal_opt
+%type <ddl> create_table_prefix alter_table_prefix
+%type <tableSpec> table_spec table_element_list
+%type <columnDefinition> column_definition
+%type <columnType> column_type data_type
+%type <str> type_name charset_name index_or_key index_or_key_opt
+%type <strs> enum_value_list
+%type <valExpr> default_value current_timestamp
+%type <indexDefinition> index_definition
+%type <indexInfo> index_info
+%type <indexColumns> index_column_list
+%type <indexColumn> index_column
+%type <colIdent> name_opt index_using_opt
+%type <tableOptions> table_option_list_opt table_option_list
+%type <tableOption> table_option table_option_value
+%type <alterSpecs> alter_spec_list
+%type <alterSpec> alter_spec column_position_opt
+%type <bytes> non_reserved_keyword
 %type <colIdent> sql_id as_ci_opt
 %type <tableIdent> table_id as_opt_id
 %type <empty> as_opt
-%type <empty> force_eof
+%type <empty> force_eof force_target
 
 %start any_command
 
@@ -186,6 +234,11 @@ command:
 | rename_statement
 | drop_statement
 | analyze_statement
+| show_statement
+| use_statement
+| begin_statement
+| commit_statement
+| rollback_statement
 | other_statement
 
 select_statement:
@@ -241,14 +294,20 @@ set_statement:
   }
 
 create_statement:
-  CREATE TABLE not_exists_opt table_id force_eof
+  create_table_prefix
   {
-    $$ = &DDL{Action: CreateStr, NewName: $4}
+    $$ = $1
+  }
+| create_table_prefix table_spec
+  {
+    $1.TableSpec = $2
+    $$ = $1
   }
 | CREATE constraint_opt INDEX ID using_opt ON table_id force_eof
   {
-    // Change this to an alter statement
-    $$ = &DDL{Action: AlterStr, Table: $7, NewName: $7}
+    // Change this to an alter statement. It's partial, since
+    // the index is dropped.
+    $$ = &DDL{Action: AlterStr, Table: $7, NewName: $7, Partial: true}
   }
 | CREATE VIEW sql_id force_eof
   {
@@ -256,20 +315,470 @@ create_statement:
   }
 
 alter_statement:
-  ALTER ignore_opt TABLE table_id non_rename_operation force_eof
+  alter_table_prefix alter_spec_list
   {
-    $$ = &DDL{Action: AlterStr, Table: $4, NewName: $4}
+    $1.AlterSpecs = $2
+    $$ = $1
   }
-| ALTER ignore_opt TABLE table_id RENAME to_opt table_id
+| alter_table_prefix RENAME to_opt table_id
   {
     // Change this to a rename statement
-    $$ = &DDL{Action: RenameStr, Table: $4, NewName: $7}
+    $$ = &DDL{Action: RenameStr, Qualifier: $1.Qualifier, Table: $1.Table, NewName: $4}
   }
 | ALTER VIEW sql_id force_eof
   {
     $$ = &DDL{Action: AlterStr, Table: TableIdent($3.Lowered()), NewName: TableIdent($3.Lowered())}
   }
 
+// The prefixes of CREATE TABLE and ALTER TABLE are saved: if the rest
+// of the statement can't be parsed, Parse returns them, marked Partial.
+create_table_prefix:
+  CREATE TABLE not_exists_opt table_name
+  {
+    var notExists bool
+    if $3 != 0 {
+      notExists = true
+    }
+    $$ = &DDL{Action: CreateStr, Qualifier: $4.Qualifier, NewName: $4.Name, IfNotExists: notExists}
+    setPartialStatement(yylex, $$)
+  }
+
+alter_table_prefix:
+  ALTER ignore_opt TABLE table_name
+  {
+    $$ = &DDL{Action: AlterStr, Qualifier: $4.Qualifier, Table: $4.Name, NewName: $4.Name}
+    setPartialStatement(yylex, $$)
+  }
+
+table_spec:
+  openb table_element_list closeb table_option_list_opt
+  {
+    $$ = $2
+    $$.Options = $4
+  }
+
+table_element_list:
+  column_definition
+  {
+    $$ = &TableSpec{Columns: []*ColumnDefinition{$1}}
+  }
+| index_definition
+  {
+    $$ = &TableSpec{Indexes: []*IndexDefinition{$1}}
+  }
+| table_element_list ',' column_definition
+  {
+    $$ = $1
+    $$.Columns = append($$.Columns, $3)
+  }
+| table_element_list ',' index_definition
+  {
+    $$ = $1
+    $$.Indexes = append($$.Indexes, $3)
+  }
+
+column_definition:
+  sql_id column_type
+  {
+    $$ = &ColumnDefinition{Name: $1, Type: $2}
+  }
+
+// The column attributes can be in any order.
+column_type:
+  data_type
+  {
+    $$ = $1
+  }
+| column_type UNSIGNED
+  {
+    $$ = $1
+    $$.Unsigned = true
+  }
+| column_type ZEROFILL
+  {
+    $$ = $1
+    $$.Zerofill = true
+  }
+| column_type CHARACTER SET charset_name
+  {
+    $$ = $1
+    $$.Charset = $4
+  }
+| column_type COLLATE charset_name
+  {
+    $$ = $1
+    $$.Collate = $3
+  }
+| column_type NULL
+  {
+    $$ = $1
+    $$.Null = NullStr
+  }
+| column_type NOT NULL
+  {
+    $$ = $1
+    $$.Null = NotNullStr
+  }
+| column_type DEFAULT default_value
+  {
+    $$ = $1
+    $$.Default = $3
+  }
+| column_type ON UPDATE current_timestamp
+  {
+    $$ = $1
+    $$.OnUpdate = $4
+  }
+| column_type AUTO_INCREMENT
+  {
+    $$ = $1
+    $$.Autoincrement = true
+  }
+| column_type PRIMARY KEY
+  {
+    $$ = $1
+    $$.KeyOpt = ColKeyPrimaryStr
+  }
+| column_type UNIQUE
+  {
+    $$ = $1
+    $$.KeyOpt = ColKeyUniqueStr
+  }
+| column_type UNIQUE KEY
+  {
+    $$ = $1
+    $$.KeyOpt = ColKeyUniqueKeyStr
+  }
+| column_type COMMENT_KEYWORD STRING
+  {
+    $$ = $1
+    $$.Comment = StrVal($3)
+  }
+
+data_type:
+  type_name
+  {
+    $$ = ColumnType{Type: $1}
+  }
+| type_name openb NUMBER closeb
+  {
+    $$ = ColumnType{Type: $1, Length: NumVal($3)}
+  }
+| type_name openb NUMBER ',' NUMBER closeb
+  {
+    $$ = ColumnType{Type: $1, Length: NumVal($3), Scale: NumVal($5)}
+  }
+| type_name openb enum_value_list closeb
+  {
+    $$ = ColumnType{Type: $1, EnumValues: $3}
+  }
+| SET openb enum_value_list closeb
+  {
+    $$ = ColumnType{Type: "set", EnumValues: $3}
+  }
+
+// Only some of the type names are reserved words.
+type_name:
+  ID
+  {
+    $$ = strings.ToLower(string($1))
+  }
+| DATA_TYPE
+  {
+    $$ = string($1)
+  }
+
+enum_value_list:
+  STRING
+  {
+    $$ = []string{string($1)}
+  }
+| enum_value_list ',' STRING
+  {
+    $$ = append($1, string($3))
+  }
+
+// binary is a reserved word, but also a character set.
+charset_name:
+  ID
+  {
+    $$ = string($1)
+  }
+| DATA_TYPE
+  {
+    $$ = string($1)
+  }
+
+default_value:
+  STRING
+  {
+    $$ = StrVal($1)
+  }
+| NUMBER
+  {
+    $$ = NumVal($1)
+  }
+| '-' NUMBER
+  {
+    $$ = append(NumVal("-"), $2...)
+  }
+| NULL
+  {
+    $$ = &NullVal{}
+  }
+| current_timestamp
+  {
+    $$ = $1
+  }
+
+current_timestamp:
+  CURRENT_TIMESTAMP
+  {
+    $$ = &FuncExpr{Name: "current_timestamp"}
+  }
+| CURRENT_TIMESTAMP openb closeb
+  {
+    $$ = &FuncExpr{Name: "current_timestamp"}
+  }
+| CURRENT_TIMESTAMP openb NUMBER closeb
+  {
+    $$ = &FuncExpr{Name: "current_timestamp", Exprs: SelectExprs{&NonStarExpr{Expr: NumVal($3)}}}
+  }
+
+index_definition:
+  index_info openb index_column_list closeb index_using_opt
+  {
+    $$ = &IndexDefinition{Info: $1, Columns: $3, Using: $5}
+  }
+
+index_info:
+  PRIMARY KEY
+  {
+    $$ = &IndexInfo{Type: "primary key", Name: NewColIdent("PRIMARY"), Primary: true, Unique: true}
+  }
+| UNIQUE index_or_key_opt name_opt
+  {
+    $$ = &IndexInfo{Type: "unique" + $2, Name: $3, Unique: true}
+  }
+| index_or_key name_opt
+  {
+    $$ = &IndexInfo{Type: $1, Name: $2}
+  }
+| FULLTEXT index_or_key_opt name_opt
+  {
+    $$ = &IndexInfo{Type: "fulltext" + $2, Name: $3}
+  }
+| SPATIAL index_or_key_opt name_opt
+  {
+    $$ = &IndexInfo{Type: "spatial" + $2, Name: $3}
+  }
+
+index_or_key:
+  INDEX
+  {
+    $$ = "index"
+  }
+| KEY
+  {
+    $$ = "key"
+  }
+
+index_or_key_opt:
+  {
+    $$ = ""
+  }
+| index_or_key
+  {
+    $$ = " " + $1
+  }
+
+name_opt:
+  {
+    $$ = ColIdent{}
+  }
+| sql_id
+  {
+    $$ = $1
+  }
+
+index_column_list:
+  index_column
+  {
+    $$ = []*IndexColumn{$1}
+  }
+| index_column_list ',' index_column
+  {
+    $$ = append($1, $3)
+  }
+
+index_column:
+  sql_id
+  {
+    $$ = &IndexColumn{Column: $1}
+  }
+| sql_id openb NUMBER closeb
+  {
+    $$ = &IndexColumn{Column: $1, Length: NumVal($3)}
+  }
+
+index_using_opt:
+  {
+    $$ = ColIdent{}
+  }
+| USING sql_id
+  {
+    $$ = $2
+  }
+
+table_option_list_opt:
+  {
+    $$ = nil
+  }
+| table_option_list
+  {
+    $$ = $1
+  }
+
+table_option_list:
+  table_option
+  {
+    $$ = TableOptions{$1}
+  }
+| table_option_list table_option
+  {
+    $$ = append($1, $2)
+  }
+
+// The '=' is only optional for the options that start with
+// a keyword, to tell them apart from the other alter operations.
+table_option:
+  ID '=' table_option_value
+  {
+    $$ = $3
+    $$.Name = strings.ToLower(string($1))
+  }
+| DEFAULT ID equal_opt table_option_value
+  {
+    $$ = $4
+    $$.Name = "default " + strings.ToLower(string($2))
+  }
+| DEFAULT CHARACTER SET equal_opt table_option_value
+  {
+    $$ = $5
+    $$.Name = "default character set"
+  }
+| DEFAULT COLLATE equal_opt table_option_value
+  {
+    $$ = $4
+    $$.Name = "default collate"
+  }
+| CHARACTER SET equal_opt table_option_value
+  {
+    $$ = $4
+    $$.Name = "character set"
+  }
+| COLLATE equal_opt table_option_value
+  {
+    $$ = $3
+    $$.Name = "collate"
+  }
+| AUTO_INCREMENT equal_opt table_option_value
+  {
+    $$ = $3
+    $$.Name = "auto_increment"
+  }
+| COMMENT_KEYWORD equal_opt table_option_value
+  {
+    $$ = $3
+    $$.Name = "comment"
+  }
+
+table_option_value:
+  ID
+  {
+    $$ = &TableOption{String: string($1)}
+  }
+| DATA_TYPE
+  {
+    $$ = &TableOption{String: string($1)}
+  }
+| STRING
+  {
+    $$ = &TableOption{Value: StrVal($1)}
+  }
+| NUMBER
+  {
+    $$ = &TableOption{Value: NumVal($1)}
+  }
+
+alter_spec_list:
+  alter_spec
+  {
+    $$ = AlterSpecs{$1}
+  }
+| alter_spec_list ',' alter_spec
+  {
+    $$ = append($1, $3)
+  }
+
+alter_spec:
+  ADD column_opt column_definition column_position_opt
+  {
+    $$ = $4
+    $$.Action = AddColumnStr
+    $$.Column = $3
+  }
+| ADD index_definition
+  {
+    $$ = &AlterSpec{Action: AddIndexStr, Index: $2}
+  }
+| DROP column_opt sql_id
+  {
+    $$ = &AlterSpec{Action: DropColumnStr, Name: $3}
+  }
+| DROP index_or_key sql_id
+  {
+    $$ = &AlterSpec{Action: DropIndexStr, Name: $3}
+  }
+| DROP PRIMARY KEY
+  {
+    $$ = &AlterSpec{Action: DropPrimaryKeyStr}
+  }
+| ID column_opt column_definition column_position_opt
+  {
+    if strings.ToLower(string($1)) != "modify" {
+      yylex.Error("expecting modify")
+      return 1
+    }
+    $$ = $4
+    $$.Action = ModifyColumnStr
+    $$.Column = $3
+  }
+| CHANGE column_opt sql_id column_definition column_position_opt
+  {
+    $$ = $5
+    $$.Action = ChangeColumnStr
+    $$.Name = $3
+    $$.Column = $4
+  }
+| table_option_list
+  {
+    $$ = &AlterSpec{Action: TableOptionsStr, Options: $1}
+  }
+
+column_position_opt:
+  {
+    $$ = &AlterSpec{}
+  }
+| FIRST
+  {
+    $$ = &AlterSpec{First: true}
+  }
+| AFTER sql_id
+  {
+    $$ = &AlterSpec{After: $2}
+  }
+
 rename_statement:
   RENAME TABLE table_id TO table_id
   {
@@ -287,8 +796,9 @@ drop_statement:
   }
 | DROP INDEX ID ON table_id
   {
-    // Change this to an alter statement
-    $$ = &DDL{Action: AlterStr, Table: $5, NewName: $5}
+    // Change this to an alter statement. It's partial, since
+    // the index is dropped.
+    $$ = &DDL{Action: AlterStr, Table: $5, NewName: $5, Partial: true}
   }
 | DROP VIEW exists_opt sql_id force_eof
   {
@@ -305,12 +815,74 @@ analyze_statement:
     $$ = &DDL{Action: AlterStr, Table: $3, NewName: $3}
   }
 
-other_statement:
-  SHOW force_eof
+// The prefix is saved: if the rest of the statement
+// can't be parsed, Parse returns an unsupported SHOW.
+show_statement:
+  show_prefix DATABASES
   {
-    $$ = &Other{}
+    $$ = &Show{Type: ShowDatabasesStr}
+  }
+| show_prefix ID
+  {
+    switch strings.ToLower(string($2)) {
+    case ShowTablesStr:
+      $$ = &Show{Type: ShowTablesStr}
+    case ShowVitessShardsStr:
+      $$ = &Show{Type: ShowVitessShardsStr}
+    default:
+      $$ = &Show{Type: ShowUnsupportedStr}
+    }
+  }
+| show_prefix ID ID
+  {
+    if strings.ToLower(string($2)) == "vschema" && strings.ToLower(string($3)) == "tables" {
+      $$ = &Show{Type: ShowVSchemaTablesStr}
+    } else {
+      $$ = &Show{Type: ShowUnsupportedStr}
+    }
+  }
+
+show_prefix:
+  SHOW
+  {
+    setPartialStatement(yylex, &Show{Type: ShowUnsupportedStr})
+  }
+
+// The target of a USE is scanned as a whole.
+use_statement:
+  USE force_target
+  {
+    $$ = &Use{}
+  }
+| USE force_target ID
+  {
+    $$ = NewUse(string($3))
   }
-| DESCRIBE force_eof
+
+begin_statement:
+  BEGIN
+  {
+    $$ = &Begin{}
+  }
+| START TRANSACTION
+  {
+    $$ = &Begin{}
+  }
+
+commit_statement:
+  COMMIT
+  {
+    $$ = &Commit{}
+  }
+
+rollback_statement:
+  ROLLBACK
+  {
+    $$ = &Rollback{}
+  }
+
+other_statement:
+  DESCRIBE force_eof
   {
     $$ = &Other{}
   }
@@ -1100,29 +1672,15 @@ exists_opt:
   { $$ = 1 }
 
 not_exists_opt:
-  { $$ = struct{}{} }
+  { $$ = 0 }
 | IF NOT EXISTS
-  { $$ = struct{}{} }
+  { $$ = 1 }
 
 ignore_opt:
   { $$ = "" }
 | IGNORE
   { $$ = IgnoreStr }
 
-non_rename_operation:
-  ALTER
-  { $$ = struct{}{} }
-| DEFAULT
-  { $$ = struct{}{} }
-| DROP
-  { $$ = struct{}{} }
-| ORDER
-  { $$ = struct{}{} }
-| UNUSED
-  { $$ = struct{}{} }
-| ID
-  { $$ = struct{}{} }
-
 to_opt:
   { $$ = struct{}{} }
 | TO
@@ -1138,17 +1696,46 @@ using_opt:
 | USING sql_id
   { $$ = struct{}{} }
 
+column_opt:
+  { $$ = struct{}{} }
+| COLUMN
+  { $$ = struct{}{} }
+
+equal_opt:
+  { $$ = struct{}{} }
+| '='
+  { $$ = struct{}{} }
+
 sql_id:
   ID
   {
     $$ = NewColIdent(string($1))
   }
+| non_reserved_keyword
+  {
+    $$ = NewColIdent(string($1))
+  }
 
 table_id:
   ID
   {
     $$ = TableIdent($1)
   }
+| non_reserved_keyword
+  {
+    $$ = TableIdent($1)
+  }
+
+non_reserved_keyword:
+  AFTER
+| AUTO_INCREMENT
+| BEGIN
+| COMMENT_KEYWORD
+| COMMIT
+| FIRST
+| ROLLBACK
+| START
+| TRANSACTION
 
 openb:
   '('
@@ -1169,3 +1756,8 @@ force_eof:
 {
   forceEOF(yylex)
 }
+
+force_target:
+{
+  forceTarget(yylex)
+}
@@ -17,16 +17,18 @@ const eofChar = 0x100
 // Tokenizer is the struct used to generate SQL
 // tokens for the parser.
 type Tokenizer struct {
-	InStream      *strings.Reader
-	AllowComments bool
-	ForceEOF      bool
-	lastChar      uint16
-	Position      int
-	lastToken     []byte
-	LastError     string
-	posVarIndex   int
-	ParseTree     Statement
-	nesting       int
+	InStream         *strings.Reader
+	AllowComments    bool
+	ForceEOF         bool
+	lastChar         uint16
+	Position         int
+	lastToken        []byte
+	LastError        string
+	posVarIndex      int
+	ParseTree        Statement
+	partialStatement Statement
+	forceTarget      bool
+	nesting          int
 }
 
 // NewStringTokenizer creates a new Tokenizer for the
@@ -37,7 +39,8 @@ func NewStringTokenizer(sql string) *Tokenizer {
 
 var keywords = map[string]int{
 	"accessible":          UNUSED,
-	"add":                 UNUSED,
+	"add":                 ADD,
+	"after":               AFTER,
 	"all":                 ALL,
 	"alter":               ALTER,
 	"analyze":             ANALYZE,
@@ -45,22 +48,26 @@ var keywords = map[string]int{
 	"as":                  AS,
 	"asc":                 ASC,
 	"asensitive":          UNUSED,
+	"auto_increment":      AUTO_INCREMENT,
 	"before":              UNUSED,
+	"begin":               BEGIN,
 	"between":             BETWEEN,
-	"bigint":              UNUSED,
-	"binary":              UNUSED,
-	"blob":                UNUSED,
+	"bigint":              DATA_TYPE,
+	"binary":              DATA_TYPE,
+	"blob":                DATA_TYPE,
 	"both":                UNUSED,
 	"by":                  BY,
 	"call":                UNUSED,
 	"cascade":             UNUSED,
 	"case":                CASE,
-	"change":              UNUSED,
-	"char":                UNUSED,
-	"character":           UNUSED,
+	"change":              CHANGE,
+	"char":                DATA_TYPE,
+	"character":           CHARACTER,
 	"check":               UNUSED,
-	"collate":             UNUSED,
-	"column":              UNUSED,
+	"collate":             COLLATE,
+	"column":              COLUMN,
+	"comment":             COMMENT_KEYWORD,
+	"commit":              COMMIT,
 	"condition":           UNUSED,
 	"constraint":          UNUSED,
 	"continue":            UNUSED,
@@ -69,17 +76,17 @@ var keywords = map[string]int{
 	"cross":               CROSS,
 	"current_date":        UNUSED,
 	"current_time":        UNUSED,
-	"current_timestamp":   UNUSED,
+	"current_timestamp":   CURRENT_TIMESTAMP,
 	"current_user":        UNUSED,
 	"cursor":              UNUSED,
 	"database":            UNUSED,
-	"databases":           UNUSED,
+	"databases":           DATABASES,
 	"day_hour":            UNUSED,
 	"day_microsecond":     UNUSED,
 	"day_minute":          UNUSED,
 	"day_second":          UNUSED,
-	"dec":                 UNUSED,
-	"decimal":             UNUSED,
+	"dec":                 DATA_TYPE,
+	"decimal":             DATA_TYPE,
 	"declare":             UNUSED,
 	"default":             DEFAULT,
 	"delayed":             UNUSED,
@@ -90,7 +97,7 @@ var keywords = map[string]int{
 	"distinct":            DISTINCT,
 	"distinctrow":         UNUSED,
 	"div":                 UNUSED,
-	"double":              UNUSED,
+	"double":              DATA_TYPE,
 	"drop":                DROP,
 	"duplicate":           DUPLICATE,
 	"each":                UNUSED,
@@ -104,14 +111,15 @@ var keywords = map[string]int{
 	"explain":             EXPLAIN,
 	"false":               FALSE,
 	"fetch":               UNUSED,
-	"float":               UNUSED,
-	"float4":              UNUSED,
-	"float8":              UNUSED,
+	"first":               FIRST,
+	"float":               DATA_TYPE,
+	"float4":              DATA_TYPE,
+	"float8":              DATA_TYPE,
 	"for":                 FOR,
 	"force":               FORCE,
 	"foreign":             UNUSED,
 	"from":                FROM,
-	"fulltext":            UNUSED,
+	"fulltext":            FULLTEXT,
 	"generated":           UNUSED,
 	"get":                 UNUSED,
 	"grant":               UNUSED,
@@ -126,17 +134,17 @@ var keywords = map[string]int{
 	"in":                  IN,
 	"index":               INDEX,
 	"infile":              UNUSED,
-	"inout":               UNUSED,
 	"inner":               INNER,
+	"inout":               UNUSED,
 	"insensitive":         UNUSED,
 	"insert":              INSERT,
-	"int":                 UNUSED,
-	"int1":                UNUSED,
-	"int2":                UNUSED,
-	"int3":                UNUSED,
-	"int4":                UNUSED,
-	"int8":                UNUSED,
-	"integer":             UNUSED,
+	"int":                 DATA_TYPE,
+	"int1":                DATA_TYPE,
+	"int2":                DATA_TYPE,
+	"int3":                DATA_TYPE,
+	"int4":                DATA_TYPE,
+	"int8":                DATA_TYPE,
+	"integer":             DATA_TYPE,
 	"interval":            INTERVAL,
 	"into":                INTO,
 	"io_after_gtids":      UNUSED,
@@ -159,27 +167,27 @@ var keywords = map[string]int{
 	"localtimestamp":      UNUSED,
 	"lock":                LOCK,
 	"long":                UNUSED,
-	"longblob":            UNUSED,
-	"longtext":            UNUSED,
+	"longblob":            DATA_TYPE,
+	"longtext":            DATA_TYPE,
 	"loop":                UNUSED,
 	"low_priority":        UNUSED,
 	"master_bind":         UNUSED,
 	"match":               UNUSED,
 	"maxvalue":            UNUSED,
-	"mediumblob":          UNUSED,
-	"mediumint":           UNUSED,
-	"mediumtext":          UNUSED,
-	"middleint":           UNUSED,
+	"mediumblob":          DATA_TYPE,
+	"mediumint":           DATA_TYPE,
+	"mediumtext":          DATA_TYPE,
+	"middleint":           DATA_TYPE,
 	"minute_microsecond":  UNUSED,
 	"minute_second":       UNUSED,
 	"mod":                 UNUSED,
 	"modifies":            UNUSED,
 	"natural":             NATURAL,
 	"next":                NEXT,
-	"not":                 NOT,
 	"no_write_to_binlog":  UNUSED,
+	"not":                 NOT,
 	"null":                NULL,
-	"numeric":             UNUSED,
+	"numeric":             DATA_TYPE,
 	"on":                  ON,
 	"optimize":            UNUSED,
 	"optimizer_costs":     UNUSED,
@@ -192,13 +200,13 @@ var keywords = map[string]int{
 	"outfile":             UNUSED,
 	"partition":           UNUSED,
 	"precision":           UNUSED,
-	"primary":             UNUSED,
+	"primary":             PRIMARY,
 	"procedure":           UNUSED,
 	"range":               UNUSED,
 	"read":                UNUSED,
-	"reads":               UNUSED,
 	"read_write":          UNUSED,
-	"real":                UNUSED,
+	"reads":               UNUSED,
+	"real":                DATA_TYPE,
 	"references":          UNUSED,
 	"regexp":              REGEXP,
 	"release":             UNUSED,
@@ -212,6 +220,7 @@ var keywords = map[string]int{
 	"revoke":              UNUSED,
 	"right":               RIGHT,
 	"rlike":               REGEXP,
+	"rollback":            ROLLBACK,
 	"schema":              UNUSED,
 	"schemas":             UNUSED,
 	"second_microsecond":  UNUSED,
@@ -221,35 +230,37 @@ var keywords = map[string]int{
 	"set":                 SET,
 	"show":                SHOW,
 	"signal":              UNUSED,
-	"smallint":            UNUSED,
-	"spatial":             UNUSED,
+	"smallint":            DATA_TYPE,
+	"spatial":             SPATIAL,
 	"specific":            UNUSED,
 	"sql":                 UNUSED,
-	"sqlexception":        UNUSED,
-	"sqlstate":            UNUSED,
-	"sqlwarning":          UNUSED,
 	"sql_big_result":      UNUSED,
 	"sql_calc_found_rows": UNUSED,
 	"sql_small_result":    UNUSED,
+	"sqlexception":        UNUSED,
+	"sqlstate":            UNUSED,
+	"sqlwarning":          UNUSED,
 	"ssl":                 UNUSED,
+	"start":               START,
 	"starting":            UNUSED,
 	"stored":              UNUSED,
 	"straight_join":       STRAIGHT_JOIN,
 	"table":               TABLE,
 	"terminated":          UNUSED,
 	"then":                THEN,
-	"tinyblob":            UNUSED,
-	"tinyint":             UNUSED,
-	"tinytext":            UNUSED,
+	"tinyblob":            DATA_TYPE,
+	"tinyint":             DATA_TYPE,
+	"tinytext":            DATA_TYPE,
 	"to":                  TO,
 	"trailing":            UNUSED,
+	"transaction":         TRANSACTION,
 	"trigger":             UNUSED,
 	"true":                TRUE,
 	"undo":                UNUSED,
 	"union":               UNION,
 	"unique":              UNIQUE,
 	"unlock":              UNUSED,
-	"unsigned":            UNUSED,
+	"unsigned":            UNSIGNED,
 	"update":              UPDATE,
 	"usage":               UNUSED,
 	"use":                 USE,
@@ -258,12 +269,12 @@ var keywords = map[string]int{
 	"utc_time":            UNUSED,
 	"utc_timestamp":       UNUSED,
 	"values":              VALUES,
-	"varbinary":           UNUSED,
-	"varchar":             UNUSED,
-	"varcharacter":        UNUSED,
+	"varbinary":           DATA_TYPE,
+	"varchar":             DATA_TYPE,
+	"varcharacter":        DATA_TYPE,
 	"varying":             UNUSED,
-	"virtual":             UNUSED,
 	"view":                VIEW,
+	"virtual":             UNUSED,
 	"when":                WHEN,
 	"where":               WHERE,
 	"while":               UNUSED,
@@ -271,7 +282,7 @@ var keywords = map[string]int{
 	"write":               UNUSED,
 	"xor":                 UNUSED,
 	"year_month":          UNUSED,
-	"zerofill":            UNUSED,
+	"zerofill":            ZEROFILL,
 }
 
 // Lex returns the next token form the Tokenizer.
@@ -285,7 +296,9 @@ func (tkn *Tokenizer) Lex(lval *yySymType) int {
 		typ, val = tkn.Scan()
 	}
 	switch typ {
-	case ID, STRING, NUMBER, VALUE_ARG, LIST_ARG, COMMENT:
+	case ID, STRING, NUMBER, VALUE_ARG, LIST_ARG, COMMENT, DATA_TYPE,
+		AFTER, AUTO_INCREMENT, COMMENT_KEYWORD, FIRST,
+		BEGIN, COMMIT, ROLLBACK, START, TRANSACTION:
 		lval.bytes = val
 	}
 	tkn.lastToken = val
@@ -309,6 +322,10 @@ func (tkn *Tokenizer) Scan() (int, []byte) {
 	if tkn.ForceEOF {
 		return 0, nil
 	}
+	if tkn.forceTarget {
+		tkn.forceTarget = false
+		return tkn.scanTarget()
+	}
 
 	if tkn.lastChar == 0 {
 		tkn.next()
@@ -428,6 +445,38 @@ func (tkn *Tokenizer) scanIdentifier() (int, []byte) {
 	return ID, buffer.Bytes()
 }
 
+// scanTarget scans the target of a USE statement, which can contain
+// characters that are not allowed in identifiers, like in
+// "ks:-80@replica". It can be back-quoted. Unquoted, it ends with
+// a blank or a ';'.
+func (tkn *Tokenizer) scanTarget() (int, []byte) {
+	if tkn.lastChar == 0 {
+		tkn.next()
+	}
+	tkn.skipBlank()
+	if tkn.lastChar == eofChar {
+		return 0, nil
+	}
+	buffer := &bytes.Buffer{}
+	if tkn.lastChar == '`' {
+		for tkn.next(); tkn.lastChar != '`'; tkn.next() {
+			if tkn.lastChar == eofChar {
+				return LEX_ERROR, buffer.Bytes()
+			}
+			buffer.WriteByte(byte(tkn.lastChar))
+		}
+		tkn.next()
+		return ID, buffer.Bytes()
+	}
+	for ; tkn.lastChar != eofChar; tkn.next() {
+		if ch := tkn.lastChar; ch == ' ' || ch == '\n' || ch == '\r' || ch == '\t' || ch == ';' {
+			break
+		}
+		buffer.WriteByte(byte(tkn.lastChar))
+	}
+	return ID, buffer.Bytes()
+}
+
 func (tkn *Tokenizer) scanLiteralIdentifier() (int, []byte) {
 	buffer := &bytes.Buffer{}
 	buffer.WriteByte(byte(tkn.lastChar))
@@ -17,6 +17,7 @@ import (
 	log "github.com/golang/glog"
 	"github.com/golang/protobuf/proto"
 	"github.com/tchap/go-patricia/patricia"
+	"github.com/youtube/vitess/go/vt/sqlparser"
 	"github.com/youtube/vitess/go/vt/tableacl/acl"
 
 	tableaclpb "github.com/youtube/vitess/go/vt/proto/tableacl"
@@ -26,12 +27,90 @@ import (
 type ACLResult struct {
 	acl.ACL
 	GroupName string
+
+	// ColumnReaders and RowFilters are only set for the READER role.
+	ColumnReaders []*ColumnReadersACL
+	RowFilters    []*RowFilterACL
+}
+
+// ColumnReadersACL embeds the acl.ACL of the entities who can read
+// the given columns of a table group.
+type ColumnReadersACL struct {
+	acl.ACL
+	// Columns contains the lower-cased column names.
+	Columns map[string]bool
+}
+
+// RowFilterACL embeds the acl.ACL of the entities who can only read
+// the rows matching the predicate.
+type RowFilterACL struct {
+	acl.ACL
+	Predicate string
+}
+
+// IsColumnReader returns true if the column grants of the user cover
+// all the given columns. A nil columns means all columns of the table,
+// which are never covered by column grants.
+func (ar *ACLResult) IsColumnReader(principal string, columns []string) bool {
+	if columns == nil {
+		return false
+	}
+	granted := make(map[string]bool)
+	for _, cr := range ar.ColumnReaders {
+		if !cr.IsMember(principal) {
+			continue
+		}
+		for column := range cr.Columns {
+			granted[column] = true
+		}
+	}
+	if len(granted) == 0 {
+		return false
+	}
+	for _, column := range columns {
+		if !granted[strings.ToLower(column)] {
+			return false
+		}
+	}
+	return true
+}
+
+// RowFilter returns the predicate restricting the rows the user
+// can read, or "" if the user can read all rows. If the user is
+// in several row filters, their predicates are and-ed.
+func (ar *ACLResult) RowFilter(principal string) string {
+	var predicates []string
+	for _, rf := range ar.RowFilters {
+		if rf.IsMember(principal) {
+			predicates = append(predicates, "("+rf.Predicate+")")
+		}
+	}
+	return strings.Join(predicates, " and ")
+}
+
+// IsRestricted returns true if the user has column grants or row
+// filters, i.e. can only read some columns or some rows of the tables.
+func (ar *ACLResult) IsRestricted(principal string) bool {
+	if ar.RowFilter(principal) != "" {
+		return true
+	}
+	if ar.IsMember(principal) {
+		return false
+	}
+	for _, cr := range ar.ColumnReaders {
+		if cr.IsMember(principal) {
+			return true
+		}
+	}
+	return false
 }
 
 type aclEntry struct {
 	tableNameOrPrefix string
 	groupName         string
 	acl               map[Role]acl.ACL
+	columnReaders     []*ColumnReadersACL
+	rowFilters        []*RowFilterACL
 }
 
 type aclEntries []aclEntry
@@ -79,7 +158,19 @@ var aclCallback func()
 //       "table_names_or_prefixes": ["name1"],
 //       "readers": ["client1"],
 //       "writers": ["client1"],
-//       "admins": ["client1"]
+//       "admins": ["client1"],
+//       "column_readers": [
+//         {
+//           "columns": ["id", "region"],
+//           "readers": ["analytics"]
+//         }
+//       ],
+//       "row_filters": [
+//         {
+//           "predicate": "region = 'US'",
+//           "readers": ["analytics"]
+//         }
+//       ]
 //     }
 //   ]
 // }
@@ -133,6 +224,26 @@ func load(config *tableaclpb.Config) error {
 		if err != nil {
 			return err
 		}
+		var columnReaders []*ColumnReadersACL
+		for _, cr := range group.ColumnReaders {
+			a, err := newACL(cr.Readers)
+			if err != nil {
+				return err
+			}
+			columns := make(map[string]bool)
+			for _, column := range cr.Columns {
+				columns[strings.ToLower(column)] = true
+			}
+			columnReaders = append(columnReaders, &ColumnReadersACL{ACL: a, Columns: columns})
+		}
+		var rowFilters []*RowFilterACL
+		for _, rf := range group.RowFilters {
+			a, err := newACL(rf.Readers)
+			if err != nil {
+				return err
+			}
+			rowFilters = append(rowFilters, &RowFilterACL{ACL: a, Predicate: rf.Predicate})
+		}
 		for _, tableNameOrPrefix := range group.TableNamesOrPrefixes {
 			entries = append(entries, aclEntry{
 				tableNameOrPrefix: tableNameOrPrefix,
@@ -142,6 +253,8 @@ func load(config *tableaclpb.Config) error {
 					WRITER: writers,
 					ADMIN:  admins,
 				},
+				columnReaders: columnReaders,
+				rowFilters:    rowFilters,
 			})
 		}
 	}
@@ -161,6 +274,16 @@ func load(config *tableaclpb.Config) error {
 func ValidateProto(config *tableaclpb.Config) (err error) {
 	t := patricia.NewTrie()
 	for _, group := range config.TableGroups {
+		for _, cr := range group.ColumnReaders {
+			if len(cr.Columns) == 0 {
+				return fmt.Errorf("group %s: column readers %v have no columns", group.Name, cr.Readers)
+			}
+		}
+		for _, rf := range group.RowFilters {
+			if _, err := sqlparser.ParseBoolExpr(rf.Predicate); err != nil {
+				return fmt.Errorf("group %s: invalid row filter %q: %v", group.Name, rf.Predicate, err)
+			}
+		}
 		for _, name := range group.TableNamesOrPrefixes {
 			var prefix patricia.Prefix
 			if strings.HasSuffix(name, "%") {
@@ -198,10 +321,15 @@ func Authorized(table string, role Role) *ACLResult {
 		if table == val || (strings.HasSuffix(val, "%") && strings.HasPrefix(table, val[:len(val)-1])) {
 			acl, ok := currentACL.entries[mid].acl[role]
 			if ok {
-				return &ACLResult{
+				result := &ACLResult{
 					ACL:       acl,
 					GroupName: currentACL.entries[mid].groupName,
 				}
+				if role == READER {
+					result.ColumnReaders = currentACL.entries[mid].columnReaders
+					result.RowFilters = currentACL.entries[mid].rowFilters
+				}
+				return result
 			}
 			break
 		} else if table < val {
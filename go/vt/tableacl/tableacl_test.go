@@ -181,6 +181,106 @@ func TestTableACLAuthorize(t *testing.T) {
 	}
 }
 
+func TestTableACLColumnReadersAndRowFilters(t *testing.T) {
+	setUpTableACL(&simpleacl.Factory{})
+	config := &tableaclpb.Config{
+		TableGroups: []*tableaclpb.TableGroupSpec{{
+			Name:                 "group01",
+			TableNamesOrPrefixes: []string{"test_users"},
+			Readers:              []string{"u1", "u2"},
+			Writers:              []string{"u1"},
+			ColumnReaders: []*tableaclpb.ColumnReaders{{
+				Columns: []string{"id", "Region"},
+				Readers: []string{"u3", "u4"},
+			}, {
+				Columns: []string{"name"},
+				Readers: []string{"u4"},
+			}},
+			RowFilters: []*tableaclpb.RowFilter{{
+				Predicate: "region = 'US'",
+				Readers:   []string{"u2", "u3", "u4"},
+			}, {
+				Predicate: "deleted = 0 or admin = 1",
+				Readers:   []string{"u4"},
+			}},
+		}},
+	}
+	if err := InitFromProto(config); err != nil {
+		t.Fatalf("InitFromProto(<data>) = %v, want: nil", err)
+	}
+
+	readerACL := Authorized("test_users", READER)
+	columnTests := []struct {
+		principal string
+		columns   []string
+		want      bool
+	}{
+		{"u1", []string{"id"}, false},
+		{"u3", []string{"id", "region"}, true},
+		{"u3", []string{"ID"}, true},
+		{"u3", []string{"id", "name"}, false},
+		{"u3", nil, false},
+		{"u3", []string{}, true},
+		{"u4", []string{"id", "name"}, true},
+		{"u5", []string{}, false},
+	}
+	for _, test := range columnTests {
+		if got := readerACL.IsColumnReader(test.principal, test.columns); got != test.want {
+			t.Errorf("IsColumnReader(%v, %v) = %v, want %v", test.principal, test.columns, got, test.want)
+		}
+	}
+
+	filterTests := []struct {
+		principal string
+		want      string
+	}{
+		{"u1", ""},
+		{"u2", "(region = 'US')"},
+		{"u4", "(region = 'US') and (deleted = 0 or admin = 1)"},
+	}
+	for _, test := range filterTests {
+		if got := readerACL.RowFilter(test.principal); got != test.want {
+			t.Errorf("RowFilter(%v) = %q, want %q", test.principal, got, test.want)
+		}
+	}
+
+	restrictedTests := []struct {
+		principal string
+		want      bool
+	}{
+		{"u1", false},
+		{"u2", true},
+		{"u3", true},
+		{"u5", false},
+	}
+	for _, test := range restrictedTests {
+		if got := readerACL.IsRestricted(test.principal); got != test.want {
+			t.Errorf("IsRestricted(%v) = %v, want %v", test.principal, got, test.want)
+		}
+	}
+
+	// Column grants and row filters don't apply to writers.
+	writerACL := Authorized("test_users", WRITER)
+	if writerACL.IsColumnReader("u3", []string{"id"}) || writerACL.RowFilter("u4") != "" {
+		t.Errorf("column readers and row filters should only be set for readers: %+v", writerACL)
+	}
+
+	// Invalid configs.
+	config.TableGroups[0].RowFilters[0].Predicate = "region = :region"
+	if err := ValidateProto(config); err == nil {
+		t.Errorf("ValidateProto should fail for a row filter with bind variables")
+	}
+	config.TableGroups[0].RowFilters[0].Predicate = "1 union select * from secrets"
+	if err := ValidateProto(config); err == nil {
+		t.Errorf("ValidateProto should fail for a row filter that is not a predicate")
+	}
+	config.TableGroups[0].RowFilters[0].Predicate = "region = 'US'"
+	config.TableGroups[0].ColumnReaders[0].Columns = nil
+	if err := ValidateProto(config); err == nil {
+		t.Errorf("ValidateProto should fail for column readers without columns")
+	}
+}
+
 func TestFailedToCreateACL(t *testing.T) {
 	setUpTableACL(&fakeAclFactory{})
 	config := &tableaclpb.Config{
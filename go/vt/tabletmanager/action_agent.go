@@ -266,6 +266,14 @@ func NewActionAgent(
 		agent.initHealthCheck()
 	}
 
+	// Feed the replication lag of the shard to the query throttler.
+	agent.initQueryThrottlerHealthCheck()
+
+	// Run the online schema migrations of our shard when we're master.
+	if *onlineDDLCheckInterval > 0 {
+		go agent.onlineDDLLoop(batchCtx, *onlineDDLCheckInterval)
+	}
+
 	// Start periodic Orchestrator self-registration, if configured.
 	if agent.orc != nil {
 		go agent.orc.DiscoverLoop(agent)
@@ -18,6 +18,7 @@ import (
 	"time"
 
 	log "github.com/golang/glog"
+	"github.com/golang/protobuf/proto"
 	"github.com/youtube/vitess/go/stats"
 	"github.com/youtube/vitess/go/tb"
 	"github.com/youtube/vitess/go/vt/binlog/binlogplayer"
@@ -463,8 +464,21 @@ func (blm *BinlogPlayerMap) maxSecondsBehindMasterUNGUARDED() int64 {
 func (blm *BinlogPlayerMap) addPlayer(ctx context.Context, cell string, keyRange *topodatapb.KeyRange, sourceShard *topodatapb.Shard_SourceShard, dbName string) {
 	bpc, ok := blm.players[sourceShard.Uid]
 	if ok {
-		log.Infof("Already playing logs for %v", sourceShard)
-		return
+		if proto.Equal(bpc.sourceShard, sourceShard) {
+			log.Infof("Already playing logs for %v", sourceShard)
+			return
+		}
+
+		// The source changed, e.g. a table was removed by a
+		// MoveTables cutover. Restart the player, it will resume
+		// from its checkpoint.
+		log.Infof("Restarting player for %v, source changed from %v", sourceShard, bpc.sourceShard)
+		if blm.state == BpmStateRunning {
+			bpc.Stop()
+		}
+		if err := bpc.Close(); err != nil {
+			log.Error(err)
+		}
 	}
 
 	bpc = newBinlogPlayerController(blm.ts, blm.vtClientFactory, blm.mysqld, cell, keyRange, sourceShard, dbName)
@@ -0,0 +1,209 @@
+// Copyright 2016, Google Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tabletmanager
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"time"
+
+	log "github.com/golang/glog"
+	"golang.org/x/net/context"
+
+	"github.com/youtube/vitess/go/vt/discovery"
+	"github.com/youtube/vitess/go/vt/schemamanager/onlineddl"
+	"github.com/youtube/vitess/go/vt/throttler"
+	"github.com/youtube/vitess/go/vt/topo"
+	"github.com/youtube/vitess/go/vt/topo/topoproto"
+
+	topodatapb "github.com/youtube/vitess/go/vt/proto/topodata"
+)
+
+// This file runs the online schema migrations released by vtctld for
+// our shard, when the tablet is the master. See the onlineddl package
+// for how a migration runs.
+
+var (
+	onlineDDLCheckInterval    = flag.Duration("online_ddl_check_interval", 30*time.Second, "interval at which the master checks the topology for online schema migrations to run. 0 disables online schema migrations on this tablet.")
+	onlineDDLProgressInterval = flag.Duration("online_ddl_progress_interval", 30*time.Second, "interval at which the progress of a running online schema migration is saved in the topology. It must be well below the -schema_migration_shard_timeout of vtctld.")
+	onlineDDLChunkSize        = flag.Int("online_ddl_chunk_size", onlineddl.DefaultConfig.ChunkSize, "number of rows copied at once by online schema migrations")
+	onlineDDLMaxRate          = flag.Int64("online_ddl_max_rate", onlineddl.DefaultConfig.MaxRate, "maximum number of chunks copied (or binlog transactions replayed) per second by online schema migrations. The throttler of a migration is called OnlineDDL/<id>, and can be reconfigured at runtime.")
+	onlineDDLMaxLag           = flag.Int64("online_ddl_max_replication_lag", onlineddl.DefaultConfig.MaxReplicationLag, "replication lag (in seconds) of the replicas above which online schema migrations slow down")
+	onlineDDLCutOverTimeout   = flag.Duration("online_ddl_cutover_timeout", onlineddl.DefaultConfig.CutOverTimeout, "maximum time the table stays locked during the cut-over of an online schema migration")
+	onlineDDLCutOverAttempts  = flag.Int("online_ddl_cutover_attempts", onlineddl.DefaultConfig.CutOverAttempts, "number of times the cut-over of an online schema migration is tried before the migration fails")
+	onlineDDLRPCTimeout       = flag.Duration("online_ddl_rpc_timeout", 30*time.Second, "timeout for the topology calls of online schema migrations")
+)
+
+// errSchemaMigrationCancelled is returned by the progress callback
+// when the migration was cancelled.
+var errSchemaMigrationCancelled = errors.New("schema migration was cancelled")
+
+// onlineDDLLoop checks for migrations to run until ctx is canceled.
+// Migrations run one at a time, in this goroutine.
+func (agent *ActionAgent) onlineDDLLoop(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if agent.Tablet().Type == topodatapb.TabletType_MASTER {
+			if err := agent.checkSchemaMigrations(ctx); err != nil {
+				log.Warningf("cannot check online schema migrations: %v", err)
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// checkSchemaMigrations runs the migrations released for our shard.
+func (agent *ActionAgent) checkSchemaMigrations(ctx context.Context) error {
+	tablet := agent.Tablet()
+	alias := topoproto.TabletAliasString(tablet.Alias)
+	shortCtx, cancel := context.WithTimeout(ctx, *onlineDDLRPCTimeout)
+	ids, err := agent.TopoServer.GetSchemaMigrationIDs(shortCtx, tablet.Keyspace)
+	cancel()
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		// Claim the migration. A migration that is running with our
+		// alias was interrupted by a restart, so we take it again.
+		var sql string
+		now := time.Now().Unix()
+		shortCtx, cancel := context.WithTimeout(ctx, *onlineDDLRPCTimeout)
+		smi, err := agent.TopoServer.UpdateSchemaMigrationFields(shortCtx, tablet.Keyspace, id, func(sm *topo.SchemaMigration) error {
+			ssm, ok := sm.Shards[tablet.Shard]
+			if !ok || sm.CancelRequested {
+				return topo.ErrNoUpdateNeeded
+			}
+			if ssm.State != topo.SchemaMigrationReady && (ssm.State != topo.SchemaMigrationRunning || ssm.Tablet != alias) {
+				return topo.ErrNoUpdateNeeded
+			}
+			*ssm = topo.ShardSchemaMigration{
+				State:      topo.SchemaMigrationRunning,
+				Tablet:     alias,
+				StartTime:  now,
+				UpdateTime: now,
+			}
+			sql = sm.SQL
+			return nil
+		})
+		cancel()
+		switch {
+		case err == topo.ErrNoNode:
+			// Deleted in the meantime.
+			continue
+		case err != nil:
+			return err
+		case smi == nil:
+			continue
+		}
+		agent.runSchemaMigration(ctx, tablet, id, sql)
+	}
+	return nil
+}
+
+// runSchemaMigration runs a migration we claimed, and saves its outcome.
+func (agent *ActionAgent) runSchemaMigration(ctx context.Context, tablet *topodatapb.Tablet, id, sql string) {
+	log.Infof("starting online schema migration %v: %v", id, sql)
+	err := agent.migrate(ctx, tablet, id, sql)
+
+	state := topo.SchemaMigrationComplete
+	errorMessage := ""
+	switch err {
+	case nil:
+		log.Infof("online schema migration %v is complete", id)
+		if err := agent.QueryServiceControl.ReloadSchema(ctx); err != nil {
+			log.Warningf("cannot reload schema after online schema migration %v: %v", id, err)
+		}
+	case errSchemaMigrationCancelled:
+		log.Infof("online schema migration %v was cancelled", id)
+		state = topo.SchemaMigrationCancelled
+	default:
+		log.Errorf("online schema migration %v failed: %v", id, err)
+		state = topo.SchemaMigrationFailed
+		errorMessage = err.Error()
+	}
+
+	// Use a new context, ctx may have been canceled.
+	shortCtx, cancel := context.WithTimeout(context.Background(), *onlineDDLRPCTimeout)
+	defer cancel()
+	if _, err := agent.TopoServer.UpdateSchemaMigrationFields(shortCtx, tablet.Keyspace, id, func(sm *topo.SchemaMigration) error {
+		ssm, ok := sm.Shards[tablet.Shard]
+		if !ok {
+			return topo.ErrNoUpdateNeeded
+		}
+		ssm.State = state
+		ssm.Error = errorMessage
+		ssm.EndTime = time.Now().Unix()
+		ssm.UpdateTime = ssm.EndTime
+		return nil
+	}); err != nil {
+		log.Errorf("cannot save the outcome of online schema migration %v: %v", id, err)
+	}
+}
+
+// migrate runs the migration, with its throttler fed by the
+// replication lag of the replicas of the shard.
+func (agent *ActionAgent) migrate(ctx context.Context, tablet *topodatapb.Tablet, id, sql string) error {
+	m, err := onlineddl.NewMigrator(agent.MysqlDaemon, topoproto.TabletDbName(tablet), id, sql, onlineddl.Config{
+		ChunkSize:         *onlineDDLChunkSize,
+		MaxRate:           *onlineDDLMaxRate,
+		MaxReplicationLag: *onlineDDLMaxLag,
+		CutOverTimeout:    *onlineDDLCutOverTimeout,
+		CutOverAttempts:   *onlineDDLCutOverAttempts,
+	})
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+
+	if *onlineDDLMaxLag != throttler.ReplicationLagModuleDisabled {
+		hc := discovery.NewHealthCheck(*queryThrottlerTimeout, *queryThrottlerRetryDelay, *queryThrottlerTimeout)
+		hc.SetListener(m, false /* sendDownEvents */)
+		watcher := discovery.NewShardReplicationWatcher(agent.TopoServer, hc, tablet.Alias.Cell, tablet.Keyspace, tablet.Shard, *queryThrottlerTopologyRefresh, discovery.DefaultTopoReadConcurrency)
+		// The migrator must not get updates once it's closed.
+		defer hc.Close()
+		defer watcher.Stop()
+	}
+
+	lastUpdate := time.Now()
+	return m.Run(ctx, func(rowsCopied, rowsTotal int64) error {
+		if time.Since(lastUpdate) < *onlineDDLProgressInterval {
+			return nil
+		}
+		lastUpdate = time.Now()
+		if agent.Tablet().Type != topodatapb.TabletType_MASTER {
+			return fmt.Errorf("tablet is not the master any more")
+		}
+		shortCtx, cancel := context.WithTimeout(ctx, *onlineDDLRPCTimeout)
+		defer cancel()
+		cancelled := false
+		_, err := agent.TopoServer.UpdateSchemaMigrationFields(shortCtx, tablet.Keyspace, id, func(sm *topo.SchemaMigration) error {
+			cancelled = sm.CancelRequested
+			ssm, ok := sm.Shards[tablet.Shard]
+			if !ok {
+				return fmt.Errorf("shard %v is not part of the migration any more", tablet.Shard)
+			}
+			ssm.RowsCopied = rowsCopied
+			ssm.RowsTotal = rowsTotal
+			ssm.UpdateTime = lastUpdate.Unix()
+			return nil
+		})
+		if err != nil {
+			// Keep going, vtctld only gives up on us after
+			// several missed updates.
+			log.Warningf("cannot save the progress of online schema migration %v: %v", id, err)
+			return nil
+		}
+		if cancelled {
+			return errSchemaMigrationCancelled
+		}
+		return nil
+	})
+}
@@ -0,0 +1,40 @@
+// Copyright 2016, Google Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tabletmanager
+
+import (
+	"flag"
+	"time"
+
+	log "github.com/golang/glog"
+
+	"github.com/youtube/vitess/go/vt/discovery"
+)
+
+// This file feeds the replication lag of the shard replicas to the
+// query throttler of the tablet server, if it is enabled.
+
+var (
+	queryThrottlerTopologyRefresh = flag.Duration("query_throttler_healthcheck_topology_refresh", 30*time.Second, "refresh interval for re-reading the topology to find the replicas watched by the query throttler")
+	queryThrottlerRetryDelay      = flag.Duration("query_throttler_healthcheck_retry_delay", 5*time.Second, "delay before retrying a failed healthcheck of a replica watched by the query throttler")
+	queryThrottlerTimeout         = flag.Duration("query_throttler_healthcheck_timeout", time.Minute, "the health check timeout period of the replicas watched by the query throttler")
+)
+
+// initQueryThrottlerHealthCheck starts a healthcheck on the tablets
+// of our shard in our cell, and sends their stats to the query throttler.
+// It does nothing if the query throttler is disabled.
+func (agent *ActionAgent) initQueryThrottlerHealthCheck() {
+	qt := agent.QueryServiceControl.QueryThrottler()
+	if qt == nil {
+		return
+	}
+	tablet := agent.Tablet()
+	log.Infof("Starting the query throttler healthcheck on %v/%v in cell %v", tablet.Keyspace, tablet.Shard, tablet.Alias.Cell)
+
+	hc := discovery.NewHealthCheck(*queryThrottlerTimeout, *queryThrottlerRetryDelay, *queryThrottlerTimeout)
+	hc.SetListener(qt, false /* sendDownEvents */)
+	// The watcher and the healthcheck live as long as the process.
+	discovery.NewShardReplicationWatcher(agent.TopoServer, hc, tablet.Alias.Cell, tablet.Keyspace, tablet.Shard, *queryThrottlerTopologyRefresh, discovery.DefaultTopoReadConcurrency)
+}
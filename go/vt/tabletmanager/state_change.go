@@ -168,6 +168,31 @@ func (agent *ActionAgent) updateState(ctx context.Context, oldTablet *topodatapb
 // It owns starting and stopping the update stream service.
 //
 // It owns reading the TabletControl for the current tablet, and storing it.
+// movedTables returns the tables filtered replication copies into the
+// shard, if the tables are moved into an existing keyspace (see vtctl
+// MoveTables). The master of such a keyspace keeps serving its other
+// tables. It returns nil for regular filtered replication, and for a
+// vertical split into a new keyspace, which is served from its source
+// keyspace until the migration.
+func (agent *ActionAgent) movedTables(ctx context.Context, si *topo.ShardInfo) []string {
+	var tables []string
+	for _, ss := range si.SourceShards {
+		if len(ss.Tables) == 0 {
+			return nil
+		}
+		tables = append(tables, ss.Tables...)
+	}
+	ki, err := agent.TopoServer.GetKeyspace(ctx, si.Keyspace())
+	if err != nil {
+		log.Errorf("Cannot read keyspace %v, assuming it is a vertical split target: %v", si.Keyspace(), err)
+		return nil
+	}
+	if len(ki.ServedFroms) > 0 {
+		return nil
+	}
+	return tables
+}
+
 func (agent *ActionAgent) changeCallback(ctx context.Context, oldTablet, newTablet *topodatapb.Tablet) {
 	span := trace.NewSpanFromContext(ctx)
 	span.StartLocal("ActionAgent.changeCallback")
@@ -190,10 +215,14 @@ func (agent *ActionAgent) changeCallback(ctx context.Context, oldTablet, newTabl
 			log.Errorf("Cannot read shard for this tablet %v, might have inaccurate SourceShards and TabletControls: %v", newTablet.Alias, err)
 			updateBlacklistedTables = false
 		} else {
+			var movedTables []string
 			if newTablet.Type == topodatapb.TabletType_MASTER {
 				if len(shardInfo.SourceShards) > 0 {
-					allowQuery = false
-					disallowQueryReason = "master tablet with filtered replication on"
+					movedTables = agent.movedTables(ctx, shardInfo)
+					if movedTables == nil {
+						allowQuery = false
+						disallowQueryReason = "master tablet with filtered replication on"
+					}
 				}
 			}
 			if tc := shardInfo.GetTabletControl(newTablet.Type); tc != nil {
@@ -205,6 +234,10 @@ func (agent *ActionAgent) changeCallback(ctx context.Context, oldTablet, newTabl
 					blacklistedTables = tc.BlacklistedTables
 				}
 			}
+			if len(movedTables) > 0 {
+				// The tables are still written by filtered replication.
+				blacklistedTables = append(append([]string(nil), blacklistedTables...), movedTables...)
+			}
 		}
 	} else {
 		disallowQueryReason = fmt.Sprintf("not a serving tablet type(%v)", newTablet.Type)
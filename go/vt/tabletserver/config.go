@@ -15,6 +15,7 @@ import (
 	"github.com/youtube/vitess/go/vt/dbconfigs"
 	"github.com/youtube/vitess/go/vt/mysqlctl"
 	"github.com/youtube/vitess/go/vt/tabletserver/queryservice"
+	"github.com/youtube/vitess/go/vt/throttler"
 
 	querypb "github.com/youtube/vitess/go/vt/proto/query"
 	topodatapb "github.com/youtube/vitess/go/vt/proto/topodata"
@@ -34,6 +35,7 @@ func init() {
 	flag.IntVar(&qsConfig.MaxResultSize, "queryserver-config-max-result-size", DefaultQsConfig.MaxResultSize, "query server max result size, maximum number of rows allowed to return from vttablet for non-streaming queries.")
 	flag.IntVar(&qsConfig.MaxDMLRows, "queryserver-config-max-dml-rows", DefaultQsConfig.MaxDMLRows, "query server max dml rows per statement, maximum number of rows allowed to return at a time for an upadte or delete with either 1) an equality where clauses on primary keys, or 2) a subselect statement. For update and delete statements in above two categories, vttablet will split the original query into multiple small queries based on this configuration value. ")
 	flag.IntVar(&qsConfig.StreamBufferSize, "queryserver-config-stream-buffer-size", DefaultQsConfig.StreamBufferSize, "query server stream buffer size, the maximum number of bytes sent from vttablet for each stream call.")
+	flag.Float64Var(&qsConfig.StreamSendTimeout, "queryserver-config-stream-send-timeout", DefaultQsConfig.StreamSendTimeout, "query server stream send timeout (in seconds). A streaming query stops reading from MySQL while its client doesn't read its results. If the client doesn't read a result for longer than this value, the query is killed and the stream fails. 0 means no limit.")
 	flag.IntVar(&qsConfig.QueryCacheSize, "queryserver-config-query-cache-size", DefaultQsConfig.QueryCacheSize, "query server query cache size, maximum number of queries to be cached. vttablet analyzes every incoming query and generate a query plan, these plans are being cached in a lru cache. This config controls the capacity of the lru cache.")
 	flag.Float64Var(&qsConfig.SchemaReloadTime, "queryserver-config-schema-reload-time", DefaultQsConfig.SchemaReloadTime, "query server schema reload time, how often vttablet reloads schemas from underlying MySQL instance in seconds. vttablet keeps table schemas in its own memory and periodically refreshes it from MySQL. This config controls the reload time.")
 	flag.Float64Var(&qsConfig.QueryTimeout, "queryserver-config-query-timeout", DefaultQsConfig.QueryTimeout, "query server query timeout (in seconds), this is the query timeout in vttablet side. If a query takes more than this timeout, it will be killed.")
@@ -50,6 +52,23 @@ func init() {
 	flag.StringVar(&qsConfig.DebugURLPrefix, "debug-url-prefix", DefaultQsConfig.DebugURLPrefix, "debug url prefix, vttablet will report various system debug pages and this config controls the prefix of these debug urls")
 	flag.StringVar(&qsConfig.PoolNamePrefix, "pool-name-prefix", DefaultQsConfig.PoolNamePrefix, "pool name prefix, vttablet has several pools and each of them has a name. This config specifies the prefix of these pool names")
 	flag.BoolVar(&qsConfig.EnableAutoCommit, "enable-autocommit", DefaultQsConfig.EnableAutoCommit, "if the flag is on, a DML outsides a transaction will be auto committed.")
+	// query throttler related configurations.
+	flag.BoolVar(&qsConfig.EnableQueryThrottler, "enable-query-throttler", DefaultQsConfig.EnableQueryThrottler, "if the flag is on, DMLs and streaming queries are throttled based on the replication lag of the shard. The throttlers can be inspected on /throttlerz and reconfigured with the throttler RPCs.")
+	flag.StringVar(&qsConfig.QueryThrottlerKeys, "queryserver-config-query-throttler-keys", DefaultQsConfig.QueryThrottlerKeys, "comma separated list of keys the queries are throttled by. Each key value gets its own throttler. Valid keys are 'table', 'caller' (effective caller if set, immediate caller otherwise) and 'workload' (as set by a 'workload=<name>' query comment).")
+	flag.Int64Var(&qsConfig.QueryThrottlerMaxRate, "queryserver-config-query-throttler-max-rate", DefaultQsConfig.QueryThrottlerMaxRate, "initial maximum rate (in queries per second) of each query throttler. Defaults to no limit.")
+	flag.Int64Var(&qsConfig.QueryThrottlerMaxReplicationLag, "queryserver-config-query-throttler-max-replication-lag", DefaultQsConfig.QueryThrottlerMaxReplicationLag, "maximum replication lag (in seconds) of the replicas of the shard above which the query throttlers reduce their rate. Defaults to no limit.")
+	// hot row protection related configurations.
+	flag.BoolVar(&qsConfig.EnableHotRowProtection, "enable-hot-row-protection", DefaultQsConfig.EnableHotRowProtection, "if the flag is on, the transactions that update the same row (DMLs by primary key) are queued, instead of piling up on the MySQL row lock. Autocommit DMLs are queued before they get a transaction pool connection. Transactions started with Begin already hold their connection, and keep it while they are queued.")
+	flag.IntVar(&qsConfig.HotRowProtectionMaxQueueSize, "queryserver-config-hot-row-protection-max-queue-size", DefaultQsConfig.HotRowProtectionMaxQueueSize, "maximum number of transactions running or queued for the same row. Transactions above it fail with a retryable error.")
+	flag.IntVar(&qsConfig.HotRowProtectionConcurrentTransactions, "queryserver-config-hot-row-protection-concurrent-transactions", DefaultQsConfig.HotRowProtectionConcurrentTransactions, "number of transactions on the same row that run concurrently. The others wait in the queue of the row. Values above 1 keep MySQL busy while the running transactions commit.")
+	// query killer related configurations.
+	flag.BoolVar(&qsConfig.EnableQueryKiller, "enable-query-killer", DefaultQsConfig.EnableQueryKiller, "if the flag is on, the queries that run for longer than their limit in -queryserver-config-query-killer-limits are killed with KILL QUERY on a dba connection. The recently killed queries are listed on /querykillz.")
+	flag.StringVar(&qsConfig.QueryKillerLimits, "queryserver-config-query-killer-limits", DefaultQsConfig.QueryKillerLimits, "comma separated list of query time limits, as <plan|table|caller>:<name>=<duration>, e.g. 'plan:PASS_SELECT=10s,table:big_table=1m,caller:batch=10m'. A caller limit (effective caller if set, immediate caller otherwise) takes precedence over a table limit, which takes precedence over a plan limit.")
+	flag.BoolVar(&qsConfig.QueryKillerDryRun, "queryserver-config-query-killer-dry-run", DefaultQsConfig.QueryKillerDryRun, "if the flag is on, the query killer only logs and lists on /querykillz the queries it would kill.")
+	// result cache related configurations.
+	flag.BoolVar(&qsConfig.EnableResultCache, "enable-result-cache", DefaultQsConfig.EnableResultCache, "if the flag is on, the results of the selects by primary key on the tables in -queryserver-config-result-cache-tables are cached. The cache is invalidated by the DMLs of the tablet on masters, and by the update stream on the other tablets.")
+	flag.StringVar(&qsConfig.ResultCacheTables, "queryserver-config-result-cache-tables", DefaultQsConfig.ResultCacheTables, "comma separated list of the tables whose selects by primary key are served from the result cache.")
+	flag.Int64Var(&qsConfig.ResultCacheSize, "queryserver-config-result-cache-size", DefaultQsConfig.ResultCacheSize, "memory budget (in bytes) of the result cache. The least recently used results are evicted past it.")
 }
 
 // Init must be called after flag.Parse, and before doing any other operations.
@@ -58,6 +77,16 @@ func Init() {
 	TxLogger.ServeLogs(*txLogHandler, buildFmter(TxLogger))
 }
 
+// VerifyConfig checks the query service config set by the flags.
+// It must be called after flag.Parse. A process shouldn't start
+// serving if it returns an error.
+func VerifyConfig() error {
+	if err := verifyQueryThrottlerConfig(qsConfig); err != nil {
+		return fmt.Errorf("invalid query throttler config: %v", err)
+	}
+	return nil
+}
+
 // Config contains all the configuration for query service
 type Config struct {
 	PoolSize             int
@@ -67,6 +96,7 @@ type Config struct {
 	MaxResultSize        int
 	MaxDMLRows           int
 	StreamBufferSize     int
+	StreamSendTimeout    float64
 	QueryCacheSize       int
 	SchemaReloadTime     float64
 	QueryTimeout         float64
@@ -82,6 +112,23 @@ type Config struct {
 	DebugURLPrefix       string
 	PoolNamePrefix       string
 	TableAclExemptACL    string
+
+	EnableQueryThrottler            bool
+	QueryThrottlerKeys              string
+	QueryThrottlerMaxRate           int64
+	QueryThrottlerMaxReplicationLag int64
+
+	EnableHotRowProtection                 bool
+	HotRowProtectionMaxQueueSize           int
+	HotRowProtectionConcurrentTransactions int
+
+	EnableQueryKiller bool
+	QueryKillerLimits string
+	QueryKillerDryRun bool
+
+	EnableResultCache bool
+	ResultCacheTables string
+	ResultCacheSize   int64
 }
 
 // DefaultQsConfig is the default value for the query service config.
@@ -104,6 +151,7 @@ var DefaultQsConfig = Config{
 	TxPoolTimeout:        1,
 	IdleTimeout:          30 * 60,
 	StreamBufferSize:     32 * 1024,
+	StreamSendTimeout:    0,
 	StrictMode:           true,
 	StrictTableAcl:       false,
 	TerseErrors:          false,
@@ -114,6 +162,23 @@ var DefaultQsConfig = Config{
 	DebugURLPrefix:       "/debug",
 	PoolNamePrefix:       "",
 	TableAclExemptACL:    "",
+
+	EnableQueryThrottler:            false,
+	QueryThrottlerKeys:              QueryThrottlerKeyTable,
+	QueryThrottlerMaxRate:           throttler.MaxRateModuleDisabled,
+	QueryThrottlerMaxReplicationLag: throttler.ReplicationLagModuleDisabled,
+
+	EnableHotRowProtection:                 false,
+	HotRowProtectionMaxQueueSize:           20,
+	HotRowProtectionConcurrentTransactions: 5,
+
+	EnableQueryKiller: false,
+	QueryKillerLimits: "",
+	QueryKillerDryRun: false,
+
+	EnableResultCache: false,
+	ResultCacheTables: "",
+	ResultCacheSize:   64 * 1024 * 1024,
 }
 
 var qsConfig Config
@@ -148,6 +213,9 @@ type Controller interface {
 	// InitDBConfig sets up the db config vars.
 	InitDBConfig(querypb.Target, dbconfigs.DBConfigs, mysqlctl.MysqlDaemon) error
 
+	// Target returns the target of the query service, as set by InitDBConfig.
+	Target() querypb.Target
+
 	// SetServingType transitions the query service to the required serving type.
 	// Returns true if the state of QueryService or the tablet type changed.
 	SetServingType(tabletType topodatapb.TabletType, serving bool, alsoAllow []topodatapb.TabletType) (bool, error)
@@ -181,6 +249,9 @@ type Controller interface {
 
 	QueryServiceStats() *QueryServiceStats
 
+	// QueryThrottler returns the query throttler, nil if it is disabled.
+	QueryThrottler() *QueryThrottler
+
 	// BroadcastHealth sends the current health to all listeners
 	BroadcastHealth(terTimestamp int64, stats *querypb.RealtimeStats)
 }
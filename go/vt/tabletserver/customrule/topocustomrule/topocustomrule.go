@@ -0,0 +1,201 @@
+// Copyright 2016, Google Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package topocustomrule
+
+import (
+	"flag"
+	"reflect"
+	"sync"
+	"time"
+
+	log "github.com/golang/glog"
+	"golang.org/x/net/context"
+
+	"github.com/youtube/vitess/go/vt/servenv"
+	"github.com/youtube/vitess/go/vt/tabletserver"
+	"github.com/youtube/vitess/go/vt/topo"
+)
+
+var (
+	// Actual TopoCustomRule object in charge of rule updates
+	topoCustomRule *TopoCustomRule
+	// Commandline flags to enable the topo based rules
+	topoRules      = flag.Bool("topocustomrules", false, "read the custom query rules of the tablet keyspace and shard from the global topology")
+	topoRetryDelay = flag.Duration("topocustomrules_retry_delay", 30*time.Second, "delay before watching the topology custom rules again after an error")
+)
+
+// TopoCustomRuleSource is topo based custom rule source name
+const TopoCustomRuleSource string = "TOPO_CUSTOM_RULE"
+
+// TopoCustomRule is the topo.Server backed implementation of
+// CustomRuleManager. It watches the rules of the keyspace and the
+// rules of the shard of the tablet, and applies both, keyspace rules
+// first. It works with any registered topo implementation.
+type TopoCustomRule struct {
+	ts         topo.Server
+	keyspace   string
+	shard      string
+	retryDelay time.Duration
+
+	mu             sync.Mutex
+	keyspaceRules  []byte
+	shardRules     []byte
+	currentRuleSet *tabletserver.QueryRules
+	cancel         context.CancelFunc
+	wg             sync.WaitGroup
+}
+
+// NewTopoCustomRule creates a new TopoCustomRule for the given shard.
+func NewTopoCustomRule(ts topo.Server, keyspace, shard string, retryDelay time.Duration) *TopoCustomRule {
+	return &TopoCustomRule{
+		ts:             ts,
+		keyspace:       keyspace,
+		shard:          shard,
+		retryDelay:     retryDelay,
+		currentRuleSet: tabletserver.NewQueryRules(),
+	}
+}
+
+// Open starts watching the rules in the topology. Missing rules are
+// not an error, they are picked up when they are created.
+func (tcr *TopoCustomRule) Open(qsc tabletserver.Controller) {
+	ctx, cancel := context.WithCancel(context.Background())
+	tcr.mu.Lock()
+	tcr.cancel = cancel
+	tcr.mu.Unlock()
+	tcr.wg.Add(2)
+	go tcr.watch(ctx, qsc, "")
+	go tcr.watch(ctx, qsc, tcr.shard)
+}
+
+// watch keeps watching the keyspace rules, or the shard rules if
+// shard is not empty, until ctx is done.
+func (tcr *TopoCustomRule) watch(ctx context.Context, qsc tabletserver.Controller, shard string) {
+	defer tcr.wg.Done()
+	for {
+		current, changes, cancel := tcr.ts.WatchQueryRules(ctx, tcr.keyspace, shard)
+		if current.Err != nil {
+			if current.Err == topo.ErrNoNode {
+				tcr.setRules(qsc, shard, nil)
+			} else {
+				log.Warningf("Cannot watch the custom rules of %v/%v: %v", tcr.keyspace, shard, current.Err)
+			}
+		} else {
+			tcr.setRules(qsc, shard, current.Contents)
+			if !tcr.processChanges(ctx, qsc, shard, changes, cancel) {
+				return
+			}
+		}
+
+		// Either the file doesn't exist, or the watch failed.
+		// Try again later.
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(tcr.retryDelay):
+		}
+	}
+}
+
+// processChanges applies the rule changes until the watch errors out,
+// or ctx is done, in which case it returns false.
+func (tcr *TopoCustomRule) processChanges(ctx context.Context, qsc tabletserver.Controller, shard string, changes <-chan *topo.WatchData, cancel topo.CancelFunc) bool {
+	for {
+		select {
+		case <-ctx.Done():
+			cancel()
+			for range changes {
+			}
+			return false
+		case wd, ok := <-changes:
+			if !ok {
+				return true
+			}
+			if wd.Err != nil {
+				// The channel is closed right after this.
+				if wd.Err == topo.ErrNoNode {
+					tcr.setRules(qsc, shard, nil)
+				} else {
+					log.Warningf("Watch of the custom rules of %v/%v failed: %v", tcr.keyspace, shard, wd.Err)
+				}
+				for range changes {
+				}
+				return true
+			}
+			tcr.setRules(qsc, shard, wd.Contents)
+		}
+	}
+}
+
+// setRules saves the new rules of the keyspace, or of the shard if
+// shard is not empty, and propagates the merged rules to the query
+// service if they changed. If the rules cannot be parsed, the
+// previous rules are kept.
+func (tcr *TopoCustomRule) setRules(qsc tabletserver.Controller, shard string, data []byte) {
+	tcr.mu.Lock()
+	defer tcr.mu.Unlock()
+	if shard == "" {
+		tcr.keyspaceRules = data
+	} else {
+		tcr.shardRules = data
+	}
+
+	qrs := tabletserver.NewQueryRules()
+	for _, rules := range [][]byte{tcr.keyspaceRules, tcr.shardRules} {
+		if len(rules) == 0 {
+			continue
+		}
+		levelqrs := tabletserver.NewQueryRules()
+		if err := levelqrs.UnmarshalJSON(rules); err != nil {
+			log.Warningf("Error unmarshaling query rules %v, original data '%s'", err, rules)
+			return
+		}
+		qrs.Append(levelqrs)
+	}
+	if !reflect.DeepEqual(tcr.currentRuleSet, qrs) {
+		tcr.currentRuleSet = qrs.Copy()
+		qsc.SetQueryRules(TopoCustomRuleSource, qrs.Copy())
+		log.Infof("Custom rules of %v/%v fetched from the topology and applied to vttablet", tcr.keyspace, tcr.shard)
+	}
+}
+
+// Close stops watching the rules.
+func (tcr *TopoCustomRule) Close() {
+	tcr.mu.Lock()
+	cancel := tcr.cancel
+	tcr.cancel = nil
+	tcr.mu.Unlock()
+	if cancel != nil {
+		cancel()
+		tcr.wg.Wait()
+	}
+}
+
+// GetRules retrives cached rules
+func (tcr *TopoCustomRule) GetRules() *tabletserver.QueryRules {
+	tcr.mu.Lock()
+	defer tcr.mu.Unlock()
+	return tcr.currentRuleSet.Copy()
+}
+
+// ActivateTopoCustomRules activates the topo based custom rule mechanism
+func ActivateTopoCustomRules(qsc tabletserver.Controller) {
+	if !*topoRules {
+		return
+	}
+	target := qsc.Target()
+	topoCustomRule = NewTopoCustomRule(topo.GetServer(), target.Keyspace, target.Shard, *topoRetryDelay)
+	qsc.RegisterQueryRuleSource(TopoCustomRuleSource)
+	topoCustomRule.Open(qsc)
+}
+
+func init() {
+	tabletserver.RegisterFunctions = append(tabletserver.RegisterFunctions, ActivateTopoCustomRules)
+	servenv.OnTerm(func() {
+		if topoCustomRule != nil {
+			topoCustomRule.Close()
+		}
+	})
+}
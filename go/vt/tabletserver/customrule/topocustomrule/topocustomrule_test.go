@@ -0,0 +1,94 @@
+// Copyright 2016, Google Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package topocustomrule
+
+import (
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/youtube/vitess/go/vt/tabletserver/tabletservermock"
+	"github.com/youtube/vitess/go/vt/zktopo/zktestserver"
+)
+
+var keyspaceRules = `[{
+	"Name": "r1",
+	"Description": "disallow bindvar 'asdfg'",
+	"BindVarConds":[{
+		"Name": "asdfg",
+		"OnAbsent": false,
+		"Operator": ""
+	}]
+}]`
+
+var shardRules = `[{
+	"Name": "r2",
+	"Description": "disallow insert on table test",
+	"TableNames" : ["test"],
+	"Query" : "(insert)|(INSERT)"
+}]`
+
+// waitForRules waits until the rules with the given names, and only
+// them, are applied.
+func waitForRules(t *testing.T, tcr *TopoCustomRule, names ...string) {
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		qrs := tcr.GetRules()
+		ok := true
+		for _, name := range []string{"r1", "r2"} {
+			want := false
+			for _, n := range names {
+				if n == name {
+					want = true
+				}
+			}
+			if (qrs.Find(name) != nil) != want {
+				ok = false
+			}
+		}
+		if ok {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for rules %v, got %v", names, qrs)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestTopoCustomRule(t *testing.T) {
+	ctx := context.Background()
+	ts := zktestserver.New(t, []string{"cell1"})
+	tqsc := tabletservermock.NewController()
+
+	if err := ts.SaveQueryRules(ctx, "ks", "", []byte(keyspaceRules)); err != nil {
+		t.Fatalf("SaveQueryRules failed: %v", err)
+	}
+	tcr := NewTopoCustomRule(ts, "ks", "-80", 10*time.Millisecond)
+	tcr.Open(tqsc)
+	defer tcr.Close()
+	waitForRules(t, tcr, "r1")
+
+	// The shard rules are picked up when they are created, and
+	// applied on top of the keyspace rules.
+	if err := ts.SaveQueryRules(ctx, "ks", "-80", []byte(shardRules)); err != nil {
+		t.Fatalf("SaveQueryRules failed: %v", err)
+	}
+	waitForRules(t, tcr, "r1", "r2")
+
+	// Invalid rules are ignored.
+	if err := ts.SaveQueryRules(ctx, "ks", "", []byte("not json")); err != nil {
+		t.Fatalf("SaveQueryRules failed: %v", err)
+	}
+	time.Sleep(100 * time.Millisecond)
+	waitForRules(t, tcr, "r1", "r2")
+
+	// Removing the keyspace rules.
+	if err := ts.SaveQueryRules(ctx, "ks", "", nil); err != nil {
+		t.Fatalf("SaveQueryRules failed: %v", err)
+	}
+	waitForRules(t, tcr, "r2")
+}
@@ -183,6 +183,19 @@ func (dbc *DBConn) Recycle() {
 // Kill will also not kill a query more than once.
 func (dbc *DBConn) Kill(reason string) error {
 	dbc.queryServiceStats.KillStats.Add("Queries", 1)
+	return dbc.kill(reason, "kill")
+}
+
+// KillQuery kills the currently executing query on MySQL side with
+// KILL QUERY. The connection stays open, and the query fails with an
+// interrupted error. If no query is executing, it's a no-op.
+func (dbc *DBConn) KillQuery(reason string) error {
+	return dbc.kill(reason, "kill query")
+}
+
+// kill runs the given kill statement for the connection on a
+// connection of the dba pool.
+func (dbc *DBConn) kill(reason, killStatement string) error {
 	log.Infof("Due to %s, killing query %s", reason, dbc.Current())
 	killConn, err := dbc.pool.dbaPool.Get(context.TODO())
 	if err != nil {
@@ -191,7 +204,7 @@ func (dbc *DBConn) Kill(reason string) error {
 		return NewTabletError(vtrpcpb.ErrorCode_INTERNAL_ERROR, "Failed to get conn from dba pool: %v", err)
 	}
 	defer killConn.Recycle()
-	sql := fmt.Sprintf("kill %d", dbc.conn.ID())
+	sql := fmt.Sprintf("%s %d", killStatement, dbc.conn.ID())
 	_, err = killConn.ExecuteFetch(sql, 10000, false)
 	if err != nil {
 		log.Errorf("Could not kill query %s: %v", dbc.Current(), err)
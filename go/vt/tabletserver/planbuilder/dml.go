@@ -147,10 +147,119 @@ func analyzeSelect(sel *sqlparser.Select, getTable TableGetter) (plan *ExecPlan,
 		plan.PlanID = PlanNextval
 		plan.FieldQuery = nil
 		plan.FullQuery = nil
+		return plan, nil
+	}
+	plan.ColumnsRead = analyzeColumnsRead(sel)
+	if plan.PlanID == PlanPassSelect && !hasSubquery(sel) {
+		plan.PKValues = analyzeSelectPK(sel.Where, tableInfo)
 	}
 	return plan, nil
 }
 
+// analyzeSelectPK returns the primary key values of a select that
+// only reads rows by primary key: its where clause has only equality
+// or IN conditions, that cover all the primary key columns. It
+// returns nil for the other selects.
+func analyzeSelectPK(node *sqlparser.Where, tableInfo *schema.Table) []interface{} {
+	if node == nil || len(tableInfo.Indexes) == 0 || tableInfo.Indexes[0].Name.Lowered() != "primary" {
+		return nil
+	}
+	conditions := analyzeBoolean(node.Expr)
+	if conditions == nil {
+		return nil
+	}
+	for _, condition := range conditions {
+		if condition.Operator == sqlparser.LikeStr {
+			return nil
+		}
+	}
+	return getPKValues(conditions, tableInfo.Indexes[0])
+}
+
+// hasSubquery returns true if the select has a subquery anywhere.
+func hasSubquery(sel *sqlparser.Select) bool {
+	found := false
+	sqlparser.Walk(func(node sqlparser.SQLNode) (bool, error) {
+		if _, ok := node.(*sqlparser.Subquery); ok {
+			found = true
+			return false, nil
+		}
+		return true, nil
+	}, sel)
+	return found
+}
+
+// analyzeColumnsRead returns the lower-cased names of the columns a
+// single table select reads. It returns nil if all columns are read
+// (select *), or if the select has subqueries.
+func analyzeColumnsRead(sel *sqlparser.Select) []string {
+	columns := []string{}
+	seen := make(map[string]bool)
+	allColumns := false
+	var visit sqlparser.Visit
+	visit = func(node sqlparser.SQLNode) (bool, error) {
+		switch node := node.(type) {
+		case *sqlparser.StarExpr, *sqlparser.Subquery:
+			allColumns = true
+			return false, nil
+		case *sqlparser.FuncExpr:
+			// count(*) doesn't read any column.
+			for _, expr := range node.Exprs {
+				if _, ok := expr.(*sqlparser.StarExpr); ok {
+					continue
+				}
+				sqlparser.Walk(visit, expr)
+			}
+			return false, nil
+		case *sqlparser.ColName:
+			name := node.Name.Lowered()
+			if !seen[name] {
+				seen[name] = true
+				columns = append(columns, name)
+			}
+		}
+		return true, nil
+	}
+	sqlparser.Walk(visit, sel)
+	if allColumns {
+		return nil
+	}
+	return columns
+}
+
+// analyzeOtherTablesRead returns the tables read by the FROM clauses of
+// statement, once each. For a single table select, its table is left
+// out, unless it's also read by a subquery.
+func analyzeOtherTablesRead(statement sqlparser.Statement, tableName string) []string {
+	var tables []string
+	seen := make(map[string]bool)
+	skipTableName := false
+	if sel, ok := statement.(*sqlparser.Select); ok && tableName != "" && analyzeFrom(sel.From) == tableName {
+		skipTableName = true
+	}
+	sqlparser.Walk(func(node sqlparser.SQLNode) (bool, error) {
+		aliased, ok := node.(*sqlparser.AliasedTableExpr)
+		if !ok {
+			return true, nil
+		}
+		tn, ok := aliased.Expr.(*sqlparser.TableName)
+		if !ok {
+			return true, nil
+		}
+		name := string(tn.Name)
+		if skipTableName && name == tableName && tn.Qualifier == "" {
+			skipTableName = false
+			return true, nil
+		}
+		if !seen[name] {
+			seen[name] = true
+			tables = append(tables, name)
+		}
+		return true, nil
+	}, statement)
+	return tables
+}
+
 func analyzeFrom(tableExprs sqlparser.TableExprs) string {
 	if len(tableExprs) > 1 {
 		return ""
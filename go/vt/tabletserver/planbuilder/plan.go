@@ -183,6 +183,8 @@ type ExecPlan struct {
 
 	// PlanDMLPK: where clause values.
 	// PlanInsertPK: values clause.
+	// PlanPassSelect: where clause values, if the select only reads
+	// rows by primary key. Used by the result cache.
 	PKValues []interface{} `json:",omitempty"`
 
 	// For update: set clause if pk is changing.
@@ -190,6 +192,17 @@ type ExecPlan struct {
 
 	// For PlanInsertSubquery: pk columns in the subquery result.
 	SubqueryPKColumns []int `json:",omitempty"`
+
+	// For selects: the lower-cased names of the columns read from
+	// TableName, used by column-level table ACLs. It is nil if all
+	// columns are read, or if they can't be determined.
+	ColumnsRead []string `json:",omitempty"`
+
+	// OtherTablesRead are the tables read by the FROM clauses of the
+	// statement, except TableName for single table selects: joined
+	// tables, and the tables of unions, derived tables and subqueries.
+	// Column grants and row filters can't be enforced on them.
+	OtherTablesRead []string `json:",omitempty"`
 }
 
 func (plan *ExecPlan) setTableInfo(tableName string, getTable TableGetter) (*schema.Table, error) {
@@ -210,6 +223,15 @@ func GetExecPlan(sql string, getTable TableGetter) (plan *ExecPlan, err error) {
 	if err != nil {
 		return nil, err
 	}
+	plan, err = getExecPlan(statement, getTable)
+	if err != nil {
+		return nil, err
+	}
+	plan.OtherTablesRead = analyzeOtherTablesRead(statement, plan.TableName)
+	return plan, nil
+}
+
+func getExecPlan(statement sqlparser.Statement, getTable TableGetter) (plan *ExecPlan, err error) {
 	switch stmt := statement.(type) {
 	case *sqlparser.Union:
 		return &ExecPlan{
@@ -229,7 +251,7 @@ func GetExecPlan(sql string, getTable TableGetter) (plan *ExecPlan, err error) {
 		return analyzeSet(stmt), nil
 	case *sqlparser.DDL:
 		return analyzeDDL(stmt, getTable), nil
-	case *sqlparser.Other:
+	case *sqlparser.Show, *sqlparser.Other:
 		return &ExecPlan{PlanID: PlanOther}, nil
 	}
 	return nil, errors.New("invalid SQL")
@@ -254,12 +276,14 @@ func GetStreamExecPlan(sql string, getTable TableGetter) (plan *ExecPlan, err er
 		}
 		if tableName := analyzeFrom(stmt.From); tableName != "" {
 			plan.setTableInfo(tableName, getTable)
+			plan.ColumnsRead = analyzeColumnsRead(stmt)
 		}
 	case *sqlparser.Union:
 		// pass
 	default:
 		return nil, fmt.Errorf("'%v' not allowed for streaming", sqlparser.String(stmt))
 	}
+	plan.OtherTablesRead = analyzeOtherTablesRead(statement, plan.TableName)
 
 	return plan, nil
 }
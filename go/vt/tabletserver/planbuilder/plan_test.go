@@ -126,6 +126,45 @@ func TestDDLPlan(t *testing.T) {
 	}
 }
 
+func TestGenerateRowFilteredQuery(t *testing.T) {
+	testcases := []struct {
+		sql       string
+		forStream bool
+		want      string
+	}{{
+		sql:  "select eid from a",
+		want: "select eid from a where (region = 'US') limit :#maxLimit",
+	}, {
+		sql:  "select eid from a where eid = 1 or eid = 2",
+		want: "select eid from a where (eid = 1 or eid = 2) and (region = 'US') limit :#maxLimit",
+	}, {
+		sql:       "select eid from a where eid = 1 order by eid",
+		forStream: true,
+		want:      "select eid from a where (eid = 1) and (region = 'US') order by eid asc",
+	}, {
+		sql:  "select eid from a join b",
+		want: "row filters are only supported for single table selects",
+	}, {
+		sql:  "select eid from a union select eid from b",
+		want: "row filters are only supported for single table selects",
+	}, {
+		sql:  "select eid from a where eid in (select eid from a)",
+		want: "row filters are not supported for selects with subqueries",
+	}}
+	for _, tc := range testcases {
+		var got string
+		query, err := GenerateRowFilteredQuery(tc.sql, "region = 'US'", tc.forStream)
+		if err != nil {
+			got = err.Error()
+		} else {
+			got = query.Query
+		}
+		if got != tc.want {
+			t.Errorf("GenerateRowFilteredQuery(%q): %q, want %q", tc.sql, got, tc.want)
+		}
+	}
+}
+
 func matchString(t *testing.T, line int, expected interface{}, actual string) {
 	if expected != nil {
 		if expected.(string) != actual {
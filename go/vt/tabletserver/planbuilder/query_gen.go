@@ -136,3 +136,57 @@ func GenerateSubquery(columns []cistring.CIString, table *sqlparser.AliasedTable
 	}
 	return buf.ParsedQuery()
 }
+
+// GenerateRowFilteredQuery generates the full query of a select with
+// the predicate and-ed to its WHERE clause. It fails if the query is
+// not a single table select without subqueries, because the predicate
+// couldn't be enforced then. The limit is only added if forStream is
+// false, like for the regular full queries.
+func GenerateRowFilteredQuery(sql, predicate string, forStream bool) (*sqlparser.ParsedQuery, error) {
+	statement, err := sqlparser.Parse(sql)
+	if err != nil {
+		return nil, err
+	}
+	sel, ok := statement.(*sqlparser.Select)
+	if !ok || analyzeFrom(sel.From) == "" {
+		return nil, fmt.Errorf("row filters are only supported for single table selects")
+	}
+	hasSubquery := false
+	sqlparser.Walk(func(node sqlparser.SQLNode) (bool, error) {
+		if _, ok := node.(*sqlparser.Subquery); ok {
+			hasSubquery = true
+			return false, nil
+		}
+		return true, nil
+	}, sel)
+	if hasSubquery {
+		return nil, fmt.Errorf("row filters are not supported for selects with subqueries")
+	}
+	filter, err := sqlparser.ParseBoolExpr(predicate)
+	if err != nil {
+		return nil, err
+	}
+
+	// Parenthesize both sides: the existing condition may be
+	// an OR, which has a lower precedence than AND.
+	filter = parenthesize(filter)
+	if sel.Where == nil {
+		sel.Where = sqlparser.NewWhere(sqlparser.WhereStr, filter)
+	} else {
+		sel.Where.Expr = &sqlparser.AndExpr{
+			Left:  parenthesize(sel.Where.Expr),
+			Right: filter,
+		}
+	}
+	if forStream {
+		return GenerateFullQuery(sel), nil
+	}
+	return GenerateSelectLimitQuery(sel), nil
+}
+
+func parenthesize(expr sqlparser.BoolExpr) sqlparser.BoolExpr {
+	if _, ok := expr.(*sqlparser.ParenBoolExpr); ok {
+		return expr
+	}
+	return &sqlparser.ParenBoolExpr{Expr: expr}
+}
@@ -60,6 +60,9 @@ type QueryEngine struct {
 	maxResultSize    sync2.AtomicInt64
 	maxDMLRows       sync2.AtomicInt64
 	streamBufferSize sync2.AtomicInt64
+	// streamSendTimeout is how long a streaming query waits for
+	// its client to read a result. 0 means no limit.
+	streamSendTimeout sync2.AtomicDuration
 	// tableaclExemptCount count the number of accesses allowed
 	// based on membership in the superuser ACL
 	tableaclExemptCount  sync2.AtomicInt64
@@ -73,6 +76,16 @@ type QueryEngine struct {
 	// Loggers
 	accessCheckerLogger *logutil.ThrottledLogger
 
+	// queryThrottler is nil if the query throttler is disabled.
+	queryThrottler *QueryThrottler
+	// queryKiller is nil if the query killer is disabled.
+	queryKiller *QueryKiller
+
+	// txSerializer is nil if the hot row protection is disabled.
+	txSerializer *TxSerializer
+	// resultCache is nil if the result cache is disabled.
+	resultCache *ResultCache
+
 	// Stats
 	queryServiceStats *QueryServiceStats
 }
@@ -183,9 +196,26 @@ func NewQueryEngine(checker MySQLChecker, config Config) *QueryEngine {
 	qe.maxResultSize = sync2.NewAtomicInt64(int64(config.MaxResultSize))
 	qe.maxDMLRows = sync2.NewAtomicInt64(int64(config.MaxDMLRows))
 	qe.streamBufferSize = sync2.NewAtomicInt64(int64(config.StreamBufferSize))
+	qe.streamSendTimeout = sync2.NewAtomicDuration(time.Duration(config.StreamSendTimeout * 1e9))
 
 	qe.accessCheckerLogger = logutil.NewThrottledLogger("accessChecker", 1*time.Second)
 
+	// The query throttler lives as long as the process: the throttlers
+	// it creates must stay registered in throttler.GlobalManager.
+	queryThrottler, err := NewQueryThrottler(config, qe.queryServiceStats)
+	if err != nil {
+		// VerifyConfig() rejects the invalid configs at startup.
+		log.Errorf("Cannot create the query throttler, queries won't be throttled: %v", err)
+	}
+	qe.queryThrottler = queryThrottler
+	qe.txSerializer = NewTxSerializer(config, qe.queryServiceStats)
+	queryKiller, err := NewQueryKiller(config, qe.queryServiceStats)
+	if err != nil {
+		log.Errorf("Cannot create the query killer, queries won't be killed: %v", err)
+	}
+	qe.queryKiller = queryKiller
+	qe.resultCache = NewResultCache(config, qe.queryServiceStats)
+
 	var tableACLAllowedName string
 	var tableACLDeniedName string
 	var tableACLPseudoDeniedName string
@@ -193,6 +223,7 @@ func NewQueryEngine(checker MySQLChecker, config Config) *QueryEngine {
 		stats.Publish(config.StatsPrefix+"MaxResultSize", stats.IntFunc(qe.maxResultSize.Get))
 		stats.Publish(config.StatsPrefix+"MaxDMLRows", stats.IntFunc(qe.maxDMLRows.Get))
 		stats.Publish(config.StatsPrefix+"StreamBufferSize", stats.IntFunc(qe.streamBufferSize.Get))
+		stats.Publish(config.StatsPrefix+"StreamSendTimeout", stats.DurationFunc(qe.streamSendTimeout.Get))
 		stats.Publish(config.StatsPrefix+"TableACLExemptCount", stats.IntFunc(qe.tableaclExemptCount.Get))
 		tableACLAllowedName = "TableACLAllowed"
 		tableACLDeniedName = "TableACLDenied"
@@ -6,6 +6,7 @@ package tabletserver
 
 import (
 	"fmt"
+	"regexp"
 	"strconv"
 	"strings"
 	"time"
@@ -13,7 +14,9 @@ import (
 	"github.com/youtube/vitess/go/cistring"
 	"github.com/youtube/vitess/go/hack"
 	"github.com/youtube/vitess/go/mysql"
+	"github.com/youtube/vitess/go/sqldb"
 	"github.com/youtube/vitess/go/sqltypes"
+	"github.com/youtube/vitess/go/sync2"
 	"github.com/youtube/vitess/go/trace"
 	"github.com/youtube/vitess/go/vt/callerid"
 	"github.com/youtube/vitess/go/vt/callinfo"
@@ -33,8 +36,20 @@ type QueryExecutor struct {
 	ctx           context.Context
 	logStats      *LogStats
 	qe            *QueryEngine
+
+	// rowFilteredQuery replaces plan.FullQuery for selects if the
+	// caller is restricted by a table ACL row filter.
+	rowFilteredQuery *sqlparser.ParsedQuery
+
+	// actionRule is the query rule that changes how the query runs
+	// (QRAddHint, QRCapResult or QRStream), if any.
+	actionRule *QueryRule
 }
 
+// hintedStatementRE matches the start of a statement, up to the keyword
+// an optimizer hint comment follows.
+var hintedStatementRE = regexp.MustCompile(`(?is)^\s*(?:/\*.*?\*/\s*)*(?:select|insert|replace|update|delete)\b`)
+
 var sequenceFields = []*querypb.Field{
 	{
 		Name: "nextval",
@@ -75,6 +90,13 @@ func (qre *QueryExecutor) Execute() (reply *sqltypes.Result, err error) {
 		return nil, err
 	}
 
+	switch qre.plan.PlanID {
+	case planbuilder.PlanPassDML, planbuilder.PlanInsertPK, planbuilder.PlanInsertSubquery, planbuilder.PlanDMLPK, planbuilder.PlanDMLSubquery, planbuilder.PlanUpsertPK:
+		if err := qre.checkThrottler(); err != nil {
+			return nil, err
+		}
+	}
+
 	switch qre.plan.PlanID {
 	case planbuilder.PlanDDL:
 		return qre.execDDL()
@@ -89,11 +111,24 @@ func (qre *QueryExecutor) Execute() (reply *sqltypes.Result, err error) {
 			return nil, err
 		}
 		defer conn.Recycle()
+		// Only the first statement of a transaction can be queued by
+		// the hot row protection, see tx_serializer.go.
+		// Statements like SELECT FOR UPDATE are not recorded in
+		// conn.Queries, but they may lock rows as well.
+		if !conn.executed {
+			done, err := qre.waitForSameRowTransactions()
+			if err != nil {
+				return nil, err
+			}
+			conn.hotRowDone = done
+			conn.executed = true
+		}
 		switch qre.plan.PlanID {
 		case planbuilder.PlanPassDML:
 			if qre.qe.strictMode.Get() != 0 {
 				return nil, NewTabletError(vtrpcpb.ErrorCode_BAD_INPUT, "DML too complex")
 			}
+			qre.recordResultCacheChange(conn, nil)
 			return qre.txFetch(conn, qre.plan.FullQuery, qre.bindVars, nil, false, true)
 		case planbuilder.PlanInsertPK:
 			return qre.execInsertPK(conn)
@@ -132,6 +167,13 @@ func (qre *QueryExecutor) Execute() (reply *sqltypes.Result, err error) {
 				return nil, NewTabletError(vtrpcpb.ErrorCode_BAD_INPUT,
 					"unsupported query outside transaction: %s", qre.query)
 			}
+			done, err := qre.waitForSameRowTransactions()
+			if err != nil {
+				return nil, err
+			}
+			if done != nil {
+				defer done()
+			}
 			return qre.execDmlAutoCommit()
 		}
 	}
@@ -150,6 +192,12 @@ func (qre *QueryExecutor) Stream(excludeFieldNames bool, sendReply func(*sqltype
 	if err := qre.checkPermissions(); err != nil {
 		return err
 	}
+	if err := qre.checkThrottler(); err != nil {
+		return err
+	}
+	if qre.actionRule != nil && qre.actionRule.act == QRCapResult {
+		sendReply = capResult(int64(qre.actionRule.maxRows), sendReply)
+	}
 
 	conn, err := qre.getConn(qre.qe.streamConnPool)
 	if err != nil {
@@ -161,7 +209,41 @@ func (qre *QueryExecutor) Stream(excludeFieldNames bool, sendReply func(*sqltype
 	qre.qe.streamQList.Add(qd)
 	defer qre.qe.streamQList.Remove(qd)
 
-	return qre.streamFetch(conn, qre.plan.FullQuery, qre.bindVars, nil, excludeFieldNames, sendReply)
+	sendReply, sendTimedOut := qre.watchStreamSend(conn, sendReply)
+	err = qre.streamFetch(conn, qre.fullQuery(), qre.bindVars, nil, excludeFieldNames, sendReply)
+	if err != nil && sendTimedOut() {
+		return NewTabletError(vtrpcpb.ErrorCode_RESOURCE_EXHAUSTED, "stream send timeout: the client didn't read a result for %v", qre.qe.streamSendTimeout.Get())
+	}
+	return err
+}
+
+// watchStreamSend wraps the callback of a streaming query to record
+// how long the client takes to read each result. A slow client makes
+// the callback block, and the query stops reading from MySQL. If the
+// stream send timeout is set and a result isn't read in time, the
+// query is killed, so it doesn't hold MySQL resources any longer, and
+// the callback fails once the result is read. The returned function
+// tells if that happened.
+func (qre *QueryExecutor) watchStreamSend(conn killableQuery, sendReply func(*sqltypes.Result) error) (func(*sqltypes.Result) error, func() bool) {
+	var timedOut sync2.AtomicInt32
+	callback := func(r *sqltypes.Result) error {
+		start := time.Now()
+		if timeout := qre.qe.streamSendTimeout.Get(); timeout > 0 {
+			timer := time.AfterFunc(timeout, func() {
+				timedOut.Set(1)
+				qre.qe.queryServiceStats.KillStats.Add("StreamSendTimeout", 1)
+				conn.KillQuery("stream send timeout")
+			})
+			defer timer.Stop()
+		}
+		err := sendReply(r)
+		qre.qe.queryServiceStats.WaitStats.Record("StreamSend", start)
+		if err == nil && timedOut.Get() != 0 {
+			err = fmt.Errorf("stream send timeout")
+		}
+		return err
+	}
+	return callback, func() bool { return timedOut.Get() != 0 }
 }
 
 func (qre *QueryExecutor) execDmlAutoCommit() (reply *sqltypes.Result, err error) {
@@ -171,6 +253,7 @@ func (qre *QueryExecutor) execDmlAutoCommit() (reply *sqltypes.Result, err error
 			if qre.qe.strictMode.Get() != 0 {
 				return nil, NewTabletError(vtrpcpb.ErrorCode_BAD_INPUT, "DML too complex")
 			}
+			qre.recordResultCacheChange(conn, nil)
 			reply, err = qre.txFetch(conn, qre.plan.FullQuery, qre.bindVars, nil, false, true)
 		case planbuilder.PlanInsertPK:
 			reply, err = qre.execInsertPK(conn)
@@ -212,6 +295,66 @@ func (qre *QueryExecutor) execAsTransaction(f func(conn *TxConnection) (*sqltype
 	return reply, nil
 }
 
+// checkThrottler returns an error if the query throttler says the
+// query is over budget.
+func (qre *QueryExecutor) checkThrottler() error {
+	// Internal queries are never throttled.
+	if qre.qe.queryThrottler == nil || qre.ctx == context.Background() {
+		return nil
+	}
+	return qre.qe.queryThrottler.Throttle(qre.ctx, qre.plan.TableName, qre.query, qre.bindVars)
+}
+
+// waitForSameRowTransactions queues the transaction behind the other
+// transactions on the same row, if the hot row protection is enabled
+// and the query is a DML by primary key. The returned function must be
+// called once the transaction is concluded. It's nil if the
+// transaction was not queued.
+func (qre *QueryExecutor) waitForSameRowTransactions() (func(), error) {
+	if qre.qe.txSerializer == nil {
+		return nil, nil
+	}
+	switch qre.plan.PlanID {
+	case planbuilder.PlanDMLPK, planbuilder.PlanUpsertPK:
+	default:
+		return nil, nil
+	}
+	pkRows, err := buildValueList(qre.plan.TableInfo, qre.plan.PKValues, qre.bindVars)
+	if err != nil || len(pkRows) == 0 {
+		// The query will fail (or do nothing) without locking rows.
+		return nil, nil
+	}
+	done, _, err := qre.qe.txSerializer.Wait(qre.ctx, hotRowKey(qre.plan.TableName, pkRows), qre.plan.TableName)
+	return done, err
+}
+
+// recordResultCacheChange records that the transaction changes the
+// given rows, or the whole table if pkRows is nil. The result cache
+// entries are invalidated once the transaction is concluded.
+func (qre *QueryExecutor) recordResultCacheChange(conn *TxConnection, pkRows [][]sqltypes.Value) {
+	if qre.qe.resultCache == nil {
+		return
+	}
+	conn.resultCache = qre.qe.resultCache
+	conn.resultCacheChanges = append(conn.resultCacheChanges, resultCacheChange{
+		table:  qre.plan.TableName,
+		pkRows: pkRows,
+	})
+}
+
+// delay makes the query wait, as requested by a QRDelay rule.
+func (qre *QueryExecutor) delay(qr *QueryRule) error {
+	defer qre.qe.queryServiceStats.WaitStats.Record("QueryRuleDelay", time.Now())
+	timer := time.NewTimer(qr.delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-qre.ctx.Done():
+		return NewTabletError(vtrpcpb.ErrorCode_DEADLINE_EXCEEDED, "Query delayed due to rule %s: %v", qr.Description, qre.ctx.Err())
+	}
+}
+
 // checkPermissions
 func (qre *QueryExecutor) checkPermissions() error {
 	// Skip permissions check if we have a background context.
@@ -227,12 +370,19 @@ func (qre *QueryExecutor) checkPermissions() error {
 		remoteAddr = ci.RemoteAddr()
 		username = ci.Username()
 	}
-	action, desc := qre.plan.Rules.getAction(remoteAddr, username, qre.bindVars)
-	switch action {
-	case QRFail:
-		return NewTabletError(vtrpcpb.ErrorCode_BAD_INPUT, "Query disallowed due to rule: %s", desc)
-	case QRFailRetry:
-		return NewTabletError(vtrpcpb.ErrorCode_QUERY_NOT_SERVED, "Query disallowed due to rule: %s", desc)
+	if qr := qre.plan.Rules.getRule(remoteAddr, username, qre.bindVars); qr != nil {
+		switch qr.act {
+		case QRFail:
+			return NewTabletError(vtrpcpb.ErrorCode_BAD_INPUT, "Query disallowed due to rule: %s", qr.Description)
+		case QRFailRetry:
+			return NewTabletError(vtrpcpb.ErrorCode_QUERY_NOT_SERVED, "Query disallowed due to rule: %s", qr.Description)
+		case QRDelay:
+			if err := qre.delay(qr); err != nil {
+				return err
+			}
+		default:
+			qre.actionRule = qr
+		}
 	}
 
 	// Check for SuperUser calling directly to VTTablet (e.g. VTWorker)
@@ -255,6 +405,10 @@ func (qre *QueryExecutor) checkPermissions() error {
 		return nil
 	}
 
+	if err := qre.checkOtherTablesRead(callerID.Username); err != nil {
+		return err
+	}
+
 	// empty table name, do not need a table ACL check.
 	if qre.plan.TableName == "" {
 		return nil
@@ -270,7 +424,8 @@ func (qre *QueryExecutor) checkPermissions() error {
 		callerID.Username,
 	}
 	// perform table ACL check if it is enabled.
-	if !qre.plan.Authorized.IsMember(callerID.Username) {
+	// Readers of some columns only can run the selects that read them.
+	if !qre.plan.Authorized.IsMember(callerID.Username) && !qre.plan.Authorized.IsColumnReader(callerID.Username, qre.plan.ColumnsRead) {
 		if qre.qe.enableTableAclDryRun {
 			qre.qe.tableaclPseudoDenied.Add(tableACLStatsKey, 1)
 			return nil
@@ -284,10 +439,54 @@ func (qre *QueryExecutor) checkPermissions() error {
 		}
 		return nil
 	}
+	if rowFilter := qre.plan.Authorized.RowFilter(callerID.Username); rowFilter != "" && !qre.qe.enableTableAclDryRun {
+		query, err := planbuilder.GenerateRowFilteredQuery(qre.query, rowFilter, qre.plan.PlanID == planbuilder.PlanSelectStream)
+		if err != nil {
+			qre.qe.tableaclDenied.Add(tableACLStatsKey, 1)
+			return NewTabletError(vtrpcpb.ErrorCode_PERMISSION_DENIED, "table acl error: cannot apply the row filter of %q on table %q: %v", callerID.Username, qre.plan.TableName, err)
+		}
+		qre.rowFilteredQuery = query
+	}
 	qre.qe.tableaclAllowed.Add(tableACLStatsKey, 1)
 	return nil
 }
 
+// checkOtherTablesRead refuses the queries which read tables on which
+// the user has column grants or row filters, other than the table of a
+// single table select: these restrictions can't be enforced in joins,
+// unions, derived tables and subqueries.
+func (qre *QueryExecutor) checkOtherTablesRead(username string) error {
+	for i, authorized := range qre.plan.OtherAuthorized {
+		if !authorized.IsRestricted(username) {
+			continue
+		}
+		table := qre.plan.OtherTablesRead[i]
+		tableACLStatsKey := []string{
+			table,
+			authorized.GroupName,
+			qre.plan.PlanID.String(),
+			username,
+		}
+		if qre.qe.enableTableAclDryRun {
+			qre.qe.tableaclPseudoDenied.Add(tableACLStatsKey, 1)
+			continue
+		}
+		errStr := fmt.Sprintf("table acl error: %q can only read some columns or rows of table %q, which is not allowed in joins, unions or subqueries", username, table)
+		qre.qe.tableaclDenied.Add(tableACLStatsKey, 1)
+		qre.qe.accessCheckerLogger.Infof("%s", errStr)
+		return NewTabletError(vtrpcpb.ErrorCode_PERMISSION_DENIED, "%s", errStr)
+	}
+	return nil
+}
+
+// fullQuery returns the query to run for selects.
+func (qre *QueryExecutor) fullQuery() *sqlparser.ParsedQuery {
+	if qre.rowFilteredQuery != nil {
+		return qre.rowFilteredQuery
+	}
+	return qre.plan.FullQuery
+}
+
 func (qre *QueryExecutor) execDDL() (*sqltypes.Result, error) {
 	ddlPlan := planbuilder.DDLParse(qre.query)
 	if ddlPlan.Action == "" {
@@ -311,6 +510,9 @@ func (qre *QueryExecutor) execDDL() (*sqltypes.Result, error) {
 	if ddlPlan.NewName != "" {
 		qre.qe.schemaInfo.CreateOrUpdateTable(qre.ctx, ddlPlan.NewName)
 	}
+	if qre.qe.resultCache != nil {
+		qre.qe.resultCache.InvalidateAll()
+	}
 	return result, nil
 }
 
@@ -376,21 +578,59 @@ func (qre *QueryExecutor) execNextval() (*sqltypes.Result, error) {
 // execDirect is for reads inside transactions. Always send to MySQL.
 func (qre *QueryExecutor) execDirect(conn *TxConnection) (*sqltypes.Result, error) {
 	if qre.plan.Fields != nil {
-		result, err := qre.txFetch(conn, qre.plan.FullQuery, qre.bindVars, nil, false, false)
+		result, err := qre.txFetch(conn, qre.fullQuery(), qre.bindVars, nil, false, false)
 		if err != nil {
 			return nil, err
 		}
 		result.Fields = qre.plan.Fields
 		return result, nil
 	}
-	return qre.txFetch(conn, qre.plan.FullQuery, qre.bindVars, nil, true, false)
+	return qre.txFetch(conn, qre.fullQuery(), qre.bindVars, nil, true, false)
 }
 
-// execSelect sends a query to mysql only if another identical query is not running. Otherwise, it waits and
-// reuses the result. If the plan is missng field info, it sends the query to mysql requesting full info.
+// execSelect runs a select, from the result cache if possible.
 func (qre *QueryExecutor) execSelect() (*sqltypes.Result, error) {
+	if qre.actionRule != nil && qre.actionRule.act == QRStream {
+		return qre.execStreamSelect()
+	}
+	if qre.qe.resultCache != nil && qre.qe.resultCache.Caches(qre.plan) {
+		return qre.execCachedSelect()
+	}
+	return qre.execUncachedSelect()
+}
+
+// execCachedSelect serves a select by primary key from the result
+// cache. On a miss, it runs the select and caches its result.
+func (qre *QueryExecutor) execCachedSelect() (*sqltypes.Result, error) {
+	pkRows, err := buildValueList(qre.plan.TableInfo, qre.plan.PKValues, qre.bindVars)
+	if err != nil {
+		return nil, err
+	}
+	// The final SQL covers the plan, the bind variables, and what
+	// query rules or row filters changed.
+	key, err := qre.generateFinalSQL(qre.fullQuery(), qre.bindVars, nil)
+	if err != nil {
+		return nil, err
+	}
+	rc := qre.qe.resultCache
+	if result, ok := rc.Get(qre.plan.TableName, key); ok {
+		qre.logStats.QuerySources |= QuerySourceResultCache
+		return result, nil
+	}
+	version := rc.Version()
+	result, err := qre.execUncachedSelect()
+	if err != nil {
+		return nil, err
+	}
+	rc.Set(qre.plan.TableName, key, pkRows, version, result)
+	return result, nil
+}
+
+// execUncachedSelect sends a query to mysql only if another identical query is not running. Otherwise, it waits and
+// reuses the result. If the plan is missng field info, it sends the query to mysql requesting full info.
+func (qre *QueryExecutor) execUncachedSelect() (*sqltypes.Result, error) {
 	if qre.plan.Fields != nil {
-		result, err := qre.qFetch(qre.logStats, qre.plan.FullQuery, qre.bindVars)
+		result, err := qre.qFetch(qre.logStats, qre.fullQuery(), qre.bindVars)
 		if err != nil {
 			return nil, err
 		}
@@ -404,7 +644,51 @@ func (qre *QueryExecutor) execSelect() (*sqltypes.Result, error) {
 		return nil, err
 	}
 	defer conn.Recycle()
-	return qre.dbConnFetch(conn, qre.plan.FullQuery, qre.bindVars, nil, true)
+	return qre.dbConnFetch(conn, qre.fullQuery(), qre.bindVars, nil, true)
+}
+
+// execStreamSelect runs a select on the streaming pool, as requested by
+// a QRStream rule, and buffers its result. It is not consolidated.
+func (qre *QueryExecutor) execStreamSelect() (*sqltypes.Result, error) {
+	conn, err := qre.getConn(qre.qe.streamConnPool)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Recycle()
+
+	qd := NewQueryDetail(qre.logStats.ctx, conn)
+	qre.qe.streamQList.Add(qd)
+	defer qre.qe.streamQList.Remove(qd)
+
+	result := &sqltypes.Result{}
+	err = qre.streamFetch(conn, qre.fullQuery(), qre.bindVars, nil, false, capResult(qre.maxResultSize(), func(r *sqltypes.Result) error {
+		if r.Fields != nil {
+			result.Fields = r.Fields
+		}
+		result.Rows = append(result.Rows, r.Rows...)
+		return nil
+	}))
+	if err != nil {
+		return nil, err
+	}
+	if qre.plan.Fields != nil {
+		result.Fields = qre.plan.Fields
+	}
+	result.RowsAffected = uint64(len(result.Rows))
+	return result, nil
+}
+
+// capResult wraps a streaming callback to fail once more than maxRows
+// rows were sent, the way non-streaming queries do.
+func capResult(maxRows int64, callback func(*sqltypes.Result) error) func(*sqltypes.Result) error {
+	var rows int64
+	return func(r *sqltypes.Result) error {
+		rows += int64(len(r.Rows))
+		if rows > maxRows {
+			return &sqldb.SQLError{Message: fmt.Sprintf("Row count exceeded %d", maxRows)}
+		}
+		return callback(r)
+	}
 }
 
 func (qre *QueryExecutor) execInsertPK(conn *TxConnection) (*sqltypes.Result, error) {
@@ -441,6 +725,7 @@ func (qre *QueryExecutor) execInsertSubquery(conn *TxConnection) (*sqltypes.Resu
 }
 
 func (qre *QueryExecutor) execInsertPKRows(conn *TxConnection, pkRows [][]sqltypes.Value) (*sqltypes.Result, error) {
+	qre.recordResultCacheChange(conn, pkRows)
 	bsc := buildStreamComment(qre.plan.TableInfo, pkRows, nil)
 	return qre.txFetch(conn, qre.plan.OuterQuery, qre.bindVars, bsc, false, true)
 }
@@ -450,6 +735,7 @@ func (qre *QueryExecutor) execUpsertPK(conn *TxConnection) (*sqltypes.Result, er
 	if err != nil {
 		return nil, err
 	}
+	qre.recordResultCacheChange(conn, pkRows)
 	bsc := buildStreamComment(qre.plan.TableInfo, pkRows, nil)
 	result, err := qre.txFetch(conn, qre.plan.OuterQuery, qre.bindVars, bsc, false, true)
 	if err == nil {
@@ -503,6 +789,10 @@ func (qre *QueryExecutor) execDMLPKRows(conn *TxConnection, query *sqlparser.Par
 	if err != nil {
 		return nil, err
 	}
+	qre.recordResultCacheChange(conn, pkRows)
+	if secondaryList != nil {
+		qre.recordResultCacheChange(conn, secondaryList)
+	}
 
 	result := &sqltypes.Result{}
 	maxRows := int(qre.qe.maxDMLRows.Get())
@@ -562,6 +852,18 @@ func (qre *QueryExecutor) qFetch(logStats *LogStats, parsedQuery *sqlparser.Pars
 	if err != nil {
 		return nil, err
 	}
+	if qre.actionRule != nil && qre.actionRule.act == QRCapResult {
+		// The consolidated result may have been fetched with another
+		// row limit, don't share it.
+		waitingForConnectionStart := time.Now()
+		conn, err := qre.qe.connPool.Get(qre.ctx)
+		logStats.WaitingForConnection += time.Now().Sub(waitingForConnectionStart)
+		if err != nil {
+			return nil, NewTabletErrorSQL(vtrpcpb.ErrorCode_INTERNAL_ERROR, err)
+		}
+		defer conn.Recycle()
+		return qre.execSQL(conn, sql, false)
+	}
 	q, ok := qre.qe.consolidator.Create(string(sql))
 	if ok {
 		defer q.Broadcast()
@@ -622,11 +924,16 @@ func (qre *QueryExecutor) streamFetch(conn *DBConn, parsedQuery *sqlparser.Parse
 }
 
 func (qre *QueryExecutor) generateFinalSQL(parsedQuery *sqlparser.ParsedQuery, bindVars map[string]interface{}, buildStreamComment []byte) (string, error) {
-	bindVars["#maxLimit"] = qre.qe.maxResultSize.Get() + 1
+	bindVars["#maxLimit"] = qre.maxResultSize() + 1
 	sql, err := parsedQuery.GenerateQuery(bindVars)
 	if err != nil {
 		return "", NewTabletError(vtrpcpb.ErrorCode_BAD_INPUT, "%s", err)
 	}
+	if qre.actionRule != nil && qre.actionRule.act == QRAddHint {
+		if sql, err = addHint(sql, qre.actionRule.hint); err != nil {
+			return "", err
+		}
+	}
 	if buildStreamComment != nil {
 		sql = append(sql, buildStreamComment...)
 	}
@@ -636,19 +943,66 @@ func (qre *QueryExecutor) generateFinalSQL(parsedQuery *sqlparser.ParsedQuery, b
 
 // poolConn is an abstraction for reusing code in execSQL.
 type poolConn interface {
+	killableQuery
 	Exec(ctx context.Context, query string, maxrows int, wantfields bool) (*sqltypes.Result, error)
 }
 
 func (qre *QueryExecutor) execSQL(conn poolConn, sql string, wantfields bool) (*sqltypes.Result, error) {
 	defer qre.logStats.AddRewrittenSQL(sql, time.Now())
-	return conn.Exec(qre.ctx, sql, int(qre.qe.maxResultSize.Get()), wantfields)
+	done := qre.watchQuery(conn, sql)
+	qr, err := conn.Exec(qre.ctx, sql, int(qre.maxResultSize()), wantfields)
+	if killErr := killedQueryError(done()); err != nil && killErr != nil {
+		return nil, killErr
+	}
+	return qr, err
+}
+
+// watchQuery hands the query over to the query killer, if enabled.
+// The returned function must be called once the query returns.
+func (qre *QueryExecutor) watchQuery(conn killableQuery, sql string) func() *KilledQuery {
+	if qre.qe.queryKiller == nil {
+		return func() *KilledQuery { return nil }
+	}
+	return qre.qe.queryKiller.Watch(qre.ctx, conn, qre.plan.PlanID, qre.plan.TableName, sql, qre.logStats)
+}
+
+// maxResultSize returns the maximum number of rows the query can
+// return. A QRCapResult rule can lower it.
+func (qre *QueryExecutor) maxResultSize() int64 {
+	maxResultSize := qre.qe.maxResultSize.Get()
+	if qre.actionRule != nil && qre.actionRule.act == QRCapResult && int64(qre.actionRule.maxRows) < maxResultSize {
+		return int64(qre.actionRule.maxRows)
+	}
+	return maxResultSize
+}
+
+// addHint inserts the optimizer hint comment after the first keyword of
+// the statement. Statements that can't have optimizer hints are
+// returned unchanged. Hints that would close the comment are rejected.
+func addHint(sql []byte, hint string) ([]byte, error) {
+	if strings.Contains(hint, "*/") {
+		return nil, NewTabletError(vtrpcpb.ErrorCode_INTERNAL_ERROR, "Hint cannot contain '*/': %s", hint)
+	}
+	loc := hintedStatementRE.FindIndex(sql)
+	if loc == nil {
+		return sql, nil
+	}
+	result := make([]byte, 0, len(sql)+len(hint)+8)
+	result = append(result, sql[:loc[1]]...)
+	result = append(result, " /*+ "+hint+" */"...)
+	return append(result, sql[loc[1]:]...), nil
 }
 
 func (qre *QueryExecutor) execStreamSQL(conn *DBConn, sql string, excludeFieldNames bool, callback func(*sqltypes.Result) error) error {
 	start := time.Now()
+	done := qre.watchQuery(conn, sql)
 	err := conn.Stream(qre.ctx, sql, callback, int(qre.qe.streamBufferSize.Get()), excludeFieldNames)
+	killErr := killedQueryError(done())
 	qre.logStats.AddRewrittenSQL(sql, start)
 	if err != nil {
+		if killErr != nil {
+			return killErr
+		}
 		// MySQL error that isn't due to a connection issue
 		return NewTabletErrorSQL(vtrpcpb.ErrorCode_UNKNOWN_ERROR, err)
 	}
@@ -5,11 +5,13 @@
 package tabletserver
 
 import (
+	"errors"
 	"fmt"
 	"math/rand"
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	"golang.org/x/net/context"
 
@@ -422,6 +424,155 @@ func TestQueryExecutorPlanDmlAutoCommit(t *testing.T) {
 	}
 }
 
+func TestQueryExecutorPlanDmlPkHotRowProtection(t *testing.T) {
+	db := setUpQueryExecutorTest()
+	query := "update test_table set name = 2 where pk in (1) /* _stream test_table (pk ) (1 ); */"
+	db.AddQuery(query, &sqltypes.Result{})
+	ctx := context.Background()
+	tsv := newTestTabletServer(ctx, enableStrict|enableHotRowProtection, db)
+	defer tsv.StopService()
+
+	// The transaction holds the row until it's concluded.
+	txid := newTransaction(tsv)
+	qre := newTestQueryExecutor(ctx, tsv, query, txid)
+	if _, err := qre.Execute(); err != nil {
+		t.Fatalf("qre.Execute() = %v, want nil", err)
+	}
+	key := "test_table (1)"
+	if got := tsv.qe.txSerializer.Pending(key); got != 1 {
+		t.Errorf("Pending(%v) = %v, want 1", key, got)
+	}
+
+	// So the autocommit DML on the same row waits.
+	shortCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	qre = newTestQueryExecutor(shortCtx, tsv, query, 0)
+	_, err := qre.Execute()
+	if terr, ok := err.(*TabletError); !ok || terr.ErrorCode != vtrpcpb.ErrorCode_DEADLINE_EXCEEDED {
+		t.Fatalf("qre.Execute() = %v, want DEADLINE_EXCEEDED", err)
+	}
+
+	if err := tsv.Commit(ctx, &tsv.target, txid); err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+	if got := tsv.qe.txSerializer.Pending(key); got != 0 {
+		t.Errorf("Pending(%v) = %v, want 0", key, got)
+	}
+	qre = newTestQueryExecutor(ctx, tsv, query, 0)
+	if _, err := qre.Execute(); err != nil {
+		t.Fatalf("qre.Execute() = %v, want nil", err)
+	}
+}
+
+func TestQueryExecutorPlanDmlPkHotRowProtectionInTransaction(t *testing.T) {
+	db := setUpQueryExecutorTest()
+	query := "update test_table set name = 2 where pk in (1) /* _stream test_table (pk ) (1 ); */"
+	query2 := "update test_table set name = 2 where pk in (2) /* _stream test_table (pk ) (2 ); */"
+	db.AddQuery(query, &sqltypes.Result{})
+	db.AddQuery(query2, &sqltypes.Result{})
+	ctx := context.Background()
+	tsv := newTestTabletServer(ctx, enableStrict|enableHotRowProtection, db)
+	defer tsv.StopService()
+
+	txid1 := newTransaction(tsv)
+	if _, err := newTestQueryExecutor(ctx, tsv, query, txid1).Execute(); err != nil {
+		t.Fatalf("qre.Execute() = %v, want nil", err)
+	}
+
+	// The first DML of another transaction on the same row waits,
+	// with the connection the transaction got in Begin.
+	txid2 := newTransaction(tsv)
+	errc := make(chan error)
+	go func() {
+		_, err := newTestQueryExecutor(ctx, tsv, query, txid2).Execute()
+		errc <- err
+	}()
+	key := "test_table (1)"
+	for tsv.qe.txSerializer.Pending(key) != 2 {
+		time.Sleep(time.Millisecond)
+	}
+	if got, want := tsv.qe.txPool.pool.Available(), tsv.qe.txPool.pool.Capacity()-2; got != want {
+		t.Errorf("available transaction pool connections: %v, want %v", got, want)
+	}
+
+	if err := tsv.Commit(ctx, &tsv.target, txid1); err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+	if err := <-errc; err != nil {
+		t.Fatalf("qre.Execute() = %v, want nil", err)
+	}
+	if got := tsv.qe.txSerializer.Pending(key); got != 1 {
+		t.Errorf("Pending(%v) = %v, want 1", key, got)
+	}
+
+	// The next statements of the transaction are not queued.
+	if _, err := newTestQueryExecutor(ctx, tsv, query2, txid2).Execute(); err != nil {
+		t.Fatalf("qre.Execute() = %v, want nil", err)
+	}
+	key2 := "test_table (2)"
+	if got := tsv.qe.txSerializer.Pending(key2); got != 0 {
+		t.Errorf("Pending(%v) = %v, want 0", key2, got)
+	}
+
+	if err := tsv.Commit(ctx, &tsv.target, txid2); err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+	if got := tsv.qe.txSerializer.Pending(key); got != 0 {
+		t.Errorf("Pending(%v) = %v, want 0", key, got)
+	}
+}
+
+func TestQueryExecutorPlanDmlPkHotRowProtectionAfterSelectLock(t *testing.T) {
+	db := setUpQueryExecutorTest()
+	selQuery := "select * from test_table where pk = 2 limit 10001 for update"
+	query := "update test_table set name = 2 where pk in (1) /* _stream test_table (pk ) (1 ); */"
+	db.AddQuery(selQuery, &sqltypes.Result{
+		Fields: getTestTableFields(),
+		Rows:   [][]sqltypes.Value{},
+	})
+	db.AddQuery("select * from test_table where 1 != 1", &sqltypes.Result{
+		Fields: getTestTableFields(),
+	})
+	db.AddQuery(query, &sqltypes.Result{})
+	ctx := context.Background()
+	tsv := newTestTabletServer(ctx, enableStrict|enableHotRowProtection, db)
+	defer tsv.StopService()
+
+	txid1 := newTransaction(tsv)
+	if _, err := newTestQueryExecutor(ctx, tsv, query, txid1).Execute(); err != nil {
+		t.Fatalf("qre.Execute() = %v, want nil", err)
+	}
+
+	// The SELECT FOR UPDATE is the first statement of the transaction:
+	// the DML which follows may not be queued, since the transaction
+	// may hold row locks already.
+	txid2 := newTransaction(tsv)
+	qre := newTestQueryExecutor(ctx, tsv, "select * from test_table where pk = 2 for update", txid2)
+	checkPlanID(t, planbuilder.PlanSelectLock, qre.plan.PlanID)
+	if _, err := qre.Execute(); err != nil {
+		t.Fatalf("qre.Execute() = %v, want nil", err)
+	}
+	dmlCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	if _, err := newTestQueryExecutor(dmlCtx, tsv, query, txid2).Execute(); err != nil {
+		t.Errorf("qre.Execute() = %v, want nil", err)
+	}
+	key := "test_table (1)"
+	if got := tsv.qe.txSerializer.Pending(key); got != 1 {
+		t.Errorf("Pending(%v) = %v, want 1", key, got)
+	}
+
+	if err := tsv.Commit(ctx, &tsv.target, txid2); err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+	if err := tsv.Commit(ctx, &tsv.target, txid1); err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+	if got := tsv.qe.txSerializer.Pending(key); got != 0 {
+		t.Errorf("Pending(%v) = %v, want 0", key, got)
+	}
+}
+
 func TestQueryExecutorPlanDmlSubQuery(t *testing.T) {
 	db := setUpQueryExecutorTest()
 	query := "update test_table set addr = 3 where name = 1 limit 1000"
@@ -600,6 +751,62 @@ func TestQueryExecutorPlanPassSelect(t *testing.T) {
 	}
 }
 
+func TestQueryExecutorPlanPassSelectResultCache(t *testing.T) {
+	db := setUpQueryExecutorTest()
+	query := "select * from test_table where pk = 1 limit 1000"
+	want := &sqltypes.Result{
+		Fields: getTestTableFields(),
+		Rows:   [][]sqltypes.Value{},
+	}
+	db.AddQuery(query, want)
+	db.AddQuery("select * from test_table where 1 != 1", &sqltypes.Result{
+		Fields: getTestTableFields(),
+	})
+	dml := "update test_table set name = 2 where pk in (1) /* _stream test_table (pk ) (1 ); */"
+	db.AddQuery(dml, &sqltypes.Result{})
+	ctx := context.Background()
+	tsv := newTestTabletServer(ctx, enableStrict|enableResultCache, db)
+	defer tsv.StopService()
+
+	// The second select is served from the cache.
+	for i := 0; i < 2; i++ {
+		qre := newTestQueryExecutor(ctx, tsv, query, 0)
+		got, err := qre.Execute()
+		if err != nil {
+			t.Fatalf("qre.Execute() = %v, want nil", err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("got: %v, want: %v", got, want)
+		}
+	}
+	if got := db.GetQueryCalledNum(query); got != 1 {
+		t.Errorf("select sent %v times to MySQL, want 1", got)
+	}
+
+	// Until a DML changes the row.
+	qre := newTestQueryExecutor(ctx, tsv, dml, 0)
+	if _, err := qre.Execute(); err != nil {
+		t.Fatalf("qre.Execute() = %v, want nil", err)
+	}
+	qre = newTestQueryExecutor(ctx, tsv, query, 0)
+	if _, err := qre.Execute(); err != nil {
+		t.Fatalf("qre.Execute() = %v, want nil", err)
+	}
+	if got := db.GetQueryCalledNum(query); got != 2 {
+		t.Errorf("select sent %v times to MySQL, want 2", got)
+	}
+
+	// Or a schema change is found.
+	tsv.schemaChanged([]string{"test_table"})
+	qre = newTestQueryExecutor(ctx, tsv, query, 0)
+	if _, err := qre.Execute(); err != nil {
+		t.Fatalf("qre.Execute() = %v, want nil", err)
+	}
+	if got := db.GetQueryCalledNum(query); got != 3 {
+		t.Errorf("select sent %v times to MySQL, want 3", got)
+	}
+}
+
 func TestQueryExecutorPlanSet(t *testing.T) {
 	db := setUpQueryExecutorTest()
 	setQuery := "set unknown_key = 1"
@@ -810,6 +1017,80 @@ func TestQueryExecutorTableAclNoPermission(t *testing.T) {
 	}
 }
 
+func TestQueryExecutorTableAclColumnReadersAndRowFilters(t *testing.T) {
+	aclName := fmt.Sprintf("simpleacl-test-%d", rand.Int63())
+	tableacl.Register(aclName, &simpleacl.Factory{})
+	tableacl.SetDefaultACL(aclName)
+	db := setUpQueryExecutorTest()
+	fields := []*querypb.Field{{Name: "pk", Type: sqltypes.Int32}}
+	want := &sqltypes.Result{
+		Fields: fields,
+		Rows:   [][]sqltypes.Value{},
+	}
+	// Only the filtered query is known by the fake db.
+	db.AddQuery("select pk from test_table where (pk > 1) and (addr = 1) limit 1000", want)
+	db.AddQuery("select pk from test_table where 1 != 1", &sqltypes.Result{Fields: fields})
+	db.AddQuery("select pk, name from test_table where 1 != 1", &sqltypes.Result{Fields: getTestTableFields()[:2]})
+
+	username := "u2"
+	callerID := &querypb.VTGateCallerID{
+		Username: username,
+	}
+	ctx := callerid.NewContext(context.Background(), nil, callerID)
+	config := &tableaclpb.Config{
+		TableGroups: []*tableaclpb.TableGroupSpec{{
+			Name:                 "group01",
+			TableNamesOrPrefixes: []string{"test_table"},
+			Readers:              []string{"superuser"},
+			ColumnReaders: []*tableaclpb.ColumnReaders{{
+				Columns: []string{"pk", "addr"},
+				Readers: []string{username},
+			}},
+			RowFilters: []*tableaclpb.RowFilter{{
+				Predicate: "addr = 1",
+				Readers:   []string{username},
+			}},
+		}},
+	}
+	if err := tableacl.InitFromProto(config); err != nil {
+		t.Fatalf("unable to load tableacl config, error: %v", err)
+	}
+
+	tsv := newTestTabletServer(ctx, enableStrict|enableStrictTableAcl, db)
+	defer tsv.StopService()
+
+	// The user can read pk, and only the rows matching the filter.
+	qre := newTestQueryExecutor(ctx, tsv, "select pk from test_table where pk > 1 limit 1000", 0)
+	checkPlanID(t, planbuilder.PlanPassSelect, qre.plan.PlanID)
+	got, err := qre.Execute()
+	if err != nil {
+		t.Fatalf("got: %v, want nil", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("qre.Execute() = %v, want: %v", got, want)
+	}
+
+	// The user can't read name.
+	qre = newTestQueryExecutor(ctx, tsv, "select pk, name from test_table limit 1000", 0)
+	_, err = qre.Execute()
+	if tabletError, ok := err.(*TabletError); !ok || tabletError.ErrorCode != vtrpcpb.ErrorCode_PERMISSION_DENIED {
+		t.Fatalf("got: %v, want: PERMISSION_DENIED", err)
+	}
+
+	// The restrictions can't be enforced in joins and subqueries.
+	db.AddQuery("select a.pk from test_table as a, test_table as b where 1 != 1", &sqltypes.Result{Fields: fields})
+	for _, sql := range []string{
+		"select a.pk from test_table as a, test_table as b where a.pk = b.pk limit 1000",
+		"select pk from test_table where pk in (select pk from test_table) limit 1000",
+	} {
+		qre = newTestQueryExecutor(ctx, tsv, sql, 0)
+		_, err = qre.Execute()
+		if tabletError, ok := err.(*TabletError); !ok || tabletError.ErrorCode != vtrpcpb.ErrorCode_PERMISSION_DENIED {
+			t.Errorf("%v: got: %v, want: PERMISSION_DENIED", sql, err)
+		}
+	}
+}
+
 func TestQueryExecutorTableAclExemptACL(t *testing.T) {
 	aclName := fmt.Sprintf("simpleacl-test-%d", rand.Int63())
 	tableacl.Register(aclName, &simpleacl.Factory{})
@@ -1001,6 +1282,202 @@ func TestQueryExecutorBlacklistQRFail(t *testing.T) {
 	}
 }
 
+func TestQueryExecutorQueryRuleActions(t *testing.T) {
+	query := "select * from test_table where name = 1 limit 1000"
+	twoRows := &sqltypes.Result{
+		Fields: getTestTableFields(),
+		Rows: [][]sqltypes.Value{
+			{sqltypes.MakeTrusted(sqltypes.Int32, []byte("1")), sqltypes.MakeTrusted(sqltypes.Int32, []byte("1")), sqltypes.MakeTrusted(sqltypes.Int32, []byte("1"))},
+			{sqltypes.MakeTrusted(sqltypes.Int32, []byte("2")), sqltypes.MakeTrusted(sqltypes.Int32, []byte("1")), sqltypes.MakeTrusted(sqltypes.Int32, []byte("2"))},
+		},
+		RowsAffected: 2,
+	}
+	testcases := []struct {
+		name    string
+		setRule func(qr *QueryRule)
+		act     Action
+		// mysqlQuery is the query MySQL gets, if it's not the original one.
+		mysqlQuery string
+		timeout    time.Duration
+		wantErr    string
+	}{{
+		name:    "delay",
+		act:     QRDelay,
+		setRule: func(qr *QueryRule) { qr.SetDelay(10 * time.Millisecond) },
+	}, {
+		name:    "delay timeout",
+		act:     QRDelay,
+		setRule: func(qr *QueryRule) { qr.SetDelay(time.Hour) },
+		timeout: 10 * time.Millisecond,
+		wantErr: "Query delayed due to rule delay timeout: context deadline exceeded",
+	}, {
+		name:       "hint",
+		act:        QRAddHint,
+		setRule:    func(qr *QueryRule) { qr.SetHint("MAX_EXECUTION_TIME(1000)") },
+		mysqlQuery: "select /*+ MAX_EXECUTION_TIME(1000) */ * from test_table where name = 1 limit 1000",
+	}, {
+		name:    "hint closing the comment",
+		act:     QRAddHint,
+		setRule: func(qr *QueryRule) { qr.SetHint("BKA(t) */ drop table t /*") },
+		wantErr: "Hint cannot contain '*/'",
+	}, {
+		name:    "cap result",
+		act:     QRCapResult,
+		setRule: func(qr *QueryRule) { qr.SetMaxRows(1) },
+		wantErr: "row count exceeded 1",
+	}, {
+		name:    "stream",
+		act:     QRStream,
+		setRule: func(qr *QueryRule) {},
+	}}
+	for _, tc := range testcases {
+		db := setUpQueryExecutorTest()
+		mysqlQuery := query
+		if tc.mysqlQuery != "" {
+			mysqlQuery = tc.mysqlQuery
+		}
+		db.AddQuery(mysqlQuery, twoRows)
+		db.AddQuery("select * from test_table where 1 != 1", &sqltypes.Result{
+			Fields: getTestTableFields(),
+		})
+
+		qr := NewQueryRule(tc.name, tc.name, tc.act)
+		qr.SetQueryCond("select.*")
+		tc.setRule(qr)
+		rules := NewQueryRules()
+		rules.Add(qr)
+
+		ctx := callinfo.NewContext(context.Background(), &fakeCallInfo{remoteAddr: "127.0.0.1", username: "u1"})
+		tsv := newTestTabletServer(ctx, enableStrict, db)
+		rulesName := "queryRuleActions"
+		tsv.qe.schemaInfo.queryRuleSources.RegisterQueryRuleSource(rulesName)
+		if err := tsv.qe.schemaInfo.queryRuleSources.SetRules(rulesName, rules); err != nil {
+			t.Fatalf("failed to set rule, error: %v", err)
+		}
+		if tc.timeout != 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, tc.timeout)
+			defer cancel()
+		}
+		qre := newTestQueryExecutor(ctx, tsv, query, 0)
+		got, err := qre.Execute()
+		tsv.qe.schemaInfo.queryRuleSources.UnRegisterQueryRuleSource(rulesName)
+		tsv.StopService()
+
+		if tc.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("%v: qre.Execute() = %v, want error containing %q", tc.name, err, tc.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%v: qre.Execute() = %v, want nil", tc.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(got.Rows, twoRows.Rows) || got.RowsAffected != 2 {
+			t.Errorf("%v: got: %v, want: %v", tc.name, got, twoRows)
+		}
+	}
+}
+
+func TestCapResult(t *testing.T) {
+	var rows int
+	callback := capResult(3, func(r *sqltypes.Result) error {
+		rows += len(r.Rows)
+		return nil
+	})
+	twoRows := &sqltypes.Result{Rows: make([][]sqltypes.Value, 2)}
+	if err := callback(twoRows); err != nil {
+		t.Fatalf("callback() = %v, want nil", err)
+	}
+	if err := callback(twoRows); err == nil || !strings.HasPrefix(err.Error(), "Row count exceeded 3") {
+		t.Errorf("callback() = %v, want Row count exceeded 3", err)
+	}
+	if rows != 2 {
+		t.Errorf("rows sent: %v, want 2", rows)
+	}
+}
+
+func TestAddHint(t *testing.T) {
+	testcases := []struct {
+		in, want string
+	}{
+		{"select a from t", "select /*+ BKA(t) */ a from t"},
+		{"/* comment */ UPDATE t set a = 1", "/* comment */ UPDATE /*+ BKA(t) */ t set a = 1"},
+		{"selectx from t", "selectx from t"},
+		{"set autocommit = 1", "set autocommit = 1"},
+	}
+	for _, tc := range testcases {
+		got, err := addHint([]byte(tc.in), "BKA(t)")
+		if err != nil || string(got) != tc.want {
+			t.Errorf("addHint(%q) = %q, %v, want %q", tc.in, got, err, tc.want)
+		}
+	}
+	if _, err := addHint([]byte("select a from t"), "BKA(t) */ drop table t /*"); err == nil {
+		t.Errorf("addHint() with a hint closing the comment: nil error")
+	}
+}
+
+func TestQueryExecutorCapResultNotConsolidated(t *testing.T) {
+	db := setUpQueryExecutorTest()
+	query := "select * from test_table limit 1000"
+	want := &sqltypes.Result{
+		Fields: getTestTableFields(),
+		Rows: [][]sqltypes.Value{
+			{sqltypes.MakeTrusted(sqltypes.Int32, []byte("1"))},
+		},
+		RowsAffected: 1,
+	}
+	db.AddQuery(query, want)
+	db.AddQuery("select * from test_table where 1 != 1", &sqltypes.Result{
+		Fields: getTestTableFields(),
+	})
+
+	qr := NewQueryRule("cap result", "cap result", QRCapResult)
+	qr.SetQueryCond("select.*")
+	qr.SetMaxRows(1)
+	rules := NewQueryRules()
+	rules.Add(qr)
+
+	ctx := callinfo.NewContext(context.Background(), &fakeCallInfo{remoteAddr: "127.0.0.1", username: "u1"})
+	tsv := newTestTabletServer(ctx, enableStrict, db)
+	defer tsv.StopService()
+	rulesName := "queryRuleCapResult"
+	tsv.qe.schemaInfo.queryRuleSources.RegisterQueryRuleSource(rulesName)
+	defer tsv.qe.schemaInfo.queryRuleSources.UnRegisterQueryRuleSource(rulesName)
+	if err := tsv.qe.schemaInfo.queryRuleSources.SetRules(rulesName, rules); err != nil {
+		t.Fatalf("failed to set rule, error: %v", err)
+	}
+
+	// An uncapped execution of the same query is in flight: the capped
+	// one must not wait for, and share, its result.
+	q, created := tsv.qe.consolidator.Create(query)
+	if !created {
+		t.Fatalf("consolidator.Create() did not create the query")
+	}
+	defer q.Broadcast()
+	q.Err = errors.New("uncapped result")
+
+	done := make(chan struct{})
+	var got *sqltypes.Result
+	var err error
+	go func() {
+		defer close(done)
+		got, err = newTestQueryExecutor(ctx, tsv, query, 0).Execute()
+	}()
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatalf("capped query waited for the consolidated one")
+	}
+	if err != nil {
+		t.Fatalf("qre.Execute() = %v, want nil", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got: %v, want: %v", got, want)
+	}
+}
+
 func TestQueryExecutorBlacklistQRRetry(t *testing.T) {
 	db := setUpQueryExecutorTest()
 	query := "select * from test_table where name = 1 limit 1000"
@@ -1067,6 +1544,8 @@ const (
 	enableStrict               = 1 << iota
 	enableStrictTableAcl
 	smallTxPool
+	enableHotRowProtection
+	enableResultCache
 )
 
 // newTestQueryExecutor uses a package level variable testTabletServer defined in tabletserver_test.go
@@ -1095,6 +1574,14 @@ func newTestTabletServer(ctx context.Context, flags executorFlags, db *fakesqldb
 	} else {
 		config.StrictTableAcl = false
 	}
+	if flags&enableHotRowProtection > 0 {
+		config.EnableHotRowProtection = true
+		config.HotRowProtectionConcurrentTransactions = 1
+	}
+	if flags&enableResultCache > 0 {
+		config.EnableResultCache = true
+		config.ResultCacheTables = "test_table"
+	}
 	tsv := NewTabletServer(config)
 	testUtils := newTestUtils()
 	dbconfigs := testUtils.newDBConfigs(db)
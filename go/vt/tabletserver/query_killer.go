@@ -0,0 +1,242 @@
+// Copyright 2016, Google Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tabletserver
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/golang/glog"
+	"golang.org/x/net/context"
+
+	"github.com/youtube/vitess/go/vt/tabletserver/planbuilder"
+
+	vtrpcpb "github.com/youtube/vitess/go/vt/proto/vtrpc"
+)
+
+// This file contains the query killer. Where QueryTimeout is a single
+// limit for all the queries, enforced by cancelling their context, the
+// query killer applies time limits per plan type, per table and per
+// caller. A query that runs longer than its limit is killed with KILL
+// QUERY on a dba connection: MySQL interrupts the statement, and the
+// connection stays usable. The recently killed queries are kept, with
+// their LogStats, for /querykillz. In dry-run mode, the queries are
+// only logged and listed.
+
+const (
+	// QueryKillerKeyPlan sets a limit for a plan type, e.g. PASS_SELECT.
+	QueryKillerKeyPlan = "plan"
+	// QueryKillerKeyTable sets a limit for a table.
+	QueryKillerKeyTable = "table"
+	// QueryKillerKeyCaller sets a limit for a caller. The effective
+	// caller principal is used if set, the immediate caller username
+	// otherwise.
+	QueryKillerKeyCaller = "caller"
+
+	// maxKilledQueries is the number of killed queries we remember.
+	maxKilledQueries = 100
+)
+
+// queryKillerKeys is the order in which the limits are looked up:
+// the first one that matches the query wins.
+var queryKillerKeys = []string{QueryKillerKeyCaller, QueryKillerKeyTable, QueryKillerKeyPlan}
+
+// KilledQuery describes a query killed by the query killer.
+type KilledQuery struct {
+	// Limit is the limit that was exceeded, and LimitKey the
+	// <key>:<name> it was set for.
+	Limit    time.Duration
+	LimitKey string
+	// DryRun is true if the query was not actually killed.
+	DryRun bool
+	// ConnID is the MySQL connection id of the query.
+	ConnID int64
+	// SQL is the statement that was running in MySQL.
+	SQL string
+	// KillTime is when the limit was exceeded.
+	KillTime time.Time
+	// Err is set if KILL QUERY failed.
+	Err error
+	// LogStats is a snapshot of the LogStats of the request when it
+	// was killed.
+	LogStats LogStats
+}
+
+// killableQuery is a connection whose current query can be killed.
+type killableQuery interface {
+	ID() int64
+	KillQuery(reason string) error
+}
+
+// QueryKiller kills the queries that run for longer than their limit.
+type QueryKiller struct {
+	limits            map[string]time.Duration
+	dryRun            bool
+	queryServiceStats *QueryServiceStats
+
+	mu     sync.Mutex
+	killed []*KilledQuery
+}
+
+// NewQueryKiller creates a QueryKiller from the config.
+// It returns nil if the query killer is disabled.
+func NewQueryKiller(config Config, queryServiceStats *QueryServiceStats) (*QueryKiller, error) {
+	if !config.EnableQueryKiller {
+		return nil, nil
+	}
+	limits, err := parseQueryKillerLimits(config.QueryKillerLimits)
+	if err != nil {
+		return nil, err
+	}
+	if len(limits) == 0 {
+		return nil, fmt.Errorf("query killer is enabled but no limit is set")
+	}
+	return &QueryKiller{
+		limits:            limits,
+		dryRun:            config.QueryKillerDryRun,
+		queryServiceStats: queryServiceStats,
+	}, nil
+}
+
+// parseQueryKillerLimits parses a list of <key>:<name>=<duration>.
+func parseQueryKillerLimits(value string) (map[string]time.Duration, error) {
+	limits := make(map[string]time.Duration)
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		parts := strings.SplitN(entry, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid query killer limit %v: want <key>:<name>=<duration>", entry)
+		}
+		keyName := strings.SplitN(parts[0], ":", 2)
+		if len(keyName) != 2 || keyName[1] == "" {
+			return nil, fmt.Errorf("invalid query killer limit %v: want <key>:<name>=<duration>", entry)
+		}
+		switch keyName[0] {
+		case QueryKillerKeyPlan:
+			if _, ok := planbuilder.PlanByName(keyName[1]); !ok {
+				return nil, fmt.Errorf("invalid plan name in query killer limit %v", entry)
+			}
+		case QueryKillerKeyTable, QueryKillerKeyCaller:
+		default:
+			return nil, fmt.Errorf("invalid query killer key in %v: want one of %v", entry, queryKillerKeys)
+		}
+		limit, err := time.ParseDuration(parts[1])
+		if err != nil || limit <= 0 {
+			return nil, fmt.Errorf("invalid duration in query killer limit %v", entry)
+		}
+		limits[parts[0]] = limit
+	}
+	return limits, nil
+}
+
+// limit returns the limit of a query, and the <key>:<name> it was set
+// for. It returns 0 if the query has no limit.
+func (qk *QueryKiller) limit(ctx context.Context, planType planbuilder.PlanType, tableName string) (time.Duration, string) {
+	for _, key := range queryKillerKeys {
+		var name string
+		switch key {
+		case QueryKillerKeyCaller:
+			name = queryThrottlerKey(ctx, QueryThrottlerKeyCaller, "", "", nil)
+		case QueryKillerKeyTable:
+			name = tableName
+		case QueryKillerKeyPlan:
+			name = planType.String()
+		}
+		if name == "" {
+			continue
+		}
+		limitKey := key + ":" + name
+		if limit, ok := qk.limits[limitKey]; ok {
+			return limit, limitKey
+		}
+	}
+	return 0, ""
+}
+
+// Watch starts watching a query that is about to run on conn. The
+// returned function must be called once the query returns. It returns
+// the KilledQuery if the query was killed, nil otherwise.
+func (qk *QueryKiller) Watch(ctx context.Context, conn killableQuery, planType planbuilder.PlanType, tableName, sql string, logStats *LogStats) func() *KilledQuery {
+	limit, limitKey := qk.limit(ctx, planType, tableName)
+	if limit == 0 {
+		return func() *KilledQuery { return nil }
+	}
+
+	// The kill happens while holding mu, and done() takes it: the
+	// connection cannot be reused by another query before the kill
+	// is over.
+	var mu sync.Mutex
+	finished := false
+	var killed *KilledQuery
+	timer := time.AfterFunc(limit, func() {
+		mu.Lock()
+		defer mu.Unlock()
+		if finished {
+			return
+		}
+		killed = qk.kill(conn, limit, limitKey, sql, logStats)
+	})
+	return func() *KilledQuery {
+		timer.Stop()
+		mu.Lock()
+		defer mu.Unlock()
+		finished = true
+		return killed
+	}
+}
+
+// kill kills the query, unless in dry-run mode, and remembers it.
+func (qk *QueryKiller) kill(conn killableQuery, limit time.Duration, limitKey, sql string, logStats *LogStats) *KilledQuery {
+	kq := &KilledQuery{
+		Limit:    limit,
+		LimitKey: limitKey,
+		DryRun:   qk.dryRun,
+		ConnID:   conn.ID(),
+		SQL:      sql,
+		KillTime: time.Now(),
+		LogStats: *logStats,
+	}
+	kq.LogStats.EndTime = kq.KillTime
+	if qk.dryRun {
+		qk.queryServiceStats.KillStats.Add("QueryKillerDryRun", 1)
+		log.Infof("Query killer dry run: query %s exceeded its %v limit of %v", sql, limitKey, limit)
+	} else {
+		qk.queryServiceStats.KillStats.Add("QueryKiller", 1)
+		kq.Err = conn.KillQuery(fmt.Sprintf("query killer (%v limit of %v exceeded)", limitKey, limit))
+	}
+
+	qk.mu.Lock()
+	defer qk.mu.Unlock()
+	qk.killed = append(qk.killed, kq)
+	if len(qk.killed) > maxKilledQueries {
+		qk.killed = qk.killed[len(qk.killed)-maxKilledQueries:]
+	}
+	return kq
+}
+
+// KilledQueries returns the recently killed queries, most recent first.
+func (qk *QueryKiller) KilledQueries() []*KilledQuery {
+	qk.mu.Lock()
+	defer qk.mu.Unlock()
+	result := make([]*KilledQuery, 0, len(qk.killed))
+	for i := len(qk.killed) - 1; i >= 0; i-- {
+		result = append(result, qk.killed[i])
+	}
+	return result
+}
+
+// killedQueryError returns the error of a query that was killed by the
+// query killer, or nil if it was not.
+func killedQueryError(kq *KilledQuery) error {
+	if kq == nil || kq.DryRun || kq.Err != nil {
+		return nil
+	}
+	return NewTabletError(vtrpcpb.ErrorCode_DEADLINE_EXCEEDED, "query killed: it exceeded its %v limit of %v", kq.LimitKey, kq.Limit)
+}
@@ -0,0 +1,210 @@
+// Copyright 2016, Google Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tabletserver
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/youtube/vitess/go/vt/callerid"
+	"github.com/youtube/vitess/go/vt/tabletserver/planbuilder"
+)
+
+// killQueryConn is a killableQuery that records the kills.
+type killQueryConn struct {
+	id    int64
+	kills []string
+}
+
+func (c *killQueryConn) ID() int64 { return c.id }
+
+func (c *killQueryConn) KillQuery(reason string) error {
+	c.kills = append(c.kills, reason)
+	return nil
+}
+
+func newTestQueryKiller(t *testing.T, limits string, dryRun bool) *QueryKiller {
+	config := DefaultQsConfig
+	config.EnableQueryKiller = true
+	config.QueryKillerLimits = limits
+	config.QueryKillerDryRun = dryRun
+	qk, err := NewQueryKiller(config, NewQueryServiceStats("", false))
+	if err != nil {
+		t.Fatalf("NewQueryKiller failed: %v", err)
+	}
+	return qk
+}
+
+func TestNewQueryKiller(t *testing.T) {
+	config := DefaultQsConfig
+	if qk, err := NewQueryKiller(config, NewQueryServiceStats("", false)); qk != nil || err != nil {
+		t.Errorf("NewQueryKiller when disabled: %v, %v, want nil, nil", qk, err)
+	}
+	config.EnableQueryKiller = true
+	for _, limits := range []string{
+		"",
+		"plan:PASS_SELECT",
+		"PASS_SELECT=1s",
+		"plan:NOT_A_PLAN=1s",
+		"column:a=1s",
+		"table:a=1",
+		"table:a=-1s",
+		"table:=1s",
+	} {
+		config.QueryKillerLimits = limits
+		if _, err := NewQueryKiller(config, NewQueryServiceStats("", false)); err == nil {
+			t.Errorf("NewQueryKiller(%q) should have failed", limits)
+		}
+	}
+	config.QueryKillerLimits = "plan:PASS_SELECT=10s, table:t1=1m,caller:batch=10m"
+	qk, err := NewQueryKiller(config, NewQueryServiceStats("", false))
+	if err != nil {
+		t.Fatalf("NewQueryKiller failed: %v", err)
+	}
+	if len(qk.limits) != 3 || qk.limits["table:t1"] != time.Minute {
+		t.Errorf("unexpected limits: %v", qk.limits)
+	}
+}
+
+func TestQueryKillerLimit(t *testing.T) {
+	qk := newTestQueryKiller(t, "plan:PASS_SELECT=10s,table:t1=1m,caller:batch=10m", false)
+	batchCtx := callerid.NewContext(context.Background(), callerid.NewEffectiveCallerID("batch", "", ""), callerid.NewImmediateCallerID("app"))
+	testcases := []struct {
+		ctx       context.Context
+		planType  planbuilder.PlanType
+		tableName string
+		limit     time.Duration
+		limitKey  string
+	}{{
+		ctx:       context.Background(),
+		planType:  planbuilder.PlanPassSelect,
+		tableName: "t2",
+		limit:     10 * time.Second,
+		limitKey:  "plan:PASS_SELECT",
+	}, {
+		ctx:       context.Background(),
+		planType:  planbuilder.PlanPassSelect,
+		tableName: "t1",
+		limit:     time.Minute,
+		limitKey:  "table:t1",
+	}, {
+		ctx:       batchCtx,
+		planType:  planbuilder.PlanPassSelect,
+		tableName: "t1",
+		limit:     10 * time.Minute,
+		limitKey:  "caller:batch",
+	}, {
+		ctx:       context.Background(),
+		planType:  planbuilder.PlanPassDML,
+		tableName: "t2",
+	}}
+	for _, tc := range testcases {
+		limit, limitKey := qk.limit(tc.ctx, tc.planType, tc.tableName)
+		if limit != tc.limit || limitKey != tc.limitKey {
+			t.Errorf("limit(%v, %v): %v %v, want %v %v", tc.planType, tc.tableName, limit, limitKey, tc.limit, tc.limitKey)
+		}
+	}
+}
+
+func TestQueryKillerWatch(t *testing.T) {
+	qk := newTestQueryKiller(t, "table:t1=10ms", false)
+	ctx := context.Background()
+	logStats := newLogStats("Execute", ctx)
+	logStats.OriginalSQL = "select * from t1"
+
+	// A fast query is not killed.
+	conn := &killQueryConn{id: 1}
+	done := qk.Watch(ctx, conn, planbuilder.PlanPassSelect, "t1", "select * from t1 limit 10001", logStats)
+	if kq := done(); kq != nil || len(conn.kills) != 0 {
+		t.Errorf("fast query was killed: %v, %v", kq, conn.kills)
+	}
+
+	// A query without a limit is not killed.
+	done = qk.Watch(ctx, conn, planbuilder.PlanPassSelect, "t2", "select * from t2 limit 10001", logStats)
+	time.Sleep(50 * time.Millisecond)
+	if kq := done(); kq != nil || len(conn.kills) != 0 {
+		t.Errorf("query without limit was killed: %v, %v", kq, conn.kills)
+	}
+
+	// A slow query is killed.
+	done = qk.Watch(ctx, conn, planbuilder.PlanPassSelect, "t1", "select * from t1 limit 10001", logStats)
+	time.Sleep(50 * time.Millisecond)
+	kq := done()
+	if kq == nil || len(conn.kills) != 1 {
+		t.Fatalf("slow query was not killed: %v, %v", kq, conn.kills)
+	}
+	if kq.LimitKey != "table:t1" || kq.ConnID != 1 || kq.DryRun || kq.LogStats.OriginalSQL != "select * from t1" {
+		t.Errorf("unexpected KilledQuery: %+v", kq)
+	}
+	if err := killedQueryError(kq); err == nil || !strings.Contains(err.Error(), "exceeded its table:t1 limit of 10ms") {
+		t.Errorf("killedQueryError: %v", err)
+	}
+	if got := qk.queryServiceStats.KillStats.Counts()["QueryKiller"]; got != 1 {
+		t.Errorf("QueryKiller kills: %v, want 1", got)
+	}
+
+	// Old kills are forgotten, the most recent one comes first.
+	for i := 0; i < maxKilledQueries+5; i++ {
+		qk.kill(&killQueryConn{id: int64(i + 2)}, time.Second, "table:t1", "select 1", logStats)
+	}
+	killed := qk.KilledQueries()
+	if len(killed) != maxKilledQueries || killed[0].ConnID != maxKilledQueries+6 {
+		t.Errorf("KilledQueries: %v entries, first one for conn %v", len(killed), killed[0].ConnID)
+	}
+}
+
+func TestQueryKillerDryRun(t *testing.T) {
+	qk := newTestQueryKiller(t, "plan:PASS_SELECT=10ms", true)
+	ctx := context.Background()
+	conn := &killQueryConn{id: 1}
+	done := qk.Watch(ctx, conn, planbuilder.PlanPassSelect, "t1", "select * from t1", newLogStats("Execute", ctx))
+	time.Sleep(50 * time.Millisecond)
+	kq := done()
+	if kq == nil || !kq.DryRun || len(conn.kills) != 0 {
+		t.Fatalf("dry run: %v, %v", kq, conn.kills)
+	}
+	if err := killedQueryError(kq); err != nil {
+		t.Errorf("killedQueryError in dry run: %v", err)
+	}
+	if got := qk.queryServiceStats.KillStats.Counts()["QueryKillerDryRun"]; got != 1 {
+		t.Errorf("QueryKillerDryRun kills: %v, want 1", got)
+	}
+}
+
+func TestQueryKillzHandler(t *testing.T) {
+	qk := newTestQueryKiller(t, "plan:PASS_SELECT=10ms", false)
+	logStats := newLogStats("Execute", context.Background())
+	logStats.OriginalSQL = "select * from t1"
+	logStats.PlanType = "PASS_SELECT"
+	qk.kill(&killQueryConn{id: 12}, 10*time.Millisecond, "plan:PASS_SELECT", "select * from t1 limit 10001", logStats)
+
+	resp := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/querykillz", nil)
+	querykillzHandler(qk, resp, req)
+	body := resp.Body.String()
+	for _, want := range []string{"plan:PASS_SELECT 10ms", "<td>12</td>", "PASS_SELECT"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("querykillz should contain %q, got %v", want, body)
+		}
+	}
+
+	resp = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/querykillz?format=json", nil)
+	querykillzHandler(qk, resp, req)
+	if body := resp.Body.String(); !strings.Contains(body, `"ConnID":12`) {
+		t.Errorf("querykillz json: %v", body)
+	}
+
+	resp = httptest.NewRecorder()
+	querykillzHandler(nil, resp, req)
+	if body := resp.Body.String(); !strings.Contains(body, "disabled") {
+		t.Errorf("querykillz for a disabled query killer: %v", body)
+	}
+}
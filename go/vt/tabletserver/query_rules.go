@@ -10,6 +10,8 @@ import (
 	"fmt"
 	"regexp"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/youtube/vitess/go/vt/key"
 	"github.com/youtube/vitess/go/vt/tabletserver/planbuilder"
@@ -134,12 +136,21 @@ func (qrs *QueryRules) filterByPlan(query string, planid planbuilder.PlanType, t
 }
 
 func (qrs *QueryRules) getAction(ip, user string, bindVars map[string]interface{}) (action Action, desc string) {
+	if qr := qrs.getRule(ip, user, bindVars); qr != nil {
+		return qr.act, qr.Description
+	}
+	return QRContinue, ""
+}
+
+// getRule returns the first rule that fires, nil if none does. The
+// parameters of its action are in the rule.
+func (qrs *QueryRules) getRule(ip, user string, bindVars map[string]interface{}) *QueryRule {
 	for _, qr := range qrs.rules {
 		if act := qr.getAction(ip, user, bindVars); act != QRContinue {
-			return act, qr.Description
+			return qr
 		}
 	}
-	return QRContinue, ""
+	return nil
 }
 
 //-----------------------------------------------
@@ -172,6 +183,12 @@ type QueryRule struct {
 
 	// Action to be performed on trigger
 	act Action
+
+	// Parameters of the action: how long QRDelay waits, the optimizer
+	// hint QRAddHint adds, and the number of rows QRCapResult allows.
+	delay   time.Duration
+	hint    string
+	maxRows int
 }
 
 type namedRegexp struct {
@@ -199,6 +216,9 @@ func (qr *QueryRule) Copy() (newqr *QueryRule) {
 		user:        qr.user,
 		query:       qr.query,
 		act:         qr.act,
+		delay:       qr.delay,
+		hint:        qr.hint,
+		maxRows:     qr.maxRows,
 	}
 	if qr.plans != nil {
 		newqr.plans = make([]planbuilder.PlanType, len(qr.plans))
@@ -241,10 +261,37 @@ func (qr *QueryRule) MarshalJSON() ([]byte, error) {
 	if qr.act != QRContinue {
 		safeEncode(b, `,"Action":`, qr.act)
 	}
+	if qr.delay != 0 {
+		safeEncode(b, `,"Delay":`, qr.delay.String())
+	}
+	if qr.hint != "" {
+		safeEncode(b, `,"Hint":`, qr.hint)
+	}
+	if qr.maxRows != 0 {
+		safeEncode(b, `,"MaxRows":`, qr.maxRows)
+	}
 	_, _ = b.WriteString("}")
 	return b.Bytes(), nil
 }
 
+// SetDelay sets how long the query waits before it runs, for QRDelay.
+func (qr *QueryRule) SetDelay(delay time.Duration) {
+	qr.delay = delay
+}
+
+// SetHint sets the optimizer hint added to the query, for QRAddHint.
+// It's the content of the hint comment, e.g. "MAX_EXECUTION_TIME(1000)".
+// Queries matching a rule whose hint contains "*/" fail.
+func (qr *QueryRule) SetHint(hint string) {
+	qr.hint = hint
+}
+
+// SetMaxRows sets the maximum number of rows the query can return,
+// for QRCapResult. Queries returning more rows fail.
+func (qr *QueryRule) SetMaxRows(maxRows int) {
+	qr.maxRows = maxRows
+}
+
 // SetIPCond adds a regular expression condition for the client IP.
 // It has to be a full match (not substring).
 func (qr *QueryRule) SetIPCond(pattern string) (err error) {
@@ -434,23 +481,36 @@ func bvMatch(bvcond BindVarCond, bindVars map[string]interface{}) bool {
 // when a QueryRule is triggered.
 type Action int
 
-// These are actions.
+// These are actions. Unlike QRFail and QRFailRetry, the other actions
+// let the query run, after changing how it runs.
 const (
 	QRContinue = Action(iota)
 	QRFail
 	QRFailRetry
+	// QRDelay makes the query wait before it runs.
+	QRDelay
+	// QRAddHint adds an optimizer hint to the query.
+	QRAddHint
+	// QRCapResult lowers the maximum result size of the query.
+	QRCapResult
+	// QRStream runs a select on the streaming pool, instead of the
+	// regular connection pool.
+	QRStream
 )
 
+var actionNames = map[Action]string{
+	QRFail:      "FAIL",
+	QRFailRetry: "FAIL_RETRY",
+	QRDelay:     "DELAY",
+	QRAddHint:   "ADD_HINT",
+	QRCapResult: "CAP_RESULT",
+	QRStream:    "STREAM",
+}
+
 // MarshalJSON marshals to JSON.
 func (act Action) MarshalJSON() ([]byte, error) {
-	// If we add more actions, we'll need to use a map.
-	var str string
-	switch act {
-	case QRFail:
-		str = "FAIL"
-	case QRFailRetry:
-		str = "FAIL_RETRY"
-	default:
+	str, ok := actionNames[act]
+	if !ok {
 		str = "INVALID"
 	}
 	return json.Marshal(str)
@@ -812,7 +872,7 @@ func BuildQueryRule(ruleInfo map[string]interface{}) (qr *QueryRule, err error)
 		var lv []interface{}
 		var ok bool
 		switch k {
-		case "Name", "Description", "RequestIP", "User", "Query", "Action":
+		case "Name", "Description", "RequestIP", "User", "Query", "Action", "Delay", "Hint":
 			sv, ok = v.(string)
 			if !ok {
 				return nil, NewTabletError(vtrpcpb.ErrorCode_INTERNAL_ERROR, "want string for %s", k)
@@ -822,6 +882,7 @@ func BuildQueryRule(ruleInfo map[string]interface{}) (qr *QueryRule, err error)
 			if !ok {
 				return nil, NewTabletError(vtrpcpb.ErrorCode_INTERNAL_ERROR, "want list for %s", k)
 			}
+		case "MaxRows":
 		default:
 			return nil, NewTabletError(vtrpcpb.ErrorCode_INTERNAL_ERROR, "unrecognized tag %s", k)
 		}
@@ -877,19 +938,58 @@ func BuildQueryRule(ruleInfo map[string]interface{}) (qr *QueryRule, err error)
 				}
 			}
 		case "Action":
-			switch sv {
-			case "FAIL":
-				qr.act = QRFail
-			case "FAIL_RETRY":
-				qr.act = QRFailRetry
-			default:
+			qr.act = QRContinue
+			for act, name := range actionNames {
+				if name == sv {
+					qr.act = act
+				}
+			}
+			if qr.act == QRContinue {
 				return nil, NewTabletError(vtrpcpb.ErrorCode_INTERNAL_ERROR, "invalid Action %s", sv)
 			}
+		case "Delay":
+			qr.delay, err = time.ParseDuration(sv)
+			if err != nil || qr.delay <= 0 {
+				return nil, NewTabletError(vtrpcpb.ErrorCode_INTERNAL_ERROR, "want a positive duration for Delay: %s", sv)
+			}
+		case "Hint":
+			qr.hint = sv
+		case "MaxRows":
+			nv, ok := v.(json.Number)
+			if !ok {
+				return nil, NewTabletError(vtrpcpb.ErrorCode_INTERNAL_ERROR, "want number for MaxRows")
+			}
+			maxRows, err := nv.Int64()
+			if err != nil || maxRows <= 0 {
+				return nil, NewTabletError(vtrpcpb.ErrorCode_INTERNAL_ERROR, "want a positive integer for MaxRows: %s", string(nv))
+			}
+			qr.maxRows = int(maxRows)
 		}
 	}
+	if err := qr.checkActionParams(); err != nil {
+		return nil, err
+	}
 	return qr, nil
 }
 
+// checkActionParams checks the rule has the parameters its action
+// needs, and no others.
+func (qr *QueryRule) checkActionParams() error {
+	if (qr.act == QRDelay) != (qr.delay != 0) {
+		return NewTabletError(vtrpcpb.ErrorCode_INTERNAL_ERROR, "Delay is required by, and only valid for, the DELAY action")
+	}
+	if (qr.act == QRAddHint) != (qr.hint != "") {
+		return NewTabletError(vtrpcpb.ErrorCode_INTERNAL_ERROR, "Hint is required by, and only valid for, the ADD_HINT action")
+	}
+	if strings.Contains(qr.hint, "*/") {
+		return NewTabletError(vtrpcpb.ErrorCode_INTERNAL_ERROR, "Hint cannot contain '*/': %s", qr.hint)
+	}
+	if (qr.act == QRCapResult) != (qr.maxRows != 0) {
+		return NewTabletError(vtrpcpb.ErrorCode_INTERNAL_ERROR, "MaxRows is required by, and only valid for, the CAP_RESULT action")
+	}
+	return nil
+}
+
 func buildBindVarCondition(bvc interface{}) (name string, onAbsent, onMismatch bool, op Operator, value interface{}, err error) {
 	bvcinfo, ok := bvc.(map[string]interface{})
 	if !ok {
@@ -11,6 +11,7 @@ import (
 	"regexp"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/youtube/vitess/go/vt/key"
 	"github.com/youtube/vitess/go/vt/tabletserver/planbuilder"
@@ -722,6 +723,15 @@ var invalidjsons = []InvalidJSONCase{
 	{`[{"BindVarConds": [{"Name": "a", "OnAbsent": true, "OnMismatch": true, "Operator": "NOMATCH", "Value": "["}]}]`, "processing [: error parsing regexp: missing closing ]: `[$`"},
 	{`[{"Action": 1 }]`, "want string for Action"},
 	{`[{"Action": "foo" }]`, "invalid Action foo"},
+	{`[{"Action": "DELAY" }]`, "Delay is required by, and only valid for, the DELAY action"},
+	{`[{"Action": "FAIL", "Delay": "1s" }]`, "Delay is required by, and only valid for, the DELAY action"},
+	{`[{"Action": "DELAY", "Delay": "soon" }]`, "want a positive duration for Delay: soon"},
+	{`[{"Action": "ADD_HINT" }]`, "Hint is required by, and only valid for, the ADD_HINT action"},
+	{`[{"Action": "ADD_HINT", "Hint": "BKA(t) */ drop table t /*" }]`, "Hint cannot contain '*/': BKA(t) */ drop table t /*"},
+	{`[{"Action": "CAP_RESULT" }]`, "MaxRows is required by, and only valid for, the CAP_RESULT action"},
+	{`[{"Action": "CAP_RESULT", "MaxRows": "10" }]`, "want number for MaxRows"},
+	{`[{"Action": "CAP_RESULT", "MaxRows": 0 }]`, "want a positive integer for MaxRows: 0"},
+	{`[{"Action": "STREAM", "MaxRows": 10 }]`, "MaxRows is required by, and only valid for, the CAP_RESULT action"},
 }
 
 func TestInvalidJSON(t *testing.T) {
@@ -763,6 +773,46 @@ func TestBuildQueryRuleActionFail(t *testing.T) {
 	}
 }
 
+func TestBuildQueryRuleActionParams(t *testing.T) {
+	testcases := []struct {
+		input   string
+		act     Action
+		delay   time.Duration
+		hint    string
+		maxRows int
+	}{
+		{`[{"Action": "DELAY", "Delay": "150ms"}]`, QRDelay, 150 * time.Millisecond, "", 0},
+		{`[{"Action": "ADD_HINT", "Hint": "MAX_EXECUTION_TIME(1000)"}]`, QRAddHint, 0, "MAX_EXECUTION_TIME(1000)", 0},
+		{`[{"Action": "CAP_RESULT", "MaxRows": 100}]`, QRCapResult, 0, "", 100},
+		{`[{"Action": "STREAM"}]`, QRStream, 0, "", 0},
+	}
+	for _, tc := range testcases {
+		qrs := NewQueryRules()
+		if err := qrs.UnmarshalJSON([]byte(tc.input)); err != nil {
+			t.Errorf("UnmarshalJSON(%v) failed: %v", tc.input, err)
+			continue
+		}
+		qr := qrs.rules[0]
+		if qr.act != tc.act || qr.delay != tc.delay || qr.hint != tc.hint || qr.maxRows != tc.maxRows {
+			t.Errorf("UnmarshalJSON(%v) = %v %v %q %v, want %v %v %q %v", tc.input, qr.act, qr.delay, qr.hint, qr.maxRows, tc.act, tc.delay, tc.hint, tc.maxRows)
+		}
+
+		// The rule must survive a round trip through JSON.
+		data, err := json.Marshal(qrs)
+		if err != nil {
+			t.Fatalf("json.Marshal failed: %v", err)
+		}
+		qrs2 := NewQueryRules()
+		if err := qrs2.UnmarshalJSON(data); err != nil {
+			t.Errorf("UnmarshalJSON(%s) failed: %v", data, err)
+			continue
+		}
+		if !reflect.DeepEqual(qrs2.rules[0], qr) {
+			t.Errorf("round trip of %v: %v, want %v", tc.input, qrs2.rules[0], qr)
+		}
+	}
+}
+
 func TestBuildQueryRuleFailureModes(t *testing.T) {
 	var err error
 	var errStr string
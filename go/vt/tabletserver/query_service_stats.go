@@ -38,6 +38,21 @@ type QueryServiceStats struct {
 	QPSRates *stats.Rates
 	// ResultStats shows the histogram of number of rows returned.
 	ResultStats *stats.Histogram
+	// ThrottledQueries shows the number of queries rejected by the
+	// query throttler, for each throttler key.
+	ThrottledQueries *stats.MultiCounters
+	// HotRowWaits shows the number of transactions that waited for
+	// other transactions on the same row, per table.
+	HotRowWaits *stats.Counters
+	// HotRowQueueExceeded shows the number of transactions rejected
+	// because too many transactions were queued for the same row, per
+	// table.
+	HotRowQueueExceeded *stats.Counters
+	// ResultCacheHits, ResultCacheMisses and ResultCacheInvalidations
+	// show the activity of the result cache, per table.
+	ResultCacheHits          *stats.Counters
+	ResultCacheMisses        *stats.Counters
+	ResultCacheInvalidations *stats.Counters
 }
 
 // NewQueryServiceStats returns a new QueryServiceStats instance.
@@ -55,6 +70,12 @@ func NewQueryServiceStats(statsPrefix string, enablePublishStats bool) *QuerySer
 	userTableQueryTimesNsName := ""
 	userTransactionCountName := ""
 	userTransactionTimesNsName := ""
+	throttledQueriesName := ""
+	hotRowWaitsName := ""
+	hotRowQueueExceededName := ""
+	resultCacheHitsName := ""
+	resultCacheMissesName := ""
+	resultCacheInvalidationsName := ""
 	if enablePublishStats {
 		mysqlStatsName = statsPrefix + "Mysql"
 		queryStatsName = statsPrefix + "Queries"
@@ -69,6 +90,12 @@ func NewQueryServiceStats(statsPrefix string, enablePublishStats bool) *QuerySer
 		userTableQueryTimesNsName = statsPrefix + "UserTableQueryTimesNs"
 		userTransactionCountName = statsPrefix + "UserTransactionCount"
 		userTransactionTimesNsName = statsPrefix + "UserTransactionTimesNs"
+		throttledQueriesName = statsPrefix + "ThrottledQueries"
+		hotRowWaitsName = statsPrefix + "HotRowWaits"
+		hotRowQueueExceededName = statsPrefix + "HotRowQueueExceeded"
+		resultCacheHitsName = statsPrefix + "ResultCacheHits"
+		resultCacheMissesName = statsPrefix + "ResultCacheMisses"
+		resultCacheInvalidationsName = statsPrefix + "ResultCacheInvalidations"
 	}
 	resultBuckets := []int64{0, 1, 5, 10, 50, 100, 500, 1000, 5000, 10000}
 	queryStats := stats.NewTimings(queryStatsName)
@@ -76,7 +103,7 @@ func NewQueryServiceStats(statsPrefix string, enablePublishStats bool) *QuerySer
 		MySQLStats: stats.NewTimings(mysqlStatsName),
 		QueryStats: queryStats,
 		WaitStats:  stats.NewTimings(waitStatsName),
-		KillStats:  stats.NewCounters(killStatsName, "Transactions", "Queries"),
+		KillStats:  stats.NewCounters(killStatsName, "Transactions", "Queries", "QueryKiller", "QueryKillerDryRun", "StreamSendTimeout"),
 		InfoErrors: stats.NewCounters(infoErrorsName, "Retry", "Fatal", "DupKey"),
 		ErrorStats: stats.NewCounters(errorStatsName, "Fail", "TxPoolFull", "NotInTx", "Deadlock"),
 		InternalErrors: stats.NewCounters(internalErrorsName, "Task",
@@ -92,5 +119,12 @@ func NewQueryServiceStats(statsPrefix string, enablePublishStats bool) *QuerySer
 		// Sample every 5 seconds and keep samples for up to 15 minutes.
 		QPSRates:    stats.NewRates(qpsRateName, queryStats, 15*60/5, 5*time.Second),
 		ResultStats: stats.NewHistogram(resultStatsName, resultBuckets),
+		ThrottledQueries: stats.NewMultiCounters(
+			throttledQueriesName, []string{"KeyType", "Key"}),
+		HotRowWaits:              stats.NewCounters(hotRowWaitsName),
+		HotRowQueueExceeded:      stats.NewCounters(hotRowQueueExceededName),
+		ResultCacheHits:          stats.NewCounters(resultCacheHitsName),
+		ResultCacheMisses:        stats.NewCounters(resultCacheMissesName),
+		ResultCacheInvalidations: stats.NewCounters(resultCacheInvalidationsName),
 	}
 }
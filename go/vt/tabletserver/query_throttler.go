@@ -0,0 +1,272 @@
+// Copyright 2016, Google Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tabletserver
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/golang/glog"
+	"golang.org/x/net/context"
+
+	"github.com/youtube/vitess/go/vt/callerid"
+	"github.com/youtube/vitess/go/vt/discovery"
+	"github.com/youtube/vitess/go/vt/throttler"
+
+	topodatapb "github.com/youtube/vitess/go/vt/proto/topodata"
+	vtrpcpb "github.com/youtube/vitess/go/vt/proto/vtrpc"
+)
+
+// This file contains the query throttler. It paces the DMLs and the
+// streaming queries served by this tablet, per table, per caller or
+// per workload (tagged in a query comment), using the adaptive logic
+// of go/vt/throttler. Each key gets its own throttler, registered in
+// throttler.GlobalManager, so they can be inspected on /throttlerz and
+// configured with the throttlerdata RPCs. Queries that are over budget
+// fail with a retryable TRANSIENT_ERROR.
+
+const (
+	// QueryThrottlerKeyTable throttles queries per table.
+	QueryThrottlerKeyTable = "table"
+	// QueryThrottlerKeyCaller throttles queries per caller.
+	// The effective caller principal is used if set, the immediate
+	// caller username otherwise.
+	QueryThrottlerKeyCaller = "caller"
+	// QueryThrottlerKeyWorkload throttles queries per workload, as
+	// tagged by a 'workload=<name>' query comment.
+	QueryThrottlerKeyWorkload = "workload"
+
+	// queryThrottlerTemplateName is the name of the throttler whose
+	// max rate and configuration are copied when a throttler is
+	// created for a new key. It doesn't throttle anything by itself.
+	queryThrottlerTemplateName = "QueryThrottler"
+
+	// maxQueryThrottlers caps the number of keys we track. Queries for
+	// new keys above that number share one throttler per key type.
+	maxQueryThrottlers = 1000
+	// queryThrottlerOtherKey is the key reported in the stats for the
+	// queries throttled by the shared throttler of a key type.
+	queryThrottlerOtherKey = "(other)"
+)
+
+var (
+	commentRE  = regexp.MustCompile(`(?s)/\*(.*?)\*/`)
+	workloadRE = regexp.MustCompile(`(?i)\bworkload\s*=\s*([\w.-]+)`)
+)
+
+// keyedThrottler is the throttler of one key. Throttler.Throttle() calls
+// must be serialized, hence the mutex.
+type keyedThrottler struct {
+	// key is reported in the stats.
+	key string
+
+	mu sync.Mutex
+	t  *throttler.Throttler
+}
+
+// QueryThrottler throttles queries per key.
+type QueryThrottler struct {
+	keyTypes          []string
+	maxRate           int64
+	maxReplicationLag int64
+	queryServiceStats *QueryServiceStats
+
+	mu         sync.Mutex
+	template   *throttler.Throttler
+	throttlers map[string]*keyedThrottler
+	closed     bool
+}
+
+// NewQueryThrottler creates a QueryThrottler from the config.
+// It returns nil if the query throttler is disabled.
+func NewQueryThrottler(config Config, queryServiceStats *QueryServiceStats) (*QueryThrottler, error) {
+	if !config.EnableQueryThrottler {
+		return nil, nil
+	}
+	keyTypes, err := queryThrottlerKeyTypes(config)
+	if err != nil {
+		return nil, err
+	}
+	template, err := throttler.NewThrottler(queryThrottlerTemplateName, "queries", 1, config.QueryThrottlerMaxRate, config.QueryThrottlerMaxReplicationLag)
+	if err != nil {
+		return nil, err
+	}
+	return &QueryThrottler{
+		keyTypes:          keyTypes,
+		maxRate:           config.QueryThrottlerMaxRate,
+		maxReplicationLag: config.QueryThrottlerMaxReplicationLag,
+		queryServiceStats: queryServiceStats,
+		template:          template,
+		throttlers:        make(map[string]*keyedThrottler),
+	}, nil
+}
+
+// verifyQueryThrottlerConfig returns an error if the query throttler
+// is enabled with an invalid config.
+func verifyQueryThrottlerConfig(config Config) error {
+	if !config.EnableQueryThrottler {
+		return nil
+	}
+	if _, err := queryThrottlerKeyTypes(config); err != nil {
+		return err
+	}
+	if config.QueryThrottlerMaxRate < 0 {
+		return fmt.Errorf("query throttler max rate must be >= 0: %v", config.QueryThrottlerMaxRate)
+	}
+	if config.QueryThrottlerMaxReplicationLag < 0 {
+		return fmt.Errorf("query throttler max replication lag must be >= 0: %v", config.QueryThrottlerMaxReplicationLag)
+	}
+	return nil
+}
+
+// queryThrottlerKeyTypes parses the key types of the config.
+func queryThrottlerKeyTypes(config Config) ([]string, error) {
+	var keyTypes []string
+	for _, keyType := range strings.Split(config.QueryThrottlerKeys, ",") {
+		keyType = strings.TrimSpace(keyType)
+		switch keyType {
+		case QueryThrottlerKeyTable, QueryThrottlerKeyCaller, QueryThrottlerKeyWorkload:
+			keyTypes = append(keyTypes, keyType)
+		case "":
+		default:
+			return nil, fmt.Errorf("invalid query throttler key: %v", keyType)
+		}
+	}
+	if len(keyTypes) == 0 {
+		return nil, fmt.Errorf("query throttler is enabled but no key is set")
+	}
+	return keyTypes, nil
+}
+
+// queryThrottlerKey returns the value of the key of the given type
+// for a query. It returns "" if the query has no such key.
+func queryThrottlerKey(ctx context.Context, keyType, tableName, sql string, bindVars map[string]interface{}) string {
+	switch keyType {
+	case QueryThrottlerKeyTable:
+		return tableName
+	case QueryThrottlerKeyCaller:
+		if username := callerid.GetPrincipal(callerid.EffectiveCallerIDFromContext(ctx)); username != "" {
+			return username
+		}
+		return callerid.GetUsername(callerid.ImmediateCallerIDFromContext(ctx))
+	case QueryThrottlerKeyWorkload:
+		comments := commentRE.FindAllStringSubmatch(sql, -1)
+		if trailing, ok := bindVars[trailingComment].(string); ok {
+			comments = append(comments, commentRE.FindAllStringSubmatch(trailing, -1)...)
+		}
+		for _, c := range comments {
+			if m := workloadRE.FindStringSubmatch(c[1]); m != nil {
+				return m[1]
+			}
+		}
+	}
+	return ""
+}
+
+// Throttle returns a TRANSIENT_ERROR TabletError if any of the keys of
+// the query is over its budget.
+func (qt *QueryThrottler) Throttle(ctx context.Context, tableName, sql string, bindVars map[string]interface{}) error {
+	for _, keyType := range qt.keyTypes {
+		key := queryThrottlerKey(ctx, keyType, tableName, sql, bindVars)
+		if key == "" {
+			continue
+		}
+		kt := qt.getThrottler(keyType, key)
+		if kt == nil {
+			continue
+		}
+		kt.mu.Lock()
+		backoff := kt.t.Throttle(0)
+		kt.mu.Unlock()
+		if backoff != throttler.NotThrottled {
+			qt.queryServiceStats.ThrottledQueries.Add([]string{keyType, kt.key}, 1)
+			return NewTabletError(vtrpcpb.ErrorCode_TRANSIENT_ERROR, "query throttled for %v %v, retry after %v", keyType, key, backoff)
+		}
+	}
+	return nil
+}
+
+// getThrottler returns the throttler for a key, creating it if needed.
+// Once too many keys are tracked, the new keys share the throttler of
+// their key type, so clients can't escape throttling by using new keys.
+// It returns nil if the throttler is closed.
+func (qt *QueryThrottler) getThrottler(keyType, key string) *keyedThrottler {
+	name := fmt.Sprintf("%v/%v/%v", queryThrottlerTemplateName, keyType, key)
+
+	qt.mu.Lock()
+	defer qt.mu.Unlock()
+	if qt.closed {
+		return nil
+	}
+	if kt, ok := qt.throttlers[name]; ok {
+		return kt
+	}
+	if len(qt.throttlers) >= maxQueryThrottlers {
+		// The names of the shared throttlers have no key, so they
+		// can't collide with the throttler of a key.
+		name = fmt.Sprintf("%v/%v", queryThrottlerTemplateName, keyType)
+		key = queryThrottlerOtherKey
+		if kt, ok := qt.throttlers[name]; ok {
+			return kt
+		}
+	}
+
+	t, err := throttler.NewThrottler(name, "queries", 1, qt.template.MaxRate(), qt.maxReplicationLag)
+	if err != nil {
+		log.Errorf("cannot create query throttler %v: %v", name, err)
+		return nil
+	}
+	if err := t.UpdateConfiguration(qt.template.GetConfiguration(), true /* copyZeroValues */); err != nil {
+		log.Warningf("cannot copy the configuration of %v to %v: %v", queryThrottlerTemplateName, name, err)
+	}
+	kt := &keyedThrottler{
+		key: key,
+		t:   t,
+	}
+	qt.throttlers[name] = kt
+	return kt
+}
+
+// StatsUpdate forwards the replication lag of the replicas of the shard
+// to all throttlers. It is part of the discovery.HealthCheckStatsListener
+// interface.
+func (qt *QueryThrottler) StatsUpdate(ts *discovery.TabletStats) {
+	if ts.Target == nil || (ts.Target.TabletType != topodatapb.TabletType_REPLICA && ts.Target.TabletType != topodatapb.TabletType_RDONLY) {
+		return
+	}
+
+	// Hold the lock so Close() can't close the throttlers meanwhile.
+	qt.mu.Lock()
+	defer qt.mu.Unlock()
+	if qt.closed {
+		return
+	}
+	now := time.Now()
+	qt.template.RecordReplicationLag(now, ts)
+	for _, kt := range qt.throttlers {
+		kt.t.RecordReplicationLag(now, ts)
+	}
+}
+
+// Close closes all the throttlers. Throttle() doesn't throttle
+// anything afterwards.
+func (qt *QueryThrottler) Close() {
+	qt.mu.Lock()
+	defer qt.mu.Unlock()
+	if qt.closed {
+		return
+	}
+	qt.closed = true
+	for _, kt := range qt.throttlers {
+		kt.mu.Lock()
+		kt.t.Close()
+		kt.mu.Unlock()
+	}
+	qt.throttlers = nil
+	qt.template.Close()
+}
@@ -0,0 +1,195 @@
+// Copyright 2016, Google Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tabletserver
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/context"
+
+	"github.com/youtube/vitess/go/vt/callerid"
+
+	vtrpcpb "github.com/youtube/vitess/go/vt/proto/vtrpc"
+)
+
+func TestQueryThrottlerKey(t *testing.T) {
+	ctx := context.Background()
+	callerCtx := callerid.NewContext(ctx, nil, callerid.NewImmediateCallerID("immediate"))
+	effectiveCtx := callerid.NewContext(ctx, callerid.NewEffectiveCallerID("effective", "", ""), callerid.NewImmediateCallerID("immediate"))
+
+	testcases := []struct {
+		ctx      context.Context
+		keyType  string
+		sql      string
+		bindVars map[string]interface{}
+		want     string
+	}{{
+		ctx:     ctx,
+		keyType: QueryThrottlerKeyTable,
+		want:    "test_table",
+	}, {
+		ctx:     ctx,
+		keyType: QueryThrottlerKeyCaller,
+		want:    "",
+	}, {
+		ctx:     callerCtx,
+		keyType: QueryThrottlerKeyCaller,
+		want:    "immediate",
+	}, {
+		ctx:     effectiveCtx,
+		keyType: QueryThrottlerKeyCaller,
+		want:    "effective",
+	}, {
+		ctx:     ctx,
+		keyType: QueryThrottlerKeyWorkload,
+		sql:     "/* workload=batch */ update test_table set a=1",
+		want:    "batch",
+	}, {
+		ctx:      ctx,
+		keyType:  QueryThrottlerKeyWorkload,
+		sql:      "update test_table set a=1",
+		bindVars: map[string]interface{}{trailingComment: " /* id:123, Workload = etl-daily */"},
+		want:     "etl-daily",
+	}, {
+		ctx:     ctx,
+		keyType: QueryThrottlerKeyWorkload,
+		sql:     "update test_table set workload=1",
+		want:    "",
+	}}
+	for _, tc := range testcases {
+		if got := queryThrottlerKey(tc.ctx, tc.keyType, "test_table", tc.sql, tc.bindVars); got != tc.want {
+			t.Errorf("queryThrottlerKey(%v, %q, %v): %q, want %q", tc.keyType, tc.sql, tc.bindVars, got, tc.want)
+		}
+	}
+}
+
+func TestQueryThrottler(t *testing.T) {
+	config := DefaultQsConfig
+	if qt, err := NewQueryThrottler(config, nil); qt != nil || err != nil {
+		t.Fatalf("NewQueryThrottler with a disabled throttler: %v, %v, want nil, nil", qt, err)
+	}
+	config.EnableQueryThrottler = true
+	config.QueryThrottlerKeys = "table,foo"
+	if _, err := NewQueryThrottler(config, nil); err == nil {
+		t.Fatalf("NewQueryThrottler with an invalid key should have failed")
+	}
+
+	config.QueryThrottlerKeys = "table, workload"
+	config.QueryThrottlerMaxRate = 1
+	stats := NewQueryServiceStats("", false)
+	qt, err := NewQueryThrottler(config, stats)
+	if err != nil {
+		t.Fatalf("NewQueryThrottler failed: %v", err)
+	}
+	defer qt.Close()
+
+	ctx := context.Background()
+	if err := qt.Throttle(ctx, "t1", "update t1 set a=1", nil); err != nil {
+		t.Fatalf("first query on t1 should not be throttled: %v", err)
+	}
+	err = qt.Throttle(ctx, "t1", "update t1 set a=2", nil)
+	if err == nil {
+		t.Fatalf("second query on t1 should be throttled")
+	}
+	if tabletErr, ok := err.(*TabletError); !ok || tabletErr.ErrorCode != vtrpcpb.ErrorCode_TRANSIENT_ERROR {
+		t.Errorf("throttled query should fail with a TRANSIENT_ERROR: %v", err)
+	}
+	if got := stats.ThrottledQueries.Counts()["table.t1"]; got != 1 {
+		t.Errorf("ThrottledQueries[table.t1]: %v, want 1", got)
+	}
+
+	// Other tables have their own budget, but the workload budget
+	// is shared across tables.
+	if err := qt.Throttle(ctx, "t2", "/* workload=batch */ update t2 set a=1", nil); err != nil {
+		t.Fatalf("first query on t2 should not be throttled: %v", err)
+	}
+	if err := qt.Throttle(ctx, "t3", "/* workload=batch */ update t3 set a=1", nil); err == nil {
+		t.Fatalf("second query of the batch workload should be throttled")
+	}
+
+	// Closed throttlers don't throttle anything.
+	qt.Close()
+	if err := qt.Throttle(ctx, "t1", "update t1 set a=3", nil); err != nil {
+		t.Errorf("closed throttler should not throttle: %v", err)
+	}
+}
+
+func TestVerifyQueryThrottlerConfig(t *testing.T) {
+	testcases := []struct {
+		enable  bool
+		keys    string
+		maxRate int64
+		wantErr string
+	}{{
+		keys: "foo",
+	}, {
+		enable:  true,
+		keys:    "table",
+		maxRate: 100,
+	}, {
+		enable:  true,
+		keys:    "table,foo",
+		wantErr: "invalid query throttler key: foo",
+	}, {
+		enable:  true,
+		keys:    " ",
+		wantErr: "no key is set",
+	}, {
+		enable:  true,
+		keys:    "caller",
+		maxRate: -1,
+		wantErr: "max rate must be >= 0",
+	}}
+	for _, tc := range testcases {
+		config := DefaultQsConfig
+		config.EnableQueryThrottler = tc.enable
+		config.QueryThrottlerKeys = tc.keys
+		config.QueryThrottlerMaxRate = tc.maxRate
+		err := verifyQueryThrottlerConfig(config)
+		if tc.wantErr == "" {
+			if err != nil {
+				t.Errorf("verifyQueryThrottlerConfig(%v, %q, %v) failed: %v", tc.enable, tc.keys, tc.maxRate, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+			t.Errorf("verifyQueryThrottlerConfig(%v, %q, %v): %v, want error containing %q", tc.enable, tc.keys, tc.maxRate, err, tc.wantErr)
+		}
+	}
+}
+
+func TestQueryThrottlerTooManyKeys(t *testing.T) {
+	config := DefaultQsConfig
+	config.EnableQueryThrottler = true
+	config.QueryThrottlerKeys = "table"
+	config.QueryThrottlerMaxRate = 1
+	stats := NewQueryServiceStats("", false)
+	qt, err := NewQueryThrottler(config, stats)
+	if err != nil {
+		t.Fatalf("NewQueryThrottler failed: %v", err)
+	}
+	defer qt.Close()
+
+	ctx := context.Background()
+	for i := 0; i < maxQueryThrottlers; i++ {
+		table := fmt.Sprintf("t%v", i)
+		if err := qt.Throttle(ctx, table, "update "+table+" set a=1", nil); err != nil {
+			t.Fatalf("first query on %v should not be throttled: %v", table, err)
+		}
+	}
+
+	// The new tables share one throttler.
+	if err := qt.Throttle(ctx, "new1", "update new1 set a=1", nil); err != nil {
+		t.Fatalf("first query on a new table should not be throttled: %v", err)
+	}
+	if err := qt.Throttle(ctx, "new2", "update new2 set a=1", nil); err == nil {
+		t.Fatalf("second query on a new table should be throttled")
+	}
+	if got := stats.ThrottledQueries.Counts()["table."+queryThrottlerOtherKey]; got != 1 {
+		t.Errorf("ThrottledQueries[table.%v]: %v, want 1", queryThrottlerOtherKey, got)
+	}
+}
@@ -0,0 +1,141 @@
+// Copyright 2016, Google Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tabletserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net/http"
+	"time"
+
+	log "github.com/golang/glog"
+	"github.com/youtube/vitess/go/acl"
+	"github.com/youtube/vitess/go/vt/logz"
+)
+
+var (
+	querykillzHeader = []byte(`<thead>
+		<tr>
+			<th>Kill time</th>
+			<th>Limit</th>
+			<th>Dry run</th>
+			<th>Method</th>
+			<th>Context</th>
+			<th>Effective Caller</th>
+			<th>Immediate Caller</th>
+			<th>Start</th>
+			<th>Duration</th>
+			<th>Plan</th>
+			<th>SQL</th>
+			<th>Running SQL</th>
+			<th>ConnectionID</th>
+			<th>Transaction ID</th>
+			<th>Error</th>
+		</tr>
+        </thead>
+	`)
+	querykillzFuncMap = template.FuncMap{
+		"stampMicro":   func(t time.Time) string { return t.Format(time.StampMicro) },
+		"cssWrappable": logz.Wrappable,
+	}
+	querykillzTmpl = template.Must(template.New("example").Funcs(querykillzFuncMap).Parse(`
+		<tr class="{{if .DryRun}}medium{{else}}high{{end}}">
+			<td>{{.KillTime | stampMicro}}</td>
+			<td>{{.LimitKey}} {{.Limit}}</td>
+			<td>{{.DryRun}}</td>
+			<td>{{.LogStats.Method}}</td>
+			<td>{{.LogStats.ContextHTML}}</td>
+			<td>{{.LogStats.EffectiveCaller}}</td>
+			<td>{{.LogStats.ImmediateCaller}}</td>
+			<td>{{.LogStats.StartTime | stampMicro}}</td>
+			<td>{{.LogStats.TotalTime.Seconds}}</td>
+			<td>{{.LogStats.PlanType}}</td>
+			<td>{{.LogStats.OriginalSQL | cssWrappable}}</td>
+			<td>{{.SQL | cssWrappable}}</td>
+			<td>{{.ConnID}}</td>
+			<td>{{.LogStats.TransactionID}}</td>
+			<td>{{if .Err}}{{.Err}}{{end}}</td>
+		</tr>
+	`))
+)
+
+// querykillzRow is the JSON version of a KilledQuery.
+type querykillzRow struct {
+	KillTime        time.Time
+	LimitKey        string
+	Limit           string
+	DryRun          bool
+	Method          string
+	EffectiveCaller string
+	ImmediateCaller string
+	StartTime       time.Time
+	Duration        float64
+	PlanType        string
+	OriginalSQL     string
+	SQL             string
+	ConnID          int64
+	TransactionID   int64
+	Error           string `json:",omitempty"`
+}
+
+// querykillzHandler lists the queries recently killed by the query
+// killer, most recent first.
+func querykillzHandler(queryKiller *QueryKiller, w http.ResponseWriter, r *http.Request) {
+	if err := acl.CheckAccessHTTP(r, acl.DEBUGGING); err != nil {
+		acl.SendError(w, err)
+		return
+	}
+	if queryKiller == nil {
+		w.Write([]byte("query killer is disabled, see -enable-query-killer"))
+		return
+	}
+	killed := queryKiller.KilledQueries()
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, fmt.Sprintf("cannot parse form: %s", err), http.StatusInternalServerError)
+		return
+	}
+	if r.FormValue("format") == "json" {
+		rows := make([]querykillzRow, 0, len(killed))
+		for _, kq := range killed {
+			row := querykillzRow{
+				KillTime:        kq.KillTime,
+				LimitKey:        kq.LimitKey,
+				Limit:           kq.Limit.String(),
+				DryRun:          kq.DryRun,
+				Method:          kq.LogStats.Method,
+				EffectiveCaller: kq.LogStats.EffectiveCaller(),
+				ImmediateCaller: kq.LogStats.ImmediateCaller(),
+				StartTime:       kq.LogStats.StartTime,
+				Duration:        kq.LogStats.TotalTime().Seconds(),
+				PlanType:        kq.LogStats.PlanType,
+				OriginalSQL:     kq.LogStats.OriginalSQL,
+				SQL:             kq.SQL,
+				ConnID:          kq.ConnID,
+				TransactionID:   kq.LogStats.TransactionID,
+			}
+			if kq.Err != nil {
+				row.Error = kq.Err.Error()
+			}
+			rows = append(rows, row)
+		}
+		js, err := json.Marshal(rows)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(js)
+		return
+	}
+	logz.StartHTMLTable(w)
+	defer logz.EndHTMLTable(w)
+	w.Write(querykillzHeader)
+	for _, kq := range killed {
+		if err := querykillzTmpl.Execute(w, kq); err != nil {
+			log.Errorf("querykillz: couldn't execute template: %v", err)
+		}
+	}
+}
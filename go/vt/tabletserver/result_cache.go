@@ -0,0 +1,415 @@
+// Copyright 2016, Google Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tabletserver
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/golang/glog"
+	"golang.org/x/net/context"
+
+	"github.com/youtube/vitess/go/cache"
+	"github.com/youtube/vitess/go/sqltypes"
+	"github.com/youtube/vitess/go/stats"
+	"github.com/youtube/vitess/go/vt/binlog"
+	"github.com/youtube/vitess/go/vt/mysqlctl"
+	"github.com/youtube/vitess/go/vt/tabletserver/planbuilder"
+
+	querypb "github.com/youtube/vitess/go/vt/proto/query"
+)
+
+// This file contains the result cache. It caches the results of the
+// selects by primary key (PASS_SELECT plans with PKValues) on the
+// tables listed in the config, keyed by the final SQL of the query,
+// i.e. the plan and its bind variables. The cache is bounded by a
+// memory budget: it's a cache.LRUCache whose values are sized by the
+// bytes they hold.
+//
+// The entries are invalidated per row:
+// - on a master, by the DMLs that go through this tablet, when their
+//   transaction is concluded. Writes that don't go through vttablet
+//   (e.g. filtered replication) are not seen, so the cache should only
+//   be enabled on masters that don't get any.
+// - on the other tablets, by the update stream: an EventStreamer on
+//   the local binlogs, started from the current position when the
+//   tablet starts serving. The cache is not used while the stream is
+//   down.
+// A DML without primary key values (e.g. non-strict PASS_DML, or an
+// insert with an auto-increment value) invalidates its whole table,
+// and a DDL or a schema change found by a reload the whole cache.
+// Only the tables whose primary key columns are integrals or binary
+// strings are cached, see Caches.
+//
+// Instead of looking up the entries of a row, an invalidation records
+// a version number for the row: the entries filled from a query that
+// started before that version are stale. This is also how the results
+// of the queries that run concurrently with an invalidation are
+// discarded.
+
+const (
+	// maxResultCacheInvalidations is the number of row invalidations
+	// we remember. Past that, the whole cache is flushed.
+	maxResultCacheInvalidations = 10000
+
+	// resultCacheRetryDelay is how long we wait before restarting the
+	// update stream after an error.
+	resultCacheRetryDelay = 5 * time.Second
+)
+
+// resultCacheEntry is a cached result.
+type resultCacheEntry struct {
+	table   string
+	rowKeys []string
+	version int64
+	result  *sqltypes.Result
+	size    int
+}
+
+// Size is part of the cache.Value interface.
+func (entry *resultCacheEntry) Size() int {
+	return entry.size
+}
+
+// resultCacheChange is a change made by a transaction: pkRows of
+// table, or the whole table if pkRows is nil.
+type resultCacheChange struct {
+	table  string
+	pkRows [][]sqltypes.Value
+}
+
+// ResultCache caches the results of the selects by primary key.
+type ResultCache struct {
+	tables            map[string]bool
+	cache             *cache.LRUCache
+	queryServiceStats *QueryServiceStats
+
+	mu sync.Mutex
+	// enabled is false while the entries can't be invalidated,
+	// i.e. while the update stream is down on non-master tablets.
+	enabled bool
+	// version is incremented by every invalidation.
+	version int64
+	// minVersion is the oldest version of the valid entries. It is
+	// set when the whole cache is flushed.
+	minVersion int64
+	// tableVersions and rowVersions are the versions of the latest
+	// invalidation of each table and row.
+	tableVersions map[string]int64
+	rowVersions   map[string]int64
+	// streamCancel stops the update stream.
+	streamCancel context.CancelFunc
+}
+
+// NewResultCache creates a ResultCache from the config.
+// It returns nil if the result cache is disabled.
+func NewResultCache(config Config, queryServiceStats *QueryServiceStats) *ResultCache {
+	if !config.EnableResultCache {
+		return nil
+	}
+	tables := make(map[string]bool)
+	for _, table := range strings.Split(config.ResultCacheTables, ",") {
+		if table = strings.TrimSpace(table); table != "" {
+			tables[table] = true
+		}
+	}
+	rc := &ResultCache{
+		tables:            tables,
+		cache:             cache.NewLRUCache(config.ResultCacheSize),
+		queryServiceStats: queryServiceStats,
+		tableVersions:     make(map[string]int64),
+		rowVersions:       make(map[string]int64),
+	}
+	if config.EnablePublishStats {
+		stats.Publish(config.StatsPrefix+"ResultCacheLength", stats.IntFunc(rc.cache.Length))
+		stats.Publish(config.StatsPrefix+"ResultCacheSize", stats.IntFunc(rc.cache.Size))
+		stats.Publish(config.StatsPrefix+"ResultCacheCapacity", stats.IntFunc(rc.cache.Capacity))
+	}
+	return rc
+}
+
+// Caches returns true if the results of the plan can be cached.
+// Tables whose primary key has a column that is not an integral or a
+// binary string are not cached: MySQL compares these values with the
+// collation or type of the column (e.g. ignoring case or trailing
+// spaces), so the rows a query reads can't be identified by the raw
+// values of its primary key.
+func (rc *ResultCache) Caches(plan *ExecPlan) bool {
+	if plan.PlanID != planbuilder.PlanPassSelect || plan.PKValues == nil || !rc.tables[plan.TableName] {
+		return false
+	}
+	for _, index := range plan.TableInfo.PKColumns {
+		typ := plan.TableInfo.Columns[index].Type
+		if !sqltypes.IsIntegral(typ) && !sqltypes.IsBinary(typ) {
+			return false
+		}
+	}
+	return true
+}
+
+// Open enables the cache. On a master, the entries are invalidated by
+// the DMLs of the tablet. Otherwise, Open starts the update stream
+// that invalidates them, and the cache is enabled once the stream
+// starts.
+func (rc *ResultCache) Open(isMaster bool, dbname string, mysqld mysqlctl.MysqlDaemon) {
+	rc.Close()
+	if isMaster {
+		rc.setEnabled(true)
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	rc.mu.Lock()
+	rc.streamCancel = cancel
+	rc.mu.Unlock()
+	go rc.invalidator(ctx, dbname, mysqld)
+}
+
+// Close stops the update stream, disables the cache and flushes it.
+func (rc *ResultCache) Close() {
+	rc.mu.Lock()
+	if rc.streamCancel != nil {
+		rc.streamCancel()
+		rc.streamCancel = nil
+	}
+	rc.mu.Unlock()
+	rc.setEnabled(false)
+}
+
+// setEnabled enables or disables the cache. Both flush it.
+func (rc *ResultCache) setEnabled(enabled bool) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rc.enabled = enabled
+	rc.flushLocked()
+}
+
+// setStreaming enables or disables the cache as the update stream of
+// ctx starts or stops, unless the stream was stopped by Close.
+func (rc *ResultCache) setStreaming(ctx context.Context, enabled bool) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	if ctx.Err() != nil {
+		return
+	}
+	rc.enabled = enabled
+	rc.flushLocked()
+}
+
+// invalidator is the background thread that reads the update stream.
+// It will run in a loop. If it errors out, it will wait before
+// restarting.
+func (rc *ResultCache) invalidator(ctx context.Context, dbname string, mysqld mysqlctl.MysqlDaemon) {
+	for {
+		// The cache is enabled before the stream starts, and the
+		// stream starts from the current position: the entries
+		// filled from now on read data that is at this position, or
+		// after it, so we'll see all the changes they miss.
+		if pos, err := mysqld.MasterPosition(); err != nil {
+			log.Warningf("Result cache: cannot get the replication position, retrying in %v: %v", resultCacheRetryDelay, err)
+		} else {
+			rc.setStreaming(ctx, true)
+			streamer := binlog.NewEventStreamer(dbname, mysqld, pos, 0, func(event *querypb.StreamEvent) error {
+				rc.invalidateEvent(event)
+				return nil
+			})
+			err := streamer.Stream(ctx)
+			rc.setStreaming(ctx, false)
+			log.Infof("Result cache update stream stopped: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(resultCacheRetryDelay):
+		}
+	}
+}
+
+// invalidateEvent applies the invalidations of an update stream event.
+func (rc *ResultCache) invalidateEvent(event *querypb.StreamEvent) {
+	for _, statement := range event.Statements {
+		switch statement.Category {
+		case querypb.StreamEvent_Statement_DML:
+			if !rc.tables[statement.TableName] {
+				continue
+			}
+			pkRows := make([][]sqltypes.Value, 0, len(statement.PrimaryKeyValues))
+			for _, row := range statement.PrimaryKeyValues {
+				// The values were parsed by the EventStreamer from
+				// the stream comment vttablet wrote, so we can trust
+				// them.
+				pkRows = append(pkRows, sqltypes.MakeRowTrusted(statement.PrimaryKeyFields, row))
+			}
+			rc.InvalidateRows(statement.TableName, pkRows)
+		default:
+			// DDLs, and DMLs we can't parse: we don't know which
+			// rows changed.
+			rc.InvalidateAll()
+		}
+	}
+}
+
+// Version returns the current version of the cache. It must be read
+// before running the query whose result is passed to Set.
+func (rc *ResultCache) Version() int64 {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	return rc.version
+}
+
+// Get returns the cached result for the query key, if it's still
+// valid. The result must not be modified.
+func (rc *ResultCache) Get(table, key string) (*sqltypes.Result, bool) {
+	if v, ok := rc.cache.Get(key); ok {
+		entry := v.(*resultCacheEntry)
+		if rc.isValid(entry) {
+			rc.queryServiceStats.ResultCacheHits.Add(table, 1)
+			return entry.result, true
+		}
+		rc.cache.Delete(key)
+	}
+	rc.queryServiceStats.ResultCacheMisses.Add(table, 1)
+	return nil, false
+}
+
+// Set caches the result of the query key, that read the given rows.
+// version is the value of Version before the query ran: if the rows
+// were invalidated since, the result is not cached. The result must
+// not be modified afterwards.
+func (rc *ResultCache) Set(table, key string, pkRows [][]sqltypes.Value, version int64, result *sqltypes.Result) {
+	entry := &resultCacheEntry{
+		table:   table,
+		rowKeys: make([]string, 0, len(pkRows)),
+		version: version,
+		result:  result,
+		size:    len(key) + resultSize(result),
+	}
+	for _, pkRow := range pkRows {
+		entry.rowKeys = append(entry.rowKeys, resultCacheRowKey(table, pkRow))
+	}
+	if !rc.isValid(entry) {
+		return
+	}
+	rc.cache.Set(key, entry)
+}
+
+// isValid returns true if the entry was not invalidated.
+func (rc *ResultCache) isValid(entry *resultCacheEntry) bool {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	if !rc.enabled || entry.version < rc.minVersion || entry.version < rc.tableVersions[entry.table] {
+		return false
+	}
+	for _, rowKey := range entry.rowKeys {
+		if entry.version < rc.rowVersions[rowKey] {
+			return false
+		}
+	}
+	return true
+}
+
+// Invalidate applies the changes of a concluded transaction. A change
+// with no table (a DML on several tables) invalidates all the entries.
+func (rc *ResultCache) Invalidate(changes []resultCacheChange) {
+	for _, change := range changes {
+		switch {
+		case change.table == "":
+			rc.InvalidateAll()
+		case change.pkRows == nil:
+			rc.InvalidateTable(change.table)
+		default:
+			rc.InvalidateRows(change.table, change.pkRows)
+		}
+	}
+}
+
+// InvalidateRows invalidates the entries that read the given rows.
+// A row with a NULL value (e.g. an auto-increment column left to
+// MySQL) invalidates the whole table.
+func (rc *ResultCache) InvalidateRows(table string, pkRows [][]sqltypes.Value) {
+	if !rc.tables[table] {
+		return
+	}
+	for _, pkRow := range pkRows {
+		for _, v := range pkRow {
+			if v.IsNull() {
+				rc.InvalidateTable(table)
+				return
+			}
+		}
+	}
+	rc.queryServiceStats.ResultCacheInvalidations.Add(table, int64(len(pkRows)))
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rc.version++
+	for _, pkRow := range pkRows {
+		rc.rowVersions[resultCacheRowKey(table, pkRow)] = rc.version
+	}
+	if len(rc.rowVersions) > maxResultCacheInvalidations {
+		rc.flushLocked()
+	}
+}
+
+// InvalidateTable invalidates all the entries of a table.
+func (rc *ResultCache) InvalidateTable(table string) {
+	if !rc.tables[table] {
+		return
+	}
+	rc.queryServiceStats.ResultCacheInvalidations.Add(table, 1)
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rc.version++
+	rc.tableVersions[table] = rc.version
+}
+
+// InvalidateAll invalidates all the entries.
+func (rc *ResultCache) InvalidateAll() {
+	rc.queryServiceStats.ResultCacheInvalidations.Add("All", 1)
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rc.flushLocked()
+}
+
+// flushLocked invalidates all the entries. rc.mu must be held.
+func (rc *ResultCache) flushLocked() {
+	rc.version++
+	rc.minVersion = rc.version
+	rc.tableVersions = make(map[string]int64)
+	rc.rowVersions = make(map[string]int64)
+	rc.cache.Clear()
+}
+
+// resultCacheRowKey returns the key of a row for invalidations. The
+// raw values are used, as the types of the update stream values may
+// differ from the column types.
+func resultCacheRowKey(table string, pkRow []sqltypes.Value) string {
+	buf := bytes.NewBufferString(table)
+	buf.WriteByte(0)
+	var length [binary.MaxVarintLen64]byte
+	for _, v := range pkRow {
+		n := binary.PutUvarint(length[:], uint64(v.Len()))
+		buf.Write(length[:n])
+		buf.Write(v.Raw())
+	}
+	return buf.String()
+}
+
+// resultSize approximates the memory used by a result.
+func resultSize(result *sqltypes.Result) int {
+	size := 64
+	for _, field := range result.Fields {
+		size += 32 + len(field.Name)
+	}
+	for _, row := range result.Rows {
+		size += 24
+		for _, v := range row {
+			size += 32 + v.Len()
+		}
+	}
+	return size
+}
@@ -0,0 +1,223 @@
+// Copyright 2016, Google Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tabletserver
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/youtube/vitess/go/sqltypes"
+	"github.com/youtube/vitess/go/vt/tabletserver/planbuilder"
+
+	querypb "github.com/youtube/vitess/go/vt/proto/query"
+)
+
+func newTestResultCache() *ResultCache {
+	config := DefaultQsConfig
+	config.EnableResultCache = true
+	config.ResultCacheTables = "t1, t2"
+	config.EnablePublishStats = false
+	rc := NewResultCache(config, NewQueryServiceStats("", false))
+	rc.Open(true, "", nil)
+	return rc
+}
+
+func testPKRows(values ...int64) [][]sqltypes.Value {
+	var pkRows [][]sqltypes.Value
+	for _, v := range values {
+		pkRows = append(pkRows, []sqltypes.Value{sqltypes.MakeTrusted(sqltypes.Int32, []byte(strconv.FormatInt(v, 10)))})
+	}
+	return pkRows
+}
+
+func TestResultCacheDisabled(t *testing.T) {
+	if rc := NewResultCache(DefaultQsConfig, NewQueryServiceStats("", false)); rc != nil {
+		t.Errorf("NewResultCache() = %v, want nil when the result cache is disabled", rc)
+	}
+}
+
+func TestResultCacheCaches(t *testing.T) {
+	rc := newTestResultCache()
+	testcases := []struct {
+		name    string
+		table   string
+		pkTypes []querypb.Type
+		want    bool
+	}{{
+		name:    "integral",
+		table:   "t1",
+		pkTypes: []querypb.Type{sqltypes.Int64},
+		want:    true,
+	}, {
+		name:    "binary",
+		table:   "t1",
+		pkTypes: []querypb.Type{sqltypes.Int64, sqltypes.VarBinary},
+		want:    true,
+	}, {
+		name:    "text",
+		table:   "t1",
+		pkTypes: []querypb.Type{sqltypes.Int64, sqltypes.VarChar},
+		want:    false,
+	}, {
+		name:    "datetime",
+		table:   "t1",
+		pkTypes: []querypb.Type{sqltypes.Datetime},
+		want:    false,
+	}, {
+		name:    "table not cached",
+		table:   "t3",
+		pkTypes: []querypb.Type{sqltypes.Int64},
+		want:    false,
+	}}
+	for _, tc := range testcases {
+		var names []string
+		for i := range tc.pkTypes {
+			names = append(names, "pk"+strconv.Itoa(i))
+		}
+		tableInfo := createTableInfo(tc.table, names, tc.pkTypes, names)
+		plan := &ExecPlan{
+			ExecPlan: &planbuilder.ExecPlan{
+				PlanID:    planbuilder.PlanPassSelect,
+				TableName: tc.table,
+				PKValues:  []interface{}{":pk"},
+			},
+			TableInfo: &tableInfo,
+		}
+		if got := rc.Caches(plan); got != tc.want {
+			t.Errorf("%v: Caches() = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestResultCache(t *testing.T) {
+	rc := newTestResultCache()
+	result := &sqltypes.Result{RowsAffected: 1}
+
+	if _, ok := rc.Get("t1", "q1"); ok {
+		t.Fatalf("Get(q1) hit on an empty cache")
+	}
+	rc.Set("t1", "q1", testPKRows(1), rc.Version(), result)
+	rc.Set("t1", "q2", testPKRows(2), rc.Version(), result)
+	if got, ok := rc.Get("t1", "q1"); !ok || got != result {
+		t.Fatalf("Get(q1) = %v, %v, want %v", got, ok, result)
+	}
+
+	// Invalidating a row only invalidates the queries that read it.
+	rc.InvalidateRows("t1", testPKRows(1))
+	if _, ok := rc.Get("t1", "q1"); ok {
+		t.Errorf("Get(q1) hit after its row was invalidated")
+	}
+	if _, ok := rc.Get("t1", "q2"); !ok {
+		t.Errorf("Get(q2) missed after another row was invalidated")
+	}
+	// But the result can be cached again.
+	rc.Set("t1", "q1", testPKRows(1), rc.Version(), result)
+	if _, ok := rc.Get("t1", "q1"); !ok {
+		t.Errorf("Get(q1) missed after it was cached again")
+	}
+
+	// Invalidating a table invalidates all its queries.
+	rc.InvalidateTable("t1")
+	if _, ok := rc.Get("t1", "q2"); ok {
+		t.Errorf("Get(q2) hit after its table was invalidated")
+	}
+
+	if got, want := rc.queryServiceStats.ResultCacheHits.Counts()["t1"], int64(3); got != want {
+		t.Errorf("ResultCacheHits = %v, want %v", got, want)
+	}
+	if got, want := rc.queryServiceStats.ResultCacheMisses.Counts()["t1"], int64(3); got != want {
+		t.Errorf("ResultCacheMisses = %v, want %v", got, want)
+	}
+}
+
+func TestResultCacheConcurrentInvalidation(t *testing.T) {
+	rc := newTestResultCache()
+	result := &sqltypes.Result{}
+
+	// The row is invalidated while the query runs: its result may be
+	// stale, and must not be cached.
+	version := rc.Version()
+	rc.InvalidateRows("t1", testPKRows(1))
+	rc.Set("t1", "q1", testPKRows(1), version, result)
+	if _, ok := rc.Get("t1", "q1"); ok {
+		t.Errorf("Get(q1) hit for a result read before its row was invalidated")
+	}
+
+	// A NULL primary key value invalidates the whole table.
+	rc.Set("t1", "q2", testPKRows(2), rc.Version(), result)
+	rc.InvalidateRows("t1", [][]sqltypes.Value{{sqltypes.NULL}})
+	if _, ok := rc.Get("t1", "q2"); ok {
+		t.Errorf("Get(q2) hit after an insert with a NULL primary key")
+	}
+}
+
+func TestResultCacheInvalidate(t *testing.T) {
+	rc := newTestResultCache()
+	result := &sqltypes.Result{}
+	rc.Set("t1", "q1", testPKRows(1), rc.Version(), result)
+	rc.Set("t2", "q2", testPKRows(1), rc.Version(), result)
+
+	rc.Invalidate([]resultCacheChange{{table: "t2", pkRows: testPKRows(1)}})
+	if _, ok := rc.Get("t1", "q1"); !ok {
+		t.Errorf("Get(q1) missed after a change on another table")
+	}
+	if _, ok := rc.Get("t2", "q2"); ok {
+		t.Errorf("Get(q2) hit after a change on its row")
+	}
+
+	// A change without table invalidates everything.
+	rc.Invalidate([]resultCacheChange{{}})
+	if _, ok := rc.Get("t1", "q1"); ok {
+		t.Errorf("Get(q1) hit after a change on all tables")
+	}
+}
+
+func TestResultCacheInvalidateEvent(t *testing.T) {
+	rc := newTestResultCache()
+	result := &sqltypes.Result{}
+	rc.Set("t1", "q1", testPKRows(1), rc.Version(), result)
+	rc.Set("t1", "q2", testPKRows(2), rc.Version(), result)
+
+	// The update stream values are Int64 for numbers, whatever the
+	// column type is.
+	rc.invalidateEvent(&querypb.StreamEvent{
+		Statements: []*querypb.StreamEvent_Statement{{
+			Category:         querypb.StreamEvent_Statement_DML,
+			TableName:        "t1",
+			PrimaryKeyFields: []*querypb.Field{{Name: "id", Type: sqltypes.Int64}},
+			PrimaryKeyValues: []*querypb.Row{{Lengths: []int64{1}, Values: []byte("2")}},
+		}},
+	})
+	if _, ok := rc.Get("t1", "q1"); !ok {
+		t.Errorf("Get(q1) missed after an event on another row")
+	}
+	if _, ok := rc.Get("t1", "q2"); ok {
+		t.Errorf("Get(q2) hit after an event on its row")
+	}
+
+	// DDLs flush the cache.
+	rc.invalidateEvent(&querypb.StreamEvent{
+		Statements: []*querypb.StreamEvent_Statement{{
+			Category: querypb.StreamEvent_Statement_DDL,
+			Sql:      []byte("alter table t1 add column c int"),
+		}},
+	})
+	if _, ok := rc.Get("t1", "q1"); ok {
+		t.Errorf("Get(q1) hit after a DDL")
+	}
+}
+
+func TestResultCacheClosed(t *testing.T) {
+	rc := newTestResultCache()
+	rc.Set("t1", "q1", testPKRows(1), rc.Version(), &sqltypes.Result{})
+	rc.Close()
+	if _, ok := rc.Get("t1", "q1"); ok {
+		t.Errorf("Get(q1) hit on a closed cache")
+	}
+	rc.Set("t1", "q1", testPKRows(1), rc.Version(), &sqltypes.Result{})
+	if got := rc.cache.Length(); got != 0 {
+		t.Errorf("a closed cache has %v entries, want 0", got)
+	}
+}
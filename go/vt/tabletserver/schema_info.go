@@ -49,6 +49,9 @@ type ExecPlan struct {
 	Fields     []*querypb.Field
 	Rules      *QueryRules
 	Authorized *tableacl.ACLResult
+	// OtherAuthorized are the READER acls of OtherTablesRead, in
+	// the same order.
+	OtherAuthorized []*tableacl.ACLResult
 
 	mu         sync.Mutex
 	QueryCount int64
@@ -106,6 +109,10 @@ type SchemaInfo struct {
 	endpoints         map[string]string
 	queryRuleSources  *QueryRuleInfo
 	queryServiceStats *QueryServiceStats
+
+	// notifier is called with the names of the tables whose
+	// schema changed. It must be set before Open.
+	notifier func(tables []string)
 }
 
 // NewSchemaInfo creates a new SchemaInfo.
@@ -282,6 +289,9 @@ func (si *SchemaInfo) Reload(ctx context.Context) error {
 	// Reload any tables that have changed. We try every table even if some fail,
 	// but we return success only if all tables succeed.
 	// The following section requires us to hold mu.
+	// The changed tables are notified after mu is released.
+	var changed []string
+	defer func() { si.notify(changed) }()
 	errs := &concurrency.AllErrorRecorder{}
 	si.mu.Lock()
 	defer si.mu.Unlock()
@@ -296,6 +306,7 @@ func (si *SchemaInfo) Reload(ctx context.Context) error {
 				defer si.mu.Lock()
 				log.Infof("Reloading schema for table: %s", tableName)
 				errs.RecordError(si.createOrUpdateTableLocked(ctx, tableName))
+				changed = append(changed, tableName)
 			}()
 			continue
 		}
@@ -323,6 +334,19 @@ func (si *SchemaInfo) mysqlTime(ctx context.Context) int64 {
 	return t
 }
 
+// SetSchemaChangeNotifier sets the function to call with the names of
+// the tables whose schema changed, after a reload or a DDL.
+func (si *SchemaInfo) SetSchemaChangeNotifier(notifier func(tables []string)) {
+	si.notifier = notifier
+}
+
+func (si *SchemaInfo) notify(tables []string) {
+	if si.notifier == nil || len(tables) == 0 {
+		return
+	}
+	si.notifier(tables)
+}
+
 // ClearQueryPlanCache should be called if query plan cache is potentially obsolete
 func (si *SchemaInfo) ClearQueryPlanCache() {
 	si.queries.Clear()
@@ -332,6 +356,7 @@ func (si *SchemaInfo) ClearQueryPlanCache() {
 func (si *SchemaInfo) CreateOrUpdateTable(ctx context.Context, tableName string) error {
 	si.actionMutex.Lock()
 	defer si.actionMutex.Unlock()
+	defer si.notify([]string{tableName})
 	return si.createOrUpdateTableLocked(ctx, tableName)
 }
 
@@ -389,6 +414,7 @@ func (si *SchemaInfo) createOrUpdateTableLocked(ctx context.Context, tableName s
 func (si *SchemaInfo) DropTable(tableName string) {
 	si.actionMutex.Lock()
 	defer si.actionMutex.Unlock()
+	defer si.notify([]string{tableName})
 
 	si.mu.Lock()
 	defer si.mu.Unlock()
@@ -437,6 +463,7 @@ func (si *SchemaInfo) GetPlan(ctx context.Context, logStats *LogStats, sql strin
 	plan := &ExecPlan{ExecPlan: splan, TableInfo: tableInfo}
 	plan.Rules = si.queryRuleSources.filterByPlan(sql, plan.PlanID, plan.TableName)
 	plan.Authorized = tableacl.Authorized(plan.TableName, plan.PlanID.MinRole())
+	plan.OtherAuthorized = authorizeOtherTablesRead(plan.OtherTablesRead)
 	if plan.PlanID.IsSelect() {
 		if plan.FieldQuery == nil {
 			log.Warningf("Cannot cache field info: %s", sql)
@@ -479,9 +506,22 @@ func (si *SchemaInfo) GetStreamPlan(sql string) *ExecPlan {
 	plan := &ExecPlan{ExecPlan: splan, TableInfo: tableInfo}
 	plan.Rules = si.queryRuleSources.filterByPlan(sql, plan.PlanID, plan.TableName)
 	plan.Authorized = tableacl.Authorized(plan.TableName, plan.PlanID.MinRole())
+	plan.OtherAuthorized = authorizeOtherTablesRead(plan.OtherTablesRead)
 	return plan
 }
 
+// authorizeOtherTablesRead returns the READER acls of tables.
+func authorizeOtherTablesRead(tables []string) []*tableacl.ACLResult {
+	if len(tables) == 0 {
+		return nil
+	}
+	result := make([]*tableacl.ACLResult, len(tables))
+	for i, table := range tables {
+		result[i] = tableacl.Authorized(table, tableacl.READER)
+	}
+	return result
+}
+
 // GetTable returns the TableInfo for a table.
 func (si *SchemaInfo) GetTable(tableName string) *TableInfo {
 	si.mu.Lock()
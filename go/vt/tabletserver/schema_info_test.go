@@ -10,6 +10,7 @@ import (
 	"math"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
 	"testing"
 	"time"
 
@@ -296,6 +297,38 @@ func TestSchemaInfoDropTable(t *testing.T) {
 	schemaInfo.Close()
 }
 
+func TestSchemaInfoSchemaChangeNotifier(t *testing.T) {
+	db := fakesqldb.Register()
+	for query, result := range getSchemaInfoTestSupportedQueries() {
+		db.AddQuery(query, result)
+	}
+	existingTable := "test_table_01"
+	createOrDropTableQuery := fmt.Sprintf("%s and table_name = '%s'", baseShowTables, existingTable)
+	db.AddQuery(createOrDropTableQuery, &sqltypes.Result{
+		RowsAffected: 1,
+		Rows: [][]sqltypes.Value{
+			createTestTableBaseShowTable(existingTable),
+		},
+	})
+	schemaInfo := newTestSchemaInfo(10, 1*time.Second, 1*time.Second, false)
+	var changed [][]string
+	schemaInfo.SetSchemaChangeNotifier(func(tables []string) {
+		changed = append(changed, tables)
+	})
+	dbaParams := sqldb.ConnParams{Engine: db.Name}
+	schemaInfo.Open(&dbaParams, false)
+	defer schemaInfo.Close()
+	if len(changed) != 0 {
+		t.Errorf("Open() notified schema changes: %v", changed)
+	}
+	schemaInfo.CreateOrUpdateTable(context.Background(), existingTable)
+	schemaInfo.DropTable(existingTable)
+	want := [][]string{{existingTable}, {existingTable}}
+	if !reflect.DeepEqual(changed, want) {
+		t.Errorf("notified schema changes: %v, want %v", changed, want)
+	}
+}
+
 func TestSchemaInfoGetPlanPanicDuetoEmptyQuery(t *testing.T) {
 	db := fakesqldb.Register()
 	for query, result := range getSchemaInfoTestSupportedQueries() {
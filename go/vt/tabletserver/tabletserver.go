@@ -121,6 +121,9 @@ type TabletServer struct {
 	streamHealthIndex        int
 	streamHealthMap          map[int]chan<- *querypb.StreamHealthResponse
 	lastStreamHealthResponse *querypb.StreamHealthResponse
+	// schemaVersion is sent in the RealtimeStats, and incremented
+	// by every schema change.
+	schemaVersion int64
 
 	// history records changes in state for display on the status page.
 	// It has its own internal mutex.
@@ -161,6 +164,7 @@ func NewTabletServer(config Config) *TabletServer {
 		history:             history.New(10),
 	}
 	tsv.qe = NewQueryEngine(tsv, config)
+	tsv.qe.schemaInfo.SetSchemaChangeNotifier(tsv.schemaChanged)
 	tsv.updateStreamList = &binlog.StreamList{}
 	if config.EnablePublishStats {
 		stats.Publish(config.StatsPrefix+"TabletState", stats.IntFunc(func() int64 {
@@ -202,6 +206,7 @@ func (tsv *TabletServer) Register() {
 	tsv.registerQueryzHandler()
 	tsv.registerSchemazHandler()
 	tsv.registerStreamQueryzHandlers()
+	tsv.registerQueryKillzHandler()
 }
 
 // RegisterQueryRuleSource registers ruleSource for setting query rules.
@@ -273,6 +278,13 @@ func (tsv *TabletServer) InitDBConfig(target querypb.Target, dbconfigs dbconfigs
 	return nil
 }
 
+// Target returns the target of the TabletServer.
+func (tsv *TabletServer) Target() querypb.Target {
+	tsv.mu.Lock()
+	defer tsv.mu.Unlock()
+	return tsv.target
+}
+
 // StartService is a convenience function for InitDBConfig->SetServingType
 // with serving=true.
 func (tsv *TabletServer) StartService(target querypb.Target, dbconfigs dbconfigs.DBConfigs, mysqld mysqlctl.MysqlDaemon) (err error) {
@@ -382,6 +394,7 @@ func (tsv *TabletServer) fullStart() (err error) {
 			tsv.qe.Close()
 			tsv.updateStreamList.Stop()
 			tsv.stopReplicationStreamer()
+			tsv.stopResultCache()
 			tsv.transition(StateNotConnected)
 			err = x.(error)
 		}
@@ -405,6 +418,7 @@ func (tsv *TabletServer) serveNewType() (err error) {
 			tsv.qe.Close()
 			tsv.updateStreamList.Stop()
 			tsv.stopReplicationStreamer()
+			tsv.stopResultCache()
 			tsv.transition(StateNotConnected)
 			err = x.(error)
 		}
@@ -417,6 +431,7 @@ func (tsv *TabletServer) serveNewType() (err error) {
 			log.Errorf("Could not prepare transactions: %v", err)
 		}
 	}
+	tsv.startResultCache()
 	tsv.transition(StateServing)
 	return nil
 }
@@ -460,6 +475,7 @@ func (tsv *TabletServer) waitForShutdown() {
 	tsv.qe.streamQList.TerminateAll()
 	tsv.updateStreamList.Stop()
 	tsv.stopReplicationStreamer()
+	tsv.stopResultCache()
 	tsv.requests.Wait()
 }
 
@@ -568,6 +584,12 @@ func (tsv *TabletServer) QueryServiceStats() *QueryServiceStats {
 	return tsv.qe.queryServiceStats
 }
 
+// QueryThrottler returns the QueryThrottler instance of the
+// TabletServer's QueryEngine. It is nil if the throttler is disabled.
+func (tsv *TabletServer) QueryThrottler() *QueryThrottler {
+	return tsv.qe.queryThrottler
+}
+
 func (tsv *TabletServer) startReplicationStreamer() {
 	if !*watchReplicationStream {
 		return
@@ -585,6 +607,21 @@ func (tsv *TabletServer) stopReplicationStreamer() {
 	}
 }
 
+// startResultCache enables the result cache, if any, for the current
+// tablet type.
+func (tsv *TabletServer) startResultCache() {
+	if tsv.qe.resultCache == nil {
+		return
+	}
+	tsv.qe.resultCache.Open(tsv.target.TabletType == topodatapb.TabletType_MASTER, tsv.dbconfigs.App.DbName, tsv.mysqld)
+}
+
+func (tsv *TabletServer) stopResultCache() {
+	if tsv.qe.resultCache != nil {
+		tsv.qe.resultCache.Close()
+	}
+}
+
 // replicationStreamer is the background thread that reads the
 // replication stream. It will run in a loop. If it errors out, it
 // will wait for 5 seconds before restarting.
@@ -1380,11 +1417,17 @@ func (tsv *TabletServer) BroadcastHealth(terTimestamp int64, stats *querypb.Real
 		Target:  &target,
 		Serving: tsv.IsServing(),
 		TabletExternallyReparentedTimestamp: terTimestamp,
-		RealtimeStats:                       stats,
 	}
 
 	tsv.streamHealthMutex.Lock()
 	defer tsv.streamHealthMutex.Unlock()
+	if stats != nil {
+		// The stats belong to the caller: the schema version goes
+		// in a copy.
+		withVersion := *stats
+		withVersion.SchemaVersion = tsv.schemaVersion
+		shr.RealtimeStats = &withVersion
+	}
 	for _, c := range tsv.streamHealthMap {
 		// do not block on any write
 		select {
@@ -1395,6 +1438,48 @@ func (tsv *TabletServer) BroadcastHealth(terTimestamp int64, stats *querypb.Real
 	tsv.lastStreamHealthResponse = shr
 }
 
+// schemaChanged is called by the schema info with the tables whose
+// schema changed, after a reload or a DDL.
+func (tsv *TabletServer) schemaChanged(tables []string) {
+	if tsv.qe.resultCache != nil {
+		// The cached results may have the old columns.
+		tsv.qe.resultCache.InvalidateAll()
+	}
+	tsv.broadcastSchemaChange(tables)
+}
+
+// broadcastSchemaChange increments the schema version, and sends the
+// last health response to all listeners again, with the new version
+// and the list of tables whose schema changed. Only the version is
+// saved in lastStreamHealthResponse: the list is sent once, and the
+// listeners that miss it see the version jump.
+func (tsv *TabletServer) broadcastSchemaChange(tables []string) {
+	tsv.streamHealthMutex.Lock()
+	defer tsv.streamHealthMutex.Unlock()
+	tsv.schemaVersion++
+	if tsv.lastStreamHealthResponse == nil || tsv.lastStreamHealthResponse.RealtimeStats == nil {
+		// Nobody knows about this tablet yet.
+		return
+	}
+	last := *tsv.lastStreamHealthResponse
+	stats := *last.RealtimeStats
+	stats.SchemaVersion = tsv.schemaVersion
+	last.RealtimeStats = &stats
+	tsv.lastStreamHealthResponse = &last
+
+	shr := last
+	changedStats := stats
+	changedStats.TableSchemaChanged = tables
+	shr.RealtimeStats = &changedStats
+	for _, c := range tsv.streamHealthMap {
+		// do not block on any write
+		select {
+		case c <- &shr:
+		default:
+		}
+	}
+}
+
 // startRequest validates the current state and target and registers
 // the request (a waitgroup) as started. Every startRequest requires one
 // and only one corresponding endRequest. When the service shuts down,
@@ -1472,6 +1557,12 @@ func (tsv *TabletServer) registerQueryzHandler() {
 	})
 }
 
+func (tsv *TabletServer) registerQueryKillzHandler() {
+	http.HandleFunc("/querykillz", func(w http.ResponseWriter, r *http.Request) {
+		querykillzHandler(tsv.qe.queryKiller, w, r)
+	})
+}
+
 func (tsv *TabletServer) registerStreamQueryzHandlers() {
 	http.HandleFunc("/streamqueryz", func(w http.ResponseWriter, r *http.Request) {
 		streamQueryzHandler(tsv.qe.streamQList, w, r)
@@ -8,6 +8,7 @@ import (
 	"expvar"
 	"fmt"
 	"math/rand"
+	"reflect"
 	"strconv"
 	"strings"
 	"testing"
@@ -824,6 +825,44 @@ func TestTabletServerStreamExecute(t *testing.T) {
 	}
 }
 
+func TestTabletServerStreamExecuteSendTimeout(t *testing.T) {
+	db := setUpTabletServerTest()
+	testUtils := newTestUtils()
+	executeSQL := "select * from test_table limit 1000"
+	executeSQLResult := &sqltypes.Result{
+		RowsAffected: 1,
+		Rows: [][]sqltypes.Value{
+			{sqltypes.MakeString([]byte("row01"))},
+		},
+	}
+	db.AddQuery(executeSQL, executeSQLResult)
+
+	config := testUtils.newQueryServiceConfig()
+	config.StreamSendTimeout = 0.01
+	tsv := NewTabletServer(config)
+	dbconfigs := testUtils.newDBConfigs(db)
+	target := querypb.Target{TabletType: topodatapb.TabletType_MASTER}
+	err := tsv.StartService(target, dbconfigs, testUtils.newMysqld(&dbconfigs))
+	if err != nil {
+		t.Fatalf("StartService failed: %v", err)
+	}
+	defer tsv.StopService()
+	ctx := context.Background()
+	// A client that doesn't read the results in time.
+	sendReply := func(*sqltypes.Result) error {
+		time.Sleep(100 * time.Millisecond)
+		return nil
+	}
+	err = tsv.StreamExecute(ctx, &target, executeSQL, nil, nil, sendReply)
+	want := "stream send timeout"
+	if err == nil || !strings.Contains(err.Error(), want) {
+		t.Errorf("TabletServer.StreamExecute: %v, want %s", err, want)
+	}
+	if got := tsv.qe.queryServiceStats.KillStats.Counts()["StreamSendTimeout"]; got != 1 {
+		t.Errorf("StreamSendTimeout kills: %d, want 1", got)
+	}
+}
+
 func TestTabletServerExecuteBatch(t *testing.T) {
 	db := setUpTabletServerTest()
 	testUtils := newTestUtils()
@@ -1328,6 +1367,59 @@ func TestTabletServerSplitQueryV2InvalidParams(t *testing.T) {
 	}
 }
 
+func TestTabletServerBroadcastSchemaChange(t *testing.T) {
+	testUtils := newTestUtils()
+	config := testUtils.newQueryServiceConfig()
+	tsv := NewTabletServer(config)
+	c := make(chan *querypb.StreamHealthResponse, 10)
+	id, err := tsv.StreamHealthRegister(c)
+	if err != nil {
+		t.Fatalf("StreamHealthRegister failed: %v", err)
+	}
+	defer tsv.StreamHealthUnregister(id)
+	receive := func() *querypb.RealtimeStats {
+		select {
+		case shr := <-c:
+			return shr.RealtimeStats
+		default:
+			t.Fatalf("no health response was sent")
+		}
+		return nil
+	}
+
+	stats := &querypb.RealtimeStats{Qps: 1}
+	tsv.BroadcastHealth(0, stats)
+	if got := receive(); got.SchemaVersion != 0 || got.TableSchemaChanged != nil || got.Qps != 1 {
+		t.Errorf("health stats: %v, want version 0 and no table", got)
+	}
+
+	// The changed tables are only sent once, the version is kept.
+	tsv.schemaChanged([]string{"t1"})
+	if got := receive(); got.SchemaVersion != 1 || !reflect.DeepEqual(got.TableSchemaChanged, []string{"t1"}) || got.Qps != 1 {
+		t.Errorf("health stats: %v, want version 1 and table t1", got)
+	}
+	tsv.BroadcastHealth(0, stats)
+	if got := receive(); got.SchemaVersion != 1 || got.TableSchemaChanged != nil {
+		t.Errorf("health stats: %v, want version 1 and no table", got)
+	}
+	if stats.SchemaVersion != 0 {
+		t.Errorf("the stats of the caller were modified: %v", stats)
+	}
+
+	// New listeners get the version.
+	tsv.schemaChanged([]string{"t2"})
+	receive()
+	c2 := make(chan *querypb.StreamHealthResponse, 1)
+	id2, err := tsv.StreamHealthRegister(c2)
+	if err != nil {
+		t.Fatalf("StreamHealthRegister failed: %v", err)
+	}
+	defer tsv.StreamHealthUnregister(id2)
+	if got := (<-c2).RealtimeStats; got.SchemaVersion != 2 || got.TableSchemaChanged != nil {
+		t.Errorf("health stats: %v, want version 2 and no table", got)
+	}
+}
+
 func TestHandleExecUnknownError(t *testing.T) {
 	ctx := context.Background()
 	logStats := newLogStats("TestHandleExecError", ctx)
@@ -94,6 +94,13 @@ func (tqsc *Controller) InitDBConfig(target querypb.Target, dbConfigs dbconfigs.
 	return nil
 }
 
+// Target is part of the tabletserver.Controller interface
+func (tqsc *Controller) Target() querypb.Target {
+	tqsc.mu.Lock()
+	defer tqsc.mu.Unlock()
+	return tqsc.CurrentTarget
+}
+
 // SetServingType is part of the tabletserver.Controller interface
 func (tqsc *Controller) SetServingType(tabletType topodatapb.TabletType, serving bool, alsoAllow []topodatapb.TabletType) (bool, error) {
 	tqsc.mu.Lock()
@@ -160,6 +167,11 @@ func (tqsc *Controller) QueryServiceStats() *tabletserver.QueryServiceStats {
 	return nil
 }
 
+// QueryThrottler is part of the tabletserver.Controller interface
+func (tqsc *Controller) QueryThrottler() *tabletserver.QueryThrottler {
+	return nil
+}
+
 // BroadcastHealth is part of the tabletserver.Controller interface
 func (tqsc *Controller) BroadcastHealth(terTimestamp int64, stats *querypb.RealtimeStats) {
 	tqsc.mu.Lock()
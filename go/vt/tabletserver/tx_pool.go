@@ -273,6 +273,19 @@ type TxConnection struct {
 	LogToFile         sync2.AtomicInt32
 	ImmediateCallerID *querypb.VTGateCallerID
 	EffectiveCallerID *vtrpcpb.CallerID
+
+	// executed is set once the transaction executed its first
+	// statement, whatever its plan. Only the first statement can be
+	// queued by the hot row protection.
+	executed bool
+	// hotRowDone releases the row the transaction was queued for by
+	// the hot row protection, if any.
+	hotRowDone func()
+
+	// resultCacheChanges are the rows the transaction changed, which
+	// are invalidated in resultCache once it's concluded.
+	resultCache        *ResultCache
+	resultCacheChanges []resultCacheChange
 }
 
 func newTxConnection(conn *DBConn, transactionID int64, pool *TxPool, immediate *querypb.VTGateCallerID, effective *vtrpcpb.CallerID) *TxConnection {
@@ -319,6 +332,15 @@ func (txc *TxConnection) conclude(conclusion string) {
 	txc.pool.activePool.Unregister(txc.TransactionID)
 	txc.DBConn.Recycle()
 	txc.DBConn = nil
+	if txc.hotRowDone != nil {
+		txc.hotRowDone()
+		txc.hotRowDone = nil
+	}
+	if txc.resultCache != nil {
+		txc.resultCache.Invalidate(txc.resultCacheChanges)
+		txc.resultCache = nil
+		txc.resultCacheChanges = nil
+	}
 	txc.log(conclusion)
 }
 
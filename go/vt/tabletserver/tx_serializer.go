@@ -0,0 +1,160 @@
+// Copyright 2016, Google Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tabletserver
+
+import (
+	"bytes"
+	"sync"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/youtube/vitess/go/sqltypes"
+
+	vtrpcpb "github.com/youtube/vitess/go/vt/proto/vtrpc"
+)
+
+// This file contains the hot row protection. When many transactions
+// update the same row, MySQL makes them wait for the row lock, and they
+// pile up there while holding a TxPool connection. Instead, the
+// TxSerializer queues the transactions for the same row (table + primary
+// key values), lets a few of them run concurrently, and fails the others
+// fast with a retryable error once the queue for the row is full.
+//
+// Autocommit DMLs are queued before they get a TxPool connection, so
+// they don't drain the pool. Transactions started with Begin already
+// hold their connection when their first DML is queued: for them, the
+// protection only spares MySQL the lock waits, and bounds their number
+// with the queue size.
+//
+// Only the DMLs with known primary key values (PlanDMLPK and
+// PlanUpsertPK) are serialized. A transaction waits at most once, for
+// its first statement, and keeps its slot until it is concluded.
+// Waiting again later could deadlock with a transaction that waits
+// for the rows we already locked in MySQL.
+
+// txQueue is the queue of transactions for one row.
+type txQueue struct {
+	// size is the number of transactions running or waiting.
+	size int
+	// availableSlots has one token per running transaction.
+	availableSlots chan struct{}
+}
+
+// TxSerializer serializes the transactions on the same row.
+type TxSerializer struct {
+	maxQueueSize           int
+	concurrentTransactions int
+	queryServiceStats      *QueryServiceStats
+
+	mu     sync.Mutex
+	queues map[string]*txQueue
+}
+
+// NewTxSerializer creates a TxSerializer from the config.
+// It returns nil if the hot row protection is disabled.
+func NewTxSerializer(config Config, queryServiceStats *QueryServiceStats) *TxSerializer {
+	if !config.EnableHotRowProtection {
+		return nil
+	}
+	concurrentTransactions := config.HotRowProtectionConcurrentTransactions
+	if concurrentTransactions < 1 {
+		concurrentTransactions = 1
+	}
+	return &TxSerializer{
+		maxQueueSize:           config.HotRowProtectionMaxQueueSize,
+		concurrentTransactions: concurrentTransactions,
+		queryServiceStats:      queryServiceStats,
+		queues:                 make(map[string]*txQueue),
+	}
+}
+
+// Wait blocks until the transaction for the given key can run. The
+// returned done function must be called once the transaction is
+// concluded. waited is true if the transaction had to wait for other
+// transactions on the same key. If the queue for the key is full, or
+// ctx is done before the transaction gets its turn, a TabletError is
+// returned.
+func (t *TxSerializer) Wait(ctx context.Context, key, table string) (done func(), waited bool, err error) {
+	t.mu.Lock()
+	q, ok := t.queues[key]
+	if !ok {
+		q = &txQueue{availableSlots: make(chan struct{}, t.concurrentTransactions)}
+		t.queues[key] = q
+	}
+	if q.size >= t.maxQueueSize {
+		t.mu.Unlock()
+		t.queryServiceStats.HotRowQueueExceeded.Add(table, 1)
+		return nil, false, NewTabletError(vtrpcpb.ErrorCode_TRANSIENT_ERROR,
+			"hot row protection: too many queued transactions (%d >= %d) for the same row (table + primary key: %v)", q.size, t.maxQueueSize, key)
+	}
+	q.size++
+	t.mu.Unlock()
+
+	select {
+	case q.availableSlots <- struct{}{}:
+	default:
+		waited = true
+		t.queryServiceStats.HotRowWaits.Add(table, 1)
+		defer t.queryServiceStats.WaitStats.Record("HotRow", time.Now())
+		select {
+		case q.availableSlots <- struct{}{}:
+		case <-ctx.Done():
+			t.release(key, q, false)
+			return nil, true, NewTabletError(vtrpcpb.ErrorCode_DEADLINE_EXCEEDED,
+				"hot row protection: gave up waiting for the transactions on the same row (table + primary key: %v): %v", key, ctx.Err())
+		}
+	}
+
+	var once sync.Once
+	return func() {
+		once.Do(func() { t.release(key, q, true) })
+	}, waited, nil
+}
+
+// release removes a transaction from the queue of the key.
+func (t *TxSerializer) release(key string, q *txQueue, hasSlot bool) {
+	if hasSlot {
+		<-q.availableSlots
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	q.size--
+	if q.size == 0 {
+		delete(t.queues, key)
+	}
+}
+
+// Pending returns the number of transactions running or waiting for
+// the given key.
+func (t *TxSerializer) Pending(key string) int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if q, ok := t.queues[key]; ok {
+		return q.size
+	}
+	return 0
+}
+
+// hotRowKey returns the key the transactions on the given rows are
+// serialized by.
+func hotRowKey(table string, pkRows [][]sqltypes.Value) string {
+	buf := bytes.NewBufferString(table)
+	buf.WriteString(" ")
+	for i, row := range pkRows {
+		if i > 0 {
+			buf.WriteString(",")
+		}
+		buf.WriteString("(")
+		for j, v := range row {
+			if j > 0 {
+				buf.WriteString(",")
+			}
+			v.EncodeSQL(buf)
+		}
+		buf.WriteString(")")
+	}
+	return buf.String()
+}
@@ -0,0 +1,127 @@
+// Copyright 2016, Google Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tabletserver
+
+import (
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/youtube/vitess/go/sqltypes"
+
+	vtrpcpb "github.com/youtube/vitess/go/vt/proto/vtrpc"
+)
+
+func newTestTxSerializer(maxQueueSize, concurrentTransactions int) *TxSerializer {
+	config := DefaultQsConfig
+	config.EnableHotRowProtection = true
+	config.HotRowProtectionMaxQueueSize = maxQueueSize
+	config.HotRowProtectionConcurrentTransactions = concurrentTransactions
+	return NewTxSerializer(config, NewQueryServiceStats("", false))
+}
+
+func TestTxSerializerDisabled(t *testing.T) {
+	if txs := NewTxSerializer(DefaultQsConfig, NewQueryServiceStats("", false)); txs != nil {
+		t.Errorf("NewTxSerializer() = %v, want nil when the hot row protection is disabled", txs)
+	}
+}
+
+func TestTxSerializer(t *testing.T) {
+	txs := newTestTxSerializer(3, 1)
+	ctx := context.Background()
+
+	done1, waited, err := txs.Wait(ctx, "t1 (1)", "t1")
+	if err != nil || waited {
+		t.Fatalf("Wait() = %v, %v, want no wait", waited, err)
+	}
+	// Other rows are independent.
+	doneOther, waited, err := txs.Wait(ctx, "t1 (2)", "t1")
+	if err != nil || waited {
+		t.Fatalf("Wait() = %v, %v, want no wait", waited, err)
+	}
+	doneOther()
+
+	// The second transaction on the row waits for the first one.
+	result := make(chan error)
+	go func() {
+		done2, waited, err := txs.Wait(ctx, "t1 (1)", "t1")
+		if err == nil {
+			if !waited {
+				t.Errorf("second transaction should have waited")
+			}
+			done2()
+		}
+		result <- err
+	}()
+	for txs.Pending("t1 (1)") != 2 {
+		time.Sleep(time.Millisecond)
+	}
+	select {
+	case err := <-result:
+		t.Fatalf("second transaction didn't wait: %v", err)
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	// The third one times out, the fourth one is rejected right away.
+	shortCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	_, waited, err = txs.Wait(shortCtx, "t1 (1)", "t1")
+	if terr, ok := err.(*TabletError); !ok || terr.ErrorCode != vtrpcpb.ErrorCode_DEADLINE_EXCEEDED || !waited {
+		t.Errorf("Wait() = %v, %v, want DEADLINE_EXCEEDED after waiting", waited, err)
+	}
+	go func() {
+		txs.Wait(ctx, "t1 (1)", "t1")
+	}()
+	for txs.Pending("t1 (1)") != 3 {
+		time.Sleep(time.Millisecond)
+	}
+	_, _, err = txs.Wait(ctx, "t1 (1)", "t1")
+	if terr, ok := err.(*TabletError); !ok || terr.ErrorCode != vtrpcpb.ErrorCode_TRANSIENT_ERROR {
+		t.Errorf("Wait() = %v, want TRANSIENT_ERROR", err)
+	}
+	if got := txs.queryServiceStats.HotRowQueueExceeded.Counts()["t1"]; got != 1 {
+		t.Errorf("HotRowQueueExceeded = %v, want 1", got)
+	}
+
+	// Releasing the first transaction twice is fine.
+	done1()
+	done1()
+	if err := <-result; err != nil {
+		t.Errorf("second transaction failed: %v", err)
+	}
+	if got := txs.queryServiceStats.HotRowWaits.Counts()["t1"]; got != 3 {
+		t.Errorf("HotRowWaits = %v, want 3", got)
+	}
+}
+
+func TestTxSerializerConcurrentTransactions(t *testing.T) {
+	txs := newTestTxSerializer(10, 2)
+	ctx := context.Background()
+	done1, _, _ := txs.Wait(ctx, "t1 (1)", "t1")
+	done2, waited, err := txs.Wait(ctx, "t1 (1)", "t1")
+	if err != nil || waited {
+		t.Fatalf("Wait() = %v, %v, want no wait for the second concurrent transaction", waited, err)
+	}
+	done1()
+	done2()
+	if got := txs.Pending("t1 (1)"); got != 0 {
+		t.Errorf("Pending() = %v, want 0", got)
+	}
+	if len(txs.queues) != 0 {
+		t.Errorf("queues were not cleaned up: %v", txs.queues)
+	}
+}
+
+func TestHotRowKey(t *testing.T) {
+	got := hotRowKey("t1", [][]sqltypes.Value{
+		{sqltypes.MakeTrusted(sqltypes.Int64, []byte("1")), sqltypes.MakeTrusted(sqltypes.VarChar, []byte("a'b"))},
+		{sqltypes.MakeTrusted(sqltypes.Int64, []byte("2")), sqltypes.MakeTrusted(sqltypes.VarChar, []byte("c"))},
+	})
+	want := `t1 (1,'a\'b'),(2,'c')`
+	if got != want {
+		t.Errorf("hotRowKey() = %v, want %v", got, want)
+	}
+}
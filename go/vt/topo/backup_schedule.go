@@ -0,0 +1,210 @@
+package topo
+
+import (
+	"encoding/json"
+	"path"
+
+	"golang.org/x/net/context"
+)
+
+// This file provides the utility methods to save / retrieve backup
+// schedules in the topology Backend. A backup schedule describes when
+// the periodic backups of a shard are taken, and keeps the history of
+// the recent runs. It is stored as JSON in the global cell.
+
+const (
+	backupSchedulesPath    = "/backup_schedules"
+	backupScheduleFilename = "BackupSchedule"
+
+	// MaxBackupRunHistory is the number of past runs kept in a
+	// BackupSchedule.
+	MaxBackupRunHistory = 20
+)
+
+func pathForBackupSchedule(keyspace, shard string) string {
+	return path.Join(backupSchedulesPath, keyspace, shard, backupScheduleFilename)
+}
+
+// BackupRun describes one run of a scheduled backup.
+type BackupRun struct {
+	// Tablet is the alias of the tablet that took the backup.
+	// It is empty if no suitable tablet could be found.
+	Tablet string
+
+	// Owner identifies the vtctld process that runs the backup.
+	Owner string
+
+	// StartTime and EndTime are in seconds since the epoch.
+	// EndTime is 0 while the backup is running.
+	StartTime int64
+	EndTime   int64
+
+	// Error is set if the backup failed.
+	Error string
+}
+
+// BackupSchedule is the periodic backup configuration of a shard,
+// and its recent history.
+type BackupSchedule struct {
+	// Spec is the cron-like spec of the schedule, see go/cron.
+	Spec string
+
+	// TabletTypes are the types of tablet that can take the backup,
+	// in order of preference.
+	TabletTypes []string
+
+	// MaxReplicationLagSeconds is the maximum replication lag of a
+	// tablet that takes the backup. 0 means no limit.
+	MaxReplicationLagSeconds int64
+
+	// Concurrency is passed to the Backup RPC.
+	Concurrency int
+
+	// Disabled pauses the schedule.
+	Disabled bool
+
+	// LastScheduledTime is the time (in seconds since the epoch)
+	// the schedule last fired. The next run is computed from it.
+	LastScheduledTime int64
+
+	// Running is set while a backup is in progress. It guarantees
+	// at most one scheduled backup runs for the shard at a time.
+	Running *BackupRun
+
+	// History contains the most recent runs, oldest first.
+	History []*BackupRun
+}
+
+// AddRun appends a finished run to the history, dropping the oldest
+// runs so at most MaxBackupRunHistory runs are kept.
+func (bs *BackupSchedule) AddRun(run *BackupRun) {
+	bs.History = append(bs.History, run)
+	if len(bs.History) > MaxBackupRunHistory {
+		bs.History = bs.History[len(bs.History)-MaxBackupRunHistory:]
+	}
+}
+
+// BackupScheduleInfo is a meta struct that contains the version of a
+// BackupSchedule, and the shard it applies to.
+type BackupScheduleInfo struct {
+	version  Version
+	keyspace string
+	shard    string
+	*BackupSchedule
+}
+
+// Keyspace returns the keyspace the schedule applies to.
+func (bsi *BackupScheduleInfo) Keyspace() string {
+	return bsi.keyspace
+}
+
+// Shard returns the shard the schedule applies to.
+func (bsi *BackupScheduleInfo) Shard() string {
+	return bsi.shard
+}
+
+// GetBackupScheduleShards returns the shards that have a backup
+// schedule, as a map of keyspace to shard names.
+func (ts Server) GetBackupScheduleShards(ctx context.Context) (map[string][]string, error) {
+	keyspaces, err := ts.ListDir(ctx, "global", backupSchedulesPath)
+	switch err {
+	case ErrNoNode:
+		return nil, nil
+	case nil:
+	default:
+		return nil, err
+	}
+
+	result := make(map[string][]string)
+	for _, keyspace := range keyspaces {
+		shards, err := ts.ListDir(ctx, "global", path.Join(backupSchedulesPath, keyspace))
+		switch err {
+		case ErrNoNode:
+			// Deleted in the meantime.
+		case nil:
+			result[keyspace] = shards
+		default:
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// CreateBackupSchedule creates the backup schedule of a shard.
+// It returns ErrNodeExists if there is one already.
+func (ts Server) CreateBackupSchedule(ctx context.Context, keyspace, shard string, bs *BackupSchedule) (*BackupScheduleInfo, error) {
+	contents, err := json.MarshalIndent(bs, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	version, err := ts.Create(ctx, "global", pathForBackupSchedule(keyspace, shard), contents)
+	if err != nil {
+		return nil, err
+	}
+	return &BackupScheduleInfo{
+		version:        version,
+		keyspace:       keyspace,
+		shard:          shard,
+		BackupSchedule: bs,
+	}, nil
+}
+
+// GetBackupSchedule reads the backup schedule of a shard.
+func (ts Server) GetBackupSchedule(ctx context.Context, keyspace, shard string) (*BackupScheduleInfo, error) {
+	contents, version, err := ts.Get(ctx, "global", pathForBackupSchedule(keyspace, shard))
+	if err != nil {
+		return nil, err
+	}
+	bs := &BackupSchedule{}
+	if err := json.Unmarshal(contents, bs); err != nil {
+		return nil, err
+	}
+	return &BackupScheduleInfo{
+		version:        version,
+		keyspace:       keyspace,
+		shard:          shard,
+		BackupSchedule: bs,
+	}, nil
+}
+
+// SaveBackupSchedule saves the BackupScheduleInfo object. If the
+// version is not good any more, ErrBadVersion is returned.
+func (ts Server) SaveBackupSchedule(ctx context.Context, bsi *BackupScheduleInfo) error {
+	contents, err := json.MarshalIndent(bsi.BackupSchedule, "", "  ")
+	if err != nil {
+		return err
+	}
+	version, err := ts.Update(ctx, "global", pathForBackupSchedule(bsi.keyspace, bsi.shard), contents, bsi.version)
+	if err != nil {
+		return err
+	}
+	bsi.version = version
+	return nil
+}
+
+// UpdateBackupScheduleFields is a high level helper to read a backup
+// schedule, update it, and save it back. If the update method returns
+// ErrNoUpdateNeeded, nothing is written, and nil, nil is returned.
+// If the version changed in the meantime, it is retried.
+func (ts Server) UpdateBackupScheduleFields(ctx context.Context, keyspace, shard string, update func(*BackupSchedule) error) (*BackupScheduleInfo, error) {
+	for {
+		bsi, err := ts.GetBackupSchedule(ctx, keyspace, shard)
+		if err != nil {
+			return nil, err
+		}
+		if err = update(bsi.BackupSchedule); err != nil {
+			if err == ErrNoUpdateNeeded {
+				return nil, nil
+			}
+			return nil, err
+		}
+		if err = ts.SaveBackupSchedule(ctx, bsi); err != ErrBadVersion {
+			return bsi, err
+		}
+	}
+}
+
+// DeleteBackupSchedule deletes the backup schedule of a shard.
+func (ts Server) DeleteBackupSchedule(ctx context.Context, keyspace, shard string) error {
+	return ts.Delete(ctx, "global", pathForBackupSchedule(keyspace, shard), nil)
+}
@@ -0,0 +1,69 @@
+package topo
+
+import (
+	"path"
+
+	"golang.org/x/net/context"
+)
+
+// This file provides the utility methods to save / retrieve / watch
+// the custom query rules of the tablets, in the topology Backend.
+// Rules can be set for a whole keyspace, and for a single shard. They
+// are stored in the global cell, in the JSON format of the tabletserver
+// QueryRules. The topo package doesn't parse them.
+
+const (
+	queryRulesPath     = "/query_rules"
+	queryRulesFilename = "QueryRules"
+)
+
+// pathForQueryRules returns the path of the rules of a keyspace, or of
+// a shard if shard is not empty.
+func pathForQueryRules(keyspace, shard string) string {
+	if shard == "" {
+		return path.Join(queryRulesPath, keyspace, queryRulesFilename)
+	}
+	return path.Join(queryRulesPath, keyspace, "shards", shard, queryRulesFilename)
+}
+
+// GetQueryRules returns the custom query rules of a keyspace, or of a
+// shard if shard is not empty. It returns ErrNoNode if there are none.
+func (ts Server) GetQueryRules(ctx context.Context, keyspace, shard string) ([]byte, error) {
+	data, _, err := ts.Get(ctx, "global", pathForQueryRules(keyspace, shard))
+	return data, err
+}
+
+// GetQueryRulesShards returns the shards of a keyspace that have their
+// own custom query rules.
+func (ts Server) GetQueryRulesShards(ctx context.Context, keyspace string) ([]string, error) {
+	shards, err := ts.ListDir(ctx, "global", path.Join(queryRulesPath, keyspace, "shards"))
+	if err == ErrNoNode {
+		return nil, nil
+	}
+	return shards, err
+}
+
+// SaveQueryRules saves the custom query rules of a keyspace, or of a
+// shard if shard is not empty. Empty rules are deleted.
+func (ts Server) SaveQueryRules(ctx context.Context, keyspace, shard string, data []byte) error {
+	filePath := pathForQueryRules(keyspace, shard)
+	if len(data) == 0 {
+		err := ts.Delete(ctx, "global", filePath, nil)
+		if err == ErrNoNode {
+			return nil
+		}
+		return err
+	}
+	_, err := ts.Update(ctx, "global", filePath, data, nil)
+	if err == ErrNoNode {
+		_, err = ts.Create(ctx, "global", filePath, data)
+	}
+	return err
+}
+
+// WatchQueryRules watches the custom query rules of a keyspace, or of a
+// shard if shard is not empty. It has the same contract as
+// Backend.Watch.
+func (ts Server) WatchQueryRules(ctx context.Context, keyspace, shard string) (*WatchData, <-chan *WatchData, CancelFunc) {
+	return ts.Watch(ctx, "global", pathForQueryRules(keyspace, shard))
+}
@@ -0,0 +1,231 @@
+package topo
+
+import (
+	"encoding/json"
+	"path"
+	"sort"
+
+	"golang.org/x/net/context"
+)
+
+// This file provides the utility methods to save / retrieve online
+// schema migrations in the topology Backend. A schema migration is
+// an ALTER TABLE statement queued by ApplySchema -online. vtctld
+// releases the migrations of a keyspace one at a time, and the master
+// tablet of each shard runs them. It is stored as JSON in the global
+// cell.
+
+const (
+	schemaMigrationsPath    = "/schema_migrations"
+	schemaMigrationFilename = "SchemaMigration"
+)
+
+// The states of a SchemaMigration, and of each of its shards.
+const (
+	// SchemaMigrationQueued is the state of a migration that waits
+	// for the previous migrations of its keyspace to finish.
+	SchemaMigrationQueued = "queued"
+
+	// SchemaMigrationReady is the state of a shard that has been
+	// released by vtctld, but not picked up by its master yet.
+	SchemaMigrationReady = "ready"
+
+	// SchemaMigrationRunning is the state of a migration released by
+	// vtctld, and of a shard picked up by its master.
+	SchemaMigrationRunning = "running"
+
+	// SchemaMigrationComplete is the state of a migration that ran on
+	// all shards, and of a shard that was cut over.
+	SchemaMigrationComplete = "complete"
+
+	// SchemaMigrationFailed is the state of a migration that failed on
+	// at least one shard, and of a shard where it failed.
+	SchemaMigrationFailed = "failed"
+
+	// SchemaMigrationCancelled is the state of a migration that was
+	// cancelled, and of its shards that were not complete.
+	SchemaMigrationCancelled = "cancelled"
+)
+
+func pathForSchemaMigration(keyspace, id string) string {
+	return path.Join(schemaMigrationsPath, keyspace, id, schemaMigrationFilename)
+}
+
+// ShardSchemaMigration is the progress of a schema migration on a shard.
+type ShardSchemaMigration struct {
+	// State is one of the SchemaMigration* constants.
+	State string
+
+	// Tablet is the alias of the master tablet running the migration.
+	Tablet string
+
+	// RowsCopied is the number of rows copied to the shadow table,
+	// and RowsTotal the estimated number of rows of the table.
+	RowsCopied int64
+	RowsTotal  int64
+
+	// StartTime, UpdateTime and EndTime are in seconds since the
+	// epoch. UpdateTime is refreshed by the tablet while it runs the
+	// migration.
+	StartTime  int64
+	UpdateTime int64
+	EndTime    int64
+
+	// Error is set if the migration failed on the shard.
+	Error string
+}
+
+// Done returns true if the shard is in a final state.
+func (ssm *ShardSchemaMigration) Done() bool {
+	switch ssm.State {
+	case SchemaMigrationComplete, SchemaMigrationFailed, SchemaMigrationCancelled:
+		return true
+	}
+	return false
+}
+
+// SchemaMigration is an online schema change of a keyspace.
+type SchemaMigration struct {
+	// SQL is the ALTER TABLE statement, and Table the table it alters.
+	SQL   string
+	Table string
+
+	// State is one of the SchemaMigration* constants.
+	State string
+
+	// CancelRequested is set by CancelSchemaMigration. The tablets
+	// stop the migration and drop its shadow table as soon as they
+	// see it.
+	CancelRequested bool
+
+	// CreateTime, StartTime and EndTime are in seconds since the epoch.
+	CreateTime int64
+	StartTime  int64
+	EndTime    int64
+
+	// Shards has the progress of each shard of the keyspace.
+	Shards map[string]*ShardSchemaMigration
+}
+
+// SchemaMigrationInfo is a meta struct that contains the version of a
+// SchemaMigration, its keyspace and its id.
+type SchemaMigrationInfo struct {
+	version  Version
+	keyspace string
+	id       string
+	*SchemaMigration
+}
+
+// Keyspace returns the keyspace of the migration.
+func (smi *SchemaMigrationInfo) Keyspace() string {
+	return smi.keyspace
+}
+
+// ID returns the id of the migration.
+func (smi *SchemaMigrationInfo) ID() string {
+	return smi.id
+}
+
+// GetSchemaMigrationKeyspaces returns the keyspaces that have
+// schema migrations.
+func (ts Server) GetSchemaMigrationKeyspaces(ctx context.Context) ([]string, error) {
+	keyspaces, err := ts.ListDir(ctx, "global", schemaMigrationsPath)
+	if err == ErrNoNode {
+		return nil, nil
+	}
+	return keyspaces, err
+}
+
+// GetSchemaMigrationIDs returns the ids of the schema migrations of a
+// keyspace, sorted. Ids sort in the order the migrations were created.
+func (ts Server) GetSchemaMigrationIDs(ctx context.Context, keyspace string) ([]string, error) {
+	ids, err := ts.ListDir(ctx, "global", path.Join(schemaMigrationsPath, keyspace))
+	switch err {
+	case ErrNoNode:
+		return nil, nil
+	case nil:
+	default:
+		return nil, err
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+// CreateSchemaMigration creates a schema migration.
+// It returns ErrNodeExists if there is one already with that id.
+func (ts Server) CreateSchemaMigration(ctx context.Context, keyspace, id string, sm *SchemaMigration) (*SchemaMigrationInfo, error) {
+	contents, err := json.MarshalIndent(sm, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	version, err := ts.Create(ctx, "global", pathForSchemaMigration(keyspace, id), contents)
+	if err != nil {
+		return nil, err
+	}
+	return &SchemaMigrationInfo{
+		version:         version,
+		keyspace:        keyspace,
+		id:              id,
+		SchemaMigration: sm,
+	}, nil
+}
+
+// GetSchemaMigration reads a schema migration.
+func (ts Server) GetSchemaMigration(ctx context.Context, keyspace, id string) (*SchemaMigrationInfo, error) {
+	contents, version, err := ts.Get(ctx, "global", pathForSchemaMigration(keyspace, id))
+	if err != nil {
+		return nil, err
+	}
+	sm := &SchemaMigration{}
+	if err := json.Unmarshal(contents, sm); err != nil {
+		return nil, err
+	}
+	return &SchemaMigrationInfo{
+		version:         version,
+		keyspace:        keyspace,
+		id:              id,
+		SchemaMigration: sm,
+	}, nil
+}
+
+// SaveSchemaMigration saves the SchemaMigrationInfo object. If the
+// version is not good any more, ErrBadVersion is returned.
+func (ts Server) SaveSchemaMigration(ctx context.Context, smi *SchemaMigrationInfo) error {
+	contents, err := json.MarshalIndent(smi.SchemaMigration, "", "  ")
+	if err != nil {
+		return err
+	}
+	version, err := ts.Update(ctx, "global", pathForSchemaMigration(smi.keyspace, smi.id), contents, smi.version)
+	if err != nil {
+		return err
+	}
+	smi.version = version
+	return nil
+}
+
+// UpdateSchemaMigrationFields is a high level helper to read a schema
+// migration, update it, and save it back. If the update method returns
+// ErrNoUpdateNeeded, nothing is written, and nil, nil is returned.
+// If the version changed in the meantime, it is retried.
+func (ts Server) UpdateSchemaMigrationFields(ctx context.Context, keyspace, id string, update func(*SchemaMigration) error) (*SchemaMigrationInfo, error) {
+	for {
+		smi, err := ts.GetSchemaMigration(ctx, keyspace, id)
+		if err != nil {
+			return nil, err
+		}
+		if err = update(smi.SchemaMigration); err != nil {
+			if err == ErrNoUpdateNeeded {
+				return nil, nil
+			}
+			return nil, err
+		}
+		if err = ts.SaveSchemaMigration(ctx, smi); err != ErrBadVersion {
+			return smi, err
+		}
+	}
+}
+
+// DeleteSchemaMigration deletes a schema migration.
+func (ts Server) DeleteSchemaMigration(ctx context.Context, keyspace, id string) error {
+	return ts.Delete(ctx, "global", pathForSchemaMigration(keyspace, id), nil)
+}
@@ -393,6 +393,44 @@ func (si *ShardInfo) UpdateSourceBlacklistedTables(ctx context.Context, tabletTy
 	return nil
 }
 
+// AddSourceBlacklistedTables adds the listed tables to the
+// BlacklistedTables of a tablet type, in all cells. Unlike
+// UpdateSourceBlacklistedTables, the list can grow a few tables at a
+// time, as tables moved by MoveTables are cut over individually.
+// This function should be called while holding the keyspace lock.
+func (si *ShardInfo) AddSourceBlacklistedTables(ctx context.Context, tabletType topodatapb.TabletType, tables []string) error {
+	if err := CheckKeyspaceLocked(ctx, si.keyspace); err != nil {
+		return err
+	}
+	tc := si.GetTabletControl(tabletType)
+	if tc == nil {
+		si.TabletControls = append(si.TabletControls, &topodatapb.Shard_TabletControl{
+			TabletType:        tabletType,
+			BlacklistedTables: append([]string(nil), tables...),
+		})
+		return nil
+	}
+	if tc.DisableQueryService {
+		return fmt.Errorf("cannot safely alter BlacklistedTables as DisableQueryService is set for shard %v/%v", si.keyspace, si.shardName)
+	}
+	if len(tc.Cells) > 0 {
+		return fmt.Errorf("cannot add BlacklistedTables for type %v in all cells of shard %v/%v, they are only set in cells %v", tabletType, si.keyspace, si.shardName, tc.Cells)
+	}
+	for _, table := range tables {
+		found := false
+		for _, t := range tc.BlacklistedTables {
+			if t == table {
+				found = true
+				break
+			}
+		}
+		if !found {
+			tc.BlacklistedTables = append(tc.BlacklistedTables, table)
+		}
+	}
+	return nil
+}
+
 // UpdateDisableQueryService will make sure the disableQueryService is
 // set appropriately in the shard record. Note we don't support a lot
 // of the corner cases:
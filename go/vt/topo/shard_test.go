@@ -149,6 +149,40 @@ func TestUpdateSourceBlacklistedTables(t *testing.T) {
 	}
 }
 
+func TestAddSourceBlacklistedTables(t *testing.T) {
+	si := NewShardInfo("ks", "sh", &topodatapb.Shard{
+		Cells: []string{"first", "second"},
+	}, 1)
+
+	// check we enforce the keyspace lock
+	ctx := context.Background()
+	if err := si.AddSourceBlacklistedTables(ctx, topodatapb.TabletType_MASTER, []string{"t1"}); err == nil || err.Error() != "keyspace ks is not locked (no locksInfo)" {
+		t.Fatalf("unlocked keyspace produced wrong error: %v", err)
+	}
+	ctx = lockedKeyspaceContext("ks")
+
+	// add one table, then more, see the list grow
+	if err := si.AddSourceBlacklistedTables(ctx, topodatapb.TabletType_MASTER, []string{"t1"}); err != nil {
+		t.Fatalf("first add failed: %v", err)
+	}
+	if err := si.AddSourceBlacklistedTables(ctx, topodatapb.TabletType_MASTER, []string{"t1", "t2"}); err != nil || !reflect.DeepEqual(si.TabletControls, []*topodatapb.Shard_TabletControl{
+		{
+			TabletType:        topodatapb.TabletType_MASTER,
+			BlacklistedTables: []string{"t1", "t2"},
+		},
+	}) {
+		t.Fatalf("second add failed: %v %v", err, si)
+	}
+
+	// a list only set in some cells cannot be extended to all cells
+	if err := si.UpdateSourceBlacklistedTables(ctx, topodatapb.TabletType_RDONLY, []string{"first"}, false, []string{"t1"}); err != nil {
+		t.Fatalf("one cell add failed: %v", err)
+	}
+	if err := si.AddSourceBlacklistedTables(ctx, topodatapb.TabletType_RDONLY, []string{"t2"}); err == nil || err.Error() != "cannot add BlacklistedTables for type RDONLY in all cells of shard ks/sh, they are only set in cells [first]" {
+		t.Fatalf("adding to a partial list should fail: %v", err)
+	}
+}
+
 func TestUpdateDisableQueryService(t *testing.T) {
 	si := NewShardInfo("ks", "sh", &topodatapb.Shard{
 		Cells: []string{"first", "second", "third"},
@@ -1,6 +1,9 @@
 package topo
 
 import (
+	"fmt"
+
+	"github.com/golang/protobuf/proto"
 	"golang.org/x/net/context"
 
 	vschemapb "github.com/youtube/vitess/go/vt/proto/vschema"
@@ -17,3 +20,51 @@ func (ts Server) SaveVSchema(ctx context.Context, keyspace string, vschema *vsch
 
 	return ts.Impl.SaveVSchema(ctx, keyspace, vschema)
 }
+
+// routingRulesFile is the path of the RoutingRules object, in the
+// global cell.
+const routingRulesFile = "/RoutingRules"
+
+// GetRoutingRules returns the routing rules. It returns an empty object
+// if there are none.
+func (ts Server) GetRoutingRules(ctx context.Context) (*vschemapb.RoutingRules, error) {
+	rr := &vschemapb.RoutingRules{}
+	data, _, err := ts.Get(ctx, "global", routingRulesFile)
+	switch err {
+	case nil:
+	case ErrNoNode:
+		return rr, nil
+	default:
+		return nil, err
+	}
+	if err := proto.Unmarshal(data, rr); err != nil {
+		return nil, fmt.Errorf("bad routing rules data %q: %v", data, err)
+	}
+	return rr, nil
+}
+
+// SaveRoutingRules first validates the routing rules, then saves them.
+// Saving an empty list deletes the rules.
+func (ts Server) SaveRoutingRules(ctx context.Context, routingRules *vschemapb.RoutingRules) error {
+	if err := vindexes.ValidateRoutingRules(routingRules); err != nil {
+		return err
+	}
+
+	if len(routingRules.GetRules()) == 0 {
+		err := ts.Delete(ctx, "global", routingRulesFile, nil)
+		if err == ErrNoNode {
+			return nil
+		}
+		return err
+	}
+
+	data, err := proto.Marshal(routingRules)
+	if err != nil {
+		return err
+	}
+	_, err = ts.Update(ctx, "global", routingRulesFile, data, nil)
+	if err == ErrNoNode {
+		_, err = ts.Create(ctx, "global", routingRulesFile, data)
+	}
+	return err
+}
@@ -67,6 +67,15 @@ func RebuildVSchema(ctx context.Context, log logutil.Logger, ts topo.Server, cel
 		return finalErr
 	}
 
+	// add the routing rules
+	rr, err := ts.GetRoutingRules(ctx)
+	if err != nil {
+		return fmt.Errorf("GetRoutingRules failed: %v", err)
+	}
+	if len(rr.Rules) > 0 {
+		srvVSchema.RoutingRules = rr
+	}
+
 	// now save the SrvVSchema in all cells in parallel
 	for _, cell := range cells {
 		wg.Add(1)
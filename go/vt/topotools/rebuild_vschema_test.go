@@ -135,4 +135,44 @@ func TestRebuildVSchema(t *testing.T) {
 			t.Errorf("unexpected GetSrvVSchema(%v) result: %v %v", cell, v, err)
 		}
 	}
+
+	// save routing rules, rebuild, should see them
+	rules := &vschemapb.RoutingRules{
+		Rules: []*vschemapb.RoutingRule{
+			{
+				FromTable: "table1",
+				ToTable:   "ks1.table1",
+			},
+		},
+	}
+	if err := ts.SaveRoutingRules(ctx, rules); err != nil {
+		t.Fatalf("SaveRoutingRules failed: %v", err)
+	}
+	if err := RebuildVSchema(ctx, logger, ts, nil); err != nil {
+		t.Errorf("RebuildVSchema failed: %v", err)
+	}
+	wanted3 := &vschemapb.SrvVSchema{
+		Keyspaces: map[string]*vschemapb.Keyspace{
+			"ks1": keyspace1,
+		},
+		RoutingRules: rules,
+	}
+	for _, cell := range cells {
+		if v, err := ts.GetSrvVSchema(ctx, cell); err != nil || !proto.Equal(v, wanted3) {
+			t.Errorf("unexpected GetSrvVSchema(%v) result: %v %v", cell, v, err)
+		}
+	}
+
+	// clear the routing rules, rebuild, they should be gone
+	if err := ts.SaveRoutingRules(ctx, &vschemapb.RoutingRules{}); err != nil {
+		t.Fatalf("SaveRoutingRules failed: %v", err)
+	}
+	if err := RebuildVSchema(ctx, logger, ts, nil); err != nil {
+		t.Errorf("RebuildVSchema failed: %v", err)
+	}
+	for _, cell := range cells {
+		if v, err := ts.GetSrvVSchema(ctx, cell); err != nil || !proto.Equal(v, wanted1) {
+			t.Errorf("unexpected GetSrvVSchema(%v) result: %v %v", cell, v, err)
+		}
+	}
 }
@@ -8,9 +8,14 @@ import (
 	"flag"
 	"fmt"
 	"io"
+	"strings"
+	"time"
 
+	"github.com/youtube/vitess/go/cron"
 	"github.com/youtube/vitess/go/vt/logutil"
+	"github.com/youtube/vitess/go/vt/mysqlctl"
 	"github.com/youtube/vitess/go/vt/mysqlctl/backupstorage"
+	"github.com/youtube/vitess/go/vt/topo"
 	"github.com/youtube/vitess/go/vt/topo/topoproto"
 	"github.com/youtube/vitess/go/vt/wrangler"
 	"golang.org/x/net/context"
@@ -27,6 +32,26 @@ func init() {
 		commandRemoveBackup,
 		"<keyspace/shard> <backup name>",
 		"Removes a backup for the BackupStorage."})
+	addCommand("Shards", command{
+		"PruneBackups",
+		commandPruneBackups,
+		"[-keep_last=N] [-keep_daily_days=D] [-keep_weekly_days=W] [-dry_run] <keyspace/shard|keyspace>",
+		"Removes the backups of a shard (or of all shards of a keyspace) that are not retained by the given policy. The most recent restorable backup is always kept."})
+	addCommand("Shards", command{
+		"SetBackupSchedule",
+		commandSetBackupSchedule,
+		"[-tablet_types=rdonly] [-max_replication_lag=0] [-concurrency=4] [-disabled] <keyspace/shard> <cron spec>",
+		"Sets the periodic backup schedule of a shard. The spec is either five cron fields ('minute hour day_of_month month day_of_week') or one of @hourly, @daily, @weekly, @monthly. The backups are taken by vtctld, if it runs with -backup_scheduler_interval."})
+	addCommand("Shards", command{
+		"GetBackupSchedule",
+		commandGetBackupSchedule,
+		"<keyspace/shard>",
+		"Displays the backup schedule of a shard, and the history of its recent runs."})
+	addCommand("Shards", command{
+		"DeleteBackupSchedule",
+		commandDeleteBackupSchedule,
+		"<keyspace/shard>",
+		"Deletes the backup schedule of a shard."})
 
 	addCommand("Tablets", command{
 		"RestoreFromBackup",
@@ -87,6 +112,137 @@ func commandRemoveBackup(ctx context.Context, wr *wrangler.Wrangler, subFlags *f
 	return bs.RemoveBackup(bucket, name)
 }
 
+func commandPruneBackups(ctx context.Context, wr *wrangler.Wrangler, subFlags *flag.FlagSet, args []string) error {
+	keepLast := subFlags.Int("keep_last", 0, "Number of most recent restorable backups to keep")
+	keepDailyDays := subFlags.Int("keep_daily_days", 0, "Keep the most recent restorable backup of each day, for that many days")
+	keepWeeklyDays := subFlags.Int("keep_weekly_days", 0, "Keep the most recent restorable backup of each week, for that many days")
+	dryRun := subFlags.Bool("dry_run", false, "Only log the backups that would be removed")
+	if err := subFlags.Parse(args); err != nil {
+		return err
+	}
+	if subFlags.NArg() != 1 {
+		return fmt.Errorf("action PruneBackups requires <keyspace/shard|keyspace>")
+	}
+	policy := mysqlctl.BackupRetentionPolicy{
+		KeepLast:       *keepLast,
+		KeepDailyDays:  *keepDailyDays,
+		KeepWeeklyDays: *keepWeeklyDays,
+	}
+	if err := policy.Validate(); err != nil {
+		return err
+	}
+
+	var keyspace string
+	var shards []string
+	if strings.Contains(subFlags.Arg(0), "/") {
+		k, s, err := topoproto.ParseKeyspaceShard(subFlags.Arg(0))
+		if err != nil {
+			return err
+		}
+		keyspace = k
+		shards = []string{s}
+	} else {
+		keyspace = subFlags.Arg(0)
+		var err error
+		shards, err = wr.TopoServer().GetShardNames(ctx, keyspace)
+		if err != nil {
+			return err
+		}
+	}
+
+	bs, err := backupstorage.GetBackupStorage()
+	if err != nil {
+		return err
+	}
+	defer bs.Close()
+	now := time.Now().UTC()
+	for _, shard := range shards {
+		bucket := fmt.Sprintf("%v/%v", keyspace, shard)
+		if _, err := mysqlctl.PruneBackups(bs, bucket, policy, now, *dryRun, wr.Logger()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func commandSetBackupSchedule(ctx context.Context, wr *wrangler.Wrangler, subFlags *flag.FlagSet, args []string) error {
+	tabletTypes := subFlags.String("tablet_types", "rdonly", "Comma separated list of the tablet types that can take the backup, in order of preference")
+	maxReplicationLag := subFlags.Duration("max_replication_lag", 0, "Tablets lagging more than this are not used for backups. 0 means no limit")
+	concurrency := subFlags.Int("concurrency", 4, "Specifies the number of compression/checksum jobs to run simultaneously")
+	disabled := subFlags.Bool("disabled", false, "Pauses the schedule")
+	if err := subFlags.Parse(args); err != nil {
+		return err
+	}
+	if subFlags.NArg() != 2 {
+		return fmt.Errorf("action SetBackupSchedule requires <keyspace/shard> <cron spec>")
+	}
+	keyspace, shard, err := topoproto.ParseKeyspaceShard(subFlags.Arg(0))
+	if err != nil {
+		return err
+	}
+	spec := subFlags.Arg(1)
+	if _, err := cron.Parse(spec); err != nil {
+		return err
+	}
+	types := strings.Split(*tabletTypes, ",")
+	for _, tt := range types {
+		if _, err := topoproto.ParseTabletType(tt); err != nil {
+			return err
+		}
+	}
+	if _, err := wr.TopoServer().GetShard(ctx, keyspace, shard); err != nil {
+		return fmt.Errorf("cannot get shard %v/%v: %v", keyspace, shard, err)
+	}
+
+	update := func(bs *topo.BackupSchedule) error {
+		bs.Spec = spec
+		bs.TabletTypes = types
+		bs.MaxReplicationLagSeconds = int64(maxReplicationLag.Seconds())
+		bs.Concurrency = *concurrency
+		bs.Disabled = *disabled
+		return nil
+	}
+	_, err = wr.TopoServer().UpdateBackupScheduleFields(ctx, keyspace, shard, update)
+	if err == topo.ErrNoNode {
+		bs := &topo.BackupSchedule{}
+		update(bs)
+		_, err = wr.TopoServer().CreateBackupSchedule(ctx, keyspace, shard, bs)
+	}
+	return err
+}
+
+func commandGetBackupSchedule(ctx context.Context, wr *wrangler.Wrangler, subFlags *flag.FlagSet, args []string) error {
+	if err := subFlags.Parse(args); err != nil {
+		return err
+	}
+	if subFlags.NArg() != 1 {
+		return fmt.Errorf("action GetBackupSchedule requires <keyspace/shard>")
+	}
+	keyspace, shard, err := topoproto.ParseKeyspaceShard(subFlags.Arg(0))
+	if err != nil {
+		return err
+	}
+	bsi, err := wr.TopoServer().GetBackupSchedule(ctx, keyspace, shard)
+	if err != nil {
+		return err
+	}
+	return printJSON(wr.Logger(), bsi.BackupSchedule)
+}
+
+func commandDeleteBackupSchedule(ctx context.Context, wr *wrangler.Wrangler, subFlags *flag.FlagSet, args []string) error {
+	if err := subFlags.Parse(args); err != nil {
+		return err
+	}
+	if subFlags.NArg() != 1 {
+		return fmt.Errorf("action DeleteBackupSchedule requires <keyspace/shard>")
+	}
+	keyspace, shard, err := topoproto.ParseKeyspaceShard(subFlags.Arg(0))
+	if err != nil {
+		return err
+	}
+	return wr.TopoServer().DeleteBackupSchedule(ctx, keyspace, shard)
+}
+
 func commandRestoreFromBackup(ctx context.Context, wr *wrangler.Wrangler, subFlags *flag.FlagSet, args []string) error {
 	if err := subFlags.Parse(args); err != nil {
 		return err
@@ -0,0 +1,302 @@
+// Copyright 2016, Google Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vtctl
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/youtube/vitess/go/vt/tabletserver/planbuilder"
+	"github.com/youtube/vitess/go/vt/topo"
+	"github.com/youtube/vitess/go/vt/topo/topoproto"
+	"github.com/youtube/vitess/go/vt/wrangler"
+)
+
+// This file contains the commands to manage the custom query rules
+// stored in the topology, and applied by the vttablets started with
+// -topocustomrules. Rules can be set for a keyspace, and for a shard.
+//
+// The rules are validated here before they are saved, but vtctl
+// doesn't link the query service: the tablets parse them again, and
+// keep their previous rules if that fails.
+
+func init() {
+	addCommand("Schema, Version, Permissions", command{
+		"GetQueryRules",
+		commandGetQueryRules,
+		"<keyspace|keyspace/shard>",
+		"Displays the custom query rules stored in the topology for a keyspace or a shard."})
+	addCommand("Schema, Version, Permissions", command{
+		"SetQueryRules",
+		commandSetQueryRules,
+		"[-rules=<json>] [-rules-file=<file>] [-dry-run] <keyspace|keyspace/shard>",
+		"Validates and saves the custom query rules of a keyspace or a shard. The tablets started with -topocustomrules apply the rules of their keyspace, then the rules of their shard."})
+	addCommand("Schema, Version, Permissions", command{
+		"DeleteQueryRules",
+		commandDeleteQueryRules,
+		"<keyspace|keyspace/shard>",
+		"Deletes the custom query rules of a keyspace or a shard from the topology."})
+	addCommand("Schema, Version, Permissions", command{
+		"ValidateQueryRules",
+		commandValidateQueryRules,
+		"<keyspace>",
+		"Validates the custom query rules of a keyspace and of its shards, and checks the shards with rules exist."})
+}
+
+// parseQueryRulesTarget parses a <keyspace|keyspace/shard> parameter.
+func parseQueryRulesTarget(param string) (keyspace, shard string, err error) {
+	if !strings.Contains(param, "/") {
+		return param, "", nil
+	}
+	return topoproto.ParseKeyspaceShard(param)
+}
+
+func commandGetQueryRules(ctx context.Context, wr *wrangler.Wrangler, subFlags *flag.FlagSet, args []string) error {
+	if err := subFlags.Parse(args); err != nil {
+		return err
+	}
+	if subFlags.NArg() != 1 {
+		return fmt.Errorf("action GetQueryRules requires <keyspace|keyspace/shard>")
+	}
+	keyspace, shard, err := parseQueryRulesTarget(subFlags.Arg(0))
+	if err != nil {
+		return err
+	}
+	data, err := wr.TopoServer().GetQueryRules(ctx, keyspace, shard)
+	if err != nil {
+		return err
+	}
+	var out bytes.Buffer
+	if err := json.Indent(&out, data, "", "  "); err != nil {
+		// Display invalid rules as they are.
+		wr.Logger().Printf("%s\n", data)
+		return nil
+	}
+	wr.Logger().Printf("%v\n", out.String())
+	return nil
+}
+
+func commandSetQueryRules(ctx context.Context, wr *wrangler.Wrangler, subFlags *flag.FlagSet, args []string) error {
+	rules := subFlags.String("rules", "", "The custom query rules, in JSON")
+	rulesFile := subFlags.String("rules-file", "", "Identifies the file that contains the custom query rules")
+	dryRun := subFlags.Bool("dry-run", false, "Only validates the rules")
+	if err := subFlags.Parse(args); err != nil {
+		return err
+	}
+	if subFlags.NArg() != 1 {
+		return fmt.Errorf("action SetQueryRules requires <keyspace|keyspace/shard>")
+	}
+	keyspace, shard, err := parseQueryRulesTarget(subFlags.Arg(0))
+	if err != nil {
+		return err
+	}
+	data, err := getFileParam(*rules, *rulesFile, "rules")
+	if err != nil {
+		return err
+	}
+	if err := validateQueryRules([]byte(data)); err != nil {
+		return err
+	}
+	if shard == "" {
+		_, err = wr.TopoServer().GetKeyspace(ctx, keyspace)
+	} else {
+		_, err = wr.TopoServer().GetShard(ctx, keyspace, shard)
+	}
+	if err != nil {
+		return fmt.Errorf("cannot find %v: %v", subFlags.Arg(0), err)
+	}
+	if *dryRun {
+		return nil
+	}
+	return wr.TopoServer().SaveQueryRules(ctx, keyspace, shard, []byte(data))
+}
+
+func commandDeleteQueryRules(ctx context.Context, wr *wrangler.Wrangler, subFlags *flag.FlagSet, args []string) error {
+	if err := subFlags.Parse(args); err != nil {
+		return err
+	}
+	if subFlags.NArg() != 1 {
+		return fmt.Errorf("action DeleteQueryRules requires <keyspace|keyspace/shard>")
+	}
+	keyspace, shard, err := parseQueryRulesTarget(subFlags.Arg(0))
+	if err != nil {
+		return err
+	}
+	return wr.TopoServer().SaveQueryRules(ctx, keyspace, shard, nil)
+}
+
+func commandValidateQueryRules(ctx context.Context, wr *wrangler.Wrangler, subFlags *flag.FlagSet, args []string) error {
+	if err := subFlags.Parse(args); err != nil {
+		return err
+	}
+	if subFlags.NArg() != 1 {
+		return fmt.Errorf("action ValidateQueryRules requires <keyspace>")
+	}
+	keyspace := subFlags.Arg(0)
+	ts := wr.TopoServer()
+	shards, err := ts.GetShardNames(ctx, keyspace)
+	if err != nil {
+		return err
+	}
+	existing := make(map[string]bool)
+	for _, shard := range shards {
+		existing[shard] = true
+	}
+	ruleShards, err := ts.GetQueryRulesShards(ctx, keyspace)
+	if err != nil {
+		return err
+	}
+
+	var errs []string
+	for _, shard := range append([]string{""}, ruleShards...) {
+		name := keyspace
+		if shard != "" {
+			name = topoproto.KeyspaceShardString(keyspace, shard)
+			if !existing[shard] {
+				errs = append(errs, fmt.Sprintf("%v: shard doesn't exist", name))
+				continue
+			}
+		}
+		data, err := ts.GetQueryRules(ctx, keyspace, shard)
+		if err != nil {
+			if err == topo.ErrNoNode {
+				continue
+			}
+			return err
+		}
+		if err := validateQueryRules(data); err != nil {
+			errs = append(errs, fmt.Sprintf("%v: %v", name, err))
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid query rules:\n%v", strings.Join(errs, "\n"))
+	}
+	return nil
+}
+
+// queryRuleActions lists the actions of the tabletserver QueryRules.
+var queryRuleActions = map[string]bool{
+	"FAIL":       true,
+	"FAIL_RETRY": true,
+	"DELAY":      true,
+	"ADD_HINT":   true,
+	"CAP_RESULT": true,
+	"STREAM":     true,
+}
+
+// validateQueryRules checks the custom query rules have the JSON
+// format tabletserver.QueryRules.UnmarshalJSON expects.
+func validateQueryRules(data []byte) error {
+	var rules []map[string]interface{}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&rules); err != nil {
+		return fmt.Errorf("want a JSON list of rules: %v", err)
+	}
+	for i, rule := range rules {
+		if err := validateQueryRule(rule); err != nil {
+			return fmt.Errorf("rule %v (%v): %v", i, rule["Name"], err)
+		}
+	}
+	return nil
+}
+
+func validateQueryRule(rule map[string]interface{}) error {
+	for k, v := range rule {
+		switch k {
+		case "Name", "Description", "Action", "Hint":
+			if _, ok := v.(string); !ok {
+				return fmt.Errorf("want string for %v", k)
+			}
+		case "RequestIP", "User", "Query":
+			sv, ok := v.(string)
+			if !ok {
+				return fmt.Errorf("want string for %v", k)
+			}
+			if _, err := regexp.Compile("^" + sv + "$"); err != nil {
+				return fmt.Errorf("invalid regexp for %v: %v", k, err)
+			}
+		case "Delay":
+			sv, ok := v.(string)
+			if !ok {
+				return fmt.Errorf("want string for %v", k)
+			}
+			if delay, err := time.ParseDuration(sv); err != nil || delay <= 0 {
+				return fmt.Errorf("want a positive duration for Delay: %v", sv)
+			}
+		case "MaxRows":
+			nv, ok := v.(json.Number)
+			if !ok {
+				return fmt.Errorf("want number for MaxRows")
+			}
+			if maxRows, err := nv.Int64(); err != nil || maxRows <= 0 {
+				return fmt.Errorf("want a positive integer for MaxRows: %v", nv)
+			}
+		case "Plans", "TableNames":
+			lv, ok := v.([]interface{})
+			if !ok {
+				return fmt.Errorf("want list for %v", k)
+			}
+			for _, elem := range lv {
+				sv, ok := elem.(string)
+				if !ok {
+					return fmt.Errorf("want string for %v", k)
+				}
+				if k == "Plans" {
+					if _, ok := planbuilder.PlanByName(sv); !ok {
+						return fmt.Errorf("invalid plan name: %v", sv)
+					}
+				}
+			}
+		case "BindVarConds":
+			lv, ok := v.([]interface{})
+			if !ok {
+				return fmt.Errorf("want list for %v", k)
+			}
+			for _, elem := range lv {
+				bvc, ok := elem.(map[string]interface{})
+				if !ok {
+					return fmt.Errorf("want json object for bind var conditions")
+				}
+				if _, ok := bvc["Name"].(string); !ok {
+					return fmt.Errorf("want string for the Name of bind var conditions")
+				}
+			}
+		default:
+			return fmt.Errorf("unrecognized tag %v", k)
+		}
+	}
+
+	action := "FAIL"
+	if v, ok := rule["Action"]; ok {
+		action = v.(string)
+	}
+	if !queryRuleActions[action] {
+		return fmt.Errorf("invalid Action %v", action)
+	}
+	_, hasDelay := rule["Delay"]
+	if (action == "DELAY") != hasDelay {
+		return fmt.Errorf("Delay is required by, and only valid for, the DELAY action")
+	}
+	hint, hasHint := rule["Hint"]
+	if (action == "ADD_HINT") != (hasHint && hint != "") {
+		return fmt.Errorf("Hint is required by, and only valid for, the ADD_HINT action")
+	}
+	if hasHint && strings.Contains(hint.(string), "*/") {
+		return fmt.Errorf("Hint cannot contain '*/': %v", hint)
+	}
+	_, hasMaxRows := rule["MaxRows"]
+	if (action == "CAP_RESULT") != hasMaxRows {
+		return fmt.Errorf("MaxRows is required by, and only valid for, the CAP_RESULT action")
+	}
+	return nil
+}
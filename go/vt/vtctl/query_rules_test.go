@@ -0,0 +1,43 @@
+// Copyright 2016, Google Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vtctl
+
+import (
+	"testing"
+
+	"github.com/youtube/vitess/go/vt/tabletserver"
+)
+
+// TestValidateQueryRules checks validateQueryRules agrees with the
+// tabletserver parser.
+func TestValidateQueryRules(t *testing.T) {
+	testcases := []string{
+		`[]`,
+		`[{"Name": "r1", "Query": "select.*", "Plans": ["PASS_SELECT"], "TableNames": ["a"]}]`,
+		`[{"Name": "r1", "BindVarConds": [{"Name": "id", "OnAbsent": true, "Operator": ""}]}]`,
+		`[{"Name": "r1", "Action": "DELAY", "Delay": "1s"}]`,
+		`[{"Name": "r1", "Action": "ADD_HINT", "Hint": "MAX_EXECUTION_TIME(10)"}]`,
+		`[{"Name": "r1", "Action": "CAP_RESULT", "MaxRows": 10}]`,
+		`[{"Name": "r1", "Action": "STREAM"}]`,
+		`{"Name": "r1"}`,
+		`[{"Name": "r1", "Unknown": "a"}]`,
+		`[{"Name": "r1", "Query": "("}]`,
+		`[{"Name": "r1", "Plans": ["NOT_A_PLAN"]}]`,
+		`[{"Name": "r1", "Action": "NOT_AN_ACTION"}]`,
+		`[{"Name": "r1", "Action": "DELAY"}]`,
+		`[{"Name": "r1", "Delay": "1s"}]`,
+		`[{"Name": "r1", "Action": "DELAY", "Delay": "-1s"}]`,
+		`[{"Name": "r1", "Action": "ADD_HINT", "Hint": "a */ b"}]`,
+		`[{"Name": "r1", "Action": "CAP_RESULT", "MaxRows": 0}]`,
+		`[{"Name": "r1", "BindVarConds": ["id"]}]`,
+	}
+	for _, tc := range testcases {
+		err := validateQueryRules([]byte(tc))
+		want := tabletserver.NewQueryRules().UnmarshalJSON([]byte(tc))
+		if (err == nil) != (want == nil) {
+			t.Errorf("validateQueryRules(%v): %v, tabletserver returns %v", tc, err, want)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+// Copyright 2016, Google Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vtctl
+
+import (
+	"flag"
+	"fmt"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/youtube/vitess/go/vt/schemamanager"
+	"github.com/youtube/vitess/go/vt/topo"
+	"github.com/youtube/vitess/go/vt/wrangler"
+)
+
+// This file contains the commands to observe and control the online
+// schema migrations queued by ApplySchema -online.
+
+func init() {
+	addCommand("Schema, Version, Permissions", command{
+		"GetSchemaMigrations",
+		commandGetSchemaMigrations,
+		"<keyspace>",
+		"Lists the online schema migrations of a keyspace, oldest first, with their state and the progress of the running ones."})
+	addCommand("Schema, Version, Permissions", command{
+		"GetSchemaMigration",
+		commandGetSchemaMigration,
+		"<keyspace> <migration id>",
+		"Displays an online schema migration, and its progress on each shard."})
+	addCommand("Schema, Version, Permissions", command{
+		"CancelSchemaMigration",
+		commandCancelSchemaMigration,
+		"<keyspace> <migration id>",
+		"Cancels a queued or running online schema migration. The shards that were already cut over keep the new schema."})
+	addCommand("Schema, Version, Permissions", command{
+		"RetrySchemaMigration",
+		commandRetrySchemaMigration,
+		"<keyspace> <migration id>",
+		"Runs a failed or cancelled online schema migration again, on the shards where it didn't complete."})
+	addCommand("Schema, Version, Permissions", command{
+		"DeleteSchemaMigration",
+		commandDeleteSchemaMigration,
+		"<keyspace> <migration id>",
+		"Deletes a finished online schema migration from the topology."})
+}
+
+func commandGetSchemaMigrations(ctx context.Context, wr *wrangler.Wrangler, subFlags *flag.FlagSet, args []string) error {
+	if err := subFlags.Parse(args); err != nil {
+		return err
+	}
+	if subFlags.NArg() != 1 {
+		return fmt.Errorf("action GetSchemaMigrations requires <keyspace>")
+	}
+	keyspace := subFlags.Arg(0)
+	ids, err := wr.TopoServer().GetSchemaMigrationIDs(ctx, keyspace)
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		smi, err := wr.TopoServer().GetSchemaMigration(ctx, keyspace, id)
+		if err != nil {
+			if err == topo.ErrNoNode {
+				continue
+			}
+			return err
+		}
+		wr.Logger().Printf("%v %v %v %v\n", id, smi.State, schemaMigrationProgress(smi.SchemaMigration), smi.SQL)
+	}
+	return nil
+}
+
+// schemaMigrationProgress summarizes the progress of a migration, e.g.
+// "shards=3/4 rows=1200/1500 created=2016-10-19T10:00:00Z".
+func schemaMigrationProgress(sm *topo.SchemaMigration) string {
+	done := 0
+	var rowsCopied, rowsTotal int64
+	for _, ssm := range sm.Shards {
+		if ssm.Done() {
+			done++
+		}
+		rowsCopied += ssm.RowsCopied
+		rowsTotal += ssm.RowsTotal
+	}
+	result := fmt.Sprintf("shards=%v/%v rows=%v/%v created=%v", done, len(sm.Shards), rowsCopied, rowsTotal, time.Unix(sm.CreateTime, 0).UTC().Format(time.RFC3339))
+	if sm.CancelRequested && sm.State == topo.SchemaMigrationRunning {
+		result += " cancelling"
+	}
+	return result
+}
+
+func commandGetSchemaMigration(ctx context.Context, wr *wrangler.Wrangler, subFlags *flag.FlagSet, args []string) error {
+	if err := subFlags.Parse(args); err != nil {
+		return err
+	}
+	if subFlags.NArg() != 2 {
+		return fmt.Errorf("action GetSchemaMigration requires <keyspace> <migration id>")
+	}
+	smi, err := wr.TopoServer().GetSchemaMigration(ctx, subFlags.Arg(0), subFlags.Arg(1))
+	if err != nil {
+		return err
+	}
+	return printJSON(wr.Logger(), smi.SchemaMigration)
+}
+
+func commandCancelSchemaMigration(ctx context.Context, wr *wrangler.Wrangler, subFlags *flag.FlagSet, args []string) error {
+	if err := subFlags.Parse(args); err != nil {
+		return err
+	}
+	if subFlags.NArg() != 2 {
+		return fmt.Errorf("action CancelSchemaMigration requires <keyspace> <migration id>")
+	}
+	return schemamanager.CancelSchemaMigration(ctx, wr.TopoServer(), subFlags.Arg(0), subFlags.Arg(1))
+}
+
+func commandRetrySchemaMigration(ctx context.Context, wr *wrangler.Wrangler, subFlags *flag.FlagSet, args []string) error {
+	if err := subFlags.Parse(args); err != nil {
+		return err
+	}
+	if subFlags.NArg() != 2 {
+		return fmt.Errorf("action RetrySchemaMigration requires <keyspace> <migration id>")
+	}
+	return schemamanager.RetrySchemaMigration(ctx, wr.TopoServer(), subFlags.Arg(0), subFlags.Arg(1))
+}
+
+func commandDeleteSchemaMigration(ctx context.Context, wr *wrangler.Wrangler, subFlags *flag.FlagSet, args []string) error {
+	if err := subFlags.Parse(args); err != nil {
+		return err
+	}
+	if subFlags.NArg() != 2 {
+		return fmt.Errorf("action DeleteSchemaMigration requires <keyspace> <migration id>")
+	}
+	keyspace, id := subFlags.Arg(0), subFlags.Arg(1)
+	smi, err := wr.TopoServer().GetSchemaMigration(ctx, keyspace, id)
+	if err != nil {
+		return err
+	}
+	switch smi.State {
+	case topo.SchemaMigrationComplete, topo.SchemaMigrationFailed, topo.SchemaMigrationCancelled:
+	default:
+		return fmt.Errorf("schema migration %v is %v, cancel it first", id, smi.State)
+	}
+	return wr.TopoServer().DeleteSchemaMigration(ctx, keyspace, id)
+}
@@ -278,11 +278,17 @@ var commands = []commandGroup{
 				"[-ping-tablets] <keyspace name>",
 				"Validates that all nodes reachable from the specified keyspace are consistent."},
 			{"MigrateServedTypes", commandMigrateServedTypes,
-				"[-cells=c1,c2,...] [-reverse] [-skip-refresh-state] <keyspace/shard> <served tablet type>",
-				"Migrates a serving type from the source shard to the shards that it replicates to. This command also rebuilds the serving graph. The <keyspace/shard> argument can specify any of the shards involved in the migration."},
+				"[-cells=c1,c2,...] [-reverse] [-skip-refresh-state] [-reverse_replication] <keyspace/shard> <served tablet type>",
+				"Migrates a serving type from the source shard to the shards that it replicates to. This command also rebuilds the serving graph. The <keyspace/shard> argument can specify any of the shards involved in the migration. With -reverse_replication, a master migration makes the source shards replicate from the destination shards: to roll it back, migrate the served types again, without -reverse, in the usual order (rdonly, replica, then master)."},
 			{"MigrateServedFrom", commandMigrateServedFrom,
 				"[-cells=c1,c2,...] [-reverse] <destination keyspace/shard> <served tablet type>",
 				"Makes the <destination keyspace/shard> serve the given type. This command also rebuilds the serving graph."},
+			{"MoveTables", commandMoveTables,
+				"<source keyspace> <destination keyspace> <table1>,<table2>,...",
+				"Starts moving the tables into an existing keyspace: the queries for the tables keep going to the source keyspace, through routing rules, while they are copied with 'vtworker VerticalSplitClone --source_keyspace'. The keyspaces must have the same shards."},
+			{"SwitchTables", commandSwitchTables,
+				"[-filtered_replication_wait_time=30s] <destination keyspace> <table1>,<table2>,...",
+				"Switches the tables moved with MoveTables to the destination keyspace: blacklists them on the source shards, waits for filtered replication to catch up, and updates the routing rules. This command also rebuilds the SrvVSchema."},
 			{"FindAllShardsInKeyspace", commandFindAllShardsInKeyspace,
 				"<keyspace>",
 				"Displays all of the shards in the specified keyspace."},
@@ -324,8 +330,8 @@ var commands = []commandGroup{
 				"[-exclude_tables=''] [-include-views] <keyspace name>",
 				"Validates that the master schema from shard 0 matches the schema on all of the other tablets in the keyspace."},
 			{"ApplySchema", commandApplySchema,
-				"[-allow_long_unavailability] [-wait_slave_timeout=10s] {-sql=<sql> || -sql-file=<filename>} <keyspace>",
-				"Applies the schema change to the specified keyspace on every master, running in parallel on all shards. The changes are then propagated to slaves via replication. If -allow_long_unavailability is set, schema changes affecting a large number of rows (and possibly incurring a longer period of unavailability) will not be rejected."},
+				"[-allow_long_unavailability] [-online] [-wait_slave_timeout=10s] {-sql=<sql> || -sql-file=<filename>} <keyspace>",
+				"Applies the schema change to the specified keyspace on every master, running in parallel on all shards. The changes are then propagated to slaves via replication. If -allow_long_unavailability is set, schema changes affecting a large number of rows (and possibly incurring a longer period of unavailability) will not be rejected. If -online is set, the ALTER TABLE statements are queued as online schema migrations instead, see GetSchemaMigrations."},
 			{"CopySchemaShard", commandCopySchemaShard,
 				"[-tables=<table1>,<table2>,...] [-exclude_tables=<table1>,<table2>,...] [-include-views] [-wait_slave_timeout=10s] {<source keyspace/shard> || <source tablet alias>} <destination keyspace/shard>",
 				"Copies the schema from a source shard's master (or a specific tablet) to a destination shard. The schema is applied directly on the master of the destination shard, and it is propagated to the replicas through binlogs."},
@@ -356,6 +362,12 @@ var commands = []commandGroup{
 			{"RebuildVSchemaGraph", commandRebuildVSchemaGraph,
 				"[-cells=c1,c2,...]",
 				"Rebuilds the cell-specific SrvVSchema from the global VSchema objects in the provided cells (or all cells if none provided)."},
+			{"GetRoutingRules", commandGetRoutingRules,
+				"",
+				"Displays the VTGate routing rules, used to move tables between keyspaces."},
+			{"ApplyRoutingRules", commandApplyRoutingRules,
+				"{-rules=<rules> || -rules_file=<rules file>} [-cells=c1,c2,...] [-skip_rebuild]",
+				"Replaces the VTGate routing rules. This command is intended for emergency fixes, the rules are normally maintained by MoveTables and SwitchTables."},
 		},
 	},
 	{
@@ -1656,6 +1668,7 @@ func commandMigrateServedTypes(ctx context.Context, wr *wrangler.Wrangler, subFl
 	cellsStr := subFlags.String("cells", "", "Specifies a comma-separated list of cells to update")
 	reverse := subFlags.Bool("reverse", false, "Moves the served tablet type backward instead of forward. Use in case of trouble")
 	skipReFreshState := subFlags.Bool("skip-refresh-state", false, "Skips refreshing the state of the source tablets after the migration, meaning that the refresh will need to be done manually, replica and rdonly only)")
+	reverseReplication := subFlags.Bool("reverse_replication", false, "For master migrations, makes the source shards replicate from the destination shards, so the master can be migrated back without losing writes")
 	filteredReplicationWaitTime := subFlags.Duration("filtered_replication_wait_time", 30*time.Second, "Specifies the maximum time to wait, in seconds, for filtered replication to catch up on master migrations")
 	if err := subFlags.Parse(args); err != nil {
 		return err
@@ -1679,7 +1692,7 @@ func commandMigrateServedTypes(ctx context.Context, wr *wrangler.Wrangler, subFl
 	if *cellsStr != "" {
 		cells = strings.Split(*cellsStr, ",")
 	}
-	return wr.MigrateServedTypes(ctx, keyspace, shard, cells, servedType, *reverse, *skipReFreshState, *filteredReplicationWaitTime)
+	return wr.MigrateServedTypes(ctx, keyspace, shard, cells, servedType, *reverse, *skipReFreshState, *reverseReplication, *filteredReplicationWaitTime)
 }
 
 func commandMigrateServedFrom(ctx context.Context, wr *wrangler.Wrangler, subFlags *flag.FlagSet, args []string) error {
@@ -1708,6 +1721,29 @@ func commandMigrateServedFrom(ctx context.Context, wr *wrangler.Wrangler, subFla
 	return wr.MigrateServedFrom(ctx, keyspace, shard, servedType, cells, *reverse, *filteredReplicationWaitTime)
 }
 
+func commandMoveTables(ctx context.Context, wr *wrangler.Wrangler, subFlags *flag.FlagSet, args []string) error {
+	if err := subFlags.Parse(args); err != nil {
+		return err
+	}
+	if subFlags.NArg() != 3 {
+		return fmt.Errorf("The <source keyspace>, <destination keyspace> and <table list> arguments are required for the MoveTables command.")
+	}
+	tables := strings.Split(subFlags.Arg(2), ",")
+	return wr.MoveTables(ctx, subFlags.Arg(0), subFlags.Arg(1), tables)
+}
+
+func commandSwitchTables(ctx context.Context, wr *wrangler.Wrangler, subFlags *flag.FlagSet, args []string) error {
+	filteredReplicationWaitTime := subFlags.Duration("filtered_replication_wait_time", 30*time.Second, "Specifies the maximum time to wait, in seconds, for filtered replication to catch up")
+	if err := subFlags.Parse(args); err != nil {
+		return err
+	}
+	if subFlags.NArg() != 2 {
+		return fmt.Errorf("The <destination keyspace> and <table list> arguments are required for the SwitchTables command.")
+	}
+	tables := strings.Split(subFlags.Arg(1), ",")
+	return wr.SwitchTables(ctx, subFlags.Arg(0), tables, *filteredReplicationWaitTime)
+}
+
 func commandFindAllShardsInKeyspace(ctx context.Context, wr *wrangler.Wrangler, subFlags *flag.FlagSet, args []string) error {
 	if err := subFlags.Parse(args); err != nil {
 		return err
@@ -1860,6 +1896,7 @@ func commandValidateSchemaKeyspace(ctx context.Context, wr *wrangler.Wrangler, s
 
 func commandApplySchema(ctx context.Context, wr *wrangler.Wrangler, subFlags *flag.FlagSet, args []string) error {
 	allowLongUnavailability := subFlags.Bool("allow_long_unavailability", false, "Allow large schema changes which incur a longer unavailability of the database.")
+	online := subFlags.Bool("online", false, "Queue the ALTER TABLE statements as online schema migrations, run by the masters without blocking the tables.")
 	sql := subFlags.String("sql", "", "A list of semicolon-delimited SQL commands")
 	sqlFile := subFlags.String("sql-file", "", "Identifies the file that contains the SQL commands")
 	waitSlaveTimeout := subFlags.Duration("wait_slave_timeout", 10*time.Second, "The amount of time to wait for slaves to receive the schema change via replication.")
@@ -1876,6 +1913,13 @@ func commandApplySchema(ctx context.Context, wr *wrangler.Wrangler, subFlags *fl
 		return err
 	}
 
+	if *online {
+		return schemamanager.Run(
+			ctx,
+			schemamanager.NewPlainController(change, keyspace),
+			schemamanager.NewOnlineExecutor(wr),
+		)
+	}
 	executor := schemamanager.NewTabletExecutor(wr, *waitSlaveTimeout)
 	if *allowLongUnavailability {
 		executor.AllowBigSchemaChange()
@@ -2080,6 +2124,74 @@ func commandApplyVSchema(ctx context.Context, wr *wrangler.Wrangler, subFlags *f
 	return topotools.RebuildVSchema(ctx, wr.Logger(), wr.TopoServer(), cells)
 }
 
+func commandGetRoutingRules(ctx context.Context, wr *wrangler.Wrangler, subFlags *flag.FlagSet, args []string) error {
+	if err := subFlags.Parse(args); err != nil {
+		return err
+	}
+	if subFlags.NArg() != 0 {
+		return fmt.Errorf("GetRoutingRules doesn't take any arguments.")
+	}
+	rr, err := wr.TopoServer().GetRoutingRules(ctx)
+	if err != nil {
+		return err
+	}
+	b, err := json.MarshalIndent(rr, "", "  ")
+	if err != nil {
+		wr.Logger().Printf("%v\n", err)
+		return err
+	}
+	wr.Logger().Printf("%s\n", b)
+	return nil
+}
+
+func commandApplyRoutingRules(ctx context.Context, wr *wrangler.Wrangler, subFlags *flag.FlagSet, args []string) error {
+	rules := subFlags.String("rules", "", "Specifies the routing rules, as JSON")
+	rulesFile := subFlags.String("rules_file", "", "Specifies a file containing the routing rules, as JSON")
+	skipRebuild := subFlags.Bool("skip_rebuild", false, "If set, do no rebuild the SrvSchema objects.")
+	var cells flagutil.StringListValue
+	subFlags.Var(&cells, "cells", "If specified, limits the rebuild to the cells, after upload. Ignored if skipRebuild is set.")
+
+	if err := subFlags.Parse(args); err != nil {
+		return err
+	}
+	if subFlags.NArg() != 0 {
+		return fmt.Errorf("ApplyRoutingRules doesn't take any arguments.")
+	}
+	if (*rules == "") == (*rulesFile == "") {
+		return fmt.Errorf("Either the rules or rules_file flag must be specified when calling the ApplyRoutingRules command.")
+	}
+	var data []byte
+	if *rulesFile != "" {
+		var err error
+		data, err = ioutil.ReadFile(*rulesFile)
+		if err != nil {
+			return err
+		}
+	} else {
+		data = []byte(*rules)
+	}
+	var rr vschemapb.RoutingRules
+	if err := json.Unmarshal(data, &rr); err != nil {
+		return err
+	}
+	if err := wr.TopoServer().SaveRoutingRules(ctx, &rr); err != nil {
+		return err
+	}
+
+	b, err := json.MarshalIndent(&rr, "", "  ")
+	if err != nil {
+		wr.Logger().Errorf("Failed to marshal RoutingRules for display: %v", err)
+	} else {
+		wr.Logger().Printf("Uploaded RoutingRules object:\n%s\nIf this is not what you expected, check the input data (as JSON parsing will skip unexpected fields).\n", b)
+	}
+
+	if *skipRebuild {
+		wr.Logger().Warningf("Skipping rebuild of SrvVSchema, will need to run RebuildVSchemaGraph for changes to take effect")
+		return nil
+	}
+	return topotools.RebuildVSchema(ctx, wr.Logger(), wr.TopoServer(), cells)
+}
+
 func commandGetSrvKeyspaceNames(ctx context.Context, wr *wrangler.Wrangler, subFlags *flag.FlagSet, args []string) error {
 	if err := subFlags.Parse(args); err != nil {
 		return err
@@ -0,0 +1,170 @@
+// Copyright 2016, Google Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vtctl
+
+import (
+	"flag"
+	"fmt"
+
+	"golang.org/x/net/context"
+
+	"github.com/youtube/vitess/go/vt/workflow"
+	"github.com/youtube/vitess/go/vt/workflow/resharding"
+	"github.com/youtube/vitess/go/vt/wrangler"
+)
+
+// This file contains the commands to manage the workflows stored in
+// the topology. They only change the workflow records: the workflows
+// run in the vtctld that is the leader of the workflow manager
+// election, which picks the changes up.
+
+const workflowGroupName = "Workflows"
+
+func init() {
+	addCommandGroup(workflowGroupName)
+
+	addCommand(workflowGroupName, command{
+		"WorkflowCreate",
+		commandWorkflowCreate,
+		"[-skip_start] <factory name> [parameters...]",
+		"Creates the workflow with the provided parameters, and starts it unless -skip_start is set. The parameters depend on the workflow factory. Outputs the uuid of the workflow."})
+	addCommand(workflowGroupName, command{
+		"WorkflowStart",
+		commandWorkflowStart,
+		"<uuid>",
+		"Starts a workflow that was created with -skip_start, or stopped."})
+	addCommand(workflowGroupName, command{
+		"WorkflowStop",
+		commandWorkflowStop,
+		"<uuid>",
+		"Stops a running workflow. It keeps its progress, and can be started again."})
+	addCommand(workflowGroupName, command{
+		"WorkflowDelete",
+		commandWorkflowDelete,
+		"<uuid>",
+		"Deletes a workflow that is not running."})
+	addCommand(workflowGroupName, command{
+		"WorkflowList",
+		commandWorkflowList,
+		"",
+		"Lists the workflows, with their factory name, state and name."})
+	addCommand(workflowGroupName, command{
+		"WorkflowWait",
+		commandWorkflowWait,
+		"<uuid>",
+		"Waits for a workflow to be done, and returns its error."})
+	addCommand(workflowGroupName, command{
+		"HorizontalReshardingApprove",
+		commandHorizontalReshardingApprove,
+		"<uuid> <step>",
+		"Approves a step of a horizontal_resharding workflow. The workflow waits for an approval before the steps of its -pause_before list, and before running again a step that failed."})
+	addCommand(workflowGroupName, command{
+		"HorizontalReshardingRollback",
+		commandHorizontalReshardingRollback,
+		"<uuid>",
+		"Asks a horizontal_resharding workflow to migrate the served types back to the source shards. The workflow then waits for new approvals to migrate them again. Once the master is migrated, it needs the reverse replication, which the workflow sets up unless it was created with -reverse_replication=false."})
+}
+
+func commandWorkflowCreate(ctx context.Context, wr *wrangler.Wrangler, subFlags *flag.FlagSet, args []string) error {
+	skipStart := subFlags.Bool("skip_start", false, "If set, the workflow will not be started.")
+	if err := subFlags.Parse(args); err != nil {
+		return err
+	}
+	if subFlags.NArg() < 1 {
+		return fmt.Errorf("the <factory name> argument is required for the WorkflowCreate command")
+	}
+	m := workflow.NewManager(wr.TopoServer())
+	uuid, err := m.Create(ctx, subFlags.Arg(0), subFlags.Args()[1:])
+	if err != nil {
+		return err
+	}
+	wr.Logger().Printf("uuid: %v\n", uuid)
+	if *skipStart {
+		return nil
+	}
+	return m.Start(ctx, uuid)
+}
+
+// parseWorkflowUUID parses the <uuid> argument of a command.
+func parseWorkflowUUID(name string, subFlags *flag.FlagSet, args []string) (string, error) {
+	if err := subFlags.Parse(args); err != nil {
+		return "", err
+	}
+	if subFlags.NArg() != 1 {
+		return "", fmt.Errorf("the <uuid> argument is required for the %v command", name)
+	}
+	return subFlags.Arg(0), nil
+}
+
+func commandWorkflowStart(ctx context.Context, wr *wrangler.Wrangler, subFlags *flag.FlagSet, args []string) error {
+	uuid, err := parseWorkflowUUID("WorkflowStart", subFlags, args)
+	if err != nil {
+		return err
+	}
+	return workflow.NewManager(wr.TopoServer()).Start(ctx, uuid)
+}
+
+func commandWorkflowStop(ctx context.Context, wr *wrangler.Wrangler, subFlags *flag.FlagSet, args []string) error {
+	uuid, err := parseWorkflowUUID("WorkflowStop", subFlags, args)
+	if err != nil {
+		return err
+	}
+	return workflow.NewManager(wr.TopoServer()).Stop(ctx, uuid)
+}
+
+func commandWorkflowDelete(ctx context.Context, wr *wrangler.Wrangler, subFlags *flag.FlagSet, args []string) error {
+	uuid, err := parseWorkflowUUID("WorkflowDelete", subFlags, args)
+	if err != nil {
+		return err
+	}
+	return workflow.NewManager(wr.TopoServer()).Delete(ctx, uuid)
+}
+
+func commandWorkflowWait(ctx context.Context, wr *wrangler.Wrangler, subFlags *flag.FlagSet, args []string) error {
+	uuid, err := parseWorkflowUUID("WorkflowWait", subFlags, args)
+	if err != nil {
+		return err
+	}
+	return workflow.NewManager(wr.TopoServer()).Wait(ctx, uuid)
+}
+
+func commandWorkflowList(ctx context.Context, wr *wrangler.Wrangler, subFlags *flag.FlagSet, args []string) error {
+	if err := subFlags.Parse(args); err != nil {
+		return err
+	}
+	if subFlags.NArg() != 0 {
+		return fmt.Errorf("the WorkflowList command takes no parameter")
+	}
+	workflows, err := workflow.NewManager(wr.TopoServer()).List(ctx)
+	if err != nil {
+		return err
+	}
+	for _, w := range workflows {
+		line := fmt.Sprintf("%v %v %v %v", w.Uuid, w.FactoryName, w.State, w.Name)
+		if w.Error != "" {
+			line += " (error: " + w.Error + ")"
+		}
+		wr.Logger().Printf("%v\n", line)
+	}
+	return nil
+}
+
+func commandHorizontalReshardingApprove(ctx context.Context, wr *wrangler.Wrangler, subFlags *flag.FlagSet, args []string) error {
+	if err := subFlags.Parse(args); err != nil {
+		return err
+	}
+	if subFlags.NArg() != 2 {
+		return fmt.Errorf("the <uuid> and <step> arguments are required for the HorizontalReshardingApprove command")
+	}
+	return resharding.Approve(ctx, wr.TopoServer(), subFlags.Arg(0), subFlags.Arg(1))
+}
+
+func commandHorizontalReshardingRollback(ctx context.Context, wr *wrangler.Wrangler, subFlags *flag.FlagSet, args []string) error {
+	uuid, err := parseWorkflowUUID("HorizontalReshardingRollback", subFlags, args)
+	if err != nil {
+		return err
+	}
+	return resharding.Rollback(ctx, wr.TopoServer(), uuid)
+}
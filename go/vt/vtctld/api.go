@@ -220,6 +220,26 @@ func initAPI(ctx context.Context, ts topo.Server, actions *ActionRepository, rea
 		return si.Shard, err
 	})
 
+	// Backup schedules, with the history of the recent runs.
+	handleCollection("backup_schedules", func(r *http.Request) (interface{}, error) {
+		shardPath := getItemPath(r.URL.Path)
+
+		// List the shards that have a schedule.
+		if shardPath == "" {
+			shards, err := ts.GetBackupScheduleShards(ctx)
+			if shards == nil {
+				shards = make(map[string][]string)
+			}
+			return shards, err
+		}
+
+		keyspace, shard, err := topoproto.ParseKeyspaceShard(shardPath)
+		if err != nil {
+			return nil, err
+		}
+		return getBackupScheduleStatus(ctx, ts, keyspace, shard)
+	})
+
 	// SrvKeyspace
 	handleCollection("srv_keyspace", func(r *http.Request) (interface{}, error) {
 		keyspacePath := getItemPath(r.URL.Path)
@@ -11,6 +11,7 @@ import (
 
 	"golang.org/x/net/context"
 
+	"github.com/youtube/vitess/go/vt/topo"
 	"github.com/youtube/vitess/go/vt/wrangler"
 	"github.com/youtube/vitess/go/vt/zktopo/zktestserver"
 
@@ -51,6 +52,18 @@ func TestAPI(t *testing.T) {
 		PortMap:  map[string]int32{"vt": 100},
 	}
 	ts.CreateTablet(ctx, &tablet1)
+	ts.CreateBackupSchedule(ctx, "ks1", "-80", &topo.BackupSchedule{
+		Spec:              "@daily",
+		TabletTypes:       []string{"rdonly"},
+		Concurrency:       4,
+		LastScheduledTime: 1472083200,
+		History: []*topo.BackupRun{{
+			Tablet:    "cell1-0000000100",
+			Owner:     "vtctld",
+			StartTime: 1472083200,
+			EndTime:   1472083260,
+		}},
+	})
 
 	tablet2 := topodatapb.Tablet{
 		Alias:    &topodatapb.TabletAlias{Cell: "cell2", Uid: 200},
@@ -0,0 +1,347 @@
+// Copyright 2016, Google Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vtctld
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/golang/glog"
+	"golang.org/x/net/context"
+
+	"github.com/youtube/vitess/go/cron"
+	"github.com/youtube/vitess/go/vt/logutil"
+	"github.com/youtube/vitess/go/vt/tabletmanager/tmclient"
+	"github.com/youtube/vitess/go/vt/topo"
+	"github.com/youtube/vitess/go/vt/topo/topoproto"
+
+	querypb "github.com/youtube/vitess/go/vt/proto/query"
+	topodatapb "github.com/youtube/vitess/go/vt/proto/topodata"
+)
+
+// This file contains the backup scheduler: it periodically reads the
+// backup schedules stored in the topology, and takes the backups that
+// are due on a suitable tablet of each shard.
+
+var (
+	backupSchedulerInterval   = flag.Duration("backup_scheduler_interval", 0, "if set, vtctld checks the backup schedules stored in the topology at this interval, and takes the backups that are due. 0 disables the backup scheduler.")
+	backupSchedulerRunTimeout = flag.Duration("backup_scheduler_run_timeout", 24*time.Hour, "a scheduled backup that has been running for longer than this is considered failed (for instance because the vtctld running it died)")
+	backupSchedulerRPCTimeout = flag.Duration("backup_scheduler_rpc_timeout", 30*time.Second, "timeout for the topology and health check calls of the backup scheduler")
+)
+
+// DefaultBackupTabletTypes are the tablet types used for scheduled
+// backups when the schedule doesn't specify any.
+var DefaultBackupTabletTypes = []string{"rdonly"}
+
+// backupScheduler runs the scheduled backups. Multiple vtctld
+// processes can run it: the Running field of the schedule, updated
+// with a version check, makes sure only one of them takes the backup.
+type backupScheduler struct {
+	ts    topo.Server
+	owner string
+
+	// health returns the last health stream response of a tablet.
+	health func(ctx context.Context, tabletAlias *topodatapb.TabletAlias) (*querypb.StreamHealthResponse, error)
+	// backup takes a backup on a tablet, and returns when it is done.
+	backup func(ctx context.Context, tablet *topodatapb.Tablet, concurrency int) error
+	// now returns the current time. Replaced in tests.
+	now func() time.Time
+
+	// wg tracks the running backups.
+	wg sync.WaitGroup
+}
+
+func newBackupScheduler(ts topo.Server) *backupScheduler {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+	thc := newTabletHealthCache(ts)
+	tmc := tmclient.NewTabletManagerClient()
+	return &backupScheduler{
+		ts:     ts,
+		owner:  fmt.Sprintf("%v:%v", hostname, os.Getpid()),
+		health: thc.Get,
+		backup: func(ctx context.Context, tablet *topodatapb.Tablet, concurrency int) error {
+			stream, err := tmc.Backup(ctx, tablet, concurrency)
+			if err != nil {
+				return err
+			}
+			logger := logutil.NewConsoleLogger()
+			for {
+				e, err := stream.Recv()
+				switch err {
+				case nil:
+					logutil.LogEvent(logger, e)
+				case io.EOF:
+					return nil
+				default:
+					return err
+				}
+			}
+		},
+		now: time.Now,
+	}
+}
+
+// run checks the schedules every interval, until ctx is canceled.
+func (bs *backupScheduler) run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		bs.checkAll(ctx)
+		select {
+		case <-ctx.Done():
+			bs.wg.Wait()
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// checkAll checks the schedules of all shards once, and starts the
+// backups that are due. It doesn't wait for them.
+func (bs *backupScheduler) checkAll(ctx context.Context) {
+	shortCtx, cancel := context.WithTimeout(ctx, *backupSchedulerRPCTimeout)
+	shards, err := bs.ts.GetBackupScheduleShards(shortCtx)
+	cancel()
+	if err != nil {
+		log.Warningf("backup scheduler: cannot list backup schedules: %v", err)
+		return
+	}
+	for keyspace, shardNames := range shards {
+		for _, shard := range shardNames {
+			if err := bs.checkShard(ctx, keyspace, shard); err != nil {
+				log.Warningf("backup scheduler: %v/%v: %v", keyspace, shard, err)
+			}
+		}
+	}
+}
+
+// checkShard starts the backup of a shard if it is due.
+func (bs *backupScheduler) checkShard(ctx context.Context, keyspace, shard string) error {
+	now := bs.now()
+	var run *topo.BackupRun
+	var stale *topo.BackupRun
+	shortCtx, cancel := context.WithTimeout(ctx, *backupSchedulerRPCTimeout)
+	bsi, err := bs.ts.UpdateBackupScheduleFields(shortCtx, keyspace, shard, func(s *topo.BackupSchedule) error {
+		run, stale = nil, nil
+		if s.Disabled {
+			return topo.ErrNoUpdateNeeded
+		}
+		schedule, err := cron.Parse(s.Spec)
+		if err != nil {
+			return err
+		}
+
+		if s.Running != nil {
+			if now.Sub(time.Unix(s.Running.StartTime, 0)) < *backupSchedulerRunTimeout {
+				// Still running, nothing to do.
+				return topo.ErrNoUpdateNeeded
+			}
+			// The backup has been running for too long. Give up
+			// on it, so the next one can start.
+			stale = s.Running
+			stale.EndTime = now.Unix()
+			stale.Error = fmt.Sprintf("backup didn't finish within %v", *backupSchedulerRunTimeout)
+			s.AddRun(stale)
+			s.Running = nil
+		}
+
+		if s.LastScheduledTime == 0 {
+			// First time we see this schedule: start counting
+			// from now, so we don't run a backup right away.
+			s.LastScheduledTime = now.Unix()
+			return nil
+		}
+		next := schedule.Next(time.Unix(s.LastScheduledTime, 0))
+		if next.IsZero() || next.After(now) {
+			if stale == nil {
+				return topo.ErrNoUpdateNeeded
+			}
+			return nil
+		}
+
+		// The backup is due, claim it.
+		run = &topo.BackupRun{
+			Owner:     bs.owner,
+			StartTime: now.Unix(),
+		}
+		// Record the time the backup was scheduled for, not now,
+		// so the schedule doesn't drift by the polling delay.
+		// The runs missed in between are skipped.
+		for {
+			following := schedule.Next(next)
+			if following.IsZero() || following.After(now) {
+				break
+			}
+			next = following
+		}
+		s.LastScheduledTime = next.Unix()
+		s.Running = run
+		return nil
+	})
+	cancel()
+	if err != nil {
+		if err == topo.ErrNoNode {
+			// Deleted in the meantime.
+			return nil
+		}
+		return err
+	}
+	if stale != nil {
+		log.Warningf("backup scheduler: %v/%v: backup started at %v by %v timed out", keyspace, shard, time.Unix(stale.StartTime, 0), stale.Owner)
+	}
+	if run == nil || bsi == nil {
+		return nil
+	}
+
+	// Copy what we need, the backup runs in the background.
+	tabletTypes := bsi.TabletTypes
+	if len(tabletTypes) == 0 {
+		tabletTypes = DefaultBackupTabletTypes
+	}
+	maxLag := bsi.MaxReplicationLagSeconds
+	concurrency := bsi.Concurrency
+	if concurrency < 1 {
+		concurrency = 4
+	}
+	bs.wg.Add(1)
+	go func() {
+		defer bs.wg.Done()
+		bs.runBackup(ctx, keyspace, shard, run, tabletTypes, maxLag, concurrency)
+	}()
+	return nil
+}
+
+// runBackup picks a tablet, takes the backup, and records the result
+// in the schedule.
+func (bs *backupScheduler) runBackup(ctx context.Context, keyspace, shard string, run *topo.BackupRun, tabletTypes []string, maxLag int64, concurrency int) {
+	err := func() error {
+		tablet, err := bs.pickTablet(ctx, keyspace, shard, tabletTypes, maxLag)
+		if err != nil {
+			return err
+		}
+		run.Tablet = topoproto.TabletAliasString(tablet.Alias)
+		log.Infof("backup scheduler: %v/%v: starting backup on tablet %v", keyspace, shard, run.Tablet)
+		return bs.backup(ctx, tablet, concurrency)
+	}()
+	run.EndTime = bs.now().Unix()
+	if err != nil {
+		run.Error = err.Error()
+		log.Errorf("backup scheduler: %v/%v: backup failed: %v", keyspace, shard, err)
+	} else {
+		log.Infof("backup scheduler: %v/%v: backup on tablet %v done", keyspace, shard, run.Tablet)
+	}
+
+	// Use a new context, ctx may have been canceled.
+	shortCtx, cancel := context.WithTimeout(context.Background(), *backupSchedulerRPCTimeout)
+	defer cancel()
+	if _, err := bs.ts.UpdateBackupScheduleFields(shortCtx, keyspace, shard, func(s *topo.BackupSchedule) error {
+		if s.Running != nil && s.Running.Owner == run.Owner && s.Running.StartTime == run.StartTime {
+			s.Running = nil
+		}
+		s.AddRun(run)
+		return nil
+	}); err != nil {
+		log.Errorf("backup scheduler: %v/%v: cannot record backup result: %v", keyspace, shard, err)
+	}
+}
+
+// backupCandidate is a tablet that can take a backup.
+type backupCandidate struct {
+	tablet *topodatapb.Tablet
+	lag    uint32
+}
+
+type byLag []backupCandidate
+
+func (b byLag) Len() int           { return len(b) }
+func (b byLag) Swap(i, j int)      { b[i], b[j] = b[j], b[i] }
+func (b byLag) Less(i, j int) bool { return b[i].lag < b[j].lag }
+
+// pickTablet returns the healthy tablet with the lowest replication lag
+// of the first tablet type in tabletTypes that has one.
+func (bs *backupScheduler) pickTablet(ctx context.Context, keyspace, shard string, tabletTypes []string, maxLag int64) (*topodatapb.Tablet, error) {
+	shortCtx, cancel := context.WithTimeout(ctx, *backupSchedulerRPCTimeout)
+	defer cancel()
+	tabletMap, err := bs.ts.GetTabletMapForShard(shortCtx, keyspace, shard)
+	if err != nil && err != topo.ErrPartialResult {
+		return nil, fmt.Errorf("cannot read tablets: %v", err)
+	}
+
+	var rejected []string
+	for _, tt := range tabletTypes {
+		tabletType, err := topoproto.ParseTabletType(tt)
+		if err != nil {
+			return nil, err
+		}
+		var candidates []backupCandidate
+		for _, ti := range tabletMap {
+			if ti.Type != tabletType {
+				continue
+			}
+			alias := topoproto.TabletAliasString(ti.Alias)
+			health, err := bs.health(shortCtx, ti.Alias)
+			switch {
+			case err != nil:
+				rejected = append(rejected, fmt.Sprintf("%v: cannot get health: %v", alias, err))
+			case health.RealtimeStats == nil:
+				rejected = append(rejected, fmt.Sprintf("%v: no health stats", alias))
+			case health.RealtimeStats.HealthError != "":
+				rejected = append(rejected, fmt.Sprintf("%v: unhealthy: %v", alias, health.RealtimeStats.HealthError))
+			case maxLag > 0 && int64(health.RealtimeStats.SecondsBehindMaster) > maxLag:
+				rejected = append(rejected, fmt.Sprintf("%v: lagging by %vs", alias, health.RealtimeStats.SecondsBehindMaster))
+			default:
+				candidates = append(candidates, backupCandidate{
+					tablet: ti.Tablet,
+					lag:    health.RealtimeStats.SecondsBehindMaster,
+				})
+			}
+		}
+		if len(candidates) > 0 {
+			sort.Sort(byLag(candidates))
+			return candidates[0].tablet, nil
+		}
+	}
+	sort.Strings(rejected)
+	return nil, fmt.Errorf("no healthy tablet of type %v in shard %v/%v (rejected: %v)", strings.Join(tabletTypes, ","), keyspace, shard, strings.Join(rejected, ", "))
+}
+
+// backupScheduleStatus is what the API returns for a schedule.
+type backupScheduleStatus struct {
+	Keyspace string
+	Shard    string
+	*topo.BackupSchedule
+
+	// NextRunTime is when the schedule fires next, in seconds
+	// since the epoch. It is 0 if unknown.
+	NextRunTime int64
+}
+
+// getBackupScheduleStatus returns the status of the schedule of a shard.
+func getBackupScheduleStatus(ctx context.Context, ts topo.Server, keyspace, shard string) (*backupScheduleStatus, error) {
+	bsi, err := ts.GetBackupSchedule(ctx, keyspace, shard)
+	if err != nil {
+		return nil, err
+	}
+	status := &backupScheduleStatus{
+		Keyspace:       keyspace,
+		Shard:          shard,
+		BackupSchedule: bsi.BackupSchedule,
+	}
+	if schedule, err := cron.Parse(bsi.Spec); err == nil && !bsi.Disabled && bsi.LastScheduledTime != 0 {
+		if next := schedule.Next(time.Unix(bsi.LastScheduledTime, 0)); !next.IsZero() {
+			status.NextRunTime = next.Unix()
+		}
+	}
+	return status, nil
+}
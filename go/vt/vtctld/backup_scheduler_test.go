@@ -0,0 +1,215 @@
+// Copyright 2016, Google Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vtctld
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/youtube/vitess/go/vt/topo"
+	"github.com/youtube/vitess/go/vt/topo/topoproto"
+	"github.com/youtube/vitess/go/vt/zktopo/zktestserver"
+
+	querypb "github.com/youtube/vitess/go/vt/proto/query"
+	topodatapb "github.com/youtube/vitess/go/vt/proto/topodata"
+)
+
+// fakeBackupTablets records the backups the scheduler takes.
+type fakeBackupTablets struct {
+	mu      sync.Mutex
+	health  map[string]*querypb.RealtimeStats
+	backups []string
+	err     error
+}
+
+func (f *fakeBackupTablets) getHealth(ctx context.Context, tabletAlias *topodatapb.TabletAlias) (*querypb.StreamHealthResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	stats, ok := f.health[topoproto.TabletAliasString(tabletAlias)]
+	if !ok {
+		return nil, errors.New("no health stream")
+	}
+	return &querypb.StreamHealthResponse{RealtimeStats: stats}, nil
+}
+
+func (f *fakeBackupTablets) backup(ctx context.Context, tablet *topodatapb.Tablet, concurrency int) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.backups = append(f.backups, topoproto.TabletAliasString(tablet.Alias))
+	return f.err
+}
+
+func TestBackupScheduler(t *testing.T) {
+	ctx := context.Background()
+	cells := []string{"cell1"}
+	ts := zktestserver.New(t, cells)
+	ts.CreateKeyspace(ctx, "ks", &topodatapb.Keyspace{})
+	ts.Impl.CreateShard(ctx, "ks", "0", &topodatapb.Shard{
+		Cells: cells,
+	})
+	for _, tablet := range []*topodatapb.Tablet{{
+		Alias:    &topodatapb.TabletAlias{Cell: "cell1", Uid: 100},
+		Keyspace: "ks",
+		Shard:    "0",
+		Type:     topodatapb.TabletType_MASTER,
+	}, {
+		Alias:    &topodatapb.TabletAlias{Cell: "cell1", Uid: 101},
+		Keyspace: "ks",
+		Shard:    "0",
+		Type:     topodatapb.TabletType_RDONLY,
+	}, {
+		Alias:    &topodatapb.TabletAlias{Cell: "cell1", Uid: 102},
+		Keyspace: "ks",
+		Shard:    "0",
+		Type:     topodatapb.TabletType_RDONLY,
+	}, {
+		Alias:    &topodatapb.TabletAlias{Cell: "cell1", Uid: 103},
+		Keyspace: "ks",
+		Shard:    "0",
+		Type:     topodatapb.TabletType_RDONLY,
+	}} {
+		if err := ts.CreateTablet(ctx, tablet); err != nil {
+			t.Fatalf("CreateTablet failed: %v", err)
+		}
+	}
+
+	// cell1-0000000101 is lagging, cell1-0000000102 is unhealthy,
+	// cell1-0000000103 is the one we want.
+	tablets := &fakeBackupTablets{
+		health: map[string]*querypb.RealtimeStats{
+			"cell1-0000000100": {},
+			"cell1-0000000101": {SecondsBehindMaster: 3600},
+			"cell1-0000000102": {HealthError: "mysqld is down"},
+			"cell1-0000000103": {SecondsBehindMaster: 2},
+		},
+	}
+	now := time.Date(2016, 8, 24, 10, 15, 0, 0, time.UTC)
+	bs := &backupScheduler{
+		ts:     ts,
+		owner:  "test",
+		health: tablets.getHealth,
+		backup: tablets.backup,
+		now:    func() time.Time { return now },
+	}
+
+	if _, err := ts.CreateBackupSchedule(ctx, "ks", "0", &topo.BackupSchedule{
+		Spec:                     "@daily",
+		TabletTypes:              []string{"rdonly"},
+		MaxReplicationLagSeconds: 60,
+	}); err != nil {
+		t.Fatalf("CreateBackupSchedule failed: %v", err)
+	}
+
+	// The first check only records the start time.
+	bs.checkAll(ctx)
+	bs.wg.Wait()
+	if len(tablets.backups) != 0 {
+		t.Fatalf("backup taken too early: %v", tablets.backups)
+	}
+
+	// Not due yet.
+	now = time.Date(2016, 8, 24, 23, 59, 0, 0, time.UTC)
+	bs.checkAll(ctx)
+	bs.wg.Wait()
+	if len(tablets.backups) != 0 {
+		t.Fatalf("backup taken too early: %v", tablets.backups)
+	}
+
+	// Due now.
+	now = time.Date(2016, 8, 25, 0, 1, 0, 0, time.UTC)
+	bs.checkAll(ctx)
+	bs.wg.Wait()
+	if len(tablets.backups) != 1 || tablets.backups[0] != "cell1-0000000103" {
+		t.Fatalf("unexpected backups: %v", tablets.backups)
+	}
+	status, err := getBackupScheduleStatus(ctx, ts, "ks", "0")
+	if err != nil {
+		t.Fatalf("getBackupScheduleStatus failed: %v", err)
+	}
+	if status.Running != nil || len(status.History) != 1 || status.History[0].Tablet != "cell1-0000000103" || status.History[0].Error != "" {
+		t.Errorf("unexpected schedule after backup: %+v", status.BackupSchedule)
+	}
+	if want := time.Date(2016, 8, 25, 0, 0, 0, 0, time.UTC).Unix(); status.LastScheduledTime != want {
+		t.Errorf("LastScheduledTime: got %v, want %v", status.LastScheduledTime, want)
+	}
+	if want := time.Date(2016, 8, 26, 0, 0, 0, 0, time.UTC).Unix(); status.NextRunTime != want {
+		t.Errorf("NextRunTime: got %v, want %v", status.NextRunTime, want)
+	}
+
+	// The next day, no tablet is healthy: the failure is recorded.
+	tablets.health["cell1-0000000103"].HealthError = "replication stopped"
+	now = time.Date(2016, 8, 26, 0, 1, 0, 0, time.UTC)
+	bs.checkAll(ctx)
+	bs.wg.Wait()
+	if len(tablets.backups) != 1 {
+		t.Fatalf("unexpected backups: %v", tablets.backups)
+	}
+	bsi, err := ts.GetBackupSchedule(ctx, "ks", "0")
+	if err != nil {
+		t.Fatalf("GetBackupSchedule failed: %v", err)
+	}
+	if len(bsi.History) != 2 || !strings.Contains(bsi.History[1].Error, "no healthy tablet") {
+		t.Errorf("unexpected history: %+v", bsi.History)
+	}
+}
+
+func TestBackupSchedulerOneAtATime(t *testing.T) {
+	ctx := context.Background()
+	ts := zktestserver.New(t, []string{"cell1"})
+	now := time.Date(2016, 8, 25, 0, 1, 0, 0, time.UTC)
+	bs := &backupScheduler{
+		ts:    ts,
+		owner: "test",
+		now:   func() time.Time { return now },
+	}
+
+	running := &topo.BackupRun{
+		Owner:     "other",
+		StartTime: now.Add(-time.Hour).Unix(),
+	}
+	if _, err := ts.CreateBackupSchedule(ctx, "ks", "0", &topo.BackupSchedule{
+		Spec:              "@hourly",
+		LastScheduledTime: now.Add(-2 * time.Hour).Unix(),
+		Running:           running,
+	}); err != nil {
+		t.Fatalf("CreateBackupSchedule failed: %v", err)
+	}
+
+	// A backup is running, so nothing happens even if one is due.
+	if err := bs.checkShard(ctx, "ks", "0"); err != nil {
+		t.Fatalf("checkShard failed: %v", err)
+	}
+	bsi, err := ts.GetBackupSchedule(ctx, "ks", "0")
+	if err != nil {
+		t.Fatalf("GetBackupSchedule failed: %v", err)
+	}
+	if bsi.Running == nil || bsi.Running.Owner != "other" {
+		t.Errorf("running backup was replaced: %+v", bsi.Running)
+	}
+
+	// If it runs for too long, it is marked as failed. The new run
+	// can't find a tablet (the shard has none), so it fails too.
+	now = now.Add(*backupSchedulerRunTimeout)
+	if err := bs.checkShard(ctx, "ks", "0"); err != nil {
+		t.Fatalf("checkShard failed: %v", err)
+	}
+	bs.wg.Wait()
+	bsi, err = ts.GetBackupSchedule(ctx, "ks", "0")
+	if err != nil {
+		t.Fatalf("GetBackupSchedule failed: %v", err)
+	}
+	if bsi.Running != nil || len(bsi.History) != 2 || bsi.History[0].Owner != "other" || !strings.Contains(bsi.History[0].Error, "didn't finish") || bsi.History[1].Owner != "test" {
+		t.Errorf("unexpected schedule: %+v", bsi.BackupSchedule)
+	}
+	// The runs missed while the other backup was running are skipped.
+	if want := now.Truncate(time.Hour).Unix(); bsi.LastScheduledTime != want {
+		t.Errorf("LastScheduledTime: got %v, want %v", bsi.LastScheduledTime, want)
+	}
+}
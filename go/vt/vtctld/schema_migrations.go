@@ -0,0 +1,198 @@
+package vtctld
+
+import (
+	"flag"
+	"fmt"
+	"time"
+
+	log "github.com/golang/glog"
+	"golang.org/x/net/context"
+
+	"github.com/youtube/vitess/go/vt/topo"
+)
+
+// This file contains the schema migration scheduler: it periodically
+// reads the online schema migrations stored in the topology, releases
+// the queued ones to the shard masters one at a time per keyspace, and
+// records their outcome once all shards are done.
+
+var (
+	schemaMigrationCheckInterval = flag.Duration("schema_migration_check_interval", 0, "if set, vtctld checks the online schema migrations stored in the topology at this interval, and starts the queued ones. 0 disables the schema migration scheduler.")
+	schemaMigrationShardTimeout  = flag.Duration("schema_migration_shard_timeout", 10*time.Minute, "a shard whose master hasn't reported the progress of a running schema migration for longer than this is considered failed")
+	schemaMigrationRPCTimeout    = flag.Duration("schema_migration_rpc_timeout", 30*time.Second, "timeout for the topology calls of the schema migration scheduler")
+)
+
+// schemaMigrationScheduler drives the online schema migrations.
+// Multiple vtctld processes can run it: all updates are done with a
+// version check.
+type schemaMigrationScheduler struct {
+	ts topo.Server
+	// now returns the current time. Replaced in tests.
+	now func() time.Time
+}
+
+func newSchemaMigrationScheduler(ts topo.Server) *schemaMigrationScheduler {
+	return &schemaMigrationScheduler{
+		ts:  ts,
+		now: time.Now,
+	}
+}
+
+// run checks the migrations every interval, until ctx is canceled.
+func (sms *schemaMigrationScheduler) run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		sms.checkAll(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// checkAll checks the migrations of all keyspaces once.
+func (sms *schemaMigrationScheduler) checkAll(ctx context.Context) {
+	shortCtx, cancel := context.WithTimeout(ctx, *schemaMigrationRPCTimeout)
+	keyspaces, err := sms.ts.GetSchemaMigrationKeyspaces(shortCtx)
+	cancel()
+	if err != nil {
+		log.Warningf("schema migration scheduler: cannot list keyspaces: %v", err)
+		return
+	}
+	for _, keyspace := range keyspaces {
+		if err := sms.checkKeyspace(ctx, keyspace); err != nil {
+			log.Warningf("schema migration scheduler: %v: %v", keyspace, err)
+		}
+	}
+}
+
+// checkKeyspace updates the running migration of a keyspace, or
+// starts the oldest queued one if none is running.
+func (sms *schemaMigrationScheduler) checkKeyspace(ctx context.Context, keyspace string) error {
+	shortCtx, cancel := context.WithTimeout(ctx, *schemaMigrationRPCTimeout)
+	defer cancel()
+	ids, err := sms.ts.GetSchemaMigrationIDs(shortCtx, keyspace)
+	if err != nil {
+		return err
+	}
+
+	var queued []string
+	for _, id := range ids {
+		smi, err := sms.ts.GetSchemaMigration(shortCtx, keyspace, id)
+		switch err {
+		case nil:
+		case topo.ErrNoNode:
+			// Deleted in the meantime.
+			continue
+		default:
+			return err
+		}
+		switch smi.State {
+		case topo.SchemaMigrationRunning:
+			// Only one migration runs at a time, wait for it.
+			return sms.updateRunning(shortCtx, keyspace, id)
+		case topo.SchemaMigrationQueued:
+			queued = append(queued, id)
+		}
+	}
+	if len(queued) == 0 {
+		return nil
+	}
+	return sms.start(shortCtx, keyspace, queued[0])
+}
+
+// start releases a queued migration to the shard masters.
+func (sms *schemaMigrationScheduler) start(ctx context.Context, keyspace, id string) error {
+	now := sms.now()
+	_, err := sms.ts.UpdateSchemaMigrationFields(ctx, keyspace, id, func(sm *topo.SchemaMigration) error {
+		if sm.State != topo.SchemaMigrationQueued {
+			return topo.ErrNoUpdateNeeded
+		}
+		sm.State = topo.SchemaMigrationRunning
+		sm.StartTime = now.Unix()
+		for _, ssm := range sm.Shards {
+			if ssm.State == topo.SchemaMigrationQueued {
+				ssm.State = topo.SchemaMigrationReady
+			}
+		}
+		return nil
+	})
+	if err == nil {
+		log.Infof("schema migration scheduler: %v: started schema migration %v", keyspace, id)
+	}
+	return err
+}
+
+// updateRunning times out the shards that stopped reporting progress,
+// cancels the shards that haven't started if a cancel was requested,
+// and records the outcome of the migration once all shards are done.
+func (sms *schemaMigrationScheduler) updateRunning(ctx context.Context, keyspace, id string) error {
+	now := sms.now()
+	var state string
+	_, err := sms.ts.UpdateSchemaMigrationFields(ctx, keyspace, id, func(sm *topo.SchemaMigration) error {
+		state = ""
+		changed := false
+		for shard, ssm := range sm.Shards {
+			switch ssm.State {
+			case topo.SchemaMigrationReady:
+				switch {
+				case sm.CancelRequested:
+					ssm.State = topo.SchemaMigrationCancelled
+					ssm.EndTime = now.Unix()
+					changed = true
+				case now.Sub(time.Unix(sm.StartTime, 0)) > *schemaMigrationShardTimeout:
+					log.Warningf("schema migration scheduler: %v/%v: migration %v wasn't picked up by the master", keyspace, shard, id)
+					ssm.State = topo.SchemaMigrationFailed
+					ssm.Error = fmt.Sprintf("the master didn't pick up the migration within %v, check it runs with -online_ddl_check_interval", *schemaMigrationShardTimeout)
+					ssm.EndTime = now.Unix()
+					changed = true
+				}
+			case topo.SchemaMigrationRunning:
+				if now.Sub(time.Unix(ssm.UpdateTime, 0)) > *schemaMigrationShardTimeout {
+					log.Warningf("schema migration scheduler: %v/%v: migration %v timed out on tablet %v", keyspace, shard, id, ssm.Tablet)
+					ssm.State = topo.SchemaMigrationFailed
+					ssm.Error = fmt.Sprintf("tablet %v didn't report progress for %v", ssm.Tablet, *schemaMigrationShardTimeout)
+					ssm.EndTime = now.Unix()
+					changed = true
+				}
+			}
+		}
+
+		state = schemaMigrationOutcome(sm)
+		if state == "" {
+			if !changed {
+				return topo.ErrNoUpdateNeeded
+			}
+			return nil
+		}
+		sm.State = state
+		sm.EndTime = now.Unix()
+		return nil
+	})
+	if err == nil && state != "" {
+		log.Infof("schema migration scheduler: %v: schema migration %v is %v", keyspace, id, state)
+	}
+	return err
+}
+
+// schemaMigrationOutcome returns the final state of a migration, or ""
+// if some of its shards are not done yet.
+func schemaMigrationOutcome(sm *topo.SchemaMigration) string {
+	state := topo.SchemaMigrationComplete
+	for _, ssm := range sm.Shards {
+		switch ssm.State {
+		case topo.SchemaMigrationComplete:
+		case topo.SchemaMigrationFailed:
+			state = topo.SchemaMigrationFailed
+		case topo.SchemaMigrationCancelled:
+			if state == topo.SchemaMigrationComplete {
+				state = topo.SchemaMigrationCancelled
+			}
+		default:
+			return ""
+		}
+	}
+	return state
+}
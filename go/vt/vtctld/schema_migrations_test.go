@@ -0,0 +1,113 @@
+package vtctld
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/youtube/vitess/go/vt/topo"
+	"github.com/youtube/vitess/go/vt/zktopo/zktestserver"
+)
+
+func TestSchemaMigrationScheduler(t *testing.T) {
+	ctx := context.Background()
+	ts := zktestserver.New(t, []string{"cell1"})
+	for _, id := range []string{"1", "2"} {
+		if _, err := ts.CreateSchemaMigration(ctx, "ks", id, &topo.SchemaMigration{
+			SQL:   "alter table t add column c" + id + " int",
+			Table: "t",
+			State: topo.SchemaMigrationQueued,
+			Shards: map[string]*topo.ShardSchemaMigration{
+				"-80": {State: topo.SchemaMigrationQueued},
+				"80-": {State: topo.SchemaMigrationQueued},
+			},
+		}); err != nil {
+			t.Fatalf("CreateSchemaMigration failed: %v", err)
+		}
+	}
+	now := time.Unix(1000000, 0)
+	sms := newSchemaMigrationScheduler(ts)
+	sms.now = func() time.Time { return now }
+
+	checkState := func(id, want string, wantShards map[string]string) {
+		smi, err := ts.GetSchemaMigration(ctx, "ks", id)
+		if err != nil {
+			t.Fatalf("GetSchemaMigration(%v) failed: %v", id, err)
+		}
+		if smi.State != want {
+			t.Errorf("migration %v: state %v, want %v", id, smi.State, want)
+		}
+		for shard, state := range wantShards {
+			if got := smi.Shards[shard].State; got != state {
+				t.Errorf("migration %v: shard %v state %v, want %v", id, shard, got, state)
+			}
+		}
+	}
+
+	// The first migration is released, the second one waits.
+	sms.checkAll(ctx)
+	checkState("1", topo.SchemaMigrationRunning, map[string]string{"-80": topo.SchemaMigrationReady, "80-": topo.SchemaMigrationReady})
+	checkState("2", topo.SchemaMigrationQueued, nil)
+
+	// The masters pick it up, one of them completes it, the other
+	// one stops reporting progress.
+	if _, err := ts.UpdateSchemaMigrationFields(ctx, "ks", "1", func(sm *topo.SchemaMigration) error {
+		sm.Shards["-80"].State = topo.SchemaMigrationComplete
+		sm.Shards["80-"].State = topo.SchemaMigrationRunning
+		sm.Shards["80-"].UpdateTime = now.Unix()
+		return nil
+	}); err != nil {
+		t.Fatalf("UpdateSchemaMigrationFields failed: %v", err)
+	}
+	sms.checkAll(ctx)
+	checkState("1", topo.SchemaMigrationRunning, nil)
+	checkState("2", topo.SchemaMigrationQueued, nil)
+
+	now = now.Add(*schemaMigrationShardTimeout + time.Second)
+	sms.checkAll(ctx)
+	checkState("1", topo.SchemaMigrationFailed, map[string]string{"-80": topo.SchemaMigrationComplete, "80-": topo.SchemaMigrationFailed})
+	checkState("2", topo.SchemaMigrationQueued, nil)
+
+	// Then the next one starts, and its ready shards are cancelled
+	// right away.
+	sms.checkAll(ctx)
+	checkState("2", topo.SchemaMigrationRunning, map[string]string{"-80": topo.SchemaMigrationReady})
+	if _, err := ts.UpdateSchemaMigrationFields(ctx, "ks", "2", func(sm *topo.SchemaMigration) error {
+		sm.CancelRequested = true
+		return nil
+	}); err != nil {
+		t.Fatalf("UpdateSchemaMigrationFields failed: %v", err)
+	}
+	sms.checkAll(ctx)
+	checkState("2", topo.SchemaMigrationCancelled, map[string]string{"-80": topo.SchemaMigrationCancelled, "80-": topo.SchemaMigrationCancelled})
+}
+
+func TestSchemaMigrationOutcome(t *testing.T) {
+	testcases := []struct {
+		shards []string
+		want   string
+	}{{
+		shards: []string{topo.SchemaMigrationComplete, topo.SchemaMigrationComplete},
+		want:   topo.SchemaMigrationComplete,
+	}, {
+		shards: []string{topo.SchemaMigrationComplete, topo.SchemaMigrationRunning},
+		want:   "",
+	}, {
+		shards: []string{topo.SchemaMigrationCancelled, topo.SchemaMigrationComplete},
+		want:   topo.SchemaMigrationCancelled,
+	}, {
+		shards: []string{topo.SchemaMigrationCancelled, topo.SchemaMigrationFailed},
+		want:   topo.SchemaMigrationFailed,
+	}}
+	for _, tc := range testcases {
+		sm := &topo.SchemaMigration{Shards: make(map[string]*topo.ShardSchemaMigration)}
+		for i, state := range tc.shards {
+			sm.Shards[fmt.Sprintf("shard%v", i)] = &topo.ShardSchemaMigration{State: state}
+		}
+		if got := schemaMigrationOutcome(sm); got != tc.want {
+			t.Errorf("schemaMigrationOutcome(%v): %q, want %q", tc.shards, got, tc.want)
+		}
+	}
+}
@@ -165,6 +165,19 @@ func InitVtctld(ts topo.Server) {
 		}
 	}
 
+	// Start the backup scheduler if enabled.
+	if *backupSchedulerInterval > 0 {
+		go newBackupScheduler(ts).run(context.Background(), *backupSchedulerInterval)
+	}
+
+	// Start the schema migration scheduler if enabled.
+	if *schemaMigrationCheckInterval > 0 {
+		go newSchemaMigrationScheduler(ts).run(context.Background(), *schemaMigrationCheckInterval)
+	}
+
+	// Start the workflow manager if enabled, and serve its API.
+	initWorkflowManager(ts)
+
 	// Serve the REST API for the vtctld web app.
 	initAPI(context.Background(), ts, actionRepo, realtimeStats)
 
@@ -0,0 +1,125 @@
+package vtctld
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	log "github.com/golang/glog"
+	"golang.org/x/net/context"
+
+	"github.com/youtube/vitess/go/acl"
+	"github.com/youtube/vitess/go/vt/servenv"
+	"github.com/youtube/vitess/go/vt/topo"
+	"github.com/youtube/vitess/go/vt/workflow"
+	"github.com/youtube/vitess/go/vt/workflow/resharding"
+)
+
+// This file runs the workflow manager in vtctld, and serves the
+// workflow API. All the vtctlds can change the workflow records, but
+// only the leader of the election runs the workflows.
+
+var (
+	workflowManagerInit = flag.Bool("workflow_manager_init", false, "if set, vtctld takes part in the workflow manager election, and runs the workflows stored in the topology while it is the leader")
+)
+
+// workflowElectionName is the name of the workflow manager election.
+const workflowElectionName = "vtctld_workflow_manager"
+
+// initWorkflowManager starts the workflow manager if enabled, and
+// serves the workflow API.
+func initWorkflowManager(ts topo.Server) {
+	m := workflow.NewManager(ts)
+
+	if *workflowManagerInit {
+		hostname, err := os.Hostname()
+		if err != nil {
+			log.Errorf("cannot get the hostname, the workflow manager won't run: %v", err)
+		} else {
+			ctx, cancel := context.WithCancel(context.Background())
+			done := make(chan struct{})
+			servenv.OnRun(func() {
+				go func() {
+					m.RunWithElection(ctx, workflowElectionName, fmt.Sprintf("%v:%v", hostname, *servenv.Port))
+					close(done)
+				}()
+			})
+			servenv.OnTermSync(func() {
+				cancel()
+				<-done
+			})
+		}
+	}
+
+	// List the workflows, or get one.
+	handleCollection("workflows", func(r *http.Request) (interface{}, error) {
+		ctx := context.Background()
+		uuid := getItemPath(r.URL.Path)
+		if uuid == "" {
+			return m.List(ctx)
+		}
+		wi, err := ts.GetWorkflow(ctx, uuid)
+		if err != nil {
+			return nil, err
+		}
+		return wi.Workflow, nil
+	})
+
+	// Workflow actions: create, start, stop and delete, and the
+	// approve and rollback actions of the horizontal resharding
+	// workflows.
+	http.HandleFunc(apiPrefix+"workflow/", func(w http.ResponseWriter, r *http.Request) {
+		if err := acl.CheckAccessHTTP(r, acl.ADMIN); err != nil {
+			httpErrorf(w, r, "Access denied")
+			return
+		}
+		req := struct {
+			FactoryName string
+			Args        []string
+			SkipStart   bool
+			UUID        string
+			Step        string
+		}{}
+		if err := unmarshalRequest(r, &req); err != nil {
+			httpErrorf(w, r, "can't unmarshal request: %v", err)
+			return
+		}
+
+		ctx := context.Background()
+		var err error
+		switch action := strings.TrimPrefix(r.URL.Path, apiPrefix+"workflow/"); action {
+		case "create":
+			req.UUID, err = m.Create(ctx, req.FactoryName, req.Args)
+			if err == nil && !req.SkipStart {
+				err = m.Start(ctx, req.UUID)
+			}
+		case "start":
+			err = m.Start(ctx, req.UUID)
+		case "stop":
+			err = m.Stop(ctx, req.UUID)
+		case "delete":
+			err = m.Delete(ctx, req.UUID)
+		case "approve":
+			err = resharding.Approve(ctx, ts, req.UUID, req.Step)
+		case "rollback":
+			err = resharding.Rollback(ctx, ts, req.UUID)
+		default:
+			http.NotFound(w, r)
+			return
+		}
+		if err != nil {
+			httpErrorf(w, r, "workflow action failed: %v", err)
+			return
+		}
+		data, err := json.MarshalIndent(struct{ UUID string }{req.UUID}, "", "  ")
+		if err != nil {
+			httpErrorf(w, r, "json error: %v", err)
+			return
+		}
+		w.Header().Set("Content-Type", jsonContentType)
+		w.Write(data)
+	})
+}
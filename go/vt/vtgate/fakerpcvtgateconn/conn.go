@@ -325,7 +325,6 @@ func (conn *FakeVTGateConn) StreamExecute(ctx context.Context, sql string, bindV
 		return nil, response.err
 	}
 	var resultChan chan *sqltypes.Result
-	defer close(resultChan)
 	if response.reply != nil {
 		// create a result channel big enough to buffer all of
 		// the responses so we don't need to fork a go routine.
@@ -341,6 +340,7 @@ func (conn *FakeVTGateConn) StreamExecute(ctx context.Context, sql string, bindV
 	} else {
 		resultChan = make(chan *sqltypes.Result)
 	}
+	close(resultChan)
 	return &streamExecuteAdapter{resultChan}, nil
 }
 
@@ -63,12 +63,14 @@ type discoveryGateway struct {
 	// statusAggregators is a map indexed by the key
 	// keyspace/shard/tablet_type.
 	statusAggregators map[string]*TabletStatusAggregator
+	// statsListener is also notified of the health updates.
+	statsListener discovery.HealthCheckStatsListener
 }
 
 func createDiscoveryGateway(hc discovery.HealthCheck, topoServer topo.Server, serv topo.SrvTopoServer, cell string, retryCount int) Gateway {
 	dg := &discoveryGateway{
 		hc:                hc,
-		tsc:               discovery.NewTabletStatsCache(hc, cell),
+		tsc:               discovery.NewTabletStatsCacheDoNotSetListener(cell),
 		topoServer:        topoServer,
 		srvTopoServer:     serv,
 		localCell:         cell,
@@ -76,6 +78,9 @@ func createDiscoveryGateway(hc discovery.HealthCheck, topoServer topo.Server, se
 		tabletsWatchers:   make([]*discovery.TopologyWatcher, 0, 1),
 		statusAggregators: make(map[string]*TabletStatusAggregator),
 	}
+	// We need to set sendDownEvents=true to get the deletes from the
+	// TabletStatsCache upon type change.
+	hc.SetListener(dg, true /* sendDownEvents */)
 	log.Infof("loading tablets for cells: %v", *cellsToWatch)
 	for _, c := range strings.Split(*cellsToWatch, ",") {
 		if c == "" {
@@ -96,6 +101,27 @@ func createDiscoveryGateway(hc discovery.HealthCheck, topoServer topo.Server, se
 	return dg
 }
 
+// StatsUpdate is part of the discovery.HealthCheckStatsListener interface.
+// It forwards the update to the TabletStatsCache, and to the stats
+// listener, if any.
+func (dg *discoveryGateway) StatsUpdate(ts *discovery.TabletStats) {
+	dg.tsc.StatsUpdate(ts)
+
+	dg.mu.RLock()
+	listener := dg.statsListener
+	dg.mu.RUnlock()
+	if listener != nil {
+		listener.StatsUpdate(ts)
+	}
+}
+
+// SetStatsListener is part of the gateway.Gateway interface.
+func (dg *discoveryGateway) SetStatsListener(listener discovery.HealthCheckStatsListener) {
+	dg.mu.Lock()
+	defer dg.mu.Unlock()
+	dg.statsListener = listener
+}
+
 // WaitForTablets is part of the gateway.Gateway interface.
 func (dg *discoveryGateway) WaitForTablets(ctx context.Context, tabletTypesToWait []topodatapb.TabletType) error {
 	// Skip waiting for tablets if we are not told to do so.
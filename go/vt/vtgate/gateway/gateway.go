@@ -92,6 +92,11 @@ type Gateway interface {
 
 	// CacheStatus returns a list of TabletCacheStatus per tablet.
 	CacheStatus() TabletCacheStatusList
+
+	// SetStatsListener sets a listener for the health updates of
+	// the tablets the gateway sends queries to. Gateways that do
+	// not receive them from the tablets ignore it.
+	SetStatsListener(listener discovery.HealthCheckStatsListener)
 }
 
 // Creator is the factory method which can create the actual gateway object.
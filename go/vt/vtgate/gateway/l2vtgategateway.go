@@ -306,6 +306,12 @@ func (lg *l2VTGateGateway) Close(ctx context.Context) error {
 	return nil
 }
 
+// SetStatsListener is part of the gateway.Gateway interface.
+// l2vtgate doesn't forward the health updates of the tablets,
+// so the listener is never called.
+func (lg *l2VTGateGateway) SetStatsListener(listener discovery.HealthCheckStatsListener) {
+}
+
 // CacheStatus returns a list of TabletCacheStatus per
 // keyspace/shard/tablet_type.
 func (lg *l2VTGateGateway) CacheStatus() TabletCacheStatusList {
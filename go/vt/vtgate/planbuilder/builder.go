@@ -93,7 +93,8 @@ func Build(query string, vschema VSchema) (*engine.Plan, error) {
 		plan.Instructions, err = buildUpdatePlan(statement, vschema)
 	case *sqlparser.Delete:
 		plan.Instructions, err = buildDeletePlan(statement, vschema)
-	case *sqlparser.Union, *sqlparser.Set, *sqlparser.DDL, *sqlparser.Other:
+	case *sqlparser.Union, *sqlparser.Set, *sqlparser.DDL, *sqlparser.Show, *sqlparser.Use,
+		*sqlparser.Begin, *sqlparser.Commit, *sqlparser.Rollback, *sqlparser.Other:
 		return nil, errors.New("unsupported construct")
 	default:
 		panic("unexpected statement type")
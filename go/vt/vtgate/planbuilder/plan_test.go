@@ -15,6 +15,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/youtube/vitess/go/cistring"
 	"github.com/youtube/vitess/go/testfiles"
 	"github.com/youtube/vitess/go/vt/vtgate/vindexes"
 )
@@ -102,6 +103,38 @@ func TestPlan(t *testing.T) {
 	testFile(t, "unsupported_cases.txt", vschema)
 }
 
+// columnsVSchema adds the known columns of the tables to
+// a vschema, like vtgate does when it tracks the schema.
+type columnsVSchema struct {
+	*vindexes.VSchema
+	columns map[string][]cistring.CIString
+}
+
+func (vs *columnsVSchema) Find(keyspace, tablename string) (*vindexes.Table, error) {
+	table, err := vs.VSchema.Find(keyspace, tablename)
+	if err != nil {
+		return nil, err
+	}
+	columns, ok := vs.columns[table.Name]
+	if !ok {
+		return table, nil
+	}
+	withColumns := *table
+	withColumns.Columns = columns
+	return &withColumns, nil
+}
+
+func TestPlanWithColumns(t *testing.T) {
+	vschema := &columnsVSchema{
+		VSchema: loadSchema(t, "schema_test.json"),
+		columns: map[string][]cistring.CIString{
+			"user":       {cistring.New("id"), cistring.New("name")},
+			"user_extra": {cistring.New("id"), cistring.New("user_id"), cistring.New("email")},
+		},
+	}
+	testFile(t, "column_cases.txt", vschema)
+}
+
 func TestOne(t *testing.T) {
 	vschema := loadSchema(t, "schema_test.json")
 	testFile(t, "onecase.txt", vschema)
@@ -119,7 +152,7 @@ func loadSchema(t *testing.T, filename string) *vindexes.VSchema {
 	return vschema
 }
 
-func testFile(t *testing.T, filename string, vschema *vindexes.VSchema) {
+func testFile(t *testing.T, filename string, vschema VSchema) {
 	for tcase := range iterateExecFile(filename) {
 		plan, err := Build(tcase.input, vschema)
 		var out string
@@ -190,27 +190,36 @@ func checkAggregates(sel *sqlparser.Select, bldr builder) error {
 // pusheSelectRoutes is a convenience function that pushes all the select
 // expressions and returns the list of colsyms generated for it.
 func pushSelectRoutes(selectExprs sqlparser.SelectExprs, bldr builder) ([]*colsym, error) {
-	colsyms := make([]*colsym, len(selectExprs))
-	for i, node := range selectExprs {
+	colsyms := make([]*colsym, 0, len(selectExprs))
+	for _, node := range selectExprs {
 		switch node := node.(type) {
 		case *sqlparser.NonStarExpr:
-			rb, err := findRoute(node.Expr, bldr)
+			colsym, err := pushSelectRoute(node, bldr)
 			if err != nil {
 				return nil, err
 			}
-			colsyms[i], _, err = bldr.PushSelect(node, rb)
+			colsyms = append(colsyms, colsym)
+		case *sqlparser.StarExpr:
+			// We'll allow select * for simple routes.
+			if rb, ok := bldr.(*route); ok {
+				// We can push without validating the reference because
+				// MySQL will fail if it's invalid.
+				colsyms = append(colsyms, rb.PushStar(node))
+				continue
+			}
+			// For complex joins, the '*' is expanded into the
+			// columns of the tables, if they're known.
+			exprs, err := bldr.Symtab().ExpandStar(node)
 			if err != nil {
 				return nil, err
 			}
-		case *sqlparser.StarExpr:
-			// We'll allow select * for simple routes.
-			rb, ok := bldr.(*route)
-			if !ok {
-				return nil, errors.New("unsupported: '*' expression in complex join")
+			for _, expr := range exprs {
+				colsym, err := pushSelectRoute(expr, bldr)
+				if err != nil {
+					return nil, err
+				}
+				colsyms = append(colsyms, colsym)
 			}
-			// We can push without validating the reference because
-			// MySQL will fail if it's invalid.
-			colsyms[i] = rb.PushStar(node)
 		case sqlparser.Nextval:
 			// For now, this is only supported as an implicit feature
 			// for auto_inc in inserts.
@@ -219,3 +228,14 @@ func pushSelectRoutes(selectExprs sqlparser.SelectExprs, bldr builder) ([]*colsy
 	}
 	return colsyms, nil
 }
+
+// pushSelectRoute pushes a select expression to the route
+// it references.
+func pushSelectRoute(expr *sqlparser.NonStarExpr, bldr builder) (*colsym, error) {
+	rb, err := findRoute(expr.Expr, bldr)
+	if err != nil {
+		return nil, err
+	}
+	colsym, _, err := bldr.PushSelect(expr, rb)
+	return colsym, err
+}
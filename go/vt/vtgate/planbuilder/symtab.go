@@ -5,6 +5,7 @@
 package planbuilder
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/youtube/vitess/go/cistring"
@@ -74,6 +75,7 @@ func (st *symtab) AddAlias(alias, astName sqlparser.TableIdent, table *vindexes.
 		symtab:         st,
 		Keyspace:       table.Keyspace,
 		ColumnVindexes: table.ColumnVindexes,
+		Columns:        table.Columns,
 	})
 	return nil
 }
@@ -123,8 +125,11 @@ func (st *symtab) SetRHS() {
 // it. Subsequent searches will reuse this meatadata.
 // If autoResolve is true, and there is only one table in the symbol table,
 // then an unqualified reference is assumed to be implicitly against
-// that table. The table info doesn't contain the full list of columns.
-// So, any column reference is presumed valid. If a Colsyms scope is
+// that table. If there are more tables, and all their columns are known,
+// the reference is resolved against the only table that has the column.
+// If the table info doesn't contain the full list of columns, any column
+// reference is presumed valid. Otherwise, references to columns the
+// table doesn't have are reported as not found. If a Colsyms scope is
 // present, then the table scope is not searched. If a symbol is found
 // in the current symtab, then isLocal is set to true. Otherwise, the
 // search is continued in the outer symtab. If so, isLocal will be set
@@ -160,13 +165,16 @@ func (st *symtab) Find(col *sqlparser.ColName, autoResolve bool) (rb *route, isL
 		return nil, false, fmt.Errorf("symbol %s not found", sqlparser.String(col))
 	}
 	qualifier := sqlparser.TableIdent(sqlparser.String(col.Qualifier))
-	if qualifier == "" && autoResolve && len(st.tables) == 1 {
-		for _, t := range st.tables {
-			qualifier = t.Alias
-			break
+	if qualifier == "" && autoResolve {
+		qualifier, err = st.resolveColumn(col.Name)
+		if err != nil {
+			return nil, false, err
 		}
 	}
 	alias := st.findTable(qualifier)
+	if alias != nil && !alias.HasColumn(col.Name) {
+		return nil, false, fmt.Errorf("symbol %s not found", sqlparser.String(col))
+	}
 	if alias == nil {
 		if st.Outer != nil {
 			// autoResolve only allowed for innermost scope.
@@ -182,6 +190,64 @@ func (st *symtab) Find(col *sqlparser.ColName, autoResolve bool) (rb *route, isL
 	return alias.Route(), true, nil
 }
 
+// resolveColumn returns the alias of the table an unqualified column
+// reference is implicitly against. If there is only one table, that's
+// the one. Otherwise, it's the only table that has the column, which
+// can only be known if the columns of all the tables are known.
+// An empty alias is returned if the table cannot be determined.
+func (st *symtab) resolveColumn(name sqlparser.ColIdent) (sqlparser.TableIdent, error) {
+	if len(st.tables) == 1 {
+		return st.tables[0].Alias, nil
+	}
+	var found *tabsym
+	for _, t := range st.tables {
+		if t.Columns == nil {
+			return "", nil
+		}
+		if !t.HasColumn(name) {
+			continue
+		}
+		if found != nil {
+			return "", fmt.Errorf("ambiguous symbol reference: %s", name.Original())
+		}
+		found = t
+	}
+	if found == nil {
+		return "", nil
+	}
+	return found.Alias, nil
+}
+
+// ExpandStar returns the column references a '*' or 'table.*'
+// expression stands for. This requires the columns of the
+// tables to be known.
+func (st *symtab) ExpandStar(expr *sqlparser.StarExpr) ([]*sqlparser.NonStarExpr, error) {
+	var exprs []*sqlparser.NonStarExpr
+	found := false
+	for _, t := range st.tables {
+		if expr.TableName != "" && t.Alias != expr.TableName {
+			continue
+		}
+		found = true
+		if t.Columns == nil {
+			return nil, errors.New("unsupported: '*' expression in complex join")
+		}
+		for _, column := range t.Columns {
+			exprs = append(exprs, &sqlparser.NonStarExpr{
+				Expr: &sqlparser.ColName{
+					Metadata:  t,
+					Qualifier: &sqlparser.TableName{Name: t.ASTName},
+					Name:      sqlparser.ColIdent(column),
+				},
+			})
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("symbol %s not found", sqlparser.String(expr))
+	}
+	return exprs, nil
+}
+
 // Vindex returns the vindex if the expression is a plain column reference
 // that is part of the specified route, and has an associated vindex.
 func (st *symtab) Vindex(expr sqlparser.Expr, scope *route, autoResolve bool) vindexes.Vindex {
@@ -238,6 +304,9 @@ type tabsym struct {
 	symtab         *symtab
 	Keyspace       *vindexes.Keyspace
 	ColumnVindexes []*vindexes.ColumnVindex
+	// Columns is the list of columns of the table. It's nil
+	// if they're not known.
+	Columns []cistring.CIString
 }
 
 func (t *tabsym) newColRef(col *sqlparser.ColName) colref {
@@ -255,6 +324,20 @@ func (t *tabsym) Symtab() *symtab {
 	return t.symtab
 }
 
+// HasColumn returns true if the table has the column. If the
+// columns of the table are not known, it's presumed to have it.
+func (t *tabsym) HasColumn(name sqlparser.ColIdent) bool {
+	if t.Columns == nil {
+		return true
+	}
+	for _, column := range t.Columns {
+		if column.Equal(cistring.CIString(name)) {
+			return true
+		}
+	}
+	return false
+}
+
 // FindVindex returns the vindex if one was found for the column.
 func (t *tabsym) FindVindex(name sqlparser.ColIdent) vindexes.Vindex {
 	for _, colVindex := range t.ColumnVindexes {
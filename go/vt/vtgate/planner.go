@@ -22,6 +22,7 @@ import (
 	"github.com/youtube/vitess/go/cache"
 	"github.com/youtube/vitess/go/vt/topo"
 	"github.com/youtube/vitess/go/vt/vtgate/engine"
+	"github.com/youtube/vitess/go/vt/vtgate/gateway"
 	"github.com/youtube/vitess/go/vt/vtgate/planbuilder"
 	"github.com/youtube/vitess/go/vt/vtgate/vindexes"
 
@@ -31,11 +32,16 @@ import (
 // Planner is used to compute the plan. It contains
 // the vschema, and has a cache of previous computed plans.
 type Planner struct {
-	serv    topo.SrvTopoServer
-	cell    string
-	mu      sync.Mutex
-	vschema *vindexes.VSchema
-	plans   *cache.LRUCache
+	serv          topo.SrvTopoServer
+	cell          string
+	mu            sync.Mutex
+	vschema       *vindexes.VSchema
+	schemaTracker *SchemaTracker
+	// generation is incremented every time the vschema or the
+	// tracked columns change, before the plans are cleared. The
+	// plans built from the previous ones are not cached.
+	generation int64
+	plans      *cache.LRUCache
 }
 
 var plannerOnce sync.Once
@@ -104,6 +110,7 @@ func (plr *Planner) WatchSrvVSchema(ctx context.Context, cell string) {
 					plr.vschema = vschema
 				}
 			}
+			plr.generation++
 			plr.mu.Unlock()
 			plr.plans.Clear()
 
@@ -156,6 +163,32 @@ func (plr *Planner) VSchema() *vindexes.VSchema {
 	return plr.vschema
 }
 
+// TrackSchema makes the planner use the columns of the tables, as
+// reported by the tablets the gateway sends queries to.
+func (plr *Planner) TrackSchema(gw gateway.Gateway) {
+	schemaTracker := NewSchemaTracker(gw, plr.clearPlans)
+	plr.mu.Lock()
+	plr.schemaTracker = schemaTracker
+	plr.mu.Unlock()
+	gw.SetStatsListener(schemaTracker)
+}
+
+// clearPlans clears the plans, when the columns change.
+func (plr *Planner) clearPlans() {
+	plr.mu.Lock()
+	plr.generation++
+	plr.mu.Unlock()
+	plr.plans.Clear()
+}
+
+// SchemaTracker returns the SchemaTracker, or nil if the
+// schema is not tracked.
+func (plr *Planner) SchemaTracker() *SchemaTracker {
+	plr.mu.Lock()
+	defer plr.mu.Unlock()
+	return plr.schemaTracker
+}
+
 // GetPlan computes the plan for the given query. If one is in
 // the cache, it reuses it.
 func (plr *Planner) GetPlan(sql, keyspace string) (*engine.Plan, error) {
@@ -169,14 +202,24 @@ func (plr *Planner) GetPlan(sql, keyspace string) (*engine.Plan, error) {
 	if result, ok := plr.plans.Get(key); ok {
 		return result.(*engine.Plan), nil
 	}
+	plr.mu.Lock()
+	generation := plr.generation
+	plr.mu.Unlock()
 	plan, err := planbuilder.Build(sql, &wrappedVSchema{
-		vschema:  plr.VSchema(),
-		keyspace: keyspace,
+		vschema:       plr.VSchema(),
+		schemaTracker: plr.SchemaTracker(),
+		keyspace:      keyspace,
 	})
 	if err != nil {
 		return nil, err
 	}
-	plr.plans.Set(sql, plan)
+	// Don't cache the plan if it may have been built from the old
+	// vschema or columns, it could outlive the Clear.
+	plr.mu.Lock()
+	defer plr.mu.Unlock()
+	if plr.generation == generation {
+		plr.plans.Set(sql, plan)
+	}
 	return plan, nil
 }
 
@@ -217,13 +260,25 @@ func (plr *Planner) ServeHTTP(response http.ResponseWriter, request *http.Reques
 }
 
 type wrappedVSchema struct {
-	vschema  *vindexes.VSchema
-	keyspace string
+	vschema       *vindexes.VSchema
+	schemaTracker *SchemaTracker
+	keyspace      string
 }
 
 func (vs *wrappedVSchema) Find(keyspace, tablename string) (table *vindexes.Table, err error) {
 	if keyspace == "" {
 		keyspace = vs.keyspace
 	}
-	return vs.vschema.Find(keyspace, tablename)
+	table, err = vs.vschema.Find(keyspace, tablename)
+	if err != nil || vs.schemaTracker == nil {
+		return table, err
+	}
+	columns := vs.schemaTracker.Columns(table.Keyspace.Name, table.Name)
+	if columns == nil {
+		return table, nil
+	}
+	// The vschema tables are shared: the columns go in a copy.
+	withColumns := *table
+	withColumns.Columns = columns
+	return &withColumns, nil
 }
@@ -355,42 +355,62 @@ func (stc *ScatterConn) ExecuteBatch(
 	return results, nil
 }
 
-func (stc *ScatterConn) processOneStreamingResult(mu *sync.Mutex, stream sqltypes.ResultStream, err error, replyErr *error, fieldSent *bool, sendReply func(reply *sqltypes.Result) error) error {
+func (stc *ScatterConn) processOneStreamingResult(sm *streamMerger, stream sqltypes.ResultStream, err error) error {
 	if err != nil {
 		return err
 	}
 	for {
 		qr, err := stream.Recv()
 		if err != nil {
-			if err == io.EOF {
+			// If the query failed, the stream was cancelled and
+			// streamMerger.send reports the reason.
+			if err == io.EOF || sm.stopped() {
 				return nil
 			}
 			return err
 		}
-
-		mu.Lock()
-		if *replyErr != nil {
-			mu.Unlock()
-			// we had an error sending results, drain input
-			for {
-				if _, err := stream.Recv(); err != nil {
-					break
-				}
+		if err := sm.add(qr); err != nil {
+			if err == errStreamStopped {
+				return nil
 			}
-			return nil
+			return err
 		}
+	}
+}
 
-		// only send field info once for scattered streaming
-		if len(qr.Fields) > 0 && len(qr.Rows) == 0 {
-			if *fieldSent {
-				mu.Unlock()
-				continue
-			}
-			*fieldSent = true
-		}
-		*replyErr = sendReply(qr)
-		mu.Unlock()
+// streamExecute runs the tablet streams of a streaming query in the
+// background, and sends their merged results to the client.
+func (stc *ScatterConn) streamExecute(
+	ctx context.Context,
+	keyspace string,
+	shards []string,
+	tabletType topodatapb.TabletType,
+	sendReply func(reply *sqltypes.Result) error,
+	streamShard func(ctx context.Context, shard string) (sqltypes.ResultStream, error),
+) error {
+	sm := newStreamMerger(ctx, keyspace)
+	var allErrors *concurrency.AllErrorRecorder
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		allErrors = stc.multiGo(
+			sm.ctx,
+			"StreamExecute",
+			keyspace,
+			shards,
+			tabletType,
+			func(shard string) error {
+				stream, err := streamShard(sm.ctx, shard)
+				return stc.processOneStreamingResult(sm, stream, err)
+			})
+		sm.close()
+	}()
+	replyErr := sm.send(sendReply)
+	<-done
+	if replyErr != nil {
+		allErrors.RecordError(replyErr)
 	}
+	return allErrors.AggrError(stc.aggregateErrors)
 }
 
 // StreamExecute executes a streaming query on vttablet. The retry rules are the same.
@@ -404,26 +424,9 @@ func (stc *ScatterConn) StreamExecute(
 	options *querypb.ExecuteOptions,
 	sendReply func(reply *sqltypes.Result) error,
 ) error {
-
-	// mu protects fieldSent, replyErr and sendReply
-	var mu sync.Mutex
-	var replyErr error
-	fieldSent := false
-
-	allErrors := stc.multiGo(
-		ctx,
-		"StreamExecute",
-		keyspace,
-		shards,
-		tabletType,
-		func(shard string) error {
-			stream, err := stc.gateway.StreamExecute(ctx, keyspace, shard, tabletType, query, bindVars, options)
-			return stc.processOneStreamingResult(&mu, stream, err, &replyErr, &fieldSent, sendReply)
-		})
-	if replyErr != nil {
-		allErrors.RecordError(replyErr)
-	}
-	return allErrors.AggrError(stc.aggregateErrors)
+	return stc.streamExecute(ctx, keyspace, shards, tabletType, sendReply, func(ctx context.Context, shard string) (sqltypes.ResultStream, error) {
+		return stc.gateway.StreamExecute(ctx, keyspace, shard, tabletType, query, bindVars, options)
+	})
 }
 
 // StreamExecuteMulti is like StreamExecute,
@@ -438,25 +441,9 @@ func (stc *ScatterConn) StreamExecuteMulti(
 	options *querypb.ExecuteOptions,
 	sendReply func(reply *sqltypes.Result) error,
 ) error {
-	// mu protects fieldSent, sendReply and replyErr
-	var mu sync.Mutex
-	var replyErr error
-	fieldSent := false
-
-	allErrors := stc.multiGo(
-		ctx,
-		"StreamExecute",
-		keyspace,
-		getShards(shardVars),
-		tabletType,
-		func(shard string) error {
-			stream, err := stc.gateway.StreamExecute(ctx, keyspace, shard, tabletType, query, shardVars[shard], options)
-			return stc.processOneStreamingResult(&mu, stream, err, &replyErr, &fieldSent, sendReply)
-		})
-	if replyErr != nil {
-		allErrors.RecordError(replyErr)
-	}
-	return allErrors.AggrError(stc.aggregateErrors)
+	return stc.streamExecute(ctx, keyspace, getShards(shardVars), tabletType, sendReply, func(ctx context.Context, shard string) (sqltypes.ResultStream, error) {
+		return stc.gateway.StreamExecute(ctx, keyspace, shard, tabletType, query, shardVars[shard], options)
+	})
 }
 
 // Commit commits the current transaction. There are no retries on this operation.
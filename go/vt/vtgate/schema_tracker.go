@@ -0,0 +1,232 @@
+// Copyright 2016, Google Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vtgate
+
+// This is a V3 file. Do not intermix with V2.
+
+import (
+	"flag"
+	"io"
+	"sync"
+	"time"
+
+	log "github.com/golang/glog"
+	"golang.org/x/net/context"
+
+	"github.com/youtube/vitess/go/cistring"
+	"github.com/youtube/vitess/go/vt/discovery"
+	"github.com/youtube/vitess/go/vt/vtgate/gateway"
+
+	querypb "github.com/youtube/vitess/go/vt/proto/query"
+)
+
+var enableSchemaTracking = flag.Bool("enable_schema_tracking", false, "if set, vtgate keeps track of the columns of the tables, as reported by the tablets, to expand '*' expressions in joins and to validate column references")
+
+const (
+	// columnsQuery returns the columns of all the tables of
+	// the database of a tablet.
+	columnsQuery = "select table_name, column_name from information_schema.columns where table_schema = database() order by table_name, ordinal_position"
+	// tableColumnsQuery returns the columns of some tables only.
+	tableColumnsQuery = "select table_name, column_name from information_schema.columns where table_schema = database() and table_name in ::tables order by table_name, ordinal_position"
+
+	columnsQueryTimeout = 30 * time.Second
+
+	// After a failed load, the columns are not loaded again before
+	// a delay, which starts at minLoadRetryDelay and doubles with
+	// every failure, up to maxLoadRetryDelay.
+	minLoadRetryDelay = 5 * time.Second
+	maxLoadRetryDelay = 5 * time.Minute
+)
+
+// SchemaTracker keeps the columns of the tables of each keyspace.
+// It listens to the health updates of the tablets: the first time
+// a serving tablet of a keyspace is seen, the columns of all its
+// tables are loaded from it. After that, only the tables the tablets
+// report as changed are loaded again. If the schema version of a
+// tablet shows we missed a change, all the tables are loaded again.
+type SchemaTracker struct {
+	gateway  gateway.Gateway
+	onChange func()
+
+	// mu protects the following fields and their contents.
+	mu        sync.Mutex
+	keyspaces map[string]*keyspaceColumns
+	// schemaVersions are the last schema versions reported by
+	// the tablets, by tablet key.
+	schemaVersions map[string]int64
+}
+
+// keyspaceColumns contains the columns of the tables of a keyspace.
+type keyspaceColumns struct {
+	tables map[string][]cistring.CIString
+	// full is set if all the tables must be loaded. Otherwise,
+	// changed contains the tables to load.
+	full    bool
+	changed map[string]bool
+	// loading is set while a goroutine loads the columns.
+	loading bool
+	// retryDelay is the delay after the last failed load, or 0 if
+	// it succeeded. The columns are not loaded before nextLoad.
+	retryDelay time.Duration
+	nextLoad   time.Time
+}
+
+// NewSchemaTracker creates a new SchemaTracker. The columns are loaded
+// through the gateway, and onChange is called every time they change.
+func NewSchemaTracker(gw gateway.Gateway, onChange func()) *SchemaTracker {
+	return &SchemaTracker{
+		gateway:        gw,
+		onChange:       onChange,
+		keyspaces:      make(map[string]*keyspaceColumns),
+		schemaVersions: make(map[string]int64),
+	}
+}
+
+// StatsUpdate is part of the discovery.HealthCheckStatsListener interface.
+func (st *SchemaTracker) StatsUpdate(ts *discovery.TabletStats) {
+	if !ts.Up || !ts.Serving || ts.Target == nil {
+		return
+	}
+
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	kc, ok := st.keyspaces[ts.Target.Keyspace]
+	if !ok {
+		kc = &keyspaceColumns{
+			full:    true,
+			changed: make(map[string]bool),
+		}
+		st.keyspaces[ts.Target.Keyspace] = kc
+	}
+	if ts.Stats != nil {
+		version, known := st.schemaVersions[ts.Key]
+		st.schemaVersions[ts.Key] = ts.Stats.SchemaVersion
+		switch {
+		case !known || ts.Stats.SchemaVersion == version:
+			// The tablet was just found, or its schema didn't
+			// change.
+		case ts.Stats.SchemaVersion == version+1 && len(ts.Stats.TableSchemaChanged) > 0:
+			for _, table := range ts.Stats.TableSchemaChanged {
+				kc.changed[table] = true
+			}
+		default:
+			// We missed the message listing the changed tables,
+			// or the tablet restarted.
+			kc.full = true
+		}
+	}
+	if kc.loading || (!kc.full && len(kc.changed) == 0) || time.Now().Before(kc.nextLoad) {
+		return
+	}
+	kc.loading = true
+	target := *ts.Target
+	go st.load(&target, kc)
+}
+
+// load loads the columns of the keyspace from the tablets of the
+// target, until there is nothing left to load. If it fails, what
+// was not loaded is kept, and will be loaded again with the first
+// health update after the retry delay.
+func (st *SchemaTracker) load(target *querypb.Target, kc *keyspaceColumns) {
+	for {
+		st.mu.Lock()
+		full, changed := kc.full, kc.changed
+		if !full && len(changed) == 0 {
+			kc.loading = false
+			st.mu.Unlock()
+			return
+		}
+		kc.full = false
+		kc.changed = make(map[string]bool)
+		st.mu.Unlock()
+
+		tables, err := st.loadColumns(target, full, changed)
+
+		st.mu.Lock()
+		if err != nil {
+			kc.retryDelay *= 2
+			if kc.retryDelay < minLoadRetryDelay {
+				kc.retryDelay = minLoadRetryDelay
+			}
+			if kc.retryDelay > maxLoadRetryDelay {
+				kc.retryDelay = maxLoadRetryDelay
+			}
+			kc.nextLoad = time.Now().Add(kc.retryDelay)
+			log.Warningf("Error loading the columns of keyspace %v (will try again in %v): %v", target.Keyspace, kc.retryDelay, err)
+			kc.full = kc.full || full
+			for table := range changed {
+				kc.changed[table] = true
+			}
+			kc.loading = false
+			st.mu.Unlock()
+			return
+		}
+		kc.retryDelay = 0
+		if full {
+			kc.tables = tables
+		} else {
+			for table := range changed {
+				if columns, ok := tables[table]; ok {
+					kc.tables[table] = columns
+				} else {
+					// The table was dropped.
+					delete(kc.tables, table)
+				}
+			}
+		}
+		st.mu.Unlock()
+		st.onChange()
+	}
+}
+
+// loadColumns loads the columns of all the tables if full is set, or
+// of the changed tables otherwise. The query is streamed, since the
+// columns of all the tables may be more than the rows a non-streaming
+// query can return.
+func (st *SchemaTracker) loadColumns(target *querypb.Target, full bool, changed map[string]bool) (map[string][]cistring.CIString, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), columnsQueryTimeout)
+	defer cancel()
+
+	query := columnsQuery
+	var bindVars map[string]interface{}
+	if !full {
+		names := make([]interface{}, 0, len(changed))
+		for table := range changed {
+			names = append(names, table)
+		}
+		query = tableColumnsQuery
+		bindVars = map[string]interface{}{"tables": names}
+	}
+	stream, err := st.gateway.StreamExecute(ctx, target.Keyspace, target.Shard, target.TabletType, query, bindVars, nil)
+	if err != nil {
+		return nil, err
+	}
+	tables := make(map[string][]cistring.CIString)
+	for {
+		qr, err := stream.Recv()
+		if err == io.EOF {
+			return tables, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, row := range qr.Rows {
+			table := row[0].String()
+			tables[table] = append(tables[table], cistring.New(row[1].String()))
+		}
+	}
+}
+
+// Columns returns the columns of a table, or nil if they are not known.
+// The returned slice must not be modified.
+func (st *SchemaTracker) Columns(keyspace, table string) []cistring.CIString {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	kc, ok := st.keyspaces[keyspace]
+	if !ok {
+		return nil
+	}
+	return kc.tables[table]
+}
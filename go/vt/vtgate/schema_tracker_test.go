@@ -0,0 +1,258 @@
+// Copyright 2016, Google Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vtgate
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/youtube/vitess/go/cistring"
+	"github.com/youtube/vitess/go/sqltypes"
+	"github.com/youtube/vitess/go/vt/discovery"
+	"github.com/youtube/vitess/go/vt/topo"
+
+	querypb "github.com/youtube/vitess/go/vt/proto/query"
+	topodatapb "github.com/youtube/vitess/go/vt/proto/topodata"
+)
+
+func columnsResult(rows ...[2]string) *sqltypes.Result {
+	result := &sqltypes.Result{}
+	for _, row := range rows {
+		result.Rows = append(result.Rows, []sqltypes.Value{
+			sqltypes.MakeString([]byte(row[0])),
+			sqltypes.MakeString([]byte(row[1])),
+		})
+	}
+	result.RowsAffected = uint64(len(result.Rows))
+	return result
+}
+
+func TestSchemaTracker(t *testing.T) {
+	keyspace := "TestSchemaTracker"
+	hc := discovery.NewFakeHealthCheck()
+	sc := NewScatterConn(hc, topo.Server{}, new(sandboxTopo), "", "aa", retryCount, nil)
+	sbc := hc.AddTestTablet("aa", "0", 1, keyspace, "0", topodatapb.TabletType_REPLICA, true, 1, nil)
+
+	changes := make(chan struct{}, 10)
+	st := NewSchemaTracker(sc.gateway, func() { changes <- struct{}{} })
+	waitForChange := func() {
+		select {
+		case <-changes:
+		case <-time.After(10 * time.Second):
+			t.Fatalf("timed out waiting for the columns to be loaded")
+		}
+	}
+	ts := &discovery.TabletStats{
+		Target: &querypb.Target{
+			Keyspace:   keyspace,
+			Shard:      "0",
+			TabletType: topodatapb.TabletType_REPLICA,
+		},
+		Up:      true,
+		Serving: true,
+		Stats:   &querypb.RealtimeStats{},
+	}
+
+	// The first health update loads all the tables.
+	sbc.SetResults([]*sqltypes.Result{columnsResult(
+		[2]string{"t1", "id"},
+		[2]string{"t1", "name"},
+		[2]string{"t2", "id"},
+	)})
+	st.StatsUpdate(ts)
+	waitForChange()
+	if got, want := sbc.Queries[0].Sql, columnsQuery; got != want {
+		t.Errorf("query: %v, want %v", got, want)
+	}
+	if got, want := st.Columns(keyspace, "t1"), []cistring.CIString{cistring.New("id"), cistring.New("name")}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Columns(t1): %v, want %v", got, want)
+	}
+
+	// Updates without schema changes don't load anything.
+	st.StatsUpdate(ts)
+	if got := sbc.ExecCount.Get(); got != 1 {
+		t.Errorf("ExecCount: %v, want 1", got)
+	}
+
+	// Only the changed tables are loaded again.
+	sbc.SetResults([]*sqltypes.Result{columnsResult(
+		[2]string{"t2", "id"},
+		[2]string{"t2", "email"},
+	)})
+	changed := *ts
+	changed.Stats = &querypb.RealtimeStats{TableSchemaChanged: []string{"t2", "t3"}, SchemaVersion: 1}
+	st.StatsUpdate(&changed)
+	waitForChange()
+	if got, want := sbc.Queries[1].Sql, tableColumnsQuery; got != want {
+		t.Errorf("query: %v, want %v", got, want)
+	}
+	if got, want := st.Columns(keyspace, "t2"), []cistring.CIString{cistring.New("id"), cistring.New("email")}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Columns(t2): %v, want %v", got, want)
+	}
+	if got := st.Columns(keyspace, "t1"); len(got) != 2 {
+		t.Errorf("Columns(t1): %v, want 2 columns", got)
+	}
+	if got := st.Columns(keyspace, "t3"); got != nil {
+		t.Errorf("Columns(t3): %v, want nil", got)
+	}
+	if got := st.Columns("unknown", "t1"); got != nil {
+		t.Errorf("Columns(unknown.t1): %v, want nil", got)
+	}
+
+	// The same version, without the changed tables, doesn't load
+	// anything.
+	unchanged := *ts
+	unchanged.Stats = &querypb.RealtimeStats{SchemaVersion: 1}
+	st.StatsUpdate(&unchanged)
+	if got := sbc.ExecCount.Get(); got != 2 {
+		t.Errorf("ExecCount: %v, want 2", got)
+	}
+
+	// A version jump means we missed a change: all the tables are
+	// loaded again.
+	sbc.SetResults([]*sqltypes.Result{columnsResult(
+		[2]string{"t1", "id"},
+		[2]string{"t3", "id"},
+	)})
+	missed := *ts
+	missed.Stats = &querypb.RealtimeStats{SchemaVersion: 3}
+	st.StatsUpdate(&missed)
+	waitForChange()
+	if got, want := sbc.Queries[2].Sql, columnsQuery; got != want {
+		t.Errorf("query: %v, want %v", got, want)
+	}
+	if got := st.Columns(keyspace, "t2"); got != nil {
+		t.Errorf("Columns(t2): %v, want nil", got)
+	}
+	if got, want := st.Columns(keyspace, "t3"), []cistring.CIString{cistring.New("id")}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Columns(t3): %v, want %v", got, want)
+	}
+}
+
+func TestSchemaTrackerManyColumns(t *testing.T) {
+	keyspace := "TestSchemaTrackerManyColumns"
+	hc := discovery.NewFakeHealthCheck()
+	sc := NewScatterConn(hc, topo.Server{}, new(sandboxTopo), "", "aa", retryCount, nil)
+	sbc := hc.AddTestTablet("aa", "0", 1, keyspace, "0", topodatapb.TabletType_REPLICA, true, 1, nil)
+
+	changes := make(chan struct{}, 10)
+	st := NewSchemaTracker(sc.gateway, func() { changes <- struct{}{} })
+
+	// More columns than the rows a non-streaming query can return.
+	var rows [][2]string
+	for i := 0; i < 10001; i++ {
+		rows = append(rows, [2]string{fmt.Sprintf("t%v", i/10), fmt.Sprintf("c%v", i%10)})
+	}
+	sbc.SetResults([]*sqltypes.Result{columnsResult(rows...)})
+	st.StatsUpdate(&discovery.TabletStats{
+		Target: &querypb.Target{
+			Keyspace:   keyspace,
+			Shard:      "0",
+			TabletType: topodatapb.TabletType_REPLICA,
+		},
+		Up:      true,
+		Serving: true,
+		Stats:   &querypb.RealtimeStats{},
+	})
+	select {
+	case <-changes:
+	case <-time.After(10 * time.Second):
+		t.Fatalf("timed out waiting for the columns to be loaded")
+	}
+	if got := st.Columns(keyspace, "t999"); len(got) != 10 {
+		t.Errorf("Columns(t999): %v, want 10 columns", got)
+	}
+	if got, want := st.Columns(keyspace, "t1000"), []cistring.CIString{cistring.New("c0")}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Columns(t1000): %v, want %v", got, want)
+	}
+}
+
+func TestSchemaTrackerLoadError(t *testing.T) {
+	keyspace := "TestSchemaTrackerLoadError"
+	hc := discovery.NewFakeHealthCheck()
+	sc := NewScatterConn(hc, topo.Server{}, new(sandboxTopo), "", "aa", retryCount, nil)
+	sbc := hc.AddTestTablet("aa", "0", 1, keyspace, "0", topodatapb.TabletType_REPLICA, true, 1, nil)
+
+	changes := make(chan struct{}, 10)
+	st := NewSchemaTracker(sc.gateway, func() { changes <- struct{}{} })
+	ts := &discovery.TabletStats{
+		Target: &querypb.Target{
+			Keyspace:   keyspace,
+			Shard:      "0",
+			TabletType: topodatapb.TabletType_REPLICA,
+		},
+		Up:      true,
+		Serving: true,
+		Stats:   &querypb.RealtimeStats{},
+	}
+	// waitForLoad waits until the load started by a health update
+	// is over, and returns the keyspace.
+	waitForLoad := func() *keyspaceColumns {
+		for i := 0; i < 1000; i++ {
+			st.mu.Lock()
+			kc := st.keyspaces[keyspace]
+			loading := kc.loading
+			st.mu.Unlock()
+			if !loading {
+				return kc
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		t.Fatalf("timed out waiting for the columns to be loaded")
+		return nil
+	}
+
+	sbc.MustFailServer = 1
+	st.StatsUpdate(ts)
+	kc := waitForLoad()
+	st.mu.Lock()
+	if !kc.full || kc.retryDelay != minLoadRetryDelay {
+		t.Errorf("after an error: full: %v, retryDelay: %v, want true, %v", kc.full, kc.retryDelay, minLoadRetryDelay)
+	}
+	st.mu.Unlock()
+
+	// The next health updates don't load anything before the
+	// retry delay.
+	st.StatsUpdate(ts)
+	if got := sbc.ExecCount.Get(); got != 1 {
+		t.Errorf("ExecCount: %v, want 1", got)
+	}
+
+	// After the delay, the failure doubles it.
+	st.mu.Lock()
+	kc.nextLoad = time.Time{}
+	st.mu.Unlock()
+	sbc.MustFailServer = 1
+	st.StatsUpdate(ts)
+	waitForLoad()
+	st.mu.Lock()
+	if got, want := kc.retryDelay, 2*minLoadRetryDelay; got != want {
+		t.Errorf("retryDelay: %v, want %v", got, want)
+	}
+	kc.nextLoad = time.Time{}
+	st.mu.Unlock()
+
+	// A successful load resets it.
+	sbc.SetResults([]*sqltypes.Result{columnsResult([2]string{"t1", "id"})})
+	st.StatsUpdate(ts)
+	select {
+	case <-changes:
+	case <-time.After(10 * time.Second):
+		t.Fatalf("timed out waiting for the columns to be loaded")
+	}
+	if got, want := st.Columns(keyspace, "t1"), []cistring.CIString{cistring.New("id")}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Columns(t1): %v, want %v", got, want)
+	}
+	st.mu.Lock()
+	if kc.retryDelay != 0 {
+		t.Errorf("retryDelay: %v, want 0", kc.retryDelay)
+	}
+	st.mu.Unlock()
+	if got := sbc.ExecCount.Get(); got != 3 {
+		t.Errorf("ExecCount: %v, want 3", got)
+	}
+}
@@ -0,0 +1,265 @@
+// Copyright 2016, Google Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vtgate
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"sync"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/youtube/vitess/go/sqltypes"
+	"github.com/youtube/vitess/go/stats"
+	"github.com/youtube/vitess/go/vt/vterrors"
+
+	vtrpcpb "github.com/youtube/vitess/go/vt/proto/vtrpc"
+)
+
+const (
+	// streamBufferPolicyBlock makes the shard streams wait when the
+	// buffer of a streaming query is full.
+	streamBufferPolicyBlock = "block"
+	// streamBufferPolicyAbort makes the streaming query fail when its
+	// buffer is full.
+	streamBufferPolicyAbort = "abort"
+)
+
+var (
+	streamBufferSize       = flag.Int("stream_buffer_size", 1024*1024, "maximum number of bytes of results buffered for each streaming query, between the tablet streams and the client. When the buffer is full, vtgate stops reading from the tablets, which in turn stop reading from MySQL until the client catches up. 0 lets only one result be pending at a time.")
+	streamBufferFullPolicy = flag.String("stream_buffer_full_policy", streamBufferPolicyBlock, "what to do when the buffer of a streaming query is full: 'block' waits for the client to catch up, 'abort' fails the query.")
+	streamIdleTimeout      = flag.Duration("stream_idle_timeout", 0, "if set, a streaming query fails when its client hasn't read any result for that long while its buffer is full. The tablet streams are cancelled, which releases their MySQL connections. Defaults to no limit.")
+
+	// streamBufferedBytes is the number of bytes buffered by all the
+	// streaming queries.
+	streamBufferedBytes = stats.NewInt("VtgateStreamBufferedBytes")
+	// streamBufferWaits shows how long the tablet streams waited for
+	// room in a full buffer, per keyspace.
+	streamBufferWaits = stats.NewTimings("VtgateStreamBufferWaits")
+	// streamAborts shows the number of streaming queries that failed
+	// because of a limit, per reason.
+	streamAborts = stats.NewCounters("VtgateStreamAborts", "BufferFull", "IdleTimeout")
+)
+
+// errStreamStopped is returned to the tablet streams once the
+// streaming query failed. The error is reported by streamMerger.send.
+var errStreamStopped = errors.New("stream stopped")
+
+// streamMerger merges the results of the tablet streams of a
+// streaming query into the stream of the client. The results are
+// buffered up to a byte budget: the tablet streams add them from
+// their own goroutine, and the caller sends them to the client.
+// When the budget is used up, the tablet streams stop reading, so
+// the backpressure of a slow client propagates to the tablets.
+type streamMerger struct {
+	ctx         context.Context
+	cancel      context.CancelFunc
+	keyspace    string
+	budget      int
+	policy      string
+	idleTimeout time.Duration
+
+	// ready is signaled when results were added, or the tablet
+	// streams are done.
+	ready chan struct{}
+
+	// mu protects the fields below.
+	mu        sync.Mutex
+	results   []*sqltypes.Result
+	sizes     []int
+	buffered  int
+	fieldSent bool
+	done      bool
+	// err is set once the query failed. Nothing is sent after it.
+	err error
+	// lastSend is the last time the client read a result.
+	lastSend time.Time
+	// space is closed, and replaced, when the client read a result.
+	space chan struct{}
+}
+
+// newStreamMerger creates a streamMerger from the flags. The tablet
+// streams must use its context: it is cancelled when the query fails.
+func newStreamMerger(ctx context.Context, keyspace string) *streamMerger {
+	ctx, cancel := context.WithCancel(ctx)
+	return &streamMerger{
+		ctx:         ctx,
+		cancel:      cancel,
+		keyspace:    keyspace,
+		budget:      *streamBufferSize,
+		policy:      *streamBufferFullPolicy,
+		idleTimeout: *streamIdleTimeout,
+		ready:       make(chan struct{}, 1),
+		space:       make(chan struct{}),
+	}
+}
+
+// resultSize estimates the memory used by a result.
+func resultSize(qr *sqltypes.Result) int {
+	size := 0
+	for _, row := range qr.Rows {
+		for _, v := range row {
+			size += v.Len()
+		}
+	}
+	return size
+}
+
+// add buffers a result of a tablet stream. It waits while the buffer
+// is full. It returns errStreamStopped if the query failed, or the
+// error of the context.
+func (sm *streamMerger) add(qr *sqltypes.Result) error {
+	// Only send the fields once. They are small enough not to
+	// count against the budget.
+	if len(qr.Fields) > 0 && len(qr.Rows) == 0 {
+		sm.mu.Lock()
+		fieldSent := sm.fieldSent
+		sm.fieldSent = true
+		sm.mu.Unlock()
+		if fieldSent {
+			return nil
+		}
+	}
+
+	size := resultSize(qr)
+	var waitStart time.Time
+	for {
+		sm.mu.Lock()
+		if sm.err != nil {
+			sm.mu.Unlock()
+			return errStreamStopped
+		}
+		// A result bigger than the budget is accepted when nothing
+		// else is buffered.
+		if sm.buffered == 0 || sm.buffered+size <= sm.budget {
+			sm.results = append(sm.results, qr)
+			sm.sizes = append(sm.sizes, size)
+			sm.buffered += size
+			sm.mu.Unlock()
+			streamBufferedBytes.Add(int64(size))
+			if !waitStart.IsZero() {
+				streamBufferWaits.Record(sm.keyspace, waitStart)
+			}
+			sm.signal()
+			return nil
+		}
+		if sm.policy == streamBufferPolicyAbort {
+			sm.abortLocked("BufferFull", fmt.Errorf("stream buffer full: more than %d bytes are pending for the client", sm.budget))
+			sm.mu.Unlock()
+			return errStreamStopped
+		}
+		if waitStart.IsZero() {
+			waitStart = time.Now()
+		}
+		var idle <-chan time.Time
+		if sm.idleTimeout > 0 {
+			// The client is idle since it last read a result,
+			// but at most since the buffer is full: the tablets
+			// may take a while to return the first results.
+			idleSince := sm.lastSend
+			if idleSince.Before(waitStart) {
+				idleSince = waitStart
+			}
+			remaining := sm.idleTimeout - time.Since(idleSince)
+			if remaining <= 0 {
+				sm.abortLocked("IdleTimeout", fmt.Errorf("stream idle timeout: the client didn't read any result for %v", sm.idleTimeout))
+				sm.mu.Unlock()
+				return errStreamStopped
+			}
+			idle = time.After(remaining)
+		}
+		space := sm.space
+		sm.mu.Unlock()
+
+		select {
+		case <-space:
+		case <-idle:
+		case <-sm.ctx.Done():
+			return sm.ctx.Err()
+		}
+	}
+}
+
+// abortLocked fails the query because of a limit. mu must be held.
+func (sm *streamMerger) abortLocked(reason string, err error) {
+	streamAborts.Add(reason, 1)
+	sm.err = vterrors.FromError(vtrpcpb.ErrorCode_RESOURCE_EXHAUSTED, err)
+	sm.cancel()
+	sm.signal()
+}
+
+// stopped returns true if the query failed. The tablet streams
+// ignore their errors then, as they were cancelled.
+func (sm *streamMerger) stopped() bool {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	return sm.err != nil
+}
+
+// close must be called once all the tablet streams are done.
+func (sm *streamMerger) close() {
+	sm.mu.Lock()
+	sm.done = true
+	sm.mu.Unlock()
+	sm.signal()
+}
+
+func (sm *streamMerger) signal() {
+	select {
+	case sm.ready <- struct{}{}:
+	default:
+	}
+}
+
+// send sends the buffered results to the client until the tablet
+// streams are done, and returns the error of the query, if any.
+// If sendReply fails, the tablet streams are cancelled.
+func (sm *streamMerger) send(sendReply func(*sqltypes.Result) error) error {
+	defer sm.cancel()
+	for {
+		sm.mu.Lock()
+		if sm.err != nil {
+			err := sm.err
+			sm.releaseLocked()
+			sm.mu.Unlock()
+			return err
+		}
+		if len(sm.results) == 0 {
+			done := sm.done
+			sm.mu.Unlock()
+			if done {
+				return nil
+			}
+			<-sm.ready
+			continue
+		}
+		qr, size := sm.results[0], sm.sizes[0]
+		sm.results, sm.sizes = sm.results[1:], sm.sizes[1:]
+		sm.mu.Unlock()
+
+		err := sendReply(qr)
+
+		sm.mu.Lock()
+		sm.buffered -= size
+		sm.lastSend = time.Now()
+		close(sm.space)
+		sm.space = make(chan struct{})
+		if err != nil && sm.err == nil {
+			sm.err = err
+			sm.cancel()
+		}
+		sm.mu.Unlock()
+		streamBufferedBytes.Add(int64(-size))
+	}
+}
+
+// releaseLocked drops the buffered results. mu must be held.
+func (sm *streamMerger) releaseLocked() {
+	streamBufferedBytes.Add(int64(-sm.buffered))
+	sm.results, sm.sizes = nil, nil
+	sm.buffered = 0
+}
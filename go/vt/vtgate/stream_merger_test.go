@@ -0,0 +1,197 @@
+// Copyright 2016, Google Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vtgate
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/youtube/vitess/go/sqltypes"
+
+	querypb "github.com/youtube/vitess/go/vt/proto/query"
+)
+
+func newTestStreamMerger(budget int, policy string, idleTimeout time.Duration) *streamMerger {
+	sm := newStreamMerger(context.Background(), "ks")
+	sm.budget = budget
+	sm.policy = policy
+	sm.idleTimeout = idleTimeout
+	return sm
+}
+
+// rowResult returns a result with one row of the given size.
+func rowResult(size int) *sqltypes.Result {
+	return &sqltypes.Result{
+		Rows: [][]sqltypes.Value{{sqltypes.MakeString(make([]byte, size))}},
+	}
+}
+
+func TestStreamMergerFields(t *testing.T) {
+	sm := newTestStreamMerger(100, streamBufferPolicyBlock, 0)
+	fields := &sqltypes.Result{Fields: []*querypb.Field{{Name: "a"}}}
+	go func() {
+		for i := 0; i < 2; i++ {
+			if err := sm.add(fields); err != nil {
+				t.Errorf("add: %v", err)
+			}
+			if err := sm.add(rowResult(10)); err != nil {
+				t.Errorf("add: %v", err)
+			}
+		}
+		sm.close()
+	}()
+	var got []*sqltypes.Result
+	err := sm.send(func(qr *sqltypes.Result) error {
+		got = append(got, qr)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	want := []*sqltypes.Result{fields, rowResult(10), rowResult(10)}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("sent: %v, want %v", got, want)
+	}
+}
+
+func TestStreamMergerBlock(t *testing.T) {
+	sm := newTestStreamMerger(15, streamBufferPolicyBlock, 0)
+	if err := sm.add(rowResult(10)); err != nil {
+		t.Fatalf("add: %v", err)
+	}
+	added := make(chan error)
+	go func() {
+		added <- sm.add(rowResult(10))
+	}()
+	select {
+	case err := <-added:
+		t.Fatalf("add returned %v, want it to wait for the client", err)
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	// Reading a result makes room for the next one.
+	sent := make(chan *sqltypes.Result)
+	go func() {
+		if err := sm.send(func(qr *sqltypes.Result) error {
+			sent <- qr
+			return nil
+		}); err != nil {
+			t.Errorf("send: %v", err)
+		}
+		close(sent)
+	}()
+	<-sent
+	if err := <-added; err != nil {
+		t.Errorf("add: %v", err)
+	}
+	<-sent
+	sm.close()
+	if _, ok := <-sent; ok {
+		t.Errorf("send sent more results than added")
+	}
+}
+
+func TestStreamMergerAbort(t *testing.T) {
+	sm := newTestStreamMerger(15, streamBufferPolicyAbort, 0)
+	if err := sm.add(rowResult(10)); err != nil {
+		t.Fatalf("add: %v", err)
+	}
+	if err := sm.add(rowResult(10)); err != errStreamStopped {
+		t.Errorf("add: %v, want %v", err, errStreamStopped)
+	}
+	if err := sm.ctx.Err(); err != context.Canceled {
+		t.Errorf("tablet streams context: %v, want %v", err, context.Canceled)
+	}
+	err := sm.send(func(qr *sqltypes.Result) error {
+		t.Errorf("unexpected result sent after the abort")
+		return nil
+	})
+	want := "stream buffer full"
+	if err == nil || !strings.Contains(err.Error(), want) {
+		t.Errorf("send: %v, want %v", err, want)
+	}
+	if got := streamBufferedBytes.Get(); got != 0 {
+		t.Errorf("buffered bytes: %v, want 0", got)
+	}
+}
+
+func TestStreamMergerIdleTimeout(t *testing.T) {
+	sm := newTestStreamMerger(15, streamBufferPolicyBlock, 10*time.Millisecond)
+	if err := sm.add(rowResult(10)); err != nil {
+		t.Fatalf("add: %v", err)
+	}
+	// Nobody reads the results.
+	if err := sm.add(rowResult(10)); err != errStreamStopped {
+		t.Errorf("add: %v, want %v", err, errStreamStopped)
+	}
+	err := sm.send(func(qr *sqltypes.Result) error { return nil })
+	want := "stream idle timeout"
+	if err == nil || !strings.Contains(err.Error(), want) {
+		t.Errorf("send: %v, want %v", err, want)
+	}
+}
+
+func TestStreamMergerIdleTimeoutLateResult(t *testing.T) {
+	sm := newTestStreamMerger(15, streamBufferPolicyBlock, 50*time.Millisecond)
+	// The first result arrives after more than the idle timeout:
+	// the client was not idle, it was waiting for it.
+	time.Sleep(100 * time.Millisecond)
+	if err := sm.add(rowResult(10)); err != nil {
+		t.Fatalf("add: %v", err)
+	}
+	added := make(chan error)
+	go func() {
+		added <- sm.add(rowResult(10))
+	}()
+	select {
+	case err := <-added:
+		t.Fatalf("add returned %v, want it to wait for the client", err)
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	sent := make(chan *sqltypes.Result)
+	go func() {
+		if err := sm.send(func(qr *sqltypes.Result) error {
+			sent <- qr
+			return nil
+		}); err != nil {
+			t.Errorf("send: %v", err)
+		}
+		close(sent)
+	}()
+	<-sent
+	if err := <-added; err != nil {
+		t.Errorf("add: %v", err)
+	}
+	<-sent
+	sm.close()
+	if _, ok := <-sent; ok {
+		t.Errorf("send sent more results than added")
+	}
+}
+
+func TestStreamMergerSendError(t *testing.T) {
+	sm := newTestStreamMerger(15, streamBufferPolicyBlock, 0)
+	if err := sm.add(rowResult(10)); err != nil {
+		t.Fatalf("add: %v", err)
+	}
+	done := make(chan error)
+	go func() {
+		done <- sm.add(rowResult(10))
+	}()
+	err := sm.send(func(qr *sqltypes.Result) error { return fmt.Errorf("send error") })
+	if err == nil || err.Error() != "send error" {
+		t.Errorf("send: %v, want send error", err)
+	}
+	// The waiting tablet stream is released.
+	if err := <-done; err != errStreamStopped && err != context.Canceled {
+		t.Errorf("add: %v, want %v", err, errStreamStopped)
+	}
+}
@@ -2,6 +2,8 @@ package vindexes
 
 import (
 	"encoding/json"
+
+	"github.com/youtube/vitess/go/sqltypes"
 )
 
 func init() {
@@ -53,6 +55,16 @@ func (vindex *LookupNonUnique) Delete(vcursor VCursor, ids []interface{}, ksid [
 	return vindex.lkp.Delete(vcursor, ids, ksid)
 }
 
+// LookupColumns returns the lookup table, and its from and to columns.
+func (vindex *LookupNonUnique) LookupColumns() (string, string, string) {
+	return vindex.lkp.LookupColumns()
+}
+
+// KeyspaceID returns the keyspace id stored in a value of the to column.
+func (vindex *LookupNonUnique) KeyspaceID(to sqltypes.Value) ([]byte, error) {
+	return vindex.lkp.KeyspaceID(to)
+}
+
 // MarshalJSON returns a JSON representation of LookupHash.
 func (vindex *LookupNonUnique) MarshalJSON() ([]byte, error) {
 	return json.Marshal(vindex.lkp)
@@ -103,6 +115,16 @@ func (vindex *LookupUnique) Delete(vcursor VCursor, ids []interface{}, ksid []by
 	return vindex.lkp.Delete(vcursor, ids, ksid)
 }
 
+// LookupColumns returns the lookup table, and its from and to columns.
+func (vindex *LookupUnique) LookupColumns() (string, string, string) {
+	return vindex.lkp.LookupColumns()
+}
+
+// KeyspaceID returns the keyspace id stored in a value of the to column.
+func (vindex *LookupUnique) KeyspaceID(to sqltypes.Value) ([]byte, error) {
+	return vindex.lkp.KeyspaceID(to)
+}
+
 // MarshalJSON returns a JSON representation of LookupHashUnique.
 func (vindex *LookupUnique) MarshalJSON() ([]byte, error) {
 	return json.Marshal(vindex.lkp)
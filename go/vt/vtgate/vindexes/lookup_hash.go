@@ -6,6 +6,8 @@ package vindexes
 
 import (
 	"encoding/json"
+
+	"github.com/youtube/vitess/go/sqltypes"
 )
 
 func init() {
@@ -60,6 +62,16 @@ func (vind *LookupHash) Delete(vcursor VCursor, ids []interface{}, ksid []byte)
 	return vind.lkp.Delete(vcursor, ids, ksid)
 }
 
+// LookupColumns returns the lookup table, and its from and to columns.
+func (vind *LookupHash) LookupColumns() (string, string, string) {
+	return vind.lkp.LookupColumns()
+}
+
+// KeyspaceID returns the keyspace id stored in a value of the to column.
+func (vind *LookupHash) KeyspaceID(to sqltypes.Value) ([]byte, error) {
+	return vind.lkp.KeyspaceID(to)
+}
+
 // MarshalJSON returns a JSON representation of LookupHash.
 func (vind *LookupHash) MarshalJSON() ([]byte, error) {
 	return json.Marshal(vind.lkp)
@@ -112,6 +124,16 @@ func (vind *LookupHashUnique) Delete(vcursor VCursor, ids []interface{}, ksid []
 	return vind.lkp.Delete(vcursor, ids, ksid)
 }
 
+// LookupColumns returns the lookup table, and its from and to columns.
+func (vind *LookupHashUnique) LookupColumns() (string, string, string) {
+	return vind.lkp.LookupColumns()
+}
+
+// KeyspaceID returns the keyspace id stored in a value of the to column.
+func (vind *LookupHashUnique) KeyspaceID(to sqltypes.Value) ([]byte, error) {
+	return vind.lkp.KeyspaceID(to)
+}
+
 // MarshalJSON returns a JSON representation of LookupHashUnique.
 func (vind *LookupHashUnique) MarshalJSON() ([]byte, error) {
 	return json.Marshal(vind.lkp)
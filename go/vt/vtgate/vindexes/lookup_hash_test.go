@@ -143,3 +143,21 @@ func TestLookupHashDelete(t *testing.T) {
 		t.Errorf("vc.query = %#v, want %#v", vc.bq, wantQuery)
 	}
 }
+
+func TestLookupHashLookupTable(t *testing.T) {
+	lt := lhm.(LookupTable)
+	table, from, to := lt.LookupColumns()
+	if table != "t" || from != "fromc" || to != "toc" {
+		t.Errorf("LookupColumns(): %v, %v, %v, want t, fromc, toc", table, from, to)
+	}
+	got, err := lt.KeyspaceID(sqltypes.MakeTrusted(sqltypes.Int64, []byte("1")))
+	if err != nil {
+		t.Error(err)
+	}
+	if want := []byte("\x16k@\xb4J\xbaK\xd6"); !reflect.DeepEqual(got, want) {
+		t.Errorf("KeyspaceID(): %#v, want %#v", got, want)
+	}
+	if _, err := lt.KeyspaceID(sqltypes.MakeTrusted(sqltypes.VarChar, []byte("a"))); err == nil {
+		t.Errorf("KeyspaceID(a) worked")
+	}
+}
@@ -2,6 +2,8 @@ package vindexes
 
 import (
 	"fmt"
+
+	"github.com/youtube/vitess/go/sqltypes"
 )
 
 // lookup implements the functions for the Lookup vindexes.
@@ -156,3 +158,20 @@ func (lkp *lookup) Delete(vcursor VCursor, ids []interface{}, ksid []byte) error
 	}
 	return nil
 }
+
+// LookupColumns returns the table, and its from and to columns.
+func (lkp *lookup) LookupColumns() (string, string, string) {
+	return lkp.Table, lkp.From, lkp.To
+}
+
+// KeyspaceID returns the keyspace id stored in a value of the to column.
+func (lkp *lookup) KeyspaceID(to sqltypes.Value) ([]byte, error) {
+	if lkp.isHashedIndex {
+		num, err := getNumber(to.ToNative())
+		if err != nil {
+			return nil, fmt.Errorf("lookup.KeyspaceID: %v", err)
+		}
+		return vhash(num), nil
+	}
+	return to.Raw(), nil
+}
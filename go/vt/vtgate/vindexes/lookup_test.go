@@ -4,6 +4,7 @@ import (
 	"reflect"
 	"testing"
 
+	"github.com/youtube/vitess/go/sqltypes"
 	"github.com/youtube/vitess/go/vt/tabletserver/querytypes"
 )
 
@@ -85,3 +86,14 @@ func TestLookupUniqueDelete(t *testing.T) {
 		t.Errorf("vc.query = %#v, want %#v", vc.bq, wantQuery)
 	}
 }
+
+func TestLookupUniqueLookupTable(t *testing.T) {
+	lt := lookupUnique.(LookupTable)
+	got, err := lt.KeyspaceID(sqltypes.MakeTrusted(sqltypes.VarBinary, []byte("test")))
+	if err != nil {
+		t.Error(err)
+	}
+	if want := []byte("test"); !reflect.DeepEqual(got, want) {
+		t.Errorf("KeyspaceID(): %#v, want %#v", got, want)
+	}
+}
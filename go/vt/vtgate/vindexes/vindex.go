@@ -90,6 +90,19 @@ type Lookup interface {
 	Delete(VCursor, []interface{}, []byte) error
 }
 
+// A LookupTable is a Lookup vindex that stores its map in a
+// table. It lets tools scan the whole table, e.g. to backfill
+// it or to check it against the owner table.
+type LookupTable interface {
+	Lookup
+	// LookupColumns returns the name of the table, and of its
+	// from and to columns.
+	LookupColumns() (table, from, to string)
+	// KeyspaceID returns the keyspace id stored in a value of
+	// the to column.
+	KeyspaceID(to sqltypes.Value) ([]byte, error)
+}
+
 // A NewVindexFunc is a function that creates a Vindex based on the
 // properties specified in the input map. Every vindex must
 // register a NewVindexFunc under a unique vindexType.
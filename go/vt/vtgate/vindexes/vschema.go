@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"io/ioutil"
 	"sort"
+	"strings"
 
 	"github.com/youtube/vitess/go/cistring"
 	vschemapb "github.com/youtube/vitess/go/vt/proto/vschema"
@@ -19,6 +20,18 @@ import (
 type VSchema struct {
 	tables    map[string]*Table
 	Keyspaces map[string]*KeyspaceSchema `json:"keyspaces"`
+	// routingRules maps the table references ("table" or
+	// "keyspace.table") to the tables they're redirected to.
+	// It is nil if there are no routing rules.
+	routingRules map[string]*routingRule
+}
+
+// routingRule is the resolved version of a vschemapb.RoutingRule.
+// If the target table cannot be resolved, err is set, and returned
+// to the queries that reference the table.
+type routingRule struct {
+	table *Table
+	err   error
 }
 
 // Table represents a table in VSchema.
@@ -30,6 +43,10 @@ type Table struct {
 	Ordered        []*ColumnVindex `json:"ordered,omitempty"`
 	Owned          []*ColumnVindex `json:"owned,omitempty"`
 	AutoIncrement  *AutoIncrement  `json:"auto_increment,omitempty"`
+	// Columns is the list of columns of the table, if known.
+	// It's not part of the vschema: vtgate fills it in from the
+	// schema of the tablets, if schema tracking is enabled.
+	Columns []cistring.CIString `json:"columns,omitempty"`
 }
 
 // Keyspace contains the keyspcae info for each Table.
@@ -88,6 +105,7 @@ func BuildVSchema(source *vschemapb.SrvVSchema) (vschema *VSchema, err error) {
 	if err != nil {
 		return nil, err
 	}
+	buildRoutingRules(source, vschema)
 	return vschema, nil
 }
 
@@ -122,6 +140,42 @@ func ValidateKeyspace(input *vschemapb.Keyspace) error {
 	return err
 }
 
+// ValidateRoutingRules ensures that the routing rules are well formed.
+// The target tables are not resolved, as the rules are also used to
+// redirect references to tables which are not in the vschema yet.
+func ValidateRoutingRules(rules *vschemapb.RoutingRules) error {
+	seen := make(map[string]bool)
+	for _, rule := range rules.GetRules() {
+		if _, table := splitQualifiedTableName(rule.FromTable); table == "" {
+			return fmt.Errorf("invalid from_table in routing rule: %q", rule.FromTable)
+		}
+		if seen[rule.FromTable] {
+			return fmt.Errorf("duplicate routing rule for table %s", rule.FromTable)
+		}
+		seen[rule.FromTable] = true
+		if keyspace, table := splitQualifiedTableName(rule.ToTable); keyspace == "" || table == "" {
+			return fmt.Errorf("to_table must be qualified with a keyspace in routing rule for table %s: %q", rule.FromTable, rule.ToTable)
+		}
+	}
+	return nil
+}
+
+// splitQualifiedTableName splits "keyspace.table" into its keyspace
+// and table parts. The keyspace is empty for "table".
+func splitQualifiedTableName(name string) (keyspace, table string) {
+	parts := strings.Split(name, ".")
+	switch len(parts) {
+	case 1:
+		return "", parts[0]
+	case 2:
+		if parts[0] == "" {
+			return "", ""
+		}
+		return parts[0], parts[1]
+	}
+	return "", ""
+}
+
 func buildKeyspaces(source *vschemapb.SrvVSchema, vschema *VSchema) {
 	for ksname, ks := range source.Keyspaces {
 		vschema.Keyspaces[ksname] = &KeyspaceSchema{
@@ -231,6 +285,25 @@ func resolveAutoIncrement(source *vschemapb.SrvVSchema, vschema *VSchema) error
 	return nil
 }
 
+// buildRoutingRules resolves the routing rules of the SrvVSchema.
+// Rules that cannot be resolved are kept with their error, so that
+// only the queries referencing them fail.
+func buildRoutingRules(source *vschemapb.SrvVSchema, vschema *VSchema) {
+	for _, rule := range source.GetRoutingRules().GetRules() {
+		if vschema.routingRules == nil {
+			vschema.routingRules = make(map[string]*routingRule)
+		}
+		rr := &routingRule{}
+		vschema.routingRules[rule.FromTable] = rr
+		keyspace, tablename := splitQualifiedTableName(rule.ToTable)
+		if keyspace == "" || tablename == "" {
+			rr.err = fmt.Errorf("invalid routing rule for table %s: to_table %q must be qualified with a keyspace", rule.FromTable, rule.ToTable)
+			continue
+		}
+		rr.table, rr.err = vschema.findTable(keyspace, tablename)
+	}
+}
+
 // Find returns a pointer to the Table. If a keyspace is specified, only tables
 // from that keyspace are searched. If the specified keyspace is unsharded
 // and no tables matched, it's considered valid: Find will construct a table
@@ -238,7 +311,18 @@ func resolveAutoIncrement(source *vschemapb.SrvVSchema, vschema *VSchema) error
 // only if its name is unique across all keyspaces. If there is only one
 // keyspace in the vschema, and it's unsharded, then all table requests are considered
 // valid and belonging to that keyspace.
+// Routing rules take precedence: if a rule matches the table, as
+// qualified in the query, the table of the rule is returned.
 func (vschema *VSchema) Find(keyspace, tablename string) (table *Table, err error) {
+	if vschema.routingRules != nil {
+		name := tablename
+		if keyspace != "" {
+			name = keyspace + "." + tablename
+		}
+		if rr, ok := vschema.routingRules[name]; ok {
+			return rr.table, rr.err
+		}
+	}
 	if keyspace == "" {
 		table, ok := vschema.tables[tablename]
 		if table == nil {
@@ -258,11 +342,17 @@ func (vschema *VSchema) Find(keyspace, tablename string) (table *Table, err erro
 		}
 		return table, nil
 	}
+	return vschema.findTable(keyspace, tablename)
+}
+
+// findTable returns the table of a keyspace. Tables of unsharded
+// keyspaces don't have to be listed in the vschema.
+func (vschema *VSchema) findTable(keyspace, tablename string) (*Table, error) {
 	ks, ok := vschema.Keyspaces[keyspace]
 	if !ok {
 		return nil, fmt.Errorf("keyspace %s not found in vschema", keyspace)
 	}
-	table = ks.Tables[tablename]
+	table := ks.Tables[tablename]
 	if table == nil {
 		if ks.Keyspace.Sharded {
 			return nil, fmt.Errorf("table %s not found", tablename)
@@ -816,6 +816,85 @@ func TestFind(t *testing.T) {
 	}
 }
 
+func TestFindRoutingRules(t *testing.T) {
+	input := vschemapb.SrvVSchema{
+		Keyspaces: map[string]*vschemapb.Keyspace{
+			"ksa": {
+				Tables: map[string]*vschemapb.Table{
+					"t1": {},
+				},
+			},
+			"ksb": {
+				Tables: map[string]*vschemapb.Table{
+					"t1": {},
+				},
+			},
+		},
+		RoutingRules: &vschemapb.RoutingRules{
+			Rules: []*vschemapb.RoutingRule{
+				{FromTable: "t1", ToTable: "ksb.t1"},
+				{FromTable: "ksa.t1", ToTable: "ksb.t1"},
+				{FromTable: "t2", ToTable: "none.t2"},
+			},
+		},
+	}
+	vschema, err := BuildVSchema(&input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t1 := vschema.Keyspaces["ksb"].Tables["t1"]
+	for _, keyspace := range []string{"", "ksa", "ksb"} {
+		got, err := vschema.Find(keyspace, "t1")
+		if err != nil || got != t1 {
+			t.Errorf("Find(%q, \"t1\"): %+v, %v, want %+v", keyspace, got, err, t1)
+		}
+	}
+	// Rules that cannot be resolved only fail the matching queries.
+	_, err = vschema.Find("", "t2")
+	wantErr := "keyspace none not found in vschema"
+	if err == nil || err.Error() != wantErr {
+		t.Errorf("Find(\"\", \"t2\"): %v, want %s", err, wantErr)
+	}
+	got, err := vschema.Find("ksa", "t2")
+	if err != nil || got.Keyspace.Name != "ksa" {
+		t.Errorf("Find(\"ksa\", \"t2\"): %+v, %v, want a table in ksa", got, err)
+	}
+}
+
+func TestValidateRoutingRules(t *testing.T) {
+	good := &vschemapb.RoutingRules{
+		Rules: []*vschemapb.RoutingRule{
+			{FromTable: "t1", ToTable: "ksb.t1"},
+			{FromTable: "ksa.t1", ToTable: "ksb.t1"},
+		},
+	}
+	if err := ValidateRoutingRules(good); err != nil {
+		t.Error(err)
+	}
+	testcases := []struct {
+		rule *vschemapb.RoutingRule
+		err  string
+	}{{
+		rule: &vschemapb.RoutingRule{FromTable: "a.b.c", ToTable: "ksb.t1"},
+		err:  "invalid from_table in routing rule: \"a.b.c\"",
+	}, {
+		rule: &vschemapb.RoutingRule{FromTable: "t1", ToTable: "ksc.t1"},
+		err:  "duplicate routing rule for table t1",
+	}, {
+		rule: &vschemapb.RoutingRule{FromTable: "t2", ToTable: "t2"},
+		err:  "to_table must be qualified with a keyspace in routing rule for table t2: \"t2\"",
+	}}
+	for _, tcase := range testcases {
+		bad := &vschemapb.RoutingRules{
+			Rules: append([]*vschemapb.RoutingRule{good.Rules[0]}, tcase.rule),
+		}
+		err := ValidateRoutingRules(bad)
+		if err == nil || err.Error() != tcase.err {
+			t.Errorf("ValidateRoutingRules(%v): %v, want %s", tcase.rule, err, tcase.err)
+		}
+	}
+}
+
 func TestBuildKeyspaceSchema(t *testing.T) {
 	good := &vschemapb.Keyspace{
 		Tables: map[string]*vschemapb.Table{
@@ -116,6 +116,9 @@ func Init(ctx context.Context, hc discovery.HealthCheck, topoServer topo.Server,
 	}
 	// Resuse resolver's scatterConn.
 	rpcVTGate.router = NewRouter(ctx, serv, cell, "VTGateRouter", rpcVTGate.resolver.scatterConn)
+	if *enableSchemaTracking {
+		rpcVTGate.router.planner.TrackSchema(rpcVTGate.resolver.scatterConn.gateway)
+	}
 	normalErrors = stats.NewMultiCounters("VtgateApiErrorCounts", []string{"Operation", "Keyspace", "DbType"})
 	infoErrors = stats.NewCounters("VtgateInfoErrorCounts")
 	internalErrors = stats.NewCounters("VtgateInternalErrorCounts")
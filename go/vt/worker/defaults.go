@@ -4,7 +4,11 @@
 
 package worker
 
-import "github.com/youtube/vitess/go/vt/throttler"
+import (
+	"time"
+
+	"github.com/youtube/vitess/go/vt/throttler"
+)
 
 const (
 	defaultOnline  = true
@@ -39,4 +43,7 @@ const (
 	defaultMinHealthyRdonlyTablets = 2
 	defaultMaxTPS                  = throttler.MaxRateModuleDisabled
 	defaultMaxReplicationLag       = throttler.ReplicationLagModuleDisabled
+	// defaultVtgateConnectTimeout is used by the commands which send
+	// the queries of the lookup vindexes to vtgate.
+	defaultVtgateConnectTimeout = 30 * time.Second
 )
@@ -212,45 +212,123 @@ func TableScanByKeyRange(ctx context.Context, log logutil.Logger, ts topo.Server
 	}
 
 	// in v2 mode, we can do the filtering at the source
-	where := ""
+	where, err := keyRangeWhereClause(keyRange, shardingColumnName, shardingColumnType)
+	if err != nil {
+		return nil, err
+	}
+	if where != "" {
+		where = "WHERE " + where + " "
+	}
+
+	sql := fmt.Sprintf("SELECT %v FROM %v %vORDER BY %v", strings.Join(escapeAll(orderedColumns(tableDefinition)), ", "), escape(tableDefinition.Name), where, strings.Join(escapeAll(tableDefinition.PrimaryKeyColumns), ", "))
+	log.Infof("SQL query for %v/%v: %v", topoproto.TabletAliasString(tabletAlias), tableDefinition.Name, sql)
+	return NewQueryResultReaderForTablet(ctx, ts, tabletAlias, sql)
+}
+
+// keyRangeWhereClause returns the condition which matches the rows
+// within the KeyRange, or "" if the KeyRange covers everything.
+func keyRangeWhereClause(keyRange *topodatapb.KeyRange, shardingColumnName string, shardingColumnType topodatapb.KeyspaceIdType) (string, error) {
 	switch shardingColumnType {
 	case topodatapb.KeyspaceIdType_UINT64:
 		if len(keyRange.Start) > 0 {
 			if len(keyRange.End) > 0 {
 				// have start & end
-				where = fmt.Sprintf("WHERE %v >= %v AND %v < %v ", escape(shardingColumnName), uint64FromKeyspaceID(keyRange.Start), escape(shardingColumnName), uint64FromKeyspaceID(keyRange.End))
-			} else {
-				// have start only
-				where = fmt.Sprintf("WHERE %v >= %v ", escape(shardingColumnName), uint64FromKeyspaceID(keyRange.Start))
-			}
-		} else {
-			if len(keyRange.End) > 0 {
-				// have end only
-				where = fmt.Sprintf("WHERE %v < %v ", escape(shardingColumnName), uint64FromKeyspaceID(keyRange.End))
+				return fmt.Sprintf("%v >= %v AND %v < %v", escape(shardingColumnName), uint64FromKeyspaceID(keyRange.Start), escape(shardingColumnName), uint64FromKeyspaceID(keyRange.End)), nil
 			}
+			// have start only
+			return fmt.Sprintf("%v >= %v", escape(shardingColumnName), uint64FromKeyspaceID(keyRange.Start)), nil
+		}
+		if len(keyRange.End) > 0 {
+			// have end only
+			return fmt.Sprintf("%v < %v", escape(shardingColumnName), uint64FromKeyspaceID(keyRange.End)), nil
 		}
 	case topodatapb.KeyspaceIdType_BYTES:
 		if len(keyRange.Start) > 0 {
 			if len(keyRange.End) > 0 {
 				// have start & end
-				where = fmt.Sprintf("WHERE HEX(%v) >= '%v' AND HEX(%v) < '%v' ", escape(shardingColumnName), hex.EncodeToString(keyRange.Start), escape(shardingColumnName), hex.EncodeToString(keyRange.End))
-			} else {
-				// have start only
-				where = fmt.Sprintf("WHERE HEX(%v) >= '%v' ", escape(shardingColumnName), hex.EncodeToString(keyRange.Start))
+				return fmt.Sprintf("HEX(%v) >= '%v' AND HEX(%v) < '%v'", escape(shardingColumnName), hex.EncodeToString(keyRange.Start), escape(shardingColumnName), hex.EncodeToString(keyRange.End)), nil
+			}
+			// have start only
+			return fmt.Sprintf("HEX(%v) >= '%v'", escape(shardingColumnName), hex.EncodeToString(keyRange.Start)), nil
+		}
+		if len(keyRange.End) > 0 {
+			// have end only
+			return fmt.Sprintf("HEX(%v) < '%v'", escape(shardingColumnName), hex.EncodeToString(keyRange.End)), nil
+		}
+	default:
+		return "", fmt.Errorf("Unsupported ShardingColumnType: %v", shardingColumnType)
+	}
+	return "", nil
+}
+
+// chunkWhereClauses returns the conditions which restrict a query to the
+// rows of the chunk. It returns no conditions for a complete chunk.
+func chunkWhereClauses(td *tabletmanagerdatapb.TableDefinition, chunk chunk) []string {
+	var clauses []string
+	if !chunk.start.IsNull() {
+		var b bytes.Buffer
+		writeEscaped(&b, td.PrimaryKeyColumns[0])
+		b.WriteString(">=")
+		chunk.start.EncodeSQL(&b)
+		clauses = append(clauses, b.String())
+	}
+	if !chunk.end.IsNull() {
+		var b bytes.Buffer
+		writeEscaped(&b, td.PrimaryKeyColumns[0])
+		b.WriteString("<")
+		chunk.end.EncodeSQL(&b)
+		clauses = append(clauses, b.String())
+	}
+	return clauses
+}
+
+// tableScanChunk returns a QueryResultReader that gets the rows of a
+// chunk of a table, ordered by Primary Key. The returned columns are
+// ordered with the Primary Key columns in front.
+// If keyRange is set, only the rows within the KeyRange are returned,
+// filtered the same way as TableScanByKeyRange does.
+func tableScanChunk(ctx context.Context, log logutil.Logger, ts topo.Server, tabletAlias *topodatapb.TabletAlias, tableDefinition *tabletmanagerdatapb.TableDefinition, chunk chunk, keyRange *topodatapb.KeyRange, keyspaceSchema *vindexes.KeyspaceSchema, shardingColumnName string, shardingColumnType topodatapb.KeyspaceIdType) (*QueryResultReader, error) {
+	clauses := chunkWhereClauses(tableDefinition, chunk)
+
+	var keyResolver keyspaceIDResolver
+	if keyRange != nil {
+		if keyspaceSchema != nil {
+			// In v3 mode, we filter here.
+			var err error
+			keyResolver, err = newV3ResolverFromColumnList(keyspaceSchema, tableDefinition.Name, orderedColumns(tableDefinition))
+			if err != nil {
+				return nil, fmt.Errorf("cannot resolve v3 sharding keys for table %v: %v", tableDefinition.Name, err)
 			}
 		} else {
-			if len(keyRange.End) > 0 {
-				// have end only
-				where = fmt.Sprintf("WHERE HEX(%v) < '%v' ", escape(shardingColumnName), hex.EncodeToString(keyRange.End))
+			// In v2 mode, we filter at the source.
+			clause, err := keyRangeWhereClause(keyRange, shardingColumnName, shardingColumnType)
+			if err != nil {
+				return nil, err
+			}
+			if clause != "" {
+				clauses = append(clauses, clause)
 			}
 		}
-	default:
-		return nil, fmt.Errorf("Unsupported ShardingColumnType: %v", shardingColumnType)
 	}
 
+	where := ""
+	if len(clauses) > 0 {
+		where = "WHERE " + strings.Join(clauses, " AND ") + " "
+	}
 	sql := fmt.Sprintf("SELECT %v FROM %v %vORDER BY %v", strings.Join(escapeAll(orderedColumns(tableDefinition)), ", "), escape(tableDefinition.Name), where, strings.Join(escapeAll(tableDefinition.PrimaryKeyColumns), ", "))
-	log.Infof("SQL query for %v/%v: %v", topoproto.TabletAliasString(tabletAlias), tableDefinition.Name, sql)
-	return NewQueryResultReaderForTablet(ctx, ts, tabletAlias, sql)
+	log.Infof("SQL query for %v/%v chunk %v: %v", topoproto.TabletAliasString(tabletAlias), tableDefinition.Name, chunk, sql)
+	scan, err := NewQueryResultReaderForTablet(ctx, ts, tabletAlias, sql)
+	if err != nil {
+		return nil, err
+	}
+	if keyResolver != nil {
+		scan.output = &v3KeyRangeFilter{
+			input:    scan.output,
+			resolver: keyResolver.(*v3Resolver),
+			keyRange: keyRange,
+		}
+	}
+	return scan, nil
 }
 
 // ErrStoppedRowReader is returned by RowReader.Next() when
@@ -423,7 +501,7 @@ type RowDiffer struct {
 }
 
 // NewRowDiffer returns a new RowDiffer
-func NewRowDiffer(left, right *QueryResultReader, tableDefinition *tabletmanagerdatapb.TableDefinition) (*RowDiffer, error) {
+func NewRowDiffer(left, right ResultReader, tableDefinition *tabletmanagerdatapb.TableDefinition) (*RowDiffer, error) {
 	leftFields := left.Fields()
 	rightFields := right.Fields()
 	if len(leftFields) != len(rightFields) {
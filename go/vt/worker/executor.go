@@ -68,6 +68,35 @@ func (e *executor) fetchLoop(ctx context.Context, insertChannel chan string) err
 	}
 }
 
+// writeQuery is a write query for a destination shard. If writes is set, it
+// is notified once the query was executed.
+type writeQuery struct {
+	query  string
+	writes *chunkWrites
+}
+
+// writeLoop is like fetchLoop but notifies the chunkWrites of each query after
+// the query was executed.
+func (e *executor) writeLoop(ctx context.Context, insertChannel chan writeQuery) error {
+	for {
+		select {
+		case q, ok := <-insertChannel:
+			if !ok {
+				// no more to read, we're done
+				return nil
+			}
+			if err := e.fetchWithRetries(ctx, q.query); err != nil {
+				return fmt.Errorf("ExecuteFetch failed: %v", err)
+			}
+			if q.writes != nil {
+				q.writes.done()
+			}
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
 // fetchWithRetries will attempt to run ExecuteFetch for a single command, with
 // a reasonably small timeout.
 // If will keep retrying the ExecuteFetch (for a finite but longer duration) if
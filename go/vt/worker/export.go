@@ -0,0 +1,673 @@
+// Copyright 2016, Google Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package worker
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/youtube/vitess/go/sqltypes"
+	"github.com/youtube/vitess/go/sync2"
+	"github.com/youtube/vitess/go/vt/concurrency"
+	"github.com/youtube/vitess/go/vt/mysqlctl/backupstorage"
+	"github.com/youtube/vitess/go/vt/mysqlctl/tmutils"
+	"github.com/youtube/vitess/go/vt/topo"
+	"github.com/youtube/vitess/go/vt/topo/topoproto"
+	"github.com/youtube/vitess/go/vt/wrangler"
+
+	querypb "github.com/youtube/vitess/go/vt/proto/query"
+	tabletmanagerdatapb "github.com/youtube/vitess/go/vt/proto/tabletmanagerdata"
+	topodatapb "github.com/youtube/vitess/go/vt/proto/topodata"
+)
+
+const (
+	// ExportFormatCSV writes one CSV file per chunk, with a header row.
+	// NULL values are written as ExportCSVNull.
+	ExportFormatCSV = "csv"
+	// ExportFormatJSON writes one newline-delimited JSON file per chunk,
+	// with one object per row. Binary columns are base64 encoded.
+	ExportFormatJSON = "json"
+
+	// ExportCSVNull is how NULL values are written in CSV files.
+	// It is the representation LOAD DATA INFILE understands. Values
+	// made of backslashes followed by N are written with one more
+	// backslash, so they can't be mistaken for NULL.
+	ExportCSVNull = `\N`
+
+	// exportManifestFile is the name of the manifest of an export.
+	exportManifestFile = "MANIFEST"
+)
+
+// ExportDir returns the BackupStorage directory of the exports of a
+// keyspace.
+func ExportDir(keyspace string) string {
+	return path.Join("exports", keyspace)
+}
+
+// ExportManifest describes an export. It is written as JSON in the
+// MANIFEST file, after all the data files were written: an export
+// without a MANIFEST is incomplete.
+type ExportManifest struct {
+	Keyspace string
+	Format   string
+	// StartTime and EndTime are in RFC3339 format, in UTC.
+	StartTime string
+	EndTime   string
+	Shards    []*ExportShard
+	Tables    []*ExportTable
+}
+
+// ExportShard describes the snapshot a shard was exported from.
+type ExportShard struct {
+	Shard string
+	// TabletAlias is the rdonly tablet the data was read from.
+	TabletAlias string
+	// Position is the replication position the tablet was stopped at.
+	Position string
+}
+
+// ExportTable lists the files of an exported table.
+type ExportTable struct {
+	Name    string
+	Columns []*ExportColumn
+	Files   []*ExportFile
+}
+
+// ExportColumn describes a column of an exported table, in file order.
+type ExportColumn struct {
+	Name string
+	Type string
+}
+
+// ExportFile is one chunk of a table, read from one shard.
+type ExportFile struct {
+	Name     string
+	Shard    string
+	Chunk    int
+	RowCount uint64
+}
+
+// ExportConfig contains the parameters of an Export run.
+type ExportConfig struct {
+	// Name is the name of the export in the BackupStorage, in the
+	// ExportDir of the keyspace. It defaults to the start time.
+	Name string
+	// Tables and ExcludeTables filter the exported tables, as for
+	// GetSchema.
+	Tables        []string
+	ExcludeTables []string
+	// Format is ExportFormatCSV or ExportFormatJSON.
+	Format string
+	// ChunkCount and MinRowsPerChunk control how the tables are split
+	// into chunks, as for SplitClone.
+	ChunkCount      int
+	MinRowsPerChunk int
+	// Concurrency is the number of chunks exported in parallel.
+	Concurrency             int
+	MinHealthyRdonlyTablets int
+}
+
+// ExportWorker writes a snapshot of a keyspace to files in the
+// BackupStorage. In each shard, an rdonly tablet is taken out of
+// serving and its replication is stopped, so all the tables of a shard
+// are exported at the same, known position.
+type ExportWorker struct {
+	StatusWorker
+
+	wr       *wrangler.Wrangler
+	cell     string
+	keyspace string
+	config   ExportConfig
+	cleaner  *wrangler.Cleaner
+
+	// populated during WorkerStateInit, read-only after that
+	shards []*topo.ShardInfo
+
+	// populated during WorkerStateFindTargets, read-only after that
+	// aliases, tablets and schemas have one entry for each shard.
+	aliases  []*topodatapb.TabletAlias
+	tablets  []*topodatapb.Tablet
+	schemas  []*tabletmanagerdatapb.SchemaDefinition
+	manifest *ExportManifest
+
+	// populated during WorkerStateCloneOffline
+	rowsExported  sync2.AtomicInt64
+	filesExported sync2.AtomicInt64
+}
+
+// NewExportWorker returns a new ExportWorker object.
+func NewExportWorker(wr *wrangler.Wrangler, cell, keyspace string, config ExportConfig) (Worker, error) {
+	if config.Format != ExportFormatCSV && config.Format != ExportFormatJSON {
+		return nil, fmt.Errorf("format must be %v or %v, got %q", ExportFormatCSV, ExportFormatJSON, config.Format)
+	}
+	if config.ChunkCount < 1 {
+		return nil, fmt.Errorf("chunk_count must be at least 1, got %v", config.ChunkCount)
+	}
+	if config.Concurrency < 1 {
+		return nil, fmt.Errorf("concurrency must be at least 1, got %v", config.Concurrency)
+	}
+	if strings.ContainsAny(config.Name, "/") {
+		return nil, fmt.Errorf("invalid export name %q", config.Name)
+	}
+	return &ExportWorker{
+		StatusWorker: NewStatusWorker(),
+		wr:           wr,
+		cell:         cell,
+		keyspace:     keyspace,
+		config:       config,
+		cleaner:      &wrangler.Cleaner{},
+	}, nil
+}
+
+// StatusAsHTML implements the Worker interface.
+func (ew *ExportWorker) StatusAsHTML() template.HTML {
+	state := ew.State()
+
+	result := "<b>Exporting:</b> " + ew.keyspace + "</br>\n"
+	result += "<b>State:</b> " + state.String() + "</br>\n"
+	switch state {
+	case WorkerStateCloneOffline:
+		result += fmt.Sprintf("<b>Exported:</b> %v rows in %v files</br>\n", ew.rowsExported.Get(), ew.filesExported.Get())
+	case WorkerStateDone:
+		result += fmt.Sprintf("<b>Success:</b> %v rows in %v files</br>\n", ew.rowsExported.Get(), ew.filesExported.Get())
+	}
+	return template.HTML(result)
+}
+
+// StatusAsText implements the Worker interface.
+func (ew *ExportWorker) StatusAsText() string {
+	state := ew.State()
+
+	result := "Exporting: " + ew.keyspace + "\n"
+	result += "State: " + state.String() + "\n"
+	switch state {
+	case WorkerStateCloneOffline:
+		result += fmt.Sprintf("Exported: %v rows in %v files\n", ew.rowsExported.Get(), ew.filesExported.Get())
+	case WorkerStateDone:
+		result += fmt.Sprintf("Success: %v rows in %v files\n", ew.rowsExported.Get(), ew.filesExported.Get())
+	}
+	return result
+}
+
+// Run is mostly a wrapper to run the cleanup at the end.
+func (ew *ExportWorker) Run(ctx context.Context) error {
+	resetVars()
+	err := ew.run(ctx)
+
+	ew.SetState(WorkerStateCleanUp)
+	cerr := ew.cleaner.CleanUp(ew.wr)
+	if cerr != nil {
+		if err != nil {
+			ew.wr.Logger().Errorf("CleanUp failed in addition to job error: %v", cerr)
+		} else {
+			err = cerr
+		}
+	}
+	if err != nil {
+		ew.SetState(WorkerStateError)
+		return err
+	}
+	ew.SetState(WorkerStateDone)
+	return nil
+}
+
+func (ew *ExportWorker) run(ctx context.Context) error {
+	// first state: read what we need to do
+	if err := ew.init(ctx); err != nil {
+		return fmt.Errorf("init() failed: %v", err)
+	}
+	if err := checkDone(ctx); err != nil {
+		return err
+	}
+
+	// second state: find and stop the source tablets
+	if err := ew.findTargets(ctx); err != nil {
+		return fmt.Errorf("findTargets() failed: %v", err)
+	}
+	if err := checkDone(ctx); err != nil {
+		return err
+	}
+
+	// third state: write the files
+	if err := ew.export(ctx); err != nil {
+		return fmt.Errorf("export() failed: %v", err)
+	}
+	return nil
+}
+
+// init phase:
+// - read the shards of the keyspace
+func (ew *ExportWorker) init(ctx context.Context) error {
+	ew.SetState(WorkerStateInit)
+
+	shortCtx, cancel := context.WithTimeout(ctx, *remoteActionsTimeout)
+	shardMap, err := ew.wr.TopoServer().FindAllShardsInKeyspace(shortCtx, ew.keyspace)
+	cancel()
+	if err != nil {
+		return fmt.Errorf("cannot read shards of keyspace %v: %v", ew.keyspace, err)
+	}
+	if len(shardMap) == 0 {
+		return fmt.Errorf("keyspace %v has no shards", ew.keyspace)
+	}
+	shardNames := make([]string, 0, len(shardMap))
+	for shardName := range shardMap {
+		shardNames = append(shardNames, shardName)
+	}
+	sort.Strings(shardNames)
+	ew.shards = make([]*topo.ShardInfo, len(shardNames))
+	for i, shardName := range shardNames {
+		ew.shards[i] = shardMap[shardName]
+	}
+	return nil
+}
+
+// findTargets phase:
+// - find one rdonly in each shard
+// - mark it as 'worker' pointing back to us
+// - stop its replication, and record the position
+// - read the schema of the exported tables
+func (ew *ExportWorker) findTargets(ctx context.Context) error {
+	ew.SetState(WorkerStateFindTargets)
+
+	ew.manifest = &ExportManifest{
+		Keyspace:  ew.keyspace,
+		Format:    ew.config.Format,
+		StartTime: time.Now().UTC().Format(time.RFC3339),
+	}
+	ew.aliases = make([]*topodatapb.TabletAlias, len(ew.shards))
+	ew.tablets = make([]*topodatapb.Tablet, len(ew.shards))
+	ew.schemas = make([]*tabletmanagerdatapb.SchemaDefinition, len(ew.shards))
+	for i, si := range ew.shards {
+		alias, err := FindWorkerTablet(ctx, ew.wr, ew.cleaner, nil /* tsc */, ew.cell, si.Keyspace(), si.ShardName(), ew.config.MinHealthyRdonlyTablets)
+		if err != nil {
+			return fmt.Errorf("FindWorkerTablet() failed for %v/%v/%v: %v", ew.cell, si.Keyspace(), si.ShardName(), err)
+		}
+		ew.aliases[i] = alias
+		ew.wr.Logger().Infof("Using tablet %v to export %v/%v", topoproto.TabletAliasString(alias), si.Keyspace(), si.ShardName())
+
+		shortCtx, cancel := context.WithTimeout(ctx, *remoteActionsTimeout)
+		ti, err := ew.wr.TopoServer().GetTablet(shortCtx, alias)
+		cancel()
+		if err != nil {
+			return fmt.Errorf("cannot read tablet %v: %v", topoproto.TabletAliasString(alias), err)
+		}
+		ew.tablets[i] = ti.Tablet
+
+		shortCtx, cancel = context.WithTimeout(ctx, *remoteActionsTimeout)
+		err = ew.wr.TabletManagerClient().StopSlave(shortCtx, ti.Tablet)
+		cancel()
+		if err != nil {
+			return fmt.Errorf("cannot stop replication on tablet %v: %v", topoproto.TabletAliasString(alias), err)
+		}
+		wrangler.RecordStartSlaveAction(ew.cleaner, ti.Tablet)
+
+		shortCtx, cancel = context.WithTimeout(ctx, *remoteActionsTimeout)
+		status, err := ew.wr.TabletManagerClient().SlaveStatus(shortCtx, ti.Tablet)
+		cancel()
+		if err != nil {
+			return fmt.Errorf("cannot read replication position of tablet %v: %v", topoproto.TabletAliasString(alias), err)
+		}
+		ew.manifest.Shards = append(ew.manifest.Shards, &ExportShard{
+			Shard:       si.ShardName(),
+			TabletAlias: topoproto.TabletAliasString(alias),
+			Position:    status.Position,
+		})
+
+		shortCtx, cancel = context.WithTimeout(ctx, *remoteActionsTimeout)
+		ew.schemas[i], err = ew.wr.GetSchema(shortCtx, alias, ew.config.Tables, ew.config.ExcludeTables, false /* includeViews */)
+		cancel()
+		if err != nil {
+			return fmt.Errorf("cannot get schema from tablet %v: %v", topoproto.TabletAliasString(alias), err)
+		}
+	}
+
+	// All shards must have the same tables, so each table has
+	// the same columns in all its files.
+	if len(ew.schemas[0].TableDefinitions) == 0 {
+		return fmt.Errorf("no tables matching the table filters on tablet %v", topoproto.TabletAliasString(ew.aliases[0]))
+	}
+	for i := 1; i < len(ew.schemas); i++ {
+		if diffs := tmutils.DiffSchemaToArray(ew.shards[0].ShardName(), ew.schemas[0], ew.shards[i].ShardName(), ew.schemas[i]); len(diffs) > 0 {
+			return fmt.Errorf("shards have different schemas, cannot export: %v", strings.Join(diffs, ", "))
+		}
+	}
+	return nil
+}
+
+// export phase:
+// - write each chunk of each table of each shard into its own file
+// - then write the manifest
+func (ew *ExportWorker) export(ctx context.Context) (err error) {
+	ew.SetState(WorkerStateCloneOffline)
+
+	bs, err := backupstorage.GetBackupStorage()
+	if err != nil {
+		return err
+	}
+	defer bs.Close()
+	name := ew.config.Name
+	if name == "" {
+		name = time.Now().UTC().Format("2006-01-02.150405")
+	}
+	bh, err := bs.StartBackup(ExportDir(ew.keyspace), name)
+	if err != nil {
+		return fmt.Errorf("StartBackup(%v, %v) failed: %v", ExportDir(ew.keyspace), name, err)
+	}
+	defer func() {
+		if err != nil {
+			if abortErr := bh.AbortBackup(); abortErr != nil {
+				ew.wr.Logger().Errorf("failed to abort export %v: %v", name, abortErr)
+			}
+		}
+	}()
+	ew.wr.Logger().Infof("Exporting keyspace %v to %v/%v", ew.keyspace, bh.Directory(), bh.Name())
+
+	// Compute all the chunks first, so the manifest lists the files
+	// in a deterministic order.
+	ew.manifest.Tables = make([]*ExportTable, len(ew.schemas[0].TableDefinitions))
+	var mu sync.Mutex
+	wg := sync.WaitGroup{}
+	rec := concurrency.AllErrorRecorder{}
+	sem := sync2.NewSemaphore(ew.config.Concurrency, 0)
+	for tableIndex, td := range ew.schemas[0].TableDefinitions {
+		table := &ExportTable{
+			Name: td.Name,
+		}
+		ew.manifest.Tables[tableIndex] = table
+		for shardIndex := range ew.shards {
+			td := tableDefinition(ew.schemas[shardIndex], td.Name)
+			// The chunks are computed on the stopped tablet, like for
+			// SplitClone. SplitQuery would split the table on a serving
+			// tablet, at a different position than the snapshot.
+			chunks, err := generateChunks(ctx, ew.wr, ew.tablets[shardIndex], td, ew.config.ChunkCount, ew.config.MinRowsPerChunk)
+			if err != nil {
+				rec.RecordError(fmt.Errorf("failed to split table %v on tablet %v into chunks: %v", td.Name, topoproto.TabletAliasString(ew.aliases[shardIndex]), err))
+				break
+			}
+			for _, c := range chunks {
+				file := &ExportFile{
+					Name:  fmt.Sprintf("table%v-shard%v-chunk%v", tableIndex, shardIndex, c.number),
+					Shard: ew.shards[shardIndex].ShardName(),
+					Chunk: c.number,
+				}
+				table.Files = append(table.Files, file)
+
+				wg.Add(1)
+				go func(shardIndex int, td *tabletmanagerdatapb.TableDefinition, c chunk, file *ExportFile) {
+					defer wg.Done()
+					sem.Acquire()
+					defer sem.Release()
+					if rec.HasErrors() {
+						return
+					}
+
+					fields, err := ew.exportChunk(ctx, bh, ew.aliases[shardIndex], td, c, file)
+					if err != nil {
+						rec.RecordError(fmt.Errorf("failed to export chunk %v of table %v from tablet %v: %v", c, td.Name, topoproto.TabletAliasString(ew.aliases[shardIndex]), err))
+						return
+					}
+					mu.Lock()
+					if table.Columns == nil {
+						table.Columns = exportColumns(fields)
+					}
+					mu.Unlock()
+				}(shardIndex, td, c, file)
+			}
+		}
+		if rec.HasErrors() {
+			break
+		}
+	}
+	wg.Wait()
+	if rec.HasErrors() {
+		return rec.Error()
+	}
+
+	ew.manifest.EndTime = time.Now().UTC().Format(time.RFC3339)
+	data, err := json.MarshalIndent(ew.manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("cannot JSON encode %v: %v", exportManifestFile, err)
+	}
+	wc, err := bh.AddFile(exportManifestFile)
+	if err != nil {
+		return fmt.Errorf("cannot add %v to export: %v", exportManifestFile, err)
+	}
+	if _, err := wc.Write(data); err != nil {
+		wc.Close()
+		return fmt.Errorf("cannot write %v: %v", exportManifestFile, err)
+	}
+	if err := wc.Close(); err != nil {
+		return fmt.Errorf("cannot close %v: %v", exportManifestFile, err)
+	}
+	if err := bh.EndBackup(); err != nil {
+		return err
+	}
+	ew.wr.Logger().Infof("Exported %v rows of keyspace %v in %v files to %v/%v", ew.rowsExported.Get(), ew.keyspace, ew.filesExported.Get(), bh.Directory(), bh.Name())
+	return nil
+}
+
+// exportChunk writes the rows of one chunk of a table into a file,
+// and records the row count in file. It returns the fields of the rows.
+func (ew *ExportWorker) exportChunk(ctx context.Context, bh backupstorage.BackupHandle, alias *topodatapb.TabletAlias, td *tabletmanagerdatapb.TableDefinition, c chunk, file *ExportFile) ([]*querypb.Field, error) {
+	where := ""
+	if clauses := chunkWhereClauses(td, c); len(clauses) > 0 {
+		where = "WHERE " + strings.Join(clauses, " AND ") + " "
+	}
+	orderBy := ""
+	if len(td.PrimaryKeyColumns) > 0 {
+		orderBy = "ORDER BY " + strings.Join(escapeAll(td.PrimaryKeyColumns), ", ")
+	}
+	sql := fmt.Sprintf("SELECT %v FROM %v %v%v", strings.Join(escapeAll(td.Columns), ", "), escape(td.Name), where, orderBy)
+	qrr, err := NewQueryResultReaderForTablet(ctx, ew.wr.TopoServer(), alias, sql)
+	if err != nil {
+		return nil, err
+	}
+	defer qrr.Close()
+
+	wc, err := bh.AddFile(file.Name)
+	if err != nil {
+		return nil, err
+	}
+	rw, err := newExportRowWriter(ew.config.Format, wc, qrr.Fields())
+	if err != nil {
+		wc.Close()
+		return nil, err
+	}
+	rowReader := NewRowReader(qrr)
+	for {
+		row, err := rowReader.Next()
+		if err != nil {
+			wc.Close()
+			return nil, err
+		}
+		if row == nil {
+			break
+		}
+		if err := rw.writeRow(row); err != nil {
+			wc.Close()
+			return nil, err
+		}
+		file.RowCount++
+	}
+	if err := rw.flush(); err != nil {
+		wc.Close()
+		return nil, err
+	}
+	if err := wc.Close(); err != nil {
+		return nil, err
+	}
+	ew.rowsExported.Add(int64(file.RowCount))
+	ew.filesExported.Add(1)
+	return qrr.Fields(), nil
+}
+
+// tableDefinition returns the definition of a table in a schema, or nil.
+func tableDefinition(sd *tabletmanagerdatapb.SchemaDefinition, name string) *tabletmanagerdatapb.TableDefinition {
+	for _, td := range sd.TableDefinitions {
+		if td.Name == name {
+			return td
+		}
+	}
+	return nil
+}
+
+// exportColumns returns the manifest columns for the fields of a table.
+func exportColumns(fields []*querypb.Field) []*ExportColumn {
+	columns := make([]*ExportColumn, len(fields))
+	for i, field := range fields {
+		columns[i] = &ExportColumn{
+			Name: field.Name,
+			Type: field.Type.String(),
+		}
+	}
+	return columns
+}
+
+// exportRowWriter writes rows in one of the export formats.
+type exportRowWriter interface {
+	writeRow(row []sqltypes.Value) error
+	// flush must be called once all the rows were written.
+	flush() error
+}
+
+// newExportRowWriter returns an exportRowWriter for the format. The CSV
+// writer writes the header row right away.
+func newExportRowWriter(format string, w io.Writer, fields []*querypb.Field) (exportRowWriter, error) {
+	switch format {
+	case ExportFormatCSV:
+		cw := &csvRowWriter{
+			w:      csv.NewWriter(w),
+			record: make([]string, len(fields)),
+		}
+		for i, field := range fields {
+			cw.record[i] = field.Name
+		}
+		if err := cw.w.Write(cw.record); err != nil {
+			return nil, err
+		}
+		return cw, nil
+	case ExportFormatJSON:
+		jw := &jsonRowWriter{
+			w:      bufio.NewWriter(w),
+			fields: fields,
+			names:  make([][]byte, len(fields)),
+		}
+		for i, field := range fields {
+			name, err := json.Marshal(field.Name)
+			if err != nil {
+				return nil, err
+			}
+			jw.names[i] = name
+		}
+		return jw, nil
+	}
+	return nil, fmt.Errorf("unknown export format %q", format)
+}
+
+// csvRowWriter writes rows as CSV records.
+type csvRowWriter struct {
+	w      *csv.Writer
+	record []string
+}
+
+func (cw *csvRowWriter) writeRow(row []sqltypes.Value) error {
+	for i, v := range row {
+		if v.IsNull() {
+			cw.record[i] = ExportCSVNull
+		} else {
+			cw.record[i] = escapeCSVValue(v.String())
+		}
+	}
+	return cw.w.Write(cw.record)
+}
+
+// isCSVNullLike returns true if s is made of at least one backslash
+// followed by N, like ExportCSVNull.
+func isCSVNullLike(s string) bool {
+	return len(s) >= 2 && s[len(s)-1] == 'N' && strings.Trim(s[:len(s)-1], `\`) == ""
+}
+
+// escapeCSVValue returns how a non-NULL value is written in a CSV file.
+func escapeCSVValue(s string) string {
+	if isCSVNullLike(s) {
+		return `\` + s
+	}
+	return s
+}
+
+// unescapeCSVValue is the reverse of escapeCSVValue. It returns false
+// if s is ExportCSVNull.
+func unescapeCSVValue(s string) (string, bool) {
+	if s == ExportCSVNull {
+		return "", false
+	}
+	if isCSVNullLike(s) {
+		return s[1:], true
+	}
+	return s, true
+}
+
+func (cw *csvRowWriter) flush() error {
+	cw.w.Flush()
+	return cw.w.Error()
+}
+
+// jsonRowWriter writes rows as JSON objects, one per line, with the
+// keys in column order.
+type jsonRowWriter struct {
+	w      *bufio.Writer
+	fields []*querypb.Field
+	// names are the JSON encoded column names.
+	names [][]byte
+	buf   bytes.Buffer
+}
+
+func (jw *jsonRowWriter) writeRow(row []sqltypes.Value) error {
+	jw.buf.Reset()
+	jw.buf.WriteByte('{')
+	for i, v := range row {
+		if i > 0 {
+			jw.buf.WriteByte(',')
+		}
+		jw.buf.Write(jw.names[i])
+		jw.buf.WriteByte(':')
+		switch {
+		case v.IsNull():
+			jw.buf.WriteString("null")
+		case sqltypes.IsIntegral(jw.fields[i].Type) || sqltypes.IsFloat(jw.fields[i].Type):
+			jw.buf.Write(v.Raw())
+		default:
+			var value interface{} = v.String()
+			if sqltypes.IsBinary(jw.fields[i].Type) {
+				// encoding/json encodes []byte in base64.
+				value = v.Raw()
+			}
+			data, err := json.Marshal(value)
+			if err != nil {
+				return err
+			}
+			jw.buf.Write(data)
+		}
+	}
+	jw.buf.WriteString("}\n")
+	_, err := jw.w.Write(jw.buf.Bytes())
+	return err
+}
+
+func (jw *jsonRowWriter) flush() error {
+	return jw.w.Flush()
+}
@@ -0,0 +1,161 @@
+// Copyright 2016, Google Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package worker
+
+import (
+	"flag"
+	"fmt"
+	"html/template"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/context"
+
+	"github.com/youtube/vitess/go/vt/wrangler"
+)
+
+const exportHTML = `
+<!DOCTYPE html>
+<head>
+  <title>Export Action</title>
+</head>
+<body>
+  <h1>Export Action</h1>
+
+    {{if .Error}}
+      <b>Error:</b> {{.Error}}</br>
+    {{else}}
+    <form action="/Clones/Export" method="post">
+      <LABEL for="keyspace">Keyspace: </LABEL>
+        <INPUT type="text" id="keyspace" name="keyspace" value=""></BR>
+      <LABEL for="name">Export name (empty for the current time): </LABEL>
+        <INPUT type="text" id="name" name="name" value=""></BR>
+      <LABEL for="tables">Tables (empty for all): </LABEL>
+        <INPUT type="text" id="tables" name="tables" value=""></BR>
+      <LABEL for="excludeTables">Exclude Tables: </LABEL>
+        <INPUT type="text" id="excludeTables" name="excludeTables" value=""></BR>
+      <LABEL for="format">Format (csv or json): </LABEL>
+        <INPUT type="text" id="format" name="format" value="{{.DefaultFormat}}"></BR>
+      <LABEL for="chunkCount">Chunk Count: </LABEL>
+        <INPUT type="text" id="chunkCount" name="chunkCount" value="{{.DefaultChunkCount}}"></BR>
+      <LABEL for="minRowsPerChunk">Minimum Number of Rows per Chunk (may reduce the Chunk Count): </LABEL>
+        <INPUT type="text" id="minRowsPerChunk" name="minRowsPerChunk" value="{{.DefaultMinRowsPerChunk}}"></BR>
+      <LABEL for="concurrency">Concurrency: </LABEL>
+        <INPUT type="text" id="concurrency" name="concurrency" value="{{.DefaultConcurrency}}"></BR>
+      <LABEL for="minHealthyRdonlyTablets">Minimum Number of required healthy RDONLY tablets in each shard: </LABEL>
+        <INPUT type="text" id="minHealthyRdonlyTablets" name="minHealthyRdonlyTablets" value="{{.DefaultMinHealthyRdonlyTablets}}"></BR>
+      <INPUT type="submit" name="submit" value="Export"/>
+    </form>
+    {{end}}
+</body>
+`
+
+var exportTemplate = mustParseTemplate("export", exportHTML)
+
+const defaultExportConcurrency = 4
+
+// splitTableList splits a comma separated list of tables.
+func splitTableList(tables string) []string {
+	if tables == "" {
+		return nil
+	}
+	return strings.Split(tables, ",")
+}
+
+func commandExport(wi *Instance, wr *wrangler.Wrangler, subFlags *flag.FlagSet, args []string) (Worker, error) {
+	name := subFlags.String("name", "", "name of the export in the backup storage (defaults to the current time)")
+	tables := subFlags.String("tables", "", "comma separated list of tables to export (defaults to all tables)")
+	excludeTables := subFlags.String("exclude_tables", "", "comma separated list of tables to exclude")
+	format := subFlags.String("format", ExportFormatCSV, "format of the files: csv, or json for newline-delimited JSON")
+	chunkCount := subFlags.Int("chunk_count", defaultChunkCount, "number of chunks per table, each chunk is written to its own file")
+	minRowsPerChunk := subFlags.Int("min_rows_per_chunk", defaultMinRowsPerChunk, "minimum number of rows per chunk (may reduce --chunk_count)")
+	concurrency := subFlags.Int("concurrency", defaultExportConcurrency, "number of chunks exported in parallel")
+	minHealthyRdonlyTablets := subFlags.Int("min_healthy_rdonly_tablets", defaultMinHealthyRdonlyTablets, "minimum number of healthy RDONLY tablets in each shard before taking one out")
+	if err := subFlags.Parse(args); err != nil {
+		return nil, err
+	}
+	if subFlags.NArg() != 1 {
+		subFlags.Usage()
+		return nil, fmt.Errorf("command Export requires <keyspace>")
+	}
+
+	worker, err := NewExportWorker(wr, wi.cell, subFlags.Arg(0), ExportConfig{
+		Name:                    *name,
+		Tables:                  splitTableList(*tables),
+		ExcludeTables:           splitTableList(*excludeTables),
+		Format:                  *format,
+		ChunkCount:              *chunkCount,
+		MinRowsPerChunk:         *minRowsPerChunk,
+		Concurrency:             *concurrency,
+		MinHealthyRdonlyTablets: *minHealthyRdonlyTablets,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("cannot create Export worker: %v", err)
+	}
+	return worker, nil
+}
+
+func interactiveExport(ctx context.Context, wi *Instance, wr *wrangler.Wrangler, w http.ResponseWriter, r *http.Request) (Worker, *template.Template, map[string]interface{}, error) {
+	if err := r.ParseForm(); err != nil {
+		return nil, nil, nil, fmt.Errorf("cannot parse form: %s", err)
+	}
+
+	if submit := r.FormValue("submit"); submit == "" {
+		// display the input form
+		result := make(map[string]interface{})
+		result["DefaultFormat"] = ExportFormatCSV
+		result["DefaultChunkCount"] = fmt.Sprintf("%v", defaultChunkCount)
+		result["DefaultMinRowsPerChunk"] = fmt.Sprintf("%v", defaultMinRowsPerChunk)
+		result["DefaultConcurrency"] = fmt.Sprintf("%v", defaultExportConcurrency)
+		result["DefaultMinHealthyRdonlyTablets"] = fmt.Sprintf("%v", defaultMinHealthyRdonlyTablets)
+		return nil, exportTemplate, result, nil
+	}
+
+	// Process input form.
+	keyspace := r.FormValue("keyspace")
+	if keyspace == "" {
+		return nil, nil, nil, fmt.Errorf("keyspace is required")
+	}
+	chunkCount, err := strconv.ParseInt(r.FormValue("chunkCount"), 0, 64)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("cannot parse chunkCount: %s", err)
+	}
+	minRowsPerChunk, err := strconv.ParseInt(r.FormValue("minRowsPerChunk"), 0, 64)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("cannot parse minRowsPerChunk: %s", err)
+	}
+	concurrency, err := strconv.ParseInt(r.FormValue("concurrency"), 0, 64)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("cannot parse concurrency: %s", err)
+	}
+	minHealthyRdonlyTablets, err := strconv.ParseInt(r.FormValue("minHealthyRdonlyTablets"), 0, 64)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("cannot parse minHealthyRdonlyTablets: %s", err)
+	}
+
+	// start the export job
+	wrk, err := NewExportWorker(wr, wi.cell, keyspace, ExportConfig{
+		Name:                    r.FormValue("name"),
+		Tables:                  splitTableList(r.FormValue("tables")),
+		ExcludeTables:           splitTableList(r.FormValue("excludeTables")),
+		Format:                  r.FormValue("format"),
+		ChunkCount:              int(chunkCount),
+		MinRowsPerChunk:         int(minRowsPerChunk),
+		Concurrency:             int(concurrency),
+		MinHealthyRdonlyTablets: int(minHealthyRdonlyTablets),
+	})
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("cannot create Export worker: %v", err)
+	}
+	return wrk, nil, nil, nil
+}
+
+func init() {
+	AddCommand("Clones", Command{"Export",
+		commandExport, interactiveExport,
+		"[--name=''] [--tables=''] [--exclude_tables=''] [--format=csv|json] [--chunk_count=N] [--concurrency=N] <keyspace>",
+		"Exports a snapshot of the keyspace to files in the backup storage: in each shard, an RDONLY tablet is stopped at a known replication position and its tables are written in chunks, as CSV or newline-delimited JSON files, with a MANIFEST listing the files and the positions"})
+}
@@ -0,0 +1,394 @@
+// Copyright 2016, Google Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package worker
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/youtube/vitess/go/sqltypes"
+	"github.com/youtube/vitess/go/vt/mysqlctl/backupstorage"
+	"github.com/youtube/vitess/go/vt/mysqlctl/filebackupstorage"
+	"github.com/youtube/vitess/go/vt/mysqlctl/replication"
+	"github.com/youtube/vitess/go/vt/mysqlctl/tmutils"
+	"github.com/youtube/vitess/go/vt/tabletserver/grpcqueryservice"
+	"github.com/youtube/vitess/go/vt/tabletserver/queryservice/fakes"
+	"github.com/youtube/vitess/go/vt/vttest/fakesqldb"
+	"github.com/youtube/vitess/go/vt/wrangler/testlib"
+	"github.com/youtube/vitess/go/vt/zktopo/zktestserver"
+	"golang.org/x/net/context"
+
+	querypb "github.com/youtube/vitess/go/vt/proto/query"
+	tabletmanagerdatapb "github.com/youtube/vitess/go/vt/proto/tabletmanagerdata"
+	topodatapb "github.com/youtube/vitess/go/vt/proto/topodata"
+)
+
+func TestExportRowWriter(t *testing.T) {
+	fields := []*querypb.Field{
+		{Name: "id", Type: sqltypes.Int64},
+		{Name: "price", Type: sqltypes.Float64},
+		{Name: "name", Type: sqltypes.VarChar},
+		{Name: "data", Type: sqltypes.VarBinary},
+	}
+	rows := [][]sqltypes.Value{
+		{
+			sqltypes.MakeTrusted(sqltypes.Int64, []byte("1")),
+			sqltypes.MakeTrusted(sqltypes.Float64, []byte("1.5")),
+			sqltypes.MakeTrusted(sqltypes.VarChar, []byte("a \"quoted\", name")),
+			sqltypes.MakeTrusted(sqltypes.VarBinary, []byte{0, 255}),
+		},
+		{
+			sqltypes.MakeTrusted(sqltypes.Int64, []byte("2")),
+			sqltypes.NULL,
+			sqltypes.MakeTrusted(sqltypes.VarChar, []byte("")),
+			sqltypes.NULL,
+		},
+	}
+
+	testcases := []struct {
+		format string
+		want   string
+	}{{
+		format: ExportFormatCSV,
+		want: "id,price,name,data\n" +
+			"1,1.5,\"a \"\"quoted\"\", name\",\x00\xff\n" +
+			"2,\\N,,\\N\n",
+	}, {
+		format: ExportFormatJSON,
+		want: `{"id":1,"price":1.5,"name":"a \"quoted\", name","data":"AP8="}` + "\n" +
+			`{"id":2,"price":null,"name":"","data":null}` + "\n",
+	}}
+	for _, tcase := range testcases {
+		var b bytes.Buffer
+		rw, err := newExportRowWriter(tcase.format, &b, fields)
+		if err != nil {
+			t.Fatalf("newExportRowWriter(%v) failed: %v", tcase.format, err)
+		}
+		for _, row := range rows {
+			if err := rw.writeRow(row); err != nil {
+				t.Fatalf("writeRow(%v) failed: %v", tcase.format, err)
+			}
+		}
+		if err := rw.flush(); err != nil {
+			t.Fatalf("flush(%v) failed: %v", tcase.format, err)
+		}
+		if got := b.String(); got != tcase.want {
+			t.Errorf("%v export:\n%q\nwant:\n%q", tcase.format, got, tcase.want)
+		}
+	}
+
+	if _, err := newExportRowWriter("xml", &bytes.Buffer{}, fields); err == nil {
+		t.Errorf("newExportRowWriter(xml) worked")
+	}
+}
+
+func TestExportCSVEscape(t *testing.T) {
+	testcases := []struct {
+		value   string
+		escaped string
+	}{
+		{"", ""},
+		{"N", "N"},
+		{`a\N`, `a\N`},
+		{`\N`, `\\N`},
+		{`\\N`, `\\\N`},
+		{`\n`, `\n`},
+	}
+	for _, tcase := range testcases {
+		escaped := escapeCSVValue(tcase.value)
+		if escaped != tcase.escaped {
+			t.Errorf("escapeCSVValue(%q) = %q, want %q", tcase.value, escaped, tcase.escaped)
+		}
+		if escaped == ExportCSVNull {
+			t.Errorf("escapeCSVValue(%q) is NULL", tcase.value)
+		}
+		if value, ok := unescapeCSVValue(escaped); !ok || value != tcase.value {
+			t.Errorf("unescapeCSVValue(%q) = %q, %v, want %q, true", escaped, value, ok, tcase.value)
+		}
+	}
+	if _, ok := unescapeCSVValue(ExportCSVNull); ok {
+		t.Errorf("unescapeCSVValue(%q) is not NULL", ExportCSVNull)
+	}
+}
+
+// exportTabletServer is a local QueryService implementation which
+// returns the rows of each chunk query.
+type exportTabletServer struct {
+	t *testing.T
+	// rows is keyed by the query of the chunk.
+	rows map[string][][]sqltypes.Value
+	// failQuery fails after its first row.
+	failQuery string
+
+	*fakes.StreamHealthQueryService
+}
+
+func (sq *exportTabletServer) StreamExecute(ctx context.Context, target *querypb.Target, sql string, bindVariables map[string]interface{}, options *querypb.ExecuteOptions, sendReply func(reply *sqltypes.Result) error) error {
+	rows, ok := sq.rows[sql]
+	if !ok {
+		sq.t.Errorf("exportTabletServer: unexpected query: %v", sql)
+		return errors.New("unexpected query")
+	}
+	if err := sendReply(&sqltypes.Result{
+		Fields: exportFields,
+	}); err != nil {
+		return err
+	}
+	for i, row := range rows {
+		if sql == sq.failQuery && i == 1 {
+			return errors.New("connection lost")
+		}
+		if err := sendReply(&sqltypes.Result{
+			Rows: [][]sqltypes.Value{row},
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var exportFields = []*querypb.Field{
+	{
+		Name: "id",
+		Type: sqltypes.Int64,
+	},
+	{
+		Name: "name",
+		Type: sqltypes.VarChar,
+	},
+}
+
+func exportRow(id string, name *string) []sqltypes.Value {
+	row := []sqltypes.Value{sqltypes.MakeTrusted(sqltypes.Int64, []byte(id)), sqltypes.NULL}
+	if name != nil {
+		row[1] = sqltypes.MakeTrusted(sqltypes.VarChar, []byte(*name))
+	}
+	return row
+}
+
+func TestExport(t *testing.T) {
+	root, err := ioutil.TempDir("", "exporttest")
+	if err != nil {
+		t.Fatalf("ioutil.TempDir failed: %v", err)
+	}
+	defer os.RemoveAll(root)
+	*filebackupstorage.FileBackupStorageRoot = path.Join(root, "fbs")
+	*backupstorage.BackupStorageImplementation = "file"
+
+	// The table is split in two chunks, at id 2. The values of the
+	// second chunk look like the NULL of the CSV files.
+	const (
+		chunk1Query = "SELECT `id`, `name` FROM `t1` WHERE `id`<2 ORDER BY `id`"
+		chunk2Query = "SELECT `id`, `name` FROM `t1` WHERE `id`>=2 ORDER BY `id`"
+	)
+	a, null, nullLike := "a", `\N`, `\\N`
+	chunkRows := map[string][][]sqltypes.Value{
+		chunk1Query: {
+			exportRow("1", &a),
+		},
+		chunk2Query: {
+			exportRow("2", &null),
+			exportRow("3", nil),
+			exportRow("4", &nullLike),
+		},
+	}
+	minMaxResult := &sqltypes.Result{
+		Fields: []*querypb.Field{
+			{
+				Name: "min",
+				Type: sqltypes.Int64,
+			},
+			{
+				Name: "max",
+				Type: sqltypes.Int64,
+			},
+		},
+		Rows: [][]sqltypes.Value{
+			{
+				sqltypes.MakeTrusted(sqltypes.Int64, []byte("1")),
+				sqltypes.MakeTrusted(sqltypes.Int64, []byte("4")),
+			},
+		},
+	}
+
+	testCases := []struct {
+		name      string
+		failQuery string
+		wantErr   string
+		// wantFiles are the contents of the files of the export.
+		wantFiles map[string]string
+		// wantRowCounts are the row counts of the files in the manifest.
+		wantRowCounts []uint64
+	}{
+		{
+			name: "csv",
+			wantFiles: map[string]string{
+				"table0-shard0-chunk1": "id,name\n1,a\n",
+				"table0-shard0-chunk2": "id,name\n2,\\\\N\n3,\\N\n4,\\\\\\N\n",
+			},
+			wantRowCounts: []uint64{1, 3},
+		},
+		{
+			name:      "failed_chunk",
+			failQuery: chunk2Query,
+			wantErr:   "failed to export chunk",
+		},
+	}
+	for _, tc := range testCases {
+		db := fakesqldb.Register()
+		ts := zktestserver.New(t, []string{"cell1"})
+		ctx := context.Background()
+		wi := NewInstance(ts, "cell1", time.Second)
+
+		master := testlib.NewFakeTablet(t, wi.wr, "cell1", 10,
+			topodatapb.TabletType_MASTER, db, testlib.TabletKeyspaceShard(t, "ks", "0"))
+		rdonly := testlib.NewFakeTablet(t, wi.wr, "cell1", 11,
+			topodatapb.TabletType_RDONLY, db, testlib.TabletKeyspaceShard(t, "ks", "0"))
+		rdonly.FakeMysqlDaemon.Schema = &tabletmanagerdatapb.SchemaDefinition{
+			TableDefinitions: []*tabletmanagerdatapb.TableDefinition{
+				{
+					Name:              "t1",
+					Columns:           []string{"id", "name"},
+					PrimaryKeyColumns: []string{"id"},
+					Type:              tmutils.TableBaseTable,
+					RowCount:          4,
+				},
+			},
+		}
+		rdonlyDb := newQueryFakeDb(t, "rdonly", map[string]*sqltypes.Result{
+			"SELECT MIN(`id`), MAX(`id`) FROM `vt_ks`.`t1`": minMaxResult,
+		})
+		rdonly.FakeMysqlDaemon.DbAppConnectionFactory = rdonlyDb.factory()
+		rdonly.FakeMysqlDaemon.CurrentMasterPosition = replication.Position{
+			GTIDSet: replication.MariadbGTID{Domain: 12, Server: 34, Sequence: 5678},
+		}
+		rdonly.FakeMysqlDaemon.ExpectedExecuteSuperQueryList = []string{
+			"STOP SLAVE",
+			"START SLAVE",
+		}
+		for _, ft := range []*testlib.FakeTablet{master, rdonly} {
+			ft.StartActionLoop(t, wi.wr)
+			defer ft.StopActionLoop(t)
+		}
+		qs := fakes.NewStreamHealthQueryService(rdonly.Target())
+		qs.AddDefaultHealthResponse()
+		grpcqueryservice.Register(rdonly.RPCServer, &exportTabletServer{
+			t:                        t,
+			rows:                     chunkRows,
+			failQuery:                tc.failQuery,
+			StreamHealthQueryService: qs,
+		})
+		if err := wi.wr.RebuildKeyspaceGraph(ctx, "ks", nil); err != nil {
+			t.Fatalf("RebuildKeyspaceGraph failed: %v", err)
+		}
+
+		args := []string{"Export", "-name", tc.name, "-format", "csv", "-chunk_count", "2", "-min_rows_per_chunk", "1", "-min_healthy_rdonly_tablets", "1", "ks"}
+		err := runCommand(t, wi, wi.wr, args)
+		bs, bsErr := backupstorage.GetBackupStorage()
+		if bsErr != nil {
+			t.Fatalf("GetBackupStorage failed: %v", bsErr)
+		}
+		defer bs.Close()
+		bhs, bsErr := bs.ListBackups(ExportDir("ks"))
+		if bsErr != nil {
+			t.Fatalf("ListBackups failed: %v", bsErr)
+		}
+		var bh backupstorage.BackupHandle
+		for _, h := range bhs {
+			if h.Name() == tc.name {
+				bh = h
+			}
+		}
+
+		if tc.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("%v: Export returned: %v, want error containing: %v", tc.name, err, tc.wantErr)
+			}
+			// The incomplete export was removed.
+			if bh != nil {
+				t.Errorf("%v: the failed export was not removed", tc.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%v: Export failed: %v", tc.name, err)
+			continue
+		}
+		if bh == nil {
+			t.Errorf("%v: export not found in %v", tc.name, ExportDir("ks"))
+			continue
+		}
+
+		var manifest ExportManifest
+		if err := json.Unmarshal([]byte(readExportFile(t, bh, exportManifestFile)), &manifest); err != nil {
+			t.Fatalf("%v: cannot decode %v: %v", tc.name, exportManifestFile, err)
+		}
+		if len(manifest.Tables) != 1 {
+			t.Fatalf("%v: manifest tables: %v, want t1", tc.name, manifest.Tables)
+		}
+		var rowCounts []uint64
+		for _, file := range manifest.Tables[0].Files {
+			rowCounts = append(rowCounts, file.RowCount)
+			if got, want := readExportFile(t, bh, file.Name), tc.wantFiles[file.Name]; got != want {
+				t.Errorf("%v: file %v:\n%q\nwant:\n%q", tc.name, file.Name, got, want)
+			}
+		}
+		if !reflect.DeepEqual(rowCounts, tc.wantRowCounts) {
+			t.Errorf("%v: row counts of the files: %v, want: %v", tc.name, rowCounts, tc.wantRowCounts)
+		}
+
+		// The values, and the NULL, are read back as they were exported.
+		fields := map[string]*querypb.Field{
+			"id":   exportFields[0],
+			"name": exportFields[1],
+		}
+		for name, query := range map[string]string{
+			"table0-shard0-chunk1": chunk1Query,
+			"table0-shard0-chunk2": chunk2Query,
+		} {
+			cr, err := newCSVImportReader(strings.NewReader(readExportFile(t, bh, name)), fields)
+			if err != nil {
+				t.Fatalf("%v: newCSVImportReader(%v) failed: %v", tc.name, name, err)
+			}
+			var rows [][]sqltypes.Value
+			for {
+				row, _, err := cr.next()
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					t.Fatalf("%v: cannot read %v: %v", tc.name, name, err)
+				}
+				rows = append(rows, row)
+			}
+			if !reflect.DeepEqual(rows, chunkRows[query]) {
+				t.Errorf("%v: rows read from %v: %v, want: %v", tc.name, name, rows, chunkRows[query])
+			}
+		}
+	}
+}
+
+// readExportFile returns the contents of a file of an export.
+func readExportFile(t *testing.T, bh backupstorage.BackupHandle, name string) string {
+	rc, err := bh.ReadFile(name)
+	if err != nil {
+		t.Fatalf("ReadFile(%v) failed: %v", name, err)
+	}
+	defer rc.Close()
+	data, err := ioutil.ReadAll(rc)
+	if err != nil {
+		t.Fatalf("cannot read %v: %v", name, err)
+	}
+	return string(data)
+}
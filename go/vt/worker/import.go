@@ -0,0 +1,1009 @@
+// Copyright 2016, Google Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package worker
+
+import (
+	"bufio"
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/youtube/vitess/go/sqltypes"
+	"github.com/youtube/vitess/go/sync2"
+	"github.com/youtube/vitess/go/vt/concurrency"
+	"github.com/youtube/vitess/go/vt/discovery"
+	"github.com/youtube/vitess/go/vt/key"
+	"github.com/youtube/vitess/go/vt/throttler"
+	"github.com/youtube/vitess/go/vt/topo"
+	"github.com/youtube/vitess/go/vt/topo/topoproto"
+	"github.com/youtube/vitess/go/vt/vtgate/vindexes"
+	"github.com/youtube/vitess/go/vt/wrangler"
+
+	querypb "github.com/youtube/vitess/go/vt/proto/query"
+	topodatapb "github.com/youtube/vitess/go/vt/proto/topodata"
+)
+
+// ImportConfig contains the parameters of an Import run.
+type ImportConfig struct {
+	// Table is the table the rows are inserted into.
+	Table string
+	// Format is ExportFormatCSV or ExportFormatJSON. The files use the
+	// same conventions as the files written by Export.
+	Format string
+	// Files are the paths of the files to import, on the vtworker host.
+	Files []string
+	// BatchSize is the maximum number of rows per INSERT.
+	BatchSize int
+	// WriterCount is the number of concurrent writers per shard.
+	WriterCount       int
+	MaxTPS            int64
+	MaxReplicationLag int64
+	// VtgateServer is the vtgate used to create the entries of the
+	// lookup vindexes owned by the table. It is only required if the
+	// table owns lookup vindexes.
+	VtgateServer         string
+	VtgateConnectTimeout time.Duration
+	// RejectedRowsFile is the path of the report of the rejected rows,
+	// one JSON object per row. If empty, they are only logged.
+	RejectedRowsFile string
+}
+
+// importRejectedRow is a line of the rejected rows report.
+type importRejectedRow struct {
+	File  string
+	Line  int
+	Error string
+	// Record is the CSV record, or the JSON object, of the row.
+	Record interface{}
+}
+
+// importRowRef locates an imported row in its file, to report it.
+type importRowRef struct {
+	file   string
+	line   int
+	record interface{}
+}
+
+// importBatch is a batch of rows for a shard, inserted with a single
+// INSERT. If it fails, the rows are inserted one by one, so only the
+// bad rows are rejected.
+type importBatch struct {
+	// insertPrefix is "INSERT INTO db.table (columns) VALUES ".
+	insertPrefix string
+	fields       []*querypb.Field
+	rows         [][]sqltypes.Value
+	refs         []importRowRef
+	// ksids has the keyspace id of each row, and ownedIndexes the
+	// columns of the owned vindexes, see route. They are used to delete
+	// the lookup vindex entries of the rows that are not inserted.
+	ksids        [][]byte
+	ownedIndexes []int
+}
+
+// ImportWorker inserts the rows of CSV or JSON files into a table of a
+// sharded keyspace. The keyspace id of each row is computed with the
+// primary vindex of the table, and the rows are inserted in batches
+// directly on the master of their shard. The entries of the lookup
+// vindexes owned by the table are created through vtgate, before the
+// row is inserted. They are deleted again if the row is rejected, or
+// not sent to MySQL because the import failed.
+type ImportWorker struct {
+	StatusWorker
+
+	wr       *wrangler.Wrangler
+	cell     string
+	keyspace string
+	config   ImportConfig
+
+	// populated during WorkerStateInit, read-only after that
+	shards []*topo.ShardInfo
+	// table is nil for an unsharded keyspace.
+	table         *vindexes.Table
+	primaryVindex vindexes.Unique
+	vcursor       *vtgateVCursor
+	healthCheck   discovery.HealthCheck
+	tsc           *discovery.TabletStatsCache
+	shardWatchers []*discovery.TopologyWatcher
+
+	// populated during WorkerStateFindTargets, read-only after that
+	// dbNames has one entry for each shard.
+	dbNames []string
+	// fields are the columns of the destination table, by name.
+	fields map[string]*querypb.Field
+
+	// throttlersMu guards the fields within this group.
+	throttlersMu sync.Mutex
+	// throttlers has one entry for each shard.
+	throttlers []*throttler.Throttler
+
+	// rejectedMu guards the report of the rejected rows.
+	rejectedMu   sync.Mutex
+	rejectedFile *os.File
+	rejected     *json.Encoder
+
+	rowsRead     sync2.AtomicInt64
+	rowsImported sync2.AtomicInt64
+	rowsRejected sync2.AtomicInt64
+}
+
+// NewImportWorker returns a new ImportWorker object.
+func NewImportWorker(wr *wrangler.Wrangler, cell, keyspace string, config ImportConfig) (Worker, error) {
+	if config.Table == "" {
+		return nil, fmt.Errorf("table must be set")
+	}
+	if config.Format != ExportFormatCSV && config.Format != ExportFormatJSON {
+		return nil, fmt.Errorf("format must be %v or %v, got %q", ExportFormatCSV, ExportFormatJSON, config.Format)
+	}
+	if len(config.Files) == 0 {
+		return nil, fmt.Errorf("no files to import")
+	}
+	if config.BatchSize < 1 {
+		return nil, fmt.Errorf("batch_size must be at least 1, got %v", config.BatchSize)
+	}
+	if config.WriterCount < 1 {
+		return nil, fmt.Errorf("writer_count must be at least 1, got %v", config.WriterCount)
+	}
+	if config.MaxTPS != throttler.MaxRateModuleDisabled && config.MaxTPS < int64(config.WriterCount) {
+		return nil, fmt.Errorf("-max_tps must be >= -writer_count: %v >= %v", config.MaxTPS, config.WriterCount)
+	}
+	return &ImportWorker{
+		StatusWorker: NewStatusWorker(),
+		wr:           wr,
+		cell:         cell,
+		keyspace:     keyspace,
+		config:       config,
+	}, nil
+}
+
+// StatusAsHTML implements the Worker interface.
+func (iw *ImportWorker) StatusAsHTML() template.HTML {
+	state := iw.State()
+
+	result := "<b>Importing into:</b> " + iw.keyspace + "." + iw.config.Table + "</br>\n"
+	result += "<b>State:</b> " + state.String() + "</br>\n"
+	switch state {
+	case WorkerStateCloneOnline, WorkerStateDone:
+		result += fmt.Sprintf("<b>Rows:</b> %v read, %v imported, %v rejected</br>\n", iw.rowsRead.Get(), iw.rowsImported.Get(), iw.rowsRejected.Get())
+	}
+	return template.HTML(result)
+}
+
+// StatusAsText implements the Worker interface.
+func (iw *ImportWorker) StatusAsText() string {
+	state := iw.State()
+
+	result := "Importing into: " + iw.keyspace + "." + iw.config.Table + "\n"
+	result += "State: " + state.String() + "\n"
+	switch state {
+	case WorkerStateCloneOnline, WorkerStateDone:
+		result += fmt.Sprintf("Rows: %v read, %v imported, %v rejected\n", iw.rowsRead.Get(), iw.rowsImported.Get(), iw.rowsRejected.Get())
+	}
+	return result
+}
+
+// Run is mostly a wrapper to run the cleanup at the end.
+func (iw *ImportWorker) Run(ctx context.Context) error {
+	resetVars()
+	err := iw.run(ctx)
+
+	iw.SetState(WorkerStateCleanUp)
+	iw.closeThrottlers()
+	// Stop watchers to prevent new tablets from getting added to the healthCheck.
+	for _, watcher := range iw.shardWatchers {
+		watcher.Stop()
+	}
+	// Stop healthCheck to make sure it stops calling our listener implementation.
+	if iw.healthCheck != nil {
+		if err := iw.healthCheck.Close(); err != nil {
+			iw.wr.Logger().Errorf("HealthCheck.Close() failed: %v", err)
+		}
+	}
+	if iw.vcursor != nil {
+		iw.vcursor.Close()
+	}
+	if iw.rejectedFile != nil {
+		if cerr := iw.rejectedFile.Close(); cerr != nil && err == nil {
+			err = fmt.Errorf("cannot close rejected rows file: %v", cerr)
+		}
+	}
+
+	if err != nil {
+		iw.SetState(WorkerStateError)
+		return err
+	}
+	iw.wr.Logger().Infof("Import into %v.%v done: %v rows read, %v imported, %v rejected", iw.keyspace, iw.config.Table, iw.rowsRead.Get(), iw.rowsImported.Get(), iw.rowsRejected.Get())
+	iw.SetState(WorkerStateDone)
+	return nil
+}
+
+func (iw *ImportWorker) run(ctx context.Context) error {
+	// first state: read what we need to do
+	if err := iw.init(ctx); err != nil {
+		return fmt.Errorf("init() failed: %v", err)
+	}
+	if err := checkDone(ctx); err != nil {
+		return err
+	}
+
+	// second state: find the destination masters
+	if err := iw.findTargets(ctx); err != nil {
+		return fmt.Errorf("findTargets() failed: %v", err)
+	}
+	if err := checkDone(ctx); err != nil {
+		return err
+	}
+
+	// third state: import the files
+	if err := iw.importFiles(ctx); err != nil {
+		return fmt.Errorf("importFiles() failed: %v", err)
+	}
+	return nil
+}
+
+// init phase:
+// - read the shards and the vschema of the keyspace
+// - connect to vtgate if the table owns lookup vindexes
+// - start the healthcheck for the shards
+func (iw *ImportWorker) init(ctx context.Context) error {
+	iw.SetState(WorkerStateInit)
+
+	shortCtx, cancel := context.WithTimeout(ctx, *remoteActionsTimeout)
+	shardMap, err := iw.wr.TopoServer().FindAllShardsInKeyspace(shortCtx, iw.keyspace)
+	cancel()
+	if err != nil {
+		return fmt.Errorf("cannot read shards of keyspace %v: %v", iw.keyspace, err)
+	}
+	if len(shardMap) == 0 {
+		return fmt.Errorf("keyspace %v has no shards", iw.keyspace)
+	}
+	shardNames := make([]string, 0, len(shardMap))
+	for shardName := range shardMap {
+		shardNames = append(shardNames, shardName)
+	}
+	sort.Strings(shardNames)
+	iw.shards = make([]*topo.ShardInfo, len(shardNames))
+	for i, shardName := range shardNames {
+		iw.shards[i] = shardMap[shardName]
+	}
+
+	shortCtx, cancel = context.WithTimeout(ctx, *remoteActionsTimeout)
+	kschema, err := iw.wr.TopoServer().GetVSchema(shortCtx, iw.keyspace)
+	cancel()
+	if err != nil {
+		return fmt.Errorf("cannot load VSchema for keyspace %v: %v", iw.keyspace, err)
+	}
+	keyspaceSchema, err := vindexes.BuildKeyspaceSchema(kschema, iw.keyspace)
+	if err != nil {
+		return fmt.Errorf("cannot build vschema for keyspace %v: %v", iw.keyspace, err)
+	}
+	if keyspaceSchema.Keyspace.Sharded {
+		if err := iw.initVindexes(ctx, keyspaceSchema); err != nil {
+			return err
+		}
+	} else if len(iw.shards) != 1 {
+		return fmt.Errorf("keyspace %v is not sharded in its vschema, but has %v shards", iw.keyspace, len(iw.shards))
+	}
+
+	if iw.config.RejectedRowsFile != "" {
+		iw.rejectedFile, err = os.Create(iw.config.RejectedRowsFile)
+		if err != nil {
+			return fmt.Errorf("cannot create rejected rows file: %v", err)
+		}
+		iw.rejected = json.NewEncoder(iw.rejectedFile)
+	}
+
+	// Initialize healthcheck and add the shards to it.
+	iw.healthCheck = discovery.NewHealthCheck(*remoteActionsTimeout, *healthcheckRetryDelay, *healthCheckTimeout)
+	iw.tsc = discovery.NewTabletStatsCacheDoNotSetListener(iw.cell)
+	// We set sendDownEvents=true because it's required by TabletStatsCache.
+	iw.healthCheck.SetListener(iw, true /* sendDownEvents */)
+	for _, si := range iw.shards {
+		watcher := discovery.NewShardReplicationWatcher(iw.wr.TopoServer(), iw.healthCheck,
+			iw.cell, si.Keyspace(), si.ShardName(),
+			*healthCheckTopologyRefresh, discovery.DefaultTopoReadConcurrency)
+		iw.shardWatchers = append(iw.shardWatchers, watcher)
+	}
+	return nil
+}
+
+// initVindexes finds the primary vindex of the table, and connects to
+// vtgate if the table owns lookup vindexes.
+func (iw *ImportWorker) initVindexes(ctx context.Context, keyspaceSchema *vindexes.KeyspaceSchema) error {
+	table, ok := keyspaceSchema.Tables[iw.config.Table]
+	if !ok {
+		return fmt.Errorf("no vschema definition for table %v", iw.config.Table)
+	}
+	if len(table.ColumnVindexes) == 0 {
+		return fmt.Errorf("no vindex definition for table %v", iw.config.Table)
+	}
+	primary := table.ColumnVindexes[0]
+	if primary.Owned {
+		return fmt.Errorf("primary vindex %v of table %v is owned by the table, this is not supported", primary.Name, iw.config.Table)
+	}
+	unique, ok := primary.Vindex.(vindexes.Unique)
+	if !ok {
+		return fmt.Errorf("primary vindex %v is not unique for table %v", primary.Name, iw.config.Table)
+	}
+	iw.table = table
+	iw.primaryVindex = unique
+
+	// The lookup vindexes, and a primary vindex with a cost above 1,
+	// need to run queries.
+	if len(table.Owned) == 0 && primary.Vindex.Cost() <= 1 {
+		return nil
+	}
+	if iw.config.VtgateServer == "" {
+		return fmt.Errorf("table %v has lookup vindexes, --vtgate_server is required", iw.config.Table)
+	}
+	vcursor, err := newVtgateVCursor(ctx, iw.config.VtgateServer, iw.config.VtgateConnectTimeout)
+	if err != nil {
+		return err
+	}
+	iw.vcursor = vcursor
+	return nil
+}
+
+// findTargets phase:
+// - find the master of each shard, and its database name
+// - read the column types of the table
+// - create the throttlers
+func (iw *ImportWorker) findTargets(ctx context.Context) error {
+	iw.SetState(WorkerStateFindTargets)
+
+	iw.dbNames = make([]string, len(iw.shards))
+	for i, si := range iw.shards {
+		waitCtx, waitCancel := context.WithTimeout(ctx, *waitForHealthyTabletsTimeout)
+		err := iw.tsc.WaitForTablets(waitCtx, iw.cell, si.Keyspace(), si.ShardName(), []topodatapb.TabletType{topodatapb.TabletType_MASTER})
+		waitCancel()
+		if err != nil {
+			return fmt.Errorf("cannot find MASTER tablet for shard %v/%v (in cell: %v): %v", si.Keyspace(), si.ShardName(), iw.cell, err)
+		}
+		masters := iw.tsc.GetHealthyTabletStats(si.Keyspace(), si.ShardName(), topodatapb.TabletType_MASTER)
+		if len(masters) == 0 {
+			return fmt.Errorf("cannot find MASTER tablet for shard %v/%v (in cell: %v) in HealthCheck: empty TabletStats list", si.Keyspace(), si.ShardName(), iw.cell)
+		}
+		master := masters[0]
+		iw.dbNames[i] = topoproto.TabletDbName(master.Tablet)
+		iw.wr.Logger().Infof("Using tablet %v as master for %v/%v", topoproto.TabletAliasString(master.Tablet.Alias), si.Keyspace(), si.ShardName())
+
+		if i == 0 {
+			query := fmt.Sprintf("SELECT * FROM %v.%v LIMIT 0", escape(iw.dbNames[i]), escape(iw.config.Table))
+			shortCtx, cancel := context.WithTimeout(ctx, *remoteActionsTimeout)
+			qr, err := iw.wr.TabletManagerClient().ExecuteFetchAsApp(shortCtx, master.Tablet, true, []byte(query), 0)
+			cancel()
+			if err != nil {
+				return fmt.Errorf("cannot read the columns of table %v: %v", iw.config.Table, err)
+			}
+			iw.fields = make(map[string]*querypb.Field, len(qr.Fields))
+			for _, field := range qr.Fields {
+				iw.fields[strings.ToLower(field.Name)] = field
+			}
+		}
+	}
+	iw.wr.Logger().Infof("NOTE: The used master of a shard might change over the course of the import e.g. due to a reparent. The HealthCheck module will track and log master changes and any error message will always refer the actually used master address.")
+
+	return iw.createThrottlers()
+}
+
+// importFiles phase:
+// - read the files one by one, and send the rows to the shard writers
+func (iw *ImportWorker) importFiles(ctx context.Context) error {
+	iw.SetState(WorkerStateCloneOnline)
+
+	// mu protects the context for cancelation, and firstError
+	mu := sync.Mutex{}
+	var firstError error
+
+	ctx, cancelImport := context.WithCancel(ctx)
+	defer cancelImport()
+	processError := func(format string, args ...interface{}) {
+		iw.wr.Logger().Errorf(format, args...)
+		mu.Lock()
+		if firstError == nil {
+			firstError = fmt.Errorf(format, args...)
+			cancelImport()
+		}
+		mu.Unlock()
+	}
+
+	batchChannels := make([]chan *importBatch, len(iw.shards))
+	writersWaitGroup := sync.WaitGroup{}
+	for shardIndex, si := range iw.shards {
+		batchChannels[shardIndex] = make(chan *importBatch, iw.config.WriterCount*2)
+		for j := 0; j < iw.config.WriterCount; j++ {
+			writersWaitGroup.Add(1)
+			go func(keyspace, shard string, batchChannel chan *importBatch, throttler *throttler.Throttler, threadID int) {
+				defer writersWaitGroup.Done()
+				defer throttler.ThreadFinished(threadID)
+
+				executor := newExecutor(iw.wr, iw.tsc, throttler, keyspace, shard, threadID)
+				if err := iw.writeLoop(ctx, executor, batchChannel); err != nil {
+					processError("writing to %v/%v failed: %v", keyspace, shard, err)
+				}
+			}(si.Keyspace(), si.ShardName(), batchChannels[shardIndex], iw.getThrottler(shardIndex), j)
+		}
+	}
+
+	for _, file := range iw.config.Files {
+		if err := iw.importFile(ctx, file, batchChannels); err != nil {
+			processError("cannot import file %v: %v", file, err)
+			break
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+
+	for _, c := range batchChannels {
+		close(c)
+	}
+	writersWaitGroup.Wait()
+
+	// The writers stop at the first error, the batches they did not
+	// read were not inserted.
+	for _, c := range batchChannels {
+		for batch := range c {
+			iw.deleteBatchLookupEntries(batch, 0)
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	return firstError
+}
+
+// importFile reads the rows of a file, and sends them in batches to
+// the writers of their shard.
+func (iw *ImportWorker) importFile(ctx context.Context, file string, batchChannels []chan *importBatch) (err error) {
+	// batches are the batches that are not sent yet.
+	batches := make([]*importBatch, len(iw.shards))
+	defer func() {
+		if err != nil {
+			for _, batch := range batches {
+				if batch != nil {
+					iw.deleteBatchLookupEntries(batch, 0)
+				}
+			}
+		}
+	}()
+
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var reader importReader
+	switch iw.config.Format {
+	case ExportFormatCSV:
+		reader, err = newCSVImportReader(f, iw.fields)
+	case ExportFormatJSON:
+		reader, err = newJSONImportReader(f, iw.fields)
+	}
+	if err != nil {
+		return err
+	}
+	fields := reader.fields()
+	iw.wr.Logger().Infof("Importing file %v, with columns %v", file, importFieldNames(fields))
+
+	primaryIndex := -1
+	ownedIndexes := make([]int, 0)
+	if iw.table != nil {
+		primaryIndex = importFieldIndex(fields, iw.table.ColumnVindexes[0].Column.Original())
+		if primaryIndex == -1 {
+			return fmt.Errorf("the file has no column %v for the primary vindex of table %v", iw.table.ColumnVindexes[0].Column, iw.config.Table)
+		}
+		for _, cv := range iw.table.Owned {
+			index := importFieldIndex(fields, cv.Column.Original())
+			if index == -1 {
+				return fmt.Errorf("the file has no column %v for the owned vindex %v", cv.Column, cv.Name)
+			}
+			ownedIndexes = append(ownedIndexes, index)
+		}
+	}
+
+	columns := make([]string, len(fields))
+	for i, field := range fields {
+		columns[i] = field.Name
+	}
+	newBatch := func(shardIndex int) *importBatch {
+		return &importBatch{
+			insertPrefix: fmt.Sprintf("INSERT INTO %v.%v (%v) VALUES ", escape(iw.dbNames[shardIndex]), escape(iw.config.Table), strings.Join(escapeAll(columns), ", ")),
+			fields:       fields,
+			ownedIndexes: ownedIndexes,
+		}
+	}
+	sendBatch := func(shardIndex int) error {
+		select {
+		case batchChannels[shardIndex] <- batches[shardIndex]:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		batches[shardIndex] = nil
+		return nil
+	}
+
+	for {
+		row, ref, err := reader.next()
+		if err == io.EOF {
+			break
+		}
+		ref.file = file
+		if err != nil {
+			if _, ok := err.(importRowError); !ok {
+				return fmt.Errorf("line %v: %v", ref.line, err)
+			}
+			iw.rowsRead.Add(1)
+			iw.reject(ref, err)
+			continue
+		}
+		iw.rowsRead.Add(1)
+
+		shardIndex, ksid, err := iw.route(row, primaryIndex, ownedIndexes)
+		if err != nil {
+			iw.reject(ref, err)
+			continue
+		}
+		if batches[shardIndex] == nil {
+			batches[shardIndex] = newBatch(shardIndex)
+		}
+		batches[shardIndex].rows = append(batches[shardIndex].rows, row)
+		batches[shardIndex].refs = append(batches[shardIndex].refs, ref)
+		batches[shardIndex].ksids = append(batches[shardIndex].ksids, ksid)
+		if len(batches[shardIndex].rows) == iw.config.BatchSize {
+			if err := sendBatch(shardIndex); err != nil {
+				return err
+			}
+		}
+	}
+	for shardIndex, batch := range batches {
+		if batch != nil {
+			if err := sendBatch(shardIndex); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// route returns the shard and the keyspace id of a row. It also
+// creates the entries of the lookup vindexes owned by the table, as
+// vtgate does for an insert. If it fails, no entries are left behind.
+func (iw *ImportWorker) route(row []sqltypes.Value, primaryIndex int, ownedIndexes []int) (int, []byte, error) {
+	if iw.table == nil {
+		return 0, nil, nil
+	}
+	var cursor vindexes.VCursor
+	if iw.vcursor != nil {
+		cursor = iw.vcursor
+	}
+	ksids, err := iw.primaryVindex.Map(cursor, []interface{}{row[primaryIndex]})
+	if err != nil {
+		return 0, nil, fmt.Errorf("cannot map %v to a keyspace id with vindex %v: %v", row[primaryIndex], iw.table.ColumnVindexes[0].Name, err)
+	}
+	if len(ksids) != 1 || len(ksids[0]) == 0 {
+		return 0, nil, fmt.Errorf("vindex %v returned no keyspace id for %v", iw.table.ColumnVindexes[0].Name, row[primaryIndex])
+	}
+	ksid := ksids[0]
+	shardIndex := -1
+	for i, si := range iw.shards {
+		if key.KeyRangeContains(si.KeyRange, ksid) {
+			shardIndex = i
+			break
+		}
+	}
+	if shardIndex == -1 {
+		return 0, nil, fmt.Errorf("no shard for keyspace id %x", ksid)
+	}
+
+	for i, cv := range iw.table.Owned {
+		value := row[ownedIndexes[i]]
+		if value.IsNull() {
+			continue
+		}
+		var err error
+		if lookup, ok := cv.Vindex.(vindexes.Lookup); !ok {
+			err = fmt.Errorf("owned vindex %v is not a lookup vindex", cv.Name)
+		} else if err = lookup.Create(cursor, value, ksid); err != nil {
+			err = fmt.Errorf("cannot create entry for %v in vindex %v: %v", value, cv.Name, err)
+		}
+		if err != nil {
+			if derr := iw.deleteLookupEntries(row, ownedIndexes[:i], ksid); derr != nil {
+				err = fmt.Errorf("%v, and cannot delete the entries created for the row: %v", err, derr)
+			}
+			return 0, nil, err
+		}
+	}
+	return shardIndex, ksid, nil
+}
+
+// deleteLookupEntries deletes the entries of the lookup vindexes owned
+// by the table for a row, as created by route. ownedIndexes may only
+// have the columns of the first owned vindexes.
+func (iw *ImportWorker) deleteLookupEntries(row []sqltypes.Value, ownedIndexes []int, ksid []byte) error {
+	var cursor vindexes.VCursor
+	if iw.vcursor != nil {
+		cursor = iw.vcursor
+	}
+	rec := concurrency.AllErrorRecorder{}
+	for i, index := range ownedIndexes {
+		value := row[index]
+		if value.IsNull() {
+			continue
+		}
+		cv := iw.table.Owned[i]
+		lookup, ok := cv.Vindex.(vindexes.Lookup)
+		if !ok {
+			continue
+		}
+		if err := lookup.Delete(cursor, []interface{}{value}, ksid); err != nil {
+			rec.RecordError(fmt.Errorf("cannot delete entry for %v in vindex %v: %v", value, cv.Name, err))
+		}
+	}
+	return rec.Error()
+}
+
+// deleteBatchLookupEntries deletes the lookup vindex entries of the
+// rows of a batch that were not inserted, starting at row from. The
+// entries that cannot be deleted are logged.
+func (iw *ImportWorker) deleteBatchLookupEntries(batch *importBatch, from int) {
+	if iw.table == nil || len(iw.table.Owned) == 0 {
+		return
+	}
+	for i := from; i < len(batch.rows); i++ {
+		if err := iw.deleteLookupEntries(batch.rows[i], batch.ownedIndexes, batch.ksids[i]); err != nil {
+			iw.wr.Logger().Errorf("Row %v:%v was not inserted, but its lookup vindex entries are left behind: %v", batch.refs[i].file, batch.refs[i].line, err)
+		}
+	}
+}
+
+// writeLoop inserts the batches it receives. If a batch fails with a
+// MySQL error, its rows are inserted one by one, and the failing rows
+// are rejected. If it fails with another error, the batch may have been
+// inserted: its lookup vindex entries are kept, but those of the rows
+// that were not sent are deleted.
+func (iw *ImportWorker) writeLoop(ctx context.Context, e *executor, batchChannel chan *importBatch) error {
+	for {
+		select {
+		case batch, ok := <-batchChannel:
+			if !ok {
+				// no more to read, we're done
+				return nil
+			}
+			err := e.fetchWithRetries(ctx, batch.insertPrefix+makeValueString(batch.fields, batch.rows))
+			if err == nil {
+				iw.rowsImported.Add(int64(len(batch.rows)))
+				continue
+			}
+			if !errExtract.MatchString(err.Error()) {
+				return err
+			}
+			for i, row := range batch.rows {
+				err := e.fetchWithRetries(ctx, batch.insertPrefix+makeValueString(batch.fields, [][]sqltypes.Value{row}))
+				if err == nil {
+					iw.rowsImported.Add(1)
+					continue
+				}
+				if !errExtract.MatchString(err.Error()) {
+					iw.deleteBatchLookupEntries(batch, i+1)
+					return err
+				}
+				if derr := iw.deleteLookupEntries(row, batch.ownedIndexes, batch.ksids[i]); derr != nil {
+					err = fmt.Errorf("%v, and its lookup vindex entries are left behind: %v", err, derr)
+				}
+				iw.reject(batch.refs[i], err)
+			}
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// reject reports a rejected row.
+func (iw *ImportWorker) reject(ref importRowRef, err error) {
+	iw.rowsRejected.Add(1)
+
+	iw.rejectedMu.Lock()
+	defer iw.rejectedMu.Unlock()
+	if iw.rejected == nil {
+		iw.wr.Logger().Warningf("Rejected row %v:%v %v: %v", ref.file, ref.line, ref.record, err)
+		return
+	}
+	if werr := iw.rejected.Encode(&importRejectedRow{
+		File:   ref.file,
+		Line:   ref.line,
+		Error:  err.Error(),
+		Record: ref.record,
+	}); werr != nil {
+		iw.wr.Logger().Errorf("cannot write rejected row %v:%v to %v: %v", ref.file, ref.line, iw.config.RejectedRowsFile, werr)
+	}
+}
+
+// StatsUpdate receives replication lag updates for each master
+// and forwards them to the respective throttler instance.
+// It is part of the discovery.HealthCheckStatsListener interface.
+func (iw *ImportWorker) StatsUpdate(ts *discovery.TabletStats) {
+	iw.tsc.StatsUpdate(ts)
+
+	// Ignore unless REPLICA or RDONLY.
+	if ts.Target.TabletType != topodatapb.TabletType_REPLICA && ts.Target.TabletType != topodatapb.TabletType_RDONLY {
+		return
+	}
+
+	iw.throttlersMu.Lock()
+	defer iw.throttlersMu.Unlock()
+	for i, si := range iw.shards {
+		if i < len(iw.throttlers) && si.Keyspace() == ts.Target.Keyspace && si.ShardName() == ts.Target.Shard {
+			iw.throttlers[i].RecordReplicationLag(time.Now(), ts)
+		}
+	}
+}
+
+func (iw *ImportWorker) createThrottlers() error {
+	iw.throttlersMu.Lock()
+	defer iw.throttlersMu.Unlock()
+
+	for _, si := range iw.shards {
+		keyspaceAndShard := topoproto.KeyspaceShardString(si.Keyspace(), si.ShardName())
+		t, err := throttler.NewThrottler(keyspaceAndShard, "transactions", iw.config.WriterCount, iw.config.MaxTPS, iw.config.MaxReplicationLag)
+		if err != nil {
+			return fmt.Errorf("cannot instantiate throttler: %v", err)
+		}
+		iw.throttlers = append(iw.throttlers, t)
+	}
+	return nil
+}
+
+func (iw *ImportWorker) getThrottler(shardIndex int) *throttler.Throttler {
+	iw.throttlersMu.Lock()
+	defer iw.throttlersMu.Unlock()
+
+	return iw.throttlers[shardIndex]
+}
+
+func (iw *ImportWorker) closeThrottlers() {
+	iw.throttlersMu.Lock()
+	defer iw.throttlersMu.Unlock()
+
+	for _, t := range iw.throttlers {
+		t.Close()
+	}
+	iw.throttlers = nil
+}
+
+// importRowError is returned by an importReader for a row that
+// cannot be parsed. The row is rejected, and the import goes on.
+type importRowError struct {
+	error
+}
+
+// importReader reads the rows of an import file.
+type importReader interface {
+	// fields returns the columns of the rows, in row order.
+	fields() []*querypb.Field
+	// next returns the next row and its location, or io.EOF.
+	// An importRowError rejects the row, other errors abort the import.
+	next() ([]sqltypes.Value, importRowRef, error)
+}
+
+// csvImportReader reads a CSV file with a header row, as written by
+// Export.
+type csvImportReader struct {
+	r      *csv.Reader
+	f      []*querypb.Field
+	line   int
+	record []string
+}
+
+func newCSVImportReader(r io.Reader, fields map[string]*querypb.Field) (*csvImportReader, error) {
+	cr := &csvImportReader{
+		r: csv.NewReader(r),
+	}
+	header, err := cr.r.Read()
+	if err != nil {
+		return nil, fmt.Errorf("cannot read header row: %v", err)
+	}
+	cr.line = 1
+	for _, name := range header {
+		field, ok := fields[strings.ToLower(name)]
+		if !ok {
+			return nil, fmt.Errorf("unknown column %v in header row", name)
+		}
+		cr.f = append(cr.f, field)
+	}
+	return cr, nil
+}
+
+func (cr *csvImportReader) fields() []*querypb.Field {
+	return cr.f
+}
+
+func (cr *csvImportReader) next() ([]sqltypes.Value, importRowRef, error) {
+	record, err := cr.r.Read()
+	cr.line++
+	ref := importRowRef{
+		line:   cr.line,
+		record: record,
+	}
+	if err == io.EOF {
+		return nil, ref, err
+	}
+	if err != nil {
+		if perr, ok := err.(*csv.ParseError); ok {
+			ref.line = perr.Line
+			return nil, ref, importRowError{err}
+		}
+		return nil, ref, err
+	}
+	row := make([]sqltypes.Value, len(record))
+	for i, s := range record {
+		s, ok := unescapeCSVValue(s)
+		if !ok {
+			row[i] = sqltypes.NULL
+			continue
+		}
+		row[i], err = sqltypes.ValueFromBytes(cr.f[i].Type, []byte(s))
+		if err != nil {
+			return nil, ref, importRowError{fmt.Errorf("invalid value for column %v: %v", cr.f[i].Name, err)}
+		}
+	}
+	return row, ref, nil
+}
+
+// jsonImportReader reads a newline-delimited JSON file, as written by
+// Export. The columns are the keys of the first row, and all rows must
+// have the same keys.
+type jsonImportReader struct {
+	s       *bufio.Scanner
+	f       []*querypb.Field
+	line    int
+	pending []byte
+}
+
+// jsonImportMaxLineSize is the maximum size of a row in a JSON file.
+const jsonImportMaxLineSize = 64 * 1024 * 1024
+
+func newJSONImportReader(r io.Reader, fields map[string]*querypb.Field) (*jsonImportReader, error) {
+	jr := &jsonImportReader{
+		s: bufio.NewScanner(r),
+	}
+	jr.s.Buffer(nil, jsonImportMaxLineSize)
+	if !jr.s.Scan() {
+		if err := jr.s.Err(); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("empty file")
+	}
+	jr.pending = jr.s.Bytes()
+	var first map[string]json.RawMessage
+	if err := json.Unmarshal(jr.pending, &first); err != nil {
+		return nil, fmt.Errorf("cannot read the columns from the first row: %v", err)
+	}
+	for name := range first {
+		field, ok := fields[strings.ToLower(name)]
+		if !ok {
+			return nil, fmt.Errorf("unknown column %v in the first row", name)
+		}
+		jr.f = append(jr.f, field)
+	}
+	sort.Sort(fieldsByName(jr.f))
+	return jr, nil
+}
+
+// fieldsByName sorts fields by name, so the columns of a JSON file
+// are in a deterministic order.
+type fieldsByName []*querypb.Field
+
+func (l fieldsByName) Len() int           { return len(l) }
+func (l fieldsByName) Swap(i, j int)      { l[i], l[j] = l[j], l[i] }
+func (l fieldsByName) Less(i, j int) bool { return l[i].Name < l[j].Name }
+
+func (jr *jsonImportReader) fields() []*querypb.Field {
+	return jr.f
+}
+
+func (jr *jsonImportReader) next() ([]sqltypes.Value, importRowRef, error) {
+	var data []byte
+	if jr.pending != nil {
+		data = jr.pending
+		jr.pending = nil
+	} else {
+		if !jr.s.Scan() {
+			if err := jr.s.Err(); err != nil {
+				return nil, importRowRef{line: jr.line + 1}, err
+			}
+			return nil, importRowRef{line: jr.line + 1}, io.EOF
+		}
+		data = jr.s.Bytes()
+	}
+	jr.line++
+	ref := importRowRef{
+		line:   jr.line,
+		record: string(data),
+	}
+
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(data, &object); err != nil {
+		return nil, ref, importRowError{err}
+	}
+	// The raw object is more readable in the report than a string.
+	ref.record = json.RawMessage(append([]byte(nil), data...))
+	if len(object) != len(jr.f) {
+		return nil, ref, importRowError{fmt.Errorf("row has %v columns, expected %v", len(object), len(jr.f))}
+	}
+	row := make([]sqltypes.Value, len(jr.f))
+	for i, field := range jr.f {
+		raw, ok := object[field.Name]
+		if !ok {
+			return nil, ref, importRowError{fmt.Errorf("row has no column %v", field.Name)}
+		}
+		v, err := jsonImportValue(field, raw)
+		if err != nil {
+			return nil, ref, importRowError{fmt.Errorf("invalid value for column %v: %v", field.Name, err)}
+		}
+		row[i] = v
+	}
+	return row, ref, nil
+}
+
+// jsonImportValue converts a JSON value into a value of the type of
+// the field. Binary columns are base64 encoded.
+func jsonImportValue(field *querypb.Field, raw json.RawMessage) (sqltypes.Value, error) {
+	s := string(raw)
+	switch {
+	case s == "null":
+		return sqltypes.NULL, nil
+	case s == "true":
+		return sqltypes.ValueFromBytes(field.Type, []byte("1"))
+	case s == "false":
+		return sqltypes.ValueFromBytes(field.Type, []byte("0"))
+	case strings.HasPrefix(s, "\""):
+		var str string
+		if err := json.Unmarshal(raw, &str); err != nil {
+			return sqltypes.NULL, err
+		}
+		if sqltypes.IsBinary(field.Type) {
+			data, err := base64.StdEncoding.DecodeString(str)
+			if err != nil {
+				return sqltypes.NULL, fmt.Errorf("binary values must be base64 encoded: %v", err)
+			}
+			return sqltypes.ValueFromBytes(field.Type, data)
+		}
+		return sqltypes.ValueFromBytes(field.Type, []byte(str))
+	case strings.HasPrefix(s, "{") || strings.HasPrefix(s, "["):
+		return sqltypes.NULL, fmt.Errorf("objects and arrays are not supported")
+	}
+	// A number.
+	return sqltypes.ValueFromBytes(field.Type, raw)
+}
+
+// importFieldIndex returns the index of a column in fields, or -1.
+func importFieldIndex(fields []*querypb.Field, name string) int {
+	for i, field := range fields {
+		if strings.EqualFold(field.Name, name) {
+			return i
+		}
+	}
+	return -1
+}
+
+// importFieldNames returns the names of fields.
+func importFieldNames(fields []*querypb.Field) []string {
+	names := make([]string, len(fields))
+	for i, field := range fields {
+		names[i] = field.Name
+	}
+	return names
+}
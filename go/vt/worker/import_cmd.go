@@ -0,0 +1,164 @@
+// Copyright 2016, Google Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package worker
+
+import (
+	"flag"
+	"fmt"
+	"html/template"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/context"
+
+	"github.com/youtube/vitess/go/vt/wrangler"
+)
+
+const importHTML = `
+<!DOCTYPE html>
+<head>
+  <title>Import Action</title>
+</head>
+<body>
+  <h1>Import Action</h1>
+
+    {{if .Error}}
+      <b>Error:</b> {{.Error}}</br>
+    {{else}}
+    <form action="/Clones/Import" method="post">
+      <LABEL for="keyspace">Keyspace: </LABEL>
+        <INPUT type="text" id="keyspace" name="keyspace" value=""></BR>
+      <LABEL for="table">Table: </LABEL>
+        <INPUT type="text" id="table" name="table" value=""></BR>
+      <LABEL for="files">Files (comma separated paths on the vtworker host): </LABEL>
+        <INPUT type="text" id="files" name="files" value=""></BR>
+      <LABEL for="format">Format (csv or json): </LABEL>
+        <INPUT type="text" id="format" name="format" value="{{.DefaultFormat}}"></BR>
+      <LABEL for="batchSize">Batch Size: </LABEL>
+        <INPUT type="text" id="batchSize" name="batchSize" value="{{.DefaultBatchSize}}"></BR>
+      <LABEL for="writerCount">Writer Count per shard: </LABEL>
+        <INPUT type="text" id="writerCount" name="writerCount" value="{{.DefaultWriterCount}}"></BR>
+      <LABEL for="maxTPS">Maximum Write Transactions/second (If non-zero, writes on the destination will be throttled. Unlimited by default.): </LABEL>
+        <INPUT type="text" id="maxTPS" name="maxTPS" value="{{.DefaultMaxTPS}}"></BR>
+      <LABEL for="maxReplicationLag">Maximum Replication Lag (enables the adapative throttler. Disabled by default.): </LABEL>
+        <INPUT type="text" id="maxReplicationLag" name="maxReplicationLag" value="{{.DefaultMaxReplicationLag}}"></BR>
+      <LABEL for="vtgateServer">vtgate address (required if the table owns lookup vindexes): </LABEL>
+        <INPUT type="text" id="vtgateServer" name="vtgateServer" value=""></BR>
+      <LABEL for="rejectedRowsFile">Rejected rows file (empty to only log them): </LABEL>
+        <INPUT type="text" id="rejectedRowsFile" name="rejectedRowsFile" value=""></BR>
+      <INPUT type="submit" name="submit" value="Import"/>
+    </form>
+    {{end}}
+</body>
+`
+
+var importTemplate = mustParseTemplate("import", importHTML)
+
+const defaultImportBatchSize = 100
+
+func commandImport(wi *Instance, wr *wrangler.Wrangler, subFlags *flag.FlagSet, args []string) (Worker, error) {
+	table := subFlags.String("table", "", "table the rows are inserted into")
+	format := subFlags.String("format", ExportFormatCSV, "format of the files: csv with a header row, or json for newline-delimited JSON, as written by Export")
+	batchSize := subFlags.Int("batch_size", defaultImportBatchSize, "maximum number of rows per INSERT")
+	writerCount := subFlags.Int("writer_count", defaultDestinationWriterCount, "number of concurrent writers per shard")
+	maxTPS := subFlags.Int64("max_tps", defaultMaxTPS, "rate limit of maximum number of (write) transactions/second on each shard (unlimited by default)")
+	maxReplicationLag := subFlags.Int64("max_replication_lag", defaultMaxReplicationLag, "if set, the adapative throttler will be enabled and automatically adjust the write rate to keep the lag below the set value (disabled by default)")
+	vtgateServer := subFlags.String("vtgate_server", "", "vtgate used to create the entries of the lookup vindexes owned by the table")
+	vtgateConnectTimeout := subFlags.Duration("vtgate_connect_timeout", defaultVtgateConnectTimeout, "timeout to connect to vtgate")
+	rejectedRowsFile := subFlags.String("rejected_rows_file", "", "if set, the rejected rows are written to this file, one JSON object per row")
+	if err := subFlags.Parse(args); err != nil {
+		return nil, err
+	}
+	if subFlags.NArg() < 2 {
+		subFlags.Usage()
+		return nil, fmt.Errorf("command Import requires <keyspace> <file> [<file>...]")
+	}
+
+	worker, err := NewImportWorker(wr, wi.cell, subFlags.Arg(0), ImportConfig{
+		Table:                *table,
+		Format:               *format,
+		Files:                subFlags.Args()[1:],
+		BatchSize:            *batchSize,
+		WriterCount:          *writerCount,
+		MaxTPS:               *maxTPS,
+		MaxReplicationLag:    *maxReplicationLag,
+		VtgateServer:         *vtgateServer,
+		VtgateConnectTimeout: *vtgateConnectTimeout,
+		RejectedRowsFile:     *rejectedRowsFile,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("cannot create Import worker: %v", err)
+	}
+	return worker, nil
+}
+
+func interactiveImport(ctx context.Context, wi *Instance, wr *wrangler.Wrangler, w http.ResponseWriter, r *http.Request) (Worker, *template.Template, map[string]interface{}, error) {
+	if err := r.ParseForm(); err != nil {
+		return nil, nil, nil, fmt.Errorf("cannot parse form: %s", err)
+	}
+
+	if submit := r.FormValue("submit"); submit == "" {
+		// display the input form
+		result := make(map[string]interface{})
+		result["DefaultFormat"] = ExportFormatCSV
+		result["DefaultBatchSize"] = fmt.Sprintf("%v", defaultImportBatchSize)
+		result["DefaultWriterCount"] = fmt.Sprintf("%v", defaultDestinationWriterCount)
+		result["DefaultMaxTPS"] = fmt.Sprintf("%v", defaultMaxTPS)
+		result["DefaultMaxReplicationLag"] = fmt.Sprintf("%v", defaultMaxReplicationLag)
+		return nil, importTemplate, result, nil
+	}
+
+	// Process input form.
+	keyspace := r.FormValue("keyspace")
+	if keyspace == "" {
+		return nil, nil, nil, fmt.Errorf("keyspace is required")
+	}
+	var files []string
+	if f := r.FormValue("files"); f != "" {
+		files = strings.Split(f, ",")
+	}
+	batchSize, err := strconv.ParseInt(r.FormValue("batchSize"), 0, 64)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("cannot parse batchSize: %s", err)
+	}
+	writerCount, err := strconv.ParseInt(r.FormValue("writerCount"), 0, 64)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("cannot parse writerCount: %s", err)
+	}
+	maxTPS, err := strconv.ParseInt(r.FormValue("maxTPS"), 0, 64)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("cannot parse maxTPS: %s", err)
+	}
+	maxReplicationLag, err := strconv.ParseInt(r.FormValue("maxReplicationLag"), 0, 64)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("cannot parse maxReplicationLag: %s", err)
+	}
+
+	// start the import job
+	wrk, err := NewImportWorker(wr, wi.cell, keyspace, ImportConfig{
+		Table:                r.FormValue("table"),
+		Format:               r.FormValue("format"),
+		Files:                files,
+		BatchSize:            int(batchSize),
+		WriterCount:          int(writerCount),
+		MaxTPS:               maxTPS,
+		MaxReplicationLag:    maxReplicationLag,
+		VtgateServer:         r.FormValue("vtgateServer"),
+		VtgateConnectTimeout: defaultVtgateConnectTimeout,
+		RejectedRowsFile:     r.FormValue("rejectedRowsFile"),
+	})
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("cannot create Import worker: %v", err)
+	}
+	return wrk, nil, nil, nil
+}
+
+func init() {
+	AddCommand("Clones", Command{"Import",
+		commandImport, interactiveImport,
+		"--table=<table> [--format=csv|json] [--batch_size=100] [--writer_count=20] [--max_tps=-1] [--max_replication_lag=-1] [--vtgate_server=''] [--rejected_rows_file=''] <keyspace> <file> [<file>...]",
+		"Imports CSV or newline-delimited JSON files, local to vtworker, into a table of a keyspace: each row is routed to its shard with the primary vindex of the table and inserted in batches on the shard master, after the entries of the lookup vindexes owned by the table are created through vtgate. Rows that cannot be imported are rejected and reported"})
+}
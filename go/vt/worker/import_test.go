@@ -0,0 +1,410 @@
+// Copyright 2016, Google Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package worker
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/youtube/vitess/go/cistring"
+	"github.com/youtube/vitess/go/sqltypes"
+	"github.com/youtube/vitess/go/vt/dbconnpool"
+	"github.com/youtube/vitess/go/vt/logutil"
+	"github.com/youtube/vitess/go/vt/tabletserver/grpcqueryservice"
+	"github.com/youtube/vitess/go/vt/tabletserver/queryservice/fakes"
+	"github.com/youtube/vitess/go/vt/topo"
+	"github.com/youtube/vitess/go/vt/vtgate/fakerpcvtgateconn"
+	"github.com/youtube/vitess/go/vt/vtgate/vindexes"
+	"github.com/youtube/vitess/go/vt/vtgate/vtgateconn"
+	"github.com/youtube/vitess/go/vt/vttest/fakesqldb"
+	"github.com/youtube/vitess/go/vt/wrangler"
+	"github.com/youtube/vitess/go/vt/wrangler/testlib"
+	"github.com/youtube/vitess/go/vt/zktopo/zktestserver"
+	"golang.org/x/net/context"
+
+	querypb "github.com/youtube/vitess/go/vt/proto/query"
+	topodatapb "github.com/youtube/vitess/go/vt/proto/topodata"
+	vschemapb "github.com/youtube/vitess/go/vt/proto/vschema"
+)
+
+func TestImportReader(t *testing.T) {
+	fields := map[string]*querypb.Field{
+		"id":    {Name: "id", Type: sqltypes.Int64},
+		"name":  {Name: "name", Type: sqltypes.VarChar},
+		"data":  {Name: "data", Type: sqltypes.VarBinary},
+		"price": {Name: "price", Type: sqltypes.Float64},
+	}
+	wantRows := [][]sqltypes.Value{
+		{
+			sqltypes.MakeTrusted(sqltypes.VarBinary, []byte{0, 255}),
+			sqltypes.MakeTrusted(sqltypes.Int64, []byte("1")),
+			sqltypes.MakeTrusted(sqltypes.VarChar, []byte("a \"quoted\", name")),
+		},
+		{
+			sqltypes.NULL,
+			sqltypes.MakeTrusted(sqltypes.Int64, []byte("3")),
+			sqltypes.MakeTrusted(sqltypes.VarChar, []byte("")),
+		},
+	}
+
+	testcases := []struct {
+		format string
+		input  string
+	}{{
+		format: ExportFormatCSV,
+		input: "data,id,name\n" +
+			"\x00\xff,1,\"a \"\"quoted\"\", name\"\n" +
+			",x,bad id\n" +
+			"\\N,3,\n",
+	}, {
+		format: ExportFormatJSON,
+		input: `{"id":1,"name":"a \"quoted\", name","data":"AP8="}` + "\n" +
+			`{"id":"x","name":"bad id","data":null}` + "\n" +
+			`{"id":3,"name":"","data":null}` + "\n",
+	}}
+	for _, tcase := range testcases {
+		var reader importReader
+		var err error
+		if tcase.format == ExportFormatCSV {
+			reader, err = newCSVImportReader(strings.NewReader(tcase.input), fields)
+		} else {
+			reader, err = newJSONImportReader(strings.NewReader(tcase.input), fields)
+		}
+		if err != nil {
+			t.Fatalf("%v reader failed: %v", tcase.format, err)
+		}
+		if got, want := importFieldNames(reader.fields()), []string{"data", "id", "name"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("%v columns: %v, want: %v", tcase.format, got, want)
+		}
+
+		var rows [][]sqltypes.Value
+		var rejectedLines []int
+		for {
+			row, ref, err := reader.next()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				if _, ok := err.(importRowError); !ok {
+					t.Fatalf("%v next() failed: %v", tcase.format, err)
+				}
+				rejectedLines = append(rejectedLines, ref.line)
+				continue
+			}
+			rows = append(rows, row)
+		}
+		if !reflect.DeepEqual(rows, wantRows) {
+			t.Errorf("%v rows:\n%v\nwant:\n%v", tcase.format, rows, wantRows)
+		}
+		// The CSV file has a header row.
+		wantRejected := []int{2}
+		if tcase.format == ExportFormatCSV {
+			wantRejected = []int{3}
+		}
+		if !reflect.DeepEqual(rejectedLines, wantRejected) {
+			t.Errorf("%v rejected lines: %v, want: %v", tcase.format, rejectedLines, wantRejected)
+		}
+	}
+
+	if _, err := newCSVImportReader(strings.NewReader("id,unknown\n"), fields); err == nil {
+		t.Errorf("newCSVImportReader() with an unknown column worked")
+	}
+}
+
+// fakeLookup is a lookup vindex which keeps its entries in memory.
+// Its lookup table is "lookup", with the columns "from" and "to",
+// and "to" stores the keyspace id.
+type fakeLookup struct {
+	name string
+	// failCreate makes Create fail for this value.
+	failCreate string
+
+	// mu protects entries.
+	mu      sync.Mutex
+	entries map[string]bool
+}
+
+func newFakeLookup(name string) *fakeLookup {
+	return &fakeLookup{name: name, entries: make(map[string]bool)}
+}
+
+func (fl *fakeLookup) String() string { return fl.name }
+func (fl *fakeLookup) Cost() int      { return 2 }
+
+func (fl *fakeLookup) Map(cursor vindexes.VCursor, ids []interface{}) ([][][]byte, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (fl *fakeLookup) Verify(cursor vindexes.VCursor, id interface{}, ks []byte) (bool, error) {
+	fl.mu.Lock()
+	defer fl.mu.Unlock()
+	return fl.entries[fmt.Sprintf("%v:%x", id, ks)], nil
+}
+
+func (fl *fakeLookup) Create(cursor vindexes.VCursor, id interface{}, ks []byte) error {
+	if fmt.Sprintf("%v", id) == fl.failCreate {
+		return errors.New("duplicate entry")
+	}
+	fl.mu.Lock()
+	defer fl.mu.Unlock()
+	fl.entries[fmt.Sprintf("%v:%x", id, ks)] = true
+	return nil
+}
+
+func (fl *fakeLookup) Delete(cursor vindexes.VCursor, ids []interface{}, ks []byte) error {
+	fl.mu.Lock()
+	defer fl.mu.Unlock()
+	for _, id := range ids {
+		delete(fl.entries, fmt.Sprintf("%v:%x", id, ks))
+	}
+	return nil
+}
+
+func (fl *fakeLookup) LookupColumns() (table, from, to string) {
+	return "lookup", "from", "to"
+}
+
+func (fl *fakeLookup) KeyspaceID(to sqltypes.Value) ([]byte, error) {
+	return to.Raw(), nil
+}
+
+func TestImportLookupEntries(t *testing.T) {
+	primary, err := vindexes.CreateVindex("numeric", "numeric", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lookup1 := newFakeLookup("lookup1")
+	lookup2 := newFakeLookup("lookup2")
+	lookup2.failCreate = "b2"
+	owned := []*vindexes.ColumnVindex{
+		{Column: cistring.New("c1"), Name: "lookup1", Owned: true, Vindex: lookup1},
+		{Column: cistring.New("c2"), Name: "lookup2", Owned: true, Vindex: lookup2},
+	}
+	iw := &ImportWorker{
+		wr:     wrangler.New(logutil.NewConsoleLogger(), topo.Server{}, nil),
+		shards: []*topo.ShardInfo{topo.NewShardInfo("ks", "0", &topodatapb.Shard{}, 0)},
+		table: &vindexes.Table{
+			ColumnVindexes: append([]*vindexes.ColumnVindex{{Column: cistring.New("id"), Name: "numeric", Vindex: primary}}, owned...),
+			Owned:          owned,
+		},
+		primaryVindex: primary.(vindexes.Unique),
+	}
+	ownedIndexes := []int{1, 2}
+	makeRow := func(id, c1, c2 string) []sqltypes.Value {
+		row := []sqltypes.Value{sqltypes.MakeTrusted(sqltypes.Int64, []byte(id)), sqltypes.NULL, sqltypes.NULL}
+		if c1 != "" {
+			row[1] = sqltypes.MakeString([]byte(c1))
+		}
+		if c2 != "" {
+			row[2] = sqltypes.MakeString([]byte(c2))
+		}
+		return row
+	}
+
+	batch := &importBatch{ownedIndexes: ownedIndexes}
+	for _, row := range [][]sqltypes.Value{
+		makeRow("1", "a1", "b1"),
+		makeRow("2", "a2", ""),
+		makeRow("3", "a3", "b3"),
+	} {
+		_, ksid, err := iw.route(row, 0, ownedIndexes)
+		if err != nil {
+			t.Fatalf("route(%v) failed: %v", row, err)
+		}
+		batch.rows = append(batch.rows, row)
+		batch.refs = append(batch.refs, importRowRef{})
+		batch.ksids = append(batch.ksids, ksid)
+	}
+	// The first owned vindex entry of a row is deleted if the second
+	// one cannot be created.
+	if _, _, err := iw.route(makeRow("4", "a4", "b2"), 0, ownedIndexes); err == nil {
+		t.Errorf("route() worked with a failing lookup vindex")
+	}
+	if got, want := len(lookup1.entries), 3; got != want {
+		t.Errorf("lookup1 has %v entries after route(), want %v: %v", got, want, lookup1.entries)
+	}
+	if got, want := len(lookup2.entries), 2; got != want {
+		t.Errorf("lookup2 has %v entries after route(), want %v: %v", got, want, lookup2.entries)
+	}
+
+	// The rows after the first one were not inserted.
+	iw.deleteBatchLookupEntries(batch, 1)
+	want1 := map[string]bool{"a1:0000000000000001": true}
+	if !reflect.DeepEqual(lookup1.entries, want1) {
+		t.Errorf("lookup1 entries: %v, want %v", lookup1.entries, want1)
+	}
+	want2 := map[string]bool{"b1:0000000000000001": true}
+	if !reflect.DeepEqual(lookup2.entries, want2) {
+		t.Errorf("lookup2 entries: %v, want %v", lookup2.entries, want2)
+	}
+}
+
+// importLookupVindex is the vindex returned by the "import_fake_lookup"
+// vindex type.
+var importLookupVindex *fakeLookup
+
+func init() {
+	vindexes.Register("import_fake_lookup", func(name string, params map[string]string) (vindexes.Vindex, error) {
+		return importLookupVindex, nil
+	})
+}
+
+// importFakeDb is a queryFakeDb which fails the inserts of the rows
+// with a value.
+type importFakeDb struct {
+	*queryFakeDb
+
+	failValue string
+}
+
+func (f *importFakeDb) factory() func() (dbconnpool.PoolConnection, error) {
+	return func() (dbconnpool.PoolConnection, error) {
+		return f, nil
+	}
+}
+
+// ExecuteFetch implements dbconnpool.PoolConnection.
+func (f *importFakeDb) ExecuteFetch(query string, maxrows int, wantfields bool) (*sqltypes.Result, error) {
+	qr, err := f.queryFakeDb.ExecuteFetch(query, maxrows, wantfields)
+	if strings.HasPrefix(query, "INSERT INTO") && strings.Contains(query, f.failValue) {
+		return nil, errors.New("Column 'c' cannot be null (errno 1048) (sqlstate 23000)")
+	}
+	return qr, err
+}
+
+func TestImport(t *testing.T) {
+	root, err := ioutil.TempDir("", "importtest")
+	if err != nil {
+		t.Fatalf("ioutil.TempDir failed: %v", err)
+	}
+	defer os.RemoveAll(root)
+
+	// The insert of the row with the value "bad" fails: it is
+	// rejected, and the other rows of its batch are inserted one by
+	// one.
+	const highID = 0x8000000000000001
+	input := fmt.Sprintf("id,c\n1,a\n2,bad\n3,c\n%v,d\n", uint64(highID))
+	file := path.Join(root, "t.csv")
+	if err := ioutil.WriteFile(file, []byte(input), 0644); err != nil {
+		t.Fatalf("cannot write %v: %v", file, err)
+	}
+	rejectedRowsFile := path.Join(root, "rejected.json")
+
+	// The fake lookup vindex does not use the vtgate connection.
+	_, protocol := fakerpcvtgateconn.RegisterFakeVTGateConnDialer()
+	oldProtocol := *vtgateconn.VtgateProtocol
+	*vtgateconn.VtgateProtocol = protocol
+	defer func() { *vtgateconn.VtgateProtocol = oldProtocol }()
+	importLookupVindex = newFakeLookup("c_lookup")
+
+	db := fakesqldb.Register()
+	ts := zktestserver.New(t, []string{"cell1"})
+	ctx := context.Background()
+	wi := NewInstance(ts, "cell1", time.Second)
+
+	columnsResult := &sqltypes.Result{
+		Fields: verifyLookupOwnerFields,
+	}
+	var masterDbs []*importFakeDb
+	for i, shard := range []string{"-80", "80-"} {
+		master := testlib.NewFakeTablet(t, wi.wr, "cell1", uint32(10*i+10),
+			topodatapb.TabletType_MASTER, db, testlib.TabletKeyspaceShard(t, "ks", shard))
+		masterDb := &importFakeDb{
+			queryFakeDb: newQueryFakeDb(t, "master"+shard, map[string]*sqltypes.Result{
+				"SELECT * FROM `vt_ks`.`t` LIMIT 0": columnsResult,
+			}),
+			failValue: "'bad'",
+		}
+		master.FakeMysqlDaemon.DbAppConnectionFactory = masterDb.factory()
+		masterDbs = append(masterDbs, masterDb)
+
+		master.StartActionLoop(t, wi.wr)
+		defer master.StopActionLoop(t)
+
+		qs := fakes.NewStreamHealthQueryService(master.Target())
+		qs.AddDefaultHealthResponse()
+		grpcqueryservice.Register(master.RPCServer, qs)
+	}
+	if err := wi.wr.RebuildKeyspaceGraph(ctx, "ks", nil); err != nil {
+		t.Fatalf("RebuildKeyspaceGraph failed: %v", err)
+	}
+	if err := ts.SaveVSchema(ctx, "ks", &vschemapb.Keyspace{
+		Sharded: true,
+		Vindexes: map[string]*vschemapb.Vindex{
+			"numeric": {
+				Type: "numeric",
+			},
+			"c_lookup": {
+				Type:  "import_fake_lookup",
+				Owner: "t",
+			},
+		},
+		Tables: map[string]*vschemapb.Table{
+			"t": {
+				ColumnVindexes: []*vschemapb.ColumnVindex{
+					{
+						Column: "id",
+						Name:   "numeric",
+					},
+					{
+						Column: "c",
+						Name:   "c_lookup",
+					},
+				},
+			},
+		},
+	}); err != nil {
+		t.Fatalf("SaveVSchema failed: %v", err)
+	}
+
+	args := []string{"Import", "-table", "t", "-vtgate_server", "localhost:0", "-rejected_rows_file", rejectedRowsFile, "ks", file}
+	if err := runCommand(t, wi, wi.wr, args); err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+
+	// The entry of the rejected row was deleted.
+	wantEntries := map[string]bool{
+		fmt.Sprintf("a:%x", numericKeyspaceID(1)):      true,
+		fmt.Sprintf("c:%x", numericKeyspaceID(3)):      true,
+		fmt.Sprintf("d:%x", numericKeyspaceID(highID)): true,
+	}
+	if !reflect.DeepEqual(importLookupVindex.entries, wantEntries) {
+		t.Errorf("entries of the vindex: %v, want: %v", importLookupVindex.entries, wantEntries)
+	}
+	for _, tcase := range []struct {
+		db    *importFakeDb
+		query string
+	}{
+		{masterDbs[0], "INSERT INTO `vt_ks`.`t` (`id`, `c`) VALUES (1,'a')"},
+		{masterDbs[0], "INSERT INTO `vt_ks`.`t` (`id`, `c`) VALUES (3,'c')"},
+		{masterDbs[1], fmt.Sprintf("INSERT INTO `vt_ks`.`t` (`id`, `c`) VALUES (%v,'d')", uint64(highID))},
+	} {
+		if tcase.db.count(tcase.query) != 1 {
+			t.Errorf("%v was not executed once on %v: %v", tcase.query, tcase.db.name, tcase.db.queries)
+		}
+	}
+
+	data, err := ioutil.ReadFile(rejectedRowsFile)
+	if err != nil {
+		t.Fatalf("cannot read %v: %v", rejectedRowsFile, err)
+	}
+	var rejected importRejectedRow
+	if err := json.Unmarshal(data, &rejected); err != nil {
+		t.Fatalf("cannot decode %v: %v", rejectedRowsFile, err)
+	}
+	if rejected.Line != 3 || !reflect.DeepEqual(rejected.Record, []interface{}{"2", "bad"}) || !strings.Contains(rejected.Error, "errno 1048") {
+		t.Errorf("rejected row: %+v, want line 3, record [2 bad] and the MySQL error", rejected)
+	}
+}
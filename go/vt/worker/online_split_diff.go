@@ -0,0 +1,483 @@
+// Copyright 2016, Google Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package worker
+
+import (
+	"fmt"
+	"html/template"
+	"sync"
+
+	"golang.org/x/net/context"
+
+	"github.com/youtube/vitess/go/vt/concurrency"
+	"github.com/youtube/vitess/go/vt/key"
+	"github.com/youtube/vitess/go/vt/mysqlctl/tmutils"
+	"github.com/youtube/vitess/go/vt/topo"
+	"github.com/youtube/vitess/go/vt/topo/topoproto"
+	"github.com/youtube/vitess/go/vt/vtgate/vindexes"
+	"github.com/youtube/vitess/go/vt/wrangler"
+
+	tabletmanagerdatapb "github.com/youtube/vitess/go/vt/proto/tabletmanagerdata"
+	topodatapb "github.com/youtube/vitess/go/vt/proto/topodata"
+)
+
+// OnlineSplitDiffWorker executes a diff between a destination shard and
+// one of its source shards while filtered replication keeps running.
+// Unlike SplitDiffWorker, it does not stop a destination rdonly tablet
+// for the whole diff: it compares the tables chunk by chunk, reading the
+// destination master and pausing its filtered replication only while a
+// chunk is read. This way, it can run repeatedly on large tables.
+type OnlineSplitDiffWorker struct {
+	StatusWorker
+
+	wr                      *wrangler.Wrangler
+	cell                    string
+	keyspace                string
+	shard                   string
+	sourceUID               uint32
+	excludeTables           []string
+	chunkCount              int
+	minRowsPerChunk         int
+	minHealthyRdonlyTablets int
+	cleaner                 *wrangler.Cleaner
+
+	// populated during WorkerStateInit, read-only after that
+	keyspaceInfo *topo.KeyspaceInfo
+	shardInfo    *topo.ShardInfo
+	sourceShard  *topodatapb.Shard_SourceShard
+
+	// populated during WorkerStateFindTargets, read-only after that
+	sourceAlias       *topodatapb.TabletAlias
+	sourceTablet      *topodatapb.Tablet
+	destinationMaster *topodatapb.Tablet
+
+	// populated during WorkerStateDiff
+	sourceSchemaDefinition      *tabletmanagerdatapb.SchemaDefinition
+	destinationSchemaDefinition *tabletmanagerdatapb.SchemaDefinition
+
+	// mu protects the progress of the diff.
+	mu              sync.Mutex
+	currentTable    string
+	currentChunk    chunk
+	diffedChunks    int
+	differentChunks int
+}
+
+// NewOnlineSplitDiffWorker returns a new OnlineSplitDiffWorker object.
+func NewOnlineSplitDiffWorker(wr *wrangler.Wrangler, cell, keyspace, shard string, sourceUID uint32, excludeTables []string, chunkCount, minRowsPerChunk, minHealthyRdonlyTablets int) Worker {
+	return &OnlineSplitDiffWorker{
+		StatusWorker:            NewStatusWorker(),
+		wr:                      wr,
+		cell:                    cell,
+		keyspace:                keyspace,
+		shard:                   shard,
+		sourceUID:               sourceUID,
+		excludeTables:           excludeTables,
+		chunkCount:              chunkCount,
+		minRowsPerChunk:         minRowsPerChunk,
+		minHealthyRdonlyTablets: minHealthyRdonlyTablets,
+		cleaner:                 &wrangler.Cleaner{},
+	}
+}
+
+// progress returns a description of the chunk being diffed, and the
+// number of chunks diffed so far.
+func (osdw *OnlineSplitDiffWorker) progress() string {
+	osdw.mu.Lock()
+	defer osdw.mu.Unlock()
+	result := fmt.Sprintf("%v chunks diffed, %v with differences", osdw.diffedChunks, osdw.differentChunks)
+	if osdw.currentTable != "" {
+		result = fmt.Sprintf("table %v chunk %v (%v)", osdw.currentTable, osdw.currentChunk, result)
+	}
+	return result
+}
+
+// StatusAsHTML is part of the Worker interface
+func (osdw *OnlineSplitDiffWorker) StatusAsHTML() template.HTML {
+	state := osdw.State()
+
+	result := "<b>Working on:</b> " + osdw.keyspace + "/" + osdw.shard + "</br>\n"
+	result += "<b>State:</b> " + state.String() + "</br>\n"
+	switch state {
+	case WorkerStateDiff:
+		result += "<b>Running:</b> " + template.HTMLEscapeString(osdw.progress()) + "</br>\n"
+	case WorkerStateDone:
+		result += "<b>Success:</b> " + template.HTMLEscapeString(osdw.progress()) + "</br>\n"
+	}
+
+	return template.HTML(result)
+}
+
+// StatusAsText is part of the Worker interface
+func (osdw *OnlineSplitDiffWorker) StatusAsText() string {
+	state := osdw.State()
+
+	result := "Working on: " + osdw.keyspace + "/" + osdw.shard + "\n"
+	result += "State: " + state.String() + "\n"
+	switch state {
+	case WorkerStateDiff:
+		result += "Running: " + osdw.progress() + "\n"
+	case WorkerStateDone:
+		result += "Success: " + osdw.progress() + "\n"
+	}
+	return result
+}
+
+// Run is mostly a wrapper to run the cleanup at the end.
+func (osdw *OnlineSplitDiffWorker) Run(ctx context.Context) error {
+	resetVars()
+	err := osdw.run(ctx)
+
+	osdw.SetState(WorkerStateCleanUp)
+	cerr := osdw.cleaner.CleanUp(osdw.wr)
+	if cerr != nil {
+		if err != nil {
+			osdw.wr.Logger().Errorf("CleanUp failed in addition to job error: %v", cerr)
+		} else {
+			err = cerr
+		}
+	}
+	if err != nil {
+		osdw.SetState(WorkerStateError)
+		return err
+	}
+	osdw.SetState(WorkerStateDone)
+	return nil
+}
+
+func (osdw *OnlineSplitDiffWorker) run(ctx context.Context) error {
+	// first state: read what we need to do
+	if err := osdw.init(ctx); err != nil {
+		return fmt.Errorf("init() failed: %v", err)
+	}
+	if err := checkDone(ctx); err != nil {
+		return err
+	}
+
+	// second state: find targets
+	if err := osdw.findTargets(ctx); err != nil {
+		return fmt.Errorf("findTargets() failed: %v", err)
+	}
+	if err := checkDone(ctx); err != nil {
+		return err
+	}
+
+	// third phase: diff, synchronizing replication for each chunk
+	if err := osdw.diff(ctx); err != nil {
+		return fmt.Errorf("diff() failed: %v", err)
+	}
+	if err := checkDone(ctx); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// init phase:
+// - read the shard info, make sure it has the source and a master
+func (osdw *OnlineSplitDiffWorker) init(ctx context.Context) error {
+	osdw.SetState(WorkerStateInit)
+
+	var err error
+	shortCtx, cancel := context.WithTimeout(ctx, *remoteActionsTimeout)
+	osdw.keyspaceInfo, err = osdw.wr.TopoServer().GetKeyspace(shortCtx, osdw.keyspace)
+	cancel()
+	if err != nil {
+		return fmt.Errorf("cannot read keyspace %v: %v", osdw.keyspace, err)
+	}
+	shortCtx, cancel = context.WithTimeout(ctx, *remoteActionsTimeout)
+	osdw.shardInfo, err = osdw.wr.TopoServer().GetShard(shortCtx, osdw.keyspace, osdw.shard)
+	cancel()
+	if err != nil {
+		return fmt.Errorf("cannot read shard %v/%v: %v", osdw.keyspace, osdw.shard, err)
+	}
+
+	for _, ss := range osdw.shardInfo.SourceShards {
+		if ss.Uid == osdw.sourceUID {
+			osdw.sourceShard = ss
+		}
+	}
+	if osdw.sourceShard == nil {
+		return fmt.Errorf("shard %v/%v has no source shard with UID %v", osdw.keyspace, osdw.shard, osdw.sourceUID)
+	}
+	if len(osdw.sourceShard.Tables) > 0 {
+		return fmt.Errorf("shard %v/%v is the destination of a vertical split, not a horizontal one", osdw.keyspace, osdw.shard)
+	}
+
+	if !osdw.shardInfo.HasMaster() {
+		return fmt.Errorf("shard %v/%v has no master", osdw.keyspace, osdw.shard)
+	}
+
+	return nil
+}
+
+// findTargets phase:
+// - find one rdonly in the source shard, and mark it as 'worker'
+//   pointing back to us
+// - read the master of the destination shard. We don't need a
+//   destination rdonly tablet because we read the master.
+func (osdw *OnlineSplitDiffWorker) findTargets(ctx context.Context) error {
+	osdw.SetState(WorkerStateFindTargets)
+
+	var err error
+	osdw.sourceAlias, err = FindWorkerTablet(ctx, osdw.wr, osdw.cleaner, nil /* tsc */, osdw.cell, osdw.keyspace, osdw.sourceShard.Shard, osdw.minHealthyRdonlyTablets)
+	if err != nil {
+		return fmt.Errorf("FindWorkerTablet() failed for %v/%v/%v: %v", osdw.cell, osdw.keyspace, osdw.sourceShard.Shard, err)
+	}
+
+	shortCtx, cancel := context.WithTimeout(ctx, *remoteActionsTimeout)
+	sourceTablet, err := osdw.wr.TopoServer().GetTablet(shortCtx, osdw.sourceAlias)
+	cancel()
+	if err != nil {
+		return fmt.Errorf("cannot get Tablet record for source %v: %v", osdw.sourceAlias, err)
+	}
+	osdw.sourceTablet = sourceTablet.Tablet
+
+	shortCtx, cancel = context.WithTimeout(ctx, *remoteActionsTimeout)
+	masterInfo, err := osdw.wr.TopoServer().GetTablet(shortCtx, osdw.shardInfo.MasterAlias)
+	cancel()
+	if err != nil {
+		return fmt.Errorf("cannot get Tablet record for master %v: %v", osdw.shardInfo.MasterAlias, err)
+	}
+	osdw.destinationMaster = masterInfo.Tablet
+
+	return nil
+}
+
+// diff phase: will log messages regarding the diff.
+// - get the schema on the source tablet and the destination master
+// - if some table schema mismatches, record them (use existing schema diff tools).
+// - for each table in destination, split it in chunks, and diff them
+//   one by one, synchronizing replication for each chunk.
+func (osdw *OnlineSplitDiffWorker) diff(ctx context.Context) error {
+	osdw.SetState(WorkerStateDiff)
+
+	osdw.wr.Logger().Infof("Gathering schema information...")
+	var err error
+	shortCtx, cancel := context.WithTimeout(ctx, *remoteActionsTimeout)
+	osdw.destinationSchemaDefinition, err = osdw.wr.GetSchema(
+		shortCtx, osdw.destinationMaster.Alias, nil /* tables */, osdw.excludeTables, false /* includeViews */)
+	cancel()
+	if err != nil {
+		return fmt.Errorf("cannot get schema from destination %v: %v", topoproto.TabletAliasString(osdw.destinationMaster.Alias), err)
+	}
+	shortCtx, cancel = context.WithTimeout(ctx, *remoteActionsTimeout)
+	osdw.sourceSchemaDefinition, err = osdw.wr.GetSchema(
+		shortCtx, osdw.sourceAlias, nil /* tables */, osdw.excludeTables, false /* includeViews */)
+	cancel()
+	if err != nil {
+		return fmt.Errorf("cannot get schema from source %v: %v", topoproto.TabletAliasString(osdw.sourceAlias), err)
+	}
+
+	osdw.wr.Logger().Infof("Diffing the schema...")
+	rec := &concurrency.AllErrorRecorder{}
+	tmutils.DiffSchema("destination", osdw.destinationSchemaDefinition, "source", osdw.sourceSchemaDefinition, rec)
+	if rec.HasErrors() {
+		osdw.wr.Logger().Warningf("Different schemas: %v", rec.Error().Error())
+	} else {
+		osdw.wr.Logger().Infof("Schema match, good.")
+	}
+
+	// read the vschema if needed
+	var keyspaceSchema *vindexes.KeyspaceSchema
+	if *useV3ReshardingMode {
+		kschema, err := osdw.wr.TopoServer().GetVSchema(ctx, osdw.keyspace)
+		if err != nil {
+			return fmt.Errorf("cannot load VSchema for keyspace %v: %v", osdw.keyspace, err)
+		}
+		if kschema == nil {
+			return fmt.Errorf("no VSchema for keyspace %v", osdw.keyspace)
+		}
+
+		keyspaceSchema, err = vindexes.BuildKeyspaceSchema(kschema, osdw.keyspace)
+		if err != nil {
+			return fmt.Errorf("cannot build vschema for keyspace %v: %v", osdw.keyspace, err)
+		}
+	}
+
+	// Compute the overlap keyrange, like SplitDiff. A side only
+	// gets filtered if its keyrange is bigger than the overlap.
+	overlap, err := key.KeyRangesOverlap(osdw.shardInfo.KeyRange, osdw.sourceShard.KeyRange)
+	if err != nil {
+		return fmt.Errorf("Source shard doesn't overlap with destination: %v", err)
+	}
+	var sourceKeyRange, destinationKeyRange *topodatapb.KeyRange
+	if !key.KeyRangeEqual(overlap, osdw.sourceShard.KeyRange) {
+		sourceKeyRange = overlap
+	}
+	if !key.KeyRangeEqual(overlap, osdw.shardInfo.KeyRange) {
+		destinationKeyRange = overlap
+	}
+
+	sourceTableDefinitions := make(map[string]*tabletmanagerdatapb.TableDefinition)
+	for _, td := range osdw.sourceSchemaDefinition.TableDefinitions {
+		sourceTableDefinitions[td.Name] = td
+	}
+
+	// Run the diffs one chunk at a time: each chunk pauses filtered
+	// replication on the destination master.
+	osdw.wr.Logger().Infof("Running the diffs...")
+	rec = &concurrency.AllErrorRecorder{}
+	for _, td := range osdw.destinationSchemaDefinition.TableDefinitions {
+		sourceTd, ok := sourceTableDefinitions[td.Name]
+		if !ok {
+			rec.RecordError(fmt.Errorf("table %v is missing on the source", td.Name))
+			continue
+		}
+		// Chunk the table with the source row count, because the
+		// destination may only have a part of the rows.
+		chunks, err := generateChunks(ctx, osdw.wr, osdw.sourceTablet, sourceTd, osdw.chunkCount, osdw.minRowsPerChunk)
+		if err != nil {
+			return fmt.Errorf("failed to split table %v into chunks: %v", td.Name, err)
+		}
+
+		different := false
+		for _, c := range chunks {
+			if err := checkDone(ctx); err != nil {
+				return err
+			}
+			osdw.mu.Lock()
+			osdw.currentTable = td.Name
+			osdw.currentChunk = c
+			osdw.mu.Unlock()
+
+			report, err := osdw.diffChunk(ctx, td, c, sourceKeyRange, destinationKeyRange, keyspaceSchema)
+			if err != nil {
+				return fmt.Errorf("table=%v chunk=%v: %v", td.Name, c, err)
+			}
+
+			osdw.mu.Lock()
+			osdw.diffedChunks++
+			if report.HasDifferences() {
+				osdw.differentChunks++
+			}
+			osdw.mu.Unlock()
+			if report.HasDifferences() {
+				different = true
+				err := fmt.Errorf("Table %v chunk %v has differences: %v", td.Name, c, report.String())
+				rec.RecordError(err)
+				osdw.wr.Logger().Warningf(err.Error())
+			}
+		}
+		if !different {
+			osdw.wr.Logger().Infof("Table %v checks out (%v chunks)", td.Name, len(chunks))
+		}
+	}
+
+	osdw.mu.Lock()
+	osdw.currentTable = ""
+	osdw.mu.Unlock()
+	return rec.Error()
+}
+
+// diffChunk diffs one chunk of a table:
+// 1 - ask the destination master to pause filtered replication, and
+//   return the source binlog positions
+//   (add a cleanup task to restart filtered replication on master)
+// 2 - stop the source tablet at a binlog position higher than the
+//   destination master. Get that new position.
+//   (add a cleanup task to restart binlog replication on the source tablet)
+// 3 - ask the destination master to resume filtered replication up
+//   to that position. Now the source tablet and the destination master
+//   are stopped at the same point.
+// 4 - read the chunk on both sides, and diff it
+// 5 - restart replication on the source tablet, and filtered
+//   replication on the destination master
+//   (remove the cleanup tasks that do the same)
+// If this fails, the cleanup tasks restart replication.
+func (osdw *OnlineSplitDiffWorker) diffChunk(ctx context.Context, td *tabletmanagerdatapb.TableDefinition, c chunk, sourceKeyRange, destinationKeyRange *topodatapb.KeyRange, keyspaceSchema *vindexes.KeyspaceSchema) (*DiffReport, error) {
+	masterAlias := topoproto.TabletAliasString(osdw.destinationMaster.Alias)
+
+	// 1 - stop the master binlog replication, get its current position
+	shortCtx, cancel := context.WithTimeout(ctx, *remoteActionsTimeout)
+	blpPositionList, err := osdw.wr.TabletManagerClient().StopBlp(shortCtx, osdw.destinationMaster)
+	cancel()
+	if err != nil {
+		return nil, fmt.Errorf("StopBlp for %v failed: %v", masterAlias, err)
+	}
+	wrangler.RecordStartBlpAction(osdw.cleaner, osdw.destinationMaster)
+	blpPos := tmutils.FindBlpPositionByID(blpPositionList, osdw.sourceUID)
+	if blpPos == nil {
+		return nil, fmt.Errorf("no binlog position on the master for Uid %v", osdw.sourceUID)
+	}
+
+	// 2 - stop the source tablet at a binlog position higher than
+	//     the destination master
+	shortCtx, cancel = context.WithTimeout(ctx, *remoteActionsTimeout)
+	stoppedAt, err := osdw.wr.TabletManagerClient().StopSlaveMinimum(shortCtx, osdw.sourceTablet, blpPos.Position, *remoteActionsTimeout)
+	cancel()
+	if err != nil {
+		return nil, fmt.Errorf("cannot stop slave %v at right binlog position %v: %v", osdw.sourceAlias, blpPos.Position, err)
+	}
+	wrangler.RecordStartSlaveAction(osdw.cleaner, osdw.sourceTablet)
+
+	// 3 - let the destination master catch up to the source tablet
+	stopPositionList := []*tabletmanagerdatapb.BlpPosition{
+		{
+			Uid:      osdw.sourceUID,
+			Position: stoppedAt,
+		},
+	}
+	shortCtx, cancel = context.WithTimeout(ctx, *remoteActionsTimeout)
+	_, err = osdw.wr.TabletManagerClient().RunBlpUntil(shortCtx, osdw.destinationMaster, stopPositionList, *remoteActionsTimeout)
+	cancel()
+	if err != nil {
+		return nil, fmt.Errorf("RunBlpUntil for %v until %v failed: %v", masterAlias, stopPositionList, err)
+	}
+
+	// 4 - diff the chunk
+	report, err := osdw.readAndDiffChunk(ctx, td, c, sourceKeyRange, destinationKeyRange, keyspaceSchema)
+	if err != nil {
+		return nil, err
+	}
+
+	// 5 - restart replication on both sides
+	shortCtx, cancel = context.WithTimeout(ctx, *remoteActionsTimeout)
+	err = osdw.wr.TabletManagerClient().StartSlave(shortCtx, osdw.sourceTablet)
+	cancel()
+	if err != nil {
+		return nil, fmt.Errorf("StartSlave failed for %v: %v", osdw.sourceAlias, err)
+	}
+	if err := osdw.cleaner.RemoveActionByName(wrangler.StartSlaveActionName, topoproto.TabletAliasString(osdw.sourceAlias)); err != nil {
+		osdw.wr.Logger().Warningf("Cannot find cleaning action %v/%v: %v", wrangler.StartSlaveActionName, topoproto.TabletAliasString(osdw.sourceAlias), err)
+	}
+	shortCtx, cancel = context.WithTimeout(ctx, *remoteActionsTimeout)
+	err = osdw.wr.TabletManagerClient().StartBlp(shortCtx, osdw.destinationMaster)
+	cancel()
+	if err != nil {
+		return nil, fmt.Errorf("StartBlp failed for %v: %v", masterAlias, err)
+	}
+	if err := osdw.cleaner.RemoveActionByName(wrangler.StartBlpActionName, masterAlias); err != nil {
+		osdw.wr.Logger().Warningf("Cannot find cleaning action %v/%v: %v", wrangler.StartBlpActionName, masterAlias, err)
+	}
+
+	return report, nil
+}
+
+// readAndDiffChunk reads a chunk on the source tablet and the
+// destination master, and compares the rows.
+func (osdw *OnlineSplitDiffWorker) readAndDiffChunk(ctx context.Context, td *tabletmanagerdatapb.TableDefinition, c chunk, sourceKeyRange, destinationKeyRange *topodatapb.KeyRange, keyspaceSchema *vindexes.KeyspaceSchema) (*DiffReport, error) {
+	sourceQueryResultReader, err := tableScanChunk(ctx, osdw.wr.Logger(), osdw.wr.TopoServer(), osdw.sourceAlias, td, c, sourceKeyRange, keyspaceSchema, osdw.keyspaceInfo.ShardingColumnName, osdw.keyspaceInfo.ShardingColumnType)
+	if err != nil {
+		return nil, fmt.Errorf("tableScanChunk(source) failed: %v", err)
+	}
+	defer sourceQueryResultReader.Close()
+
+	destinationQueryResultReader, err := tableScanChunk(ctx, osdw.wr.Logger(), osdw.wr.TopoServer(), osdw.destinationMaster.Alias, td, c, destinationKeyRange, keyspaceSchema, osdw.keyspaceInfo.ShardingColumnName, osdw.keyspaceInfo.ShardingColumnType)
+	if err != nil {
+		return nil, fmt.Errorf("tableScanChunk(destination) failed: %v", err)
+	}
+	defer destinationQueryResultReader.Close()
+
+	differ, err := NewRowDiffer(sourceQueryResultReader, destinationQueryResultReader, td)
+	if err != nil {
+		return nil, fmt.Errorf("NewRowDiffer() failed: %v", err)
+	}
+	report, err := differ.Go(osdw.wr.Logger())
+	if err != nil {
+		return nil, fmt.Errorf("Differ.Go failed: %v", err)
+	}
+	return &report, nil
+}
@@ -0,0 +1,160 @@
+// Copyright 2016, Google Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package worker
+
+import (
+	"flag"
+	"fmt"
+	"html/template"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/youtube/vitess/go/vt/topo/topoproto"
+	"github.com/youtube/vitess/go/vt/wrangler"
+	"golang.org/x/net/context"
+)
+
+const onlineSplitDiffHTML = `
+<!DOCTYPE html>
+<head>
+  <title>Online Split Diff Action</title>
+</head>
+<body>
+  <h1>Online Split Diff Action</h1>
+
+    {{if .Error}}
+      <b>Error:</b> {{.Error}}</br>
+    {{else}}
+      {{range $i, $si := .Shards}}
+        <li><a href="/Diffs/OnlineSplitDiff?keyspace={{$si.Keyspace}}&shard={{$si.Shard}}">{{$si.Keyspace}}/{{$si.Shard}}</a></li>
+      {{end}}
+    {{end}}
+</body>
+`
+
+const onlineSplitDiffHTML2 = `
+<!DOCTYPE html>
+<head>
+  <title>Online Split Diff Action</title>
+</head>
+<body>
+  <p>Shard involved: {{.Keyspace}}/{{.Shard}}</p>
+  <h1>Online Split Diff Action</h1>
+    <form action="/Diffs/OnlineSplitDiff" method="post">
+      <LABEL for="sourceUID">Source shard UID: </LABEL>
+        <INPUT type="text" id="sourceUID" name="sourceUID" value="{{.DefaultSourceUID}}"></BR>
+      <LABEL for="excludeTables">Exclude Tables: </LABEL>
+        <INPUT type="text" id="excludeTables" name="excludeTables" value=""></BR>
+      <LABEL for="chunkCount">Number of Chunks per Table: </LABEL>
+        <INPUT type="text" id="chunkCount" name="chunkCount" value="{{.DefaultChunkCount}}"></BR>
+      <LABEL for="minRowsPerChunk">Minimum Number of Rows per Chunk (may reduce the Number of Chunks): </LABEL>
+        <INPUT type="text" id="minRowsPerChunk" name="minRowsPerChunk" value="{{.DefaultMinRowsPerChunk}}"></BR>
+      <LABEL for="minHealthyRdonlyTablets">Minimum Number of required healthy RDONLY tablets in the source shard: </LABEL>
+        <INPUT type="text" id="minHealthyRdonlyTablets" name="minHealthyRdonlyTablets" value="{{.DefaultMinHealthyRdonlyTablets}}"></BR>
+      <INPUT type="hidden" name="keyspace" value="{{.Keyspace}}"/>
+      <INPUT type="hidden" name="shard" value="{{.Shard}}"/>
+      <INPUT type="submit" name="submit" value="Online Split Diff"/>
+    </form>
+  </body>
+`
+
+var onlineSplitDiffTemplate = mustParseTemplate("onlineSplitDiff", onlineSplitDiffHTML)
+var onlineSplitDiffTemplate2 = mustParseTemplate("onlineSplitDiff2", onlineSplitDiffHTML2)
+
+func commandOnlineSplitDiff(wi *Instance, wr *wrangler.Wrangler, subFlags *flag.FlagSet, args []string) (Worker, error) {
+	sourceUID := subFlags.Int("source_uid", 0, "uid of the source shard to run the diff against")
+	excludeTables := subFlags.String("exclude_tables", "", "comma separated list of tables to exclude")
+	chunkCount := subFlags.Int("chunk_count", defaultChunkCount, "number of chunks per table. Filtered replication is paused while a chunk is diffed")
+	minRowsPerChunk := subFlags.Int("min_rows_per_chunk", defaultMinRowsPerChunk, "minimum number of rows per chunk (may reduce --chunk_count)")
+	minHealthyRdonlyTablets := subFlags.Int("min_healthy_rdonly_tablets", defaultMinHealthyRdonlyTablets, "minimum number of healthy RDONLY tablets in the source shard before taking out one")
+	if err := subFlags.Parse(args); err != nil {
+		return nil, err
+	}
+	if subFlags.NArg() != 1 {
+		subFlags.Usage()
+		return nil, fmt.Errorf("command OnlineSplitDiff requires <keyspace/shard>")
+	}
+	keyspace, shard, err := topoproto.ParseKeyspaceShard(subFlags.Arg(0))
+	if err != nil {
+		return nil, err
+	}
+	var excludeTableArray []string
+	if *excludeTables != "" {
+		excludeTableArray = strings.Split(*excludeTables, ",")
+	}
+	return NewOnlineSplitDiffWorker(wr, wi.cell, keyspace, shard, uint32(*sourceUID), excludeTableArray, *chunkCount, *minRowsPerChunk, *minHealthyRdonlyTablets), nil
+}
+
+func interactiveOnlineSplitDiff(ctx context.Context, wi *Instance, wr *wrangler.Wrangler, w http.ResponseWriter, r *http.Request) (Worker, *template.Template, map[string]interface{}, error) {
+	if err := r.ParseForm(); err != nil {
+		return nil, nil, nil, fmt.Errorf("cannot parse form: %s", err)
+	}
+	keyspace := r.FormValue("keyspace")
+	shard := r.FormValue("shard")
+
+	if keyspace == "" || shard == "" {
+		// display the list of possible shards to chose from
+		result := make(map[string]interface{})
+		shards, err := shardsWithSources(ctx, wr)
+		if err != nil {
+			result["Error"] = err.Error()
+		} else {
+			result["Shards"] = shards
+		}
+		return nil, onlineSplitDiffTemplate, result, nil
+	}
+
+	submitButtonValue := r.FormValue("submit")
+	if submitButtonValue == "" {
+		// display the input form
+		result := make(map[string]interface{})
+		result["Keyspace"] = keyspace
+		result["Shard"] = shard
+		result["DefaultSourceUID"] = "0"
+		result["DefaultChunkCount"] = fmt.Sprintf("%v", defaultChunkCount)
+		result["DefaultMinRowsPerChunk"] = fmt.Sprintf("%v", defaultMinRowsPerChunk)
+		result["DefaultMinHealthyRdonlyTablets"] = fmt.Sprintf("%v", defaultMinHealthyRdonlyTablets)
+		return nil, onlineSplitDiffTemplate2, result, nil
+	}
+
+	// Process input form.
+	sourceUIDStr := r.FormValue("sourceUID")
+	sourceUID, err := strconv.ParseInt(sourceUIDStr, 0, 64)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("cannot parse sourceUID: %s", err)
+	}
+	excludeTables := r.FormValue("excludeTables")
+	var excludeTableArray []string
+	if excludeTables != "" {
+		excludeTableArray = strings.Split(excludeTables, ",")
+	}
+	chunkCountStr := r.FormValue("chunkCount")
+	chunkCount, err := strconv.ParseInt(chunkCountStr, 0, 64)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("cannot parse chunkCount: %s", err)
+	}
+	minRowsPerChunkStr := r.FormValue("minRowsPerChunk")
+	minRowsPerChunk, err := strconv.ParseInt(minRowsPerChunkStr, 0, 64)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("cannot parse minRowsPerChunk: %s", err)
+	}
+	minHealthyRdonlyTabletsStr := r.FormValue("minHealthyRdonlyTablets")
+	minHealthyRdonlyTablets, err := strconv.ParseInt(minHealthyRdonlyTabletsStr, 0, 64)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("cannot parse minHealthyRdonlyTablets: %s", err)
+	}
+
+	// start the diff job
+	wrk := NewOnlineSplitDiffWorker(wr, wi.cell, keyspace, shard, uint32(sourceUID), excludeTableArray, int(chunkCount), int(minRowsPerChunk), int(minHealthyRdonlyTablets))
+	return wrk, nil, nil, nil
+}
+
+func init() {
+	AddCommand("Diffs", Command{"OnlineSplitDiff",
+		commandOnlineSplitDiff, interactiveOnlineSplitDiff,
+		"[--exclude_tables=''] [--chunk_count=N] <keyspace/shard>",
+		"Diffs a destination shard against one of its SourceShards while filtered replication runs, pausing it for each chunk"})
+}
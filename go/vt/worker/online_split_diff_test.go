@@ -0,0 +1,295 @@
+// Copyright 2016, Google Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package worker
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/youtube/vitess/go/sqltypes"
+	"github.com/youtube/vitess/go/vt/logutil"
+	"github.com/youtube/vitess/go/vt/mysqlctl/tmutils"
+	"github.com/youtube/vitess/go/vt/tabletmanager/tmclient"
+	"github.com/youtube/vitess/go/vt/tabletserver/grpcqueryservice"
+	"github.com/youtube/vitess/go/vt/tabletserver/queryservice/fakes"
+	"github.com/youtube/vitess/go/vt/vttest/fakesqldb"
+	"github.com/youtube/vitess/go/vt/wrangler"
+	"github.com/youtube/vitess/go/vt/wrangler/testlib"
+	"github.com/youtube/vitess/go/vt/zktopo/zktestserver"
+	"golang.org/x/net/context"
+
+	querypb "github.com/youtube/vitess/go/vt/proto/query"
+	tabletmanagerdatapb "github.com/youtube/vitess/go/vt/proto/tabletmanagerdata"
+	topodatapb "github.com/youtube/vitess/go/vt/proto/topodata"
+)
+
+var (
+	chunkStartRegexp = regexp.MustCompile("`id`>=([0-9]+)")
+	chunkEndRegexp   = regexp.MustCompile("`id`<([0-9]+)")
+)
+
+// onlineSplitDiffTabletServer is a local QueryService implementation
+// which returns the even rows of table1 within the requested chunk.
+type onlineSplitDiffTabletServer struct {
+	t *testing.T
+
+	*fakes.StreamHealthQueryService
+	source bool
+	// missingRow is not returned if not negative.
+	missingRow int
+}
+
+func (sq *onlineSplitDiffTabletServer) StreamExecute(ctx context.Context, target *querypb.Target, sql string, bindVariables map[string]interface{}, options *querypb.ExecuteOptions, sendReply func(reply *sqltypes.Result) error) error {
+	hasKeyspace := strings.Contains(sql, "`keyspace_id` < 0x4000000000000000")
+	if sq.source && !hasKeyspace {
+		sq.t.Errorf("Sql query on source should contain a keyspace_id WHERE clause; query received: %v", sql)
+	}
+	if !sq.source && hasKeyspace {
+		sq.t.Errorf("Sql query on destination should not contain a keyspace_id WHERE clause; query received: %v", sql)
+	}
+
+	start, end := 0, 100
+	if m := chunkStartRegexp.FindStringSubmatch(sql); m != nil {
+		start, _ = strconv.Atoi(m[1])
+	}
+	if m := chunkEndRegexp.FindStringSubmatch(sql); m != nil {
+		end, _ = strconv.Atoi(m[1])
+	}
+
+	if err := sendReply(&sqltypes.Result{
+		Fields: []*querypb.Field{
+			{
+				Name: "id",
+				Type: sqltypes.Int64,
+			},
+			{
+				Name: "msg",
+				Type: sqltypes.VarChar,
+			},
+			{
+				Name: "keyspace_id",
+				Type: sqltypes.Int64,
+			},
+		},
+	}); err != nil {
+		return err
+	}
+	for i := start; i < end; i++ {
+		// The odd rows belong to the other destination shard.
+		if i%2 == 1 || i == sq.missingRow {
+			continue
+		}
+		if err := sendReply(&sqltypes.Result{
+			Rows: [][]sqltypes.Value{
+				{
+					sqltypes.MakeString([]byte(fmt.Sprintf("%v", i))),
+					sqltypes.MakeString([]byte(fmt.Sprintf("Text for %v", i))),
+					sqltypes.MakeString([]byte(fmt.Sprintf("%v", uint64(0x2000000000000000)))),
+				},
+			},
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// onlineSplitDiffTMC answers the MIN and MAX query used to chunk the
+// tables, and records the calls pausing and resuming filtered
+// replication.
+type onlineSplitDiffTMC struct {
+	tmclient.TabletManagerClient
+
+	mu    sync.Mutex
+	calls []string
+}
+
+func (f *onlineSplitDiffTMC) record(call string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls = append(f.calls, call)
+}
+
+// count returns how many times call was made.
+func (f *onlineSplitDiffTMC) count(call string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	n := 0
+	for _, c := range f.calls {
+		if c == call {
+			n++
+		}
+	}
+	return n
+}
+
+// ExecuteFetchAsApp is part of the tmclient.TabletManagerClient interface.
+func (f *onlineSplitDiffTMC) ExecuteFetchAsApp(ctx context.Context, tablet *topodatapb.Tablet, usePool bool, query []byte, maxRows int) (*querypb.QueryResult, error) {
+	return sqltypes.ResultToProto3(&sqltypes.Result{
+		Fields: []*querypb.Field{
+			{
+				Name: "min",
+				Type: sqltypes.Int64,
+			},
+			{
+				Name: "max",
+				Type: sqltypes.Int64,
+			},
+		},
+		Rows: [][]sqltypes.Value{
+			{
+				sqltypes.MakeTrusted(sqltypes.Int64, []byte("0")),
+				sqltypes.MakeTrusted(sqltypes.Int64, []byte("100")),
+			},
+		},
+	}), nil
+}
+
+// StopBlp is part of the tmclient.TabletManagerClient interface.
+func (f *onlineSplitDiffTMC) StopBlp(ctx context.Context, tablet *topodatapb.Tablet) ([]*tabletmanagerdatapb.BlpPosition, error) {
+	f.record("StopBlp")
+	return f.TabletManagerClient.StopBlp(ctx, tablet)
+}
+
+// StopSlaveMinimum is part of the tmclient.TabletManagerClient interface.
+func (f *onlineSplitDiffTMC) StopSlaveMinimum(ctx context.Context, tablet *topodatapb.Tablet, position string, waitTime time.Duration) (string, error) {
+	f.record("StopSlaveMinimum")
+	return f.TabletManagerClient.StopSlaveMinimum(ctx, tablet, position, waitTime)
+}
+
+// RunBlpUntil is part of the tmclient.TabletManagerClient interface.
+func (f *onlineSplitDiffTMC) RunBlpUntil(ctx context.Context, tablet *topodatapb.Tablet, positions []*tabletmanagerdatapb.BlpPosition, waitTime time.Duration) (string, error) {
+	f.record("RunBlpUntil")
+	return f.TabletManagerClient.RunBlpUntil(ctx, tablet, positions, waitTime)
+}
+
+// StartBlp is part of the tmclient.TabletManagerClient interface.
+func (f *onlineSplitDiffTMC) StartBlp(ctx context.Context, tablet *topodatapb.Tablet) error {
+	f.record("StartBlp")
+	return f.TabletManagerClient.StartBlp(ctx, tablet)
+}
+
+func testOnlineSplitDiff(t *testing.T, missingRow int) error {
+	*useV3ReshardingMode = false
+	db := fakesqldb.Register()
+	ts := zktestserver.New(t, []string{"cell1", "cell2"})
+	ctx := context.Background()
+	wi := NewInstance(ts, "cell1", time.Second)
+
+	if err := ts.CreateKeyspace(ctx, "ks", &topodatapb.Keyspace{
+		ShardingColumnName: "keyspace_id",
+		ShardingColumnType: topodatapb.KeyspaceIdType_UINT64,
+	}); err != nil {
+		t.Fatalf("CreateKeyspace failed: %v", err)
+	}
+
+	sourceMaster := testlib.NewFakeTablet(t, wi.wr, "cell1", 0,
+		topodatapb.TabletType_MASTER, db, testlib.TabletKeyspaceShard(t, "ks", "-80"))
+	sourceRdonly1 := testlib.NewFakeTablet(t, wi.wr, "cell1", 1,
+		topodatapb.TabletType_RDONLY, db, testlib.TabletKeyspaceShard(t, "ks", "-80"))
+	sourceRdonly2 := testlib.NewFakeTablet(t, wi.wr, "cell1", 2,
+		topodatapb.TabletType_RDONLY, db, testlib.TabletKeyspaceShard(t, "ks", "-80"))
+	leftMaster := testlib.NewFakeTablet(t, wi.wr, "cell1", 10,
+		topodatapb.TabletType_MASTER, db, testlib.TabletKeyspaceShard(t, "ks", "-40"))
+
+	for _, ft := range []*testlib.FakeTablet{sourceMaster, sourceRdonly1, sourceRdonly2, leftMaster} {
+		ft.StartActionLoop(t, wi.wr)
+		defer ft.StopActionLoop(t)
+	}
+
+	// -80 is split into -40 and 40-80, the keyspace is fully covered
+	// by -80 and 80-.
+	for _, shard := range []string{"80-", "40-80"} {
+		if err := ts.CreateShard(ctx, "ks", shard); err != nil {
+			t.Fatalf("CreateShard(%q) failed: %v", shard, err)
+		}
+	}
+	for _, shard := range []string{"-40", "40-80"} {
+		if err := wi.wr.SetSourceShards(ctx, "ks", shard, []*topodatapb.TabletAlias{sourceRdonly1.Tablet.Alias}, nil); err != nil {
+			t.Fatalf("SetSourceShards(%q) failed: %v", shard, err)
+		}
+	}
+	if err := wi.wr.RebuildKeyspaceGraph(ctx, "ks", nil); err != nil {
+		t.Fatalf("RebuildKeyspaceGraph failed: %v", err)
+	}
+
+	for _, ft := range []*testlib.FakeTablet{sourceRdonly1, sourceRdonly2, leftMaster} {
+		ft.FakeMysqlDaemon.Schema = &tabletmanagerdatapb.SchemaDefinition{
+			DatabaseSchema: "",
+			TableDefinitions: []*tabletmanagerdatapb.TableDefinition{
+				{
+					Name:              "table1",
+					Columns:           []string{"id", "msg", "keyspace_id"},
+					PrimaryKeyColumns: []string{"id"},
+					Type:              tmutils.TableBaseTable,
+					RowCount:          100,
+				},
+			},
+		}
+	}
+	for _, sourceRdonly := range []*testlib.FakeTablet{sourceRdonly1, sourceRdonly2} {
+		qs := fakes.NewStreamHealthQueryService(sourceRdonly.Target())
+		qs.AddDefaultHealthResponse()
+		grpcqueryservice.Register(sourceRdonly.RPCServer, &onlineSplitDiffTabletServer{
+			t: t,
+			StreamHealthQueryService: qs,
+			source:                   true,
+			missingRow:               -1,
+		})
+	}
+	qs := fakes.NewStreamHealthQueryService(leftMaster.Target())
+	qs.AddDefaultHealthResponse()
+	grpcqueryservice.Register(leftMaster.RPCServer, &onlineSplitDiffTabletServer{
+		t: t,
+		StreamHealthQueryService: qs,
+		missingRow:               missingRow,
+	})
+
+	// Run the vtworker command. The table is diffed in 2 chunks, and
+	// filtered replication is paused once per chunk.
+	args := []string{
+		"OnlineSplitDiff",
+		"-chunk_count", "2",
+		"-min_rows_per_chunk", "10",
+		"ks/-40",
+	}
+	tmc := &onlineSplitDiffTMC{
+		TabletManagerClient: newFakeTMCTopo(ts),
+	}
+	wr := wrangler.New(logutil.NewConsoleLogger(), ts, tmc)
+	err := runCommand(t, wi, wr, args)
+	if missingRow < 0 {
+		// Each chunk stops filtered replication, stops the source
+		// at or after its position, catches up, and restarts it.
+		perChunk := []string{"StopBlp", "StopSlaveMinimum", "RunBlpUntil", "StartBlp"}
+		want := append(append([]string{}, perChunk...), perChunk...)
+		if !reflect.DeepEqual(tmc.calls, want) {
+			t.Errorf("filtered replication should be paused once per chunk: got calls %v, want %v", tmc.calls, want)
+		}
+	}
+	if tmc.count("StartBlp") != tmc.count("StopBlp") {
+		t.Errorf("filtered replication was not restarted: got calls %v", tmc.calls)
+	}
+	return err
+}
+
+func TestOnlineSplitDiff(t *testing.T) {
+	if err := testOnlineSplitDiff(t, -1); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestOnlineSplitDiffWithDifferences(t *testing.T) {
+	err := testOnlineSplitDiff(t, 60)
+	if err == nil || !strings.Contains(err.Error(), "Table table1 chunk 2/2 has differences") {
+		t.Fatalf("OnlineSplitDiff should find the missing row in the second chunk: %v", err)
+	}
+}
@@ -31,7 +31,8 @@ type RowAggregator struct {
 	ctx           context.Context
 	maxRows       int
 	maxSize       int
-	insertChannel chan string
+	insertChannel chan writeQuery
+	writes        *chunkWrites
 	td            *tabletmanagerdatapb.TableDefinition
 	diffType      DiffType
 	builder       QueryBuilder
@@ -47,7 +48,8 @@ type RowAggregator struct {
 // The index of the elements in statCounters must match the elements
 // in "DiffTypes" i.e. the first counter is for inserts, second for updates
 // and the third for deletes.
-func NewRowAggregator(ctx context.Context, maxRows, maxSize int, insertChannel chan string, dbName string, td *tabletmanagerdatapb.TableDefinition, diffType DiffType, statsCounters *stats.Counters) *RowAggregator {
+// If writes is not nil, it tracks the sent queries until they were executed.
+func NewRowAggregator(ctx context.Context, maxRows, maxSize int, insertChannel chan writeQuery, writes *chunkWrites, dbName string, td *tabletmanagerdatapb.TableDefinition, diffType DiffType, statsCounters *stats.Counters) *RowAggregator {
 	// Construct head and tail base commands for the reconciliation statement.
 	var builder QueryBuilder
 	switch diffType {
@@ -71,6 +73,7 @@ func NewRowAggregator(ctx context.Context, maxRows, maxSize int, insertChannel c
 		maxRows:       maxRows,
 		maxSize:       maxSize,
 		insertChannel: insertChannel,
+		writes:        writes,
 		td:            td,
 		diffType:      diffType,
 		builder:       builder,
@@ -109,10 +112,16 @@ func (ra *RowAggregator) Flush() error {
 	}
 
 	ra.builder.WriteTail(&ra.buffer)
+	if ra.writes != nil {
+		ra.writes.add()
+	}
 	// select blocks until sending the SQL succeeded or the context was canceled.
 	select {
-	case ra.insertChannel <- ra.buffer.String():
+	case ra.insertChannel <- writeQuery{ra.buffer.String(), ra.writes}:
 	case <-ra.ctx.Done():
+		if ra.writes != nil {
+			ra.writes.done()
+		}
 		return fmt.Errorf("failed to flush RowAggregator and send the query to a writer thread channel: %v", ra.ctx.Err())
 	}
 
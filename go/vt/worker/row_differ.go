@@ -70,6 +70,7 @@ type RowDiffer2 struct {
 // NewRowDiffer2 returns a new RowDiffer2.
 // We assume that the indexes of the slice parameters always correspond to the
 // same shard e.g. insertChannels[0] refers to destinationShards[0] and so on.
+// If writes is not nil, it tracks all write queries until they were executed.
 // The column list td.Columns must be have all primary key columns first and
 // then the non-primary-key columns. The columns in the rows returned by
 // both ResultReader must have the same order as td.Columns.
@@ -77,7 +78,7 @@ func NewRowDiffer2(ctx context.Context, left, right ResultReader, td *tabletmana
 	// Parameters required by RowRouter.
 	destinationShards []*topo.ShardInfo, keyResolver keyspaceIDResolver,
 	// Parameters required by RowAggregator.
-	insertChannels []chan string, abort <-chan struct{}, dbNames []string, writeQueryMaxRows, writeQueryMaxSize, writeQueryMaxRowsDelete int, statsCounters []*stats.Counters) (*RowDiffer2, error) {
+	insertChannels []chan writeQuery, writes *chunkWrites, abort <-chan struct{}, dbNames []string, writeQueryMaxRows, writeQueryMaxSize, writeQueryMaxRowsDelete int, statsCounters []*stats.Counters) (*RowDiffer2, error) {
 
 	if len(statsCounters) != len(DiffTypes) {
 		panic(fmt.Sprintf("statsCounter has the wrong number of elements. got = %v, want = %v", len(statsCounters), len(DiffTypes)))
@@ -97,7 +98,7 @@ func NewRowDiffer2(ctx context.Context, left, right ResultReader, td *tabletmana
 				maxRows = writeQueryMaxRowsDelete
 			}
 			aggregators[i][typ] = NewRowAggregator(ctx, maxRows, writeQueryMaxSize,
-				insertChannels[i], dbNames[i], td, typ, statsCounters[typ])
+				insertChannels[i], writes, dbNames[i], td, typ, statsCounters[typ])
 		}
 	}
 
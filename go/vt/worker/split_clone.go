@@ -20,6 +20,7 @@ import (
 	"github.com/youtube/vitess/go/vt/binlog/binlogplayer"
 	"github.com/youtube/vitess/go/vt/concurrency"
 	"github.com/youtube/vitess/go/vt/discovery"
+	"github.com/youtube/vitess/go/vt/key"
 	"github.com/youtube/vitess/go/vt/throttler"
 	"github.com/youtube/vitess/go/vt/topo"
 	"github.com/youtube/vitess/go/vt/topo/topoproto"
@@ -56,10 +57,16 @@ type SplitCloneWorker struct {
 	shard               string
 	online              bool
 	offline             bool
+	// resumable enables the checkpoints in _vt.split_clone_checkpoint.
+	// See split_clone_checkpoint.go for details.
+	resumable bool
 	// verticalSplit only: List of tables which should be split out.
 	tables []string
 	// horizontalResharding only: List of tables which will be skipped.
-	excludeTables     []string
+	excludeTables []string
+	// verticalSplit only: The keyspace the tables are copied from, if
+	// the destination keyspace has no ServedFrom.
+	sourceKeyspace    string
 	strategy          *splitStrategy
 	chunkCount        int
 	minRowsPerChunk   int
@@ -133,20 +140,22 @@ type SplitCloneWorker struct {
 }
 
 // newSplitCloneWorker returns a new worker object for the SplitClone command.
-func newSplitCloneWorker(wr *wrangler.Wrangler, cell, keyspace, shard string, online, offline bool, excludeTables []string, strategyStr string, chunkCount, minRowsPerChunk, sourceReaderCount, writeQueryMaxRows, writeQueryMaxSize, writeQueryMaxRowsDelete, destinationWriterCount, minHealthyRdonlyTablets int, maxTPS, maxReplicationLag int64) (Worker, error) {
-	return newCloneWorker(wr, horizontalResharding, cell, keyspace, shard, online, offline, nil /* tables */, excludeTables, strategyStr, chunkCount, minRowsPerChunk, sourceReaderCount, writeQueryMaxRows, writeQueryMaxSize, writeQueryMaxRowsDelete, destinationWriterCount, minHealthyRdonlyTablets, maxTPS, maxReplicationLag)
+func newSplitCloneWorker(wr *wrangler.Wrangler, cell, keyspace, shard string, online, offline, resumable bool, excludeTables []string, strategyStr string, chunkCount, minRowsPerChunk, sourceReaderCount, writeQueryMaxRows, writeQueryMaxSize, writeQueryMaxRowsDelete, destinationWriterCount, minHealthyRdonlyTablets int, maxTPS, maxReplicationLag int64) (Worker, error) {
+	return newCloneWorker(wr, horizontalResharding, cell, keyspace, shard, online, offline, resumable, nil /* tables */, excludeTables, "" /* sourceKeyspace */, strategyStr, chunkCount, minRowsPerChunk, sourceReaderCount, writeQueryMaxRows, writeQueryMaxSize, writeQueryMaxRowsDelete, destinationWriterCount, minHealthyRdonlyTablets, maxTPS, maxReplicationLag)
 }
 
 // newVerticalSplitCloneWorker returns a new worker object for the
-// VerticalSplitClone command.
-func newVerticalSplitCloneWorker(wr *wrangler.Wrangler, cell, keyspace, shard string, online, offline bool, tables []string, strategyStr string, chunkCount, minRowsPerChunk, sourceReaderCount, writeQueryMaxRows, writeQueryMaxSize, writeQueryMaxRowsDelete, destinationWriterCount, minHealthyRdonlyTablets int, maxTPS, maxReplicationLag int64) (Worker, error) {
-	return newCloneWorker(wr, verticalSplit, cell, keyspace, shard, online, offline, tables, nil /* excludeTables */, strategyStr, chunkCount, minRowsPerChunk, sourceReaderCount, writeQueryMaxRows, writeQueryMaxSize, writeQueryMaxRowsDelete, destinationWriterCount, minHealthyRdonlyTablets, maxTPS, maxReplicationLag)
+// VerticalSplitClone command. sourceKeyspace is only required if the
+// destination keyspace has no ServedFrom, i.e. when tables are moved
+// into an existing keyspace.
+func newVerticalSplitCloneWorker(wr *wrangler.Wrangler, cell, keyspace, shard, sourceKeyspace string, online, offline bool, tables []string, strategyStr string, chunkCount, minRowsPerChunk, sourceReaderCount, writeQueryMaxRows, writeQueryMaxSize, writeQueryMaxRowsDelete, destinationWriterCount, minHealthyRdonlyTablets int, maxTPS, maxReplicationLag int64) (Worker, error) {
+	return newCloneWorker(wr, verticalSplit, cell, keyspace, shard, online, offline, false /* resumable */, tables, nil /* excludeTables */, sourceKeyspace, strategyStr, chunkCount, minRowsPerChunk, sourceReaderCount, writeQueryMaxRows, writeQueryMaxSize, writeQueryMaxRowsDelete, destinationWriterCount, minHealthyRdonlyTablets, maxTPS, maxReplicationLag)
 }
 
 // newCloneWorker returns a new SplitCloneWorker object which is used both by
 // the SplitClone and VerticalSplitClone command.
 // TODO(mberlin): Rename SplitCloneWorker to cloneWorker.
-func newCloneWorker(wr *wrangler.Wrangler, cloneType cloneType, cell, keyspace, shard string, online, offline bool, tables, excludeTables []string, strategyStr string, chunkCount, minRowsPerChunk, sourceReaderCount, writeQueryMaxRows, writeQueryMaxSize, writeQueryMaxRowsDelete, destinationWriterCount, minHealthyRdonlyTablets int, maxTPS, maxReplicationLag int64) (Worker, error) {
+func newCloneWorker(wr *wrangler.Wrangler, cloneType cloneType, cell, keyspace, shard string, online, offline, resumable bool, tables, excludeTables []string, sourceKeyspace, strategyStr string, chunkCount, minRowsPerChunk, sourceReaderCount, writeQueryMaxRows, writeQueryMaxSize, writeQueryMaxRowsDelete, destinationWriterCount, minHealthyRdonlyTablets int, maxTPS, maxReplicationLag int64) (Worker, error) {
 	if cloneType != horizontalResharding && cloneType != verticalSplit {
 		return nil, fmt.Errorf("unknown cloneType: %v This is a bug. Please report", cloneType)
 	}
@@ -175,8 +184,10 @@ func newCloneWorker(wr *wrangler.Wrangler, cloneType cloneType, cell, keyspace,
 		shard:                   shard,
 		online:                  online,
 		offline:                 offline,
+		resumable:               resumable,
 		tables:                  tables,
 		excludeTables:           excludeTables,
+		sourceKeyspace:          sourceKeyspace,
 		strategy:                strategy,
 		chunkCount:              chunkCount,
 		minRowsPerChunk:         minRowsPerChunk,
@@ -483,6 +494,13 @@ func (scw *SplitCloneWorker) run(ctx context.Context) error {
 		scw.wr.Logger().Infof("Offline clone skipped because --offline=false was specified.")
 	}
 
+	// Phase 5: (optional) remove the checkpoints because all phases succeeded.
+	if scw.resumable {
+		if err := scw.deleteCheckpoints(ctx); err != nil {
+			return fmt.Errorf("deleteCheckpoints() failed: %v", err)
+		}
+	}
+
 	return nil
 }
 
@@ -570,26 +588,34 @@ func (scw *SplitCloneWorker) initShardsForHorizontalResharding(ctx context.Conte
 }
 
 func (scw *SplitCloneWorker) initShardsForVerticalSplit(ctx context.Context) error {
-	if len(scw.destinationKeyspaceInfo.ServedFroms) == 0 {
-		return fmt.Errorf("destination keyspace %v has no KeyspaceServedFrom", scw.destinationKeyspace)
-	}
-
-	// Determine the source keyspace.
-	servedFrom := ""
-	for _, st := range servingTypes {
-		sf := scw.destinationKeyspaceInfo.GetServedFrom(st)
-		if sf == nil {
-			return fmt.Errorf("destination keyspace %v is serving type %v", scw.destinationKeyspace, st)
+	// Determine the source keyspace. It is given explicitly when the
+	// tables are moved into an existing keyspace (see vtctl MoveTables),
+	// which has no ServedFrom.
+	sourceKeyspace := scw.sourceKeyspace
+	if sourceKeyspace == scw.destinationKeyspace {
+		return fmt.Errorf("source keyspace %v must be different from the destination keyspace", sourceKeyspace)
+	}
+	if sourceKeyspace == "" {
+		if len(scw.destinationKeyspaceInfo.ServedFroms) == 0 {
+			return fmt.Errorf("destination keyspace %v has no KeyspaceServedFrom, and no source keyspace was specified", scw.destinationKeyspace)
 		}
-		if servedFrom == "" {
-			servedFrom = sf.Keyspace
-		} else {
-			if servedFrom != sf.Keyspace {
-				return fmt.Errorf("destination keyspace %v is serving from multiple source keyspaces %v and %v", scw.destinationKeyspace, servedFrom, sf.Keyspace)
+
+		servedFrom := ""
+		for _, st := range servingTypes {
+			sf := scw.destinationKeyspaceInfo.GetServedFrom(st)
+			if sf == nil {
+				return fmt.Errorf("destination keyspace %v is serving type %v", scw.destinationKeyspace, st)
+			}
+			if servedFrom == "" {
+				servedFrom = sf.Keyspace
+			} else {
+				if servedFrom != sf.Keyspace {
+					return fmt.Errorf("destination keyspace %v is serving from multiple source keyspaces %v and %v", scw.destinationKeyspace, servedFrom, sf.Keyspace)
+				}
 			}
 		}
+		sourceKeyspace = servedFrom
 	}
-	sourceKeyspace := servedFrom
 
 	// Init the source and destination shard info.
 	sourceShardInfo, err := scw.wr.TopoServer().GetShard(ctx, sourceKeyspace, scw.shard)
@@ -826,6 +852,12 @@ func (scw *SplitCloneWorker) clone(ctx context.Context, state StatusWorkerState)
 	}
 	defer scw.closeThrottlers()
 
+	if scw.resumable {
+		if err := scw.createCheckpointTables(ctx); err != nil {
+			return fmt.Errorf("cannot create the checkpoint table: %v", err)
+		}
+	}
+
 	sourceSchemaDefinition, err := scw.getSourceSchema(ctx, firstSourceTablet)
 	if err != nil {
 		return err
@@ -850,23 +882,23 @@ func (scw *SplitCloneWorker) clone(ctx context.Context, state StatusWorkerState)
 		mu.Unlock()
 	}
 
-	insertChannels := make([]chan string, len(scw.destinationShards))
+	insertChannels := make([]chan writeQuery, len(scw.destinationShards))
 	destinationWaitGroup := sync.WaitGroup{}
 	for shardIndex, si := range scw.destinationShards {
 		// We create one channel per destination tablet. It is sized to have a
 		// buffer of a maximum of destinationWriterCount * 2 items, to hopefully
 		// always have data. We then have destinationWriterCount go routines reading
 		// from it.
-		insertChannels[shardIndex] = make(chan string, scw.destinationWriterCount*2)
+		insertChannels[shardIndex] = make(chan writeQuery, scw.destinationWriterCount*2)
 
 		for j := 0; j < scw.destinationWriterCount; j++ {
 			destinationWaitGroup.Add(1)
-			go func(keyspace, shard string, insertChannel chan string, throttler *throttler.Throttler, threadID int) {
+			go func(keyspace, shard string, insertChannel chan writeQuery, throttler *throttler.Throttler, threadID int) {
 				defer destinationWaitGroup.Done()
 				defer throttler.ThreadFinished(threadID)
 
 				executor := newExecutor(scw.wr, scw.tsc, throttler, keyspace, shard, threadID)
-				if err := executor.fetchLoop(ctx, insertChannel); err != nil {
+				if err := executor.writeLoop(ctx, insertChannel); err != nil {
 					processError("executer.FetchLoop failed: %v", err)
 				}
 			}(si.Keyspace(), si.ShardName(), insertChannels[shardIndex], scw.getThrottler(si.Keyspace(), si.ShardName()), j)
@@ -887,7 +919,17 @@ func (scw *SplitCloneWorker) clone(ctx context.Context, state StatusWorkerState)
 
 		// TODO(mberlin): We're going to chunk *all* source shards based on the MIN
 		// and MAX values of the *first* source shard. Is this going to be a problem?
-		chunks, err := generateChunks(ctx, scw.wr, firstSourceTablet, td, scw.chunkCount, scw.minRowsPerChunk)
+		generate := func() ([]chunk, error) {
+			return generateChunks(ctx, scw.wr, firstSourceTablet, td, scw.chunkCount, scw.minRowsPerChunk)
+		}
+		var chunks []chunk
+		// doneChunks has the chunk numbers which a previous run marked as done.
+		var doneChunks map[int]bool
+		if scw.resumable {
+			chunks, doneChunks, err = scw.loadCheckpoint(ctx, td, generate)
+		} else {
+			chunks, err = generate()
+		}
 		if err != nil {
 			return err
 		}
@@ -916,6 +958,22 @@ func (scw *SplitCloneWorker) clone(ctx context.Context, state StatusWorkerState)
 					}
 				}
 
+				if doneChunks[chunk.number] {
+					// Skip the chunk if it was copied by a previous run and is still
+					// consistent.
+					upToDate, err := scw.verifyChunk(ctx, state, td, chunk)
+					if err != nil {
+						processError("%v: verifyChunk failed: %v", errPrefix, err)
+						return
+					}
+					if upToDate {
+						scw.wr.Logger().Infof("%v: Skipping chunk because it was marked as done and is still consistent.", errPrefix)
+						tableStatusList.threadDone(tableIndex)
+						return
+					}
+					scw.wr.Logger().Infof("%v: Copying chunk again because it was marked as done but has differences.", errPrefix)
+				}
+
 				// Set up readers for the diff. There will be one reader for every
 				// source and destination shard.
 				sourceReaders := make([]ResultReader, len(scw.sourceShards))
@@ -988,10 +1046,16 @@ func (scw *SplitCloneWorker) clone(ctx context.Context, state StatusWorkerState)
 					keyspaceAndShard := topoproto.KeyspaceShardString(si.Keyspace(), si.ShardName())
 					dbNames[i] = scw.destinationDbNames[keyspaceAndShard]
 				}
+				// writes tracks the write queries of this chunk. The chunk must not be
+				// marked as done before all of them were executed.
+				var writes *chunkWrites
+				if scw.resumable {
+					writes = newChunkWrites()
+				}
 				// Compare the data and reconcile any differences.
 				differ, err := NewRowDiffer2(ctx, sourceReader, destReader, td, tableStatusList, tableIndex,
 					scw.destinationShards, keyResolver,
-					insertChannels, ctx.Done(), dbNames, scw.writeQueryMaxRows, scw.writeQueryMaxSize, scw.writeQueryMaxRowsDelete, statsCounters)
+					insertChannels, writes, ctx.Done(), dbNames, scw.writeQueryMaxRows, scw.writeQueryMaxSize, scw.writeQueryMaxRowsDelete, statsCounters)
 				if err != nil {
 					processError("%v: NewRowDiffer2 failed: %v", errPrefix, err)
 					return
@@ -1003,6 +1067,18 @@ func (scw *SplitCloneWorker) clone(ctx context.Context, state StatusWorkerState)
 					return
 				}
 
+				if scw.resumable {
+					writes.seal()
+					if err := writes.wait(ctx); err != nil {
+						processError("%v: %v", errPrefix, err)
+						return
+					}
+					if err := scw.markChunkDone(ctx, td, chunk); err != nil {
+						processError("%v: markChunkDone failed: %v", errPrefix, err)
+						return
+					}
+				}
+
 				tableStatusList.threadDone(tableIndex)
 			}(td, tableIndex, c)
 		}
@@ -1023,14 +1099,13 @@ func (scw *SplitCloneWorker) clone(ctx context.Context, state StatusWorkerState)
 		if scw.strategy.skipPopulateBlpCheckpoint {
 			scw.wr.Logger().Infof("Skipping populating the blp_checkpoint table")
 		} else {
-			queries := make([]string, 0, 4)
-			queries = append(queries, binlogplayer.CreateBlpCheckpoint()...)
 			flags := ""
 			if scw.strategy.dontStartBinlogPlayer {
 				flags = binlogplayer.BlpFlagDontStart
 			}
 
 			// get the current position from the sources
+			sourcePositions := make([]string, len(scw.sourceShards))
 			for shardIndex := range scw.sourceShards {
 				shortCtx, cancel := context.WithTimeout(ctx, *remoteActionsTimeout)
 				status, err := scw.wr.TabletManagerClient().SlaveStatus(shortCtx, scw.sourceTablets[shardIndex])
@@ -1038,22 +1113,29 @@ func (scw *SplitCloneWorker) clone(ctx context.Context, state StatusWorkerState)
 				if err != nil {
 					return err
 				}
-
-				// TODO(mberlin): Fill in scw.maxReplicationLag once the adapative
-				//                throttler is enabled by default.
-				queries = append(queries, binlogplayer.PopulateBlpCheckpoint(uint32(shardIndex), status.Position, scw.maxTPS, throttler.ReplicationLagModuleDisabled, time.Now().Unix(), flags))
+				sourcePositions[shardIndex] = status.Position
 			}
 
 			for _, si := range scw.destinationShards {
+				// The uids are the indexes into the list of overlapping source
+				// shards, the same list SetSourceShards gets below.
+				queries := make([]string, 0, 4)
+				queries = append(queries, binlogplayer.CreateBlpCheckpoint()...)
+				for uid, shardIndex := range scw.overlappingSourceShards(si) {
+					// TODO(mberlin): Fill in scw.maxReplicationLag once the adapative
+					//                throttler is enabled by default.
+					queries = append(queries, binlogplayer.PopulateBlpCheckpoint(uint32(uid), sourcePositions[shardIndex], scw.maxTPS, throttler.ReplicationLagModuleDisabled, time.Now().Unix(), flags))
+				}
+
 				destinationWaitGroup.Add(1)
-				go func(keyspace, shard string) {
+				go func(keyspace, shard string, queries []string) {
 					defer destinationWaitGroup.Done()
 					scw.wr.Logger().Infof("Making and populating blp_checkpoint table")
 					keyspaceAndShard := topoproto.KeyspaceShardString(keyspace, shard)
 					if err := runSQLCommands(ctx, scw.wr, scw.tsc, keyspace, shard, scw.destinationDbNames[keyspaceAndShard], queries); err != nil {
 						processError("blp_checkpoint queries failed: %v", err)
 					}
-				}(si.Keyspace(), si.ShardName())
+				}(si.Keyspace(), si.ShardName(), queries)
 			}
 			destinationWaitGroup.Wait()
 			if firstError != nil {
@@ -1064,16 +1146,19 @@ func (scw *SplitCloneWorker) clone(ctx context.Context, state StatusWorkerState)
 		// Configure filtered replication by setting the SourceShard info.
 		// The master tablets won't enable filtered replication (the binlog player)
 		//  until they re-read the topology due to a restart or a reload.
-		// TODO(alainjobart) this is a superset, some shards may not
-		// overlap, have to deal with this better (for N -> M splits
-		// where both N>1 and M>1)
+		// Each destination shard replicates only from the source shards it
+		// overlaps with e.g. for N -> M splits where both N>1 and M>1.
 		if scw.strategy.skipSetSourceShards {
 			scw.wr.Logger().Infof("Skipping setting SourceShard on destination shards.")
 		} else {
 			for _, si := range scw.destinationShards {
+				var sourceAliases []*topodatapb.TabletAlias
+				for _, shardIndex := range scw.overlappingSourceShards(si) {
+					sourceAliases = append(sourceAliases, scw.offlineSourceAliases[shardIndex])
+				}
 				scw.wr.Logger().Infof("Setting SourceShard on shard %v/%v (tables: %v)", si.Keyspace(), si.ShardName(), scw.tables)
 				shortCtx, cancel := context.WithTimeout(ctx, *remoteActionsTimeout)
-				err := scw.wr.SetSourceShards(shortCtx, si.Keyspace(), si.ShardName(), scw.offlineSourceAliases, scw.tables)
+				err := scw.wr.SetSourceShards(shortCtx, si.Keyspace(), si.ShardName(), sourceAliases, scw.tables)
 				cancel()
 				if err != nil {
 					return fmt.Errorf("failed to set source shards: %v", err)
@@ -1110,6 +1195,19 @@ func (scw *SplitCloneWorker) clone(ctx context.Context, state StatusWorkerState)
 	return firstError
 }
 
+// overlappingSourceShards returns the indexes of the source shards whose
+// key range overlaps with the destination shard, in the order of
+// scw.sourceShards. When merging shards, that are all source shards.
+func (scw *SplitCloneWorker) overlappingSourceShards(destination *topo.ShardInfo) []int {
+	var result []int
+	for shardIndex, si := range scw.sourceShards {
+		if key.KeyRangesIntersect(si.KeyRange, destination.KeyRange) {
+			result = append(result, shardIndex)
+		}
+	}
+	return result
+}
+
 func (scw *SplitCloneWorker) getSourceSchema(ctx context.Context, tablet *topodatapb.Tablet) (*tabletmanagerdatapb.SchemaDefinition, error) {
 	// get source schema from the first shard
 	// TODO(alainjobart): for now, we assume the schema is compatible
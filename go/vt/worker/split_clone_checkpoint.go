@@ -0,0 +1,464 @@
+// Copyright 2016, Google Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package worker
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/youtube/vitess/go/sqltypes"
+	"github.com/youtube/vitess/go/vt/concurrency"
+	"github.com/youtube/vitess/go/vt/topo/topoproto"
+
+	tabletmanagerdatapb "github.com/youtube/vitess/go/vt/proto/tabletmanagerdata"
+	topodatapb "github.com/youtube/vitess/go/vt/proto/topodata"
+)
+
+// This file implements the checkpoints of a resumable SplitClone.
+//
+// With --resumable, SplitClone records the chunks of each table in the
+// _vt.split_clone_checkpoint table on every destination master. Each chunk
+// is marked as done (together with the current position of the destination
+// master) once its differences were reconciled.
+// A restarted SplitClone reuses the recorded chunks instead of generating new
+// ones. Before a chunk which is marked as done is skipped, it is verified that
+// the source and destination shards still have the same rows within the chunk.
+// The verification compares the number of rows and two checksums.
+// If the verification fails, the chunk is copied again.
+//
+// A chunk is marked as done only after all of its write queries were executed
+// on the destination masters (see chunkWrites).
+
+// maxCheckpointRows is the maximum number of chunks which are read from the
+// checkpoint table for a single table.
+const maxCheckpointRows = 100000
+
+// createSplitCloneCheckpoint returns the statements to create the
+// _vt.split_clone_checkpoint table.
+func createSplitCloneCheckpoint() []string {
+	return []string{
+		"CREATE DATABASE IF NOT EXISTS _vt",
+		`CREATE TABLE IF NOT EXISTS _vt.split_clone_checkpoint (
+  table_name VARBINARY(250) NOT NULL,
+  chunk_number INT(10) UNSIGNED NOT NULL,
+  chunk_total INT(10) UNSIGNED NOT NULL,
+  chunk_start VARBINARY(250) DEFAULT NULL,
+  chunk_end VARBINARY(250) DEFAULT NULL,
+  done TINYINT(1) NOT NULL,
+  pos VARCHAR(250) DEFAULT NULL,
+  time_updated BIGINT(20) UNSIGNED NOT NULL,
+  PRIMARY KEY (table_name, chunk_number)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8`}
+}
+
+// populateSplitCloneCheckpoint returns the statement to record the chunks of
+// a table. All chunks are recorded as not done yet.
+func populateSplitCloneCheckpoint(table string, chunks []chunk, timeUpdated int64) string {
+	b := bytes.Buffer{}
+	b.WriteString("INSERT INTO _vt.split_clone_checkpoint " +
+		"(table_name, chunk_number, chunk_total, chunk_start, chunk_end, done, pos, time_updated) VALUES ")
+	for i, c := range chunks {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteByte('(')
+		sqltypes.MakeString([]byte(table)).EncodeSQL(&b)
+		fmt.Fprintf(&b, ", %v, %v, ", c.number, c.total)
+		encodeChunkValue(&b, c.start)
+		b.WriteString(", ")
+		encodeChunkValue(&b, c.end)
+		fmt.Fprintf(&b, ", 0, NULL, %v)", timeUpdated)
+	}
+	return b.String()
+}
+
+// encodeChunkValue writes the raw value of a chunk boundary as a string.
+func encodeChunkValue(b *bytes.Buffer, v sqltypes.Value) {
+	if v.IsNull() {
+		b.WriteString("NULL")
+		return
+	}
+	sqltypes.MakeString(v.Raw()).EncodeSQL(b)
+}
+
+// markSplitCloneCheckpointDone returns the statement to mark a chunk as done.
+func markSplitCloneCheckpointDone(table string, c chunk, position string, timeUpdated int64) string {
+	b := bytes.Buffer{}
+	b.WriteString("UPDATE _vt.split_clone_checkpoint SET done=1, pos=")
+	sqltypes.MakeString([]byte(position)).EncodeSQL(&b)
+	fmt.Fprintf(&b, ", time_updated=%v WHERE table_name=", timeUpdated)
+	sqltypes.MakeString([]byte(table)).EncodeSQL(&b)
+	fmt.Fprintf(&b, " AND chunk_number=%v", c.number)
+	return b.String()
+}
+
+// deleteSplitCloneCheckpoint returns the statement to remove the checkpoints
+// of a table. If table is empty, the checkpoints of all tables are removed.
+func deleteSplitCloneCheckpoint(table string) string {
+	if table == "" {
+		return "DELETE FROM _vt.split_clone_checkpoint"
+	}
+	b := bytes.Buffer{}
+	b.WriteString("DELETE FROM _vt.split_clone_checkpoint WHERE table_name=")
+	sqltypes.MakeString([]byte(table)).EncodeSQL(&b)
+	return b.String()
+}
+
+// readSplitCloneCheckpoint returns the query which reads the checkpoints of a
+// table in the order expected by parseSplitCloneCheckpoint.
+func readSplitCloneCheckpoint(table string) string {
+	b := bytes.Buffer{}
+	b.WriteString("SELECT chunk_number, chunk_total, chunk_start, chunk_end, done FROM _vt.split_clone_checkpoint WHERE table_name=")
+	sqltypes.MakeString([]byte(table)).EncodeSQL(&b)
+	b.WriteString(" ORDER BY chunk_number")
+	return b.String()
+}
+
+// parseSplitCloneCheckpoint converts the result of readSplitCloneCheckpoint
+// back into the list of chunks. done has an entry for each chunk which was
+// marked as done. It returns no chunks if the recorded list is incomplete.
+func parseSplitCloneCheckpoint(qr *sqltypes.Result) ([]chunk, map[int]bool, error) {
+	var chunks []chunk
+	done := make(map[int]bool)
+	for i, row := range qr.Rows {
+		if len(row) != 5 {
+			return nil, nil, fmt.Errorf("checkpoint row has %v instead of 5 columns: %v", len(row), row)
+		}
+		number, err := row[0].ParseInt64()
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid chunk_number: %v", err)
+		}
+		total, err := row[1].ParseInt64()
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid chunk_total: %v", err)
+		}
+		if int(number) != i+1 || int(total) != len(qr.Rows) {
+			// The list was not written completely.
+			return nil, nil, nil
+		}
+		start, err := decodeChunkValue(row[2])
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid chunk_start of chunk %v: %v", number, err)
+		}
+		end, err := decodeChunkValue(row[3])
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid chunk_end of chunk %v: %v", number, err)
+		}
+		c, err := toChunk(start, end, int(number), int(total))
+		if err != nil {
+			return nil, nil, err
+		}
+		chunks = append(chunks, c)
+		if row[4].String() == "1" {
+			done[c.number] = true
+		}
+	}
+	return chunks, done, nil
+}
+
+// decodeChunkValue is the reverse of encodeChunkValue. Since generateChunks
+// only creates numeric boundaries, the value is parsed as a number.
+func decodeChunkValue(v sqltypes.Value) (interface{}, error) {
+	if v.IsNull() {
+		return nil, nil
+	}
+	s := v.String()
+	if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return i, nil
+	}
+	if u, err := strconv.ParseUint(s, 10, 64); err == nil {
+		return u, nil
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return nil, fmt.Errorf("value is not numeric: %v", s)
+	}
+	return f, nil
+}
+
+// sameChunks returns true if both lists have the same chunk boundaries.
+func sameChunks(a, b []chunk) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].number != b[i].number || a[i].total != b[i].total ||
+			a[i].start.String() != b[i].start.String() || a[i].end.String() != b[i].end.String() ||
+			a[i].start.IsNull() != b[i].start.IsNull() || a[i].end.IsNull() != b[i].end.IsNull() {
+			return false
+		}
+	}
+	return true
+}
+
+// chunkChecksum summarizes the rows of a chunk.
+type chunkChecksum struct {
+	count uint64
+	xor   uint64
+	sum   uint64
+}
+
+// add combines the checksum of a different shard into this one.
+func (c *chunkChecksum) add(other chunkChecksum) {
+	c.count += other.count
+	c.xor ^= other.xor
+	c.sum += other.sum
+}
+
+// chunkChecksumQuery returns the query which computes the checksum of a chunk.
+// Each row is hashed with CRC32. The ISNULL() values make sure that NULL and
+// an empty string are hashed differently.
+func chunkChecksumQuery(dbName string, td *tabletmanagerdatapb.TableDefinition, c chunk) string {
+	columns := escapeAll(orderedColumns(td))
+	values := make([]string, 0, 2*len(columns))
+	values = append(values, columns...)
+	for _, column := range columns {
+		values = append(values, "ISNULL("+column+")")
+	}
+	crc := "CRC32(CONCAT_WS('#', " + strings.Join(values, ", ") + "))"
+	query := fmt.Sprintf("SELECT COUNT(*), COALESCE(BIT_XOR(%v), 0), COALESCE(SUM(%v), 0) FROM %v.%v", crc, crc, escape(dbName), escape(td.Name))
+	if clauses := chunkWhereClauses(td, c); len(clauses) > 0 {
+		query += " WHERE " + strings.Join(clauses, " AND ")
+	}
+	return query
+}
+
+// parseChunkChecksum converts the result of chunkChecksumQuery.
+func parseChunkChecksum(qr *sqltypes.Result) (chunkChecksum, error) {
+	if len(qr.Rows) != 1 || len(qr.Rows[0]) != 3 {
+		return chunkChecksum{}, fmt.Errorf("checksum query returned an unexpected result: %v", qr.Rows)
+	}
+	var result chunkChecksum
+	for i, dst := range []*uint64{&result.count, &result.xor, &result.sum} {
+		v, err := strconv.ParseUint(qr.Rows[0][i].String(), 10, 64)
+		if err != nil {
+			return chunkChecksum{}, fmt.Errorf("checksum query returned an invalid value: %v", err)
+		}
+		*dst = v
+	}
+	return result, nil
+}
+
+// chunkWrites tracks the write queries of a chunk until they were executed by
+// the writer threads.
+type chunkWrites struct {
+	mu      sync.Mutex
+	pending int
+	sealed  bool
+	// executed is closed once the chunk was sealed and all queries were executed.
+	executed chan struct{}
+}
+
+func newChunkWrites() *chunkWrites {
+	return &chunkWrites{executed: make(chan struct{})}
+}
+
+// add registers a query which was sent to a writer thread.
+func (w *chunkWrites) add() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.pending++
+}
+
+// done is called after a query was executed.
+func (w *chunkWrites) done() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.pending--
+	if w.sealed && w.pending == 0 {
+		close(w.executed)
+	}
+}
+
+// seal is called once all queries of the chunk were sent.
+func (w *chunkWrites) seal() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.sealed = true
+	if w.pending == 0 {
+		close(w.executed)
+	}
+}
+
+// wait blocks until all queries of the sealed chunk were executed.
+func (w *chunkWrites) wait(ctx context.Context) error {
+	select {
+	case <-w.executed:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("interrupted while waiting for the write queries of the chunk: %v", ctx.Err())
+	}
+}
+
+// destinationMaster returns the current MASTER tablet of a destination shard.
+func (scw *SplitCloneWorker) destinationMaster(keyspace, shard string) (*topodatapb.Tablet, error) {
+	masters := scw.tsc.GetHealthyTabletStats(keyspace, shard, topodatapb.TabletType_MASTER)
+	if len(masters) == 0 {
+		return nil, fmt.Errorf("no MASTER tablet available for destination shard %v", topoproto.KeyspaceShardString(keyspace, shard))
+	}
+	return masters[0].Tablet, nil
+}
+
+// runOnDestinations runs the statements on all destination masters.
+func (scw *SplitCloneWorker) runOnDestinations(ctx context.Context, queries []string) error {
+	wg := sync.WaitGroup{}
+	rec := concurrency.AllErrorRecorder{}
+	for _, si := range scw.destinationShards {
+		wg.Add(1)
+		go func(keyspace, shard string) {
+			defer wg.Done()
+			keyspaceAndShard := topoproto.KeyspaceShardString(keyspace, shard)
+			if err := runSQLCommands(ctx, scw.wr, scw.tsc, keyspace, shard, scw.destinationDbNames[keyspaceAndShard], queries); err != nil {
+				rec.RecordError(fmt.Errorf("%v: %v", keyspaceAndShard, err))
+			}
+		}(si.Keyspace(), si.ShardName())
+	}
+	wg.Wait()
+	return rec.Error()
+}
+
+// createCheckpointTables creates the checkpoint table on all destination
+// masters.
+func (scw *SplitCloneWorker) createCheckpointTables(ctx context.Context) error {
+	return scw.runOnDestinations(ctx, createSplitCloneCheckpoint())
+}
+
+// deleteCheckpoints removes all checkpoints from the destination masters.
+func (scw *SplitCloneWorker) deleteCheckpoints(ctx context.Context) error {
+	return scw.runOnDestinations(ctx, []string{deleteSplitCloneCheckpoint("")})
+}
+
+// loadCheckpoint returns the chunks of a table as they were recorded by a
+// previous run, together with the chunks which were marked as done.
+// If the destination masters do not have the same complete list of chunks,
+// generate is called and its chunks are recorded instead.
+func (scw *SplitCloneWorker) loadCheckpoint(ctx context.Context, td *tabletmanagerdatapb.TableDefinition, generate func() ([]chunk, error)) ([]chunk, map[int]bool, error) {
+	var chunks []chunk
+	var done map[int]bool
+	consistent := true
+	for i, si := range scw.destinationShards {
+		master, err := scw.destinationMaster(si.Keyspace(), si.ShardName())
+		if err != nil {
+			return nil, nil, err
+		}
+		shortCtx, cancel := context.WithTimeout(ctx, *remoteActionsTimeout)
+		qr, err := scw.wr.TabletManagerClient().ExecuteFetchAsApp(shortCtx, master, true, []byte(readSplitCloneCheckpoint(td.Name)), maxCheckpointRows)
+		cancel()
+		if err != nil {
+			return nil, nil, fmt.Errorf("cannot read the checkpoint of table %v on %v: %v", td.Name, topoproto.TabletAliasString(master.Alias), err)
+		}
+		shardChunks, shardDone, err := parseSplitCloneCheckpoint(sqltypes.Proto3ToResult(qr))
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid checkpoint of table %v on %v: %v", td.Name, topoproto.TabletAliasString(master.Alias), err)
+		}
+		if i == 0 {
+			chunks = shardChunks
+			done = shardDone
+			continue
+		}
+		if !sameChunks(chunks, shardChunks) {
+			consistent = false
+			break
+		}
+		// A chunk is only done if it is done on all destination shards.
+		for number := range done {
+			if !shardDone[number] {
+				delete(done, number)
+			}
+		}
+	}
+	if consistent && len(chunks) > 0 {
+		scw.wr.Logger().Infof("table=%v: Resuming from checkpoint: %v of %v chunks are marked as done.", td.Name, len(done), len(chunks))
+		return chunks, done, nil
+	}
+
+	chunks, err := generate()
+	if err != nil {
+		return nil, nil, err
+	}
+	queries := []string{
+		deleteSplitCloneCheckpoint(td.Name),
+		populateSplitCloneCheckpoint(td.Name, chunks, time.Now().Unix()),
+	}
+	if err := scw.runOnDestinations(ctx, queries); err != nil {
+		return nil, nil, fmt.Errorf("cannot record the checkpoint of table %v: %v", td.Name, err)
+	}
+	return chunks, make(map[int]bool), nil
+}
+
+// markChunkDone records on each destination master that the chunk was
+// copied, together with the current position of the master.
+func (scw *SplitCloneWorker) markChunkDone(ctx context.Context, td *tabletmanagerdatapb.TableDefinition, c chunk) error {
+	for _, si := range scw.destinationShards {
+		master, err := scw.destinationMaster(si.Keyspace(), si.ShardName())
+		if err != nil {
+			return err
+		}
+		shortCtx, cancel := context.WithTimeout(ctx, *remoteActionsTimeout)
+		position, err := scw.wr.TabletManagerClient().MasterPosition(shortCtx, master)
+		cancel()
+		if err != nil {
+			return fmt.Errorf("cannot get the position of %v: %v", topoproto.TabletAliasString(master.Alias), err)
+		}
+		keyspaceAndShard := topoproto.KeyspaceShardString(si.Keyspace(), si.ShardName())
+		query := markSplitCloneCheckpointDone(td.Name, c, position, time.Now().Unix())
+		if err := runSQLCommands(ctx, scw.wr, scw.tsc, si.Keyspace(), si.ShardName(), scw.destinationDbNames[keyspaceAndShard], []string{query}); err != nil {
+			return fmt.Errorf("cannot mark the chunk as done on %v: %v", keyspaceAndShard, err)
+		}
+	}
+	return nil
+}
+
+// verifyChunk returns true if the source and destination shards have the
+// same rows within the chunk.
+func (scw *SplitCloneWorker) verifyChunk(ctx context.Context, state StatusWorkerState, td *tabletmanagerdatapb.TableDefinition, c chunk) (bool, error) {
+	var source, destination chunkChecksum
+	for shardIndex, si := range scw.sourceShards {
+		var tablet *topodatapb.Tablet
+		if state == WorkerStateCloneOffline {
+			tablet = scw.sourceTablets[shardIndex]
+		} else {
+			tablets := scw.tsc.GetHealthyTabletStats(si.Keyspace(), si.ShardName(), topodatapb.TabletType_RDONLY)
+			if len(tablets) == 0 {
+				return false, fmt.Errorf("no healthy RDONLY tablet in source shard (%v) available", topoproto.KeyspaceShardString(si.Keyspace(), si.ShardName()))
+			}
+			tablet = tablets[0].Tablet
+		}
+		checksum, err := scw.chunkChecksum(ctx, tablet, topoproto.TabletDbName(tablet), td, c)
+		if err != nil {
+			return false, err
+		}
+		source.add(checksum)
+	}
+	for _, si := range scw.destinationShards {
+		master, err := scw.destinationMaster(si.Keyspace(), si.ShardName())
+		if err != nil {
+			return false, err
+		}
+		keyspaceAndShard := topoproto.KeyspaceShardString(si.Keyspace(), si.ShardName())
+		checksum, err := scw.chunkChecksum(ctx, master, scw.destinationDbNames[keyspaceAndShard], td, c)
+		if err != nil {
+			return false, err
+		}
+		destination.add(checksum)
+	}
+	return source == destination, nil
+}
+
+func (scw *SplitCloneWorker) chunkChecksum(ctx context.Context, tablet *topodatapb.Tablet, dbName string, td *tabletmanagerdatapb.TableDefinition, c chunk) (chunkChecksum, error) {
+	shortCtx, cancel := context.WithTimeout(ctx, *remoteActionsTimeout)
+	qr, err := scw.wr.TabletManagerClient().ExecuteFetchAsApp(shortCtx, tablet, true, []byte(chunkChecksumQuery(dbName, td, c)), 1)
+	cancel()
+	if err != nil {
+		return chunkChecksum{}, fmt.Errorf("checksum query failed on %v: %v", topoproto.TabletAliasString(tablet.Alias), err)
+	}
+	return parseChunkChecksum(sqltypes.Proto3ToResult(qr))
+}
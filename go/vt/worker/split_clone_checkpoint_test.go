@@ -0,0 +1,139 @@
+// Copyright 2016, Google Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package worker
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/youtube/vitess/go/sqltypes"
+
+	tabletmanagerdatapb "github.com/youtube/vitess/go/vt/proto/tabletmanagerdata"
+)
+
+func mustChunk(t *testing.T, start, end interface{}, number, total int) chunk {
+	c, err := toChunk(start, end, number, total)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+// checkpointRow returns a row as it is returned by readSplitCloneCheckpoint.
+func checkpointRow(number, total, start, end, done string) []sqltypes.Value {
+	row := []sqltypes.Value{
+		sqltypes.MakeTrusted(sqltypes.Uint32, []byte(number)),
+		sqltypes.MakeTrusted(sqltypes.Uint32, []byte(total)),
+		sqltypes.NULL,
+		sqltypes.NULL,
+		sqltypes.MakeTrusted(sqltypes.Int8, []byte(done)),
+	}
+	if start != "" {
+		row[2] = sqltypes.MakeTrusted(sqltypes.VarBinary, []byte(start))
+	}
+	if end != "" {
+		row[3] = sqltypes.MakeTrusted(sqltypes.VarBinary, []byte(end))
+	}
+	return row
+}
+
+func TestSplitCloneCheckpointQueries(t *testing.T) {
+	chunks := []chunk{
+		mustChunk(t, nil, int64(100), 1, 2),
+		mustChunk(t, int64(100), nil, 2, 2),
+	}
+	want := "INSERT INTO _vt.split_clone_checkpoint " +
+		"(table_name, chunk_number, chunk_total, chunk_start, chunk_end, done, pos, time_updated) VALUES " +
+		"('table1', 1, 2, NULL, '100', 0, NULL, 1000),('table1', 2, 2, '100', NULL, 0, NULL, 1000)"
+	if got := populateSplitCloneCheckpoint("table1", chunks, 1000); got != want {
+		t.Errorf("populateSplitCloneCheckpoint() = %v, want %v", got, want)
+	}
+
+	want = "UPDATE _vt.split_clone_checkpoint SET done=1, pos='MariaDB/0-1-123', time_updated=1000 WHERE table_name='table1' AND chunk_number=2"
+	if got := markSplitCloneCheckpointDone("table1", chunks[1], "MariaDB/0-1-123", 1000); got != want {
+		t.Errorf("markSplitCloneCheckpointDone() = %v, want %v", got, want)
+	}
+
+	want = "DELETE FROM _vt.split_clone_checkpoint WHERE table_name='table1'"
+	if got := deleteSplitCloneCheckpoint("table1"); got != want {
+		t.Errorf("deleteSplitCloneCheckpoint() = %v, want %v", got, want)
+	}
+	want = "DELETE FROM _vt.split_clone_checkpoint"
+	if got := deleteSplitCloneCheckpoint(""); got != want {
+		t.Errorf("deleteSplitCloneCheckpoint() = %v, want %v", got, want)
+	}
+}
+
+func TestParseSplitCloneCheckpoint(t *testing.T) {
+	qr := &sqltypes.Result{
+		Rows: [][]sqltypes.Value{
+			checkpointRow("1", "3", "", "-5", "1"),
+			checkpointRow("2", "3", "-5", "18446744073709551000", "0"),
+			checkpointRow("3", "3", "18446744073709551000", "", "1"),
+		},
+	}
+	chunks, done, err := parseSplitCloneCheckpoint(qr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []chunk{
+		mustChunk(t, nil, int64(-5), 1, 3),
+		mustChunk(t, int64(-5), uint64(18446744073709551000), 2, 3),
+		mustChunk(t, uint64(18446744073709551000), nil, 3, 3),
+	}
+	if !sameChunks(chunks, want) {
+		t.Errorf("parseSplitCloneCheckpoint() = %v, want %v", chunks, want)
+	}
+	if wantDone := map[int]bool{1: true, 3: true}; !reflect.DeepEqual(done, wantDone) {
+		t.Errorf("parseSplitCloneCheckpoint() done = %v, want %v", done, wantDone)
+	}
+
+	// A list which was not written completely is ignored.
+	qr.Rows = qr.Rows[:2]
+	chunks, _, err = parseSplitCloneCheckpoint(qr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if chunks != nil {
+		t.Errorf("parseSplitCloneCheckpoint() of an incomplete list = %v, want no chunks", chunks)
+	}
+
+	// Non-numeric boundaries are rejected.
+	qr.Rows = [][]sqltypes.Value{checkpointRow("1", "1", "abc", "", "0")}
+	if _, _, err := parseSplitCloneCheckpoint(qr); err == nil {
+		t.Errorf("parseSplitCloneCheckpoint() should fail for a non-numeric boundary")
+	}
+}
+
+func TestChunkChecksum(t *testing.T) {
+	td := &tabletmanagerdatapb.TableDefinition{
+		Name:              "table1",
+		Columns:           []string{"msg", "id"},
+		PrimaryKeyColumns: []string{"id"},
+	}
+	c := mustChunk(t, int64(10), int64(20), 2, 3)
+	crc := "CRC32(CONCAT_WS('#', `id`, `msg`, ISNULL(`id`), ISNULL(`msg`)))"
+	want := "SELECT COUNT(*), COALESCE(BIT_XOR(" + crc + "), 0), COALESCE(SUM(" + crc + "), 0) FROM `vt_ks`.`table1` WHERE `id`>=10 AND `id`<20"
+	if got := chunkChecksumQuery("vt_ks", td, c); got != want {
+		t.Errorf("chunkChecksumQuery() = %v, want %v", got, want)
+	}
+
+	qr := &sqltypes.Result{
+		Rows: [][]sqltypes.Value{{
+			sqltypes.MakeTrusted(sqltypes.Int64, []byte("2")),
+			sqltypes.MakeTrusted(sqltypes.Uint64, []byte("6")),
+			sqltypes.MakeTrusted(sqltypes.Decimal, []byte("10")),
+		}},
+	}
+	left, err := parseChunkChecksum(qr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	right := chunkChecksum{count: 1, xor: 3, sum: 3}
+	left.add(right)
+	if want := (chunkChecksum{count: 3, xor: 5, sum: 13}); left != want {
+		t.Errorf("combined checksum = %+v, want %+v", left, want)
+	}
+}
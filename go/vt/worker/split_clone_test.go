@@ -8,6 +8,9 @@ import (
 	"errors"
 	"fmt"
 	"math"
+	"reflect"
+	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -16,6 +19,7 @@ import (
 
 	"github.com/youtube/vitess/go/sqltypes"
 	"github.com/youtube/vitess/go/vt/concurrency"
+	"github.com/youtube/vitess/go/vt/dbconnpool"
 	"github.com/youtube/vitess/go/vt/mysqlctl/replication"
 	"github.com/youtube/vitess/go/vt/mysqlctl/tmutils"
 	"github.com/youtube/vitess/go/vt/tabletmanager/tmclient"
@@ -1036,6 +1040,236 @@ func TestSplitCloneV2_NoMasterAvailable(t *testing.T) {
 	}
 }
 
+// queryFakeDb fakes out a MySQL database which answers each query based on
+// its prefix. Unlike FakePoolConnection, it does not expect the queries in a
+// specific order. Queries without a result return an empty result.
+type queryFakeDb struct {
+	*FakePoolConnection
+
+	// mu guards the fields in this group.
+	mu sync.Mutex
+	// results is keyed by the query prefix.
+	results map[string]*sqltypes.Result
+	// queries has all executed queries.
+	queries []string
+}
+
+func newQueryFakeDb(t *testing.T, name string, results map[string]*sqltypes.Result) *queryFakeDb {
+	return &queryFakeDb{
+		FakePoolConnection: NewFakePoolConnectionQuery(t, name),
+		results:            results,
+	}
+}
+
+func (f *queryFakeDb) factory() func() (dbconnpool.PoolConnection, error) {
+	return func() (dbconnpool.PoolConnection, error) {
+		return f, nil
+	}
+}
+
+// ExecuteFetch implements dbconnpool.PoolConnection.
+func (f *queryFakeDb) ExecuteFetch(query string, maxrows int, wantfields bool) (*sqltypes.Result, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.t.Logf("ExecuteFetch: %v: %v", f.name, query)
+	f.queries = append(f.queries, query)
+	for prefix, qr := range f.results {
+		if strings.HasPrefix(query, prefix) {
+			return qr, nil
+		}
+	}
+	return &sqltypes.Result{}, nil
+}
+
+// matches returns the values of the first group of re in all executed
+// queries.
+func (f *queryFakeDb) matches(re *regexp.Regexp) []int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var result []int
+	for _, query := range f.queries {
+		for _, m := range re.FindAllStringSubmatch(query, -1) {
+			v, err := strconv.Atoi(m[1])
+			if err != nil {
+				f.t.Fatal(err)
+			}
+			result = append(result, v)
+		}
+	}
+	sort.Ints(result)
+	return result
+}
+
+// verifyDoneAfterInserts returns an error if a chunk was marked as done
+// before all of its inserts were executed. split is the boundary between the
+// first and the second chunk.
+func (f *queryFakeDb) verifyDoneAfterInserts(split int) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	done := make(map[int]bool)
+	for _, query := range f.queries {
+		if m := doneChunkRegexp.FindStringSubmatch(query); m != nil {
+			number, _ := strconv.Atoi(m[1])
+			done[number] = true
+			continue
+		}
+		for _, m := range insertedIDRegexp.FindAllStringSubmatch(query, -1) {
+			id, _ := strconv.Atoi(m[1])
+			number := 1
+			if id >= split {
+				number = 2
+			}
+			if done[number] {
+				return fmt.Errorf("row %v was inserted after chunk %v was marked as done", id, number)
+			}
+		}
+	}
+	return nil
+}
+
+func (f *queryFakeDb) count(query string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	count := 0
+	for _, q := range f.queries {
+		if q == query {
+			count++
+		}
+	}
+	return count
+}
+
+var (
+	insertedIDRegexp = regexp.MustCompile(`\((\d+),'Text for `)
+	doneChunkRegexp  = regexp.MustCompile(`^UPDATE _vt.split_clone_checkpoint SET done=1.* AND chunk_number=(\d+)$`)
+)
+
+func checksumResult(count, xor, sum string) *sqltypes.Result {
+	return &sqltypes.Result{
+		Fields: []*querypb.Field{
+			{Name: "count", Type: sqltypes.Int64},
+			{Name: "xor", Type: sqltypes.Uint64},
+			{Name: "sum", Type: sqltypes.Uint64},
+		},
+		Rows: [][]sqltypes.Value{
+			{
+				sqltypes.MakeTrusted(sqltypes.Int64, []byte(count)),
+				sqltypes.MakeTrusted(sqltypes.Uint64, []byte(xor)),
+				sqltypes.MakeTrusted(sqltypes.Uint64, []byte(sum)),
+			},
+		},
+	}
+}
+
+func intRange(from, to int) []int {
+	var result []int
+	for i := from; i < to; i++ {
+		result = append(result, i)
+	}
+	return result
+}
+
+// TestSplitCloneV2_Offline_Resumable tests that SplitClone --resumable resumes
+// from the checkpoints which a previous run left on the destination masters.
+// The first of the two chunks is marked as done. It is skipped if the
+// checksums of the source and the destination shards are equal and copied
+// again otherwise. The second chunk is always copied.
+func TestSplitCloneV2_Offline_Resumable(t *testing.T) {
+	testCases := []struct {
+		desc string
+		// rightCount is the number of rows in the first chunk on the right
+		// destination shard.
+		rightCount string
+		wantIDs    []int
+		wantDone   []int
+	}{
+		{
+			desc:       "consistent chunk is skipped",
+			rightCount: "25",
+			wantIDs:    intRange(150, 200),
+			wantDone:   []int{2},
+		},
+		{
+			desc:       "inconsistent chunk is copied again",
+			rightCount: "20",
+			wantIDs:    intRange(100, 200),
+			wantDone:   []int{1, 2},
+		},
+	}
+	for _, tcase := range testCases {
+		func() {
+			tc := &splitCloneTestCase{t: t}
+			tc.setUpWithConcurreny(false /* v3 */, 2, 10, splitCloneTestRowsCount)
+			defer tc.tearDown()
+
+			// The regular destination fakes expect the queries in a fixed order.
+			// Instead, this test uses fakes which answer the queries in any order.
+			tc.leftMasterFakeDb.deleteAllEntries()
+			tc.rightMasterFakeDb.deleteAllEntries()
+			checkpoint := &sqltypes.Result{
+				Fields: []*querypb.Field{
+					{Name: "chunk_number", Type: sqltypes.Uint32},
+					{Name: "chunk_total", Type: sqltypes.Uint32},
+					{Name: "chunk_start", Type: sqltypes.VarBinary},
+					{Name: "chunk_end", Type: sqltypes.VarBinary},
+					{Name: "done", Type: sqltypes.Int8},
+				},
+				Rows: [][]sqltypes.Value{
+					checkpointRow("1", "2", "", "150", "1"),
+					checkpointRow("2", "2", "150", "", "0"),
+				},
+			}
+			// The checksums of the first chunk. They are equal if the counts,
+			// the combined XOR values (1^2) and the sums (100+200) match.
+			source := newQueryFakeDb(t, "source", map[string]*sqltypes.Result{
+				"SELECT COUNT(*)": checksumResult("50", "3", "300"),
+			})
+			left := newQueryFakeDb(t, "leftMaster", map[string]*sqltypes.Result{
+				"SELECT chunk_number": checkpoint,
+				"SELECT COUNT(*)":     checksumResult("25", "1", "100"),
+			})
+			right := newQueryFakeDb(t, "rightMaster", map[string]*sqltypes.Result{
+				"SELECT chunk_number": checkpoint,
+				"SELECT COUNT(*)":     checksumResult(tcase.rightCount, "2", "200"),
+			})
+			sourceRdonly1, sourceRdonly2, leftMaster, rightMaster := tc.tablets[1], tc.tablets[2], tc.tablets[3], tc.tablets[7]
+			sourceRdonly1.FakeMysqlDaemon.DbAppConnectionFactory = source.factory()
+			sourceRdonly2.FakeMysqlDaemon.DbAppConnectionFactory = source.factory()
+			leftMaster.FakeMysqlDaemon.DbAppConnectionFactory = left.factory()
+			rightMaster.FakeMysqlDaemon.DbAppConnectionFactory = right.factory()
+
+			// Run the vtworker command.
+			args := []string{"SplitClone", "-resumable"}
+			args = append(args, tc.defaultWorkerArgs[1:]...)
+			if err := runCommand(t, tc.wi, tc.wi.wr, args); err != nil {
+				t.Fatalf("%v: %v", tcase.desc, err)
+			}
+
+			ids := append(left.matches(insertedIDRegexp), right.matches(insertedIDRegexp)...)
+			sort.Ints(ids)
+			if !reflect.DeepEqual(ids, tcase.wantIDs) {
+				t.Errorf("%v: inserted ids = %v, want = %v", tcase.desc, ids, tcase.wantIDs)
+			}
+			for _, db := range []*queryFakeDb{left, right} {
+				if got := db.matches(doneChunkRegexp); !reflect.DeepEqual(got, tcase.wantDone) {
+					t.Errorf("%v: %v: chunks marked as done = %v, want = %v", tcase.desc, db.name, got, tcase.wantDone)
+				}
+				if err := db.verifyDoneAfterInserts(150); err != nil {
+					t.Errorf("%v: %v: %v", tcase.desc, db.name, err)
+				}
+				// The checkpoints are removed after the clone succeeded.
+				if got := db.count(deleteSplitCloneCheckpoint("")); got != 1 {
+					t.Errorf("%v: %v: checkpoints were deleted %v times, want once", tcase.desc, db.name, got)
+				}
+			}
+		}()
+	}
+}
+
 func TestSplitCloneV3(t *testing.T) {
 	tc := &splitCloneTestCase{t: t}
 	tc.setUp(true /* v3 */)
@@ -5,8 +5,10 @@
 package worker
 
 import (
+	"bytes"
 	"fmt"
 	"html/template"
+	"sort"
 	"sync"
 
 	"golang.org/x/net/context"
@@ -24,6 +26,10 @@ import (
 	topodatapb "github.com/youtube/vitess/go/vt/proto/topodata"
 )
 
+// allSourceShards is the sourceUID to diff a destination shard against the
+// union of all its source shards e.g. when shards were merged.
+const allSourceShards = -1
+
 // SplitDiffWorker executes a diff between a destination shard and its
 // source shards in a shard split case.
 type SplitDiffWorker struct {
@@ -33,7 +39,7 @@ type SplitDiffWorker struct {
 	cell                    string
 	keyspace                string
 	shard                   string
-	sourceUID               uint32
+	sourceUID               int
 	excludeTables           []string
 	minHealthyRdonlyTablets int
 	cleaner                 *wrangler.Cleaner
@@ -41,18 +47,23 @@ type SplitDiffWorker struct {
 	// populated during WorkerStateInit, read-only after that
 	keyspaceInfo *topo.KeyspaceInfo
 	shardInfo    *topo.ShardInfo
+	// sourceShards are the SourceShards the destination is compared with.
+	sourceShards []*topodatapb.Shard_SourceShard
 
 	// populated during WorkerStateFindTargets, read-only after that
-	sourceAlias      *topodatapb.TabletAlias
+	// sourceAliases has one tablet for each entry in sourceShards.
+	sourceAliases    []*topodatapb.TabletAlias
 	destinationAlias *topodatapb.TabletAlias
 
 	// populated during WorkerStateDiff
-	sourceSchemaDefinition      *tabletmanagerdatapb.SchemaDefinition
+	sourceSchemaDefinitions     []*tabletmanagerdatapb.SchemaDefinition
 	destinationSchemaDefinition *tabletmanagerdatapb.SchemaDefinition
 }
 
 // NewSplitDiffWorker returns a new SplitDiffWorker object.
-func NewSplitDiffWorker(wr *wrangler.Wrangler, cell, keyspace, shard string, sourceUID uint32, excludeTables []string, minHealthyRdonlyTablets int) Worker {
+// If sourceUID is allSourceShards, the destination shard is compared
+// with the union of all its source shards.
+func NewSplitDiffWorker(wr *wrangler.Wrangler, cell, keyspace, shard string, sourceUID int, excludeTables []string, minHealthyRdonlyTablets int) Worker {
 	return &SplitDiffWorker{
 		StatusWorker:            NewStatusWorker(),
 		wr:                      wr,
@@ -177,13 +188,12 @@ func (sdw *SplitDiffWorker) init(ctx context.Context) error {
 	if len(sdw.shardInfo.SourceShards) == 0 {
 		return fmt.Errorf("shard %v/%v has no source shard", sdw.keyspace, sdw.shard)
 	}
-	foundSourceShard := false
 	for _, ss := range sdw.shardInfo.SourceShards {
-		if ss.Uid == sdw.sourceUID {
-			foundSourceShard = true
+		if sdw.sourceUID == allSourceShards || ss.Uid == uint32(sdw.sourceUID) {
+			sdw.sourceShards = append(sdw.sourceShards, ss)
 		}
 	}
-	if !foundSourceShard {
+	if len(sdw.sourceShards) == 0 {
 		return fmt.Errorf("shard %v/%v has no source shard with UID %v", sdw.keyspace, sdw.shard, sdw.sourceUID)
 	}
 
@@ -208,13 +218,12 @@ func (sdw *SplitDiffWorker) findTargets(ctx context.Context) error {
 		return fmt.Errorf("FindWorkerTablet() failed for %v/%v/%v: %v", sdw.cell, sdw.keyspace, sdw.shard, err)
 	}
 
-	// find an appropriate tablet in the source shard
-	for _, ss := range sdw.shardInfo.SourceShards {
-		if ss.Uid == sdw.sourceUID {
-			sdw.sourceAlias, err = FindWorkerTablet(ctx, sdw.wr, sdw.cleaner, nil /* tsc */, sdw.cell, sdw.keyspace, ss.Shard, sdw.minHealthyRdonlyTablets)
-			if err != nil {
-				return fmt.Errorf("FindWorkerTablet() failed for %v/%v/%v: %v", sdw.cell, sdw.keyspace, ss.Shard, err)
-			}
+	// find an appropriate tablet in each source shard
+	sdw.sourceAliases = make([]*topodatapb.TabletAlias, len(sdw.sourceShards))
+	for i, ss := range sdw.sourceShards {
+		sdw.sourceAliases[i], err = FindWorkerTablet(ctx, sdw.wr, sdw.cleaner, nil /* tsc */, sdw.cell, sdw.keyspace, ss.Shard, sdw.minHealthyRdonlyTablets)
+		if err != nil {
+			return fmt.Errorf("FindWorkerTablet() failed for %v/%v/%v: %v", sdw.cell, sdw.keyspace, ss.Shard, err)
 		}
 	}
 
@@ -225,7 +234,7 @@ func (sdw *SplitDiffWorker) findTargets(ctx context.Context) error {
 // 1 - ask the master of the destination shard to pause filtered replication,
 //   and return the source binlog positions
 //   (add a cleanup task to restart filtered replication on master)
-// 2 - stop the source tablet at a binlog position higher than the
+// 2 - stop each source tablet at a binlog position higher than the
 //   destination master. Get that new list of positions.
 //   (add a cleanup task to restart binlog replication on the source tablet, and
 //    change the existing ChangeSlaveType cleanup action to 'spare' type)
@@ -237,8 +246,8 @@ func (sdw *SplitDiffWorker) findTargets(ctx context.Context) error {
 //    the existing ChangeSlaveType cleanup action to 'spare' type)
 // 5 - restart filtered replication on the destination master.
 //   (remove the cleanup task that does the same)
-// At this point, the source and the destination tablet are stopped at the same
-// point.
+// At this point, the source tablets and the destination tablet are stopped at
+// the same point.
 
 func (sdw *SplitDiffWorker) synchronizeReplication(ctx context.Context) error {
 	sdw.SetState(WorkerStateSyncReplication)
@@ -260,41 +269,41 @@ func (sdw *SplitDiffWorker) synchronizeReplication(ctx context.Context) error {
 	}
 	wrangler.RecordStartBlpAction(sdw.cleaner, masterInfo.Tablet)
 
-	// 2 - stop the source tablet at a binlog position
+	// 2 - stop each source tablet at a binlog position
 	//     higher than the destination master
+	stopPositionList := make([]*tabletmanagerdatapb.BlpPosition, len(sdw.sourceShards))
+	for i, ss := range sdw.sourceShards {
+		// find where we should be stopping
+		blpPos := tmutils.FindBlpPositionByID(blpPositionList, ss.Uid)
+		if blpPos == nil {
+			return fmt.Errorf("no binlog position on the master for Uid %v", ss.Uid)
+		}
 
-	// find where we should be stopping
-	blpPos := tmutils.FindBlpPositionByID(blpPositionList, sdw.sourceUID)
-	if blpPos == nil {
-		return fmt.Errorf("no binlog position on the master for Uid %v", sdw.sourceUID)
-	}
-
-	// read the tablet
-	shortCtx, cancel = context.WithTimeout(ctx, *remoteActionsTimeout)
-	sourceTablet, err := sdw.wr.TopoServer().GetTablet(shortCtx, sdw.sourceAlias)
-	cancel()
-	if err != nil {
-		return err
-	}
+		// read the tablet
+		shortCtx, cancel = context.WithTimeout(ctx, *remoteActionsTimeout)
+		sourceTablet, err := sdw.wr.TopoServer().GetTablet(shortCtx, sdw.sourceAliases[i])
+		cancel()
+		if err != nil {
+			return err
+		}
 
-	// stop replication
-	sdw.wr.Logger().Infof("Stopping slave %v at a minimum of %v", sdw.sourceAlias, blpPos.Position)
-	shortCtx, cancel = context.WithTimeout(ctx, *remoteActionsTimeout)
-	stoppedAt, err := sdw.wr.TabletManagerClient().StopSlaveMinimum(shortCtx, sourceTablet.Tablet, blpPos.Position, *remoteActionsTimeout)
-	cancel()
-	if err != nil {
-		return fmt.Errorf("cannot stop slave %v at right binlog position %v: %v", sdw.sourceAlias, blpPos.Position, err)
-	}
-	stopPositionList := []*tabletmanagerdatapb.BlpPosition{
-		{
-			Uid:      sdw.sourceUID,
+		// stop replication
+		sdw.wr.Logger().Infof("Stopping slave %v at a minimum of %v", sdw.sourceAliases[i], blpPos.Position)
+		shortCtx, cancel = context.WithTimeout(ctx, *remoteActionsTimeout)
+		stoppedAt, err := sdw.wr.TabletManagerClient().StopSlaveMinimum(shortCtx, sourceTablet.Tablet, blpPos.Position, *remoteActionsTimeout)
+		cancel()
+		if err != nil {
+			return fmt.Errorf("cannot stop slave %v at right binlog position %v: %v", sdw.sourceAliases[i], blpPos.Position, err)
+		}
+		stopPositionList[i] = &tabletmanagerdatapb.BlpPosition{
+			Uid:      ss.Uid,
 			Position: stoppedAt,
-		},
-	}
+		}
 
-	// change the cleaner actions from ChangeSlaveType(rdonly)
-	// to StartSlave() + ChangeSlaveType(spare)
-	wrangler.RecordStartSlaveAction(sdw.cleaner, sourceTablet.Tablet)
+		// change the cleaner actions from ChangeSlaveType(rdonly)
+		// to StartSlave() + ChangeSlaveType(spare)
+		wrangler.RecordStartSlaveAction(sdw.cleaner, sourceTablet.Tablet)
+	}
 
 	// 3 - ask the master of the destination shard to resume filtered
 	//     replication up to the new list of positions
@@ -347,6 +356,7 @@ func (sdw *SplitDiffWorker) diff(ctx context.Context) error {
 	sdw.SetState(WorkerStateDiff)
 
 	sdw.wr.Logger().Infof("Gathering schema information...")
+	sdw.sourceSchemaDefinitions = make([]*tabletmanagerdatapb.SchemaDefinition, len(sdw.sourceAliases))
 	wg := sync.WaitGroup{}
 	rec := &concurrency.AllErrorRecorder{}
 	wg.Add(1)
@@ -360,17 +370,19 @@ func (sdw *SplitDiffWorker) diff(ctx context.Context) error {
 		sdw.wr.Logger().Infof("Got schema from destination %v", sdw.destinationAlias)
 		wg.Done()
 	}()
-	wg.Add(1)
-	go func() {
-		var err error
-		shortCtx, cancel := context.WithTimeout(ctx, *remoteActionsTimeout)
-		sdw.sourceSchemaDefinition, err = sdw.wr.GetSchema(
-			shortCtx, sdw.sourceAlias, nil /* tables */, sdw.excludeTables, false /* includeViews */)
-		cancel()
-		rec.RecordError(err)
-		sdw.wr.Logger().Infof("Got schema from source %v", sdw.sourceAlias)
-		wg.Done()
-	}()
+	for i, sourceAlias := range sdw.sourceAliases {
+		wg.Add(1)
+		go func(i int, sourceAlias *topodatapb.TabletAlias) {
+			var err error
+			shortCtx, cancel := context.WithTimeout(ctx, *remoteActionsTimeout)
+			sdw.sourceSchemaDefinitions[i], err = sdw.wr.GetSchema(
+				shortCtx, sourceAlias, nil /* tables */, sdw.excludeTables, false /* includeViews */)
+			cancel()
+			rec.RecordError(err)
+			sdw.wr.Logger().Infof("Got schema from source %v", sourceAlias)
+			wg.Done()
+		}(i, sourceAlias)
+	}
 
 	wg.Wait()
 	if rec.HasErrors() {
@@ -379,7 +391,9 @@ func (sdw *SplitDiffWorker) diff(ctx context.Context) error {
 
 	sdw.wr.Logger().Infof("Diffing the schema...")
 	rec = &concurrency.AllErrorRecorder{}
-	tmutils.DiffSchema("destination", sdw.destinationSchemaDefinition, "source", sdw.sourceSchemaDefinition, rec)
+	for i, sourceSchemaDefinition := range sdw.sourceSchemaDefinitions {
+		tmutils.DiffSchema("destination", sdw.destinationSchemaDefinition, "source "+sdw.sourceShards[i].Shard, sourceSchemaDefinition, rec)
+	}
 	if rec.HasErrors() {
 		sdw.wr.Logger().Warningf("Different schemas: %v", rec.Error().Error())
 	} else {
@@ -403,14 +417,20 @@ func (sdw *SplitDiffWorker) diff(ctx context.Context) error {
 		}
 	}
 
-	// Compute the overlap keyrange. Later, we'll compare it with
-	// source or destination keyrange. If it matches either,
-	// we'll just ask for all the data. If the overlap is a subset,
-	// we'll filter.
-	overlap, err := key.KeyRangesOverlap(sdw.shardInfo.KeyRange, sdw.shardInfo.SourceShards[sdw.sourceUID].KeyRange)
-	if err != nil {
-		return fmt.Errorf("Source shard doesn't overlap with destination: %v", err)
+	// Compute the overlap keyrange for each source. Later, we'll compare
+	// it with the source keyrange. If it matches, we'll just ask for all
+	// the data. If the overlap is a subset, we'll filter.
+	// On the destination, we do the same with the union of the overlaps.
+	// The union is a single keyrange unless the sources have gaps.
+	overlaps := make([]*topodatapb.KeyRange, len(sdw.sourceShards))
+	for i, ss := range sdw.sourceShards {
+		overlap, err := key.KeyRangesOverlap(sdw.shardInfo.KeyRange, ss.KeyRange)
+		if err != nil {
+			return fmt.Errorf("Source shard %v doesn't overlap with destination: %v", ss.Shard, err)
+		}
+		overlaps[i] = overlap
 	}
+	destinationKeyRanges := mergeAdjacentKeyRanges(overlaps)
 
 	// run the diffs, 8 at a time
 	sdw.wr.Logger().Infof("Running the diffs...")
@@ -425,40 +445,54 @@ func (sdw *SplitDiffWorker) diff(ctx context.Context) error {
 
 			sdw.wr.Logger().Infof("Starting the diff on table %v", tableDefinition.Name)
 
-			// On the source, see if we need a full scan
+			// On each source, see if we need a full scan
 			// or a filtered scan.
-			var sourceQueryResultReader *QueryResultReader
-			if key.KeyRangeEqual(overlap, sdw.shardInfo.SourceShards[sdw.sourceUID].KeyRange) {
-				sourceQueryResultReader, err = TableScan(ctx, sdw.wr.Logger(), sdw.wr.TopoServer(), sdw.sourceAlias, tableDefinition)
-			} else {
-				sourceQueryResultReader, err = TableScanByKeyRange(ctx, sdw.wr.Logger(), sdw.wr.TopoServer(), sdw.sourceAlias, tableDefinition, overlap, keyspaceSchema, sdw.keyspaceInfo.ShardingColumnName, sdw.keyspaceInfo.ShardingColumnType)
+			sourceReaders := make([]ResultReader, len(sdw.sourceShards))
+			for i, ss := range sdw.sourceShards {
+				sourceQueryResultReader, err := sdw.tableScan(ctx, sdw.sourceAliases[i], tableDefinition, overlaps[i], ss.KeyRange, keyspaceSchema)
+				if err != nil {
+					newErr := fmt.Errorf("TableScan(ByKeyRange?)(source) failed: %v", err)
+					rec.RecordError(newErr)
+					sdw.wr.Logger().Errorf("%v", newErr)
+					return
+				}
+				defer sourceQueryResultReader.Close()
+				sourceReaders[i] = sourceQueryResultReader
 			}
+
+			// On the destination, see if we need a full scan
+			// or a filtered scan.
+			destinationReaders := make([]ResultReader, len(destinationKeyRanges))
+			for i, keyRange := range destinationKeyRanges {
+				destinationQueryResultReader, err := sdw.tableScan(ctx, sdw.destinationAlias, tableDefinition, keyRange, sdw.shardInfo.KeyRange, keyspaceSchema)
+				if err != nil {
+					newErr := fmt.Errorf("TableScan(ByKeyRange?)(destination) failed: %v", err)
+					rec.RecordError(newErr)
+					sdw.wr.Logger().Errorf("%v", newErr)
+					return
+				}
+				defer destinationQueryResultReader.Close()
+				destinationReaders[i] = destinationQueryResultReader
+			}
+
+			// Merge the sources (and the destination ranges) by primary key.
+			sourceReader, err := mergeResultReaders(sourceReaders, tableDefinition)
 			if err != nil {
-				newErr := fmt.Errorf("TableScan(ByKeyRange?)(source) failed: %v", err)
+				newErr := fmt.Errorf("NewResultMerger(source) failed: %v", err)
 				rec.RecordError(newErr)
 				sdw.wr.Logger().Errorf("%v", newErr)
 				return
 			}
-			defer sourceQueryResultReader.Close()
-
-			// On the destination, see if we need a full scan
-			// or a filtered scan.
-			var destinationQueryResultReader *QueryResultReader
-			if key.KeyRangeEqual(overlap, sdw.shardInfo.KeyRange) {
-				destinationQueryResultReader, err = TableScan(ctx, sdw.wr.Logger(), sdw.wr.TopoServer(), sdw.destinationAlias, tableDefinition)
-			} else {
-				destinationQueryResultReader, err = TableScanByKeyRange(ctx, sdw.wr.Logger(), sdw.wr.TopoServer(), sdw.destinationAlias, tableDefinition, overlap, keyspaceSchema, sdw.keyspaceInfo.ShardingColumnName, sdw.keyspaceInfo.ShardingColumnType)
-			}
+			destinationReader, err := mergeResultReaders(destinationReaders, tableDefinition)
 			if err != nil {
-				newErr := fmt.Errorf("TableScan(ByKeyRange?)(destination) failed: %v", err)
+				newErr := fmt.Errorf("NewResultMerger(destination) failed: %v", err)
 				rec.RecordError(newErr)
 				sdw.wr.Logger().Errorf("%v", newErr)
 				return
 			}
-			defer destinationQueryResultReader.Close()
 
 			// Create the row differ.
-			differ, err := NewRowDiffer(sourceQueryResultReader, destinationQueryResultReader, tableDefinition)
+			differ, err := NewRowDiffer(sourceReader, destinationReader, tableDefinition)
 			if err != nil {
 				newErr := fmt.Errorf("NewRowDiffer() failed: %v", err)
 				rec.RecordError(newErr)
@@ -487,3 +521,52 @@ func (sdw *SplitDiffWorker) diff(ctx context.Context) error {
 
 	return rec.Error()
 }
+
+// tableScan returns a full scan if keyRange covers the whole shard, and a
+// scan filtered by keyRange otherwise.
+func (sdw *SplitDiffWorker) tableScan(ctx context.Context, tabletAlias *topodatapb.TabletAlias, td *tabletmanagerdatapb.TableDefinition, keyRange, shardKeyRange *topodatapb.KeyRange, keyspaceSchema *vindexes.KeyspaceSchema) (*QueryResultReader, error) {
+	if key.KeyRangeEqual(keyRange, shardKeyRange) {
+		return TableScan(ctx, sdw.wr.Logger(), sdw.wr.TopoServer(), tabletAlias, td)
+	}
+	return TableScanByKeyRange(ctx, sdw.wr.Logger(), sdw.wr.TopoServer(), tabletAlias, td, keyRange, keyspaceSchema, sdw.keyspaceInfo.ShardingColumnName, sdw.keyspaceInfo.ShardingColumnType)
+}
+
+// mergeResultReaders returns the single reader as is, and a ResultMerger
+// which sorts by primary key otherwise.
+func mergeResultReaders(readers []ResultReader, td *tabletmanagerdatapb.TableDefinition) (ResultReader, error) {
+	if len(readers) == 1 {
+		return readers[0], nil
+	}
+	return NewResultMerger(readers, len(td.PrimaryKeyColumns))
+}
+
+// keyRangesByStart implements sort.Interface to sort key ranges by start.
+type keyRangesByStart []*topodatapb.KeyRange
+
+func (l keyRangesByStart) Len() int           { return len(l) }
+func (l keyRangesByStart) Swap(i, j int)      { l[i], l[j] = l[j], l[i] }
+func (l keyRangesByStart) Less(i, j int) bool { return bytes.Compare(l[i].Start, l[j].Start) < 0 }
+
+// mergeAdjacentKeyRanges returns the union of the non-overlapping key
+// ranges, sorted by start. Adjacent key ranges are merged into one.
+func mergeAdjacentKeyRanges(keyRanges []*topodatapb.KeyRange) []*topodatapb.KeyRange {
+	sorted := make([]*topodatapb.KeyRange, len(keyRanges))
+	for i, kr := range keyRanges {
+		if kr == nil {
+			// nil covers the whole keyspace.
+			return []*topodatapb.KeyRange{nil}
+		}
+		sorted[i] = kr
+	}
+	sort.Sort(keyRangesByStart(sorted))
+
+	var result []*topodatapb.KeyRange
+	for _, kr := range sorted {
+		if last := len(result) - 1; last >= 0 && len(result[last].End) > 0 && bytes.Equal(result[last].End, kr.Start) {
+			result[last] = &topodatapb.KeyRange{Start: result[last].Start, End: kr.End}
+			continue
+		}
+		result = append(result, kr)
+	}
+	return result
+}
@@ -46,7 +46,7 @@ const splitDiffHTML2 = `
   <p>Shard involved: {{.Keyspace}}/{{.Shard}}</p>
   <h1>Split Diff Action</h1>
     <form action="/Diffs/SplitDiff" method="post">
-      <LABEL for="sourceUID">Source shard UID: </LABEL>
+      <LABEL for="sourceUID">Source shard UID (-1 to diff against all source shards): </LABEL>
         <INPUT type="text" id="sourceUID" name="sourceUID" value="{{.DefaultSourceUID}}"></BR>
       <LABEL for="excludeTables">Exclude Tables: </LABEL>
         <INPUT type="text" id="excludeTables" name="excludeTables" value=""></BR>
@@ -63,7 +63,7 @@ var splitDiffTemplate = mustParseTemplate("splitDiff", splitDiffHTML)
 var splitDiffTemplate2 = mustParseTemplate("splitDiff2", splitDiffHTML2)
 
 func commandSplitDiff(wi *Instance, wr *wrangler.Wrangler, subFlags *flag.FlagSet, args []string) (Worker, error) {
-	sourceUID := subFlags.Int("source_uid", 0, "uid of the source shard to run the diff against")
+	sourceUID := subFlags.Int("source_uid", 0, "uid of the source shard to run the diff against (-1 to diff against the union of all source shards)")
 	excludeTables := subFlags.String("exclude_tables", "", "comma separated list of tables to exclude")
 	minHealthyRdonlyTablets := subFlags.Int("min_healthy_rdonly_tablets", defaultMinHealthyRdonlyTablets, "minimum number of healthy RDONLY tablets before taking out one")
 	if err := subFlags.Parse(args); err != nil {
@@ -81,7 +81,7 @@ func commandSplitDiff(wi *Instance, wr *wrangler.Wrangler, subFlags *flag.FlagSe
 	if *excludeTables != "" {
 		excludeTableArray = strings.Split(*excludeTables, ",")
 	}
-	return NewSplitDiffWorker(wr, wi.cell, keyspace, shard, uint32(*sourceUID), excludeTableArray, *minHealthyRdonlyTablets), nil
+	return NewSplitDiffWorker(wr, wi.cell, keyspace, shard, *sourceUID, excludeTableArray, *minHealthyRdonlyTablets), nil
 }
 
 // shardsWithSources returns all the shards that have SourceShards set
@@ -192,13 +192,13 @@ func interactiveSplitDiff(ctx context.Context, wi *Instance, wr *wrangler.Wrangl
 	}
 
 	// start the diff job
-	wrk := NewSplitDiffWorker(wr, wi.cell, keyspace, shard, uint32(sourceUID), excludeTableArray, int(minHealthyRdonlyTablets))
+	wrk := NewSplitDiffWorker(wr, wi.cell, keyspace, shard, int(sourceUID), excludeTableArray, int(minHealthyRdonlyTablets))
 	return wrk, nil, nil, nil
 }
 
 func init() {
 	AddCommand("Diffs", Command{"SplitDiff",
 		commandSplitDiff, interactiveSplitDiff,
-		"[--exclude_tables=''] <keyspace/shard>",
+		"[--source_uid=N] [--exclude_tables=''] <keyspace/shard>",
 		"Diffs a rdonly destination shard against its SourceShards"})
 }
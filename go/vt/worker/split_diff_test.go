@@ -11,10 +11,13 @@ import (
 	"time"
 
 	"github.com/youtube/vitess/go/sqltypes"
+	"github.com/youtube/vitess/go/vt/key"
 	"github.com/youtube/vitess/go/vt/logutil"
 	"github.com/youtube/vitess/go/vt/mysqlctl/tmutils"
+	"github.com/youtube/vitess/go/vt/tabletmanager/tmclient"
 	"github.com/youtube/vitess/go/vt/tabletserver/grpcqueryservice"
 	"github.com/youtube/vitess/go/vt/tabletserver/queryservice/fakes"
+	"github.com/youtube/vitess/go/vt/topo"
 	"github.com/youtube/vitess/go/vt/vttest/fakesqldb"
 	"github.com/youtube/vitess/go/vt/wrangler"
 	"github.com/youtube/vitess/go/vt/wrangler/testlib"
@@ -298,3 +301,199 @@ func TestSplitDiffv2(t *testing.T) {
 func TestSplitDiffv3(t *testing.T) {
 	testSplitDiff(t, true)
 }
+
+// mergeDiffTabletServer is a local QueryService implementation which
+// returns the rows of table1 whose id has the given parity, or all rows
+// if parity is negative.
+type mergeDiffTabletServer struct {
+	t *testing.T
+
+	*fakes.StreamHealthQueryService
+	parity int
+}
+
+func (sq *mergeDiffTabletServer) StreamExecute(ctx context.Context, target *querypb.Target, sql string, bindVariables map[string]interface{}, options *querypb.ExecuteOptions, sendReply func(reply *sqltypes.Result) error) error {
+	// The union of the source shards covers the destination shard, and
+	// each source shard is covered by the destination shard.
+	if strings.Contains(sql, "WHERE") {
+		sq.t.Errorf("Sql query should be a full table scan; query received: %v", sql)
+	}
+
+	if err := sendReply(&sqltypes.Result{
+		Fields: []*querypb.Field{
+			{
+				Name: "id",
+				Type: sqltypes.Int64,
+			},
+			{
+				Name: "msg",
+				Type: sqltypes.VarChar,
+			},
+			{
+				Name: "keyspace_id",
+				Type: sqltypes.Int64,
+			},
+		},
+	}); err != nil {
+		return err
+	}
+
+	// The even rows are in the first source shard, the odd rows in the
+	// second one.
+	ksids := []uint64{0x2000000000000000, 0x6000000000000000}
+	for i := 0; i < 100; i++ {
+		if sq.parity >= 0 && i%2 != sq.parity {
+			continue
+		}
+		if err := sendReply(&sqltypes.Result{
+			Rows: [][]sqltypes.Value{
+				{
+					sqltypes.MakeString([]byte(fmt.Sprintf("%v", i))),
+					sqltypes.MakeString([]byte(fmt.Sprintf("Text for %v", i))),
+					sqltypes.MakeString([]byte(fmt.Sprintf("%v", ksids[i%2]))),
+				},
+			},
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// mergeDiffTMC returns a binlog position for both source shards.
+type mergeDiffTMC struct {
+	tmclient.TabletManagerClient
+}
+
+// StopBlp is part of the tmclient.TabletManagerClient interface.
+func (f *mergeDiffTMC) StopBlp(ctx context.Context, tablet *topodatapb.Tablet) ([]*tabletmanagerdatapb.BlpPosition, error) {
+	return []*tabletmanagerdatapb.BlpPosition{{Uid: 0}, {Uid: 1}}, nil
+}
+
+func TestSplitDiffMerge(t *testing.T) {
+	*useV3ReshardingMode = false
+	db := fakesqldb.Register()
+	ts := zktestserver.New(t, []string{"cell1", "cell2"})
+	ctx := context.Background()
+	wi := NewInstance(ts, "cell1", time.Second)
+
+	if err := ts.CreateKeyspace(ctx, "ks", &topodatapb.Keyspace{
+		ShardingColumnName: "keyspace_id",
+		ShardingColumnType: topodatapb.KeyspaceIdType_UINT64,
+	}); err != nil {
+		t.Fatalf("CreateKeyspace failed: %v", err)
+	}
+
+	// The shards -40 and 40-80 were merged into -80.
+	source1Master := testlib.NewFakeTablet(t, wi.wr, "cell1", 0,
+		topodatapb.TabletType_MASTER, db, testlib.TabletKeyspaceShard(t, "ks", "-40"))
+	source1Rdonly := testlib.NewFakeTablet(t, wi.wr, "cell1", 1,
+		topodatapb.TabletType_RDONLY, db, testlib.TabletKeyspaceShard(t, "ks", "-40"))
+	source2Master := testlib.NewFakeTablet(t, wi.wr, "cell1", 10,
+		topodatapb.TabletType_MASTER, db, testlib.TabletKeyspaceShard(t, "ks", "40-80"))
+	source2Rdonly := testlib.NewFakeTablet(t, wi.wr, "cell1", 11,
+		topodatapb.TabletType_RDONLY, db, testlib.TabletKeyspaceShard(t, "ks", "40-80"))
+	destMaster := testlib.NewFakeTablet(t, wi.wr, "cell1", 20,
+		topodatapb.TabletType_MASTER, db, testlib.TabletKeyspaceShard(t, "ks", "-80"))
+	destRdonly := testlib.NewFakeTablet(t, wi.wr, "cell1", 21,
+		topodatapb.TabletType_RDONLY, db, testlib.TabletKeyspaceShard(t, "ks", "-80"))
+
+	for _, ft := range []*testlib.FakeTablet{source1Master, source1Rdonly, source2Master, source2Rdonly, destMaster, destRdonly} {
+		ft.StartActionLoop(t, wi.wr)
+		defer ft.StopActionLoop(t)
+	}
+
+	// The shard 80- completes the keyspace.
+	if err := ts.CreateShard(ctx, "ks", "80-"); err != nil {
+		t.Fatalf("CreateShard(\"80-\") failed: %v", err)
+	}
+	if err := wi.wr.SetSourceShards(ctx, "ks", "-80", []*topodatapb.TabletAlias{source1Rdonly.Tablet.Alias, source2Rdonly.Tablet.Alias}, nil); err != nil {
+		t.Fatalf("SetSourceShards failed: %v", err)
+	}
+	if err := wi.wr.RebuildKeyspaceGraph(ctx, "ks", nil); err != nil {
+		t.Fatalf("RebuildKeyspaceGraph failed: %v", err)
+	}
+
+	for _, rdonly := range []*testlib.FakeTablet{source1Rdonly, source2Rdonly, destRdonly} {
+		rdonly.FakeMysqlDaemon.Schema = &tabletmanagerdatapb.SchemaDefinition{
+			DatabaseSchema: "",
+			TableDefinitions: []*tabletmanagerdatapb.TableDefinition{
+				{
+					Name:              "table1",
+					Columns:           []string{"id", "msg", "keyspace_id"},
+					PrimaryKeyColumns: []string{"id"},
+					Type:              tmutils.TableBaseTable,
+				},
+			},
+		}
+	}
+
+	for parity, rdonly := range []*testlib.FakeTablet{source1Rdonly, source2Rdonly} {
+		qs := fakes.NewStreamHealthQueryService(rdonly.Target())
+		qs.AddDefaultHealthResponse()
+		grpcqueryservice.Register(rdonly.RPCServer, &mergeDiffTabletServer{
+			t: t,
+			StreamHealthQueryService: qs,
+			parity:                   parity,
+		})
+	}
+	qs := fakes.NewStreamHealthQueryService(destRdonly.Target())
+	qs.AddDefaultHealthResponse()
+	grpcqueryservice.Register(destRdonly.RPCServer, &mergeDiffTabletServer{
+		t: t,
+		StreamHealthQueryService: qs,
+		parity:                   -1,
+	})
+
+	// Run the vtworker command. The destination is compared with the
+	// union of both source shards.
+	args := []string{
+		"SplitDiff",
+		"-source_uid", "-1",
+		"-min_healthy_rdonly_tablets", "1",
+		"ks/-80",
+	}
+	wr := wrangler.New(logutil.NewConsoleLogger(), ts, &mergeDiffTMC{newFakeTMCTopo(ts)})
+	if err := runCommand(t, wi, wr, args); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMergeAdjacentKeyRanges(t *testing.T) {
+	kr := func(start, end string) *topodatapb.KeyRange {
+		_, keyRange, err := topo.ValidateShardName(start + "-" + end)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return keyRange
+	}
+	table := []struct {
+		input, want []*topodatapb.KeyRange
+	}{
+		{
+			input: []*topodatapb.KeyRange{kr("40", "80"), kr("", "40")},
+			want:  []*topodatapb.KeyRange{kr("", "80")},
+		},
+		{
+			input: []*topodatapb.KeyRange{kr("c0", ""), kr("", "40"), kr("80", "c0")},
+			want:  []*topodatapb.KeyRange{kr("", "40"), kr("80", "")},
+		},
+		{
+			input: []*topodatapb.KeyRange{kr("", "40"), nil},
+			want:  []*topodatapb.KeyRange{nil},
+		},
+	}
+	for _, tc := range table {
+		got := mergeAdjacentKeyRanges(tc.input)
+		if len(got) != len(tc.want) {
+			t.Errorf("mergeAdjacentKeyRanges(%v) = %v, want %v", tc.input, got, tc.want)
+			continue
+		}
+		for i := range got {
+			if !key.KeyRangeEqual(got[i], tc.want[i]) {
+				t.Errorf("mergeAdjacentKeyRanges(%v) = %v, want %v", tc.input, got, tc.want)
+				break
+			}
+		}
+	}
+}
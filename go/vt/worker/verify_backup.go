@@ -0,0 +1,341 @@
+// Copyright 2016, Google Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package worker
+
+import (
+	"fmt"
+	"html/template"
+	"sync"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/youtube/vitess/go/vt/dbconfigs"
+	"github.com/youtube/vitess/go/vt/mysqlctl"
+	"github.com/youtube/vitess/go/vt/mysqlctl/backupstorage"
+	"github.com/youtube/vitess/go/vt/mysqlctl/replication"
+	"github.com/youtube/vitess/go/vt/topo/topoproto"
+	"github.com/youtube/vitess/go/vt/wrangler"
+)
+
+// VerifyBackupDBConfigFlags are the db config flags the scratch mysqld
+// of the VerifyBackup worker needs. vtworker registers them.
+const VerifyBackupDBConfigFlags = dbconfigs.AppConfig | dbconfigs.AllPrivsConfig | dbconfigs.DbaConfig | dbconfigs.ReplConfig
+
+// scratchMysqld is the mysqld a backup is restored into.
+type scratchMysqld interface {
+	mysqlctl.MysqlDaemon
+	Init(ctx context.Context, initDBSQLFile string) error
+	Teardown(ctx context.Context, force bool) error
+}
+
+// newScratchMysqld creates the scratch mysqld. Tests replace it.
+var newScratchMysqld = func(tabletUID uint32, mysqlPort int32) (scratchMysqld, error) {
+	mysqld, err := mysqlctl.CreateMysqld(tabletUID, "", mysqlPort, VerifyBackupDBConfigFlags)
+	if err != nil {
+		return nil, err
+	}
+	return mysqld, nil
+}
+
+// VerifyBackupConfig contains the parameters of a VerifyBackup run.
+type VerifyBackupConfig struct {
+	// BackupName is the backup to verify. If empty, the most recent
+	// backup with a MANIFEST is used.
+	BackupName string
+	// ScratchTabletUID is the uid used for the directory of the
+	// scratch mysqld. It must not be used by a real tablet on this host.
+	ScratchTabletUID uint32
+	// ScratchMysqlPort is the port the scratch mysqld listens on.
+	ScratchMysqlPort int32
+	// InitDBSQLFile is the .sql file used to initialize the scratch mysqld.
+	InitDBSQLFile string
+	// Concurrency is the number of files read or restored in parallel.
+	Concurrency int
+	// CheckReplication enables the replication check: the restored
+	// mysqld replicates from the shard master, starting at the
+	// position recorded in the backup.
+	CheckReplication bool
+	// ReplicationTimeout is how long we wait for replication to run.
+	ReplicationTimeout time.Duration
+	// SanityQueries are run on the restored mysqld. Any error fails
+	// the verification.
+	SanityQueries []string
+	// KeepScratch keeps the scratch mysqld running once done.
+	KeepScratch bool
+}
+
+// VerifyBackupWorker restores a backup into a scratch mysqld, and checks
+// it is usable. It is meant to be scheduled as a restore drill.
+type VerifyBackupWorker struct {
+	StatusWorker
+
+	wr       *wrangler.Wrangler
+	keyspace string
+	shard    string
+	config   VerifyBackupConfig
+
+	// mu protects the fields below, populated during the run.
+	mu       sync.Mutex
+	backup   string
+	position replication.Position
+	steps    []string
+}
+
+// NewVerifyBackupWorker returns a new VerifyBackupWorker object.
+func NewVerifyBackupWorker(wr *wrangler.Wrangler, keyspace, shard string, config VerifyBackupConfig) (Worker, error) {
+	if config.ScratchTabletUID == 0 {
+		return nil, fmt.Errorf("scratch tablet uid must be set")
+	}
+	if config.ScratchMysqlPort == 0 {
+		return nil, fmt.Errorf("scratch mysql port must be set")
+	}
+	if config.InitDBSQLFile == "" {
+		return nil, fmt.Errorf("init_db_sql_file must be set")
+	}
+	if config.Concurrency < 1 {
+		return nil, fmt.Errorf("concurrency must be at least 1, got %v", config.Concurrency)
+	}
+	return &VerifyBackupWorker{
+		StatusWorker: NewStatusWorker(),
+		wr:           wr,
+		keyspace:     keyspace,
+		shard:        shard,
+		config:       config,
+	}, nil
+}
+
+// recordStep logs a successful step, and keeps it for the status.
+func (w *VerifyBackupWorker) recordStep(format string, args ...interface{}) {
+	step := fmt.Sprintf(format, args...)
+	w.wr.Logger().Infof("VerifyBackup %v/%v: %v", w.keyspace, w.shard, step)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.steps = append(w.steps, step)
+}
+
+// StatusAsHTML implements the Worker interface.
+func (w *VerifyBackupWorker) StatusAsHTML() template.HTML {
+	state := w.State()
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	result := "<b>Verifying backup of:</b> " + template.HTMLEscapeString(w.keyspace+"/"+w.shard) + "</br>\n"
+	result += "<b>State:</b> " + state.String() + "</br>\n"
+	if w.backup != "" {
+		result += "<b>Backup:</b> " + template.HTMLEscapeString(w.backup) + "</br>\n"
+	}
+	for _, step := range w.steps {
+		result += template.HTMLEscapeString(step) + "</br>\n"
+	}
+	return template.HTML(result)
+}
+
+// StatusAsText implements the Worker interface.
+func (w *VerifyBackupWorker) StatusAsText() string {
+	state := w.State()
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	result := "Verifying backup of: " + w.keyspace + "/" + w.shard + "\n"
+	result += "State: " + state.String() + "\n"
+	if w.backup != "" {
+		result += "Backup: " + w.backup + "\n"
+	}
+	for _, step := range w.steps {
+		result += step + "\n"
+	}
+	return result
+}
+
+// Run implements the Worker interface.
+func (w *VerifyBackupWorker) Run(ctx context.Context) error {
+	resetVars()
+	err := w.run(ctx)
+
+	w.SetState(WorkerStateCleanUp)
+	w.mu.Lock()
+	backup, position := w.backup, w.position
+	w.mu.Unlock()
+	if err != nil {
+		w.wr.Logger().Errorf("VerifyBackup %v/%v: backup %v FAILED verification: %v", w.keyspace, w.shard, backup, err)
+		w.SetState(WorkerStateError)
+		return err
+	}
+	w.wr.Logger().Infof("VerifyBackup %v/%v: backup %v at position %v PASSED verification", w.keyspace, w.shard, backup, position)
+	w.SetState(WorkerStateDone)
+	return nil
+}
+
+func (w *VerifyBackupWorker) run(ctx context.Context) error {
+	w.SetState(WorkerStateInit)
+	bs, err := backupstorage.GetBackupStorage()
+	if err != nil {
+		return err
+	}
+	defer bs.Close()
+
+	// Pick the backup and check its files.
+	dir := fmt.Sprintf("%v/%v", w.keyspace, w.shard)
+	bh, bm, err := w.findBackup(bs, dir)
+	if err != nil {
+		return err
+	}
+	w.mu.Lock()
+	w.backup = bh.Name()
+	w.position = bm.Position
+	w.mu.Unlock()
+	w.recordStep("picked backup %v taken at position %v with %v files", bh.Name(), bm.Position, len(bm.FileEntries))
+
+	w.SetState(WorkerStateDiff)
+	if err := mysqlctl.VerifyBackupFiles(bh, bm, w.config.Concurrency); err != nil {
+		return fmt.Errorf("cannot verify files of backup %v: %v", bh.Name(), err)
+	}
+	w.recordStep("verified the hashes of all %v files", len(bm.FileEntries))
+
+	// Restore it into a scratch mysqld.
+	w.SetState(WorkerStateCloneOffline)
+	mysqld, err := newScratchMysqld(w.config.ScratchTabletUID, w.config.ScratchMysqlPort)
+	if err != nil {
+		return fmt.Errorf("cannot create scratch mysqld: %v", err)
+	}
+	defer mysqld.Close()
+	if err := mysqld.Init(ctx, w.config.InitDBSQLFile); err != nil {
+		return fmt.Errorf("cannot init scratch mysqld: %v", err)
+	}
+	if !w.config.KeepScratch {
+		defer func() {
+			// Use a separate context, so we clean up even if ctx was canceled.
+			shortCtx, cancel := context.WithTimeout(context.Background(), *remoteActionsTimeout)
+			defer cancel()
+			if err := mysqld.Teardown(shortCtx, true /* force */); err != nil {
+				w.wr.Logger().Warningf("VerifyBackup %v/%v: cannot tear down scratch mysqld: %v", w.keyspace, w.shard, err)
+			}
+		}()
+	}
+	pos, err := mysqlctl.RestoreBackup(ctx, mysqld, bh, w.config.Concurrency, w.wr.Logger())
+	if err != nil {
+		return fmt.Errorf("cannot restore backup %v: %v", bh.Name(), err)
+	}
+	w.recordStep("restored backup into scratch mysqld %v", mysqld.TabletDir())
+
+	// Check replication can resume from the recorded position.
+	if w.config.CheckReplication {
+		w.SetState(WorkerStateSyncReplication)
+		if err := w.checkReplication(ctx, mysqld, pos); err != nil {
+			return err
+		}
+	}
+
+	// And run the sanity queries.
+	w.SetState(WorkerStateDiff)
+	for _, query := range w.config.SanityQueries {
+		qr, err := mysqld.FetchSuperQuery(ctx, query)
+		if err != nil {
+			return fmt.Errorf("sanity query %q failed: %v", query, err)
+		}
+		w.recordStep("sanity query %q returned %v rows", query, len(qr.Rows))
+	}
+	return nil
+}
+
+// findBackup returns the backup to verify and its manifest.
+func (w *VerifyBackupWorker) findBackup(bs backupstorage.BackupStorage, dir string) (backupstorage.BackupHandle, *mysqlctl.BackupManifest, error) {
+	bhs, err := bs.ListBackups(dir)
+	if err != nil {
+		return nil, nil, fmt.Errorf("ListBackups failed: %v", err)
+	}
+	if w.config.BackupName != "" {
+		for _, bh := range bhs {
+			if bh.Name() == w.config.BackupName {
+				bm, err := mysqlctl.ReadBackupManifest(bh)
+				if err != nil {
+					return nil, nil, fmt.Errorf("backup %v is not restorable: %v", bh.Name(), err)
+				}
+				return bh, bm, nil
+			}
+		}
+		return nil, nil, fmt.Errorf("no backup named %v in %v", w.config.BackupName, dir)
+	}
+	for i := len(bhs) - 1; i >= 0; i-- {
+		bm, err := mysqlctl.ReadBackupManifest(bhs[i])
+		if err != nil {
+			w.wr.Logger().Warningf("VerifyBackup %v/%v: skipping backup %v: %v", w.keyspace, w.shard, bhs[i].Name(), err)
+			continue
+		}
+		return bhs[i], bm, nil
+	}
+	return nil, nil, mysqlctl.ErrNoBackup
+}
+
+// checkReplication makes the scratch mysqld replicate from the shard
+// master, starting at pos, and waits for both replication threads to run.
+// Replication is stopped before returning.
+func (w *VerifyBackupWorker) checkReplication(ctx context.Context, mysqld mysqlctl.MysqlDaemon, pos replication.Position) error {
+	shortCtx, cancel := context.WithTimeout(ctx, *remoteActionsTimeout)
+	si, err := w.wr.TopoServer().GetShard(shortCtx, w.keyspace, w.shard)
+	cancel()
+	if err != nil {
+		return fmt.Errorf("cannot read shard: %v", err)
+	}
+	if si.MasterAlias == nil {
+		return fmt.Errorf("shard %v/%v has no master, cannot check replication", w.keyspace, w.shard)
+	}
+	shortCtx, cancel = context.WithTimeout(ctx, *remoteActionsTimeout)
+	ti, err := w.wr.TopoServer().GetTablet(shortCtx, si.MasterAlias)
+	cancel()
+	if err != nil {
+		return fmt.Errorf("cannot read master tablet %v: %v", topoproto.TabletAliasString(si.MasterAlias), err)
+	}
+
+	cmds, err := mysqld.SetSlavePositionCommands(pos)
+	if err != nil {
+		return err
+	}
+	masterCmds, err := mysqld.SetMasterCommands(ti.Hostname, int(ti.PortMap["mysql"]))
+	if err != nil {
+		return err
+	}
+	cmds = append(cmds, masterCmds...)
+	cmds = append(cmds, "START SLAVE")
+	if err := mysqld.ExecuteSuperQueryList(ctx, cmds); err != nil {
+		return fmt.Errorf("cannot start replication at position %v: %v", pos, err)
+	}
+	defer func() {
+		if err := mysqld.ExecuteSuperQueryList(ctx, []string{"STOP SLAVE"}); err != nil {
+			w.wr.Logger().Warningf("VerifyBackup %v/%v: cannot stop replication on scratch mysqld: %v", w.keyspace, w.shard, err)
+		}
+	}()
+
+	// The replication threads may start, and then stop right away if
+	// the position is not usable (e.g. the master purged the binlogs).
+	// So we want to see them running a few times in a row.
+	const wantRunningChecks = 3
+	runningChecks := 0
+	timeout := time.After(w.config.ReplicationTimeout)
+	for {
+		status, err := mysqld.SlaveStatus()
+		if err == nil && status.SlaveRunning() {
+			runningChecks++
+			if runningChecks == wantRunningChecks {
+				w.recordStep("replication from master %v running at position %v", topoproto.TabletAliasString(si.MasterAlias), status.Position)
+				return nil
+			}
+		} else {
+			runningChecks = 0
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timeout:
+			if err != nil {
+				return fmt.Errorf("replication from position %v did not start within %v: %v", pos, w.config.ReplicationTimeout, err)
+			}
+			return fmt.Errorf("replication from position %v did not start within %v: IO thread running: %v, SQL thread running: %v", pos, w.config.ReplicationTimeout, status.SlaveIORunning, status.SlaveSQLRunning)
+		case <-time.After(time.Second):
+		}
+	}
+}
@@ -0,0 +1,171 @@
+// Copyright 2016, Google Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package worker
+
+import (
+	"flag"
+	"fmt"
+	"html/template"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/youtube/vitess/go/vt/topo/topoproto"
+	"github.com/youtube/vitess/go/vt/wrangler"
+)
+
+const verifyBackupHTML = `
+<!DOCTYPE html>
+<head>
+  <title>Verify Backup Action</title>
+</head>
+<body>
+  <h1>Verify Backup Action</h1>
+
+    {{if .Error}}
+      <b>Error:</b> {{.Error}}</br>
+    {{else}}
+    <form action="/Diffs/VerifyBackup" method="post">
+      <LABEL for="keyspace">Keyspace: </LABEL>
+        <INPUT type="text" id="keyspace" name="keyspace" value=""></BR>
+      <LABEL for="shard">Shard: </LABEL>
+        <INPUT type="text" id="shard" name="shard" value=""></BR>
+      <LABEL for="backupName">Backup name (empty for the most recent one): </LABEL>
+        <INPUT type="text" id="backupName" name="backupName" value=""></BR>
+      <LABEL for="scratchTabletUID">Scratch tablet UID: </LABEL>
+        <INPUT type="text" id="scratchTabletUID" name="scratchTabletUID" value="{{.DefaultScratchTabletUID}}"></BR>
+      <LABEL for="scratchMysqlPort">Scratch MySQL port: </LABEL>
+        <INPUT type="text" id="scratchMysqlPort" name="scratchMysqlPort" value="{{.DefaultScratchMysqlPort}}"></BR>
+      <LABEL for="initDBSQLFile">Init DB SQL file: </LABEL>
+        <INPUT type="text" id="initDBSQLFile" name="initDBSQLFile" value=""></BR>
+      <LABEL for="concurrency">Concurrency: </LABEL>
+        <INPUT type="text" id="concurrency" name="concurrency" value="{{.DefaultConcurrency}}"></BR>
+      <LABEL for="checkReplication">Check replication: </LABEL>
+        <INPUT type="checkbox" id="checkReplication" name="checkReplication" value="true"></BR>
+      <LABEL for="sanityQueries">Sanity queries (separated by ';'): </LABEL>
+        <INPUT type="text" id="sanityQueries" name="sanityQueries" value=""></BR>
+      <INPUT type="submit" name="submit" value="Verify Backup"/>
+    </form>
+    {{end}}
+</body>
+`
+
+var verifyBackupTemplate = mustParseTemplate("verifyBackup", verifyBackupHTML)
+
+const (
+	defaultVerifyBackupScratchTabletUID = 999999
+	defaultVerifyBackupScratchMysqlPort = 33306
+	defaultVerifyBackupConcurrency      = 4
+)
+
+// splitSanityQueries splits a ';' separated list of queries.
+func splitSanityQueries(queries string) []string {
+	var result []string
+	for _, q := range strings.Split(queries, ";") {
+		if q = strings.TrimSpace(q); q != "" {
+			result = append(result, q)
+		}
+	}
+	return result
+}
+
+func commandVerifyBackup(wi *Instance, wr *wrangler.Wrangler, subFlags *flag.FlagSet, args []string) (Worker, error) {
+	backupName := subFlags.String("backup_name", "", "name of the backup to verify (defaults to the most recent one with a MANIFEST)")
+	scratchTabletUID := subFlags.Int("scratch_tablet_uid", defaultVerifyBackupScratchTabletUID, "tablet uid used for the directory of the scratch mysqld")
+	scratchMysqlPort := subFlags.Int("scratch_mysql_port", defaultVerifyBackupScratchMysqlPort, "port of the scratch mysqld")
+	initDBSQLFile := subFlags.String("init_db_sql_file", "", "path to the .sql file used to initialize the scratch mysqld")
+	concurrency := subFlags.Int("concurrency", defaultVerifyBackupConcurrency, "number of files read or restored in parallel")
+	checkReplication := subFlags.Bool("check_replication", false, "start replication on the restored mysqld from the shard master, and check it runs")
+	replicationTimeout := subFlags.Duration("replication_timeout", 5*time.Minute, "how long to wait for replication to run")
+	sanityQueries := subFlags.String("sanity_queries", "", "';' separated list of queries to run on the restored mysqld")
+	keepScratch := subFlags.Bool("keep_scratch", false, "keep the scratch mysqld running once done, for inspection")
+	if err := subFlags.Parse(args); err != nil {
+		return nil, err
+	}
+	if subFlags.NArg() != 1 {
+		subFlags.Usage()
+		return nil, fmt.Errorf("command VerifyBackup requires <keyspace/shard>")
+	}
+	keyspace, shard, err := topoproto.ParseKeyspaceShard(subFlags.Arg(0))
+	if err != nil {
+		return nil, err
+	}
+
+	worker, err := NewVerifyBackupWorker(wr, keyspace, shard, VerifyBackupConfig{
+		BackupName:         *backupName,
+		ScratchTabletUID:   uint32(*scratchTabletUID),
+		ScratchMysqlPort:   int32(*scratchMysqlPort),
+		InitDBSQLFile:      *initDBSQLFile,
+		Concurrency:        *concurrency,
+		CheckReplication:   *checkReplication,
+		ReplicationTimeout: *replicationTimeout,
+		SanityQueries:      splitSanityQueries(*sanityQueries),
+		KeepScratch:        *keepScratch,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("cannot create VerifyBackup worker: %v", err)
+	}
+	return worker, nil
+}
+
+func interactiveVerifyBackup(ctx context.Context, wi *Instance, wr *wrangler.Wrangler, w http.ResponseWriter, r *http.Request) (Worker, *template.Template, map[string]interface{}, error) {
+	if err := r.ParseForm(); err != nil {
+		return nil, nil, nil, fmt.Errorf("cannot parse form: %s", err)
+	}
+
+	if submit := r.FormValue("submit"); submit == "" {
+		// display the input form
+		result := make(map[string]interface{})
+		result["DefaultScratchTabletUID"] = fmt.Sprintf("%v", defaultVerifyBackupScratchTabletUID)
+		result["DefaultScratchMysqlPort"] = fmt.Sprintf("%v", defaultVerifyBackupScratchMysqlPort)
+		result["DefaultConcurrency"] = fmt.Sprintf("%v", defaultVerifyBackupConcurrency)
+		return nil, verifyBackupTemplate, result, nil
+	}
+
+	// Process input form.
+	keyspace := r.FormValue("keyspace")
+	shard := r.FormValue("shard")
+	if keyspace == "" || shard == "" {
+		return nil, nil, nil, fmt.Errorf("keyspace and shard are required")
+	}
+	scratchTabletUID, err := strconv.ParseUint(r.FormValue("scratchTabletUID"), 0, 32)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("cannot parse scratchTabletUID: %s", err)
+	}
+	scratchMysqlPort, err := strconv.ParseInt(r.FormValue("scratchMysqlPort"), 0, 32)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("cannot parse scratchMysqlPort: %s", err)
+	}
+	concurrency, err := strconv.ParseInt(r.FormValue("concurrency"), 0, 64)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("cannot parse concurrency: %s", err)
+	}
+
+	// start the verification job
+	wrk, err := NewVerifyBackupWorker(wr, keyspace, shard, VerifyBackupConfig{
+		BackupName:         r.FormValue("backupName"),
+		ScratchTabletUID:   uint32(scratchTabletUID),
+		ScratchMysqlPort:   int32(scratchMysqlPort),
+		InitDBSQLFile:      r.FormValue("initDBSQLFile"),
+		Concurrency:        int(concurrency),
+		CheckReplication:   r.FormValue("checkReplication") == "true",
+		ReplicationTimeout: 5 * time.Minute,
+		SanityQueries:      splitSanityQueries(r.FormValue("sanityQueries")),
+	})
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("cannot create VerifyBackup worker: %v", err)
+	}
+	return wrk, nil, nil, nil
+}
+
+func init() {
+	AddCommand("Diffs", Command{"VerifyBackup",
+		commandVerifyBackup, interactiveVerifyBackup,
+		"[--backup_name=''] [--init_db_sql_file=...] [--check_replication] [--sanity_queries='q1;q2'] <keyspace/shard>",
+		"Restores a backup of the shard into a scratch mysqld, and verifies it can be used: file hashes, restore, optional replication check and sanity queries"})
+}
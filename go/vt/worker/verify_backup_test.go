@@ -0,0 +1,193 @@
+// Copyright 2016, Google Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package worker
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/context"
+
+	"github.com/youtube/vitess/go/sqltypes"
+	"github.com/youtube/vitess/go/vt/logutil"
+	"github.com/youtube/vitess/go/vt/mysqlctl"
+	"github.com/youtube/vitess/go/vt/mysqlctl/backupstorage"
+	"github.com/youtube/vitess/go/vt/mysqlctl/filebackupstorage"
+	"github.com/youtube/vitess/go/vt/mysqlctl/replication"
+	"github.com/youtube/vitess/go/vt/tabletmanager/tmclient"
+	"github.com/youtube/vitess/go/vt/vttest/fakesqldb"
+	"github.com/youtube/vitess/go/vt/wrangler"
+	"github.com/youtube/vitess/go/vt/zktopo/zktestserver"
+)
+
+// fakeScratchMysqld is a scratch mysqld backed by a FakeMysqlDaemon.
+type fakeScratchMysqld struct {
+	*mysqlctl.FakeMysqlDaemon
+
+	// startError is returned by Start, to fail the restore.
+	startError error
+	tornDown   bool
+}
+
+func (f *fakeScratchMysqld) Init(ctx context.Context, initDBSQLFile string) error {
+	f.Running = true
+	return nil
+}
+
+func (f *fakeScratchMysqld) Teardown(ctx context.Context, force bool) error {
+	f.Running = false
+	f.tornDown = true
+	return nil
+}
+
+func (f *fakeScratchMysqld) Start(ctx context.Context, mysqldArgs ...string) error {
+	if f.startError != nil {
+		return f.startError
+	}
+	return f.FakeMysqlDaemon.Start(ctx, mysqldArgs...)
+}
+
+// newTestMycnf creates the directories of a fake mysqld under root.
+func newTestMycnf(t *testing.T, root string) *mysqlctl.Mycnf {
+	cnf := &mysqlctl.Mycnf{
+		DataDir:               path.Join(root, "data"),
+		InnodbDataHomeDir:     path.Join(root, "innodb_data"),
+		InnodbLogGroupHomeDir: path.Join(root, "innodb_log"),
+		BinLogPath:            path.Join(root, "bin-logs/filename_prefix"),
+		RelayLogPath:          path.Join(root, "relay-logs/filename_prefix"),
+		RelayLogIndexPath:     path.Join(root, "relay-log.index"),
+		RelayLogInfoPath:      path.Join(root, "relay-log.info"),
+	}
+	for _, dir := range []string{path.Join(cnf.DataDir, "vt_db"), cnf.InnodbDataHomeDir, cnf.InnodbLogGroupHomeDir} {
+		if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+			t.Fatalf("failed to create directory %v: %v", dir, err)
+		}
+	}
+	return cnf
+}
+
+func TestVerifyBackup(t *testing.T) {
+	ctx := context.Background()
+	db := fakesqldb.Register()
+	ts := zktestserver.New(t, []string{"cell1"})
+	wr := wrangler.New(logutil.NewConsoleLogger(), ts, tmclient.NewTabletManagerClient())
+
+	// The restore populates the metadata tables of the scratch mysqld.
+	db.AddQuery("CREATE DATABASE IF NOT EXISTS _vt", &sqltypes.Result{})
+	db.AddQuery("BEGIN", &sqltypes.Result{})
+	db.AddQuery("COMMIT", &sqltypes.Result{})
+	db.AddQueryPattern(`SET @@session\.sql_log_bin = .*`, &sqltypes.Result{})
+	db.AddQueryPattern(`CREATE TABLE IF NOT EXISTS _vt\.shard_metadata .*`, &sqltypes.Result{})
+	db.AddQueryPattern(`CREATE TABLE IF NOT EXISTS _vt\.local_metadata .*`, &sqltypes.Result{})
+	db.AddQueryPattern(`INSERT INTO _vt\.local_metadata .*`, &sqltypes.Result{})
+
+	root, err := ioutil.TempDir("", "verifybackuptest")
+	if err != nil {
+		t.Fatalf("ioutil.TempDir failed: %v", err)
+	}
+	defer os.RemoveAll(root)
+	*filebackupstorage.FileBackupStorageRoot = path.Join(root, "fbs")
+	*backupstorage.BackupStorageImplementation = "file"
+
+	// Take a backup of a fake master.
+	sourceCnf := newTestMycnf(t, path.Join(root, "source"))
+	if err := ioutil.WriteFile(path.Join(sourceCnf.DataDir, "vt_db", "db.opt"), []byte("db opt file"), os.ModePerm); err != nil {
+		t.Fatalf("failed to write db.opt: %v", err)
+	}
+	source := mysqlctl.NewFakeMysqlDaemon(db)
+	source.Mycnf = sourceCnf
+	source.ReadOnly = true
+	source.SlaveStatusError = mysqlctl.ErrNotSlave
+	source.CurrentMasterPosition = replication.Position{
+		GTIDSet: replication.MariadbGTID{
+			Domain:   2,
+			Server:   123,
+			Sequence: 457,
+		},
+	}
+	const backupName = "backup<1>"
+	if err := mysqlctl.Backup(ctx, source, logutil.NewConsoleLogger(), "ks/0", backupName, 1, nil); err != nil {
+		t.Fatalf("Backup failed: %v", err)
+	}
+
+	testcases := []struct {
+		name       string
+		startError error
+		wantErr    string
+	}{{
+		name: "success",
+	}, {
+		name:       "failed restore",
+		startError: errors.New("mysqld cannot start"),
+		wantErr:    "cannot restore backup",
+	}}
+	defer func(f func(uint32, int32) (scratchMysqld, error)) { newScratchMysqld = f }(newScratchMysqld)
+	for _, tcase := range testcases {
+		scratch := &fakeScratchMysqld{
+			FakeMysqlDaemon: mysqlctl.NewFakeMysqlDaemon(db),
+			startError:      tcase.startError,
+		}
+		scratch.Running = false
+		scratch.Mycnf = newTestMycnf(t, path.Join(root, strings.Replace(tcase.name, " ", "_", -1)))
+		scratch.FetchSuperQueryMap = map[string]*sqltypes.Result{
+			"SELECT 1": {Rows: [][]sqltypes.Value{{sqltypes.MakeTrusted(sqltypes.Int64, []byte("1"))}}},
+		}
+		newScratchMysqld = func(tabletUID uint32, mysqlPort int32) (scratchMysqld, error) {
+			return scratch, nil
+		}
+
+		w, err := NewVerifyBackupWorker(wr, "ks", "0", VerifyBackupConfig{
+			ScratchTabletUID: 1,
+			ScratchMysqlPort: 3306,
+			InitDBSQLFile:    "init_db.sql",
+			Concurrency:      1,
+			SanityQueries:    []string{"SELECT 1"},
+		})
+		if err != nil {
+			t.Fatalf("%v: NewVerifyBackupWorker failed: %v", tcase.name, err)
+		}
+		err = w.Run(ctx)
+		if tcase.wantErr == "" {
+			if err != nil {
+				t.Errorf("%v: Run failed: %v", tcase.name, err)
+			}
+			data, err := ioutil.ReadFile(path.Join(scratch.Mycnf.DataDir, "vt_db", "db.opt"))
+			if err != nil || string(data) != "db opt file" {
+				t.Errorf("%v: restored db.opt: %q, %v", tcase.name, data, err)
+			}
+			if got := w.StatusAsText(); !strings.Contains(got, `sanity query "SELECT 1" returned 1 rows`) {
+				t.Errorf("%v: status does not report the sanity query:\n%v", tcase.name, got)
+			}
+		} else if err == nil || !strings.Contains(err.Error(), tcase.wantErr) {
+			t.Errorf("%v: Run: %v, want error containing %q", tcase.name, err, tcase.wantErr)
+		}
+		if !scratch.tornDown {
+			t.Errorf("%v: scratch mysqld was not torn down", tcase.name)
+		}
+		if got := string(w.StatusAsHTML()); !strings.Contains(got, "backup&lt;1&gt;") {
+			t.Errorf("%v: backup name is not escaped in the status:\n%v", tcase.name, got)
+		}
+	}
+}
+
+func TestVerifyBackupStatusEscape(t *testing.T) {
+	w, err := NewVerifyBackupWorker(nil, "<ks>", "<0>", VerifyBackupConfig{
+		ScratchTabletUID: 1,
+		ScratchMysqlPort: 3306,
+		InitDBSQLFile:    "init_db.sql",
+		Concurrency:      1,
+	})
+	if err != nil {
+		t.Fatalf("NewVerifyBackupWorker failed: %v", err)
+	}
+	got := string(w.StatusAsHTML())
+	if want := "&lt;ks&gt;/&lt;0&gt;"; !strings.Contains(got, want) {
+		t.Errorf("keyspace and shard are not escaped in the status, got:\n%v\nwant it to contain: %v", got, want)
+	}
+}
@@ -0,0 +1,628 @@
+// Copyright 2016, Google Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package worker
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"io"
+	"sort"
+	"sync"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/youtube/vitess/go/sqltypes"
+	"github.com/youtube/vitess/go/sync2"
+	"github.com/youtube/vitess/go/vt/concurrency"
+	"github.com/youtube/vitess/go/vt/key"
+	"github.com/youtube/vitess/go/vt/topo"
+	"github.com/youtube/vitess/go/vt/topo/topoproto"
+	"github.com/youtube/vitess/go/vt/vtgate/vindexes"
+	"github.com/youtube/vitess/go/vt/wrangler"
+
+	topodatapb "github.com/youtube/vitess/go/vt/proto/topodata"
+)
+
+// VerifyLookupVindexConfig contains the parameters of a
+// VerifyLookupVindex run.
+type VerifyLookupVindexConfig struct {
+	// Table is the owner table of the vindex.
+	Table string
+	// Vindex is the name of the lookup vindex.
+	Vindex string
+	// Backfill creates the missing entries of the lookup table.
+	Backfill bool
+	// DeleteOrphans deletes the entries of the lookup table which have
+	// no row in the owner table.
+	DeleteOrphans bool
+	// VtgateServer is the vtgate used to read and write the lookup
+	// table, as vtgate itself does.
+	VtgateServer         string
+	VtgateConnectTimeout time.Duration
+	// Concurrency is the number of inconsistencies checked, and fixed,
+	// in parallel.
+	Concurrency             int
+	MinHealthyRdonlyTablets int
+	// MaxReported is the maximum number of inconsistencies logged
+	// for each kind. All of them are counted.
+	MaxReported int
+}
+
+// ownerCheckBatchSize is the maximum number of rows of the owner table
+// read at once on a master, to check an entry of the lookup table.
+const ownerCheckBatchSize = 1000
+
+// lookupEntryKey identifies an entry of a lookup table: a value of the
+// vindex column, and a keyspace id.
+type lookupEntryKey struct {
+	from string
+	ksid string
+}
+
+// lookupEntry is an entry of a lookup table, expected because of a row
+// of the owner table, or found in the lookup table.
+type lookupEntry struct {
+	from sqltypes.Value
+	ksid []byte
+	// found is set when the entry is found in the lookup table.
+	found bool
+}
+
+// VerifyLookupVindexWorker checks a lookup vindex against its owner
+// table, one shard at a time. The owner table is read on an rdonly
+// tablet of the shard, to compute the expected entries of the lookup
+// table, and the lookup table is read through vtgate, keeping only the
+// entries which belong to the shard. So only the expected entries of
+// one shard are held in memory, and the lookup table is read once per
+// shard. The differences are then checked again
+// against the current data of the masters, so a row inserted or deleted
+// during the scan is not reported. Missing entries can be created, and
+// orphan entries deleted, like vtgate does when it inserts or deletes a
+// row of the owner table. The worker fails if inconsistencies remain,
+// so it can be run periodically as a verifier.
+type VerifyLookupVindexWorker struct {
+	StatusWorker
+
+	wr       *wrangler.Wrangler
+	cell     string
+	keyspace string
+	config   VerifyLookupVindexConfig
+	cleaner  *wrangler.Cleaner
+
+	// populated during WorkerStateInit, read-only after that
+	shards        []*topo.ShardInfo
+	column        *vindexes.ColumnVindex
+	lookup        vindexes.LookupTable
+	primary       *vindexes.ColumnVindex
+	primaryVindex vindexes.Unique
+	vcursor       *vtgateVCursor
+
+	// populated during WorkerStateFindTargets, read-only after that
+	// aliases, masters and dbNames have one entry for each shard.
+	aliases []*topodatapb.TabletAlias
+	masters []*topodatapb.Tablet
+	dbNames []string
+
+	ownerRows         sync2.AtomicInt64
+	lookupRows        sync2.AtomicInt64
+	missing           sync2.AtomicInt64
+	orphaned          sync2.AtomicInt64
+	created           sync2.AtomicInt64
+	deleted           sync2.AtomicInt64
+	reportedMissing   sync2.AtomicInt64
+	reportedOrphaned  sync2.AtomicInt64
+	reportedUnmapped  sync2.AtomicInt64
+	unmappedOwnerRows sync2.AtomicInt64
+}
+
+// NewVerifyLookupVindexWorker returns a new VerifyLookupVindexWorker
+// object.
+func NewVerifyLookupVindexWorker(wr *wrangler.Wrangler, cell, keyspace string, config VerifyLookupVindexConfig) (Worker, error) {
+	if config.Table == "" || config.Vindex == "" {
+		return nil, fmt.Errorf("table and vindex must be set")
+	}
+	if config.VtgateServer == "" {
+		return nil, fmt.Errorf("vtgate_server must be set")
+	}
+	if config.Concurrency < 1 {
+		return nil, fmt.Errorf("concurrency must be at least 1, got %v", config.Concurrency)
+	}
+	return &VerifyLookupVindexWorker{
+		StatusWorker: NewStatusWorker(),
+		wr:           wr,
+		cell:         cell,
+		keyspace:     keyspace,
+		config:       config,
+		cleaner:      &wrangler.Cleaner{},
+	}, nil
+}
+
+func (vw *VerifyLookupVindexWorker) statusCounters() string {
+	return fmt.Sprintf("%v owner rows, %v lookup rows, %v missing entries (%v created), %v orphan entries (%v deleted)",
+		vw.ownerRows.Get(), vw.lookupRows.Get(), vw.missing.Get(), vw.created.Get(), vw.orphaned.Get(), vw.deleted.Get())
+}
+
+// StatusAsHTML implements the Worker interface.
+func (vw *VerifyLookupVindexWorker) StatusAsHTML() template.HTML {
+	state := vw.State()
+
+	result := "<b>Verifying lookup vindex:</b> " + vw.config.Vindex + " of " + vw.keyspace + "." + vw.config.Table + "</br>\n"
+	result += "<b>State:</b> " + state.String() + "</br>\n"
+	switch state {
+	case WorkerStateDiff, WorkerStateDone:
+		result += "<b>Found:</b> " + vw.statusCounters() + "</br>\n"
+	}
+	return template.HTML(result)
+}
+
+// StatusAsText implements the Worker interface.
+func (vw *VerifyLookupVindexWorker) StatusAsText() string {
+	state := vw.State()
+
+	result := "Verifying lookup vindex: " + vw.config.Vindex + " of " + vw.keyspace + "." + vw.config.Table + "\n"
+	result += "State: " + state.String() + "\n"
+	switch state {
+	case WorkerStateDiff, WorkerStateDone:
+		result += "Found: " + vw.statusCounters() + "\n"
+	}
+	return result
+}
+
+// Run is mostly a wrapper to run the cleanup at the end.
+func (vw *VerifyLookupVindexWorker) Run(ctx context.Context) error {
+	resetVars()
+	err := vw.run(ctx)
+
+	vw.SetState(WorkerStateCleanUp)
+	if vw.vcursor != nil {
+		vw.vcursor.Close()
+	}
+	cerr := vw.cleaner.CleanUp(vw.wr)
+	if cerr != nil {
+		if err != nil {
+			vw.wr.Logger().Errorf("CleanUp failed in addition to job error: %v", cerr)
+		} else {
+			err = cerr
+		}
+	}
+	if err != nil {
+		vw.SetState(WorkerStateError)
+		return err
+	}
+	vw.SetState(WorkerStateDone)
+	return nil
+}
+
+func (vw *VerifyLookupVindexWorker) run(ctx context.Context) error {
+	// first state: read what we need to do
+	if err := vw.init(ctx); err != nil {
+		return fmt.Errorf("init() failed: %v", err)
+	}
+	if err := checkDone(ctx); err != nil {
+		return err
+	}
+
+	// second state: find the tablets
+	if err := vw.findTargets(ctx); err != nil {
+		return fmt.Errorf("findTargets() failed: %v", err)
+	}
+	if err := checkDone(ctx); err != nil {
+		return err
+	}
+
+	// third state: scan both tables, then check and fix the differences
+	if err := vw.diff(ctx); err != nil {
+		return fmt.Errorf("diff() failed: %v", err)
+	}
+	return nil
+}
+
+// init phase:
+// - read the shards and the vschema of the keyspace
+// - find the vindex and the primary vindex of the table
+// - connect to vtgate
+func (vw *VerifyLookupVindexWorker) init(ctx context.Context) error {
+	vw.SetState(WorkerStateInit)
+
+	shortCtx, cancel := context.WithTimeout(ctx, *remoteActionsTimeout)
+	shardMap, err := vw.wr.TopoServer().FindAllShardsInKeyspace(shortCtx, vw.keyspace)
+	cancel()
+	if err != nil {
+		return fmt.Errorf("cannot read shards of keyspace %v: %v", vw.keyspace, err)
+	}
+	if len(shardMap) == 0 {
+		return fmt.Errorf("keyspace %v has no shards", vw.keyspace)
+	}
+	shardNames := make([]string, 0, len(shardMap))
+	for shardName := range shardMap {
+		shardNames = append(shardNames, shardName)
+	}
+	sort.Strings(shardNames)
+	vw.shards = make([]*topo.ShardInfo, len(shardNames))
+	for i, shardName := range shardNames {
+		vw.shards[i] = shardMap[shardName]
+	}
+
+	shortCtx, cancel = context.WithTimeout(ctx, *remoteActionsTimeout)
+	kschema, err := vw.wr.TopoServer().GetVSchema(shortCtx, vw.keyspace)
+	cancel()
+	if err != nil {
+		return fmt.Errorf("cannot load VSchema for keyspace %v: %v", vw.keyspace, err)
+	}
+	keyspaceSchema, err := vindexes.BuildKeyspaceSchema(kschema, vw.keyspace)
+	if err != nil {
+		return fmt.Errorf("cannot build vschema for keyspace %v: %v", vw.keyspace, err)
+	}
+	if !keyspaceSchema.Keyspace.Sharded {
+		return fmt.Errorf("keyspace %v is not sharded, it has no vindexes", vw.keyspace)
+	}
+	table, ok := keyspaceSchema.Tables[vw.config.Table]
+	if !ok {
+		return fmt.Errorf("no vschema definition for table %v", vw.config.Table)
+	}
+	if len(table.ColumnVindexes) == 0 {
+		return fmt.Errorf("no vindex definition for table %v", vw.config.Table)
+	}
+	vw.primary = table.ColumnVindexes[0]
+	if vw.primaryVindex, ok = vw.primary.Vindex.(vindexes.Unique); !ok {
+		return fmt.Errorf("primary vindex %v is not unique for table %v", vw.primary.Name, vw.config.Table)
+	}
+	for _, cv := range table.Owned {
+		if cv.Name == vw.config.Vindex {
+			vw.column = cv
+		}
+	}
+	if vw.column == nil {
+		return fmt.Errorf("table %v does not own a vindex %v", vw.config.Table, vw.config.Vindex)
+	}
+	if vw.lookup, ok = vw.column.Vindex.(vindexes.LookupTable); !ok {
+		return fmt.Errorf("vindex %v is not a lookup vindex backed by a table", vw.config.Vindex)
+	}
+
+	vw.vcursor, err = newVtgateVCursor(ctx, vw.config.VtgateServer, vw.config.VtgateConnectTimeout)
+	return err
+}
+
+// findTargets phase:
+// - find one rdonly in each shard, to scan the owner table
+// - mark it as 'worker' pointing back to us
+// - find the master of each shard, to check the differences
+func (vw *VerifyLookupVindexWorker) findTargets(ctx context.Context) error {
+	vw.SetState(WorkerStateFindTargets)
+
+	vw.aliases = make([]*topodatapb.TabletAlias, len(vw.shards))
+	vw.masters = make([]*topodatapb.Tablet, len(vw.shards))
+	vw.dbNames = make([]string, len(vw.shards))
+	for i, si := range vw.shards {
+		alias, err := FindWorkerTablet(ctx, vw.wr, vw.cleaner, nil /* tsc */, vw.cell, si.Keyspace(), si.ShardName(), vw.config.MinHealthyRdonlyTablets)
+		if err != nil {
+			return fmt.Errorf("FindWorkerTablet() failed for %v/%v/%v: %v", vw.cell, si.Keyspace(), si.ShardName(), err)
+		}
+		vw.aliases[i] = alias
+		vw.wr.Logger().Infof("Using tablet %v to scan %v/%v", topoproto.TabletAliasString(alias), si.Keyspace(), si.ShardName())
+
+		if si.MasterAlias == nil {
+			return fmt.Errorf("shard %v/%v has no master", si.Keyspace(), si.ShardName())
+		}
+		shortCtx, cancel := context.WithTimeout(ctx, *remoteActionsTimeout)
+		ti, err := vw.wr.TopoServer().GetTablet(shortCtx, si.MasterAlias)
+		cancel()
+		if err != nil {
+			return fmt.Errorf("cannot read master tablet %v: %v", topoproto.TabletAliasString(si.MasterAlias), err)
+		}
+		vw.masters[i] = ti.Tablet
+		vw.dbNames[i] = topoproto.TabletDbName(ti.Tablet)
+	}
+	return nil
+}
+
+// diff phase, for each shard:
+// - scan the owner table, to compute the expected entries
+// - scan the lookup table, to find the missing and orphan entries
+// - check them again on the masters, fix them if asked to
+func (vw *VerifyLookupVindexWorker) diff(ctx context.Context) error {
+	vw.SetState(WorkerStateDiff)
+
+	for i := range vw.shards {
+		if err := vw.diffShard(ctx, i); err != nil {
+			return err
+		}
+	}
+
+	vw.wr.Logger().Infof("Verified vindex %v of %v.%v: %v", vw.config.Vindex, vw.keyspace, vw.config.Table, vw.statusCounters())
+	if vw.unmappedOwnerRows.Get() > 0 {
+		return fmt.Errorf("%v rows of %v.%v have no keyspace id in their primary vindex %v", vw.unmappedOwnerRows.Get(), vw.keyspace, vw.config.Table, vw.primary.Name)
+	}
+	if missing, orphaned := vw.missing.Get()-vw.created.Get(), vw.orphaned.Get()-vw.deleted.Get(); missing > 0 || orphaned > 0 {
+		return fmt.Errorf("vindex %v is inconsistent with table %v: %v missing entries, %v orphan entries", vw.config.Vindex, vw.config.Table, missing, orphaned)
+	}
+	return nil
+}
+
+// diffShard checks the entries of the lookup table which belong to a
+// shard, i.e. whose keyspace id is in the key range of the shard.
+func (vw *VerifyLookupVindexWorker) diffShard(ctx context.Context, shardIndex int) error {
+	si := vw.shards[shardIndex]
+	entries, err := vw.scanOwnerShard(ctx, shardIndex)
+	if err != nil {
+		return err
+	}
+	vw.wr.Logger().Infof("Scanned %v.%v on %v/%v, expecting %v entries in vindex %v", vw.keyspace, vw.config.Table, si.Keyspace(), si.ShardName(), len(entries), vw.config.Vindex)
+
+	orphans, err := vw.scanLookupTable(ctx, shardIndex, entries)
+	if err != nil {
+		return err
+	}
+	if err := checkDone(ctx); err != nil {
+		return err
+	}
+
+	var missing []*lookupEntry
+	for _, e := range entries {
+		if !e.found {
+			missing = append(missing, e)
+		}
+	}
+	if err := vw.checkEntries(ctx, missing, vw.checkMissing); err != nil {
+		return err
+	}
+	return vw.checkEntries(ctx, orphans, vw.checkOrphan)
+}
+
+// scanOwnerShard reads the vindex column of the owner table on a shard,
+// and returns the expected entries of the lookup table.
+func (vw *VerifyLookupVindexWorker) scanOwnerShard(ctx context.Context, shardIndex int) (map[lookupEntryKey]*lookupEntry, error) {
+	query := fmt.Sprintf("SELECT %v, %v FROM %v WHERE %v IS NOT NULL",
+		escape(vw.column.Column.Original()), escape(vw.primary.Column.Original()), escape(vw.config.Table), escape(vw.column.Column.Original()))
+	qrr, err := NewQueryResultReaderForTablet(ctx, vw.wr.TopoServer(), vw.aliases[shardIndex], query)
+	if err != nil {
+		return nil, fmt.Errorf("cannot scan %v on tablet %v: %v", vw.config.Table, topoproto.TabletAliasString(vw.aliases[shardIndex]), err)
+	}
+	defer qrr.Close()
+
+	entries := make(map[lookupEntryKey]*lookupEntry)
+	rowReader := NewRowReader(qrr)
+	for {
+		row, err := rowReader.Next()
+		if err != nil {
+			return nil, fmt.Errorf("cannot scan %v on tablet %v: %v", vw.config.Table, topoproto.TabletAliasString(vw.aliases[shardIndex]), err)
+		}
+		if row == nil {
+			// no more rows
+			return entries, nil
+		}
+		vw.ownerRows.Add(1)
+
+		ksid, err := vw.mapPrimary(row[1])
+		if err != nil {
+			vw.unmappedOwnerRows.Add(1)
+			if vw.reportedUnmapped.Add(1) <= int64(vw.config.MaxReported) {
+				vw.wr.Logger().Warningf("Skipping row of %v with %v=%v: %v", vw.config.Table, vw.primary.Column, row[1], err)
+			}
+			continue
+		}
+		k := lookupEntryKey{
+			from: string(row[0].Raw()),
+			ksid: string(ksid),
+		}
+		if _, ok := entries[k]; !ok {
+			entries[k] = &lookupEntry{
+				from: row[0],
+				ksid: ksid,
+			}
+		}
+	}
+}
+
+// scanLookupTable reads the lookup table through vtgate, and marks the
+// expected entries it has. The other entries which belong to the shard
+// are returned as orphan candidates. The entries which belong to no
+// shard are returned with the first shard.
+func (vw *VerifyLookupVindexWorker) scanLookupTable(ctx context.Context, shardIndex int, entries map[lookupEntryKey]*lookupEntry) ([]*lookupEntry, error) {
+	table, from, to := vw.lookup.LookupColumns()
+	query := fmt.Sprintf("select %v, %v from %v", from, to, table)
+	stream, err := vw.vcursor.StreamExecute(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("cannot scan lookup table %v: %v", table, err)
+	}
+	var orphans []*lookupEntry
+	for {
+		qr, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("cannot scan lookup table %v: %v", table, err)
+		}
+		for _, row := range qr.Rows {
+			ksid, err := vw.lookup.KeyspaceID(row[1])
+			if err != nil {
+				return nil, fmt.Errorf("invalid entry %v in lookup table %v: %v", row, table, err)
+			}
+			inShard := false
+			switch vw.shardForKeyspaceID(ksid) {
+			case shardIndex:
+				inShard = true
+			case -1:
+				inShard = shardIndex == 0
+			}
+			if inShard {
+				// Each row is counted in one scan only.
+				vw.lookupRows.Add(1)
+			}
+			k := lookupEntryKey{
+				from: string(row[0].Raw()),
+				ksid: string(ksid),
+			}
+			if e, ok := entries[k]; ok {
+				e.found = true
+				continue
+			}
+			if inShard {
+				orphans = append(orphans, &lookupEntry{
+					from: row[0],
+					ksid: ksid,
+				})
+			}
+		}
+	}
+	vw.wr.Logger().Infof("Scanned lookup table %v for %v/%v, %v orphan candidates", table, vw.shards[shardIndex].Keyspace(), vw.shards[shardIndex].ShardName(), len(orphans))
+	return orphans, nil
+}
+
+// checkEntries runs check on the entries, with config.Concurrency
+// checks in parallel.
+func (vw *VerifyLookupVindexWorker) checkEntries(ctx context.Context, entries []*lookupEntry, check func(context.Context, *lookupEntry) error) error {
+	rec := concurrency.AllErrorRecorder{}
+	sema := sync2.NewSemaphore(vw.config.Concurrency, 0)
+	wg := sync.WaitGroup{}
+	for _, e := range entries {
+		if ctx.Err() != nil || rec.HasErrors() {
+			break
+		}
+		wg.Add(1)
+		sema.Acquire()
+		go func(e *lookupEntry) {
+			defer wg.Done()
+			defer sema.Release()
+			rec.RecordError(check(ctx, e))
+		}(e)
+	}
+	wg.Wait()
+	if rec.HasErrors() {
+		return rec.Error()
+	}
+	return checkDone(ctx)
+}
+
+// checkMissing checks that an expected entry is still missing, and
+// creates it if asked to.
+func (vw *VerifyLookupVindexWorker) checkMissing(ctx context.Context, e *lookupEntry) error {
+	owned, err := vw.ownerHasEntry(ctx, e)
+	if err != nil || !owned {
+		return err
+	}
+	exists, err := vw.column.Vindex.Verify(vw.vcursor, e.from, e.ksid)
+	if err != nil || exists {
+		return err
+	}
+
+	vw.missing.Add(1)
+	if vw.reportedMissing.Add(1) <= int64(vw.config.MaxReported) {
+		vw.wr.Logger().Warningf("Missing entry in vindex %v: %v=%v for keyspace id %x", vw.config.Vindex, vw.column.Column, e.from, e.ksid)
+	}
+	if !vw.config.Backfill {
+		return nil
+	}
+	if err := vw.lookup.Create(vw.vcursor, e.from, e.ksid); err != nil {
+		return fmt.Errorf("cannot create entry %v=%v in vindex %v: %v", vw.column.Column, e.from, vw.config.Vindex, err)
+	}
+	// The row may have been deleted since it was checked.
+	// Then the new entry is an orphan, and it is deleted.
+	owned, err = vw.ownerHasEntry(ctx, e)
+	if err != nil {
+		return err
+	}
+	if !owned {
+		return vw.lookup.Delete(vw.vcursor, []interface{}{e.from}, e.ksid)
+	}
+	vw.created.Add(1)
+	return nil
+}
+
+// checkOrphan checks that an entry of the lookup table still exists and
+// has no row in the owner table, and deletes it if asked to.
+func (vw *VerifyLookupVindexWorker) checkOrphan(ctx context.Context, e *lookupEntry) error {
+	exists, err := vw.column.Vindex.Verify(vw.vcursor, e.from, e.ksid)
+	if err != nil || !exists {
+		return err
+	}
+	owned, err := vw.ownerHasEntry(ctx, e)
+	if err != nil || owned {
+		return err
+	}
+
+	vw.orphaned.Add(1)
+	if vw.reportedOrphaned.Add(1) <= int64(vw.config.MaxReported) {
+		vw.wr.Logger().Warningf("Orphan entry in vindex %v: %v=%v for keyspace id %x", vw.config.Vindex, vw.column.Column, e.from, e.ksid)
+	}
+	if !vw.config.DeleteOrphans {
+		return nil
+	}
+	if err := vw.lookup.Delete(vw.vcursor, []interface{}{e.from}, e.ksid); err != nil {
+		return fmt.Errorf("cannot delete entry %v=%v from vindex %v: %v", vw.column.Column, e.from, vw.config.Vindex, err)
+	}
+	vw.deleted.Add(1)
+	return nil
+}
+
+// shardForKeyspaceID returns the index of the shard which has the
+// keyspace id, or -1 if no shard has it.
+func (vw *VerifyLookupVindexWorker) shardForKeyspaceID(ksid []byte) int {
+	for i, si := range vw.shards {
+		if key.KeyRangeContains(si.KeyRange, ksid) {
+			return i
+		}
+	}
+	return -1
+}
+
+// ownerHasEntry returns true if the owner table currently has a row
+// for the entry, on the master of the shard of its keyspace id.
+// The rows with the vindex value are read in batches of
+// ownerCheckBatchSize, ordered by the primary vindex column.
+func (vw *VerifyLookupVindexWorker) ownerHasEntry(ctx context.Context, e *lookupEntry) (bool, error) {
+	shardIndex := vw.shardForKeyspaceID(e.ksid)
+	if shardIndex == -1 {
+		// No shard can have this row.
+		return false, nil
+	}
+
+	value := &bytes.Buffer{}
+	e.from.EncodeSQL(value)
+	primaryColumn := escape(vw.primary.Column.Original())
+	after := ""
+	for {
+		query := fmt.Sprintf("SELECT %v FROM %v.%v WHERE %v = %v%v ORDER BY %v LIMIT %v",
+			primaryColumn, escape(vw.dbNames[shardIndex]), escape(vw.config.Table), escape(vw.column.Column.Original()), value.String(), after, primaryColumn, ownerCheckBatchSize)
+		shortCtx, cancel := context.WithTimeout(ctx, *remoteActionsTimeout)
+		qr, err := vw.wr.TabletManagerClient().ExecuteFetchAsApp(shortCtx, vw.masters[shardIndex], true, []byte(query), ownerCheckBatchSize)
+		cancel()
+		if err != nil {
+			return false, fmt.Errorf("cannot read %v on master %v: %v", vw.config.Table, topoproto.TabletAliasString(vw.masters[shardIndex].Alias), err)
+		}
+		rows := sqltypes.Proto3ToResult(qr).Rows
+		for _, row := range rows {
+			ksid, err := vw.mapPrimary(row[0])
+			if err != nil {
+				continue
+			}
+			if bytes.Equal(ksid, e.ksid) {
+				return true, nil
+			}
+		}
+		if len(rows) < ownerCheckBatchSize {
+			return false, nil
+		}
+		last := &bytes.Buffer{}
+		rows[len(rows)-1][0].EncodeSQL(last)
+		after = fmt.Sprintf(" AND %v > %v", primaryColumn, last.String())
+	}
+}
+
+// mapPrimary returns the keyspace id of a row, from the value of its
+// primary vindex column.
+func (vw *VerifyLookupVindexWorker) mapPrimary(value sqltypes.Value) ([]byte, error) {
+	ksids, err := vw.primaryVindex.Map(vw.vcursor, []interface{}{value})
+	if err != nil {
+		return nil, err
+	}
+	if len(ksids) != 1 || len(ksids[0]) == 0 {
+		return nil, fmt.Errorf("vindex %v returned no keyspace id", vw.primary.Name)
+	}
+	return ksids[0], nil
+}
@@ -0,0 +1,143 @@
+// Copyright 2016, Google Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package worker
+
+import (
+	"flag"
+	"fmt"
+	"html/template"
+	"net/http"
+	"strconv"
+
+	"golang.org/x/net/context"
+
+	"github.com/youtube/vitess/go/vt/wrangler"
+)
+
+const verifyLookupVindexHTML = `
+<!DOCTYPE html>
+<head>
+  <title>Verify Lookup Vindex Action</title>
+</head>
+<body>
+  <h1>Verify Lookup Vindex Action</h1>
+
+    {{if .Error}}
+      <b>Error:</b> {{.Error}}</br>
+    {{else}}
+    <form action="/Diffs/VerifyLookupVindex" method="post">
+      <LABEL for="keyspace">Keyspace: </LABEL>
+        <INPUT type="text" id="keyspace" name="keyspace" value=""></BR>
+      <LABEL for="table">Owner Table: </LABEL>
+        <INPUT type="text" id="table" name="table" value=""></BR>
+      <LABEL for="vindex">Lookup Vindex: </LABEL>
+        <INPUT type="text" id="vindex" name="vindex" value=""></BR>
+      <LABEL for="vtgateServer">vtgate address: </LABEL>
+        <INPUT type="text" id="vtgateServer" name="vtgateServer" value=""></BR>
+      <LABEL for="backfill">Create the missing entries: </LABEL>
+        <INPUT type="checkbox" id="backfill" name="backfill" value="true"></BR>
+      <LABEL for="deleteOrphans">Delete the orphan entries: </LABEL>
+        <INPUT type="checkbox" id="deleteOrphans" name="deleteOrphans" value="true"></BR>
+      <LABEL for="concurrency">Concurrency: </LABEL>
+        <INPUT type="text" id="concurrency" name="concurrency" value="{{.DefaultConcurrency}}"></BR>
+      <LABEL for="minHealthyRdonlyTablets">Minimum Number of required healthy RDONLY tablets in each shard: </LABEL>
+        <INPUT type="text" id="minHealthyRdonlyTablets" name="minHealthyRdonlyTablets" value="{{.DefaultMinHealthyRdonlyTablets}}"></BR>
+      <INPUT type="submit" name="submit" value="Verify Lookup Vindex"/>
+    </form>
+    {{end}}
+</body>
+`
+
+var verifyLookupVindexTemplate = mustParseTemplate("verifyLookupVindex", verifyLookupVindexHTML)
+
+const (
+	defaultVerifyLookupVindexConcurrency = 10
+	defaultVerifyLookupVindexMaxReported = 100
+)
+
+func commandVerifyLookupVindex(wi *Instance, wr *wrangler.Wrangler, subFlags *flag.FlagSet, args []string) (Worker, error) {
+	backfill := subFlags.Bool("backfill", false, "create the missing entries of the lookup table")
+	deleteOrphans := subFlags.Bool("delete_orphans", false, "delete the entries of the lookup table which have no row in the owner table")
+	vtgateServer := subFlags.String("vtgate_server", "", "vtgate used to read and write the lookup table")
+	vtgateConnectTimeout := subFlags.Duration("vtgate_connect_timeout", defaultVtgateConnectTimeout, "timeout to connect to vtgate")
+	concurrency := subFlags.Int("concurrency", defaultVerifyLookupVindexConcurrency, "number of differences checked, and fixed, in parallel")
+	maxReported := subFlags.Int("max_reported", defaultVerifyLookupVindexMaxReported, "maximum number of missing, and of orphan, entries logged (all of them are counted)")
+	minHealthyRdonlyTablets := subFlags.Int("min_healthy_rdonly_tablets", defaultMinHealthyRdonlyTablets, "minimum number of healthy RDONLY tablets in each shard before taking one out")
+	if err := subFlags.Parse(args); err != nil {
+		return nil, err
+	}
+	if subFlags.NArg() != 3 {
+		subFlags.Usage()
+		return nil, fmt.Errorf("command VerifyLookupVindex requires <keyspace> <table> <vindex>")
+	}
+
+	worker, err := NewVerifyLookupVindexWorker(wr, wi.cell, subFlags.Arg(0), VerifyLookupVindexConfig{
+		Table:                   subFlags.Arg(1),
+		Vindex:                  subFlags.Arg(2),
+		Backfill:                *backfill,
+		DeleteOrphans:           *deleteOrphans,
+		VtgateServer:            *vtgateServer,
+		VtgateConnectTimeout:    *vtgateConnectTimeout,
+		Concurrency:             *concurrency,
+		MinHealthyRdonlyTablets: *minHealthyRdonlyTablets,
+		MaxReported:             *maxReported,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("cannot create VerifyLookupVindex worker: %v", err)
+	}
+	return worker, nil
+}
+
+func interactiveVerifyLookupVindex(ctx context.Context, wi *Instance, wr *wrangler.Wrangler, w http.ResponseWriter, r *http.Request) (Worker, *template.Template, map[string]interface{}, error) {
+	if err := r.ParseForm(); err != nil {
+		return nil, nil, nil, fmt.Errorf("cannot parse form: %s", err)
+	}
+
+	if submit := r.FormValue("submit"); submit == "" {
+		// display the input form
+		result := make(map[string]interface{})
+		result["DefaultConcurrency"] = fmt.Sprintf("%v", defaultVerifyLookupVindexConcurrency)
+		result["DefaultMinHealthyRdonlyTablets"] = fmt.Sprintf("%v", defaultMinHealthyRdonlyTablets)
+		return nil, verifyLookupVindexTemplate, result, nil
+	}
+
+	// Process input form.
+	keyspace := r.FormValue("keyspace")
+	if keyspace == "" {
+		return nil, nil, nil, fmt.Errorf("keyspace is required")
+	}
+	concurrency, err := strconv.ParseInt(r.FormValue("concurrency"), 0, 64)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("cannot parse concurrency: %s", err)
+	}
+	minHealthyRdonlyTablets, err := strconv.ParseInt(r.FormValue("minHealthyRdonlyTablets"), 0, 64)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("cannot parse minHealthyRdonlyTablets: %s", err)
+	}
+
+	// start the verify job
+	wrk, err := NewVerifyLookupVindexWorker(wr, wi.cell, keyspace, VerifyLookupVindexConfig{
+		Table:                   r.FormValue("table"),
+		Vindex:                  r.FormValue("vindex"),
+		Backfill:                r.FormValue("backfill") == "true",
+		DeleteOrphans:           r.FormValue("deleteOrphans") == "true",
+		VtgateServer:            r.FormValue("vtgateServer"),
+		VtgateConnectTimeout:    defaultVtgateConnectTimeout,
+		Concurrency:             int(concurrency),
+		MinHealthyRdonlyTablets: int(minHealthyRdonlyTablets),
+		MaxReported:             defaultVerifyLookupVindexMaxReported,
+	})
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("cannot create VerifyLookupVindex worker: %v", err)
+	}
+	return wrk, nil, nil, nil
+}
+
+func init() {
+	AddCommand("Diffs", Command{"VerifyLookupVindex",
+		commandVerifyLookupVindex, interactiveVerifyLookupVindex,
+		"--vtgate_server=<addr> [--backfill] [--delete_orphans] [--concurrency=10] [--max_reported=100] <keyspace> <table> <vindex>",
+		"Checks a lookup vindex against its owner table: the table is scanned on an RDONLY tablet of each shard, and the lookup table through vtgate. Missing and orphan entries are checked again on the masters, then reported, created (--backfill) or deleted (--delete_orphans). Fails if inconsistencies remain, so it can be run periodically"})
+}
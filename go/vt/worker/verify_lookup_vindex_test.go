@@ -0,0 +1,303 @@
+// Copyright 2016, Google Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package worker
+
+import (
+	"encoding/binary"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/youtube/vitess/go/sqltypes"
+	"github.com/youtube/vitess/go/vt/tabletserver/grpcqueryservice"
+	"github.com/youtube/vitess/go/vt/tabletserver/queryservice/fakes"
+	"github.com/youtube/vitess/go/vt/vtgate/fakerpcvtgateconn"
+	"github.com/youtube/vitess/go/vt/vtgate/vindexes"
+	"github.com/youtube/vitess/go/vt/vtgate/vtgateconn"
+	"github.com/youtube/vitess/go/vt/vttest/fakesqldb"
+	"github.com/youtube/vitess/go/vt/wrangler/testlib"
+	"github.com/youtube/vitess/go/vt/zktopo/zktestserver"
+	"golang.org/x/net/context"
+
+	querypb "github.com/youtube/vitess/go/vt/proto/query"
+	topodatapb "github.com/youtube/vitess/go/vt/proto/topodata"
+	vschemapb "github.com/youtube/vitess/go/vt/proto/vschema"
+)
+
+// verifyLookupVindex is the vindex returned by the "verify_fake_lookup"
+// vindex type.
+var verifyLookupVindex *fakeLookup
+
+func init() {
+	vindexes.Register("verify_fake_lookup", func(name string, params map[string]string) (vindexes.Vindex, error) {
+		return verifyLookupVindex, nil
+	})
+}
+
+// verifyLookupTabletServer is a local QueryService implementation which
+// returns the rows of the owner table of a shard.
+type verifyLookupTabletServer struct {
+	t    *testing.T
+	rows [][]sqltypes.Value
+
+	*fakes.StreamHealthQueryService
+}
+
+func (sq *verifyLookupTabletServer) StreamExecute(ctx context.Context, target *querypb.Target, sql string, bindVariables map[string]interface{}, options *querypb.ExecuteOptions, sendReply func(reply *sqltypes.Result) error) error {
+	if want := "SELECT `c`, `id` FROM `t` WHERE `c` IS NOT NULL"; sql != want {
+		sq.t.Errorf("verifyLookupTabletServer: got query: %v, want: %v", sql, want)
+	}
+	if err := sendReply(&sqltypes.Result{
+		Fields: verifyLookupOwnerFields,
+	}); err != nil {
+		return err
+	}
+	for _, row := range sq.rows {
+		if err := sendReply(&sqltypes.Result{
+			Rows: [][]sqltypes.Value{row},
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var verifyLookupOwnerFields = []*querypb.Field{
+	{
+		Name: "c",
+		Type: sqltypes.VarChar,
+	},
+	{
+		Name: "id",
+		Type: sqltypes.Uint64,
+	},
+}
+
+// numericKeyspaceID returns the keyspace id of id in the numeric vindex.
+func numericKeyspaceID(id uint64) []byte {
+	ksid := make([]byte, 8)
+	binary.BigEndian.PutUint64(ksid, id)
+	return ksid
+}
+
+func ownerRow(c string, id uint64) []sqltypes.Value {
+	return []sqltypes.Value{
+		sqltypes.MakeString([]byte(c)),
+		sqltypes.MakeTrusted(sqltypes.Uint64, []byte(fmt.Sprintf("%v", id))),
+	}
+}
+
+// ownerResult returns the rows with the ids, as read on a master.
+func ownerResult(ids ...uint64) *sqltypes.Result {
+	qr := &sqltypes.Result{
+		Fields: verifyLookupOwnerFields[1:],
+	}
+	for _, id := range ids {
+		qr.Rows = append(qr.Rows, ownerRow("", id)[1:])
+	}
+	return qr
+}
+
+// ownerQuery returns the query which checks the owner table on a master.
+func ownerQuery(c, after string) string {
+	return fmt.Sprintf("SELECT `id` FROM `vt_ks`.`t` WHERE `c` = '%v'%v ORDER BY", c, after)
+}
+
+func TestVerifyLookupVindex(t *testing.T) {
+	// ids of the rows in the shard 80-.
+	const (
+		highID1 = 0x8000000000000001
+		highID2 = 0x8000000000000002
+		highID3 = 0x8000000000000003
+	)
+	// The rows read on the rdonly tablets.
+	rdonlyRows := map[string][][]sqltypes.Value{
+		"-80": {
+			ownerRow("a1", 1),
+			// Its entry is missing.
+			ownerRow("a2", 2),
+			// It was deleted since, on the master.
+			ownerRow("gone", 3),
+		},
+		"80-": {
+			ownerRow("b1", highID1),
+			// Its entry is missing, and the master has more than
+			// one batch of rows for this value.
+			ownerRow("many", highID2),
+		},
+	}
+	// The entries of the lookup table.
+	lookupEntries := []struct {
+		from string
+		id   uint64
+	}{
+		{"a1", 1},
+		{"b1", highID1},
+		// It has no row in the owner table.
+		{"orphan", 4},
+		// Its row was inserted since, on the master.
+		{"new", highID3},
+	}
+	manyIDs := make([]uint64, ownerCheckBatchSize)
+	for i := range manyIDs {
+		manyIDs[i] = uint64(100 + i)
+	}
+	manyAfter := fmt.Sprintf(" AND `id` > %v", manyIDs[len(manyIDs)-1])
+	masterResults := map[string]map[string]*sqltypes.Result{
+		"-80": {
+			ownerQuery("a2", ""): ownerResult(2),
+		},
+		"80-": {
+			ownerQuery("many", ""):        ownerResult(manyIDs...),
+			ownerQuery("many", manyAfter): ownerResult(highID2),
+			ownerQuery("new", ""):         ownerResult(highID3),
+		},
+	}
+
+	fake, protocol := fakerpcvtgateconn.RegisterFakeVTGateConnDialer()
+	oldProtocol := *vtgateconn.VtgateProtocol
+	*vtgateconn.VtgateProtocol = protocol
+	defer func() { *vtgateconn.VtgateProtocol = oldProtocol }()
+	lookupResult := &sqltypes.Result{
+		Fields: []*querypb.Field{
+			{
+				Name: "from",
+				Type: sqltypes.VarChar,
+			},
+			{
+				Name: "to",
+				Type: sqltypes.VarBinary,
+			},
+		},
+	}
+	for _, e := range lookupEntries {
+		lookupResult.Rows = append(lookupResult.Rows, []sqltypes.Value{
+			sqltypes.MakeString([]byte(e.from)),
+			sqltypes.MakeTrusted(sqltypes.VarBinary, numericKeyspaceID(e.id)),
+		})
+	}
+	fake.AddQuery("select from, to from lookup", nil, topodatapb.TabletType_MASTER, nil, false, lookupResult)
+
+	entry := func(from string, id uint64) string {
+		return fmt.Sprintf("%v:%x", from, numericKeyspaceID(id))
+	}
+	initialEntries := make(map[string]bool)
+	for _, e := range lookupEntries {
+		initialEntries[entry(e.from, e.id)] = true
+	}
+	fixedEntries := map[string]bool{
+		entry("a1", 1):         true,
+		entry("a2", 2):         true,
+		entry("b1", highID1):   true,
+		entry("many", highID2): true,
+		entry("new", highID3):  true,
+	}
+
+	testCases := []struct {
+		name        string
+		args        []string
+		wantErr     string
+		wantEntries map[string]bool
+	}{
+		{
+			name:        "report",
+			wantErr:     "2 missing entries, 1 orphan entries",
+			wantEntries: initialEntries,
+		},
+		{
+			name:        "backfill and delete orphans",
+			args:        []string{"-backfill", "-delete_orphans"},
+			wantEntries: fixedEntries,
+		},
+	}
+	for _, tc := range testCases {
+		verifyLookupVindex = newFakeLookup("c_lookup")
+		for e := range initialEntries {
+			verifyLookupVindex.entries[e] = true
+		}
+
+		db := fakesqldb.Register()
+		ts := zktestserver.New(t, []string{"cell1"})
+		ctx := context.Background()
+		wi := NewInstance(ts, "cell1", time.Second)
+
+		var masterDbs []*queryFakeDb
+		for i, shard := range []string{"-80", "80-"} {
+			master := testlib.NewFakeTablet(t, wi.wr, "cell1", uint32(10*i+10),
+				topodatapb.TabletType_MASTER, db, testlib.TabletKeyspaceShard(t, "ks", shard))
+			rdonly := testlib.NewFakeTablet(t, wi.wr, "cell1", uint32(10*i+11),
+				topodatapb.TabletType_RDONLY, db, testlib.TabletKeyspaceShard(t, "ks", shard))
+
+			masterDb := newQueryFakeDb(t, "master"+shard, masterResults[shard])
+			master.FakeMysqlDaemon.DbAppConnectionFactory = masterDb.factory()
+			masterDbs = append(masterDbs, masterDb)
+
+			for _, ft := range []*testlib.FakeTablet{master, rdonly} {
+				ft.StartActionLoop(t, wi.wr)
+				defer ft.StopActionLoop(t)
+			}
+
+			qs := fakes.NewStreamHealthQueryService(rdonly.Target())
+			qs.AddDefaultHealthResponse()
+			grpcqueryservice.Register(rdonly.RPCServer, &verifyLookupTabletServer{
+				t:                        t,
+				rows:                     rdonlyRows[shard],
+				StreamHealthQueryService: qs,
+			})
+		}
+		if err := wi.wr.RebuildKeyspaceGraph(ctx, "ks", nil); err != nil {
+			t.Fatalf("RebuildKeyspaceGraph failed: %v", err)
+		}
+		if err := ts.SaveVSchema(ctx, "ks", &vschemapb.Keyspace{
+			Sharded: true,
+			Vindexes: map[string]*vschemapb.Vindex{
+				"numeric": {
+					Type: "numeric",
+				},
+				"c_lookup": {
+					Type:  "verify_fake_lookup",
+					Owner: "t",
+				},
+			},
+			Tables: map[string]*vschemapb.Table{
+				"t": {
+					ColumnVindexes: []*vschemapb.ColumnVindex{
+						{
+							Column: "id",
+							Name:   "numeric",
+						},
+						{
+							Column: "c",
+							Name:   "c_lookup",
+						},
+					},
+				},
+			},
+		}); err != nil {
+			t.Fatalf("SaveVSchema failed: %v", err)
+		}
+
+		args := []string{"VerifyLookupVindex", "-vtgate_server", "localhost:0", "-min_healthy_rdonly_tablets", "1"}
+		args = append(args, tc.args...)
+		args = append(args, "ks", "t", "c_lookup")
+		err := runCommand(t, wi, wi.wr, args)
+		switch {
+		case tc.wantErr == "" && err != nil:
+			t.Errorf("%v: VerifyLookupVindex failed: %v", tc.name, err)
+		case tc.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tc.wantErr)):
+			t.Errorf("%v: VerifyLookupVindex returned: %v, want error containing: %v", tc.name, err, tc.wantErr)
+		}
+		if !reflect.DeepEqual(verifyLookupVindex.entries, tc.wantEntries) {
+			t.Errorf("%v: entries of the vindex: %v, want: %v", tc.name, verifyLookupVindex.entries, tc.wantEntries)
+		}
+		// The row of "many" is found in the second batch.
+		query := fmt.Sprintf("%v `id` LIMIT %v", ownerQuery("many", manyAfter), ownerCheckBatchSize)
+		if masterDbs[1].count(query) == 0 {
+			t.Errorf("%v: the master of 80- was not queried with: %v", tc.name, query)
+		}
+	}
+}
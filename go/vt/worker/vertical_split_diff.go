@@ -161,9 +161,9 @@ func (vsdw *VerticalSplitDiffWorker) init(ctx context.Context) error {
 	if err != nil {
 		return fmt.Errorf("cannot read keyspace %v: %v", vsdw.keyspace, err)
 	}
-	if len(vsdw.keyspaceInfo.ServedFroms) == 0 {
-		return fmt.Errorf("keyspace %v has no KeyspaceServedFrom", vsdw.keyspace)
-	}
+	// The keyspace has no ServedFrom when tables are moved into an
+	// existing keyspace (see vtctl MoveTables), so only its shard's
+	// SourceShards are checked.
 
 	// read the shardinfo and validate it
 	vsdw.shardInfo, err = vsdw.wr.TopoServer().GetShard(ctx, vsdw.keyspace, vsdw.shard)
@@ -0,0 +1,55 @@
+// Copyright 2016, Google Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package worker
+
+import (
+	"fmt"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/youtube/vitess/go/sqltypes"
+	"github.com/youtube/vitess/go/vt/vtgate/vtgateconn"
+
+	topodatapb "github.com/youtube/vitess/go/vt/proto/topodata"
+)
+
+// vtgateVCursor implements vindexes.VCursor by sending the queries
+// of the vindexes to vtgate, on the masters. It lets a worker create,
+// verify and delete the entries of lookup vindexes like vtgate does.
+type vtgateVCursor struct {
+	ctx  context.Context
+	conn *vtgateconn.VTGateConn
+}
+
+// newVtgateVCursor connects to vtgate. ctx is used for all the queries
+// of the cursor.
+func newVtgateVCursor(ctx context.Context, addr string, timeout time.Duration) (*vtgateVCursor, error) {
+	conn, err := vtgateconn.Dial(ctx, addr, timeout, "")
+	if err != nil {
+		return nil, fmt.Errorf("cannot connect to vtgate %v: %v", addr, err)
+	}
+	return &vtgateVCursor{
+		ctx:  ctx,
+		conn: conn,
+	}, nil
+}
+
+// Execute is part of the vindexes.VCursor interface.
+func (vc *vtgateVCursor) Execute(query string, bindvars map[string]interface{}) (*sqltypes.Result, error) {
+	shortCtx, cancel := context.WithTimeout(vc.ctx, *remoteActionsTimeout)
+	defer cancel()
+	return vc.conn.Execute(shortCtx, query, bindvars, topodatapb.TabletType_MASTER, nil)
+}
+
+// StreamExecute streams the results of a query from the masters.
+func (vc *vtgateVCursor) StreamExecute(ctx context.Context, query string) (sqltypes.ResultStream, error) {
+	return vc.conn.StreamExecute(ctx, query, nil, topodatapb.TabletType_MASTER, nil)
+}
+
+// Close closes the connection to vtgate.
+func (vc *vtgateVCursor) Close() {
+	vc.conn.Close()
+}
@@ -0,0 +1,432 @@
+// Copyright 2016, Google Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package workflow contains the Workflow Manager, which runs the
+// long-running jobs stored as Workflow records in the topology.
+//
+// The topology is the source of truth: Create, Start, Stop and Delete
+// only change the records, so any process can call them. One vtctld,
+// elected as the leader, runs the Manager main loop: it periodically
+// reads the records, runs the Running workflows, and interrupts the
+// ones that were stopped. The workflows checkpoint their progress in
+// the data field of their record, so a new leader resumes them after
+// a vtctld restart.
+package workflow
+
+import (
+	"crypto/rand"
+	"flag"
+	"fmt"
+	"sync"
+	"time"
+
+	log "github.com/golang/glog"
+	"golang.org/x/net/context"
+
+	"github.com/youtube/vitess/go/vt/topo"
+
+	workflowpb "github.com/youtube/vitess/go/vt/proto/workflow"
+)
+
+var (
+	pollInterval = flag.Duration("workflow_manager_poll_interval", 5*time.Second, "how often the workflow manager reads the workflow records from the topology to start and stop workflows")
+)
+
+// Factory can create the initial version of a Workflow, or
+// instantiate it from its record.
+type Factory interface {
+	// Init initializes a new workflow from the command line
+	// arguments. The Uuid, FactoryName and State fields are set,
+	// Init must set Name and Data.
+	Init(w *workflowpb.Workflow, args []string) error
+
+	// Instantiate loads a workflow from its record.
+	Instantiate(w *workflowpb.Workflow) (Workflow, error)
+}
+
+// Workflow is a running instance of a job.
+type Workflow interface {
+	// Run runs the workflow until it is done, and returns its
+	// error. It must return ctx.Err() as soon as possible once ctx
+	// is done: the workflow was stopped, or the manager lost the
+	// leadership. To checkpoint its progress, the workflow updates
+	// wi.Data and calls manager.Checkpoint. When Run is called, wi
+	// contains the last checkpoint, if any.
+	Run(ctx context.Context, manager *Manager, wi *topo.WorkflowInfo) error
+}
+
+var (
+	factoriesMu sync.Mutex
+	factories   = make(map[string]Factory)
+)
+
+// Register registers a Factory. It must be called from an init()
+// function. Registering the same name twice is a fatal error.
+func Register(factoryName string, factory Factory) {
+	factoriesMu.Lock()
+	defer factoriesMu.Unlock()
+	if _, ok := factories[factoryName]; ok {
+		log.Fatalf("workflow factory %v is already registered", factoryName)
+	}
+	factories[factoryName] = factory
+}
+
+func getFactory(factoryName string) (Factory, error) {
+	factoriesMu.Lock()
+	defer factoriesMu.Unlock()
+	factory, ok := factories[factoryName]
+	if !ok {
+		return nil, fmt.Errorf("no workflow factory registered for %v", factoryName)
+	}
+	return factory, nil
+}
+
+// Manager creates and runs workflows.
+type Manager struct {
+	ts topo.Server
+
+	// wake is signaled when a workflow was started or stopped by
+	// this process, so Run doesn't wait for the next poll.
+	wake chan struct{}
+
+	// mu protects running.
+	mu sync.Mutex
+	// running has the workflows run by this process, by uuid.
+	// It is only used by the leader.
+	running map[string]*runningWorkflow
+}
+
+// runningWorkflow is a workflow run by the manager.
+type runningWorkflow struct {
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewManager creates a Manager.
+func NewManager(ts topo.Server) *Manager {
+	return &Manager{
+		ts:      ts,
+		wake:    make(chan struct{}, 1),
+		running: make(map[string]*runningWorkflow),
+	}
+}
+
+// TopoServer returns the topo.Server used by the manager.
+func (m *Manager) TopoServer() topo.Server {
+	return m.ts
+}
+
+func (m *Manager) signal() {
+	select {
+	case m.wake <- struct{}{}:
+	default:
+	}
+}
+
+// newUUID returns a random (version 4) UUID.
+func newUUID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:]), nil
+}
+
+// Create creates a NotStarted workflow with the given factory, and
+// returns its uuid.
+func (m *Manager) Create(ctx context.Context, factoryName string, args []string) (string, error) {
+	factory, err := getFactory(factoryName)
+	if err != nil {
+		return "", err
+	}
+	uuid, err := newUUID()
+	if err != nil {
+		return "", err
+	}
+	w := &workflowpb.Workflow{
+		Uuid:        uuid,
+		FactoryName: factoryName,
+		State:       workflowpb.WorkflowState_NotStarted,
+	}
+	if err := factory.Init(w, args); err != nil {
+		return "", err
+	}
+	// Make sure the record can be run.
+	if _, err := factory.Instantiate(w); err != nil {
+		return "", err
+	}
+	if _, err := m.ts.CreateWorkflow(ctx, w); err != nil {
+		return "", err
+	}
+	return uuid, nil
+}
+
+// Start switches a NotStarted workflow to Running. The leader runs it
+// at its next poll.
+func (m *Manager) Start(ctx context.Context, uuid string) error {
+	return m.changeState(ctx, uuid, workflowpb.WorkflowState_NotStarted, workflowpb.WorkflowState_Running)
+}
+
+// Stop switches a Running workflow back to NotStarted. The leader
+// interrupts it at its next poll, and its next checkpoint fails. Its
+// data is kept, so it can be started again later.
+func (m *Manager) Stop(ctx context.Context, uuid string) error {
+	return m.changeState(ctx, uuid, workflowpb.WorkflowState_Running, workflowpb.WorkflowState_NotStarted)
+}
+
+// changeState changes the state of a workflow. It retries if the
+// record is changed concurrently, e.g. by a checkpoint.
+func (m *Manager) changeState(ctx context.Context, uuid string, from, to workflowpb.WorkflowState) error {
+	for {
+		wi, err := m.ts.GetWorkflow(ctx, uuid)
+		if err != nil {
+			return err
+		}
+		if wi.State != from {
+			return fmt.Errorf("workflow %v is %v, it must be %v", uuid, wi.State, from)
+		}
+		wi.State = to
+		if to == workflowpb.WorkflowState_Running && wi.StartTime == 0 {
+			wi.StartTime = time.Now().UnixNano()
+		}
+		switch err := m.ts.SaveWorkflow(ctx, wi); err {
+		case nil:
+			m.signal()
+			return nil
+		case topo.ErrBadVersion:
+			continue
+		default:
+			return err
+		}
+	}
+}
+
+// Delete deletes a workflow that isn't Running.
+func (m *Manager) Delete(ctx context.Context, uuid string) error {
+	wi, err := m.ts.GetWorkflow(ctx, uuid)
+	if err != nil {
+		return err
+	}
+	if wi.State == workflowpb.WorkflowState_Running {
+		return fmt.Errorf("workflow %v is Running, stop it first", uuid)
+	}
+	return m.ts.DeleteWorkflow(ctx, wi)
+}
+
+// List returns all the workflows, sorted by uuid.
+func (m *Manager) List(ctx context.Context) ([]*workflowpb.Workflow, error) {
+	uuids, err := m.ts.GetWorkflowNames(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*workflowpb.Workflow, 0, len(uuids))
+	for _, uuid := range uuids {
+		wi, err := m.ts.GetWorkflow(ctx, uuid)
+		if err == topo.ErrNoNode {
+			// Deleted in the meantime.
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, wi.Workflow)
+	}
+	return result, nil
+}
+
+// Wait waits until a workflow is Done, and returns its error.
+func (m *Manager) Wait(ctx context.Context, uuid string) error {
+	for {
+		wi, err := m.ts.GetWorkflow(ctx, uuid)
+		if err != nil {
+			return err
+		}
+		if wi.State == workflowpb.WorkflowState_Done {
+			if wi.Error != "" {
+				return fmt.Errorf("workflow %v failed: %v", uuid, wi.Error)
+			}
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(*pollInterval):
+		}
+	}
+}
+
+// Checkpoint saves the record of a running workflow. It fails if the
+// record was changed by another process, typically because the
+// workflow was stopped: the workflow should then return.
+func (m *Manager) Checkpoint(ctx context.Context, wi *topo.WorkflowInfo) error {
+	if err := m.ts.SaveWorkflow(ctx, wi); err != nil {
+		return fmt.Errorf("cannot checkpoint workflow %v, it was probably stopped: %v", wi.Uuid, err)
+	}
+	return nil
+}
+
+// Run runs the workflows until ctx is done. Only one process must
+// run it at a time: see RunWithElection. When ctx is done, the
+// running workflows are interrupted, and stay Running for the next
+// leader.
+func (m *Manager) Run(ctx context.Context) {
+	ticker := time.NewTicker(*pollInterval)
+	defer ticker.Stop()
+	for {
+		m.poll(ctx)
+		select {
+		case <-ctx.Done():
+			m.stopAll()
+			return
+		case <-ticker.C:
+		case <-m.wake:
+		}
+	}
+}
+
+// poll reads the workflow records, runs the Running workflows this
+// process doesn't run yet, and interrupts the ones that are not
+// Running any more.
+func (m *Manager) poll(ctx context.Context) {
+	uuids, err := m.ts.GetWorkflowNames(ctx)
+	if err != nil {
+		log.Warningf("workflow manager: cannot list workflows: %v", err)
+		return
+	}
+	records := make(map[string]*topo.WorkflowInfo)
+	for _, uuid := range uuids {
+		wi, err := m.ts.GetWorkflow(ctx, uuid)
+		if err == topo.ErrNoNode {
+			continue
+		}
+		if err != nil {
+			log.Warningf("workflow manager: cannot read workflow %v: %v", uuid, err)
+			// Don't interrupt it if it is running.
+			records[uuid] = nil
+			continue
+		}
+		records[uuid] = wi
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for uuid, rw := range m.running {
+		wi, ok := records[uuid]
+		if !ok || (wi != nil && wi.State != workflowpb.WorkflowState_Running) {
+			log.Infof("workflow manager: stopping workflow %v", uuid)
+			rw.cancel()
+		}
+	}
+	for uuid, wi := range records {
+		if wi == nil || wi.State != workflowpb.WorkflowState_Running {
+			continue
+		}
+		if _, ok := m.running[uuid]; ok {
+			continue
+		}
+		if err := m.runLocked(ctx, wi); err != nil {
+			log.Warningf("workflow manager: cannot run workflow %v: %v", uuid, err)
+		}
+	}
+}
+
+// runLocked starts running a workflow in the background. m.mu must
+// be held.
+func (m *Manager) runLocked(ctx context.Context, wi *topo.WorkflowInfo) error {
+	factory, err := getFactory(wi.FactoryName)
+	if err != nil {
+		return err
+	}
+	w, err := factory.Instantiate(wi.Workflow)
+	if err != nil {
+		return err
+	}
+	log.Infof("workflow manager: running workflow %v (%v)", wi.Uuid, wi.Name)
+	wctx, cancel := context.WithCancel(ctx)
+	rw := &runningWorkflow{
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+	m.running[wi.Uuid] = rw
+	go func() {
+		defer close(rw.done)
+		err := w.Run(wctx, m, wi)
+		if wctx.Err() != nil {
+			// Stopped, or the manager is shutting down: the
+			// workflow isn't done.
+			log.Infof("workflow manager: workflow %v interrupted: %v", wi.Uuid, err)
+		} else {
+			m.finish(ctx, wi, err)
+		}
+		cancel()
+		m.mu.Lock()
+		delete(m.running, wi.Uuid)
+		m.mu.Unlock()
+	}()
+	return nil
+}
+
+// finish saves a workflow as Done.
+func (m *Manager) finish(ctx context.Context, wi *topo.WorkflowInfo, err error) {
+	wi.State = workflowpb.WorkflowState_Done
+	wi.EndTime = time.Now().UnixNano()
+	if err != nil {
+		log.Warningf("workflow manager: workflow %v failed: %v", wi.Uuid, err)
+		wi.Error = err.Error()
+	} else {
+		log.Infof("workflow manager: workflow %v done", wi.Uuid)
+	}
+	if err := m.ts.SaveWorkflow(ctx, wi); err != nil {
+		log.Warningf("workflow manager: cannot save workflow %v as Done, it was probably stopped: %v", wi.Uuid, err)
+	}
+}
+
+// stopAll interrupts all the running workflows, and waits for them.
+func (m *Manager) stopAll() {
+	m.mu.Lock()
+	var dones []chan struct{}
+	for _, rw := range m.running {
+		rw.cancel()
+		dones = append(dones, rw.done)
+	}
+	m.mu.Unlock()
+	for _, done := range dones {
+		<-done
+	}
+}
+
+// RunWithElection runs the manager while this process is the leader
+// of the election for name, until ctx is done. id identifies this
+// process, typically its hostname:port.
+func (m *Manager) RunWithElection(ctx context.Context, name, id string) {
+	mp, err := m.ts.NewMasterParticipation(name, id)
+	if err != nil {
+		log.Errorf("workflow manager: cannot take part in the %v election, workflows won't run: %v", name, err)
+		return
+	}
+	go func() {
+		<-ctx.Done()
+		mp.Stop()
+	}()
+	for {
+		masterCtx, err := mp.WaitForMastership()
+		switch err {
+		case nil:
+			log.Infof("workflow manager: %v is the leader, running workflows", id)
+			m.Run(masterCtx)
+			log.Infof("workflow manager: %v is not the leader any more", id)
+		case topo.ErrInterrupted:
+			return
+		default:
+			log.Errorf("workflow manager: error while waiting for the leadership, will retry in 5s: %v", err)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(5 * time.Second):
+			}
+		}
+	}
+}
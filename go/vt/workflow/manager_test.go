@@ -0,0 +1,240 @@
+// Copyright 2016, Google Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package workflow
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/youtube/vitess/go/vt/topo"
+	"github.com/youtube/vitess/go/vt/topo/memorytopo"
+
+	workflowpb "github.com/youtube/vitess/go/vt/proto/workflow"
+)
+
+// counterWorkflow counts up to a target, checkpointing the counter
+// after each step.
+type counterWorkflow struct {
+	counter, target int
+}
+
+func (cw *counterWorkflow) Run(ctx context.Context, manager *Manager, wi *topo.WorkflowInfo) error {
+	for cw.counter < cw.target {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(10 * time.Millisecond):
+		}
+		cw.counter++
+		data, err := json.Marshal([]int{cw.counter, cw.target})
+		if err != nil {
+			return err
+		}
+		wi.Data = data
+		if err := manager.Checkpoint(ctx, wi); err != nil {
+			return err
+		}
+	}
+	if cw.target < 0 {
+		return fmt.Errorf("negative target")
+	}
+	return nil
+}
+
+type counterWorkflowFactory struct{}
+
+func (f *counterWorkflowFactory) Init(w *workflowpb.Workflow, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("counter workflow needs a target")
+	}
+	target, err := strconv.Atoi(args[0])
+	if err != nil {
+		return err
+	}
+	w.Name = "count to " + args[0]
+	w.Data, err = json.Marshal([]int{0, target})
+	return err
+}
+
+func (f *counterWorkflowFactory) Instantiate(w *workflowpb.Workflow) (Workflow, error) {
+	var data []int
+	if err := json.Unmarshal(w.Data, &data); err != nil {
+		return nil, err
+	}
+	return &counterWorkflow{counter: data[0], target: data[1]}, nil
+}
+
+func init() {
+	Register("counter", &counterWorkflowFactory{})
+}
+
+func newTestManager(t *testing.T) (*Manager, context.Context) {
+	*pollInterval = 10 * time.Millisecond
+	ts := topo.Server{Impl: memorytopo.NewMemoryTopo([]string{"global"})}
+	return NewManager(ts), context.Background()
+}
+
+// waitForCounter waits until the checkpointed counter of a workflow
+// reaches a value, and returns the record.
+func waitForCounter(t *testing.T, m *Manager, uuid string, counter int) *topo.WorkflowInfo {
+	for i := 0; i < 500; i++ {
+		wi, err := m.TopoServer().GetWorkflow(context.Background(), uuid)
+		if err != nil {
+			t.Fatalf("GetWorkflow: %v", err)
+		}
+		var data []int
+		if err := json.Unmarshal(wi.Data, &data); err != nil {
+			t.Fatalf("invalid data: %v", err)
+		}
+		if data[0] >= counter {
+			return wi
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("workflow %v didn't reach %v", uuid, counter)
+	return nil
+}
+
+func TestManagerRun(t *testing.T) {
+	m, ctx := newTestManager(t)
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go m.Run(runCtx)
+
+	uuid, err := m.Create(ctx, "counter", []string{"5"})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	wi, err := m.TopoServer().GetWorkflow(ctx, uuid)
+	if err != nil {
+		t.Fatalf("GetWorkflow: %v", err)
+	}
+	if wi.State != workflowpb.WorkflowState_NotStarted || wi.Name != "count to 5" || wi.FactoryName != "counter" {
+		t.Errorf("created workflow: %v", wi.Workflow)
+	}
+
+	if err := m.Start(ctx, uuid); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	if err := m.Wait(ctx, uuid); err != nil {
+		t.Fatalf("Wait: %v", err)
+	}
+	wi = waitForCounter(t, m, uuid, 5)
+	if wi.State != workflowpb.WorkflowState_Done || wi.StartTime == 0 || wi.EndTime == 0 || wi.Error != "" {
+		t.Errorf("finished workflow: %v", wi.Workflow)
+	}
+
+	// A workflow error is saved in the record.
+	uuid, err = m.Create(ctx, "counter", []string{"-1"})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if err := m.Start(ctx, uuid); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	want := "negative target"
+	if err := m.Wait(ctx, uuid); err == nil || !strings.Contains(err.Error(), want) {
+		t.Errorf("Wait: %v, want %v", err, want)
+	}
+
+	if _, err := m.Create(ctx, "unknown", nil); err == nil {
+		t.Errorf("Create with an unknown factory should fail")
+	}
+}
+
+func TestManagerStopStart(t *testing.T) {
+	m, ctx := newTestManager(t)
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go m.Run(runCtx)
+
+	uuid, err := m.Create(ctx, "counter", []string{"1000"})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if err := m.Start(ctx, uuid); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	waitForCounter(t, m, uuid, 2)
+	if err := m.Delete(ctx, uuid); err == nil {
+		t.Errorf("Delete of a Running workflow should fail")
+	}
+	if err := m.Stop(ctx, uuid); err != nil {
+		t.Fatalf("Stop: %v", err)
+	}
+
+	// The workflow stops checkpointing, and keeps its data.
+	time.Sleep(50 * time.Millisecond)
+	wi1 := waitForCounter(t, m, uuid, 2)
+	time.Sleep(50 * time.Millisecond)
+	wi2 := waitForCounter(t, m, uuid, 2)
+	if string(wi1.Data) != string(wi2.Data) || wi2.State != workflowpb.WorkflowState_NotStarted {
+		t.Errorf("stopped workflow is still running: %v then %v", wi1.Workflow, wi2.Workflow)
+	}
+
+	// It resumes from its checkpoint.
+	var data []int
+	json.Unmarshal(wi2.Data, &data)
+	if err := m.Start(ctx, uuid); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	waitForCounter(t, m, uuid, data[0]+2)
+	if err := m.Stop(ctx, uuid); err != nil {
+		t.Fatalf("Stop: %v", err)
+	}
+	if err := m.Delete(ctx, uuid); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	workflows, err := m.List(ctx)
+	if err != nil || len(workflows) != 0 {
+		t.Errorf("List: %v, %v, want no workflow", workflows, err)
+	}
+}
+
+func TestManagerRestart(t *testing.T) {
+	m, ctx := newTestManager(t)
+	runCtx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		m.Run(runCtx)
+		close(done)
+	}()
+
+	uuid, err := m.Create(ctx, "counter", []string{"10"})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if err := m.Start(ctx, uuid); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	waitForCounter(t, m, uuid, 2)
+
+	// The manager stops: the workflow stays Running.
+	cancel()
+	<-done
+	wi, err := m.TopoServer().GetWorkflow(ctx, uuid)
+	if err != nil {
+		t.Fatalf("GetWorkflow: %v", err)
+	}
+	if wi.State != workflowpb.WorkflowState_Running {
+		t.Errorf("interrupted workflow: %v, want Running", wi.Workflow)
+	}
+
+	// A new manager finishes it.
+	m2 := NewManager(m.TopoServer())
+	runCtx, cancel = context.WithCancel(ctx)
+	defer cancel()
+	go m2.Run(runCtx)
+	if err := m2.Wait(ctx, uuid); err != nil {
+		t.Fatalf("Wait: %v", err)
+	}
+	waitForCounter(t, m2, uuid, 10)
+}
@@ -0,0 +1,645 @@
+// Copyright 2016, Google Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package resharding contains the workflows that reshard a keyspace.
+package resharding
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+
+	log "github.com/golang/glog"
+	"golang.org/x/net/context"
+
+	"github.com/youtube/vitess/go/vt/automation"
+	"github.com/youtube/vitess/go/vt/topo"
+	"github.com/youtube/vitess/go/vt/topo/topoproto"
+	"github.com/youtube/vitess/go/vt/workflow"
+
+	topodatapb "github.com/youtube/vitess/go/vt/proto/topodata"
+	workflowpb "github.com/youtube/vitess/go/vt/proto/workflow"
+)
+
+// This file contains the horizontal resharding workflow. It runs the
+// steps of a resharding in order, through the vtctld and vtworker
+// RPCs, and checkpoints its progress after each step (and after each
+// shard for the long steps), so it resumes where it was stopped.
+//
+// The workflow waits for an approval before the steps listed in
+// PauseBefore (by default, the served type migrations), and before
+// running again a step that failed. The migrated served types can be
+// rolled back, even after the master migration if it set up the
+// reverse replication: the workflow then waits for new approvals
+// before migrating them again.
+
+const horizontalReshardingFactoryName = "horizontal_resharding"
+
+// The steps of the workflow.
+const (
+	stepCreateShards               = "create_shards"
+	stepCopySchema                 = "copy_schema"
+	stepClone                      = "clone"
+	stepWaitForFilteredReplication = "wait_for_filtered_replication"
+	stepDiff                       = "diff"
+	stepMigrateRdonly              = "migrate_rdonly"
+	stepMigrateReplica             = "migrate_replica"
+	stepMigrateMaster              = "migrate_master"
+)
+
+// steps lists the steps of the workflow, in order.
+var steps = []string{
+	stepCreateShards,
+	stepCopySchema,
+	stepClone,
+	stepWaitForFilteredReplication,
+	stepDiff,
+	stepMigrateRdonly,
+	stepMigrateReplica,
+	stepMigrateMaster,
+}
+
+// migrationSteps maps the served type migration steps to their
+// served type.
+var migrationSteps = map[string]string{
+	stepMigrateRdonly:  "rdonly",
+	stepMigrateReplica: "replica",
+	stepMigrateMaster:  "master",
+}
+
+// filteredReplicationMaxDelay is the maximum filtered replication
+// delay the workflow waits for before running the diffs.
+const filteredReplicationMaxDelay = "30s"
+
+// pollInterval is how often the workflow reads the topology while it
+// waits for an approval, or for the destination masters.
+var pollInterval = 5 * time.Second
+
+func init() {
+	workflow.Register(horizontalReshardingFactoryName, &HorizontalReshardingWorkflowFactory{})
+}
+
+// HorizontalReshardingData is the data of a horizontal resharding
+// workflow: its parameters, its progress, and the human decisions.
+type HorizontalReshardingData struct {
+	Keyspace                string
+	SourceShards            []string
+	DestinationShards       []string
+	VtctldEndpoint          string
+	VtworkerEndpoint        string
+	ExcludeTables           string
+	MinHealthyRdonlyTablets string
+	// ReverseReplication is set if the master migration sets up
+	// the reverse replication, to allow a rollback after it.
+	ReverseReplication bool
+
+	// PauseBefore lists the steps that wait for an approval.
+	PauseBefore []string
+
+	// Done has the completed steps, and the completed shards of
+	// the steps that run one command per shard, as <step>/<shard>.
+	Done map[string]bool
+	// Current describes what the workflow is doing.
+	Current string
+	// FailedStep is the last step that failed, and LastError its
+	// error. The step runs again once it is approved.
+	FailedStep string
+	LastError  string
+	// MigratedTypes lists the migrated served types, in order.
+	MigratedTypes []string
+
+	// Approved has the approved steps. It is set by Approve.
+	Approved map[string]bool
+	// RollbackRequests is incremented by Rollback, and
+	// RollbacksDone by the workflow once the served types are
+	// rolled back.
+	RollbackRequests int
+	RollbacksDone    int
+}
+
+// rollbackRequested returns true if the workflow has to roll back
+// the served types.
+func (hd *HorizontalReshardingData) rollbackRequested() bool {
+	return hd.RollbackRequests > hd.RollbacksDone
+}
+
+// parseData unmarshals the data of a horizontal resharding workflow.
+func parseData(data []byte) (*HorizontalReshardingData, error) {
+	hd := &HorizontalReshardingData{}
+	if err := json.Unmarshal(data, hd); err != nil {
+		return nil, fmt.Errorf("invalid horizontal resharding workflow data: %v", err)
+	}
+	if hd.Done == nil {
+		hd.Done = make(map[string]bool)
+	}
+	if hd.Approved == nil {
+		hd.Approved = make(map[string]bool)
+	}
+	return hd, nil
+}
+
+// HorizontalReshardingWorkflow runs a horizontal resharding.
+type HorizontalReshardingWorkflow struct {
+	data *HorizontalReshardingData
+
+	// ts and wi are set by Run.
+	ts topo.Server
+	wi *topo.WorkflowInfo
+}
+
+// Run is part of the workflow.Workflow interface.
+func (hw *HorizontalReshardingWorkflow) Run(ctx context.Context, manager *workflow.Manager, wi *topo.WorkflowInfo) error {
+	hw.ts = manager.TopoServer()
+	hw.wi = wi
+	for {
+		if hw.data.rollbackRequested() {
+			if err := hw.rollback(ctx); err != nil {
+				return err
+			}
+		}
+		step := hw.nextStep()
+		if step == "" {
+			break
+		}
+		if err := hw.waitForApproval(ctx, step); err != nil {
+			return err
+		}
+		if hw.data.rollbackRequested() {
+			continue
+		}
+
+		hw.data.Current = "running " + step
+		if err := hw.checkpoint(ctx); err != nil {
+			return err
+		}
+		if err := hw.runStep(ctx, step); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			// The step runs again once it is approved.
+			log.Errorf("step %v of workflow %v failed: %v", step, hw.wi.Uuid, err)
+			hw.data.FailedStep = step
+			hw.data.LastError = err.Error()
+			delete(hw.data.Approved, step)
+			if err := hw.checkpoint(ctx); err != nil {
+				return err
+			}
+			continue
+		}
+		hw.data.Done[step] = true
+		hw.data.FailedStep = ""
+		if err := hw.checkpoint(ctx); err != nil {
+			return err
+		}
+	}
+	hw.data.Current = "done"
+	return hw.checkpoint(ctx)
+}
+
+// nextStep returns the first step that is not done, or "" if they
+// all are.
+func (hw *HorizontalReshardingWorkflow) nextStep() string {
+	for _, step := range steps {
+		if !hw.data.Done[step] {
+			return step
+		}
+	}
+	return ""
+}
+
+// needsApproval returns true if a step has to wait for an approval.
+func (hw *HorizontalReshardingWorkflow) needsApproval(step string) bool {
+	if hw.data.Approved[step] {
+		return false
+	}
+	if step == hw.data.FailedStep {
+		return true
+	}
+	for _, s := range hw.data.PauseBefore {
+		if s == step {
+			return true
+		}
+	}
+	return false
+}
+
+// waitForApproval returns once the step is approved, or a rollback
+// is requested.
+func (hw *HorizontalReshardingWorkflow) waitForApproval(ctx context.Context, step string) error {
+	if !hw.needsApproval(step) {
+		return nil
+	}
+	hw.data.Current = "waiting for the approval of " + step
+	if err := hw.checkpoint(ctx); err != nil {
+		return err
+	}
+	for hw.needsApproval(step) && !hw.data.rollbackRequested() {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(pollInterval):
+		}
+		if err := hw.refresh(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkpoint saves the data of the workflow. It doesn't use
+// Manager.Checkpoint, as it has to tell apart the record changes
+// made by Approve and Rollback: it takes their changes, and saves
+// again.
+func (hw *HorizontalReshardingWorkflow) checkpoint(ctx context.Context) error {
+	for {
+		data, err := json.Marshal(hw.data)
+		if err != nil {
+			return err
+		}
+		hw.wi.Data = data
+		switch err := hw.ts.SaveWorkflow(ctx, hw.wi); err {
+		case nil:
+			return nil
+		case topo.ErrBadVersion:
+			if err := hw.refresh(ctx); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("cannot checkpoint workflow %v: %v", hw.wi.Uuid, err)
+		}
+	}
+}
+
+// refresh reads the record of the workflow, and takes the approvals
+// and the rollback request from it. If the workflow was stopped, it
+// waits for the manager to interrupt it.
+func (hw *HorizontalReshardingWorkflow) refresh(ctx context.Context) error {
+	wi, err := hw.ts.GetWorkflow(ctx, hw.wi.Uuid)
+	if err != nil {
+		return err
+	}
+	if wi.State != workflowpb.WorkflowState_Running {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	data, err := parseData(wi.Data)
+	if err != nil {
+		return err
+	}
+	for step, approved := range data.Approved {
+		if approved {
+			hw.data.Approved[step] = true
+		}
+	}
+	if data.RollbackRequests > hw.data.RollbackRequests {
+		hw.data.RollbackRequests = data.RollbackRequests
+	}
+	*hw.wi = *wi
+	return nil
+}
+
+// runStep runs one step of the workflow.
+func (hw *HorizontalReshardingWorkflow) runStep(ctx context.Context, step string) error {
+	switch step {
+	case stepCreateShards:
+		return hw.createShards(ctx)
+	case stepCopySchema:
+		source := topoproto.KeyspaceShardString(hw.data.Keyspace, hw.data.SourceShards[0])
+		return hw.forEachShard(ctx, step, hw.data.DestinationShards, func(shard string) error {
+			args := []string{"CopySchemaShard"}
+			if hw.data.ExcludeTables != "" {
+				args = append(args, "-exclude_tables="+hw.data.ExcludeTables)
+			}
+			args = append(args, source, topoproto.KeyspaceShardString(hw.data.Keyspace, shard))
+			return hw.vtctl(ctx, args)
+		})
+	case stepClone:
+		// One SplitClone copies the data of all the source shards
+		// to all the destination shards: it runs from the first
+		// source shard only.
+		return hw.forEachShard(ctx, step, hw.data.SourceShards[:1], func(shard string) error {
+			return hw.vtworker(ctx, hw.vtworkerArgs("SplitClone", shard))
+		})
+	case stepWaitForFilteredReplication:
+		for _, shard := range hw.data.DestinationShards {
+			if err := hw.vtctl(ctx, []string{"WaitForFilteredReplication", "-max_delay", filteredReplicationMaxDelay, topoproto.KeyspaceShardString(hw.data.Keyspace, shard)}); err != nil {
+				return err
+			}
+		}
+		return nil
+	case stepDiff:
+		// Each destination shard is compared to the union of its
+		// source shards.
+		return hw.forEachShard(ctx, step, hw.data.DestinationShards, func(shard string) error {
+			return hw.vtworker(ctx, hw.vtworkerArgs("SplitDiff", shard, "--source_uid=-1"))
+		})
+	case stepMigrateRdonly, stepMigrateReplica, stepMigrateMaster:
+		return hw.migrate(ctx, migrationSteps[step], false /* reverse */)
+	}
+	return fmt.Errorf("unknown step %v", step)
+}
+
+// forEachShard runs a step on each shard, and checkpoints after each
+// of them. The shards done before an interruption are skipped.
+func (hw *HorizontalReshardingWorkflow) forEachShard(ctx context.Context, step string, shards []string, f func(shard string) error) error {
+	for _, shard := range shards {
+		key := step + "/" + shard
+		if hw.data.Done[key] {
+			continue
+		}
+		hw.data.Current = fmt.Sprintf("running %v on %v", step, shard)
+		if err := hw.checkpoint(ctx); err != nil {
+			return err
+		}
+		if err := f(shard); err != nil {
+			return fmt.Errorf("shard %v: %v", shard, err)
+		}
+		hw.data.Done[key] = true
+		if err := hw.checkpoint(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// createShards creates the destination shards, and waits for their
+// masters: the clone needs them, and they are started by hand.
+func (hw *HorizontalReshardingWorkflow) createShards(ctx context.Context) error {
+	for _, shard := range hw.data.DestinationShards {
+		if err := hw.vtctl(ctx, []string{"CreateShard", "-force", topoproto.KeyspaceShardString(hw.data.Keyspace, shard)}); err != nil {
+			return err
+		}
+	}
+
+	hw.data.Current = "waiting for the masters of the destination shards"
+	if err := hw.checkpoint(ctx); err != nil {
+		return err
+	}
+	for _, shard := range hw.data.DestinationShards {
+		for {
+			si, err := hw.ts.GetShard(ctx, hw.data.Keyspace, shard)
+			if err != nil {
+				return err
+			}
+			if si.HasMaster() {
+				break
+			}
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(pollInterval):
+			}
+		}
+	}
+	return nil
+}
+
+// migrate migrates a served type from the source shards to the
+// destination shards, or back. A migration that was done before an
+// interruption is not run again.
+//
+// Once the master is migrated with reverse replication, the shards
+// swapped their roles: the served types are migrated back without
+// -reverse, in the usual order.
+func (hw *HorizontalReshardingWorkflow) migrate(ctx context.Context, servedType string, back bool) error {
+	tabletType, err := topoproto.ParseTabletType(servedType)
+	if err != nil {
+		return err
+	}
+	si, err := hw.ts.GetShard(ctx, hw.data.Keyspace, hw.data.SourceShards[0])
+	if err != nil {
+		return err
+	}
+	if (si.GetServedType(tabletType) != nil) != back {
+		args := []string{"MigrateServedTypes"}
+		if back && !hw.data.Done[stepMigrateMaster] {
+			args = append(args, "-reverse")
+		}
+		if tabletType == topodatapb.TabletType_MASTER && hw.data.ReverseReplication {
+			args = append(args, "-reverse_replication")
+		}
+		args = append(args, topoproto.KeyspaceShardString(hw.data.Keyspace, hw.data.SourceShards[0]), servedType)
+		if err := hw.vtctl(ctx, args); err != nil {
+			return err
+		}
+	}
+	if !back {
+		hw.data.MigratedTypes = append(hw.data.MigratedTypes, servedType)
+	}
+	return nil
+}
+
+// rollback migrates the served types back: the most recent first
+// before the master migration, in the usual order after it. The
+// migration steps then wait for new approvals.
+func (hw *HorizontalReshardingWorkflow) rollback(ctx context.Context) error {
+	masterMigrated := hw.data.Done[stepMigrateMaster]
+	if masterMigrated && !hw.data.ReverseReplication {
+		// Rollback refuses this case, it was requested too late.
+		hw.data.RollbacksDone = hw.data.RollbackRequests
+		hw.data.LastError = "the master was migrated without reverse replication, the served types can't be rolled back"
+		return hw.checkpoint(ctx)
+	}
+	for len(hw.data.MigratedTypes) > 0 {
+		i := len(hw.data.MigratedTypes) - 1
+		if masterMigrated {
+			i = 0
+		}
+		servedType := hw.data.MigratedTypes[i]
+		hw.data.Current = "rolling back the migration of " + servedType
+		if err := hw.checkpoint(ctx); err != nil {
+			return err
+		}
+		if err := hw.migrate(ctx, servedType, true /* back */); err != nil {
+			return fmt.Errorf("cannot roll back the migration of %v: %v", servedType, err)
+		}
+		hw.data.MigratedTypes = append(hw.data.MigratedTypes[:i], hw.data.MigratedTypes[i+1:]...)
+		delete(hw.data.Done, "migrate_"+servedType)
+		if err := hw.checkpoint(ctx); err != nil {
+			return err
+		}
+	}
+
+	for _, step := range steps {
+		if _, ok := migrationSteps[step]; !ok {
+			continue
+		}
+		delete(hw.data.Approved, step)
+		hw.data.PauseBefore = appendIfMissing(hw.data.PauseBefore, step)
+	}
+	hw.data.FailedStep = ""
+	hw.data.RollbacksDone = hw.data.RollbackRequests
+	return hw.checkpoint(ctx)
+}
+
+// vtctl runs a vtctl command through vtctld.
+func (hw *HorizontalReshardingWorkflow) vtctl(ctx context.Context, args []string) error {
+	_, err := automation.ExecuteVtctl(ctx, hw.data.VtctldEndpoint, args)
+	return err
+}
+
+// vtworker runs a vtworker command.
+func (hw *HorizontalReshardingWorkflow) vtworker(ctx context.Context, args []string) error {
+	// Run a "Reset" first to clear the state of a previous finished
+	// command. This reset is best effort.
+	automation.ExecuteVtworker(ctx, hw.data.VtworkerEndpoint, []string{"Reset"})
+
+	_, err := automation.ExecuteVtworker(ctx, hw.data.VtworkerEndpoint, args)
+	return err
+}
+
+// vtworkerArgs returns the arguments of the SplitClone and SplitDiff
+// commands, with the flags specific to the command.
+func (hw *HorizontalReshardingWorkflow) vtworkerArgs(command, shard string, flags ...string) []string {
+	args := append([]string{command}, flags...)
+	if hw.data.ExcludeTables != "" {
+		args = append(args, "--exclude_tables="+hw.data.ExcludeTables)
+	}
+	if hw.data.MinHealthyRdonlyTablets != "" {
+		args = append(args, "--min_healthy_rdonly_tablets="+hw.data.MinHealthyRdonlyTablets)
+	}
+	return append(args, topoproto.KeyspaceShardString(hw.data.Keyspace, shard))
+}
+
+func appendIfMissing(list []string, value string) []string {
+	for _, v := range list {
+		if v == value {
+			return list
+		}
+	}
+	return append(list, value)
+}
+
+// HorizontalReshardingWorkflowFactory creates horizontal resharding
+// workflows.
+type HorizontalReshardingWorkflowFactory struct{}
+
+// Init is part of the workflow.Factory interface.
+func (f *HorizontalReshardingWorkflowFactory) Init(w *workflowpb.Workflow, args []string) error {
+	subFlags := flag.NewFlagSet(horizontalReshardingFactoryName, flag.ContinueOnError)
+	keyspace := subFlags.String("keyspace", "", "Name of the keyspace to reshard")
+	sourceShards := subFlags.String("source_shards", "", "Comma-separated list of the shards to split")
+	destinationShards := subFlags.String("destination_shards", "", "Comma-separated list of the new shards")
+	vtctldEndpoint := subFlags.String("vtctld_endpoint", "", "Endpoint of the vtctld that runs the vtctl commands")
+	vtworkerEndpoint := subFlags.String("vtworker_endpoint", "", "Endpoint of the vtworker that runs the clone and the diffs")
+	excludeTables := subFlags.String("exclude_tables", "", "Comma-separated list of the tables not to copy")
+	minHealthyRdonlyTablets := subFlags.String("min_healthy_rdonly_tablets", "", "Minimum number of healthy rdonly tablets the vtworker needs in the source and destination shards")
+	reverseReplication := subFlags.Bool("reverse_replication", true, "If set, the master migration sets up the reverse replication, so the served types can still be rolled back after it")
+	pauseBefore := subFlags.String("pause_before", strings.Join([]string{stepMigrateRdonly, stepMigrateReplica, stepMigrateMaster}, ","), "Comma-separated list of the steps that wait for an approval, in: "+strings.Join(steps, ", "))
+	if err := subFlags.Parse(args); err != nil {
+		return err
+	}
+	if *keyspace == "" || *sourceShards == "" || *destinationShards == "" || *vtctldEndpoint == "" || *vtworkerEndpoint == "" {
+		return fmt.Errorf("keyspace, source_shards, destination_shards, vtctld_endpoint and vtworker_endpoint are required")
+	}
+	hd := &HorizontalReshardingData{
+		Keyspace:                *keyspace,
+		SourceShards:            strings.Split(*sourceShards, ","),
+		DestinationShards:       strings.Split(*destinationShards, ","),
+		VtctldEndpoint:          *vtctldEndpoint,
+		VtworkerEndpoint:        *vtworkerEndpoint,
+		ExcludeTables:           *excludeTables,
+		MinHealthyRdonlyTablets: *minHealthyRdonlyTablets,
+		ReverseReplication:      *reverseReplication,
+		Done:                    make(map[string]bool),
+		Approved:                make(map[string]bool),
+	}
+	if *pauseBefore != "" {
+		for _, step := range strings.Split(*pauseBefore, ",") {
+			if !isStep(step) {
+				return fmt.Errorf("unknown step %v in pause_before", step)
+			}
+			hd.PauseBefore = append(hd.PauseBefore, step)
+		}
+	}
+
+	w.Name = fmt.Sprintf("Horizontal resharding of %v from %v to %v", *keyspace, *sourceShards, *destinationShards)
+	data, err := json.Marshal(hd)
+	if err != nil {
+		return err
+	}
+	w.Data = data
+	return nil
+}
+
+// Instantiate is part of the workflow.Factory interface.
+func (f *HorizontalReshardingWorkflowFactory) Instantiate(w *workflowpb.Workflow) (workflow.Workflow, error) {
+	hd, err := parseData(w.Data)
+	if err != nil {
+		return nil, err
+	}
+	return &HorizontalReshardingWorkflow{data: hd}, nil
+}
+
+func isStep(step string) bool {
+	for _, s := range steps {
+		if s == step {
+			return true
+		}
+	}
+	return false
+}
+
+// Approve approves a step of a horizontal resharding workflow. The
+// workflow runs it once it gets there, or right away if it is
+// waiting for it.
+func Approve(ctx context.Context, ts topo.Server, uuid, step string) error {
+	if !isStep(step) {
+		return fmt.Errorf("unknown step %v, it must be one of: %v", step, strings.Join(steps, ", "))
+	}
+	return update(ctx, ts, uuid, func(hd *HorizontalReshardingData) error {
+		if hd.Done[step] {
+			return fmt.Errorf("step %v is already done", step)
+		}
+		hd.Approved[step] = true
+		return nil
+	})
+}
+
+// Rollback asks a horizontal resharding workflow to migrate the served
+// types back to the source shards. Once the master is migrated, it
+// needs the reverse replication.
+func Rollback(ctx context.Context, ts topo.Server, uuid string) error {
+	return update(ctx, ts, uuid, func(hd *HorizontalReshardingData) error {
+		if hd.Done[stepMigrateMaster] && !hd.ReverseReplication {
+			return fmt.Errorf("the master was migrated without reverse replication, the served types can't be rolled back")
+		}
+		hd.RollbackRequests++
+		return nil
+	})
+}
+
+// update changes the data of a horizontal resharding workflow record.
+// The running workflow takes the change at its next checkpoint.
+func update(ctx context.Context, ts topo.Server, uuid string, f func(*HorizontalReshardingData) error) error {
+	for {
+		wi, err := ts.GetWorkflow(ctx, uuid)
+		if err != nil {
+			return err
+		}
+		if wi.FactoryName != horizontalReshardingFactoryName {
+			return fmt.Errorf("workflow %v is a %v workflow, not a %v workflow", uuid, wi.FactoryName, horizontalReshardingFactoryName)
+		}
+		hd, err := parseData(wi.Data)
+		if err != nil {
+			return err
+		}
+		if err := f(hd); err != nil {
+			return err
+		}
+		if wi.Data, err = json.Marshal(hd); err != nil {
+			return err
+		}
+		switch err := ts.SaveWorkflow(ctx, wi); err {
+		case nil:
+			return nil
+		case topo.ErrBadVersion:
+			continue
+		default:
+			return err
+		}
+	}
+}
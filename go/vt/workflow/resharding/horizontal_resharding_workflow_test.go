@@ -0,0 +1,335 @@
+// Copyright 2016, Google Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package resharding
+
+import (
+	"flag"
+	"reflect"
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/youtube/vitess/go/vt/topo"
+	"github.com/youtube/vitess/go/vt/vtctl/fakevtctlclient"
+	"github.com/youtube/vitess/go/vt/vtctl/vtctlclient"
+	"github.com/youtube/vitess/go/vt/worker/fakevtworkerclient"
+	"github.com/youtube/vitess/go/vt/worker/vtworkerclient"
+	"github.com/youtube/vitess/go/vt/workflow"
+	"github.com/youtube/vitess/go/vt/zktopo/zktestserver"
+
+	topodatapb "github.com/youtube/vitess/go/vt/proto/topodata"
+	workflowpb "github.com/youtube/vitess/go/vt/proto/workflow"
+)
+
+// setUpTopo creates a keyspace with the source shard 0, and the
+// destination shards with their masters, as if their tablets were
+// started.
+func setUpTopo(t *testing.T) topo.Server {
+	ctx := context.Background()
+	ts := zktestserver.New(t, []string{"cell1"})
+	if err := ts.CreateKeyspace(ctx, "ks", &topodatapb.Keyspace{}); err != nil {
+		t.Fatalf("CreateKeyspace: %v", err)
+	}
+	for _, shard := range []string{"0", "-80", "80-"} {
+		if err := ts.CreateShard(ctx, "ks", shard); err != nil {
+			t.Fatalf("CreateShard: %v", err)
+		}
+	}
+	for i, shard := range []string{"-80", "80-"} {
+		if _, err := ts.UpdateShardFields(ctx, "ks", shard, func(si *topo.ShardInfo) error {
+			si.MasterAlias = &topodatapb.TabletAlias{Cell: "cell1", Uid: uint32(100 + i)}
+			return nil
+		}); err != nil {
+			t.Fatalf("UpdateShardFields: %v", err)
+		}
+	}
+	return ts
+}
+
+// waitForCurrent waits until a workflow reports what it is doing.
+func waitForCurrent(t *testing.T, ts topo.Server, uuid, current string) *HorizontalReshardingData {
+	for i := 0; i < 500; i++ {
+		wi, err := ts.GetWorkflow(context.Background(), uuid)
+		if err != nil {
+			t.Fatalf("GetWorkflow: %v", err)
+		}
+		hd, err := parseData(wi.Data)
+		if err != nil {
+			t.Fatalf("parseData: %v", err)
+		}
+		if hd.Current == current {
+			return hd
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("workflow %v didn't get to %q", uuid, current)
+	return nil
+}
+
+func TestHorizontalReshardingWorkflow(t *testing.T) {
+	ctx := context.Background()
+	pollInterval = 10 * time.Millisecond
+	flag.Set("workflow_manager_poll_interval", "10ms")
+
+	vtctlFake := fakevtctlclient.NewFakeVtctlClient()
+	vtctlclient.RegisterFactory("fake", vtctlFake.FakeVtctlClientFactory)
+	defer vtctlclient.UnregisterFactoryForTest("fake")
+	flag.Set("vtctl_client_protocol", "fake")
+	vtworkerFake := fakevtworkerclient.NewFakeVtworkerClient()
+	vtworkerclient.RegisterFactory("fake", vtworkerFake.FakeVtworkerClientFactory)
+	defer vtworkerclient.UnregisterFactoryForTest("fake")
+	flag.Set("vtworker_client_protocol", "fake")
+
+	for _, args := range [][]string{
+		{"CreateShard", "-force", "ks/-80"},
+		{"CreateShard", "-force", "ks/80-"},
+		{"CopySchemaShard", "ks/0", "ks/-80"},
+		{"CopySchemaShard", "ks/0", "ks/80-"},
+		{"WaitForFilteredReplication", "-max_delay", "30s", "ks/-80"},
+		{"WaitForFilteredReplication", "-max_delay", "30s", "ks/80-"},
+		{"MigrateServedTypes", "ks/0", "rdonly"},
+		{"MigrateServedTypes", "-reverse", "ks/0", "rdonly"},
+	} {
+		vtctlFake.RegisterResult(args, "", nil)
+	}
+	for _, args := range [][]string{
+		{"Reset"},
+		{"SplitClone", "--min_healthy_rdonly_tablets=1", "ks/0"},
+		{"Reset"},
+		{"SplitDiff", "--source_uid=-1", "--min_healthy_rdonly_tablets=1", "ks/-80"},
+		{"Reset"},
+		{"SplitDiff", "--source_uid=-1", "--min_healthy_rdonly_tablets=1", "ks/80-"},
+	} {
+		vtworkerFake.RegisterResult(args, "", nil)
+	}
+
+	ts := setUpTopo(t)
+	m := workflow.NewManager(ts)
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go m.Run(runCtx)
+
+	uuid, err := m.Create(ctx, horizontalReshardingFactoryName, []string{"-keyspace=ks", "-source_shards=0", "-destination_shards=-80,80-", "-vtctld_endpoint=localhost:15000", "-vtworker_endpoint=localhost:15001", "-min_healthy_rdonly_tablets=1"})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if err := m.Start(ctx, uuid); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+
+	// The workflow runs up to the first migration.
+	hd := waitForCurrent(t, ts, uuid, "waiting for the approval of migrate_rdonly")
+	for _, step := range []string{stepCreateShards, stepCopySchema, stepClone, stepWaitForFilteredReplication, stepDiff} {
+		if !hd.Done[step] {
+			t.Errorf("step %v is not done: %v", step, hd.Done)
+		}
+	}
+	if err := Approve(ctx, ts, uuid, "unknown"); err == nil {
+		t.Errorf("Approve of an unknown step should fail")
+	}
+	if err := Approve(ctx, ts, uuid, stepDiff); err == nil {
+		t.Errorf("Approve of a done step should fail")
+	}
+	if err := Approve(ctx, ts, uuid, stepMigrateRdonly); err != nil {
+		t.Fatalf("Approve: %v", err)
+	}
+	hd = waitForCurrent(t, ts, uuid, "waiting for the approval of migrate_replica")
+	if want := []string{"rdonly"}; !reflect.DeepEqual(hd.MigratedTypes, want) {
+		t.Errorf("MigratedTypes: %v, want %v", hd.MigratedTypes, want)
+	}
+
+	// The rollback migrates rdonly back (the fake vtctld doesn't
+	// change the topology, so this test does), and waits for a new
+	// approval.
+	if _, err := ts.UpdateShardFields(ctx, "ks", "0", func(si *topo.ShardInfo) error {
+		return si.UpdateServedTypesMap(topodatapb.TabletType_RDONLY, nil, true /* remove */)
+	}); err != nil {
+		t.Fatalf("UpdateShardFields: %v", err)
+	}
+	if err := Rollback(ctx, ts, uuid); err != nil {
+		t.Fatalf("Rollback: %v", err)
+	}
+	hd = waitForCurrent(t, ts, uuid, "waiting for the approval of migrate_rdonly")
+	if len(hd.MigratedTypes) != 0 || hd.Done[stepMigrateRdonly] || hd.Approved[stepMigrateRdonly] {
+		t.Errorf("rolled back workflow: %+v", hd)
+	}
+
+	if commands := vtctlFake.RegisteredCommands(); len(commands) != 0 {
+		t.Errorf("vtctl commands were not run: %v", commands)
+	}
+	if commands := vtworkerFake.RegisteredCommands(); len(commands) != 0 {
+		t.Errorf("vtworker commands were not run: %v", commands)
+	}
+	if err := m.Stop(ctx, uuid); err != nil {
+		t.Fatalf("Stop: %v", err)
+	}
+}
+
+func TestHorizontalReshardingWorkflowTwoSources(t *testing.T) {
+	ctx := context.Background()
+	pollInterval = 10 * time.Millisecond
+	flag.Set("workflow_manager_poll_interval", "10ms")
+
+	vtctlFake := fakevtctlclient.NewFakeVtctlClient()
+	vtctlclient.RegisterFactory("fake", vtctlFake.FakeVtctlClientFactory)
+	defer vtctlclient.UnregisterFactoryForTest("fake")
+	flag.Set("vtctl_client_protocol", "fake")
+	vtworkerFake := fakevtworkerclient.NewFakeVtworkerClient()
+	vtworkerclient.RegisterFactory("fake", vtworkerFake.FakeVtworkerClientFactory)
+	defer vtworkerclient.UnregisterFactoryForTest("fake")
+	flag.Set("vtworker_client_protocol", "fake")
+
+	// The shards -80 and 80- are merged into 0: the clone runs
+	// once, and the diff compares 0 to both sources.
+	for _, args := range [][]string{
+		{"CreateShard", "-force", "ks/0"},
+		{"CopySchemaShard", "ks/-80", "ks/0"},
+		{"WaitForFilteredReplication", "-max_delay", "30s", "ks/0"},
+	} {
+		vtctlFake.RegisterResult(args, "", nil)
+	}
+	for _, args := range [][]string{
+		{"Reset"},
+		{"SplitClone", "ks/-80"},
+		{"Reset"},
+		{"SplitDiff", "--source_uid=-1", "ks/0"},
+	} {
+		vtworkerFake.RegisterResult(args, "", nil)
+	}
+
+	ts := setUpTopo(t)
+	if _, err := ts.UpdateShardFields(ctx, "ks", "0", func(si *topo.ShardInfo) error {
+		si.MasterAlias = &topodatapb.TabletAlias{Cell: "cell1", Uid: 102}
+		return nil
+	}); err != nil {
+		t.Fatalf("UpdateShardFields: %v", err)
+	}
+	m := workflow.NewManager(ts)
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go m.Run(runCtx)
+
+	uuid, err := m.Create(ctx, horizontalReshardingFactoryName, []string{"-keyspace=ks", "-source_shards=-80,80-", "-destination_shards=0", "-vtctld_endpoint=localhost:15000", "-vtworker_endpoint=localhost:15001"})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if err := m.Start(ctx, uuid); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+
+	hd := waitForCurrent(t, ts, uuid, "waiting for the approval of migrate_rdonly")
+	for _, step := range []string{stepCreateShards, stepCopySchema, stepClone, stepWaitForFilteredReplication, stepDiff} {
+		if !hd.Done[step] {
+			t.Errorf("step %v is not done: %v", step, hd.Done)
+		}
+	}
+	if hd.FailedStep != "" {
+		t.Errorf("step %v failed: %v", hd.FailedStep, hd.LastError)
+	}
+	if commands := vtctlFake.RegisteredCommands(); len(commands) != 0 {
+		t.Errorf("vtctl commands were not run: %v", commands)
+	}
+	if commands := vtworkerFake.RegisteredCommands(); len(commands) != 0 {
+		t.Errorf("vtworker commands were not run: %v", commands)
+	}
+	if err := m.Stop(ctx, uuid); err != nil {
+		t.Fatalf("Stop: %v", err)
+	}
+}
+
+func TestHorizontalReshardingWorkflowRollbackAfterMaster(t *testing.T) {
+	ctx := context.Background()
+	vtctlFake := fakevtctlclient.NewFakeVtctlClient()
+	vtctlclient.RegisterFactory("fake", vtctlFake.FakeVtctlClientFactory)
+	defer vtctlclient.UnregisterFactoryForTest("fake")
+	flag.Set("vtctl_client_protocol", "fake")
+
+	// The master was migrated with reverse replication: the
+	// served types are migrated back in the usual order, without
+	// -reverse.
+	for _, args := range [][]string{
+		{"MigrateServedTypes", "ks/0", "rdonly"},
+		{"MigrateServedTypes", "ks/0", "replica"},
+		{"MigrateServedTypes", "-reverse_replication", "ks/0", "master"},
+	} {
+		vtctlFake.RegisterResult(args, "", nil)
+	}
+
+	ts := setUpTopo(t)
+	if _, err := ts.UpdateShardFields(ctx, "ks", "0", func(si *topo.ShardInfo) error {
+		for _, tabletType := range []topodatapb.TabletType{topodatapb.TabletType_RDONLY, topodatapb.TabletType_REPLICA, topodatapb.TabletType_MASTER} {
+			if err := si.UpdateServedTypesMap(tabletType, nil, true /* remove */); err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		t.Fatalf("UpdateShardFields: %v", err)
+	}
+
+	w := &workflowpb.Workflow{}
+	if err := (&HorizontalReshardingWorkflowFactory{}).Init(w, []string{"-keyspace=ks", "-source_shards=0", "-destination_shards=-80,80-", "-vtctld_endpoint=localhost:15000", "-vtworker_endpoint=localhost:15001"}); err != nil {
+		t.Fatalf("Init: %v", err)
+	}
+	w.Uuid = "uuid"
+	w.FactoryName = horizontalReshardingFactoryName
+	w.State = workflowpb.WorkflowState_Running
+	wi, err := ts.CreateWorkflow(ctx, w)
+	if err != nil {
+		t.Fatalf("CreateWorkflow: %v", err)
+	}
+	if err := Rollback(ctx, ts, "uuid"); err != nil {
+		t.Fatalf("Rollback: %v", err)
+	}
+	hw, err := (&HorizontalReshardingWorkflowFactory{}).Instantiate(w)
+	if err != nil {
+		t.Fatalf("Instantiate: %v", err)
+	}
+	hd := hw.(*HorizontalReshardingWorkflow).data
+	for _, step := range steps {
+		hd.Done[step] = true
+	}
+	hd.MigratedTypes = []string{"rdonly", "replica", "master"}
+	hw.(*HorizontalReshardingWorkflow).ts = ts
+	hw.(*HorizontalReshardingWorkflow).wi = wi
+
+	// The checkpoint picks up the rollback request.
+	if err := hw.(*HorizontalReshardingWorkflow).checkpoint(ctx); err != nil {
+		t.Fatalf("checkpoint: %v", err)
+	}
+	if !hd.rollbackRequested() {
+		t.Fatalf("the rollback request was not picked up: %+v", hd)
+	}
+	if err := hw.(*HorizontalReshardingWorkflow).rollback(ctx); err != nil {
+		t.Fatalf("rollback: %v", err)
+	}
+	if len(hd.MigratedTypes) != 0 || hd.Done[stepMigrateMaster] || !hd.Done[stepDiff] || hd.rollbackRequested() {
+		t.Errorf("rolled back workflow: %+v", hd)
+	}
+	if commands := vtctlFake.RegisteredCommands(); len(commands) != 0 {
+		t.Errorf("vtctl commands were not run: %v", commands)
+	}
+}
+
+func TestHorizontalReshardingWorkflowInit(t *testing.T) {
+	ts := setUpTopo(t)
+	m := workflow.NewManager(ts)
+	ctx := context.Background()
+
+	if _, err := m.Create(ctx, horizontalReshardingFactoryName, []string{"-keyspace=ks"}); err == nil {
+		t.Errorf("Create without the shards should fail")
+	}
+	if _, err := m.Create(ctx, horizontalReshardingFactoryName, []string{"-keyspace=ks", "-source_shards=0", "-destination_shards=-80,80-", "-vtctld_endpoint=localhost:15000", "-vtworker_endpoint=localhost:15001", "-pause_before=unknown"}); err == nil {
+		t.Errorf("Create with an unknown step to pause before should fail")
+	}
+	uuid, err := m.Create(ctx, "sleep", nil)
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if err := Rollback(ctx, ts, uuid); err == nil {
+		t.Errorf("Rollback of a sleep workflow should fail")
+	}
+}
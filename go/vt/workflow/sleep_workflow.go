@@ -0,0 +1,94 @@
+// Copyright 2016, Google Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package workflow
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/youtube/vitess/go/vt/topo"
+
+	workflowpb "github.com/youtube/vitess/go/vt/proto/workflow"
+)
+
+// This file contains a workflow that only sleeps. It is meant to
+// test the Workflow Manager, and to show how workflows checkpoint
+// their progress.
+
+const sleepFactoryName = "sleep"
+
+func init() {
+	Register(sleepFactoryName, &SleepWorkflowFactory{})
+}
+
+// SleepWorkflowData is the data of a sleep workflow.
+type SleepWorkflowData struct {
+	// Duration is how long the workflow sleeps, in seconds.
+	Duration int
+	// Slept is how long the workflow already slept, in seconds.
+	// It is checkpointed every second.
+	Slept int
+}
+
+// SleepWorkflow sleeps for the duration in its data.
+type SleepWorkflow struct {
+	data SleepWorkflowData
+}
+
+// Run is part of the Workflow interface.
+func (sw *SleepWorkflow) Run(ctx context.Context, manager *Manager, wi *topo.WorkflowInfo) error {
+	for sw.data.Slept < sw.data.Duration {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Second):
+		}
+		sw.data.Slept++
+		data, err := json.Marshal(&sw.data)
+		if err != nil {
+			return err
+		}
+		wi.Data = data
+		if err := manager.Checkpoint(ctx, wi); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SleepWorkflowFactory creates sleep workflows.
+type SleepWorkflowFactory struct{}
+
+// Init is part of the Factory interface.
+func (f *SleepWorkflowFactory) Init(w *workflowpb.Workflow, args []string) error {
+	subFlags := flag.NewFlagSet(sleepFactoryName, flag.ContinueOnError)
+	duration := subFlags.Int("duration", 30, "How long to sleep, in seconds")
+	if err := subFlags.Parse(args); err != nil {
+		return err
+	}
+	if *duration <= 0 {
+		return fmt.Errorf("duration must be positive")
+	}
+	w.Name = fmt.Sprintf("Sleep for %v seconds", *duration)
+	data, err := json.Marshal(&SleepWorkflowData{Duration: *duration})
+	if err != nil {
+		return err
+	}
+	w.Data = data
+	return nil
+}
+
+// Instantiate is part of the Factory interface.
+func (f *SleepWorkflowFactory) Instantiate(w *workflowpb.Workflow) (Workflow, error) {
+	sw := &SleepWorkflow{}
+	if err := json.Unmarshal(w.Data, &sw.data); err != nil {
+		return nil, fmt.Errorf("invalid sleep workflow data: %v", err)
+	}
+	return sw, nil
+}
@@ -12,8 +12,11 @@ import (
 	"time"
 
 	"github.com/youtube/vitess/go/event"
+	"github.com/youtube/vitess/go/vt/binlog/binlogplayer"
 	"github.com/youtube/vitess/go/vt/concurrency"
 	"github.com/youtube/vitess/go/vt/discovery"
+	"github.com/youtube/vitess/go/vt/key"
+	"github.com/youtube/vitess/go/vt/throttler"
 	"github.com/youtube/vitess/go/vt/topo"
 	"github.com/youtube/vitess/go/vt/topo/topoproto"
 	"github.com/youtube/vitess/go/vt/topotools"
@@ -71,18 +74,27 @@ func (wr *Wrangler) SetKeyspaceShardingInfo(ctx context.Context, keyspace, shard
 
 // MigrateServedTypes is used during horizontal splits to migrate a
 // served type from a list of shards to another.
-func (wr *Wrangler) MigrateServedTypes(ctx context.Context, keyspace, shard string, cells []string, servedType topodatapb.TabletType, reverse, skipReFreshState bool, filteredReplicationWaitTime time.Duration) (err error) {
+//
+// If reverseReplication is set for a master migration, the source
+// shards replicate from the destination shards once the master is
+// migrated. The shards then swap their roles: migrating the served
+// types again, in the same order, from the destination shards to the
+// source shards, is a lossless rollback.
+func (wr *Wrangler) MigrateServedTypes(ctx context.Context, keyspace, shard string, cells []string, servedType topodatapb.TabletType, reverse, skipReFreshState, reverseReplication bool, filteredReplicationWaitTime time.Duration) (err error) {
 	// check input parameters
 	if servedType == topodatapb.TabletType_MASTER {
 		// we cannot migrate a master back, since when master migration
-		// is done, the source shards are dead
+		// is done, the source shards are dead, or they replicate
+		// from the destination shards, which are now the sources.
 		if reverse {
-			return fmt.Errorf("Cannot migrate master back to %v/%v", keyspace, shard)
+			return fmt.Errorf("Cannot migrate master back to %v/%v. If the master was migrated with reverse replication, migrate the served types again without reverse", keyspace, shard)
 		}
 		// we cannot skip refresh state for a master
 		if skipReFreshState {
 			return fmt.Errorf("Cannot skip refresh state for master migration on %v/%v", keyspace, shard)
 		}
+	} else if reverseReplication {
+		return fmt.Errorf("Reverse replication can only be set up for a master migration on %v/%v", keyspace, shard)
 	}
 
 	// lock the keyspace
@@ -129,6 +141,14 @@ func (wr *Wrangler) MigrateServedTypes(ctx context.Context, keyspace, shard stri
 		}
 	}
 
+	// Verify that each source is replicated into the destinations.
+	// When merging shards, a destination has several SourceShards.
+	if !reverse {
+		if err := checkSourceShardsReplicated(sourceShards, destinationShards); err != nil {
+			return err
+		}
+	}
+
 	// Verify the destinations do not have the type we're
 	// migrating (or do if reverse)
 	for _, si := range destinationShards {
@@ -138,7 +158,7 @@ func (wr *Wrangler) MigrateServedTypes(ctx context.Context, keyspace, shard stri
 	}
 
 	// execute the migration
-	if err = wr.migrateServedTypesLocked(ctx, keyspace, sourceShards, destinationShards, cells, servedType, reverse, filteredReplicationWaitTime); err != nil {
+	if err = wr.migrateServedTypesLocked(ctx, keyspace, sourceShards, destinationShards, cells, servedType, reverse, reverseReplication, filteredReplicationWaitTime); err != nil {
 		return err
 	}
 
@@ -186,6 +206,26 @@ func (wr *Wrangler) MigrateServedTypes(ctx context.Context, keyspace, shard stri
 	return nil
 }
 
+// checkSourceShardsReplicated returns an error if a source shard is not
+// in the SourceShards of any destination shard. Its data would not be
+// served after the migration.
+func checkSourceShardsReplicated(sourceShards, destinationShards []*topo.ShardInfo) error {
+	for _, source := range sourceShards {
+		found := false
+		for _, destination := range destinationShards {
+			for _, ss := range destination.SourceShards {
+				if ss.Keyspace == source.Keyspace() && ss.Shard == source.ShardName() {
+					found = true
+				}
+			}
+		}
+		if !found {
+			return fmt.Errorf("source shard %v/%v is not in the SourceShards of any destination shard. Did you run vtworker SplitClone with all source shards?", source.Keyspace(), source.ShardName())
+		}
+	}
+	return nil
+}
+
 func (wr *Wrangler) getMastersPosition(ctx context.Context, shards []*topo.ShardInfo) (map[*topo.ShardInfo]string, error) {
 	mu := sync.Mutex{}
 	result := make(map[*topo.ShardInfo]string)
@@ -238,6 +278,10 @@ func (wr *Wrangler) waitForFilteredReplication(ctx context.Context, sourcePositi
 						blpPosition.Position = pos
 					}
 				}
+				if blpPosition.Position == "" {
+					rec.RecordError(fmt.Errorf("%v/%v replicates from %v/%v which is not one of the source shards", si.Keyspace(), si.ShardName(), sourceShard.Keyspace, sourceShard.Shard))
+					return
+				}
 
 				// and wait for it
 				wr.Logger().Infof("Waiting for %v to catch up", topoproto.TabletAliasString(si.MasterAlias))
@@ -259,6 +303,75 @@ func (wr *Wrangler) waitForFilteredReplication(ctx context.Context, sourcePositi
 	return rec.Error()
 }
 
+// setUpReverseReplication prepares the source masters to replicate
+// from the destination masters, which must not take writes yet. It
+// populates the blp_checkpoint table of the source masters with the
+// current positions of the destination masters, and returns the
+// SourceShards each source shard needs to start its binlog players,
+// by source shard name.
+func (wr *Wrangler) setUpReverseReplication(ctx context.Context, sourceShards, destinationShards []*topo.ShardInfo) (map[string][]*topodatapb.Shard_SourceShard, error) {
+	destinationPositions, err := wr.getMastersPosition(ctx, destinationShards)
+	if err != nil {
+		return nil, err
+	}
+
+	// A source shard replicates from the destination shards which
+	// overlap it, for the overlap of their key ranges. We use a
+	// linear 0-based id, like SetSourceShards. The source masters
+	// may have rows for these ids from a previous migration in the
+	// other direction.
+	sourceShardLists := make(map[string][]*topodatapb.Shard_SourceShard)
+	sourceQueries := make(map[string][]string)
+	for _, si := range sourceShards {
+		var sourceShardList []*topodatapb.Shard_SourceShard
+		queries := binlogplayer.CreateBlpCheckpoint()
+		for _, dsi := range destinationShards {
+			if !key.KeyRangesIntersect(si.KeyRange, dsi.KeyRange) {
+				continue
+			}
+			keyRange, err := key.KeyRangesOverlap(si.KeyRange, dsi.KeyRange)
+			if err != nil {
+				return nil, err
+			}
+			uid := uint32(len(sourceShardList))
+			sourceShardList = append(sourceShardList, &topodatapb.Shard_SourceShard{
+				Uid:      uid,
+				Keyspace: dsi.Keyspace(),
+				Shard:    dsi.ShardName(),
+				KeyRange: keyRange,
+			})
+			queries = append(queries,
+				fmt.Sprintf("DELETE FROM _vt.blp_checkpoint WHERE source_shard_uid=%v", uid),
+				binlogplayer.PopulateBlpCheckpoint(uid, destinationPositions[dsi], throttler.MaxRateModuleDisabled, throttler.ReplicationLagModuleDisabled, time.Now().Unix(), ""))
+		}
+		sourceShardLists[si.ShardName()] = sourceShardList
+		sourceQueries[si.ShardName()] = queries
+	}
+
+	wg := sync.WaitGroup{}
+	rec := concurrency.AllErrorRecorder{}
+	for _, si := range sourceShards {
+		wg.Add(1)
+		go func(si *topo.ShardInfo, queries []string) {
+			defer wg.Done()
+			wr.Logger().Infof("Populating blp_checkpoint on %v", topoproto.TabletAliasString(si.MasterAlias))
+			ti, err := wr.ts.GetTablet(ctx, si.MasterAlias)
+			if err != nil {
+				rec.RecordError(err)
+				return
+			}
+			for _, query := range queries {
+				if _, err := wr.tmc.ExecuteFetchAsDba(ctx, ti.Tablet, false, []byte(query), 0, false, false); err != nil {
+					rec.RecordError(fmt.Errorf("cannot populate blp_checkpoint on %v: %v", topoproto.TabletAliasString(si.MasterAlias), err))
+					return
+				}
+			}
+		}(si, sourceQueries[si.ShardName()])
+	}
+	wg.Wait()
+	return sourceShardLists, rec.Error()
+}
+
 // refreshMasters will just RPC-ping all the masters with RefreshState
 func (wr *Wrangler) refreshMasters(ctx context.Context, shards []*topo.ShardInfo) error {
 	wg := sync.WaitGroup{}
@@ -286,7 +399,7 @@ func (wr *Wrangler) refreshMasters(ctx context.Context, shards []*topo.ShardInfo
 }
 
 // migrateServedTypesLocked operates with the keyspace locked
-func (wr *Wrangler) migrateServedTypesLocked(ctx context.Context, keyspace string, sourceShards, destinationShards []*topo.ShardInfo, cells []string, servedType topodatapb.TabletType, reverse bool, filteredReplicationWaitTime time.Duration) (err error) {
+func (wr *Wrangler) migrateServedTypesLocked(ctx context.Context, keyspace string, sourceShards, destinationShards []*topo.ShardInfo, cells []string, servedType topodatapb.TabletType, reverse, reverseReplication bool, filteredReplicationWaitTime time.Duration) (err error) {
 
 	// re-read all the shards so we are up to date
 	wr.Logger().Infof("Re-reading all shards")
@@ -319,8 +432,10 @@ func (wr *Wrangler) migrateServedTypesLocked(ctx context.Context, keyspace strin
 	// - switch the source shards to read-only by disabling query service
 	// - gather all replication points
 	// - wait for filtered replication to catch up before we continue
+	// - prepare the reverse replication if needed
 	// - we will disable filtered replication after the fact in the
 	//   next phases
+	var reverseSourceShards map[string][]*topodatapb.Shard_SourceShard
 	if servedType == topodatapb.TabletType_MASTER {
 		event.DispatchUpdate(ev, "disabling query service on all source masters")
 		for i, si := range sourceShards {
@@ -345,6 +460,16 @@ func (wr *Wrangler) migrateServedTypesLocked(ctx context.Context, keyspace strin
 		if err := wr.waitForFilteredReplication(ctx, masterPositions, destinationShards, filteredReplicationWaitTime); err != nil {
 			return err
 		}
+
+		// The destination masters are still read-only: their
+		// positions are the starting points of the reverse
+		// replication.
+		if reverseReplication {
+			event.DispatchUpdate(ev, "setting up reverse replication on source masters")
+			if reverseSourceShards, err = wr.setUpReverseReplication(ctx, sourceShards, destinationShards); err != nil {
+				return err
+			}
+		}
 	}
 
 	// Check and update all source shard records.
@@ -374,6 +499,9 @@ func (wr *Wrangler) migrateServedTypesLocked(ctx context.Context, keyspace strin
 					return err
 				}
 			}
+			if reverseSourceShards != nil {
+				si.SourceShards = reverseSourceShards[si.ShardName()]
+			}
 			return nil
 		})
 		if err != nil {
@@ -447,6 +575,15 @@ func (wr *Wrangler) migrateServedTypesLocked(ctx context.Context, keyspace strin
 		}
 	}
 
+	// The source masters start their binlog players when they
+	// see their new SourceShards.
+	if reverseSourceShards != nil {
+		event.DispatchUpdate(ev, "starting reverse replication on source masters")
+		if err := wr.refreshMasters(ctx, sourceShards); err != nil {
+			return err
+		}
+	}
+
 	event.DispatchUpdate(ev, "finished")
 	return nil
 }
@@ -0,0 +1,364 @@
+// Copyright 2016, Google Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wrangler
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/youtube/vitess/go/vt/concurrency"
+	"github.com/youtube/vitess/go/vt/topo"
+	"github.com/youtube/vitess/go/vt/topo/topoproto"
+	"github.com/youtube/vitess/go/vt/topotools"
+	"golang.org/x/net/context"
+
+	topodatapb "github.com/youtube/vitess/go/vt/proto/topodata"
+	vschemapb "github.com/youtube/vitess/go/vt/proto/vschema"
+)
+
+// MoveTables prepares moving a list of tables from a keyspace into
+// another, existing keyspace. It adds routing rules that keep sending
+// the queries for the tables to the source keyspace, whichever keyspace
+// they are qualified with. The data is then copied with
+// 'vtworker VerticalSplitClone --source_keyspace', checked with
+// VerticalSplitDiff, and each table is cut over with SwitchTables.
+func (wr *Wrangler) MoveTables(ctx context.Context, sourceKeyspace, destinationKeyspace string, tables []string) (err error) {
+	if sourceKeyspace == destinationKeyspace {
+		return fmt.Errorf("source and destination keyspaces are the same: %v", sourceKeyspace)
+	}
+	if err := checkMovedTableNames(tables); err != nil {
+		return err
+	}
+	if _, err := wr.ts.GetKeyspace(ctx, sourceKeyspace); err != nil {
+		return err
+	}
+	ki, err := wr.ts.GetKeyspace(ctx, destinationKeyspace)
+	if err != nil {
+		return err
+	}
+	if len(ki.ServedFroms) != 0 {
+		return fmt.Errorf("destination keyspace %v is the target of a vertical split, use MigrateServedFrom instead", destinationKeyspace)
+	}
+
+	// VerticalSplitClone copies each shard into the shard with the
+	// same name, so both keyspaces need the same shards.
+	sourceShards, err := wr.ts.GetShardNames(ctx, sourceKeyspace)
+	if err != nil {
+		return err
+	}
+	destinationShards, err := wr.ts.GetShardNames(ctx, destinationKeyspace)
+	if err != nil {
+		return err
+	}
+	sort.Strings(sourceShards)
+	sort.Strings(destinationShards)
+	if !reflect.DeepEqual(sourceShards, destinationShards) {
+		return fmt.Errorf("source keyspace %v has shards %v but destination keyspace %v has shards %v, they must be the same", sourceKeyspace, sourceShards, destinationKeyspace, destinationShards)
+	}
+
+	// VerticalSplitClone would replace the SourceShards of a migration
+	// that is still in progress.
+	shardMap, err := wr.ts.FindAllShardsInKeyspace(ctx, destinationKeyspace)
+	if err != nil {
+		return err
+	}
+	for _, si := range shardMap {
+		if len(si.SourceShards) != 0 {
+			return fmt.Errorf("destination shard %v/%v already has SourceShards %v, finish its migration first", si.Keyspace(), si.ShardName(), si.SourceShards)
+		}
+	}
+
+	// The routing rules are global, the destination keyspace lock
+	// serializes us with SwitchTables.
+	ctx, unlock, lockErr := wr.ts.LockKeyspace(ctx, destinationKeyspace, "MoveTables")
+	if lockErr != nil {
+		return lockErr
+	}
+	defer unlock(&err)
+
+	rules, err := wr.getRoutingRulesMap(ctx)
+	if err != nil {
+		return err
+	}
+	for _, table := range tables {
+		for _, from := range []string{table, destinationKeyspace + "." + table, sourceKeyspace + "." + table} {
+			if to, ok := rules[from]; ok {
+				return fmt.Errorf("table %v already has a routing rule: %v -> %v", table, from, to)
+			}
+		}
+		rules[table] = sourceKeyspace + "." + table
+		rules[destinationKeyspace+"."+table] = sourceKeyspace + "." + table
+	}
+	if err := wr.saveRoutingRulesMap(ctx, rules); err != nil {
+		return err
+	}
+
+	wr.Logger().Infof("Tables %v are now routed to keyspace %v. Next steps: CopySchemaShard of the tables into every shard of %v, 'vtworker VerticalSplitClone --source_keyspace=%v --tables=%v %v/<shard>' for every shard, VerticalSplitDiff, then SwitchTables.", tables, sourceKeyspace, destinationKeyspace, sourceKeyspace, strings.Join(tables, ","), destinationKeyspace)
+	return nil
+}
+
+// SwitchTables cuts over a list of tables moved with MoveTables: it
+// blacklists them on the source shards, waits for filtered replication
+// to catch up, stops replicating them into the destination shards, and
+// routes all queries for them to the destination keyspace.
+func (wr *Wrangler) SwitchTables(ctx context.Context, destinationKeyspace string, tables []string, filteredReplicationWaitTime time.Duration) (err error) {
+	if err := checkMovedTableNames(tables); err != nil {
+		return err
+	}
+
+	// check the destination shards before locking (will also be
+	// checked after locking to be sure)
+	destinationShards, sourceKeyspace, err := wr.findMovedTablesShards(ctx, destinationKeyspace, tables)
+	if err != nil {
+		return err
+	}
+
+	// lock the keyspaces, source first.
+	action := fmt.Sprintf("SwitchTables(%v)", strings.Join(tables, ","))
+	ctx, unlock, lockErr := wr.ts.LockKeyspace(ctx, sourceKeyspace, action)
+	if lockErr != nil {
+		return lockErr
+	}
+	defer unlock(&err)
+	ctx, unlock, lockErr = wr.ts.LockKeyspace(ctx, destinationKeyspace, action)
+	if lockErr != nil {
+		return lockErr
+	}
+	defer unlock(&err)
+
+	destinationShards, sourceKeyspace2, err := wr.findMovedTablesShards(ctx, destinationKeyspace, tables)
+	if err != nil {
+		return err
+	}
+	if sourceKeyspace2 != sourceKeyspace {
+		return fmt.Errorf("source keyspace of %v changed from %v to %v while locking", destinationKeyspace, sourceKeyspace, sourceKeyspace2)
+	}
+
+	// blacklist the tables on the source shards, for all tablet types:
+	// there will be no more writes on the source master after this.
+	var sourceShards []*topo.ShardInfo
+	for _, si := range destinationShards {
+		ss := si.SourceShards[0]
+		sourceShard, err := wr.ts.UpdateShardFields(ctx, ss.Keyspace, ss.Shard, func(si *topo.ShardInfo) error {
+			for _, tabletType := range []topodatapb.TabletType{topodatapb.TabletType_MASTER, topodatapb.TabletType_REPLICA, topodatapb.TabletType_RDONLY} {
+				if err := si.AddSourceBlacklistedTables(ctx, tabletType, tables); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		sourceShards = append(sourceShards, sourceShard)
+	}
+	if err := wr.refreshMasters(ctx, sourceShards); err != nil {
+		return err
+	}
+
+	// wait for the destination masters to catch up
+	sourcePositions, err := wr.getMastersPosition(ctx, sourceShards)
+	if err != nil {
+		return err
+	}
+	if err := wr.waitForFilteredReplication(ctx, sourcePositions, destinationShards, filteredReplicationWaitTime); err != nil {
+		return err
+	}
+
+	// stop replicating the tables into the destination shards. The
+	// shards that no longer replicate any table are done.
+	var doneShards []*topo.ShardInfo
+	var doneUIDs []uint32
+	for i, si := range destinationShards {
+		done := false
+		var uid uint32
+		destinationShards[i], err = wr.ts.UpdateShardFields(ctx, si.Keyspace(), si.ShardName(), func(si *topo.ShardInfo) error {
+			if len(si.SourceShards) != 1 {
+				return fmt.Errorf("destination shard %v/%v has %v source shards, expected 1", si.Keyspace(), si.ShardName(), len(si.SourceShards))
+			}
+			remaining := removeTables(si.SourceShards[0].Tables, tables)
+			done = len(remaining) == 0
+			if done {
+				uid = si.SourceShards[0].Uid
+				si.SourceShards = nil
+			} else {
+				si.SourceShards[0].Tables = remaining
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		if done {
+			doneShards = append(doneShards, destinationShards[i])
+			doneUIDs = append(doneUIDs, uid)
+		}
+	}
+	if err := wr.refreshMasters(ctx, destinationShards); err != nil {
+		return err
+	}
+
+	// the binlog players are stopped now, remove their checkpoints so
+	// a later migration with the same uid does not resume from them.
+	if err := wr.deleteBlpCheckpoints(ctx, doneShards, doneUIDs); err != nil {
+		return err
+	}
+
+	// and route the queries to the destination keyspace
+	rules, err := wr.getRoutingRulesMap(ctx)
+	if err != nil {
+		return err
+	}
+	for _, table := range tables {
+		to := destinationKeyspace + "." + table
+		rules[table] = to
+		rules[sourceKeyspace+"."+table] = to
+		rules[destinationKeyspace+"."+table] = to
+	}
+	if err := wr.saveRoutingRulesMap(ctx, rules); err != nil {
+		return err
+	}
+
+	// the source replicas and rdonlys are still serving the tables
+	// to anybody that did not pick up the new routing rules yet,
+	// now make them enforce the blacklist.
+	for _, si := range sourceShards {
+		for _, tabletType := range []topodatapb.TabletType{topodatapb.TabletType_REPLICA, topodatapb.TabletType_RDONLY} {
+			if err := wr.RefreshTabletsByShard(ctx, si, tabletType, nil); err != nil {
+				wr.Logger().Warningf("RefreshTabletsByShard(%v/%v, %v) failed with %v, the tablets may keep serving tables %v until their next refresh", si.Keyspace(), si.ShardName(), tabletType, err, tables)
+			}
+		}
+	}
+	return nil
+}
+
+// deleteBlpCheckpoints removes the _vt.blp_checkpoint row of uids[i]
+// on the master of shards[i].
+func (wr *Wrangler) deleteBlpCheckpoints(ctx context.Context, shards []*topo.ShardInfo, uids []uint32) error {
+	wg := sync.WaitGroup{}
+	rec := concurrency.AllErrorRecorder{}
+	for i, si := range shards {
+		wg.Add(1)
+		go func(si *topo.ShardInfo, uid uint32) {
+			defer wg.Done()
+			wr.Logger().Infof("Deleting blp_checkpoint %v on %v", uid, topoproto.TabletAliasString(si.MasterAlias))
+			ti, err := wr.ts.GetTablet(ctx, si.MasterAlias)
+			if err != nil {
+				rec.RecordError(err)
+				return
+			}
+			query := fmt.Sprintf("DELETE FROM _vt.blp_checkpoint WHERE source_shard_uid=%v", uid)
+			if _, err := wr.tmc.ExecuteFetchAsDba(ctx, ti.Tablet, false, []byte(query), 0, false, false); err != nil {
+				rec.RecordError(fmt.Errorf("cannot delete blp_checkpoint on %v: %v", topoproto.TabletAliasString(si.MasterAlias), err))
+			}
+		}(si, uids[i])
+	}
+	wg.Wait()
+	return rec.Error()
+}
+
+// findMovedTablesShards returns the shards of the destination keyspace
+// of a MoveTables, and the source keyspace. Each shard must replicate
+// all the given tables from a single source shard.
+func (wr *Wrangler) findMovedTablesShards(ctx context.Context, destinationKeyspace string, tables []string) ([]*topo.ShardInfo, string, error) {
+	shardMap, err := wr.ts.FindAllShardsInKeyspace(ctx, destinationKeyspace)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(shardMap) == 0 {
+		return nil, "", fmt.Errorf("keyspace %v has no shards", destinationKeyspace)
+	}
+	var shards []*topo.ShardInfo
+	sourceKeyspace := ""
+	for _, si := range shardMap {
+		if len(si.SourceShards) != 1 {
+			return nil, "", fmt.Errorf("destination shard %v/%v has %v source shards, expected 1 (did you run VerticalSplitClone --source_keyspace?)", si.Keyspace(), si.ShardName(), len(si.SourceShards))
+		}
+		ss := si.SourceShards[0]
+		if len(removeTables(tables, ss.Tables)) != 0 {
+			return nil, "", fmt.Errorf("destination shard %v/%v does not replicate all of the tables %v from %v/%v, it replicates %v", si.Keyspace(), si.ShardName(), tables, ss.Keyspace, ss.Shard, ss.Tables)
+		}
+		if sourceKeyspace == "" {
+			sourceKeyspace = ss.Keyspace
+		} else if sourceKeyspace != ss.Keyspace {
+			return nil, "", fmt.Errorf("destination keyspace %v replicates from both %v and %v", destinationKeyspace, sourceKeyspace, ss.Keyspace)
+		}
+		shards = append(shards, si)
+	}
+	return shards, sourceKeyspace, nil
+}
+
+// getRoutingRulesMap returns the routing rules as a map of from_table
+// to to_table.
+func (wr *Wrangler) getRoutingRulesMap(ctx context.Context) (map[string]string, error) {
+	rr, err := wr.ts.GetRoutingRules(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rules := make(map[string]string, len(rr.Rules))
+	for _, rule := range rr.Rules {
+		rules[rule.FromTable] = rule.ToTable
+	}
+	return rules, nil
+}
+
+// saveRoutingRulesMap saves the routing rules, sorted by from_table,
+// and rebuilds the SrvVSchema in all cells.
+func (wr *Wrangler) saveRoutingRulesMap(ctx context.Context, rules map[string]string) error {
+	var froms []string
+	for from := range rules {
+		froms = append(froms, from)
+	}
+	sort.Strings(froms)
+	rr := &vschemapb.RoutingRules{}
+	for _, from := range froms {
+		rr.Rules = append(rr.Rules, &vschemapb.RoutingRule{
+			FromTable: from,
+			ToTable:   rules[from],
+		})
+	}
+	if err := wr.ts.SaveRoutingRules(ctx, rr); err != nil {
+		return err
+	}
+	return topotools.RebuildVSchema(ctx, wr.logger, wr.ts, nil)
+}
+
+// checkMovedTableNames checks the list of tables of a MoveTables or
+// SwitchTables: tables are exact, unqualified names.
+func checkMovedTableNames(tables []string) error {
+	if len(tables) == 0 {
+		return fmt.Errorf("no tables specified")
+	}
+	seen := make(map[string]bool)
+	for _, table := range tables {
+		if table == "" || strings.ContainsAny(table, ".*%/") {
+			return fmt.Errorf("invalid table name %q, tables must be plain, unqualified names", table)
+		}
+		if seen[table] {
+			return fmt.Errorf("table %v specified twice", table)
+		}
+		seen[table] = true
+	}
+	return nil
+}
+
+// removeTables returns the tables of list that are not in toRemove.
+func removeTables(list, toRemove []string) []string {
+	remove := make(map[string]bool)
+	for _, table := range toRemove {
+		remove[table] = true
+	}
+	var result []string
+	for _, table := range list {
+		if !remove[table] {
+			result = append(result, table)
+		}
+	}
+	return result
+}
@@ -6,10 +6,14 @@ package testlib
 
 import (
 	"flag"
+	"fmt"
+	"reflect"
 	"strings"
 	"testing"
 
 	"github.com/youtube/vitess/go/sqltypes"
+	"github.com/youtube/vitess/go/vt/binlog/binlogplayer"
+	"github.com/youtube/vitess/go/vt/key"
 	"github.com/youtube/vitess/go/vt/logutil"
 	"github.com/youtube/vitess/go/vt/mysqlctl/replication"
 	"github.com/youtube/vitess/go/vt/tabletmanager/tmclient"
@@ -209,3 +213,364 @@ func TestMigrateServedTypes(t *testing.T) {
 	checkShardSourceShards(t, ts, "-80", 0)
 	checkShardSourceShards(t, ts, "80-", 0)
 }
+
+// blpCheckpointQueryMap returns the responses of a destination master
+// to WaitBlpPosition, saying it's already caught up with its sources.
+// checkReverseSourceShards checks the SourceShards of a shard, as
+// "<uid> <keyspace>/<shard> <key range>".
+func checkReverseSourceShards(t *testing.T, ts topo.Server, shard string, want []string) {
+	si, err := ts.GetShard(context.Background(), "ks", shard)
+	if err != nil {
+		t.Fatalf("GetShard failed: %v", err)
+	}
+	var got []string
+	for _, ss := range si.SourceShards {
+		got = append(got, fmt.Sprintf("%v %v/%v %v", ss.Uid, ss.Keyspace, ss.Shard, key.KeyRangeString(ss.KeyRange)))
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("SourceShards of %v: %v, want %v", shard, got, want)
+	}
+}
+
+func blpCheckpointQueryMap(positions ...replication.Position) map[string]*sqltypes.Result {
+	result := make(map[string]*sqltypes.Result)
+	for uid, pos := range positions {
+		result[fmt.Sprintf("SELECT pos, flags FROM _vt.blp_checkpoint WHERE source_shard_uid=%v", uid)] = &sqltypes.Result{
+			Rows: [][]sqltypes.Value{
+				{
+					sqltypes.MakeString([]byte(replication.EncodePosition(pos))),
+					sqltypes.MakeString([]byte("")),
+				},
+			},
+		}
+	}
+	return result
+}
+
+func TestMigrateServedTypesReverseReplication(t *testing.T) {
+	// TODO(b/26388813): Remove the next two lines once vtctl WaitForDrain is integrated in the vtctl MigrateServed* commands.
+	flag.Set("wait_for_drain_sleep_rdonly", "0s")
+	flag.Set("wait_for_drain_sleep_replica", "0s")
+
+	db := fakesqldb.Register()
+	ts := zktestserver.New(t, []string{"cell1", "cell2"})
+	wr := wrangler.New(logutil.NewConsoleLogger(), ts, tmclient.NewTabletManagerClient())
+	vp := NewVtctlPipe(t, ts)
+	defer vp.Close()
+
+	// create keyspace
+	if err := ts.CreateKeyspace(context.Background(), "ks", &topodatapb.Keyspace{
+		ShardingColumnName: "keyspace_id",
+		ShardingColumnType: topodatapb.KeyspaceIdType_UINT64,
+	}); err != nil {
+		t.Fatalf("CreateKeyspace failed: %v", err)
+	}
+
+	// create the masters of the source and destination shards
+	sourceMaster := NewFakeTablet(t, wr, "cell1", 10, topodatapb.TabletType_MASTER, db,
+		TabletKeyspaceShard(t, "ks", "0"))
+	dest1Master := NewFakeTablet(t, wr, "cell1", 20, topodatapb.TabletType_MASTER, db,
+		TabletKeyspaceShard(t, "ks", "-80"))
+	dest2Master := NewFakeTablet(t, wr, "cell1", 30, topodatapb.TabletType_MASTER, db,
+		TabletKeyspaceShard(t, "ks", "80-"))
+
+	sourcePosition := replication.Position{GTIDSet: replication.MariadbGTID{Domain: 5, Server: 456, Sequence: 892}}
+	dest1Position := replication.Position{GTIDSet: replication.MariadbGTID{Domain: 5, Server: 457, Sequence: 12}}
+	dest2Position := replication.Position{GTIDSet: replication.MariadbGTID{Domain: 5, Server: 458, Sequence: 34}}
+
+	// Each master will be asked about its replication position,
+	// and will wait for its sources: the destination masters
+	// before the master migration, the source master after it,
+	// when the served types are migrated back.
+	sourceMaster.FakeMysqlDaemon.CurrentMasterPosition = sourcePosition
+	sourceMaster.FakeMysqlDaemon.FetchSuperQueryMap = blpCheckpointQueryMap(dest1Position, dest2Position)
+	sourceMaster.StartActionLoop(t, wr)
+	defer sourceMaster.StopActionLoop(t)
+	dest1Master.FakeMysqlDaemon.CurrentMasterPosition = dest1Position
+	dest1Master.FakeMysqlDaemon.FetchSuperQueryMap = blpCheckpointQueryMap(sourcePosition)
+	dest1Master.StartActionLoop(t, wr)
+	defer dest1Master.StopActionLoop(t)
+	dest2Master.FakeMysqlDaemon.CurrentMasterPosition = dest2Position
+	dest2Master.FakeMysqlDaemon.FetchSuperQueryMap = blpCheckpointQueryMap(sourcePosition)
+	dest2Master.StartActionLoop(t, wr)
+	defer dest2Master.StopActionLoop(t)
+
+	// The source master populates its blp_checkpoint table for
+	// the reverse replication.
+	for _, query := range binlogplayer.CreateBlpCheckpoint() {
+		db.AddQuery(query, &sqltypes.Result{})
+	}
+	db.AddQuery("DELETE FROM _vt.blp_checkpoint WHERE source_shard_uid=0", &sqltypes.Result{})
+	db.AddQuery("DELETE FROM _vt.blp_checkpoint WHERE source_shard_uid=1", &sqltypes.Result{})
+	db.AddQueryPattern(fmt.Sprintf(`INSERT INTO _vt\.blp_checkpoint .* VALUES \(0, '%v', .*`, replication.EncodePosition(dest1Position)), &sqltypes.Result{})
+	db.AddQueryPattern(fmt.Sprintf(`INSERT INTO _vt\.blp_checkpoint .* VALUES \(1, '%v', .*`, replication.EncodePosition(dest2Position)), &sqltypes.Result{})
+
+	// simulate the clone, by fixing the dest shard record
+	if err := vp.Run([]string{"SourceShardAdd", "--key_range=-", "ks/-80", "0", "ks/0"}); err != nil {
+		t.Fatalf("SourceShardAdd failed: %v", err)
+	}
+	if err := vp.Run([]string{"SourceShardAdd", "--key_range=-", "ks/80-", "0", "ks/0"}); err != nil {
+		t.Fatalf("SourceShardAdd failed: %v", err)
+	}
+
+	// reverse replication is only for master migrations
+	if err := vp.Run([]string{"MigrateServedTypes", "-reverse_replication", "ks/0", "rdonly"}); err == nil {
+		t.Fatalf("MigrateServedType(rdonly) with reverse replication should fail")
+	}
+	for _, servedType := range []string{"rdonly", "replica"} {
+		if err := vp.Run([]string{"MigrateServedTypes", "ks/0", servedType}); err != nil {
+			t.Fatalf("MigrateServedType(%v) failed: %v", servedType, err)
+		}
+	}
+
+	// migrate master over, with reverse replication
+	if err := vp.Run([]string{"MigrateServedTypes", "-reverse_replication", "ks/0", "master"}); err != nil {
+		t.Fatalf("MigrateServedType(master) failed: %v", err)
+	}
+	checkShardServedTypes(t, ts, "0", 0)
+	checkShardServedTypes(t, ts, "-80", 3)
+	checkShardServedTypes(t, ts, "80-", 3)
+	checkShardSourceShards(t, ts, "-80", 0)
+	checkShardSourceShards(t, ts, "80-", 0)
+	checkReverseSourceShards(t, ts, "0", []string{"0 ks/-80 -80", "1 ks/80- 80-"})
+	if got := db.GetQueryCalledNum("CREATE DATABASE IF NOT EXISTS _vt"); got != 1 {
+		t.Errorf("blp_checkpoint was created %v times, want 1", got)
+	}
+
+	// the master cannot be migrated back with -reverse
+	if err := vp.Run([]string{"MigrateServedTypes", "-reverse", "ks/0", "master"}); err == nil {
+		t.Fatalf("MigrateServedType(master) with -reverse should fail")
+	}
+
+	// the shards swapped their roles: migrate the served types back
+	for _, servedType := range []string{"rdonly", "replica", "master"} {
+		if err := vp.Run([]string{"MigrateServedTypes", "ks/0", servedType}); err != nil {
+			t.Fatalf("MigrateServedType(%v) back failed: %v", servedType, err)
+		}
+	}
+	checkShardServedTypes(t, ts, "0", 3)
+	checkShardServedTypes(t, ts, "-80", 0)
+	checkShardServedTypes(t, ts, "80-", 0)
+	checkShardSourceShards(t, ts, "0", 0)
+	checkShardSourceShards(t, ts, "-80", 0)
+	checkShardSourceShards(t, ts, "80-", 0)
+}
+
+// testMigrateServedTypesReverseReplication migrates the served types
+// from the source shards to the destination shards, with the reverse
+// replication, and checks the SourceShards of the source shards.
+// sourceShardAdds are the SourceShardAdd commands which simulate the
+// clone, and wantSourceShards the reverse SourceShards of each source
+// shard, as "<uid> <keyspace>/<shard> <key range>".
+func testMigrateServedTypesReverseReplication(t *testing.T, sourceShards, destinationShards []string, sourceShardAdds [][]string, wantSourceShards map[string][]string) {
+	// TODO(b/26388813): Remove the next two lines once vtctl WaitForDrain is integrated in the vtctl MigrateServed* commands.
+	flag.Set("wait_for_drain_sleep_rdonly", "0s")
+	flag.Set("wait_for_drain_sleep_replica", "0s")
+
+	db := fakesqldb.Register()
+	ts := zktestserver.New(t, []string{"cell1", "cell2"})
+	wr := wrangler.New(logutil.NewConsoleLogger(), ts, tmclient.NewTabletManagerClient())
+	vp := NewVtctlPipe(t, ts)
+	defer vp.Close()
+
+	if err := ts.CreateKeyspace(context.Background(), "ks", &topodatapb.Keyspace{
+		ShardingColumnName: "keyspace_id",
+		ShardingColumnType: topodatapb.KeyspaceIdType_UINT64,
+	}); err != nil {
+		t.Fatalf("CreateKeyspace failed: %v", err)
+	}
+
+	// The source masters are created first, so they serve all
+	// the types. Each master has its own position.
+	masters := make(map[string]*FakeTablet)
+	positions := make(map[string]replication.Position)
+	for i, shard := range append(append([]string{}, sourceShards...), destinationShards...) {
+		uid := uint32(10 * (i + 1))
+		masters[shard] = NewFakeTablet(t, wr, "cell1", uid, topodatapb.TabletType_MASTER, db,
+			TabletKeyspaceShard(t, "ks", shard))
+		positions[shard] = replication.Position{GTIDSet: replication.MariadbGTID{Domain: 5, Server: uid, Sequence: uint64(100 + i)}}
+		masters[shard].FakeMysqlDaemon.CurrentMasterPosition = positions[shard]
+	}
+	// The destination shard 0 of a merge was created serving.
+	for _, shard := range destinationShards {
+		if _, err := ts.UpdateShardFields(context.Background(), "ks", shard, func(si *topo.ShardInfo) error {
+			si.ServedTypes = nil
+			return nil
+		}); err != nil {
+			t.Fatalf("UpdateShardFields failed: %v", err)
+		}
+	}
+
+	// Simulate the clone. The destination masters wait for the
+	// positions of their sources.
+	destinationSources := make(map[string][]replication.Position)
+	for _, args := range sourceShardAdds {
+		if err := vp.Run(append([]string{"SourceShardAdd"}, args...)); err != nil {
+			t.Fatalf("SourceShardAdd(%v) failed: %v", args, err)
+		}
+		dest, source := strings.TrimPrefix(args[1], "ks/"), strings.TrimPrefix(args[3], "ks/")
+		destinationSources[dest] = append(destinationSources[dest], positions[source])
+	}
+	for dest, sourcePositions := range destinationSources {
+		masters[dest].FakeMysqlDaemon.FetchSuperQueryMap = blpCheckpointQueryMap(sourcePositions...)
+	}
+	for _, master := range masters {
+		master.StartActionLoop(t, wr)
+		defer master.StopActionLoop(t)
+	}
+
+	// The source masters populate their blp_checkpoint table with
+	// the positions of the destination masters they replicate from.
+	for _, query := range binlogplayer.CreateBlpCheckpoint() {
+		db.AddQuery(query, &sqltypes.Result{})
+	}
+	for _, want := range wantSourceShards {
+		for uid, ss := range want {
+			shard := strings.TrimPrefix(strings.Fields(ss)[1], "ks/")
+			db.AddQuery(fmt.Sprintf("DELETE FROM _vt.blp_checkpoint WHERE source_shard_uid=%v", uid), &sqltypes.Result{})
+			db.AddQueryPattern(fmt.Sprintf(`INSERT INTO _vt\.blp_checkpoint .* VALUES \(%v, '%v', .*`, uid, replication.EncodePosition(positions[shard])), &sqltypes.Result{})
+		}
+	}
+
+	for _, servedType := range []string{"rdonly", "replica"} {
+		if err := vp.Run([]string{"MigrateServedTypes", "ks/" + sourceShards[0], servedType}); err != nil {
+			t.Fatalf("MigrateServedType(%v) failed: %v", servedType, err)
+		}
+	}
+	if err := vp.Run([]string{"MigrateServedTypes", "-reverse_replication", "ks/" + sourceShards[0], "master"}); err != nil {
+		t.Fatalf("MigrateServedType(master) failed: %v", err)
+	}
+	for _, shard := range sourceShards {
+		checkShardServedTypes(t, ts, shard, 0)
+		checkReverseSourceShards(t, ts, shard, wantSourceShards[shard])
+	}
+	for _, shard := range destinationShards {
+		checkShardServedTypes(t, ts, shard, 3)
+		checkShardSourceShards(t, ts, shard, 0)
+	}
+}
+
+func TestMigrateServedTypesReverseReplicationMerge(t *testing.T) {
+	testMigrateServedTypesReverseReplication(t,
+		[]string{"-80", "80-"},
+		[]string{"0"},
+		[][]string{
+			{"--key_range=-80", "ks/0", "0", "ks/-80"},
+			{"--key_range=80-", "ks/0", "1", "ks/80-"},
+		},
+		map[string][]string{
+			"-80": {"0 ks/0 -80"},
+			"80-": {"0 ks/0 80-"},
+		})
+}
+
+func TestMigrateServedTypesReverseReplicationNToM(t *testing.T) {
+	// Each source shard replicates from the two destination shards
+	// which overlap it, for the overlap only.
+	testMigrateServedTypesReverseReplication(t,
+		[]string{"-80", "80-"},
+		[]string{"-40", "40-c0", "c0-"},
+		[][]string{
+			{"--key_range=-40", "ks/-40", "0", "ks/-80"},
+			{"--key_range=40-80", "ks/40-c0", "0", "ks/-80"},
+			{"--key_range=80-c0", "ks/40-c0", "1", "ks/80-"},
+			{"--key_range=c0-", "ks/c0-", "0", "ks/80-"},
+		},
+		map[string][]string{
+			"-80": {"0 ks/-40 -40", "1 ks/40-c0 40-80"},
+			"80-": {"0 ks/40-c0 80-c0", "1 ks/c0- c0-"},
+		})
+}
+
+func TestMigrateServedTypesMerge(t *testing.T) {
+	// TODO(b/26388813): Remove the next two lines once vtctl WaitForDrain is integrated in the vtctl MigrateServed* commands.
+	flag.Set("wait_for_drain_sleep_rdonly", "0s")
+	flag.Set("wait_for_drain_sleep_replica", "0s")
+
+	db := fakesqldb.Register()
+	ts := zktestserver.New(t, []string{"cell1", "cell2"})
+	wr := wrangler.New(logutil.NewConsoleLogger(), ts, tmclient.NewTabletManagerClient())
+	vp := NewVtctlPipe(t, ts)
+	defer vp.Close()
+
+	// create keyspace
+	if err := ts.CreateKeyspace(context.Background(), "ks", &topodatapb.Keyspace{
+		ShardingColumnName: "keyspace_id",
+		ShardingColumnType: topodatapb.KeyspaceIdType_UINT64,
+	}); err != nil {
+		t.Fatalf("CreateKeyspace failed: %v", err)
+	}
+
+	// create the masters of the two source shards and the shard 80-
+	// first, so they serve all types and cover the keyspace, then the
+	// master of the destination shard
+	source1Master := NewFakeTablet(t, wr, "cell1", 10, topodatapb.TabletType_MASTER, db,
+		TabletKeyspaceShard(t, "ks", "-40"))
+	source2Master := NewFakeTablet(t, wr, "cell1", 20, topodatapb.TabletType_MASTER, db,
+		TabletKeyspaceShard(t, "ks", "40-80"))
+	if err := ts.CreateShard(context.Background(), "ks", "80-"); err != nil {
+		t.Fatalf("CreateShard(\"80-\") failed: %v", err)
+	}
+	destMaster := NewFakeTablet(t, wr, "cell1", 30, topodatapb.TabletType_MASTER, db,
+		TabletKeyspaceShard(t, "ks", "-80"))
+	checkShardServedTypes(t, ts, "-40", 3)
+	checkShardServedTypes(t, ts, "40-80", 3)
+	checkShardServedTypes(t, ts, "-80", 0)
+	checkShardServedTypes(t, ts, "80-", 3)
+
+	source1Position := replication.Position{GTIDSet: replication.MariadbGTID{Domain: 5, Server: 456, Sequence: 892}}
+	source2Position := replication.Position{GTIDSet: replication.MariadbGTID{Domain: 5, Server: 457, Sequence: 12}}
+
+	// The source masters will be asked about their replication
+	// position. The destination master waits for both of them.
+	source1Master.FakeMysqlDaemon.CurrentMasterPosition = source1Position
+	source1Master.StartActionLoop(t, wr)
+	defer source1Master.StopActionLoop(t)
+	source2Master.FakeMysqlDaemon.CurrentMasterPosition = source2Position
+	source2Master.StartActionLoop(t, wr)
+	defer source2Master.StopActionLoop(t)
+	destMaster.FakeMysqlDaemon.FetchSuperQueryMap = blpCheckpointQueryMap(source1Position, source2Position)
+	destMaster.StartActionLoop(t, wr)
+	defer destMaster.StopActionLoop(t)
+
+	// simulate the clone of the first source shard only
+	if err := vp.Run([]string{"SourceShardAdd", "--key_range=-40", "ks/-80", "0", "ks/-40"}); err != nil {
+		t.Fatalf("SourceShardAdd failed: %v", err)
+	}
+
+	// migrate will error because the data of the second source
+	// shard is not replicated into the destination shard
+	if err := vp.Run([]string{"MigrateServedTypes", "ks/-80", "rdonly"}); err == nil || !strings.Contains(err.Error(), "source shard ks/40-80 is not in the SourceShards of any destination shard") {
+		t.Fatalf("MigrateServedType(rdonly) should fail if a source shard is not replicated: %v", err)
+	}
+
+	// simulate the clone of the second source shard
+	if err := vp.Run([]string{"SourceShardAdd", "--key_range=40-80", "ks/-80", "1", "ks/40-80"}); err != nil {
+		t.Fatalf("SourceShardAdd failed: %v", err)
+	}
+	checkShardSourceShards(t, ts, "-80", 2)
+
+	// migrate rdonly and replica over, from either side of the merge
+	if err := vp.Run([]string{"MigrateServedTypes", "ks/-80", "rdonly"}); err != nil {
+		t.Fatalf("MigrateServedType(rdonly) failed: %v", err)
+	}
+	checkShardServedTypes(t, ts, "-40", 2)
+	checkShardServedTypes(t, ts, "40-80", 2)
+	checkShardServedTypes(t, ts, "-80", 1)
+	if err := vp.Run([]string{"MigrateServedTypes", "ks/40-80", "replica"}); err != nil {
+		t.Fatalf("MigrateServedType(replica) failed: %v", err)
+	}
+	checkShardServedTypes(t, ts, "-40", 1)
+	checkShardServedTypes(t, ts, "40-80", 1)
+	checkShardServedTypes(t, ts, "-80", 2)
+
+	// migrate master over, which waits for both source shards
+	if err := vp.Run([]string{"MigrateServedTypes", "ks/-80", "master"}); err != nil {
+		t.Fatalf("MigrateServedType(master) failed: %v", err)
+	}
+	checkShardServedTypes(t, ts, "-40", 0)
+	checkShardServedTypes(t, ts, "40-80", 0)
+	checkShardServedTypes(t, ts, "-80", 3)
+	checkShardServedTypes(t, ts, "80-", 3)
+	checkShardSourceShards(t, ts, "-80", 0)
+}
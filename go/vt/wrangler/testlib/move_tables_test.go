@@ -0,0 +1,206 @@
+// Copyright 2016, Google Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package testlib
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/youtube/vitess/go/sqltypes"
+	"github.com/youtube/vitess/go/vt/logutil"
+	"github.com/youtube/vitess/go/vt/mysqlctl/replication"
+	"github.com/youtube/vitess/go/vt/tabletmanager/tmclient"
+	"github.com/youtube/vitess/go/vt/vttest/fakesqldb"
+	"github.com/youtube/vitess/go/vt/wrangler"
+	"github.com/youtube/vitess/go/vt/zktopo/zktestserver"
+	"golang.org/x/net/context"
+
+	topodatapb "github.com/youtube/vitess/go/vt/proto/topodata"
+)
+
+func TestMoveTables(t *testing.T) {
+	ctx := context.Background()
+	db := fakesqldb.Register()
+	ts := zktestserver.New(t, []string{"cell1", "cell2"})
+	wr := wrangler.New(logutil.NewConsoleLogger(), ts, tmclient.NewTabletManagerClient())
+	vp := NewVtctlPipe(t, ts)
+	defer vp.Close()
+
+	// create the source and destination keyspace tablets
+	sourceMaster := NewFakeTablet(t, wr, "cell1", 10, topodatapb.TabletType_MASTER, db,
+		TabletKeyspaceShard(t, "source", "0"))
+	sourceReplica := NewFakeTablet(t, wr, "cell1", 11, topodatapb.TabletType_REPLICA, db,
+		TabletKeyspaceShard(t, "source", "0"))
+	sourceRdonly := NewFakeTablet(t, wr, "cell1", 12, topodatapb.TabletType_RDONLY, db,
+		TabletKeyspaceShard(t, "source", "0"))
+	destMaster := NewFakeTablet(t, wr, "cell1", 20, topodatapb.TabletType_MASTER, db,
+		TabletKeyspaceShard(t, "dest", "0"))
+
+	// sourceReplica and sourceRdonly will see the refresh
+	sourceReplica.StartActionLoop(t, wr)
+	defer sourceReplica.StopActionLoop(t)
+	sourceRdonly.StartActionLoop(t, wr)
+	defer sourceRdonly.StopActionLoop(t)
+
+	// sourceMaster will see the refresh, and will be asked about its
+	// replication position.
+	sourceMaster.FakeMysqlDaemon.CurrentMasterPosition = replication.Position{
+		GTIDSet: replication.MariadbGTID{
+			Domain:   5,
+			Server:   456,
+			Sequence: 892,
+		},
+	}
+	sourceMaster.StartActionLoop(t, wr)
+	defer sourceMaster.StopActionLoop(t)
+
+	// destMaster will see the refresh, and has to respond to
+	// WaitBlpPosition, saying it's already caught up.
+	destMaster.FakeMysqlDaemon.FetchSuperQueryMap = map[string]*sqltypes.Result{
+		"SELECT pos, flags FROM _vt.blp_checkpoint WHERE source_shard_uid=0": {
+			Rows: [][]sqltypes.Value{
+				{
+					sqltypes.MakeString([]byte(replication.EncodePosition(sourceMaster.FakeMysqlDaemon.CurrentMasterPosition))),
+					sqltypes.MakeString([]byte("")),
+				},
+			},
+		},
+	}
+	destMaster.StartActionLoop(t, wr)
+	defer destMaster.StopActionLoop(t)
+
+	// destMaster deletes its checkpoint once all tables are switched
+	deleteCheckpoint := "DELETE FROM _vt.blp_checkpoint WHERE source_shard_uid=0"
+	db.AddQuery(deleteCheckpoint, &sqltypes.Result{})
+
+	// the tables can't be moved into the same keyspace
+	if err := vp.Run([]string{"MoveTables", "source", "source", "moved1,moved2"}); err == nil {
+		t.Fatalf("MoveTables(source, source) worked")
+	}
+
+	// start the move, the tables are routed to the source keyspace
+	if err := vp.Run([]string{"MoveTables", "source", "dest", "moved1,moved2"}); err != nil {
+		t.Fatalf("MoveTables failed: %v", err)
+	}
+	checkRoutingRules(ctx, t, wr, map[string]string{
+		"moved1":      "source.moved1",
+		"dest.moved1": "source.moved1",
+		"moved2":      "source.moved2",
+		"dest.moved2": "source.moved2",
+	})
+	srvVSchema, err := ts.GetSrvVSchema(ctx, "cell1")
+	if err != nil {
+		t.Fatalf("GetSrvVSchema failed: %v", err)
+	}
+	if len(srvVSchema.RoutingRules.GetRules()) != 4 {
+		t.Fatalf("SrvVSchema was not rebuilt with the routing rules: %v", srvVSchema)
+	}
+
+	// the tables can't be moved twice
+	if err := vp.Run([]string{"MoveTables", "source", "dest", "moved1"}); err == nil {
+		t.Fatalf("MoveTables(moved1) worked twice")
+	}
+
+	// the tables can't be switched before they are cloned
+	if err := vp.Run([]string{"SwitchTables", "dest", "moved1"}); err == nil {
+		t.Fatalf("SwitchTables worked without filtered replication")
+	}
+
+	// simulate the clone, by fixing the dest shard record
+	if err := vp.Run([]string{"SourceShardAdd", "--tables", "moved1,moved2", "dest/0", "0", "source/0"}); err != nil {
+		t.Fatalf("SourceShardAdd failed: %v", err)
+	}
+
+	// no other tables can be moved while filtered replication runs
+	if err := vp.Run([]string{"MoveTables", "source", "dest", "moved3"}); err == nil || !strings.Contains(err.Error(), "already has SourceShards") {
+		t.Fatalf("MoveTables(moved3) should fail while dest/0 has SourceShards: %v", err)
+	}
+
+	// switch the first table
+	if err := vp.Run([]string{"SwitchTables", "dest", "moved1"}); err != nil {
+		t.Fatalf("SwitchTables(moved1) failed: %v", err)
+	}
+	checkRoutingRules(ctx, t, wr, map[string]string{
+		"moved1":        "dest.moved1",
+		"dest.moved1":   "dest.moved1",
+		"source.moved1": "dest.moved1",
+		"moved2":        "source.moved2",
+		"dest.moved2":   "source.moved2",
+	})
+	checkMovedTablesShards(ctx, t, wr, []string{"moved1"}, []string{"moved2"})
+	if n := db.GetQueryCalledNum(deleteCheckpoint); n != 0 {
+		t.Fatalf("blp_checkpoint was deleted %v times while moved2 is still replicated", n)
+	}
+
+	// switch the second table, filtered replication is done
+	if err := vp.Run([]string{"SwitchTables", "dest", "moved2"}); err != nil {
+		t.Fatalf("SwitchTables(moved2) failed: %v", err)
+	}
+	checkRoutingRules(ctx, t, wr, map[string]string{
+		"moved1":        "dest.moved1",
+		"dest.moved1":   "dest.moved1",
+		"source.moved1": "dest.moved1",
+		"moved2":        "dest.moved2",
+		"dest.moved2":   "dest.moved2",
+		"source.moved2": "dest.moved2",
+	})
+	checkMovedTablesShards(ctx, t, wr, []string{"moved1", "moved2"}, nil)
+	if n := db.GetQueryCalledNum(deleteCheckpoint); n != 1 {
+		t.Fatalf("blp_checkpoint was deleted %v times, want once", n)
+	}
+}
+
+func checkRoutingRules(ctx context.Context, t *testing.T, wr *wrangler.Wrangler, want map[string]string) {
+	rr, err := wr.TopoServer().GetRoutingRules(ctx)
+	if err != nil {
+		t.Fatalf("GetRoutingRules failed: %v", err)
+	}
+	got := make(map[string]string)
+	for _, rule := range rr.Rules {
+		got[rule.FromTable] = rule.ToTable
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got routing rules %v, want %v", got, want)
+	}
+}
+
+func checkMovedTablesShards(ctx context.Context, t *testing.T, wr *wrangler.Wrangler, blacklisted, replicated []string) {
+	si, err := wr.TopoServer().GetShard(ctx, "source", "0")
+	if err != nil {
+		t.Fatalf("GetShard failed: %v", err)
+	}
+	want := []*topodatapb.Shard_TabletControl{
+		{
+			TabletType:        topodatapb.TabletType_MASTER,
+			BlacklistedTables: blacklisted,
+		},
+		{
+			TabletType:        topodatapb.TabletType_REPLICA,
+			BlacklistedTables: blacklisted,
+		},
+		{
+			TabletType:        topodatapb.TabletType_RDONLY,
+			BlacklistedTables: blacklisted,
+		},
+	}
+	if !reflect.DeepEqual(si.TabletControls, want) {
+		t.Fatalf("source shard has bad TabletControls: %v, want %v", si.TabletControls, want)
+	}
+
+	si, err = wr.TopoServer().GetShard(ctx, "dest", "0")
+	if err != nil {
+		t.Fatalf("GetShard failed: %v", err)
+	}
+	if replicated == nil {
+		if len(si.SourceShards) != 0 {
+			t.Fatalf("dest shard still has SourceShards: %v", si.SourceShards)
+		}
+		return
+	}
+	if len(si.SourceShards) != 1 || !reflect.DeepEqual(si.SourceShards[0].Tables, replicated) {
+		t.Fatalf("dest shard has bad SourceShards: %v, want tables %v", si.SourceShards, replicated)
+	}
+}
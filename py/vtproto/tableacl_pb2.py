@@ -19,7 +19,7 @@ DESCRIPTOR = _descriptor.FileDescriptor(
   name='tableacl.proto',
   package='tableacl',
   syntax='proto3',
-  serialized_pb=_b('\n\x0etableacl.proto\x12\x08tableacl\"q\n\x0eTableGroupSpec\x12\x0c\n\x04name\x18\x01 \x01(\t\x12\x1f\n\x17table_names_or_prefixes\x18\x02 \x03(\t\x12\x0f\n\x07readers\x18\x03 \x03(\t\x12\x0f\n\x07writers\x18\x04 \x03(\t\x12\x0e\n\x06\x61\x64mins\x18\x05 \x03(\t\"8\n\x06\x43onfig\x12.\n\x0ctable_groups\x18\x01 \x03(\x0b\x32\x18.tableacl.TableGroupSpecb\x06proto3')
+  serialized_pb=_b('\n\x0etableacl.proto\x12\x08tableacl\"\xcc\x01\n\x0eTableGroupSpec\x12\x0c\n\x04name\x18\x01 \x01(\t\x12\x1f\n\x17table_names_or_prefixes\x18\x02 \x03(\t\x12\x0f\n\x07readers\x18\x03 \x03(\t\x12\x0f\n\x07writers\x18\x04 \x03(\t\x12\x0e\n\x06\x61\x64mins\x18\x05 \x03(\t\x12/\n\x0e\x63olumn_readers\x18\x06 \x03(\x0b\x32\x17.tableacl.ColumnReaders\x12(\n\x0brow_filters\x18\x07 \x03(\x0b\x32\x13.tableacl.RowFilter\"8\n\x06\x43onfig\x12.\n\x0ctable_groups\x18\x01 \x03(\x0b\x32\x18.tableacl.TableGroupSpec\"1\n\rColumnReaders\x12\x0f\n\x07\x63olumns\x18\x01 \x03(\t\x12\x0f\n\x07readers\x18\x02 \x03(\t\"/\n\tRowFilter\x12\x11\n\tpredicate\x18\x01 \x01(\t\x12\x0f\n\x07readers\x18\x02 \x03(\tb\x06proto3')
 )
 _sym_db.RegisterFileDescriptor(DESCRIPTOR)
 
@@ -68,6 +68,20 @@ _TABLEGROUPSPEC = _descriptor.Descriptor(
       message_type=None, enum_type=None, containing_type=None,
       is_extension=False, extension_scope=None,
       options=None),
+    _descriptor.FieldDescriptor(
+      name='column_readers', full_name='tableacl.TableGroupSpec.column_readers', index=5,
+      number=6, type=11, cpp_type=10, label=3,
+      has_default_value=False, default_value=[],
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None),
+    _descriptor.FieldDescriptor(
+      name='row_filters', full_name='tableacl.TableGroupSpec.row_filters', index=6,
+      number=7, type=11, cpp_type=10, label=3,
+      has_default_value=False, default_value=[],
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None),
   ],
   extensions=[
   ],
@@ -80,8 +94,8 @@ _TABLEGROUPSPEC = _descriptor.Descriptor(
   extension_ranges=[],
   oneofs=[
   ],
-  serialized_start=28,
-  serialized_end=141,
+  serialized_start=29,
+  serialized_end=233,
 )
 
 
@@ -111,13 +125,93 @@ _CONFIG = _descriptor.Descriptor(
   extension_ranges=[],
   oneofs=[
   ],
-  serialized_start=143,
-  serialized_end=199,
+  serialized_start=235,
+  serialized_end=291,
 )
 
+
+_COLUMNREADERS = _descriptor.Descriptor(
+  name='ColumnReaders',
+  full_name='tableacl.ColumnReaders',
+  filename=None,
+  file=DESCRIPTOR,
+  containing_type=None,
+  fields=[
+    _descriptor.FieldDescriptor(
+      name='columns', full_name='tableacl.ColumnReaders.columns', index=0,
+      number=1, type=9, cpp_type=9, label=3,
+      has_default_value=False, default_value=[],
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None),
+    _descriptor.FieldDescriptor(
+      name='readers', full_name='tableacl.ColumnReaders.readers', index=1,
+      number=2, type=9, cpp_type=9, label=3,
+      has_default_value=False, default_value=[],
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None),
+  ],
+  extensions=[
+  ],
+  nested_types=[],
+  enum_types=[
+  ],
+  options=None,
+  is_extendable=False,
+  syntax='proto3',
+  extension_ranges=[],
+  oneofs=[
+  ],
+  serialized_start=293,
+  serialized_end=342,
+)
+
+
+_ROWFILTER = _descriptor.Descriptor(
+  name='RowFilter',
+  full_name='tableacl.RowFilter',
+  filename=None,
+  file=DESCRIPTOR,
+  containing_type=None,
+  fields=[
+    _descriptor.FieldDescriptor(
+      name='predicate', full_name='tableacl.RowFilter.predicate', index=0,
+      number=1, type=9, cpp_type=9, label=1,
+      has_default_value=False, default_value=_b("").decode('utf-8'),
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None),
+    _descriptor.FieldDescriptor(
+      name='readers', full_name='tableacl.RowFilter.readers', index=1,
+      number=2, type=9, cpp_type=9, label=3,
+      has_default_value=False, default_value=[],
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None),
+  ],
+  extensions=[
+  ],
+  nested_types=[],
+  enum_types=[
+  ],
+  options=None,
+  is_extendable=False,
+  syntax='proto3',
+  extension_ranges=[],
+  oneofs=[
+  ],
+  serialized_start=344,
+  serialized_end=391,
+)
+
+_TABLEGROUPSPEC.fields_by_name['column_readers'].message_type = _COLUMNREADERS
+_TABLEGROUPSPEC.fields_by_name['row_filters'].message_type = _ROWFILTER
 _CONFIG.fields_by_name['table_groups'].message_type = _TABLEGROUPSPEC
 DESCRIPTOR.message_types_by_name['TableGroupSpec'] = _TABLEGROUPSPEC
 DESCRIPTOR.message_types_by_name['Config'] = _CONFIG
+DESCRIPTOR.message_types_by_name['ColumnReaders'] = _COLUMNREADERS
+DESCRIPTOR.message_types_by_name['RowFilter'] = _ROWFILTER
 
 TableGroupSpec = _reflection.GeneratedProtocolMessageType('TableGroupSpec', (_message.Message,), dict(
   DESCRIPTOR = _TABLEGROUPSPEC,
@@ -133,6 +227,20 @@ Config = _reflection.GeneratedProtocolMessageType('Config', (_message.Message,),
   ))
 _sym_db.RegisterMessage(Config)
 
+ColumnReaders = _reflection.GeneratedProtocolMessageType('ColumnReaders', (_message.Message,), dict(
+  DESCRIPTOR = _COLUMNREADERS,
+  __module__ = 'tableacl_pb2'
+  # @@protoc_insertion_point(class_scope:tableacl.ColumnReaders)
+  ))
+_sym_db.RegisterMessage(ColumnReaders)
+
+RowFilter = _reflection.GeneratedProtocolMessageType('RowFilter', (_message.Message,), dict(
+  DESCRIPTOR = _ROWFILTER,
+  __module__ = 'tableacl_pb2'
+  # @@protoc_insertion_point(class_scope:tableacl.RowFilter)
+  ))
+_sym_db.RegisterMessage(RowFilter)
+
 
 import grpc
 from grpc.beta import implementations as beta_implementations